@@ -0,0 +1,288 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cid gives chaincodes access to the identity of the client that
+// submitted the transaction, so that they can make access control decisions
+// on it. The identity is decoded from the creator of the transaction
+// proposal, so it works the same with the peer's stub and with shim.MockStub
+// (see MockStub.SetCreator).
+package cid
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// AttributesOID is the ASN1 object identifier of the X.509 extension
+// carrying the attributes of an identity. Its value is a JSON document of
+// the form {"attrs":{"name":"value",...}}
+var AttributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// attributes is the content of the attributes extension
+type attributes struct {
+	Attrs map[string]string `json:"attrs"`
+}
+
+// ClientIdentity represents the identity of the client that submitted a
+// transaction
+type ClientIdentity interface {
+	// GetID returns an identifier of the client, unique within its MSP,
+	// built from the subject and the issuer of its certificate
+	GetID() string
+
+	// GetMSPID returns the ID of the MSP the client belongs to
+	GetMSPID() string
+
+	// GetX509Certificate returns the X.509 certificate of the client
+	GetX509Certificate() *x509.Certificate
+
+	// GetSubject returns the subject of the client certificate
+	GetSubject() pkix.Name
+
+	// GetOUs returns the organization units of the client certificate
+	GetOUs() []string
+
+	// GetAttributeValue returns the value of the attribute named attrName
+	// and whether the client has it
+	GetAttributeValue(attrName string) (string, bool)
+
+	// AssertAttributeValue returns an error unless the client has the
+	// attribute attrName with the value attrValue
+	AssertAttributeValue(attrName, attrValue string) error
+
+	// SatisfiesPrincipal tells whether the client satisfies principal
+	SatisfiesPrincipal(principal *common.MSPPrincipal) (bool, error)
+}
+
+type clientIdentity struct {
+	creator []byte
+	mspID   string
+	cert    *x509.Certificate
+	attrs   map[string]string
+}
+
+// New returns the identity of the client that submitted the transaction of
+// stub
+func New(stub shim.ChaincodeStubInterface) (ClientIdentity, error) {
+	creator, err := stub.GetCreator()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting the transaction creator: %s", err)
+	}
+	if len(creator) == 0 {
+		return nil, fmt.Errorf("The transaction has no creator")
+	}
+	return newClientIdentity(creator)
+}
+
+func newClientIdentity(creator []byte) (*clientIdentity, error) {
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, sID); err != nil {
+		return nil, fmt.Errorf("Could not deserialize a SerializedIdentity: %s", err)
+	}
+
+	bl, _ := pem.Decode(sID.IdBytes)
+	if bl == nil {
+		return nil, fmt.Errorf("Could not decode the PEM structure of the identity")
+	}
+	cert, err := x509.ParseCertificate(bl.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing the certificate of the identity: %s", err)
+	}
+
+	attrs, err := getAttributes(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientIdentity{creator: creator, mspID: sID.Mspid, cert: cert, attrs: attrs}, nil
+}
+
+func getAttributes(cert *x509.Certificate) (map[string]string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(AttributesOID) {
+			continue
+		}
+		a := &attributes{}
+		if err := json.Unmarshal(ext.Value, a); err != nil {
+			return nil, fmt.Errorf("Failed parsing the attributes of the identity: %s", err)
+		}
+		return a.Attrs, nil
+	}
+	return nil, nil
+}
+
+// AttributesExtension returns the X.509 extension carrying attrs, to be
+// added to the certificate of an identity when it is issued
+func AttributesExtension(attrs map[string]string) (pkix.Extension, error) {
+	value, err := json.Marshal(&attributes{Attrs: attrs})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: AttributesOID, Value: value}, nil
+}
+
+func (c *clientIdentity) GetID() string {
+	return fmt.Sprintf("x509::%s::%s", nameToString(c.cert.Subject), nameToString(c.cert.Issuer))
+}
+
+func (c *clientIdentity) GetMSPID() string {
+	return c.mspID
+}
+
+func (c *clientIdentity) GetX509Certificate() *x509.Certificate {
+	return c.cert
+}
+
+func (c *clientIdentity) GetSubject() pkix.Name {
+	return c.cert.Subject
+}
+
+func (c *clientIdentity) GetOUs() []string {
+	return c.cert.Subject.OrganizationalUnit
+}
+
+func (c *clientIdentity) GetAttributeValue(attrName string) (string, bool) {
+	value, ok := c.attrs[attrName]
+	return value, ok
+}
+
+func (c *clientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, ok := c.attrs[attrName]
+	if !ok {
+		return fmt.Errorf("Attribute '%s' was not found", attrName)
+	}
+	if value != attrValue {
+		return fmt.Errorf("Attribute '%s' equals '%s', not '%s'", attrName, value, attrValue)
+	}
+	return nil
+}
+
+// SatisfiesPrincipal only looks at the identity itself: the chaincode has no
+// access to the MSP configuration, so the certificate chain is not validated
+// (the endorser already did it) and admins cannot be told apart. A principal
+// with the admin role is reported as an error.
+func (c *clientIdentity) SatisfiesPrincipal(principal *common.MSPPrincipal) (bool, error) {
+	switch principal.PrincipalClassification {
+	case common.MSPPrincipal_ByMSPRole:
+		role := &common.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			return false, fmt.Errorf("Could not unmarshal MSPRole from principal: %s", err)
+		}
+		if role.Role != common.MSPRole_Member {
+			return false, fmt.Errorf("Role %s cannot be checked by chaincode", role.Role)
+		}
+		return role.MSPIdentifier == c.mspID, nil
+	case common.MSPPrincipal_ByOrganizationUnit:
+		ou := &common.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			return false, fmt.Errorf("Could not unmarshal OrganizationUnit from principal: %s", err)
+		}
+		if ou.MSPIdentifier != c.mspID {
+			return false, nil
+		}
+		for _, o := range c.GetOUs() {
+			if o == ou.OrganizationUnitIdentifier {
+				return true, nil
+			}
+		}
+		return false, nil
+	case common.MSPPrincipal_ByIdentity:
+		return bytes.Equal(principal.Principal, c.creator), nil
+	default:
+		return false, fmt.Errorf("Invalid principal classification %s", principal.PrincipalClassification)
+	}
+}
+
+// nameToString returns a string representation of name, with the same
+// attributes whatever their order in the certificate
+func nameToString(name pkix.Name) string {
+	s := fmt.Sprintf("CN=%s", name.CommonName)
+	for _, f := range []struct {
+		key    string
+		values []string
+	}{
+		{"OU", name.OrganizationalUnit},
+		{"O", name.Organization},
+		{"L", name.Locality},
+		{"ST", name.Province},
+		{"C", name.Country},
+	} {
+		for _, v := range f.values {
+			s += fmt.Sprintf(",%s=%s", f.key, v)
+		}
+	}
+	return s
+}
+
+// GetID returns the ID of the client that submitted the transaction of stub
+func GetID(stub shim.ChaincodeStubInterface) (string, error) {
+	c, err := New(stub)
+	if err != nil {
+		return "", err
+	}
+	return c.GetID(), nil
+}
+
+// GetMSPID returns the ID of the MSP of the client that submitted the
+// transaction of stub
+func GetMSPID(stub shim.ChaincodeStubInterface) (string, error) {
+	c, err := New(stub)
+	if err != nil {
+		return "", err
+	}
+	return c.GetMSPID(), nil
+}
+
+// GetAttributeValue returns the value of the attribute attrName of the client
+// that submitted the transaction of stub, and whether the client has it
+func GetAttributeValue(stub shim.ChaincodeStubInterface, attrName string) (string, bool, error) {
+	c, err := New(stub)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := c.GetAttributeValue(attrName)
+	return value, ok, nil
+}
+
+// AssertAttributeValue returns an error unless the client that submitted the
+// transaction of stub has the attribute attrName with the value attrValue
+func AssertAttributeValue(stub shim.ChaincodeStubInterface, attrName, attrValue string) error {
+	c, err := New(stub)
+	if err != nil {
+		return err
+	}
+	return c.AssertAttributeValue(attrName, attrValue)
+}
+
+// SatisfiesPrincipal tells whether the client that submitted the transaction
+// of stub satisfies principal
+func SatisfiesPrincipal(stub shim.ChaincodeStubInterface, principal *common.MSPPrincipal) (bool, error) {
+	c, err := New(stub)
+	if err != nil {
+		return false, err
+	}
+	return c.SatisfiesPrincipal(principal)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCreator(t *testing.T, mspID string, attrs map[string]string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:         "alice",
+			Organization:       []string{"Org1"},
+			OrganizationalUnit: []string{"audit", "sales"},
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(time.Hour),
+	}
+	if attrs != nil {
+		ext, err := AttributesExtension(attrs)
+		assert.NoError(t, err)
+		template.ExtraExtensions = []pkix.Extension{ext}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	assert.NoError(t, err)
+	return creator
+}
+
+func newStub(creator []byte) *shim.MockStub {
+	stub := shim.NewMockStub("cid", nil)
+	stub.SetCreator(creator)
+	return stub
+}
+
+func TestClientIdentity(t *testing.T) {
+	stub := newStub(createCreator(t, "Org1MSP", map[string]string{"role": "auditor"}))
+
+	c, err := New(stub)
+	assert.NoError(t, err)
+	assert.Equal(t, "Org1MSP", c.GetMSPID())
+	assert.Equal(t, "alice", c.GetSubject().CommonName)
+	assert.Equal(t, []string{"audit", "sales"}, c.GetOUs())
+	assert.Equal(t, "alice", c.GetX509Certificate().Subject.CommonName)
+	assert.Equal(t, "x509::CN=alice,OU=audit,OU=sales,O=Org1::CN=alice,OU=audit,OU=sales,O=Org1", c.GetID())
+
+	value, ok := c.GetAttributeValue("role")
+	assert.True(t, ok)
+	assert.Equal(t, "auditor", value)
+	_, ok = c.GetAttributeValue("missing")
+	assert.False(t, ok)
+
+	assert.NoError(t, c.AssertAttributeValue("role", "auditor"))
+	assert.Error(t, c.AssertAttributeValue("role", "admin"))
+	assert.Error(t, c.AssertAttributeValue("missing", "auditor"))
+
+	id, err := GetID(stub)
+	assert.NoError(t, err)
+	assert.Equal(t, c.GetID(), id)
+	mspID, err := GetMSPID(stub)
+	assert.NoError(t, err)
+	assert.Equal(t, "Org1MSP", mspID)
+	value, ok, err = GetAttributeValue(stub, "role")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "auditor", value)
+	assert.NoError(t, AssertAttributeValue(stub, "role", "auditor"))
+	assert.Error(t, AssertAttributeValue(stub, "role", "admin"))
+}
+
+func TestClientIdentityWithoutAttributes(t *testing.T) {
+	c, err := New(newStub(createCreator(t, "Org1MSP", nil)))
+	assert.NoError(t, err)
+	_, ok := c.GetAttributeValue("role")
+	assert.False(t, ok)
+	assert.Error(t, c.AssertAttributeValue("role", "auditor"))
+}
+
+func TestClientIdentityInvalidCreator(t *testing.T) {
+	_, err := New(newStub(nil))
+	assert.Error(t, err)
+
+	_, err = New(newStub([]byte("garbage")))
+	assert.Error(t, err)
+
+	creator, _ := proto.Marshal(&msp.SerializedIdentity{Mspid: "Org1MSP", IdBytes: []byte("not a PEM")})
+	_, err = New(newStub(creator))
+	assert.Error(t, err)
+
+	_, err = GetMSPID(newStub(nil))
+	assert.Error(t, err)
+}
+
+func principal(t *testing.T, classification common.MSPPrincipal_Classification, msg proto.Message) *common.MSPPrincipal {
+	bytes, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+	return &common.MSPPrincipal{PrincipalClassification: classification, Principal: bytes}
+}
+
+func TestSatisfiesPrincipal(t *testing.T) {
+	creator := createCreator(t, "Org1MSP", nil)
+	stub := newStub(creator)
+
+	ok, err := SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByMSPRole,
+		&common.MSPRole{MSPIdentifier: "Org1MSP", Role: common.MSPRole_Member}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByMSPRole,
+		&common.MSPRole{MSPIdentifier: "Org2MSP", Role: common.MSPRole_Member}))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByMSPRole,
+		&common.MSPRole{MSPIdentifier: "Org1MSP", Role: common.MSPRole_Admin}))
+	assert.Error(t, err)
+
+	ok, err = SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByOrganizationUnit,
+		&common.OrganizationUnit{MSPIdentifier: "Org1MSP", OrganizationUnitIdentifier: "audit"}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByOrganizationUnit,
+		&common.OrganizationUnit{MSPIdentifier: "Org1MSP", OrganizationUnitIdentifier: "hr"}))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = SatisfiesPrincipal(stub, principal(t, common.MSPPrincipal_ByOrganizationUnit,
+		&common.OrganizationUnit{MSPIdentifier: "Org2MSP", OrganizationUnitIdentifier: "audit"}))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = SatisfiesPrincipal(stub, &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ByIdentity, Principal: creator})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = SatisfiesPrincipal(stub, &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ByIdentity,
+		Principal: createCreator(t, "Org1MSP", nil)})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = SatisfiesPrincipal(stub, &common.MSPPrincipal{PrincipalClassification: 42})
+	assert.Error(t, err)
+
+	_, err = SatisfiesPrincipal(stub, &common.MSPPrincipal{PrincipalClassification: common.MSPPrincipal_ByMSPRole, Principal: []byte("garbage")})
+	assert.Error(t, err)
+}
@@ -88,22 +88,58 @@ func GetBCCSP(opts Opts) (bccsp.BCCSP, error) {
 	return getBCCSPInternal(opts)
 }
 
-func initFactories() error {
+// InitOpts configures the default BCCSP
+type InitOpts struct {
+	// ProviderName is the name of the factory of the default BCCSP,
+	// SoftwareBasedFactoryName if empty
+	ProviderName string
+	// KeyStorePath is the directory of the software KeyStore, which also
+	// keeps the keys a PKCS#11-based BCCSP handles in software; os.TempDir()
+	// if empty
+	KeyStorePath string
+	// KeyStorePassphrase encrypts the keys of the software KeyStore if not
+	// nil, otherwise they are encrypted with the passphrase in the
+	// sw.KeyStorePassphraseEnv environment variable, if set
+	KeyStorePassphrase []byte
+	// PKCS11 selects the token of the PKCS#11-based BCCSP
+	PKCS11 PKCS11Opts
+}
+
+// InitDefault initializes the default BCCSP according to opts. It must be
+// called before the default BCCSP is used, otherwise the default BCCSP is
+// software-based with the default InitOpts.
+func InitDefault(opts *InitOpts) error {
+	initialized := false
 	factoriesInitOnce.Do(func() {
-		// Initialize factories map
-		if factoriesInitError = initFactoriesMap(); factoriesInitError != nil {
-			return
-		}
+		initialized = true
+		factoriesInitError = initFactoriesInternal(opts)
+	})
+	if !initialized {
+		return errors.New("The default BCCSP is already initialized")
+	}
 
-		// Create default non-ephemeral (long-term) BCCSP
-		defaultBCCSP, factoriesInitError = createDefaultBCCSP()
-		if factoriesInitError != nil {
-			return
-		}
+	return factoriesInitError
+}
+
+func initFactories() error {
+	factoriesInitOnce.Do(func() {
+		factoriesInitError = initFactoriesInternal(&InitOpts{})
 	})
 	return factoriesInitError
 }
 
+func initFactoriesInternal(opts *InitOpts) error {
+	// Initialize factories map
+	if err := initFactoriesMap(); err != nil {
+		return err
+	}
+
+	// Create default non-ephemeral (long-term) BCCSP
+	var err error
+	defaultBCCSP, err = createDefaultBCCSP(opts)
+	return err
+}
+
 func initFactoriesMap() error {
 	factories = make(map[string]BCCSPFactory)
 
@@ -111,11 +147,50 @@ func initFactoriesMap() error {
 	f := &SWFactory{}
 	factories[f.Name()] = f
 
+	// PKCS#11-Based BCCSP, when built with the pkcs11 tag
+	if p11f := newPKCS11Factory(); p11f != nil {
+		factories[p11f.Name()] = p11f
+	}
+
 	return nil
 }
 
-func createDefaultBCCSP() (bccsp.BCCSP, error) {
-	return sw.NewDefaultSecurityLevel(os.TempDir())
+func createDefaultBCCSP(opts *InitOpts) (bccsp.BCCSP, error) {
+	keyStorePath := opts.KeyStorePath
+	if keyStorePath == "" {
+		keyStorePath = os.TempDir()
+	}
+
+	// The keys are encrypted when a passphrase is set in the environment
+	pwd := opts.KeyStorePassphrase
+	if pwd == nil {
+		var err error
+		if pwd, err = sw.LoadKeyStorePassphrase("", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(pwd, keyStorePath, false); err != nil {
+		return nil, fmt.Errorf("Failed initializing key store [%s]", err)
+	}
+
+	switch opts.ProviderName {
+	case "", SoftwareBasedFactoryName:
+		return sw.New(256, "SHA2", ks)
+	case PKCS11BasedFactoryName:
+		if _, ok := factories[PKCS11BasedFactoryName]; !ok {
+			return nil, fmt.Errorf("BCCSP provider [%s] is not available, it requires a build with the pkcs11 tag", opts.ProviderName)
+		}
+		p11Opts := opts.PKCS11
+		p11Opts.Ephemeral_ = false
+		p11Opts.SecLevel = 256
+		p11Opts.HashFamily = "SHA2"
+		p11Opts.KeyStore = ks
+		return getBCCSPInternal(&p11Opts)
+	default:
+		return nil, fmt.Errorf("Unknown BCCSP provider [%s]", opts.ProviderName)
+	}
 }
 
 func getBCCSPInternal(opts Opts) (bccsp.BCCSP, error) {
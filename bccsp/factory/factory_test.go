@@ -16,9 +16,11 @@ limitations under the License.
 package factory
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/sw"
 )
 
@@ -32,6 +34,55 @@ func TestGetDefault(t *testing.T) {
 	}
 }
 
+func TestInitDefaultAfterUse(t *testing.T) {
+	if _, err := GetDefault(); err != nil {
+		t.Fatalf("Failed getting default BCCSP [%s]", err)
+	}
+	if err := InitDefault(&InitOpts{KeyStorePath: os.TempDir()}); err == nil {
+		t.Fatal("InitDefault should fail once the default BCCSP is in use")
+	}
+}
+
+func TestCreateDefaultBCCSPWithKeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "bccspks")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir [%s]", err)
+	}
+	defer os.RemoveAll(path)
+
+	csp, err := createDefaultBCCSP(&InitOpts{KeyStorePath: path, KeyStorePassphrase: []byte("passwd")})
+	if err != nil {
+		t.Fatalf("Failed creating default BCCSP [%s]", err)
+	}
+	k, err := csp.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+
+	// The key is stored encrypted at the configured path
+	plainKs := &sw.FileBasedKeyStore{}
+	if err = plainKs.Init(nil, path, true); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+	if _, err = plainKs.GetKey(k.SKI()); err == nil {
+		t.Fatal("Getting an encrypted key without password should fail")
+	}
+
+	ks := &sw.FileBasedKeyStore{}
+	if err = ks.Init([]byte("passwd"), path, true); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+	if _, err = ks.GetKey(k.SKI()); err != nil {
+		t.Fatalf("Failed getting key [%s]", err)
+	}
+}
+
+func TestCreateDefaultBCCSPUnknownProvider(t *testing.T) {
+	if _, err := createDefaultBCCSP(&InitOpts{ProviderName: "Unknown"}); err == nil {
+		t.Fatal("Creating the default BCCSP with an unknown provider should fail")
+	}
+}
+
 func TestGetBCCPEphemeral(t *testing.T) {
 	ks := &sw.FileBasedKeyStore{}
 	if err := ks.Init(nil, os.TempDir(), false); err != nil {
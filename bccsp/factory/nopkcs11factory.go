@@ -0,0 +1,25 @@
+// +build !pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+// newPKCS11Factory returns nil as the PKCS#11-based BCCSP, which needs cgo,
+// is only built with the pkcs11 tag
+func newPKCS11Factory() BCCSPFactory {
+	return nil
+}
@@ -0,0 +1,34 @@
+// +build !pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"testing"
+)
+
+func TestCreateDefaultBCCSPWithoutPKCS11(t *testing.T) {
+	if err := initFactoriesMap(); err != nil {
+		t.Fatalf("Failed initializing factories [%s]", err)
+	}
+
+	_, err := createDefaultBCCSP(&InitOpts{ProviderName: PKCS11BasedFactoryName})
+	if err == nil {
+		t.Fatal("Selecting the PKCS11 provider should fail without the pkcs11 build tag")
+	}
+}
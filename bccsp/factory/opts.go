@@ -15,6 +15,12 @@ limitations under the License.
 */
 package factory
 
+import "github.com/hyperledger/fabric/bccsp"
+
+// PKCS11BasedFactoryName is the name of the factory of the PKCS#11-based BCCSP
+// implementation, only available in binaries built with the pkcs11 tag
+const PKCS11BasedFactoryName = "PKCS11"
+
 // DefaultOpts offers a default implementation for Opts
 type DefaultOpts struct {
 	ProviderName  string
@@ -30,3 +36,31 @@ func (o *DefaultOpts) FactoryName() string {
 func (o *DefaultOpts) Ephemeral() bool {
 	return o.EphemeralFlag
 }
+
+// PKCS11Opts contains options for the PKCS11Factory
+type PKCS11Opts struct {
+	Ephemeral_ bool
+	SecLevel   int
+	HashFamily string
+	// KeyStore keeps the keys handled in software, like imported ones
+	KeyStore bccsp.KeyStore
+
+	// Library is the path of the PKCS#11 library of the HSM
+	Library string
+	// Slot is the ID of the slot of the token, used if Label is empty
+	Slot uint
+	// Label is the label of the token
+	Label string
+	// Pin is the user PIN of the token
+	Pin string
+}
+
+// FactoryName returns the name of the provider
+func (o *PKCS11Opts) FactoryName() string {
+	return PKCS11BasedFactoryName
+}
+
+// Ephemeral returns true if the CSP has to be ephemeral, false otherwise
+func (o *PKCS11Opts) Ephemeral() bool {
+	return o.Ephemeral_
+}
@@ -0,0 +1,79 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/pkcs11"
+)
+
+// newPKCS11Factory returns the factory of the PKCS#11-based BCCSP
+func newPKCS11Factory() BCCSPFactory {
+	return &PKCS11Factory{}
+}
+
+// PKCS11Factory is the factory of the PKCS#11-based BCCSP.
+type PKCS11Factory struct {
+	initOnce sync.Once
+	bccsp    bccsp.BCCSP
+	err      error
+}
+
+// Name returns the name of this factory
+func (f *PKCS11Factory) Name() string {
+	return PKCS11BasedFactoryName
+}
+
+// Get returns an instance of BCCSP using Opts.
+func (f *PKCS11Factory) Get(opts Opts) (bccsp.BCCSP, error) {
+	// Validate arguments
+	if opts == nil {
+		return nil, errors.New("Invalid opts. It must not be nil.")
+	}
+
+	if opts.FactoryName() != f.Name() {
+		return nil, fmt.Errorf("Invalid Provider Name [%s]. Opts must refer to [%s].", opts.FactoryName(), f.Name())
+	}
+
+	p11Opts, ok := opts.(*PKCS11Opts)
+	if !ok {
+		return nil, errors.New("Invalid opts. They must be of type PKCS11Opts.")
+	}
+
+	tokenOpts := &pkcs11.TokenOpts{
+		Library: p11Opts.Library,
+		Slot:    p11Opts.Slot,
+		Label:   p11Opts.Label,
+		Pin:     p11Opts.Pin,
+	}
+
+	if !opts.Ephemeral() {
+		f.initOnce.Do(func() {
+			f.bccsp, f.err = pkcs11.New(p11Opts.SecLevel, p11Opts.HashFamily, p11Opts.KeyStore, tokenOpts)
+			return
+		})
+		return f.bccsp, f.err
+	}
+
+	return pkcs11.New(p11Opts.SecLevel, p11Opts.HashFamily, p11Opts.KeyStore, tokenOpts)
+}
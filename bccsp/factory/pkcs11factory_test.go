@@ -0,0 +1,48 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+func TestGetPKCS11InvalidOpts(t *testing.T) {
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(nil, os.TempDir(), false); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+
+	_, err := GetBCCSP(&PKCS11Opts{Ephemeral_: true, SecLevel: 256, HashFamily: "SHA2", KeyStore: ks})
+	if err == nil {
+		t.Fatal("Getting a PKCS11 BCCSP without library should fail")
+	}
+
+	_, err = GetBCCSP(&PKCS11Opts{Ephemeral_: true, SecLevel: 256, HashFamily: "SHA2", KeyStore: ks, Library: "/no/such/library.so"})
+	if err == nil {
+		t.Fatal("Getting a PKCS11 BCCSP with a missing library should fail")
+	}
+
+	_, err = (&PKCS11Factory{}).Get(&SwOpts{Ephemeral_: true, SecLevel: 256, HashFamily: "SHA2", KeyStore: ks})
+	if err == nil {
+		t.Fatal("The PKCS11 factory should reject SwOpts")
+	}
+}
@@ -0,0 +1,87 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+)
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+)
+
+type config struct {
+	securityLevel int
+	hashFamily    string
+
+	ellipticCurve elliptic.Curve
+	aesBitLength  int
+}
+
+func (conf *config) setSecurityLevel(securityLevel int, hashFamily string) error {
+	// SHA3 digests are always computed in software
+	if hashFamily != "SHA2" && hashFamily != "SHA3" {
+		return fmt.Errorf("Hash Family not supported [%s]", hashFamily)
+	}
+
+	switch securityLevel {
+	case 256:
+		conf.ellipticCurve = elliptic.P256()
+		conf.aesBitLength = 32
+	case 384:
+		conf.ellipticCurve = elliptic.P384()
+		conf.aesBitLength = 32
+	default:
+		return fmt.Errorf("Security level not supported [%d]", securityLevel)
+	}
+
+	conf.securityLevel = securityLevel
+	conf.hashFamily = hashFamily
+	return nil
+}
+
+// namedCurveFromOID returns the curve of a DER encoded CKA_EC_PARAMS
+func namedCurveFromOID(params []byte) (elliptic.Curve, error) {
+	oid := asn1.ObjectIdentifier{}
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling curve parameters [%s]", err)
+	}
+
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		return elliptic.P256(), nil
+	case oid.Equal(oidNamedCurveP384):
+		return elliptic.P384(), nil
+	}
+	return nil, fmt.Errorf("Curve not supported [%s]", oid)
+}
+
+// oidFromNamedCurve returns the OID of curve, to be set as CKA_EC_PARAMS
+func oidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, error) {
+	switch curve {
+	case elliptic.P256():
+		return oidNamedCurveP256, nil
+	case elliptic.P384():
+		return oidNamedCurveP384, nil
+	}
+	return nil, fmt.Errorf("Curve not supported [%s]", curve.Params().Name)
+}
@@ -0,0 +1,337 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/miekg/pkcs11"
+	"github.com/op/go-logging"
+)
+
+var (
+	logger = logging.MustGetLogger("PKCS11_BCCSP")
+)
+
+// New returns a new instance of the PKCS#11-based BCCSP set at the passed
+// security level and hash family, keeping its keys in the token selected
+// by tokenOpts. Keys that are not generated on the token, like imported
+// keys, are handled by a software-based BCCSP using keyStore.
+func New(securityLevel int, hashFamily string, keyStore bccsp.KeyStore, tokenOpts *TokenOpts) (bccsp.BCCSP, error) {
+	// Init config
+	conf := &config{}
+	err := conf.setSecurityLevel(securityLevel, hashFamily)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing configuration [%s]", err)
+	}
+
+	// Software fallback
+	swCSP, err := sw.New(securityLevel, hashFamily, keyStore)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing fallback SW BCCSP [%s]", err)
+	}
+
+	t, err := openToken(tokenOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing token [%s]", err)
+	}
+
+	return &impl{conf: conf, sw: swCSP, token: t}, nil
+}
+
+// impl is the PKCS#11-based implementation of the BCCSP.
+type impl struct {
+	conf  *config
+	sw    bccsp.BCCSP
+	token *token
+}
+
+// KeyGen generates a key using opts.
+func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
+	// Validate arguments
+	if opts == nil {
+		return nil, errors.New("Invalid Opts parameter. It must not be nil.")
+	}
+
+	// Parse algorithm
+	switch opts.(type) {
+	case *bccsp.ECDSAKeyGenOpts:
+		return csp.generateECKey(csp.conf.ellipticCurve, opts.Ephemeral())
+
+	case *bccsp.ECDSAP256KeyGenOpts:
+		return csp.generateECKey(elliptic.P256(), opts.Ephemeral())
+
+	case *bccsp.ECDSAP384KeyGenOpts:
+		return csp.generateECKey(elliptic.P384(), opts.Ephemeral())
+
+	case *bccsp.AESKeyGenOpts:
+		return csp.generateAESKey(csp.conf.aesBitLength, opts)
+
+	case *bccsp.AES256KeyGenOpts:
+		return csp.generateAESKey(32, opts)
+
+	case *bccsp.AES192KeyGenOpts:
+		return csp.generateAESKey(24, opts)
+
+	case *bccsp.AES128KeyGenOpts:
+		return csp.generateAESKey(16, opts)
+
+	default:
+		return csp.sw.KeyGen(opts)
+	}
+}
+
+func (csp *impl) generateECKey(curve elliptic.Curve, ephemeral bool) (bccsp.Key, error) {
+	ski, pub, err := csp.token.generateECKey(curve, ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating ECDSA %s key [%s]", curve.Params().Name, err)
+	}
+
+	return &ecdsaPrivateKey{ski, ecdsaPublicKey{ski, pub}}, nil
+}
+
+func (csp *impl) generateAESKey(length int, opts bccsp.KeyGenOpts) (bccsp.Key, error) {
+	if !csp.token.supports(pkcs11.CKM_AES_KEY_GEN) || !csp.token.supports(pkcs11.CKM_AES_CBC_PAD) {
+		logger.Debugf("AES not supported by the token, falling back to software")
+		return csp.sw.KeyGen(opts)
+	}
+
+	ski, err := csp.token.generateAESKey(length, opts.Ephemeral())
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating AES %d key [%s]", length*8, err)
+	}
+
+	return &aesPrivateKey{ski}, nil
+}
+
+// KeyDeriv derives a key from k using opts.
+// The opts argument should be appropriate for the primitive used.
+func (csp *impl) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	switch k.(type) {
+	case *ecdsaPrivateKey, *ecdsaPublicKey, *aesPrivateKey:
+		return nil, errors.New("Key derivation is not supported for keys held by the token")
+	default:
+		return csp.sw.KeyDeriv(k, opts)
+	}
+}
+
+// KeyImport imports a key from its raw representation using opts.
+// The opts argument should be appropriate for the primitive used.
+// Imported keys are handled in software.
+func (csp *impl) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (k bccsp.Key, err error) {
+	return csp.sw.KeyImport(raw, opts)
+}
+
+// GetKey returns the key this CSP associates to
+// the Subject Key Identifier ski.
+func (csp *impl) GetKey(ski []byte) (k bccsp.Key, err error) {
+	if len(ski) == 0 {
+		return nil, errors.New("Invalid SKI. Cannot be of zero length.")
+	}
+
+	if pub, err := csp.token.ecPublicKey(ski); err == nil {
+		if csp.token.hasObject(pkcs11.CKO_PRIVATE_KEY, ski) {
+			return &ecdsaPrivateKey{ski, ecdsaPublicKey{ski, pub}}, nil
+		}
+		return &ecdsaPublicKey{ski, pub}, nil
+	}
+
+	if csp.token.hasObject(pkcs11.CKO_SECRET_KEY, ski) {
+		return &aesPrivateKey{ski}, nil
+	}
+
+	return csp.sw.GetKey(ski)
+}
+
+// hashMechanism returns the token mechanism for opts, or false if the hash
+// has to be computed in software
+func (csp *impl) hashMechanism(opts bccsp.HashOpts) (uint, bool) {
+	var mechanism uint
+	switch opts.(type) {
+	case nil, *bccsp.SHAOpts:
+		if csp.conf.hashFamily != "SHA2" {
+			return 0, false
+		}
+		mechanism = pkcs11.CKM_SHA256
+		if csp.conf.securityLevel == 384 {
+			mechanism = pkcs11.CKM_SHA384
+		}
+	case *bccsp.SHA256Opts:
+		mechanism = pkcs11.CKM_SHA256
+	case *bccsp.SHA384Opts:
+		mechanism = pkcs11.CKM_SHA384
+	default:
+		return 0, false
+	}
+
+	return mechanism, csp.token.supports(mechanism)
+}
+
+// Hash hashes messages msg using options opts.
+// SHA2 digests are computed by the token if it supports them, the others in
+// software.
+func (csp *impl) Hash(msg []byte, opts bccsp.HashOpts) (digest []byte, err error) {
+	mechanism, ok := csp.hashMechanism(opts)
+	if !ok {
+		return csp.sw.Hash(msg, opts)
+	}
+
+	return csp.token.digest(mechanism, msg)
+}
+
+// GetHash returns and instance of hash.Hash using options opts.
+// If opts is nil then the default hash function is returned.
+// The hash is always computed in software.
+func (csp *impl) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
+	return csp.sw.GetHash(opts)
+}
+
+// Sign signs digest using key k.
+// The opts argument should be appropriate for the primitive used.
+//
+// Note that when a signature of a hash of a larger message is needed,
+// the caller is responsible for hashing the larger message and passing
+// the hash (as digest).
+func (csp *impl) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("Invalid digest. Cannot be empty.")
+	}
+
+	// Check key type
+	switch k.(type) {
+	case *ecdsaPrivateKey:
+		sk := k.(*ecdsaPrivateKey)
+		r, s, err := csp.token.signECDSA(sk.ski, digest)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating ECDSA signature [%s]", err)
+		}
+
+		return utils.MarshalECDSASignature(r, utils.ToLowS(sk.pub.pubKey, s))
+	case *aesPrivateKey:
+		return nil, fmt.Errorf("Key type not recognized [%s]", k)
+	default:
+		return csp.sw.Sign(k, digest, opts)
+	}
+}
+
+// Verify verifies signature against key k and digest
+func (csp *impl) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	// Validate arguments
+	if k == nil {
+		return false, errors.New("Invalid Key. It must not be nil.")
+	}
+	if len(signature) == 0 {
+		return false, errors.New("Invalid signature. Cannot be empty.")
+	}
+	if len(digest) == 0 {
+		return false, errors.New("Invalid digest. Cannot be empty.")
+	}
+
+	// Check key type
+	switch k.(type) {
+	case *ecdsaPrivateKey:
+		return csp.verifyECDSA(&k.(*ecdsaPrivateKey).pub, signature, digest)
+	case *ecdsaPublicKey:
+		return csp.verifyECDSA(k.(*ecdsaPublicKey), signature, digest)
+	case *aesPrivateKey:
+		return false, fmt.Errorf("Key type not recognized [%s]", k)
+	default:
+		return csp.sw.Verify(k, signature, digest, opts)
+	}
+}
+
+func (csp *impl) verifyECDSA(k *ecdsaPublicKey, signature, digest []byte) (bool, error) {
+	r, s, err := utils.UnmarshalECDSASignature(signature)
+	if err != nil {
+		return false, err
+	}
+	if utils.IsLowSOnly() && !utils.IsLowS(k.pubKey, s) {
+		return false, fmt.Errorf("Invalid S. Must be smaller than half the order [%s].", s)
+	}
+
+	byteSize := (k.pubKey.Curve.Params().BitSize + 7) / 8
+	return csp.token.verifyECDSA(k.ski, digest, r, s, byteSize)
+}
+
+// Encrypt encrypts plaintext using key k.
+// The opts argument should be appropriate for the primitive used.
+func (csp *impl) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (ciphertext []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	// Check key type
+	switch k.(type) {
+	case *aesPrivateKey:
+		// check for mode
+		switch opts.(type) {
+		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
+			// AES in CBC mode with PKCS7 padding
+			return csp.token.encryptAES(k.SKI(), plaintext)
+		default:
+			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+		}
+	case *ecdsaPrivateKey, *ecdsaPublicKey:
+		return nil, fmt.Errorf("Key type not recognized [%s]", k)
+	default:
+		return csp.sw.Encrypt(k, plaintext, opts)
+	}
+}
+
+// Decrypt decrypts ciphertext using key k.
+// The opts argument should be appropriate for the primitive used.
+func (csp *impl) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (plaintext []byte, err error) {
+	// Validate arguments
+	if k == nil {
+		return nil, errors.New("Invalid Key. It must not be nil.")
+	}
+
+	// Check key type
+	switch k.(type) {
+	case *aesPrivateKey:
+		// check for mode
+		switch opts.(type) {
+		case *bccsp.AESCBCPKCS7ModeOpts, bccsp.AESCBCPKCS7ModeOpts:
+			// AES in CBC mode with PKCS7 padding
+			return csp.token.decryptAES(k.SKI(), ciphertext)
+		default:
+			return nil, fmt.Errorf("Mode not recognized [%s]", opts)
+		}
+	case *ecdsaPrivateKey, *ecdsaPublicKey:
+		return nil, fmt.Errorf("Key type not recognized [%s]", k)
+	default:
+		return csp.sw.Decrypt(k, ciphertext, opts)
+	}
+}
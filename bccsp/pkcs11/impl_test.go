@@ -0,0 +1,460 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// The tests run against SoftHSM. A token can be set up with
+//
+//	softhsm2-util --init-token --slot 0 --label "ForFabric" --so-pin 1234 --pin 98765432
+//
+// PKCS11_LIB, PKCS11_LABEL and PKCS11_PIN override the library, label and PIN.
+// The tests needing a token are skipped if the library is not found.
+
+var (
+	currentBCCSP bccsp.BCCSP
+	tokenOpts    *TokenOpts
+)
+
+func findSoftHSM() string {
+	if lib := os.Getenv("PKCS11_LIB"); lib != "" {
+		return lib
+	}
+
+	for _, lib := range []string{
+		"/usr/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+		"/usr/local/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/s390x-linux-gnu/softhsm/libsofthsm2.so",
+		"/usr/lib/powerpc64le-linux-gnu/softhsm/libsofthsm2.so",
+	} {
+		if _, err := os.Stat(lib); err == nil {
+			return lib
+		}
+	}
+	return ""
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func TestMain(m *testing.M) {
+	lib := findSoftHSM()
+	if lib == "" {
+		fmt.Println("SoftHSM not found, skipping the tests needing a token")
+		os.Exit(m.Run())
+	}
+
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(nil, os.TempDir(), false); err != nil {
+		fmt.Printf("Failed initializing key store [%s]\n", err)
+		os.Exit(-1)
+	}
+
+	tokenOpts = &TokenOpts{
+		Library: lib,
+		Label:   envOr("PKCS11_LABEL", "ForFabric"),
+		Pin:     envOr("PKCS11_PIN", "98765432"),
+	}
+	var err error
+	currentBCCSP, err = New(256, "SHA2", ks, tokenOpts)
+	if err != nil {
+		fmt.Printf("Failed initializing PKCS11 BCCSP [%s]\n", err)
+		os.Exit(-1)
+	}
+
+	os.Exit(m.Run())
+}
+
+func requireToken(t *testing.T) {
+	if currentBCCSP == nil {
+		t.Skip("SoftHSM not found")
+	}
+}
+
+func TestCurveOIDs(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		oid, err := oidFromNamedCurve(curve)
+		if err != nil {
+			t.Fatalf("Failed getting OID of %s [%s]", curve.Params().Name, err)
+		}
+		params, _ := utils.MarshalECDSASignature(big.NewInt(1), big.NewInt(1))
+		if _, err = namedCurveFromOID(params); err == nil {
+			t.Fatal("Parsing a non OID should fail")
+		}
+		params, err = asn1.Marshal(oid)
+		if err != nil {
+			t.Fatalf("Failed marshalling OID [%s]", err)
+		}
+		back, err := namedCurveFromOID(params)
+		if err != nil {
+			t.Fatalf("Failed parsing OID of %s [%s]", curve.Params().Name, err)
+		}
+		if back != curve {
+			t.Fatalf("Expected curve %s, got %s", curve.Params().Name, back.Params().Name)
+		}
+	}
+
+	if _, err := oidFromNamedCurve(elliptic.P224()); err == nil {
+		t.Fatal("P-224 should not be supported")
+	}
+}
+
+func TestInvalidTokenOpts(t *testing.T) {
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(nil, os.TempDir(), false); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+
+	if _, err := New(256, "SHA2", ks, nil); err == nil {
+		t.Fatal("New should fail without token options")
+	}
+	if _, err := New(256, "SHA2", ks, &TokenOpts{Library: "/no/such/library.so"}); err == nil {
+		t.Fatal("New should fail with a missing library")
+	}
+	if _, err := New(128, "SHA2", ks, &TokenOpts{Library: "/no/such/library.so"}); err == nil {
+		t.Fatal("New should fail with an invalid security level")
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	requireToken(t)
+
+	ks := &sw.FileBasedKeyStore{}
+	if err := ks.Init(nil, os.TempDir(), false); err != nil {
+		t.Fatalf("Failed initializing key store [%s]", err)
+	}
+
+	opts := *tokenOpts
+	opts.Label = "NoSuchLabel"
+	if _, err := New(256, "SHA2", ks, &opts); err == nil {
+		t.Fatal("New should fail with an unknown label")
+	}
+}
+
+func TestECDSAKeyGenSignVerify(t *testing.T) {
+	requireToken(t)
+
+	for _, opts := range []bccsp.KeyGenOpts{
+		&bccsp.ECDSAKeyGenOpts{Temporary: true},
+		&bccsp.ECDSAP256KeyGenOpts{Temporary: true},
+		&bccsp.ECDSAP384KeyGenOpts{Temporary: true},
+	} {
+		k, err := currentBCCSP.KeyGen(opts)
+		if err != nil {
+			t.Fatalf("Failed generating ECDSA key [%s]", err)
+		}
+		if !k.Private() || k.Symmetric() {
+			t.Fatal("Failed generating ECDSA key. Key should be private and asymmetric.")
+		}
+		if _, err = k.Bytes(); err == nil {
+			t.Fatal("The private key should not be exportable")
+		}
+
+		digest, err := currentBCCSP.Hash([]byte("Hello World"), &bccsp.SHAOpts{})
+		if err != nil {
+			t.Fatalf("Failed computing HASH [%s]", err)
+		}
+
+		signature, err := currentBCCSP.Sign(k, digest, nil)
+		if err != nil {
+			t.Fatalf("Failed generating ECDSA signature [%s]", err)
+		}
+
+		valid, err := currentBCCSP.Verify(k, signature, digest, nil)
+		if err != nil {
+			t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+		}
+		if !valid {
+			t.Fatal("Failed verifying ECDSA signature. Signature not valid.")
+		}
+
+		pk, err := k.PublicKey()
+		if err != nil {
+			t.Fatalf("Failed getting corresponding public key [%s]", err)
+		}
+		valid, err = currentBCCSP.Verify(pk, signature, digest, nil)
+		if err != nil {
+			t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+		}
+		if !valid {
+			t.Fatal("Failed verifying ECDSA signature. Signature not valid.")
+		}
+
+		// The signature must be verifiable in software, with a low S
+		raw, err := pk.Bytes()
+		if err != nil {
+			t.Fatalf("Failed marshalling public key [%s]", err)
+		}
+		swPK, err := currentBCCSP.KeyImport(raw, &bccsp.ECDSAPKIXPublicKeyImportOpts{Temporary: true})
+		if err != nil {
+			t.Fatalf("Failed importing public key [%s]", err)
+		}
+		if !bytes.Equal(swPK.SKI(), k.SKI()) {
+			t.Fatal("The SKI should be the one of the software-based BCCSP")
+		}
+		valid, err = currentBCCSP.Verify(swPK, signature, digest, nil)
+		if err != nil || !valid {
+			t.Fatalf("Failed verifying ECDSA signature in software [%s]", err)
+		}
+		_, s, err := utils.UnmarshalECDSASignature(signature)
+		if err != nil {
+			t.Fatalf("Failed unmarshalling signature [%s]", err)
+		}
+		if !utils.IsLowS(pk.(*ecdsaPublicKey).pubKey, s) {
+			t.Fatal("The signature should have a low S")
+		}
+
+		// A signature of another digest must not verify
+		digest[0] ^= 0xff
+		valid, err = currentBCCSP.Verify(k, signature, digest, nil)
+		if err != nil {
+			t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+		}
+		if valid {
+			t.Fatal("The signature should not be valid for another digest")
+		}
+	}
+}
+
+func TestECDSAGetKey(t *testing.T) {
+	requireToken(t)
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+
+	k2, err := currentBCCSP.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting key by SKI [%s]", err)
+	}
+	if !k2.Private() || !bytes.Equal(k2.SKI(), k.SKI()) {
+		t.Fatal("GetKey should have returned the private key")
+	}
+
+	digest := sha256.Sum256([]byte("Hello World"))
+	signature, err := currentBCCSP.Sign(k2, digest[:], nil)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA signature [%s]", err)
+	}
+	valid, err := currentBCCSP.Verify(k, signature, digest[:], nil)
+	if err != nil || !valid {
+		t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+	}
+
+	if _, err = currentBCCSP.GetKey([]byte("no such key")); err == nil {
+		t.Fatal("GetKey should fail for an unknown SKI")
+	}
+}
+
+func TestECDSALowS(t *testing.T) {
+	requireToken(t)
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	pub := k.(*ecdsaPrivateKey).pub.pubKey
+	digest := sha256.Sum256([]byte("Hello World"))
+
+	signature, err := currentBCCSP.Sign(k, digest[:], nil)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA signature [%s]", err)
+	}
+	r, s, _ := utils.UnmarshalECDSASignature(signature)
+	highSignature, _ := utils.MarshalECDSASignature(r, new(big.Int).Sub(pub.Params().N, s))
+
+	valid, err := currentBCCSP.Verify(k, highSignature, digest[:], nil)
+	if err != nil || !valid {
+		t.Fatalf("A high S must be accepted unless low S is enforced [%s]", err)
+	}
+
+	utils.SetLowSOnly(true)
+	defer utils.SetLowSOnly(false)
+	valid, err = currentBCCSP.Verify(k, highSignature, digest[:], nil)
+	if err == nil || valid {
+		t.Fatal("A high S must be rejected when low S is enforced")
+	}
+}
+
+func TestAESEncryptDecrypt(t *testing.T) {
+	requireToken(t)
+
+	for _, opts := range []bccsp.KeyGenOpts{
+		&bccsp.AESKeyGenOpts{Temporary: true},
+		&bccsp.AES128KeyGenOpts{Temporary: true},
+		&bccsp.AES192KeyGenOpts{Temporary: true},
+	} {
+		k, err := currentBCCSP.KeyGen(opts)
+		if err != nil {
+			t.Fatalf("Failed generating AES key [%s]", err)
+		}
+		if !k.Symmetric() {
+			t.Fatal("Failed generating AES key. Key should be symmetric.")
+		}
+
+		msg := []byte("Hello World, this is more than a block")
+		ct, err := currentBCCSP.Encrypt(k, msg, &bccsp.AESCBCPKCS7ModeOpts{})
+		if err != nil {
+			t.Fatalf("Failed encrypting [%s]", err)
+		}
+		pt, err := currentBCCSP.Decrypt(k, ct, &bccsp.AESCBCPKCS7ModeOpts{})
+		if err != nil {
+			t.Fatalf("Failed decrypting [%s]", err)
+		}
+		if !bytes.Equal(msg, pt) {
+			t.Fatal("Failed decrypting. Decrypted plaintext is different from the original.")
+		}
+
+		if _, err = currentBCCSP.Encrypt(k, msg, nil); err == nil {
+			t.Fatal("Encrypting without a mode should fail")
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	requireToken(t)
+
+	msg := []byte("Hello World")
+	sha256Digest := sha256.Sum256(msg)
+	sha384Digest := sha512.Sum384(msg)
+
+	for _, c := range []struct {
+		opts     bccsp.HashOpts
+		expected []byte
+	}{
+		{nil, sha256Digest[:]},
+		{&bccsp.SHAOpts{}, sha256Digest[:]},
+		{&bccsp.SHA256Opts{}, sha256Digest[:]},
+		{&bccsp.SHA384Opts{}, sha384Digest[:]},
+	} {
+		digest, err := currentBCCSP.Hash(msg, c.opts)
+		if err != nil {
+			t.Fatalf("Failed computing HASH [%s]", err)
+		}
+		if !bytes.Equal(digest, c.expected) {
+			t.Fatalf("Unexpected digest for %v", c.opts)
+		}
+	}
+
+	// SHA3 has no PKCS#11 mechanism and is computed in software
+	digest, err := currentBCCSP.Hash(msg, &bccsp.SHA3_256Opts{})
+	if err != nil || len(digest) != 32 {
+		t.Fatalf("Failed computing SHA3 HASH in software [%s]", err)
+	}
+
+	h, err := currentBCCSP.GetHash(&bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("Failed getting hash.Hash [%s]", err)
+	}
+	h.Write(msg)
+	if !bytes.Equal(h.Sum(nil), sha256Digest[:]) {
+		t.Fatal("Unexpected digest from hash.Hash")
+	}
+}
+
+func TestSoftwareFallback(t *testing.T) {
+	requireToken(t)
+
+	// RSA keys are not generated on the token
+	k, err := currentBCCSP.KeyGen(&bccsp.RSA1024KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating RSA key in software [%s]", err)
+	}
+	if _, ok := k.(*ecdsaPrivateKey); ok {
+		t.Fatal("An RSA key should not be held by the token")
+	}
+
+	// Imported keys are handled in software
+	if _, err = currentBCCSP.KeyImport([]byte("not a key"), &bccsp.ECDSAPKIXPublicKeyImportOpts{Temporary: true}); err == nil {
+		t.Fatal("Importing an invalid key should fail")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	pk, err := currentBCCSP.KeyImport(&key.PublicKey, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed importing ECDSA public key [%s]", err)
+	}
+	if _, ok := pk.(*ecdsaPublicKey); ok {
+		t.Fatal("An imported key should not be held by the token")
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	requireToken(t)
+
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	digest := sha256.Sum256([]byte("Hello World"))
+
+	// More signers than cached sessions, so that some sessions are closed
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*sessionCacheSize)
+	for i := 0; i < 3*sessionCacheSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			signature, err := currentBCCSP.Sign(k, digest[:], nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			valid, err := currentBCCSP.Verify(k, signature, digest[:], nil)
+			if err != nil || !valid {
+				errs <- fmt.Errorf("Invalid signature [%v]", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed signing concurrently [%s]", err)
+	}
+
+	// The ephemeral key must have survived the closed sessions
+	if _, err = currentBCCSP.Sign(k, digest[:], nil); err != nil {
+		t.Fatalf("Failed signing after concurrent use [%s]", err)
+	}
+}
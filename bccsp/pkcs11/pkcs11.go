@@ -0,0 +1,512 @@
+// +build pkcs11
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// sessionCacheSize is the number of idle sessions kept open
+const sessionCacheSize = 10
+
+// TokenOpts selects the token the BCCSP keeps its keys in
+type TokenOpts struct {
+	// Library is the path of the PKCS#11 library of the HSM
+	Library string
+	// Slot is the ID of the slot of the token, used if Label is empty
+	Slot uint
+	// Label is the label of the token
+	Label string
+	// Pin is the user PIN of the token
+	Pin string
+}
+
+// token is a slot of a PKCS#11 library the user is logged in to
+type token struct {
+	ctx  *pkcs11.Ctx
+	slot uint
+
+	// sessions holds the idle sessions
+	sessions chan pkcs11.SessionHandle
+
+	// objSession is the session objects are created with. Session objects
+	// live as long as the session that created it, so it is never closed.
+	objSession pkcs11.SessionHandle
+	objLock    sync.Mutex
+
+	mechanisms map[uint]bool
+}
+
+func openToken(opts *TokenOpts) (*token, error) {
+	if opts == nil || opts.Library == "" {
+		return nil, errors.New("Invalid TokenOpts. The PKCS#11 library must be set.")
+	}
+
+	ctx := pkcs11.New(opts.Library)
+	if ctx == nil {
+		return nil, fmt.Errorf("Failed loading PKCS#11 library [%s]", opts.Library)
+	}
+	if err := ctx.Initialize(); err != nil && err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, fmt.Errorf("Failed initializing PKCS#11 library [%s]", err)
+	}
+
+	slot, err := findSlot(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &token{ctx: ctx, slot: slot, sessions: make(chan pkcs11.SessionHandle, sessionCacheSize)}
+
+	t.objSession, err = t.openSession()
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.Login(t.objSession, pkcs11.CKU_USER, opts.Pin)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return nil, fmt.Errorf("Failed logging in to the token [%s]", err)
+	}
+
+	mechanisms, err := ctx.GetMechanismList(slot)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing the mechanisms of the token [%s]", err)
+	}
+	t.mechanisms = make(map[uint]bool)
+	for _, m := range mechanisms {
+		t.mechanisms[m.Mechanism] = true
+	}
+
+	return t, nil
+}
+
+// findSlot returns the slot of the token labelled opts.Label, or opts.Slot
+// if no label is given
+func findSlot(ctx *pkcs11.Ctx, opts *TokenOpts) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("Failed listing the slots [%s]", err)
+	}
+
+	for _, slot := range slots {
+		if opts.Label == "" {
+			if slot == opts.Slot {
+				return slot, nil
+			}
+			continue
+		}
+
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == opts.Label {
+			return slot, nil
+		}
+	}
+
+	if opts.Label != "" {
+		return 0, fmt.Errorf("Token with label [%s] not found", opts.Label)
+	}
+	return 0, fmt.Errorf("Slot [%d] not found or without token", opts.Slot)
+}
+
+func (t *token) openSession() (pkcs11.SessionHandle, error) {
+	s, err := t.ctx.OpenSession(t.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return 0, fmt.Errorf("Failed opening a session [%s]", err)
+	}
+	return s, nil
+}
+
+// getSession returns an idle session, or a new one if there is none
+func (t *token) getSession() (pkcs11.SessionHandle, error) {
+	select {
+	case s := <-t.sessions:
+		return s, nil
+	default:
+		return t.openSession()
+	}
+}
+
+// returnSession keeps s for later use, or closes it if enough sessions are
+// already idle
+func (t *token) returnSession(s pkcs11.SessionHandle) {
+	select {
+	case t.sessions <- s:
+	default:
+		t.ctx.CloseSession(s)
+	}
+}
+
+func (t *token) supports(mechanism uint) bool {
+	return t.mechanisms[mechanism]
+}
+
+// findObject returns the object of class whose CKA_ID is ski
+func (t *token) findObject(s pkcs11.SessionHandle, class uint, ski []byte) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+	}
+	if err := t.ctx.FindObjectsInit(s, template); err != nil {
+		return 0, fmt.Errorf("Failed looking for key [%s]", err)
+	}
+	objs, _, err := t.ctx.FindObjects(s, 1)
+	t.ctx.FindObjectsFinal(s)
+	if err != nil {
+		return 0, fmt.Errorf("Failed looking for key [%s]", err)
+	}
+	if len(objs) == 0 {
+		return 0, fmt.Errorf("Key not found [%x]", ski)
+	}
+
+	return objs[0], nil
+}
+
+// ecPoint returns the public key of the EC public key object o
+func (t *token) ecPoint(s pkcs11.SessionHandle, o pkcs11.ObjectHandle) (*ecdsa.PublicKey, error) {
+	attrs, err := t.ctx.GetAttributeValue(s, o, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading public key [%s]", err)
+	}
+
+	var point, params []byte
+	for _, a := range attrs {
+		switch a.Type {
+		case pkcs11.CKA_EC_POINT:
+			point = a.Value
+		case pkcs11.CKA_EC_PARAMS:
+			params = a.Value
+		}
+	}
+
+	curve, err := namedCurveFromOID(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The point is DER encoded as an OCTET STRING, but some tokens return
+	// it raw
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err != nil || len(rest) != 0 {
+		raw = point
+	}
+	x, y := elliptic.Unmarshal(curve, raw)
+	if x == nil {
+		return nil, errors.New("Failed unmarshalling EC point")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// ecdsaSKI returns the SKI of pub, computed as the software BCCSP does
+func ecdsaSKI(pub *ecdsa.PublicKey) []byte {
+	raw := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+
+	hash := sha256.New()
+	hash.Write(raw)
+	return hash.Sum(nil)
+}
+
+// generateECKey generates a key pair on curve and returns its SKI and
+// public key. Ephemeral keys are session objects, gone with the BCCSP.
+func (t *token) generateECKey(curve elliptic.Curve, ephemeral bool) ([]byte, *ecdsa.PublicKey, error) {
+	oid, err := oidFromNamedCurve(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, err := asn1.Marshal(oid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed marshalling curve OID [%s]", err)
+	}
+
+	t.objLock.Lock()
+	defer t.objLock.Unlock()
+	s := t.objSession
+
+	// The key is found by its SKI, which depends on the public key, so a
+	// random CKA_ID is set first and replaced once the key is generated
+	tmpID, err := t.ctx.GenerateRandom(s, 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed generating random ID [%s]", err)
+	}
+
+	pubTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, !ephemeral),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, tmpID),
+	}
+	privTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, !ephemeral),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, tmpID),
+	}
+
+	pubObj, privObj, err := t.ctx.GenerateKeyPair(s,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		pubTemplate, privTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed generating EC key pair [%s]", err)
+	}
+
+	pub, err := t.ecPoint(s, pubObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ski := ecdsaSKI(pub)
+	id := []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, ski)}
+	if err = t.ctx.SetAttributeValue(s, pubObj, id); err != nil {
+		return nil, nil, fmt.Errorf("Failed setting the SKI of the public key [%s]", err)
+	}
+	if err = t.ctx.SetAttributeValue(s, privObj, id); err != nil {
+		return nil, nil, fmt.Errorf("Failed setting the SKI of the private key [%s]", err)
+	}
+
+	return ski, pub, nil
+}
+
+// ecPublicKey returns the public key of the key pair whose SKI is ski
+func (t *token) ecPublicKey(ski []byte) (*ecdsa.PublicKey, error) {
+	s, err := t.getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer t.returnSession(s)
+
+	o, err := t.findObject(s, pkcs11.CKO_PUBLIC_KEY, ski)
+	if err != nil {
+		return nil, err
+	}
+	return t.ecPoint(s, o)
+}
+
+// signECDSA signs digest with the private key whose SKI is ski
+func (t *token) signECDSA(ski []byte, digest []byte) (*big.Int, *big.Int, error) {
+	s, err := t.getSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer t.returnSession(s)
+
+	o, err := t.findObject(s, pkcs11.CKO_PRIVATE_KEY, ski)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = t.ctx.SignInit(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, o)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed initializing signature [%s]", err)
+	}
+	sig, err := t.ctx.Sign(s, digest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed signing [%s]", err)
+	}
+
+	// The signature is R and S of the same length
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, nil, fmt.Errorf("Invalid signature length [%d]", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:len(sig)/2])
+	sv := new(big.Int).SetBytes(sig[len(sig)/2:])
+
+	return r, sv, nil
+}
+
+// verifyECDSA verifies r and s against digest with the public key whose
+// SKI is ski
+func (t *token) verifyECDSA(ski []byte, digest []byte, r, sv *big.Int, byteSize int) (bool, error) {
+	s, err := t.getSession()
+	if err != nil {
+		return false, err
+	}
+	defer t.returnSession(s)
+
+	o, err := t.findObject(s, pkcs11.CKO_PUBLIC_KEY, ski)
+	if err != nil {
+		return false, err
+	}
+
+	rBytes, sBytes := r.Bytes(), sv.Bytes()
+	if len(rBytes) > byteSize || len(sBytes) > byteSize {
+		return false, nil
+	}
+	sig := make([]byte, 2*byteSize)
+	copy(sig[byteSize-len(rBytes):byteSize], rBytes)
+	copy(sig[2*byteSize-len(sBytes):], sBytes)
+
+	err = t.ctx.VerifyInit(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, o)
+	if err != nil {
+		return false, fmt.Errorf("Failed initializing verification [%s]", err)
+	}
+	err = t.ctx.Verify(s, digest, sig)
+	if err == pkcs11.Error(pkcs11.CKR_SIGNATURE_INVALID) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Failed verifying [%s]", err)
+	}
+
+	return true, nil
+}
+
+// generateAESKey generates an AES key of length bytes and returns its SKI
+func (t *token) generateAESKey(length int, ephemeral bool) ([]byte, error) {
+	t.objLock.Lock()
+	defer t.objLock.Unlock()
+	s := t.objSession
+
+	seed, err := t.ctx.GenerateRandom(s, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating random ID [%s]", err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte{0x01})
+	hash.Write(seed)
+	ski := hash.Sum(nil)
+
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, length),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, !ephemeral),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+	}
+	_, err = t.ctx.GenerateKey(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_KEY_GEN, nil)}, template)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating AES key [%s]", err)
+	}
+
+	return ski, nil
+}
+
+// encryptAES encrypts plaintext in CBC mode with PKCS#7 padding with the key
+// whose SKI is ski. As with the software BCCSP, the IV is prepended to the
+// ciphertext.
+func (t *token) encryptAES(ski []byte, plaintext []byte) ([]byte, error) {
+	s, err := t.getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer t.returnSession(s)
+
+	o, err := t.findObject(s, pkcs11.CKO_SECRET_KEY, ski)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := t.ctx.GenerateRandom(s, 16)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating IV [%s]", err)
+	}
+	err = t.ctx.EncryptInit(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_CBC_PAD, iv)}, o)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing encryption [%s]", err)
+	}
+	ciphertext, err := t.ctx.Encrypt(s, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("Failed encrypting [%s]", err)
+	}
+
+	return append(iv, ciphertext...), nil
+}
+
+// decryptAES decrypts what encryptAES returned
+func (t *token) decryptAES(ski []byte, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 16 {
+		return nil, errors.New("Invalid ciphertext. It must include the IV.")
+	}
+
+	s, err := t.getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer t.returnSession(s)
+
+	o, err := t.findObject(s, pkcs11.CKO_SECRET_KEY, ski)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.ctx.DecryptInit(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_CBC_PAD, ciphertext[:16])}, o)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing decryption [%s]", err)
+	}
+	plaintext, err := t.ctx.Decrypt(s, ciphertext[16:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed decrypting [%s]", err)
+	}
+
+	return plaintext, nil
+}
+
+// digest hashes msg with mechanism
+func (t *token) digest(mechanism uint, msg []byte) ([]byte, error) {
+	s, err := t.getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer t.returnSession(s)
+
+	if err = t.ctx.DigestInit(s, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}); err != nil {
+		return nil, fmt.Errorf("Failed initializing digest [%s]", err)
+	}
+	digest, err := t.ctx.Digest(s, msg)
+	if err != nil {
+		return nil, fmt.Errorf("Failed computing digest [%s]", err)
+	}
+
+	return digest, nil
+}
+
+// hasObject returns true if there is an object of class whose CKA_ID is ski
+func (t *token) hasObject(class uint, ski []byte) bool {
+	s, err := t.getSession()
+	if err != nil {
+		return false
+	}
+	defer t.returnSession(s)
+
+	_, err = t.findObject(s, class, ski)
+	return err == nil
+}
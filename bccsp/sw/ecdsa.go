@@ -15,9 +15,35 @@ limitations under the License.
 */
 package sw
 
-import "math/big"
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
 
-// ECDSASignature represents an ECDSA signature
-type ecdsaSignature struct {
-	R, S *big.Int
+	"github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// signECDSA signs digest with k, normalizing S to the lower half of the
+// curve order so that the signature is not malleable
+func signECDSA(k *ecdsa.PrivateKey, digest []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.MarshalECDSASignature(r, utils.ToLowS(&k.PublicKey, s))
+}
+
+// verifyECDSA verifies signature against k and digest. Signatures with a
+// high S are rejected if utils.IsLowSOnly is set
+func verifyECDSA(k *ecdsa.PublicKey, signature, digest []byte) (bool, error) {
+	r, s, err := utils.UnmarshalECDSASignature(signature)
+	if err != nil {
+		return false, err
+	}
+	if utils.IsLowSOnly() && !utils.IsLowS(k, s) {
+		return false, fmt.Errorf("Invalid S. Must be smaller than half the order [%s].", s)
+	}
+
+	return ecdsa.Verify(k, digest, r, s), nil
 }
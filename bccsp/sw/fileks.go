@@ -212,6 +212,80 @@ func (ks *FileBasedKeyStore) StoreKey(k bccsp.Key) (err error) {
 	return
 }
 
+// Rotate re-encrypts all the keys of this KeyStore with the password newPwd,
+// which then becomes the password of the KeyStore. The keys are all loaded
+// with the current password before any file is rewritten, so that a wrong
+// password or a corrupted file leaves the KeyStore untouched. Keys stored in
+// clear get encrypted, and a nil newPwd stores all the keys in clear.
+// If this KeyStore is read only then the method will fail.
+func (ks *FileBasedKeyStore) Rotate(newPwd []byte) error {
+	if ks.readOnly {
+		return errors.New("Read only KeyStore.")
+	}
+
+	ks.m.Lock()
+	defer ks.m.Unlock()
+
+	files, err := ioutil.ReadDir(ks.path)
+	if err != nil {
+		return fmt.Errorf("Failed reading KeyStore [%s]", err)
+	}
+
+	rotated := make(map[string][]byte)
+	for _, f := range files {
+		i := strings.LastIndex(f.Name(), "_")
+		if f.IsDir() || i < 0 {
+			continue
+		}
+		alias, suffix := f.Name()[:i], f.Name()[i+1:]
+
+		var raw []byte
+		switch suffix {
+		case "sk":
+			var key interface{}
+			if key, err = ks.loadPrivateKey(alias); err != nil {
+				return fmt.Errorf("Failed loading secret key [%s] [%s]", alias, err)
+			}
+			raw, err = utils.PrivateKeyToPEM(key, newPwd)
+		case "pk":
+			var key interface{}
+			if key, err = ks.loadPublicKey(alias); err != nil {
+				return fmt.Errorf("Failed loading public key [%s] [%s]", alias, err)
+			}
+			raw, err = utils.PublicKeyToPEM(key, newPwd)
+		case "key":
+			var key []byte
+			if key, err = ks.loadKey(alias); err != nil {
+				return fmt.Errorf("Failed loading key [%s] [%s]", alias, err)
+			}
+			raw, err = utils.AEStoEncryptedPEM(key, newPwd)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Failed converting key [%s] to PEM [%s]", alias, err)
+		}
+
+		rotated[ks.getPathForAlias(alias, suffix)] = raw
+	}
+
+	// Write each key next to the old one and swap them, so that a file
+	// is never left half written
+	for path, raw := range rotated {
+		if err := ioutil.WriteFile(path+".tmp", raw, 0700); err != nil {
+			return fmt.Errorf("Failed storing key [%s]", err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return fmt.Errorf("Failed storing key [%s]", err)
+		}
+	}
+
+	ks.pwd = utils.Clone(newPwd)
+	logger.Infof("Rotated the password of %d keys in KeyStore [%s]", len(rotated), ks.path)
+
+	return nil
+}
+
 func (ks *FileBasedKeyStore) getSuffix(alias string) string {
 	files, _ := ioutil.ReadDir(ks.path)
 	for _, f := range files {
@@ -16,10 +16,18 @@ limitations under the License.
 package sw
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/utils"
 )
 
 func TestInvalidStoreKey(t *testing.T) {
@@ -64,3 +72,150 @@ func TestInvalidStoreKey(t *testing.T) {
 		t.Fatal("Error should be different from nil in this case")
 	}
 }
+
+func TestEncryptedKeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "bccspks")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir [%s]", err)
+	}
+	defer os.RemoveAll(path)
+
+	ks := &FileBasedKeyStore{}
+	if err := ks.Init([]byte("passwd"), path, false); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	k := &ecdsaPrivateKey{key}
+	if err = ks.StoreKey(k); err != nil {
+		t.Fatalf("Failed storing key [%s]", err)
+	}
+	aesKey := &aesPrivateKey{[]byte("0123456789abcdef0123456789abcdef"), false}
+	if err = ks.StoreKey(aesKey); err != nil {
+		t.Fatalf("Failed storing key [%s]", err)
+	}
+
+	// The private key is not stored in clear
+	raw, err := ioutil.ReadFile(ks.getPathForAlias(fmt.Sprintf("%x", k.SKI()), "sk"))
+	if err != nil {
+		t.Fatalf("Failed reading key file [%s]", err)
+	}
+	if _, err = utils.PEMtoPrivateKey(raw, nil); err == nil {
+		t.Fatal("Private key should not be readable without the password")
+	}
+
+	// A read only KeyStore with the password loads the keys
+	roKs := &FileBasedKeyStore{}
+	if err := roKs.Init([]byte("passwd"), path, true); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	k2, err := roKs.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting key [%s]", err)
+	}
+	if key.D.Cmp(k2.(*ecdsaPrivateKey).privKey.D) != 0 {
+		t.Fatal("Loaded key differs from the stored one")
+	}
+
+	// One without it does not
+	plainKs := &FileBasedKeyStore{}
+	if err := plainKs.Init(nil, path, true); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	if _, err = plainKs.GetKey(k.SKI()); err == nil {
+		t.Fatal("Getting an encrypted key without password should fail")
+	}
+}
+
+func TestRotateKeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "bccspks")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir [%s]", err)
+	}
+	defer os.RemoveAll(path)
+
+	// Start from a keystore in clear
+	ks := &FileBasedKeyStore{}
+	if err := ks.Init(nil, path, false); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	k := &ecdsaPrivateKey{key}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	pk := &ecdsaPublicKey{&otherKey.PublicKey}
+	aesKey := &aesPrivateKey{[]byte("0123456789abcdef0123456789abcdef"), false}
+	for _, key := range []bccsp.Key{k, pk, aesKey} {
+		if err = ks.StoreKey(key); err != nil {
+			t.Fatalf("Failed storing key [%s]", err)
+		}
+	}
+
+	roKs := &FileBasedKeyStore{}
+	if err := roKs.Init(nil, path, true); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	if err = roKs.Rotate([]byte("passwd")); err == nil {
+		t.Fatal("Rotating a read only KeyStore should fail")
+	}
+
+	if err = ks.Rotate([]byte("passwd")); err != nil {
+		t.Fatalf("Failed rotating KeyStore [%s]", err)
+	}
+	if err = ks.Rotate([]byte("passwd2")); err != nil {
+		t.Fatalf("Failed rotating KeyStore [%s]", err)
+	}
+
+	// Only the last password opens the keys
+	for _, pwd := range []string{"", "passwd"} {
+		oldKs := &FileBasedKeyStore{}
+		if err := oldKs.Init([]byte(pwd), path, true); err != nil {
+			t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+		}
+		if _, err = oldKs.GetKey(k.SKI()); err == nil {
+			t.Fatalf("Getting the key with password [%s] should fail", pwd)
+		}
+	}
+
+	newKs := &FileBasedKeyStore{}
+	if err := newKs.Init([]byte("passwd2"), path, false); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	k2, err := newKs.GetKey(k.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting key [%s]", err)
+	}
+	if key.D.Cmp(k2.(*ecdsaPrivateKey).privKey.D) != 0 {
+		t.Fatal("Loaded key differs from the stored one")
+	}
+	aesKey2, err := newKs.GetKey(aesKey.SKI())
+	if err != nil {
+		t.Fatalf("Failed getting key [%s]", err)
+	}
+	if !bytes.Equal(aesKey.privKey, aesKey2.(*aesPrivateKey).privKey) {
+		t.Fatal("Loaded key differs from the stored one")
+	}
+	if _, err = newKs.GetKey(pk.SKI()); err != nil {
+		t.Fatalf("Failed getting key [%s]", err)
+	}
+
+	// A wrong current password leaves the files untouched
+	wrongKs := &FileBasedKeyStore{}
+	if err := wrongKs.Init([]byte("wrong"), path, false); err != nil {
+		t.Fatalf("Failed initiliazing KeyStore [%s]", err)
+	}
+	if err = wrongKs.Rotate([]byte("passwd3")); err == nil {
+		t.Fatal("Rotating with a wrong password should fail")
+	}
+	if _, err = newKs.GetKey(k.SKI()); err != nil {
+		t.Fatalf("Failed getting key after a failed rotation [%s]", err)
+	}
+}
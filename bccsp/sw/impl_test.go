@@ -688,6 +688,64 @@ func TestECDSAVerify(t *testing.T) {
 	}
 }
 
+func TestECDSALowS(t *testing.T) {
+	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	pk := &k.(*ecdsaPrivateKey).privKey.PublicKey
+
+	digest, err := currentBCCSP.Hash([]byte("Hello World"), &bccsp.SHAOpts{})
+	if err != nil {
+		t.Fatalf("Failed computing HASH [%s]", err)
+	}
+
+	// A random S is high half of the time, so a few signatures are enough
+	var signature []byte
+	for i := 0; i < 16; i++ {
+		signature, err = currentBCCSP.Sign(k, digest, nil)
+		if err != nil {
+			t.Fatalf("Failed generating ECDSA signature [%s]", err)
+		}
+		_, s, err := utils.UnmarshalECDSASignature(signature)
+		if err != nil {
+			t.Fatalf("Failed unmarshalling ECDSA signature [%s]", err)
+		}
+		if !utils.IsLowS(pk, s) {
+			t.Fatal("Failed generating ECDSA signature. S must be low.")
+		}
+	}
+
+	r, s, _ := utils.UnmarshalECDSASignature(signature)
+	highSignature, err := utils.MarshalECDSASignature(r, new(big.Int).Sub(pk.Curve.Params().N, s))
+	if err != nil {
+		t.Fatalf("Failed marshalling ECDSA signature [%s]", err)
+	}
+
+	valid, err := currentBCCSP.Verify(k, highSignature, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying ECDSA signature. A high S must be accepted unless low S is enforced.")
+	}
+
+	utils.SetLowSOnly(true)
+	defer utils.SetLowSOnly(false)
+
+	valid, err = currentBCCSP.Verify(k, highSignature, digest, nil)
+	if err == nil || valid {
+		t.Fatal("Verifying a high S ECDSA signature should fail when low S is enforced")
+	}
+	valid, err = currentBCCSP.Verify(k, signature, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed verifying ECDSA signature [%s]", err)
+	}
+	if !valid {
+		t.Fatal("Failed verifying ECDSA signature. Signature not valid.")
+	}
+}
+
 func TestECDSAKeyDeriv(t *testing.T) {
 
 	k, err := currentBCCSP.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: false})
@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// KeyStorePassphraseEnv is the environment variable the password of a
+// FileBasedKeyStore is read from when none is configured.
+const KeyStorePassphraseEnv = "BCCSP_KEYSTORE_PASSPHRASE"
+
+// LoadKeyStorePassphrase returns the password of a FileBasedKeyStore, taken
+// from the first source that is set among pwd, usually coming from the
+// configuration, the KeyStorePassphraseEnv environment variable and the file
+// at path file, whose trailing newlines are ignored.
+// It returns nil when no source is set, that is for KeyStores kept in clear.
+func LoadKeyStorePassphrase(pwd, file string) ([]byte, error) {
+	if pwd != "" {
+		return []byte(pwd), nil
+	}
+
+	if pwd = os.Getenv(KeyStorePassphraseEnv); pwd != "" {
+		return []byte(pwd), nil
+	}
+
+	if file == "" {
+		return nil, nil
+	}
+
+	return ReadPassphraseFile(file)
+}
+
+// ReadPassphraseFile returns the passphrase stored in the file at path file,
+// ignoring trailing newlines. An empty passphrase is an error.
+func ReadPassphraseFile(file string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading KeyStore passphrase file [%s]", err)
+	}
+
+	pwd := strings.TrimRight(string(raw), "\r\n")
+	if pwd == "" {
+		return nil, fmt.Errorf("KeyStore passphrase file [%s] is empty", file)
+	}
+
+	return []byte(pwd), nil
+}
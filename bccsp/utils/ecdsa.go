@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// ECDSASignature is the ASN.1 structure of an ECDSA signature
+type ECDSASignature struct {
+	R, S *big.Int
+}
+
+// lowSOnly is 1 when signatures whose S is in the upper half of the curve
+// order must be rejected
+var lowSOnly int32
+
+// SetLowSOnly sets whether ECDSA signatures with a high S are rejected on
+// verification. Signatures are always produced with a low S, but older ones
+// might not be, so this is off by default.
+func SetLowSOnly(enforce bool) {
+	if enforce {
+		atomic.StoreInt32(&lowSOnly, 1)
+	} else {
+		atomic.StoreInt32(&lowSOnly, 0)
+	}
+}
+
+// IsLowSOnly returns true if ECDSA signatures with a high S are rejected
+func IsLowSOnly() bool {
+	return atomic.LoadInt32(&lowSOnly) == 1
+}
+
+// MarshalECDSASignature marshals r and s to an ASN.1 ECDSA signature
+func MarshalECDSASignature(r, s *big.Int) ([]byte, error) {
+	return asn1.Marshal(ECDSASignature{r, s})
+}
+
+// UnmarshalECDSASignature returns r and s of an ASN.1 ECDSA signature
+func UnmarshalECDSASignature(raw []byte) (*big.Int, *big.Int, error) {
+	sig := new(ECDSASignature)
+	_, err := asn1.Unmarshal(raw, sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed unmashalling signature [%s]", err)
+	}
+
+	// Validate sig
+	if sig.R == nil || sig.S == nil {
+		return nil, nil, errors.New("Invalid signature. R and S must be different from nil.")
+	}
+	if sig.R.Sign() != 1 || sig.S.Sign() != 1 {
+		return nil, nil, errors.New("Invalid signature. R and S must be larger than zero.")
+	}
+
+	return sig.R, sig.S, nil
+}
+
+// halfOrder returns the half of the order of the curve of k
+func halfOrder(k *ecdsa.PublicKey) *big.Int {
+	return new(big.Int).Rsh(k.Curve.Params().N, 1)
+}
+
+// IsLowS returns true if s is in the lower half of the order of the curve of k
+func IsLowS(k *ecdsa.PublicKey, s *big.Int) bool {
+	return s.Cmp(halfOrder(k)) != 1
+}
+
+// ToLowS returns s if it is low, N - s otherwise, N being the order of
+// the curve of k. Both are valid signatures of the same digest.
+func ToLowS(k *ecdsa.PublicKey, s *big.Int) *big.Int {
+	if IsLowS(k, s) {
+		return s
+	}
+	return new(big.Int).Sub(k.Curve.Params().N, s)
+}
+
+// SignatureToLowS returns the ASN.1 signature with S normalized to the
+// lower half of the order of the curve of k
+func SignatureToLowS(k *ecdsa.PublicKey, signature []byte) ([]byte, error) {
+	r, s, err := UnmarshalECDSASignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	if IsLowS(k, s) {
+		return signature, nil
+	}
+
+	return MarshalECDSASignature(r, ToLowS(k, s))
+}
+
+// CheckLowS returns an error if the ASN.1 signature has a high S and high
+// S signatures are rejected
+func CheckLowS(k *ecdsa.PublicKey, signature []byte) error {
+	if !IsLowSOnly() {
+		return nil
+	}
+	_, s, err := UnmarshalECDSASignature(signature)
+	if err != nil {
+		return err
+	}
+	if !IsLowS(k, s) {
+		return fmt.Errorf("Invalid S. Must be smaller than half the order [%s][%s].", s, halfOrder(k))
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// ecdsaVector is a signature of digest made of R and either a low S or its
+// high counterpart N - S, both valid
+type ecdsaVector struct {
+	curve  elliptic.Curve
+	x, y   string
+	digest string
+	r      string
+	lowS   string
+	highS  string
+}
+
+var ecdsaVectors = []ecdsaVector{
+	{
+		curve:  elliptic.P256(),
+		x:      "7b68cbc940a7fed6ce9e13fb5c30edea30b1140295ef35f9b66ab4fb05c1725b",
+		y:      "bcd9b4325c9c06697de969199bcd5972bf10d18a62292bdeb9869a050d4a2bbc",
+		digest: "ad2a542c84c7060f1f2ec92f6f7d2d675cf1fc8b47e0c75071d86380efabbb53",
+		r:      "b3d4fe76092ad4a00fa4ae078a715302cadb9e74acb876b7f7ea03b5132d51e8",
+		lowS:   "321053551009a5be27d539fbddf7a4dfb1a54f09573605ec03b0301bedd9ed9c",
+		highS:  "cdefaca9eff65a42d82ac60422085b200b41aba44fe19898f0099aa70e8937b5",
+	},
+	{
+		curve:  elliptic.P384(),
+		x:      "5cf0bbba812409ad6657052d9bf3998e865078ca79fd773363ada96e9cfaf983f68a60bf265d1da1f55dfa96e7550f84",
+		y:      "7a455bb090d46798ee051c9fac7ddc1fdd97316fcc8e35c9c97180f96eb79d8eec685592f7a4fb5a81742fec3da531ac",
+		digest: "e00bf4bb6dc8bde16b7067f0d14dd47d0ea2e3b7191f0dfd4bce7bb8d39f91d7164770e3d1761a9b0020a1ce10eab0d9",
+		r:      "c75ed955800378c560bc0683341b5e0f6d000edb0af7630bc187efd2595696153488aac6f14aeaf4967410f876249b47",
+		lowS:   "2c70e9a06f5781f2f55e567a57e7085ad0697059f6b72a6abee1eba7a9cc77917264a632b5cba41ed87ec41372264773",
+		highS:  "d38f165f90a87e0d0aa1a985a818f7a52f968fa60948d595088161da4a6ab64de5b5677f92e5035c146d55575a9ee200",
+	},
+}
+
+func hexInt(t *testing.T, h string) *big.Int {
+	i, ok := new(big.Int).SetString(h, 16)
+	if !ok {
+		t.Fatalf("Invalid hex integer [%s]", h)
+	}
+	return i
+}
+
+func (v *ecdsaVector) parse(t *testing.T) (*ecdsa.PublicKey, []byte, *big.Int, *big.Int, *big.Int) {
+	digest, err := hex.DecodeString(v.digest)
+	if err != nil {
+		t.Fatalf("Invalid digest [%s]", err)
+	}
+	pk := &ecdsa.PublicKey{Curve: v.curve, X: hexInt(t, v.x), Y: hexInt(t, v.y)}
+	return pk, digest, hexInt(t, v.r), hexInt(t, v.lowS), hexInt(t, v.highS)
+}
+
+func TestECDSALowS(t *testing.T) {
+	for _, v := range ecdsaVectors {
+		pk, digest, r, lowS, highS := v.parse(t)
+		name := v.curve.Params().Name
+
+		if !ecdsa.Verify(pk, digest, r, lowS) || !ecdsa.Verify(pk, digest, r, highS) {
+			t.Fatalf("Both signatures of the %s vector should be valid", name)
+		}
+		if !IsLowS(pk, lowS) {
+			t.Fatalf("S should be low for %s", name)
+		}
+		if IsLowS(pk, highS) {
+			t.Fatalf("S should be high for %s", name)
+		}
+		if ToLowS(pk, highS).Cmp(lowS) != 0 {
+			t.Fatalf("ToLowS should have returned N - S for %s", name)
+		}
+		if ToLowS(pk, lowS).Cmp(lowS) != 0 {
+			t.Fatalf("ToLowS should have returned a low S unchanged for %s", name)
+		}
+
+		lowSig, err := MarshalECDSASignature(r, lowS)
+		if err != nil {
+			t.Fatalf("Failed marshalling signature [%s]", err)
+		}
+		highSig, err := MarshalECDSASignature(r, highS)
+		if err != nil {
+			t.Fatalf("Failed marshalling signature [%s]", err)
+		}
+		normalized, err := SignatureToLowS(pk, highSig)
+		if err != nil {
+			t.Fatalf("Failed normalizing signature [%s]", err)
+		}
+		if string(normalized) != string(lowSig) {
+			t.Fatalf("SignatureToLowS should have returned the low S signature for %s", name)
+		}
+		r2, s2, err := UnmarshalECDSASignature(normalized)
+		if err != nil {
+			t.Fatalf("Failed unmarshalling signature [%s]", err)
+		}
+		if r2.Cmp(r) != 0 || s2.Cmp(lowS) != 0 {
+			t.Fatalf("Unmarshalled signature does not match for %s", name)
+		}
+	}
+}
+
+func TestCheckLowS(t *testing.T) {
+	v := ecdsaVectors[0]
+	pk, _, r, lowS, highS := v.parse(t)
+	lowSig, _ := MarshalECDSASignature(r, lowS)
+	highSig, _ := MarshalECDSASignature(r, highS)
+
+	if err := CheckLowS(pk, highSig); err != nil {
+		t.Fatalf("High S should be accepted by default [%s]", err)
+	}
+
+	SetLowSOnly(true)
+	defer SetLowSOnly(false)
+	if !IsLowSOnly() {
+		t.Fatal("Low S should be enforced")
+	}
+	if err := CheckLowS(pk, highSig); err == nil {
+		t.Fatal("High S should be rejected when low S is enforced")
+	}
+	if err := CheckLowS(pk, lowSig); err != nil {
+		t.Fatalf("Low S should be accepted [%s]", err)
+	}
+}
+
+func TestUnmarshalECDSASignatureInvalid(t *testing.T) {
+	if _, _, err := UnmarshalECDSASignature([]byte("not a signature")); err == nil {
+		t.Fatal("Unmarshalling garbage should fail")
+	}
+	zero, _ := MarshalECDSASignature(big.NewInt(0), big.NewInt(1))
+	if _, _, err := UnmarshalECDSASignature(zero); err == nil {
+		t.Fatal("Unmarshalling a zero R should fail")
+	}
+}
@@ -18,7 +18,6 @@ package utils
 
 import (
 	"crypto/ecdsa"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -92,21 +91,25 @@ func PrivateKeyToEncryptedPEM(privateKey interface{}, pwd []byte) ([]byte, error
 			return nil, err
 		}
 
-		block, err := x509.EncryptPEMBlock(
-			rand.Reader,
-			"ECDSA PRIVATE KEY",
-			raw,
-			pwd,
-			x509.PEMCipherAES256)
-
+		block, err := EncryptPEMBlock("ECDSA PRIVATE KEY", raw, pwd)
 		if err != nil {
 			return nil, err
 		}
 
 		return pem.EncodeToMemory(block), nil
+	case *rsa.PrivateKey:
+		if k == nil {
+			return nil, errors.New("Invalid rsa private key. It must be different from nil.")
+		}
+
+		block, err := EncryptPEMBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(k), pwd)
+		if err != nil {
+			return nil, err
+		}
 
+		return pem.EncodeToMemory(block), nil
 	default:
-		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey")
+		return nil, errors.New("Invalid key type. It must be *ecdsa.PrivateKey or *rsa.PrivateKey")
 	}
 }
 
@@ -145,12 +148,12 @@ func PEMtoPrivateKey(raw []byte, pwd []byte) (interface{}, error) {
 
 	// TODO: derive from header the type of the key
 
-	if x509.IsEncryptedPEMBlock(block) {
+	if IsEncryptedPEMBlock(block) {
 		if len(pwd) == 0 {
 			return nil, errors.New("Encrypted Key. Need a password")
 		}
 
-		decrypted, err := x509.DecryptPEMBlock(block, pwd)
+		decrypted, err := DecryptPEMBlock(block, pwd)
 		if err != nil {
 			return nil, fmt.Errorf("Failed PEM decryption [%s]", err)
 		}
@@ -179,12 +182,12 @@ func PEMtoAES(raw []byte, pwd []byte) ([]byte, error) {
 		return nil, fmt.Errorf("Failed decoding PEM. Block must be different from nil. [% x]", raw)
 	}
 
-	if x509.IsEncryptedPEMBlock(block) {
+	if IsEncryptedPEMBlock(block) {
 		if len(pwd) == 0 {
 			return nil, errors.New("Encrypted Key. Password must be different fom nil")
 		}
 
-		decrypted, err := x509.DecryptPEMBlock(block, pwd)
+		decrypted, err := DecryptPEMBlock(block, pwd)
 		if err != nil {
 			return nil, fmt.Errorf("Failed PEM decryption. [%s]", err)
 		}
@@ -208,13 +211,7 @@ func AEStoEncryptedPEM(raw []byte, pwd []byte) ([]byte, error) {
 		return AEStoPEM(raw), nil
 	}
 
-	block, err := x509.EncryptPEMBlock(
-		rand.Reader,
-		"AES PRIVATE KEY",
-		raw,
-		pwd,
-		x509.PEMCipherAES256)
-
+	block, err := EncryptPEMBlock("AES PRIVATE KEY", raw, pwd)
 	if err != nil {
 		return nil, err
 	}
@@ -289,33 +286,33 @@ func PublicKeyToDER(publicKey interface{}) ([]byte, error) {
 
 // PublicKeyToEncryptedPEM converts a public key to encrypted pem
 func PublicKeyToEncryptedPEM(publicKey interface{}, pwd []byte) ([]byte, error) {
+	var blockType string
 	switch k := publicKey.(type) {
 	case *ecdsa.PublicKey:
 		if k == nil {
 			return nil, errors.New("Invalid ecdsa public key. It must be different from nil.")
 		}
-		raw, err := x509.MarshalPKIXPublicKey(k)
-
-		if err != nil {
-			return nil, err
-		}
-
-		block, err := x509.EncryptPEMBlock(
-			rand.Reader,
-			"ECDSA PUBLIC KEY",
-			raw,
-			pwd,
-			x509.PEMCipherAES256)
-
-		if err != nil {
-			return nil, err
+		blockType = "ECDSA PUBLIC KEY"
+	case *rsa.PublicKey:
+		if k == nil {
+			return nil, errors.New("Invalid rsa public key. It must be different from nil.")
 		}
+		blockType = "RSA PUBLIC KEY"
+	default:
+		return nil, errors.New("Invalid key type. It must be *ecdsa.PublicKey or *rsa.PublicKey")
+	}
 
-		return pem.EncodeToMemory(block), nil
+	raw, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		return nil, errors.New("Invalid key type. It must be *ecdsa.PublicKey")
+	block, err := EncryptPEMBlock(blockType, raw, pwd)
+	if err != nil {
+		return nil, err
 	}
+
+	return pem.EncodeToMemory(block), nil
 }
 
 // PEMtoPublicKey unmarshals a pem to public key
@@ -329,12 +326,12 @@ func PEMtoPublicKey(raw []byte, pwd []byte) (interface{}, error) {
 	}
 
 	// TODO: derive from header the type of the key
-	if x509.IsEncryptedPEMBlock(block) {
+	if IsEncryptedPEMBlock(block) {
 		if len(pwd) == 0 {
 			return nil, errors.New("Encrypted Key. Password must be different from nil")
 		}
 
-		decrypted, err := x509.DecryptPEMBlock(block, pwd)
+		decrypted, err := DecryptPEMBlock(block, pwd)
 		if err != nil {
 			return nil, fmt.Errorf("Failed PEM decryption. [%s]", err)
 		}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 )
 
@@ -184,3 +188,124 @@ func TestECDSAKeys(t *testing.T) {
 		t.Fatal("PEMtoPublicKey should fail on nil PEM and wrong password")
 	}
 }
+
+func TestRSAEncryptedPEM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed generating RSA key [%s]", err)
+	}
+
+	encPEM, err := PrivateKeyToPEM(key, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting private key to encrypted PEM [%s]", err)
+	}
+	keyFromPEM, err := PEMtoPrivateKey(encPEM, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting encrypted PEM to private key [%s]", err)
+	}
+	if key.D.Cmp(keyFromPEM.(*rsa.PrivateKey).D) != 0 {
+		t.Fatal("Failed converting encrypted PEM to private key. Invalid D.")
+	}
+
+	_, err = PEMtoPrivateKey(encPEM, nil)
+	if err == nil {
+		t.Fatal("PEMtoPrivateKey should fail on nil password")
+	}
+
+	encPEM, err = PublicKeyToPEM(&key.PublicKey, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting public key to encrypted PEM [%s]", err)
+	}
+	pkFromPEM, err := PEMtoPublicKey(encPEM, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting encrypted PEM to public key [%s]", err)
+	}
+	if key.N.Cmp(pkFromPEM.(*rsa.PublicKey).N) != 0 {
+		t.Fatal("Failed converting encrypted PEM to public key. Invalid N.")
+	}
+}
+
+func TestAESEncryptedPEM(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	encPEM, err := AEStoEncryptedPEM(key, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting AES key to encrypted PEM [%s]", err)
+	}
+	if block, _ := pem.Decode(encPEM); bytes.Contains(block.Bytes, key) {
+		t.Fatal("Encrypted PEM must not contain the key in clear")
+	}
+
+	keyFromPEM, err := PEMtoAES(encPEM, []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting encrypted PEM to AES key [%s]", err)
+	}
+	if !bytes.Equal(key, keyFromPEM) {
+		t.Fatal("Failed converting encrypted PEM to AES key. Keys differ.")
+	}
+
+	_, err = PEMtoAES(encPEM, []byte("passw"))
+	if err == nil {
+		t.Fatal("PEMtoAES should fail on wrong password")
+	}
+}
+
+func TestEncryptedPEMTampering(t *testing.T) {
+	block, err := EncryptPEMBlock("AES PRIVATE KEY", []byte("secret"), []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed encrypting PEM block [%s]", err)
+	}
+	if !IsEncryptedPEMBlock(block) {
+		t.Fatal("Block should be encrypted")
+	}
+
+	// The type is authenticated
+	block.Type = "ECDSA PRIVATE KEY"
+	if _, err = DecryptPEMBlock(block, []byte("passwd")); err == nil {
+		t.Fatal("DecryptPEMBlock should fail on a changed block type")
+	}
+	block.Type = "AES PRIVATE KEY"
+
+	block.Bytes[0] ^= 1
+	if _, err = DecryptPEMBlock(block, []byte("passwd")); err == nil {
+		t.Fatal("DecryptPEMBlock should fail on a changed ciphertext")
+	}
+	block.Bytes[0] ^= 1
+
+	block.Headers["KDF"] = "scrypt,0,8,1"
+	if _, err = DecryptPEMBlock(block, []byte("passwd")); err == nil {
+		t.Fatal("DecryptPEMBlock should fail on invalid scrypt parameters")
+	}
+
+	// Costs above the limits are rejected before deriving the key
+	for _, kdf := range []string{"scrypt,2097152,8,1", "scrypt,32768,1024,1", "scrypt,32768,8,1024"} {
+		block.Headers["KDF"] = kdf
+		if _, err = DecryptPEMBlock(block, []byte("passwd")); err == nil {
+			t.Fatalf("DecryptPEMBlock should fail on excessive scrypt parameters [%s]", kdf)
+		}
+	}
+}
+
+func TestLegacyEncryptedPEM(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating ECDSA key [%s]", err)
+	}
+	raw, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed marshalling ECDSA key [%s]", err)
+	}
+
+	// Keys encrypted before scrypt and AES-GCM were introduced must still load
+	block, err := x509.EncryptPEMBlock(rand.Reader, "ECDSA PRIVATE KEY", raw, []byte("passwd"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatalf("Failed encrypting PEM block [%s]", err)
+	}
+	keyFromPEM, err := PEMtoPrivateKey(pem.EncodeToMemory(block), []byte("passwd"))
+	if err != nil {
+		t.Fatalf("Failed converting legacy encrypted PEM to private key [%s]", err)
+	}
+	if key.D.Cmp(keyFromPEM.(*ecdsa.PrivateKey).D) != 0 {
+		t.Fatal("Failed converting legacy encrypted PEM to private key. Invalid D.")
+	}
+}
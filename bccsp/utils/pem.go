@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PEM headers of the blocks encrypted by EncryptPEMBlock.
+const (
+	pemEncryptionHeader = "Encryption"
+	pemKDFHeader        = "KDF"
+	pemSaltHeader       = "Salt"
+	pemNonceHeader      = "Nonce"
+
+	pemEncryptionAESGCM = "AES-256-GCM"
+	pemKDFScrypt        = "scrypt"
+)
+
+// scrypt cost parameters used for new blocks. They are recorded in the
+// KDF header so they can be raised without breaking existing files.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	pemSaltSize = 32
+)
+
+// Upper bounds of the scrypt cost parameters accepted when decrypting, so
+// that a crafted KDF header cannot make the derivation use gigabytes of
+// memory or run for hours. They leave room to raise the parameters above.
+const (
+	maxScryptN = 1 << 20
+	maxScryptR = 16
+	maxScryptP = 16
+)
+
+// EncryptPEMBlock returns a PEM block of the passed type holding data
+// encrypted with AES-256-GCM under a key derived from pwd with scrypt.
+// The salt, the nonce and the scrypt parameters are stored in the headers
+// of the block, and the block type is authenticated together with data.
+func EncryptPEMBlock(blockType string, data, pwd []byte) (*pem.Block, error) {
+	if len(pwd) == 0 {
+		return nil, errors.New("Invalid password. It must be different from nil.")
+	}
+
+	salt, err := randomBytes(pemSaltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newPEMCipher(pwd, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{
+		Type: blockType,
+		Headers: map[string]string{
+			pemEncryptionHeader: pemEncryptionAESGCM,
+			pemKDFHeader:        fmt.Sprintf("%s,%d,%d,%d", pemKDFScrypt, scryptN, scryptR, scryptP),
+			pemSaltHeader:       strings.ToUpper(hex.EncodeToString(salt)),
+			pemNonceHeader:      strings.ToUpper(hex.EncodeToString(nonce)),
+		},
+		Bytes: gcm.Seal(nil, nonce, data, []byte(blockType)),
+	}, nil
+}
+
+// IsEncryptedPEMBlock returns true if the block was encrypted either by
+// EncryptPEMBlock or with the legacy RFC 1423 scheme of x509.EncryptPEMBlock.
+func IsEncryptedPEMBlock(block *pem.Block) bool {
+	_, ok := block.Headers[pemEncryptionHeader]
+	return ok || x509.IsEncryptedPEMBlock(block)
+}
+
+// DecryptPEMBlock returns the content of a block encrypted by EncryptPEMBlock
+// or with the legacy RFC 1423 scheme, using the password pwd.
+func DecryptPEMBlock(block *pem.Block, pwd []byte) ([]byte, error) {
+	if len(pwd) == 0 {
+		return nil, errors.New("Encrypted Key. Password must be different from nil")
+	}
+
+	if x509.IsEncryptedPEMBlock(block) {
+		return x509.DecryptPEMBlock(block, pwd)
+	}
+
+	if alg := block.Headers[pemEncryptionHeader]; alg != pemEncryptionAESGCM {
+		return nil, fmt.Errorf("Unsupported PEM encryption [%s]", alg)
+	}
+
+	kdf := strings.Split(block.Headers[pemKDFHeader], ",")
+	if len(kdf) != 4 || kdf[0] != pemKDFScrypt {
+		return nil, fmt.Errorf("Unsupported PEM key derivation [%s]", block.Headers[pemKDFHeader])
+	}
+	var params [3]int
+	maxParams := [3]int{maxScryptN, maxScryptR, maxScryptP}
+	for i := range params {
+		v, err := strconv.Atoi(kdf[i+1])
+		if err != nil || v <= 0 || v > maxParams[i] {
+			return nil, fmt.Errorf("Invalid scrypt parameters [%s]", block.Headers[pemKDFHeader])
+		}
+		params[i] = v
+	}
+
+	salt, err := hex.DecodeString(block.Headers[pemSaltHeader])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("Invalid PEM salt")
+	}
+
+	nonce, err := hex.DecodeString(block.Headers[pemNonceHeader])
+	if err != nil {
+		return nil, errors.New("Invalid PEM nonce")
+	}
+
+	gcm, err := newPEMCipher(pwd, salt, params[0], params[1], params[2])
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("Invalid PEM nonce")
+	}
+
+	data, err := gcm.Open(nil, nonce, block.Bytes, []byte(block.Type))
+	if err != nil {
+		return nil, errors.New("Decryption failed, wrong password or corrupted block")
+	}
+
+	return data, nil
+}
+
+func newPEMCipher(pwd, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key(pwd, salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Failed deriving key from password [%s]", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, fmt.Errorf("Failed getting random bytes [%s]", err)
+	}
+
+	return b, nil
+}
@@ -34,10 +34,6 @@ func createChaincodeSpec(ccType string, path string, args [][]byte) *pb.Chaincod
 
 }
 
-func createProposalID() string {
-	return util.GenerateUUID()
-}
-
 // createChaincodeDeploymentSpec  Returns a deployment proposal of chaincode type
 func createProposalForChaincode(ccChaincodeDeploymentSpec *pb.ChaincodeDeploymentSpec, creator []byte) (proposal *pb.Proposal, err error) {
 	var ccDeploymentSpecBytes []byte
@@ -49,8 +45,7 @@ func createProposalForChaincode(ccChaincodeDeploymentSpec *pb.ChaincodeDeploymen
 		CtorMsg:     &pb.ChaincodeInput{Args: [][]byte{[]byte("deploy"), []byte("default"), ccDeploymentSpecBytes}}}
 	lcChaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: lcChaincodeSpec}
 
-	uuid := createProposalID()
-
 	// make proposal
-	return putils.CreateChaincodeProposal(uuid, util.GetTestChainID(), lcChaincodeInvocationSpec, creator)
+	proposal, _, err = putils.CreateChaincodeProposal(util.GetTestChainID(), lcChaincodeInvocationSpec, creator)
+	return proposal, err
 }
@@ -465,7 +465,7 @@ func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface) ([]byte, error
 }
 
 func main() {
-	err := shim.Start(new(SimpleChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(SimpleChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting Simple chaincode: %s", err)
 	}
@@ -0,0 +1,45 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+type mspCryptoHelper struct {
+	deserializer msp.Common
+}
+
+// NewMSPCryptoHelper returns a CryptoHelper which verifies signatures with
+// the identities deserialized by an MSP or an MSP manager. The identity is
+// not validated: signatures may be checked long after they were made, once
+// the certificate of the signer has expired, and the policy pins the
+// identities it accepts.
+func NewMSPCryptoHelper(deserializer msp.Common) CryptoHelper {
+	return &mspCryptoHelper{deserializer: deserializer}
+}
+
+func (h *mspCryptoHelper) VerifySignature(signedData *cb.SignedData) error {
+	id, err := h.deserializer.DeserializeIdentity(signedData.Identity)
+	if err != nil {
+		return fmt.Errorf("Cannot deserialize the identity of the signer: %s", err)
+	}
+	return id.Verify(signedData.Data, signedData.Signature)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cauthdsl
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+type mockIdentity struct {
+	msp.Identity
+}
+
+func (id *mockIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(sig, validSignature) {
+		return fmt.Errorf("Bad signature")
+	}
+	return nil
+}
+
+type mockDeserializer struct{}
+
+func (d *mockDeserializer) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	if !bytes.Equal(serializedIdentity, signers[0]) {
+		return nil, fmt.Errorf("Unknown identity")
+	}
+	return &mockIdentity{}, nil
+}
+
+func TestMSPCryptoHelper(t *testing.T) {
+	helper := NewMSPCryptoHelper(&mockDeserializer{})
+
+	if err := helper.VerifySignature(&cb.SignedData{Identity: signers[0], Signature: validSignature}); err != nil {
+		t.Fatalf("Expected the signature to verify: %s", err)
+	}
+	if err := helper.VerifySignature(&cb.SignedData{Identity: signers[0], Signature: invalidSignature}); err == nil {
+		t.Fatalf("Expected the invalid signature to be rejected")
+	}
+	if err := helper.VerifySignature(&cb.SignedData{Identity: signers[1], Signature: validSignature}); err == nil {
+		t.Fatalf("Expected the signature of an unknown identity to be rejected")
+	}
+}
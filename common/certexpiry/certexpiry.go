@@ -0,0 +1,248 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certexpiry watches the validity window of the certificates a node
+// is configured with and warns before they expire.
+package certexpiry
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("certexpiry")
+
+// Cert is a certificate watched by a Checker
+type Cert struct {
+	// Name tells what the certificate is used for, e.g. "local signer"
+	Name string
+	Cert *x509.Certificate
+}
+
+// Status is the validity of a Cert at the time of a check
+type Status struct {
+	Name      string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// DaysLeft is the number of whole days before the certificate expires,
+	// negative once it has expired
+	DaysLeft int
+}
+
+// Source returns certificates to watch. Sources are called at every check,
+// so that renewed certificates and new channels are taken into account.
+type Source func() ([]Cert, error)
+
+// Checker periodically logs the days left before each certificate returned
+// by its sources expires. Certificates expiring within the warning window
+// are logged as warnings, expired ones as errors.
+type Checker struct {
+	sources  []Source
+	interval time.Duration
+	warn     time.Duration
+
+	lock   sync.RWMutex
+	status []Status
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewChecker returns a Checker running every interval and warning about
+// certificates expiring within warn.
+func NewChecker(interval, warn time.Duration, sources ...Source) *Checker {
+	return &Checker{
+		sources:  sources,
+		interval: interval,
+		warn:     warn,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start checks the certificates now and then every interval, until Stop is
+// called. A non positive interval disables the periodic checks.
+func (c *Checker) Start() {
+	c.Check()
+	if c.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.Check()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Check collects the certificates of all the sources, logs their validity
+// and returns it
+func (c *Checker) Check() []Status {
+	return c.checkAt(time.Now())
+}
+
+func (c *Checker) checkAt(now time.Time) []Status {
+	var status []Status
+	for _, source := range c.sources {
+		certs, err := source()
+		if err != nil {
+			logger.Errorf("Failed collecting certificates to check: %s", err)
+			continue
+		}
+
+		for _, cert := range certs {
+			s := Status{
+				Name:      cert.Name,
+				NotBefore: cert.Cert.NotBefore,
+				NotAfter:  cert.Cert.NotAfter,
+				DaysLeft:  daysLeft(cert.Cert, now),
+			}
+			status = append(status, s)
+
+			switch {
+			case now.After(s.NotAfter):
+				logger.Errorf("Certificate %s expired on %s", s.Name, s.NotAfter)
+			case now.Before(s.NotBefore):
+				logger.Errorf("Certificate %s is not valid before %s", s.Name, s.NotBefore)
+			case s.NotAfter.Sub(now) <= c.warn:
+				logger.Warningf("Certificate %s expires in %d days, on %s", s.Name, s.DaysLeft, s.NotAfter)
+			default:
+				logger.Debugf("Certificate %s expires in %d days", s.Name, s.DaysLeft)
+			}
+		}
+	}
+
+	c.lock.Lock()
+	c.status = status
+	c.lock.Unlock()
+
+	return status
+}
+
+// Status returns the result of the last check
+func (c *Checker) Status() []Status {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return append([]Status(nil), c.status...)
+}
+
+// Publish exposes the days left for each certificate of the last check as
+// the expvar variable name, served under /debug/vars by the profiling
+// server. It must be called at most once per name.
+func (c *Checker) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		days := make(map[string]int)
+		for _, s := range c.Status() {
+			days[s.Name] = s.DaysLeft
+		}
+		return days
+	}))
+}
+
+// CheckValidity returns an error if cert is expired or not valid yet at
+// time now. name tells what cert is used for in the error.
+func CheckValidity(name string, cert *x509.Certificate, now time.Time) error {
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("Certificate %s (%s) expired on %s", name, cert.Subject.CommonName, cert.NotAfter)
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("Certificate %s (%s) is not valid before %s", name, cert.Subject.CommonName, cert.NotBefore)
+	}
+
+	return nil
+}
+
+// FileSource returns a Source reading the PEM certificate at path file every
+// time it is called
+func FileSource(name, file string) Source {
+	return func() ([]Cert, error) {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read certificate %s: %s", name, err)
+		}
+
+		block, _ := pem.Decode(raw)
+		if block == nil {
+			return nil, fmt.Errorf("No PEM content for certificate %s in %s", name, file)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse certificate %s: %s", name, err)
+		}
+
+		return []Cert{{Name: name, Cert: cert}}, nil
+	}
+}
+
+// MSPSource returns a Source for the roots of trust, the admin certificates
+// and the default signing certificate of m. scope prefixes their names, e.g.
+// "local" or "chain foo".
+func MSPSource(scope string, m msp.MSP) Source {
+	return func() ([]Cert, error) {
+		id, err := m.GetIdentifier()
+		if err != nil {
+			return nil, err
+		}
+
+		var certs []Cert
+		for _, c := range msp.GetCertificates(m) {
+			name := fmt.Sprintf("%s MSP %s %s %s", scope, id, c.Role, c.Cert.Subject.CommonName)
+			certs = append(certs, Cert{Name: name, Cert: c.Cert})
+		}
+
+		return certs, nil
+	}
+}
+
+// CheckSigningCert returns an error if the default signing certificate of
+// the local MSP m is expired or not valid yet at time now
+func CheckSigningCert(m msp.MSP, now time.Time) error {
+	for _, c := range msp.GetCertificates(m) {
+		if c.Role != msp.SigningCertRole {
+			continue
+		}
+		if err := CheckValidity("of the local signing identity", c.Cert, now); err != nil {
+			return fmt.Errorf("%s, replace it in the signcerts and keystore directories of the local MSP", err)
+		}
+	}
+
+	return nil
+}
+
+func daysLeft(cert *x509.Certificate, now time.Time) int {
+	return int(cert.NotAfter.Sub(now) / (24 * time.Hour))
+}
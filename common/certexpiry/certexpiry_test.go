@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certexpiry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"expvar"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/testtools"
+)
+
+var now = time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newCert(t *testing.T, notBefore, notAfter time.Time) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed creating certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("Failed parsing certificate: %s", err)
+	}
+	return cert
+}
+
+func TestCheck(t *testing.T) {
+	day := 24 * time.Hour
+	certs := []Cert{
+		{"valid", newCert(t, now.Add(-day), now.Add(100*day))},
+		{"expiring", newCert(t, now.Add(-day), now.Add(10*day+time.Hour))},
+		{"expired", newCert(t, now.Add(-10*day), now.Add(-2*day-time.Hour))},
+		{"future", newCert(t, now.Add(day), now.Add(100*day))},
+	}
+	failing := func() ([]Cert, error) { return nil, errors.New("unavailable") }
+	c := NewChecker(time.Hour, 30*day, func() ([]Cert, error) { return certs, nil }, failing)
+
+	status := c.checkAt(now)
+	if len(status) != len(certs) {
+		t.Fatalf("Expected %d statuses, got %d", len(certs), len(status))
+	}
+	for i, days := range []int{100, 10, -2, 100} {
+		if status[i].Name != certs[i].Name || status[i].DaysLeft != days {
+			t.Fatalf("Expected %d days left for %s, got %+v", days, certs[i].Name, status[i])
+		}
+	}
+
+	if len(c.Status()) != len(certs) {
+		t.Fatalf("Status should return the last check")
+	}
+
+	c.Publish("testCertExpiryDays")
+	v := expvar.Get("testCertExpiryDays")
+	if v == nil || v.String() != `{"expired":-2,"expiring":10,"future":100,"valid":100}` {
+		t.Fatalf("Unexpected published value %v", v)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	checked := make(chan struct{}, 10)
+	c := NewChecker(time.Millisecond, time.Hour, func() ([]Cert, error) {
+		checked <- struct{}{}
+		return nil, nil
+	})
+	c.Start()
+	<-checked
+	<-checked
+	c.Stop()
+	c.Stop()
+
+	c = NewChecker(0, time.Hour)
+	c.Start()
+	c.Stop()
+}
+
+func TestCheckValidity(t *testing.T) {
+	cert := newCert(t, now.Add(-time.Hour), now.Add(time.Hour))
+	if err := CheckValidity("test", cert, now); err != nil {
+		t.Fatalf("The certificate should be valid: %s", err)
+	}
+	if err := CheckValidity("test", cert, now.Add(2*time.Hour)); err == nil {
+		t.Fatalf("The certificate should be expired")
+	}
+	if err := CheckValidity("test", cert, now.Add(-2*time.Hour)); err == nil {
+		t.Fatalf("The certificate should not be valid yet")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	f, err := ioutil.TempFile("", "cert")
+	if err != nil {
+		t.Fatalf("Failed creating temp file: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = FileSource("tls", f.Name())(); err == nil {
+		t.Fatalf("FileSource should fail on an empty file")
+	}
+
+	cert := newCert(t, now, now.Add(time.Hour))
+	pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	f.Close()
+
+	certs, err := FileSource("tls", f.Name())()
+	if err != nil {
+		t.Fatalf("FileSource should have succeeded: %s", err)
+	}
+	if len(certs) != 1 || certs[0].Name != "tls" || !certs[0].Cert.Equal(cert) {
+		t.Fatalf("Unexpected certificates %v", certs)
+	}
+
+	if _, err = FileSource("tls", "/no/such/file")(); err == nil {
+		t.Fatalf("FileSource should fail on a missing file")
+	}
+}
+
+func newMSP(t *testing.T, notBefore, notAfter time.Time) msp.MSP {
+	dir, err := ioutil.TempDir("", "msp")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if err = testtools.GenerateMSPDir(dir, notBefore, notAfter); err != nil {
+		t.Fatalf("Failed generating MSP: %s", err)
+	}
+
+	conf, err := msp.GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed loading MSP config: %s", err)
+	}
+	m, err := msp.NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Failed creating MSP: %s", err)
+	}
+	if err = m.Setup(conf); err != nil {
+		t.Fatalf("Failed setting up MSP: %s", err)
+	}
+	return m
+}
+
+func TestMSPSource(t *testing.T) {
+	notAfter := time.Now().Add(50 * time.Hour)
+	m := newMSP(t, time.Now().Add(-time.Hour), notAfter)
+
+	certs, err := MSPSource("local", m)()
+	if err != nil {
+		t.Fatalf("MSPSource should have succeeded: %s", err)
+	}
+	expected := []string{"local MSP DEFAULT root testca", "local MSP DEFAULT admin testpeer", "local MSP DEFAULT signer testpeer"}
+	if len(certs) != len(expected) {
+		t.Fatalf("Expected %d certificates, got %v", len(expected), certs)
+	}
+	for i, name := range expected {
+		if certs[i].Name != name || !certs[i].Cert.NotAfter.Equal(notAfter.Truncate(time.Second)) {
+			t.Fatalf("Unexpected certificate %s expiring at %s, expected %s", certs[i].Name, certs[i].Cert.NotAfter, name)
+		}
+	}
+
+	status := NewChecker(time.Hour, 24*time.Hour, MSPSource("local", m)).checkAt(notAfter.Add(-25 * time.Hour))
+	if len(status) != len(expected) || status[2].DaysLeft != 1 {
+		t.Fatalf("Unexpected status %+v", status)
+	}
+}
+
+func TestCheckSigningCert(t *testing.T) {
+	now := time.Now()
+	m := newMSP(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	if err := CheckSigningCert(m, now); err != nil {
+		t.Fatalf("The signing certificate should be valid: %s", err)
+	}
+	if err := CheckSigningCert(m, now.Add(2*time.Hour)); err == nil {
+		t.Fatalf("The signing certificate should be expired")
+	}
+	if err := CheckSigningCert(m, now.Add(-2*time.Hour)); err == nil {
+		t.Fatalf("The signing certificate should not be valid yet")
+	}
+	if err := CheckSigningCert(msp.NewNoopMsp(), now); err != nil {
+		t.Fatalf("An MSP without certificates should pass: %s", err)
+	}
+}
@@ -60,6 +60,9 @@ type Manager interface {
 // DefaultModificationPolicyID is the ID of the policy used when no other policy can be resolved, for instance when attempting to create a new config item
 const DefaultModificationPolicyID = "DefaultModificationPolicy"
 
+// BlockValidationPolicyID is the ID of the policy the signatures of the ordering service on the blocks of a chain must satisfy
+const BlockValidationPolicyID = "BlockValidation"
+
 type acceptAllPolicy struct{}
 
 func (ap *acceptAllPolicy) Evaluate(signedData []*cb.SignedData) error {
@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package localmsp signs on behalf of the node with the default signing
+// identity of its local MSP.
+package localmsp
+
+import (
+	"github.com/hyperledger/fabric/core/peer/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("localmsp")
+
+// Signer creates signature headers and signatures with the default
+// signing identity of the local MSP
+type Signer struct{}
+
+// NewSigner returns a Signer for the local MSP, which must have been
+// set up beforehand
+func NewSigner() *Signer {
+	return &Signer{}
+}
+
+// NewSignatureHeader creates a SignatureHeader carrying the serialized
+// signing identity as creator and a fresh nonce
+func (s *Signer) NewSignatureHeader() *cb.SignatureHeader {
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		logger.Panicf("Failed getting the local signing identity: %s", err)
+	}
+
+	creator, err := signer.Serialize()
+	if err != nil {
+		logger.Panicf("Failed serializing the local signing identity: %s", err)
+	}
+
+	return utils.MakeSignatureHeader(creator, utils.CreateNonceOrPanic())
+}
+
+// Sign signs message with the local signing identity
+func (s *Signer) Sign(message []byte) []byte {
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		logger.Panicf("Failed getting the local signing identity: %s", err)
+	}
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		logger.Panicf("Failed signing with the local signing identity: %s", err)
+	}
+
+	return signature
+}
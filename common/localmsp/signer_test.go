@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localmsp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/peer/msp"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner()
+
+	shdr := signer.NewSignatureHeader()
+	if len(shdr.Nonce) == 0 {
+		t.Fatalf("The signature header should carry a nonce")
+	}
+	if len(signer.NewSignatureHeader().Nonce) == 0 || string(signer.NewSignatureHeader().Nonce) == string(shdr.Nonce) {
+		t.Fatalf("Each signature header should carry a fresh nonce")
+	}
+
+	creator, err := mspmgmt.GetLocalMSP().DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		t.Fatalf("The creator should be the local signing identity: %s", err)
+	}
+
+	message := []byte("message")
+	signature := signer.Sign(message)
+	if err = creator.Verify(message, signature); err != nil {
+		t.Fatalf("The signature should be valid for the creator: %s", err)
+	}
+	if err = creator.Verify([]byte("other message"), signature); err == nil {
+		t.Fatalf("The signature should not be valid for another message")
+	}
+}
+
+func TestMain(m *testing.M) {
+	if err := mspmgmt.LoadLocalMsp("../../msp/sampleconfig", nil); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
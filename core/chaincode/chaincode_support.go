@@ -32,9 +32,11 @@ import (
 	"strings"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/peer"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -82,6 +84,11 @@ type CCContext struct {
 	//Syscc is this a system chaincode
 	Syscc bool
 
+	//SignedProposal for this invoke (if any)
+	//this is sent to the chaincode so it can see who created the
+	//transaction
+	SignedProposal *pb.SignedProposal
+
 	//Proposal for this invoke (if any)
 	//this is kept here just in case we need to pass something
 	//from this to the chaincode
@@ -92,7 +99,7 @@ type CCContext struct {
 }
 
 //NewCCContext just construct a new struct with whatever args
-func NewCCContext(cid, name, version, txid string, syscc bool, prop *pb.Proposal) *CCContext {
+func NewCCContext(cid, name, version, txid string, syscc bool, signedProp *pb.SignedProposal, prop *pb.Proposal) *CCContext {
 	//version CANNOT be empty. The chaincode namespace has to use version and chain name.
 	//All system chaincodes share the same version given by utils.GetSysCCVersion. Note
 	//that neither Chain Name or Version are stored in a chaincodes state on the ledger
@@ -102,7 +109,7 @@ func NewCCContext(cid, name, version, txid string, syscc bool, prop *pb.Proposal
 
 	canName := name + ":" + version + "/" + cid
 
-	cccid := &CCContext{cid, name, version, txid, syscc, prop, canName}
+	cccid := &CCContext{cid, name, version, txid, syscc, signedProp, prop, canName}
 
 	chaincodeLogger.Infof("NewCCCC (chain=%s,chaincode=%s,version=%s,txid=%s,syscc=%t,proposal=%p,canname=%s", cid, name, version, txid, syscc, prop, cccid.canonicalName)
 
@@ -177,6 +184,16 @@ func NewChaincodeSupport(getPeerEndpoint func() (*pb.PeerEndpoint, error), userr
 
 	theChaincodeSupport.userRunsCC = userrunsCC
 
+	//user chaincode runs in docker unless the peer is configured to run it
+	//as local processes
+	switch vmType := viper.GetString("vm.type"); vmType {
+	case "", "docker":
+	case "process":
+		theChaincodeSupport.useProcessVM = true
+	default:
+		chaincodeLogger.Warningf("Unknown vm.type %s, defaulting to docker", vmType)
+	}
+
 	theChaincodeSupport.ccStartupTimeout = ccstartuptimeout
 
 	//TODO I'm not sure if this needs to be on a per chain basis... too lowel and just needs to be a global default ?
@@ -238,6 +255,7 @@ type ChaincodeSupport struct {
 	ccStartupTimeout     time.Duration
 	chaincodeInstallPath string
 	userRunsCC           bool
+	useProcessVM         bool
 	peerNetworkID        string
 	peerID               string
 	peerTLS              bool
@@ -331,7 +349,7 @@ func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Contex
 
 	var notfy chan *pb.ChaincodeMessage
 	var err error
-	if notfy, err = chrte.handler.initOrReady(context, cccid.ChainID, cccid.TxID, cccid.Proposal, initArgs); err != nil {
+	if notfy, err = chrte.handler.initOrReady(context, cccid.ChainID, cccid.TxID, cccid.SignedProposal, cccid.Proposal, initArgs); err != nil {
 		return fmt.Errorf("Error sending %s: %s", pb.ChaincodeMessage_INIT, err)
 	}
 	if notfy != nil {
@@ -549,23 +567,21 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, cccid
 			chaincodeLogger.Error("You are attempting to perform an action other than Deploy on Chaincode that is not ready and you are in developer mode. Did you forget to Deploy your chaincode?")
 		}
 
-		var depPayload []byte
-
-		//hopefully we are restarting from existing image and the deployed transaction exists
-		depPayload, err = GetCDSFromLCCC(context, cccid.TxID, cccid.Proposal, cccid.ChainID, cID.Name)
+		//hopefully we are restarting from existing image and the chaincode was instantiated
+		var cd *ChaincodeData
+		cd, err = GetChaincodeDataFromLCCC(context, cccid.TxID, cccid.SignedProposal, cccid.Proposal, cccid.ChainID, cID.Name)
 		if err != nil {
-			return cID, cMsg, fmt.Errorf("Could not get deployment transaction from LCCC for %s - %s", canName, err)
-		}
-		if depPayload == nil {
-			return cID, cMsg, fmt.Errorf("failed to get deployment payload %s - %s", canName, err)
+			return cID, cMsg, fmt.Errorf("Could not get chaincode data from LCCC for %s - %s", canName, err)
 		}
 
-		cds = &pb.ChaincodeDeploymentSpec{}
-
-		//Get lang from original deployment
-		err = proto.Unmarshal(depPayload, cds)
+		//the code is not on the ledger, get it from the package installed on this peer
+		_, cds, err = ccprovider.GetChaincodeFromFS(cd.Name, cd.Version)
 		if err != nil {
-			return cID, cMsg, fmt.Errorf("failed to unmarshal deployment transactions for %s - %s", canName, err)
+			return cID, cMsg, fmt.Errorf("Could not get installed package for %s - %s", canName, err)
+		}
+
+		if !bytes.Equal(ccprovider.GetCodeHash(cds), cd.Id) {
+			return cID, cMsg, fmt.Errorf("Installed package for %s does not match the instantiated chaincode", canName)
 		}
 
 		cLang = cds.ChaincodeSpec.Type
@@ -608,6 +624,9 @@ func (chaincodeSupport *ChaincodeSupport) getVMType(cds *pb.ChaincodeDeploymentS
 	if cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
 		return container.SYSTEM, nil
 	}
+	if chaincodeSupport.useProcessVM {
+		return container.PROCESS, nil
+	}
 	return container.DOCKER, nil
 }
 
@@ -616,6 +635,10 @@ func (chaincodeSupport *ChaincodeSupport) Deploy(context context.Context, cccid
 	cLang := cds.ChaincodeSpec.Type
 	canName := cccid.GetCanonicalName()
 
+	if err := createStateDBIndexes(cccid, cds); err != nil {
+		return cds, err
+	}
+
 	if chaincodeSupport.userRunsCC {
 		chaincodeLogger.Debug("user runs chaincode, not deploying chaincode")
 		return nil, nil
@@ -651,6 +674,26 @@ func (chaincodeSupport *ChaincodeSupport) Deploy(context context.Context, cccid
 	return cds, err
 }
 
+//createStateDBIndexes creates in the state database of the chain the indexes shipped with the chaincode
+func createStateDBIndexes(cccid *CCContext, cds *pb.ChaincodeDeploymentSpec) error {
+	indexes, err := ccprovider.ExtractStateDBIndexes(cds.CodePackage)
+	if err != nil {
+		return fmt.Errorf("Error getting the state database indexes of chaincode %s: %s", cccid.Name, err)
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	lgr := peer.GetLedger(cccid.ChainID)
+	if lgr == nil {
+		return fmt.Errorf("Chain %s does not exist", cccid.ChainID)
+	}
+	chaincodeLogger.Debugf("creating %d state database indexes of chaincode %s", len(indexes), cccid.Name)
+	if err = lgr.CreateStateIndexes(cccid.Name, indexes); err != nil {
+		return fmt.Errorf("Error creating the state database indexes of chaincode %s: %s", cccid.Name, err)
+	}
+	return nil
+}
+
 // HandleChaincodeStream implements ccintf.HandleChaincodeStream for all vms to call with appropriate stream
 func (chaincodeSupport *ChaincodeSupport) HandleChaincodeStream(ctxt context.Context, stream ccintf.ChaincodeStream) error {
 	return HandleChaincodeStream(chaincodeSupport, ctxt, stream)
@@ -686,7 +729,7 @@ func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, cccid *C
 
 	var notfy chan *pb.ChaincodeMessage
 	var err error
-	if notfy, err = chrte.handler.sendExecuteMessage(ctxt, cccid.ChainID, msg, cccid.Proposal); err != nil {
+	if notfy, err = chrte.handler.sendExecuteMessage(ctxt, cccid.ChainID, msg, cccid.SignedProposal, cccid.Proposal); err != nil {
 		return nil, fmt.Errorf("Error sending %s: %s", msg.Type.String(), err)
 	}
 	var ccresp *pb.ChaincodeMessage
@@ -23,6 +23,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -37,41 +38,49 @@ func createCIS(ccname string, args [][]byte) (*pb.ChaincodeInvocationSpec, error
 }
 
 // GetCDSFromLCCC gets chaincode deployment spec from LCCC
-func GetCDSFromLCCC(ctxt context.Context, txid string, prop *pb.Proposal, chainID string, chaincodeID string) ([]byte, error) {
+func GetCDSFromLCCC(ctxt context.Context, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, chainID string, chaincodeID string) ([]byte, error) {
 	version := util.GetSysCCVersion()
-	cccid := NewCCContext(chainID, "lccc", version, txid, true, prop)
-	payload, _, err := ExecuteChaincode(ctxt, cccid, [][]byte{[]byte("getdepspec"), []byte(chainID), []byte(chaincodeID)})
-	return payload, err
+	cccid := NewCCContext(chainID, "lccc", version, txid, true, signedProp, prop)
+	res, _, err := ExecuteChaincode(ctxt, cccid, [][]byte{[]byte("getdepspec"), []byte(chainID), []byte(chaincodeID)})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != shim.OK {
+		return nil, fmt.Errorf("getdepspec failed for %s: %s", chaincodeID, res.Message)
+	}
+	return res.Payload, nil
 }
 
 // GetChaincodeDataFromLCCC gets chaincode data from LCCC given name
-func GetChaincodeDataFromLCCC(ctxt context.Context, txid string, prop *pb.Proposal, chainID string, chaincodeID string) (*ChaincodeData, error) {
+func GetChaincodeDataFromLCCC(ctxt context.Context, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, chainID string, chaincodeID string) (*ChaincodeData, error) {
 	version := util.GetSysCCVersion()
-	cccid := NewCCContext(chainID, "lccc", version, txid, true, prop)
-	payload, _, err := ExecuteChaincode(ctxt, cccid, [][]byte{[]byte("getccdata"), []byte(chainID), []byte(chaincodeID)})
-	if err == nil {
-		cd := &ChaincodeData{}
-		err = proto.Unmarshal(payload, cd)
-		if err != nil {
-			return nil, err
-		}
-		return cd, nil
+	cccid := NewCCContext(chainID, "lccc", version, txid, true, signedProp, prop)
+	res, _, err := ExecuteChaincode(ctxt, cccid, [][]byte{[]byte("getccdata"), []byte(chainID), []byte(chaincodeID)})
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, err
+	if res.Status != shim.OK {
+		return nil, fmt.Errorf("getccdata failed for %s: %s", chaincodeID, res.Message)
+	}
+	cd := &ChaincodeData{}
+	err = proto.Unmarshal(res.Payload, cd)
+	if err != nil {
+		return nil, err
+	}
+	return cd, nil
 }
 
 // ExecuteChaincode executes a given chaincode given chaincode name and arguments
-func ExecuteChaincode(ctxt context.Context, cccid *CCContext, args [][]byte) ([]byte, *pb.ChaincodeEvent, error) {
+func ExecuteChaincode(ctxt context.Context, cccid *CCContext, args [][]byte) (*pb.Response, *pb.ChaincodeEvent, error) {
 	var spec *pb.ChaincodeInvocationSpec
 	var err error
-	var b []byte
+	var res *pb.Response
 	var ccevent *pb.ChaincodeEvent
 
 	spec, err = createCIS(cccid.Name, args)
-	b, ccevent, err = Execute(ctxt, cccid, spec)
+	res, ccevent, err = Execute(ctxt, cccid, spec)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error executing chaincode: %s", err)
 	}
-	return b, ccevent, err
+	return res, ccevent, err
 }
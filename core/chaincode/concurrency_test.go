@@ -53,7 +53,7 @@ func TestExecuteConcurrentInvokes(t *testing.T) {
 
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: chaincodeID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid := NewCCContext(chainID, "nkpi", "0", "", false, nil)
+	cccid := NewCCContext(chainID, "nkpi", "0", "", false, nil, nil)
 
 	defer theChaincodeSupport.Stop(ctxt, cccid, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec})
 
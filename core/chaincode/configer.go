@@ -25,10 +25,13 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/op/go-logging"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -42,51 +45,80 @@ var cnflogger = logging.MustGetLogger("chaincode")
 
 // These are function names from Invoke first parameter
 const (
-	JoinChain         string = "JoinChain"
-	UpdateConfigBlock string = "UpdateConfigBlock"
-	GetConfigBlock    string = "GetConfigBlock"
+	JoinChain             string = "JoinChain"
+	JoinChainFromSnapshot string = "JoinChainFromSnapshot"
+	UpdateConfigBlock     string = "UpdateConfigBlock"
+	GetConfigBlock        string = "GetConfigBlock"
+	GetChannels           string = "GetChannels"
+	ExportSnapshot        string = "ExportSnapshot"
 )
 
 // Init is called once per chain when the chain is created.
 // This allows the chaincode to initialize any variables on the ledger prior
 // to any transaction execution on the chain.
-func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	cnflogger.Info("Init CSCC")
 
-	return nil, nil
+	return shim.Success(nil)
 }
 
 // Invoke is called for the following:
 // # to process joining a chain (called by app as a transaction proposal)
+// # to join a chain from a ledger snapshot (called by app)
+// # to export a snapshot of the ledger of a chain (called by app)
 // # to get the current configuration block (called by app)
 // # to update the configuration block (called by commmitter)
+// # to list the chains this peer has joined (called by app)
 // Peer calls this function with 2 arguments:
-// # args[0] is the function name, which must be JoinChain, GetConfigBlock or
-// UpdateConfigBlock
+// # args[0] is the function name, which must be JoinChain,
+// JoinChainFromSnapshot, GetConfigBlock, UpdateConfigBlock, GetChannels or
+// ExportSnapshot
 // # args[1] is a configuration Block if args[0] is JoinChain or
-// UpdateConfigBlock; otherwise it is the chain id
+// UpdateConfigBlock, the path of a ledger snapshot on the peer if args[0] is
+// JoinChainFromSnapshot, in which case args[2] is the hash of the snapshot;
+// otherwise it is the chain id. GetChannels takes no further arguments
 // TODO: Improve the scc interface to avoid marshal/unmarshal args
-func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 
-	if len(args) < 2 {
-		return nil, fmt.Errorf("Incorrect number of arguments, %d", len(args))
+	if len(args) < 1 {
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments, %d", len(args)))
 	}
 	fname := string(args[0])
 
+	if fname != GetChannels && len(args) < 2 {
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments, %d", len(args)))
+	}
+
 	cnflogger.Debugf("Invoke function: %s", fname)
 
 	// TODO: Handle ACL
 
-	if fname == JoinChain {
-		return joinChain(args[1])
+	var payload []byte
+	var err error
+	if fname == GetChannels {
+		payload, err = getChannels()
+	} else if fname == JoinChain {
+		payload, err = joinChain(args[1])
+	} else if fname == JoinChainFromSnapshot {
+		if len(args) < 3 {
+			return shim.Error(fmt.Sprintf("Incorrect number of arguments, %d", len(args)))
+		}
+		payload, err = joinChainFromSnapshot(args[1], args[2])
+	} else if fname == ExportSnapshot {
+		payload, err = exportSnapshot(args[1])
 	} else if fname == GetConfigBlock {
-		return getConfigBlock(args[1])
+		payload, err = getConfigBlock(args[1])
 	} else if fname == UpdateConfigBlock {
-		return updateConfigBlock(args[1])
+		payload, err = updateConfigBlock(args[1])
+	} else {
+		return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
 	}
 
-	return nil, fmt.Errorf("Requested function %s not found.", fname)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(payload)
 }
 
 // joinChain will join the specified chain in the configuration block.
@@ -118,6 +150,45 @@ func joinChain(blockBytes []byte) ([]byte, error) {
 	return []byte("200"), nil
 }
 
+// joinChainFromSnapshot will join the chain of the ledger snapshot in the
+// file snapshotPath, which must match hash. The ledger of the chain starts at
+// the block the snapshot was taken at instead of the genesis block
+func joinChainFromSnapshot(snapshotPath []byte, hash []byte) ([]byte, error) {
+	if len(snapshotPath) == 0 {
+		return nil, errors.New("Snapshot path must not be empty.")
+	}
+	if len(hash) == 0 {
+		return nil, errors.New("Snapshot hash must not be empty.")
+	}
+
+	chainID, err := peer.CreateChainFromSnapshot(string(snapshotPath), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize all system chainodes on this chain
+	// TODO: Fix this code to initialize instead of deploy chaincodes
+	DeploySysCCs(chainID)
+
+	return []byte("200"), nil
+}
+
+// exportSnapshot writes a snapshot of the ledger of the specified chain to
+// the file system of the peer, from which another peer can join the chain.
+// It returns where the snapshot was written along with its hash. If the peer
+// doesn't belong to the chain, return error
+func exportSnapshot(chainID []byte) ([]byte, error) {
+	if chainID == nil {
+		return nil, errors.New("ChainID must not be nil.")
+	}
+
+	info, err := peer.ExportSnapshot(string(chainID))
+	if err != nil {
+		return nil, err
+	}
+	return utils.Marshal(info)
+}
+
 func updateConfigBlock(blockBytes []byte) ([]byte, error) {
 	if blockBytes == nil {
 		return nil, errors.New("Configuration block must not be nil.")
@@ -155,3 +226,19 @@ func getConfigBlock(chainID []byte) ([]byte, error) {
 
 	return blockBytes, nil
 }
+
+// getChannels returns information about all the chains this peer has joined,
+// as a marshalled ChannelQueryResponse
+func getChannels() ([]byte, error) {
+	ledgerIDs, err := ledgermgmt.GetLedgerIDs()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the list of channels, %s", err)
+	}
+
+	channels := make([]*pb.ChannelInfo, len(ledgerIDs))
+	for i, ledgerID := range ledgerIDs {
+		channels[i] = &pb.ChannelInfo{ChannelID: ledgerID}
+	}
+
+	return proto.Marshal(&pb.ChannelQueryResponse{Channels: channels})
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,9 +31,11 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -40,8 +44,8 @@ func TestConfigerInit(t *testing.T) {
 	e := new(PeerConfiger)
 	stub := shim.NewMockStub("PeerConfiger", e)
 
-	if _, err := stub.MockInit("1", nil); err != nil {
-		fmt.Println("Init failed", err)
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -73,7 +77,7 @@ func TestConfigerInvokeJoinChainMissingParams(t *testing.T) {
 	setupEndpoint(t)
 	// Failed path: Not enough parameters
 	args := [][]byte{[]byte("JoinChain")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("cscc invoke JoinChain should have failed with invalid number of args: %v", args)
 	}
 }
@@ -90,8 +94,8 @@ func TestConfigerInvokeJoinChainWrongParams(t *testing.T) {
 
 	// Failed path: wrong parameter type
 	args := [][]byte{[]byte("JoinChain"), []byte("action")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
-		fmt.Println("Invoke", args, "failed", err)
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.Fatalf("cscc invoke JoinChain should have failed with null genesis block.  args: %v", args)
 	}
 }
@@ -122,8 +126,8 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 		t.Fatalf("cscc invoke JoinChain failed because invalid block")
 	}
 	args := [][]byte{[]byte("JoinChain"), blockBytes}
-	if _, err = stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("cscc invoke JoinChain failed with: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("cscc invoke JoinChain failed with: %v", res.Message)
 	}
 
 	// Query the configuration block
@@ -133,8 +137,116 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 		t.Fatalf("cscc invoke JoinChain failed with: %v", err)
 	}
 	args = [][]byte{[]byte("GetConfigBlock"), []byte(chainID)}
-	if _, err = stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("cscc invoke GetConfigBlock failed with: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("cscc invoke GetConfigBlock failed with: %v", res.Message)
+	}
+}
+
+func TestConfigerInvokeGetChannels(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test/")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	defer os.RemoveAll("/var/hyperledger/test/")
+
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	if _, err := ledgermgmt.CreateLedger("mytestchainid"); err != nil {
+		t.Fatalf("Failed to create ledger: %s", err)
+	}
+
+	args := [][]byte{[]byte("GetChannels")}
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("cscc invoke GetChannels failed with: %v", res.Message)
+	}
+
+	channelQueryResponse := &pb.ChannelQueryResponse{}
+	if err := proto.Unmarshal(res.Payload, channelQueryResponse); err != nil {
+		t.Fatalf("cscc invoke GetChannels returned an invalid response: %v", err)
+	}
+	if len(channelQueryResponse.Channels) != 1 || channelQueryResponse.Channels[0].ChannelID != "mytestchainid" {
+		t.Fatalf("cscc invoke GetChannels returned unexpected channels: %v", channelQueryResponse.Channels)
+	}
+}
+
+func TestConfigerInvokeExportSnapshot(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/var/hyperledger/test/")
+	peer.MockInitialize()
+	defer ledgermgmt.CleanupTestEnv()
+	defer os.RemoveAll("/var/hyperledger/test/")
+
+	e := new(PeerConfiger)
+	stub := shim.NewMockStub("PeerConfiger", e)
+
+	if err := peer.MockCreateChain("mytestchainid"); err != nil {
+		t.Fatalf("Failed to create chain: %s", err)
+	}
+	l := peer.GetLedger("mytestchainid")
+	simulator, _ := l.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	if err := l.Commit(testutil.ConstructBlock(t, [][]byte{simRes}, false)); err != nil {
+		t.Fatalf("Failed to commit block: %s", err)
+	}
+
+	// Failed path: unknown chain
+	args := [][]byte{[]byte("ExportSnapshot"), []byte("unknownchainid")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke ExportSnapshot should have failed for an unknown chain")
+	}
+
+	args = [][]byte{[]byte("ExportSnapshot"), []byte("mytestchainid")}
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("cscc invoke ExportSnapshot failed with: %v", res.Message)
+	}
+	info := &snapshot.SnapshotInfo{}
+	if err := proto.Unmarshal(res.Payload, info); err != nil {
+		t.Fatalf("cscc invoke ExportSnapshot returned an invalid snapshot info: %v", err)
+	}
+	if info.BlockNumber != 1 || len(info.Hash) == 0 {
+		t.Fatalf("cscc invoke ExportSnapshot returned unexpected snapshot info: %v", info)
+	}
+	if _, err := os.Stat(info.Path); err != nil {
+		t.Fatalf("cscc invoke ExportSnapshot did not write the snapshot: %v", err)
+	}
+
+	// Failed path: the ledger of the snapshot exists already
+	args = [][]byte{[]byte("JoinChainFromSnapshot"), []byte(filepath.Base(info.Path)), info.Hash}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke JoinChainFromSnapshot should have failed for an existing ledger")
+	}
+
+	// Failed path: the snapshot does not match the hash
+	args = [][]byte{[]byte("JoinChainFromSnapshot"), []byte(info.Path), []byte("hash")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke JoinChainFromSnapshot should have failed with a wrong hash")
+	}
+
+	// Failed path: missing hash
+	args = [][]byte{[]byte("JoinChainFromSnapshot"), []byte(info.Path)}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke JoinChainFromSnapshot should have failed without a hash")
+	}
+
+	// Failed path: the snapshot is not in the snapshots directory
+	outsidePath := filepath.Join(filepath.Dir(filepath.Dir(info.Path)), filepath.Base(info.Path))
+	if err := os.Link(info.Path, outsidePath); err != nil {
+		t.Fatalf("Failed to link the snapshot: %s", err)
+	}
+	for _, path := range []string{outsidePath, filepath.Join("..", filepath.Base(info.Path))} {
+		args = [][]byte{[]byte("JoinChainFromSnapshot"), []byte(path), info.Hash}
+		if res := stub.MockInvoke("1", args); res.Status == shim.OK || !strings.Contains(res.Message, "not in the snapshots directory") {
+			t.Fatalf("cscc invoke JoinChainFromSnapshot should have failed for a file outside the snapshots directory: %v", res.Message)
+		}
+	}
+
+	// Failed path: unknown snapshot
+	args = [][]byte{[]byte("JoinChainFromSnapshot"), []byte("action"), info.Hash}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("cscc invoke JoinChainFromSnapshot should have failed with an unknown snapshot")
 	}
 }
 
@@ -147,14 +259,14 @@ func TestConfigerInvokeUpdateConfigBlock(t *testing.T) {
 
 	// Failed path: Not enough parameters
 	args := [][]byte{[]byte("UpdateConfigBlock")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("cscc invoke UpdateConfigBlock should have failed with invalid number of args: %v", args)
 	}
 
 	// Failed path: wrong parameter type
 	args = [][]byte{[]byte("UpdateConfigBlock"), []byte("action")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
-		fmt.Println("Invoke", args, "failed", err)
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.Fatalf("cscc invoke UpdateConfigBlock should have failed with null genesis block - args: %v", args)
 	}
 
@@ -164,8 +276,8 @@ func TestConfigerInvokeUpdateConfigBlock(t *testing.T) {
 		t.Fatalf("cscc invoke UpdateConfigBlock failed because invalid block")
 	}
 	args = [][]byte{[]byte("UpdateConfigBlock"), blockBytes}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("cscc invoke UpdateConfigBlock failed with: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("cscc invoke UpdateConfigBlock failed with: %v", res.Message)
 	}
 
 	// Query the configuration block
@@ -175,8 +287,8 @@ func TestConfigerInvokeUpdateConfigBlock(t *testing.T) {
 		t.Fatalf("cscc invoke UpdateConfigBlock failed with: %v", err)
 	}
 	args = [][]byte{[]byte("GetConfigBlock"), []byte(chainID)}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("cscc invoke GetConfigBlock failed with: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("cscc invoke GetConfigBlock failed with: %v", res.Message)
 	}
 
 }
@@ -21,14 +21,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 
 	"github.com/hyperledger/fabric/events/producer"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
-//Execute - execute proposal
-func Execute(ctxt context.Context, cccid *CCContext, spec interface{}) ([]byte, *pb.ChaincodeEvent, error) {
+//Execute - execute proposal, return original response of chaincode
+func Execute(ctxt context.Context, cccid *CCContext, spec interface{}) (*pb.Response, *pb.ChaincodeEvent, error) {
 	var err error
 	var cds *pb.ChaincodeDeploymentSpec
 	var ci *pb.ChaincodeInvocationSpec
@@ -89,13 +90,19 @@ func Execute(ctxt context.Context, cccid *CCContext, spec interface{}) ([]byte,
 			}
 
 			if resp.Type == pb.ChaincodeMessage_COMPLETED {
-				// Success
-				return resp.Payload, resp.ChaincodeEvent, nil
+				// the chaincode ran, the status of its response tells
+				// whether it succeeded
+				res := &pb.Response{}
+				unmarshalErr := proto.Unmarshal(resp.Payload, res)
+				if unmarshalErr != nil {
+					return nil, nil, fmt.Errorf("Failed to unmarshal response for (%s): %s", cccid.TxID, unmarshalErr)
+				}
+				return res, resp.ChaincodeEvent, nil
 			} else if resp.Type == pb.ChaincodeMessage_ERROR {
 				// Rollback transaction
 				return nil, resp.ChaincodeEvent, fmt.Errorf("Transaction returned with failure: %s", string(resp.Payload))
 			}
-			return resp.Payload, nil, fmt.Errorf("receive a response for (%s) but in invalid state(%d)", cccid.TxID, resp.Type)
+			return nil, nil, fmt.Errorf("receive a response for (%s) but in invalid state(%d)", cccid.TxID, resp.Type)
 		}
 
 	}
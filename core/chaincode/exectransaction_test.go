@@ -29,6 +29,8 @@ import (
 	"path/filepath"
 
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -60,8 +62,12 @@ func initPeer(chainIDs ...string) (net.Listener, error) {
 	//start clean
 	finitPeer(nil, chainIDs...)
 
+	// the state is kept in memory so that the tests do not depend on the state database on disk
+	viper.Set("ledger.state.stateDatabase", "memory")
 	peer.MockInitialize()
 
+	ccprovider.SetChaincodesPath(filepath.Join(viper.GetString("peer.fileSystemPath"), "chaincodes"))
+
 	var opts []grpc.ServerOption
 	if viper.GetBool("peer.tls.enabled") {
 		creds, err := credentials.NewServerTLSFromFile(viper.GetString("peer.tls.cert.file"), viper.GetString("peer.tls.key.file"))
@@ -138,7 +144,7 @@ func endTxSimulationCDS(chainID string, txid string, txsim ledger.TxSimulator, p
 		return err
 	}
 	// get a proposal - we need it to get a transaction
-	prop, err := putils.CreateDeployProposalFromCDS(txid, chainID, cds, ss)
+	prop, _, err := putils.CreateDeployProposalFromCDS(chainID, cds, ss)
 	if err != nil {
 		return err
 	}
@@ -153,7 +159,7 @@ func endTxSimulationCIS(chainID string, txid string, txsim ledger.TxSimulator, p
 		return err
 	}
 	// get a proposal - we need it to get a transaction
-	prop, err := putils.CreateProposalFromCIS(txid, chainID, cis, ss)
+	prop, _, err := putils.CreateProposalFromCIS(chainID, cis, ss)
 	if err != nil {
 		return err
 	}
@@ -184,7 +190,7 @@ func endTxSimulation(chainID string, txsim ledger.TxSimulator, payload []byte, c
 			}
 
 			// assemble a (signed) proposal response message
-			resp, err := putils.CreateProposalResponse(prop.Header, prop.Payload, txSimulationResults, nil, nil, signer)
+			resp, err := putils.CreateProposalResponse(prop.Header, prop.Payload, nil, txSimulationResults, nil, nil, signer)
 			if err != nil {
 				return err
 			}
@@ -252,7 +258,23 @@ func deploy(ctx context.Context, cccid *CCContext, spec *pb.ChaincodeSpec) (b []
 	return deploy2(ctx, cccid, chaincodeDeploymentSpec)
 }
 
+//installChaincode puts the package in the peer's chaincode install path
+//unless that name and version is already there (eg, deployed on another chain)
+func installChaincode(cds *pb.ChaincodeDeploymentSpec) error {
+	if _, _, err := ccprovider.GetChaincodeFromFS(cds.ChaincodeSpec.ChaincodeID.Name, cds.ChaincodeSpec.ChaincodeID.Version); err == nil {
+		return nil
+	}
+
+	return ccprovider.PutChaincodeIntoFS(cds)
+}
+
 func deploy2(ctx context.Context, cccid *CCContext, chaincodeDeploymentSpec *pb.ChaincodeDeploymentSpec) (b []byte, err error) {
+	//the chaincode has to be installed with the version it is deployed with
+	chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Version = cccid.Version
+	if err = installChaincode(chaincodeDeploymentSpec); err != nil {
+		return nil, fmt.Errorf("Error installing chaincode : %s\n", err)
+	}
+
 	cis, err := getDeployLCCCSpec(cccid.ChainID, chaincodeDeploymentSpec)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating lccc spec : %s\n", err)
@@ -279,18 +301,22 @@ func deploy2(ctx context.Context, cccid *CCContext, chaincodeDeploymentSpec *pb.
 	}()
 
 	sysCCVers := util.GetSysCCVersion()
-	lcccid := NewCCContext(cccid.ChainID, cis.ChaincodeSpec.ChaincodeID.Name, sysCCVers, uuid, true, nil)
+	lcccid := NewCCContext(cccid.ChainID, cis.ChaincodeSpec.ChaincodeID.Name, sysCCVers, uuid, true, nil, nil)
 
 	//write to lccc
-	if _, _, err = Execute(ctx, lcccid, cis); err != nil {
+	var res *pb.Response
+	if res, _, err = Execute(ctx, lcccid, cis); err != nil {
 		return nil, fmt.Errorf("Error deploying chaincode: %s", err)
+	} else if res.Status != shim.OK {
+		err = fmt.Errorf("Error deploying chaincode: %s", res.Message)
+		return nil, err
 	}
 
-	if b, _, err = Execute(ctx, cccid, chaincodeDeploymentSpec); err != nil {
+	if _, _, err = Execute(ctx, cccid, chaincodeDeploymentSpec); err != nil {
 		return nil, fmt.Errorf("Error deploying chaincode: %s", err)
 	}
 
-	return b, nil
+	return nil, nil
 }
 
 // Invoke a chaincode.
@@ -322,13 +348,19 @@ func invokeWithVersion(ctx context.Context, chainID string, version string, spec
 		}
 	}()
 
-	cccid := NewCCContext(chainID, chaincodeInvocationSpec.ChaincodeSpec.ChaincodeID.Name, version, uuid, false, nil)
-	retval, ccevt, err = Execute(ctx, cccid, chaincodeInvocationSpec)
+	cccid := NewCCContext(chainID, chaincodeInvocationSpec.ChaincodeSpec.ChaincodeID.Name, version, uuid, false, nil, nil)
+	var res *pb.Response
+	res, ccevt, err = Execute(ctx, cccid, chaincodeInvocationSpec)
 	if err != nil {
 		return nil, uuid, nil, fmt.Errorf("Error invoking chaincode: %s ", err)
 	}
+	//like the endorser, treat a rejection by the chaincode as a failure
+	if res.Status >= shim.ERRORTHRESHOLD {
+		err = fmt.Errorf("Error invoking chaincode: %s ", res.Message)
+		return nil, uuid, nil, err
+	}
 
-	return ccevt, uuid, retval, err
+	return ccevt, uuid, res.Payload, err
 }
 
 func closeListenerAndSleep(l net.Listener) {
@@ -353,7 +385,7 @@ func executeDeployTransaction(t *testing.T, chainID string, name string, url str
 	args := util.ToChaincodeArgs(f, "a", "100", "b", "200")
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: name, Path: url}, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid := NewCCContext(chainID, name, "0", "", false, nil)
+	cccid := NewCCContext(chainID, name, "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid, spec)
 
@@ -380,7 +412,7 @@ func chaincodeQueryChaincode(chainID string, user string) error {
 
 	spec1 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID1, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 
 	_, err := deploy(ctxt, cccid1, spec1)
 
@@ -401,7 +433,7 @@ func chaincodeQueryChaincode(chainID string, user string) error {
 
 	spec2 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid2 := NewCCContext(chainID, "example05", "0", "", false, nil)
+	cccid2 := NewCCContext(chainID, "example05", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid2, spec2)
 	chaincodeID2 := spec2.ChaincodeID.Name
@@ -598,7 +630,7 @@ func TestExecuteInvokeTransaction(t *testing.T) {
 
 	var ctxt = context.Background()
 
-	cccid := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 	url := "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"
 	chaincodeID := &pb.ChaincodeID{Name: "example02", Path: url}
 
@@ -632,7 +664,7 @@ func TestExecuteInvokeInvalidTransaction(t *testing.T) {
 	url := "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"
 	chaincodeID := &pb.ChaincodeID{Name: "example02", Path: url}
 
-	cccid := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 
 	//FAIL, FAIL!
 	args := []string{"x", "-1"}
@@ -689,7 +721,7 @@ func chaincodeInvokeChaincode(t *testing.T, chainID string, user string) (err er
 
 	spec1 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID1, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid1, spec1)
 	chaincodeID1 := spec1.ChaincodeID.Name
@@ -713,7 +745,7 @@ func chaincodeInvokeChaincode(t *testing.T, chainID string, user string) (err er
 
 	spec2 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid2 := NewCCContext(chainID, "example04", "0", "", false, nil)
+	cccid2 := NewCCContext(chainID, "example04", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid2, spec2)
 	chaincodeID2 := spec2.ChaincodeID.Name
@@ -788,7 +820,7 @@ func TestChaincodeInvokeChaincodeErrorCase(t *testing.T) {
 
 	spec1 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID1, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid1, spec1)
 	chaincodeID1 := spec1.ChaincodeID.Name
@@ -810,7 +842,7 @@ func TestChaincodeInvokeChaincodeErrorCase(t *testing.T) {
 
 	spec2 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid2 := NewCCContext(chainID, "pthru", "0", "", false, nil)
+	cccid2 := NewCCContext(chainID, "pthru", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid2, spec2)
 	chaincodeID2 := spec2.ChaincodeID.Name
@@ -877,7 +909,7 @@ func TestRangeQuery(t *testing.T) {
 
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid := NewCCContext(chainID, "tmap", "0", "", false, nil)
+	cccid := NewCCContext(chainID, "tmap", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid, spec)
 	chaincodeID := spec.ChaincodeID.Name
@@ -923,7 +955,7 @@ func TestGetEvent(t *testing.T) {
 	f := "init"
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID, CtorMsg: &pb.ChaincodeInput{Args: util.ToChaincodeArgs(f)}}
 
-	cccid := NewCCContext(chainID, "esender", "0", "", false, nil)
+	cccid := NewCCContext(chainID, "esender", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid, spec)
 	chaincodeID := spec.ChaincodeID.Name
@@ -992,7 +1024,7 @@ func TestChaincodeQueryChaincodeUsingInvoke(t *testing.T) {
 
 	spec1 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID1, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil)
+	cccid1 := NewCCContext(chainID, "example02", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid1, spec1)
 	chaincodeID1 := spec1.ChaincodeID.Name
@@ -1014,7 +1046,7 @@ func TestChaincodeQueryChaincodeUsingInvoke(t *testing.T) {
 
 	spec2 := &pb.ChaincodeSpec{Type: 1, ChaincodeID: cID2, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid2 := NewCCContext(chainID, "example05", "0", "", false, nil)
+	cccid2 := NewCCContext(chainID, "example05", "0", "", false, nil, nil)
 
 	_, err = deploy(ctxt, cccid2, spec2)
 	chaincodeID2 := spec2.ChaincodeID.Name
@@ -1092,7 +1124,7 @@ func TestMain(m *testing.M) {
 
 	// setup the MSP manager so that we can sign/verify
 	mspMgrConfigDir := "../../msp/sampleconfig/"
-	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 	signer, err = mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
 	if err != nil {
 		os.Exit(-1)
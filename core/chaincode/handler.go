@@ -52,6 +52,7 @@ type MessageHandler interface {
 
 type transactionContext struct {
 	chainID          string
+	signedProp       *pb.SignedProposal
 	proposal         *pb.Proposal
 	responseNotifier chan *pb.ChaincodeMessage
 
@@ -169,7 +170,7 @@ func (handler *Handler) serialSendAsync(msg *pb.ChaincodeMessage, errc chan erro
 	}()
 }
 
-func (handler *Handler) createTxContext(ctxt context.Context, chainID string, txid string, prop *pb.Proposal) (*transactionContext, error) {
+func (handler *Handler) createTxContext(ctxt context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal) (*transactionContext, error) {
 	if handler.txCtxs == nil {
 		return nil, fmt.Errorf("cannot create notifier for txid:%s", txid)
 	}
@@ -178,7 +179,7 @@ func (handler *Handler) createTxContext(ctxt context.Context, chainID string, tx
 	if handler.txCtxs[txid] != nil {
 		return nil, fmt.Errorf("txid:%s exists", txid)
 	}
-	txctx := &transactionContext{chainID: chainID, proposal: prop, responseNotifier: make(chan *pb.ChaincodeMessage, 1),
+	txctx := &transactionContext{chainID: chainID, signedProp: signedProp, proposal: prop, responseNotifier: make(chan *pb.ChaincodeMessage, 1),
 		rangeQueryIteratorMap: make(map[string]ledger.ResultsIterator)}
 	handler.txCtxs[txid] = txctx
 	txctx.txsimulator = getTxSimulator(ctxt)
@@ -987,14 +988,14 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: chaincodeSpec}
 
 			//Get the latest version of calledCCName
-			cd, err := GetChaincodeDataFromLCCC(ctxt, msg.Txid, txContext.proposal, txContext.chainID, calledCCName)
+			cd, err := GetChaincodeDataFromLCCC(ctxt, msg.Txid, txContext.signedProp, txContext.proposal, txContext.chainID, calledCCName)
 			if err != nil {
 				payload := []byte(err.Error())
 				chaincodeLogger.Debugf("[%s]Failed to get chaincoed data (%s) for invoked chaincode. Sending %s", shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
 				triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 				return
 			}
-			cccid := NewCCContext(txContext.chainID, calledCCName, cd.Version, msg.Txid, false, txContext.proposal)
+			cccid := NewCCContext(txContext.chainID, calledCCName, cd.Version, msg.Txid, false, txContext.signedProp, txContext.proposal)
 
 			// Launch the new chaincode if not already running
 			_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(ctxt, cccid, chaincodeInvocationSpec)
@@ -1081,21 +1082,26 @@ func (handler *Handler) enterEndState(e *fsm.Event, state string) {
 	e.Cancel(fmt.Errorf("Entered end state"))
 }
 
-func (handler *Handler) setChaincodeProposal(prop *pb.Proposal, msg *pb.ChaincodeMessage) error {
+func (handler *Handler) setChaincodeProposal(signedProp *pb.SignedProposal, prop *pb.Proposal, msg *pb.ChaincodeMessage) error {
 	chaincodeLogger.Debug("setting chaincode proposal...")
 	if prop != nil {
-		chaincodeLogger.Debug("TODO pass Proposal to chaincode...")
+		//the chaincode decodes the proposal from the signed proposal, both
+		//have to be given
+		if signedProp == nil {
+			return fmt.Errorf("Failed getting proposal context. Signed proposal is nil")
+		}
+		msg.Proposal = signedProp
 	}
 	return nil
 }
 
 //if initArgs is set (should be for "deploy" only) move to Init
 //else move to ready
-func (handler *Handler) initOrReady(ctxt context.Context, chainID string, txid string, prop *pb.Proposal, initArgs [][]byte) (chan *pb.ChaincodeMessage, error) {
+func (handler *Handler) initOrReady(ctxt context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, initArgs [][]byte) (chan *pb.ChaincodeMessage, error) {
 	var ccMsg *pb.ChaincodeMessage
 	var send bool
 
-	txctx, funcErr := handler.createTxContext(ctxt, chainID, txid, prop)
+	txctx, funcErr := handler.createTxContext(ctxt, chainID, txid, signedProp, prop)
 	if funcErr != nil {
 		return nil, funcErr
 	}
@@ -1119,7 +1125,7 @@ func (handler *Handler) initOrReady(ctxt context.Context, chainID string, txid s
 	}
 
 	//if security is disabled the context elements will just be nil
-	if err := handler.setChaincodeProposal(prop, ccMsg); err != nil {
+	if err := handler.setChaincodeProposal(signedProp, prop, ccMsg); err != nil {
 		return nil, err
 	}
 
@@ -1171,8 +1177,8 @@ func filterError(errFromFSMEvent error) error {
 	return nil
 }
 
-func (handler *Handler) sendExecuteMessage(ctxt context.Context, chainID string, msg *pb.ChaincodeMessage, prop *pb.Proposal) (chan *pb.ChaincodeMessage, error) {
-	txctx, err := handler.createTxContext(ctxt, chainID, msg.Txid, prop)
+func (handler *Handler) sendExecuteMessage(ctxt context.Context, chainID string, msg *pb.ChaincodeMessage, signedProp *pb.SignedProposal, prop *pb.Proposal) (chan *pb.ChaincodeMessage, error) {
+	txctx, err := handler.createTxContext(ctxt, chainID, msg.Txid, signedProp, prop)
 	if err != nil {
 		return nil, err
 	}
@@ -1180,7 +1186,7 @@ func (handler *Handler) sendExecuteMessage(ctxt context.Context, chainID string,
 	chaincodeLogger.Debugf("[%s]Inside sendExecuteMessage. Message %s", shorttxid(msg.Txid), msg.Type.String())
 
 	//if security is disabled the context elements will just be nil
-	if err := handler.setChaincodeProposal(prop, msg); err != nil {
+	if err := handler.setChaincodeProposal(signedProp, prop, msg); err != nil {
 		return nil, err
 	}
 
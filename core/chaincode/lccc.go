@@ -18,24 +18,30 @@ package chaincode
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
+	mspmgmt "github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
 )
 
-//ChaincodeData defines the datastructure for chaincodes to be serialized by proto
+//ChaincodeData defines the datastructure for chaincodes to be serialized by proto.
+//The code itself is not stored on the ledger, only the hash of the package
+//installed on the peers (Id)
 type ChaincodeData struct {
 	Name    string `protobuf:"bytes,1,opt,name=name"`
 	Version string `protobuf:"bytes,2,opt,name=version"`
-	DepSpec []byte `protobuf:"bytes,3,opt,name=depSpec,proto3"`
+	Id      []byte `protobuf:"bytes,3,opt,name=id,proto3"`
 	Escc    string `protobuf:"bytes,4,opt,name=escc"`
 	Vscc    string `protobuf:"bytes,5,opt,name=vscc"`
 	Policy  []byte `protobuf:"bytes,6,opt,name=policy"`
@@ -54,8 +60,9 @@ func (*ChaincodeData) ProtoMessage() {}
 
 //The life cycle system chaincode manages chaincodes deployed
 //on this peer. It manages chaincodes via Invoke proposals.
-//     "Args":["deploy",<ChaincodeDeploymentSpec>]
-//     "Args":["upgrade",<ChaincodeDeploymentSpec>]
+//     "Args":["install",<SignedChaincodeDeploymentSpec>]
+//     "Args":["deploy",<chainname>,<ChaincodeDeploymentSpec>,<policy>,<escc>,<vscc>]
+//     "Args":["upgrade",<chainname>,<ChaincodeDeploymentSpec>,<policy>,<escc>,<vscc>]
 //     "Args":["stop",<ChaincodeInvocationSpec>]
 //     "Args":["start",<ChaincodeInvocationSpec>]
 
@@ -67,6 +74,9 @@ const (
 
 	//chaincode lifecyle commands
 
+	//INSTALL install command
+	INSTALL = "install"
+
 	//DEPLOY deploy command
 	DEPLOY = "deploy"
 
@@ -82,11 +92,18 @@ const (
 	//GETCCDATA get ChaincodeData
 	GETCCDATA = "getccdata"
 
+	//GETCHAINCODES gets the instantiated chaincodes on a channel
+	GETCHAINCODES = "getchaincodes"
+
+	//GETINSTALLEDCHAINCODES gets the installed chaincodes on a peer
+	GETINSTALLEDCHAINCODES = "getinstalledchaincodes"
+
 	//characters used in chaincodenamespace
 	specialChars = "/:[]${}"
 
-	// chaincode version when deploy
-	startVersion = "0"
+	//default endorsement and validation system chaincodes
+	defaultEscc = "escc"
+	defaultVscc = "vscc"
 )
 
 //---------- the LCCC -----------------
@@ -160,6 +177,27 @@ func (t NotFoundErr) Error() string {
 	return fmt.Sprintf("chaincode not found %s", string(t))
 }
 
+//NotInstalledErr chaincode package not installed on this peer error
+type NotInstalledErr string
+
+func (t NotInstalledErr) Error() string {
+	return fmt.Sprintf("chaincode not installed %s", string(t))
+}
+
+//InvalidVersionErr invalid version error
+type InvalidVersionErr string
+
+func (f InvalidVersionErr) Error() string {
+	return fmt.Sprintf("invalid chaincode version %s", string(f))
+}
+
+//IdenticalVersionErr upgrade to the deployed version error
+type IdenticalVersionErr string
+
+func (f IdenticalVersionErr) Error() string {
+	return fmt.Sprintf("chaincode %s is already at that version", string(f))
+}
+
 //InvalidChainNameErr invalid chain name error
 type InvalidChainNameErr string
 
@@ -183,30 +221,42 @@ func (m MarshallErr) Error() string {
 
 //-------------- helper functions ------------------
 //create the chaincode on the given chain
-func (lccc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, cccode []byte) (*ChaincodeData, error) {
-	return lccc.putChaincodeData(stub, chainname, ccname, startVersion, cccode)
+func (lccc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, chainname string, cd *ChaincodeData) error {
+	return lccc.putChaincodeData(stub, chainname, cd)
 }
 
 //upgrade the chaincode on the given chain
-func (lccc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte) (*ChaincodeData, error) {
-	return lccc.putChaincodeData(stub, chainname, ccname, version, cccode)
+func (lccc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, chainname string, cd *ChaincodeData) error {
+	return lccc.putChaincodeData(stub, chainname, cd)
 }
 
 //create the chaincode on the given chain
-func (lccc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, chainname string, ccname string, version string, cccode []byte) (*ChaincodeData, error) {
-	cd := &ChaincodeData{Name: ccname, Version: version, DepSpec: cccode}
+func (lccc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, chainname string, cd *ChaincodeData) error {
 	cdbytes, err := proto.Marshal(cd)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if cdbytes == nil {
-		return nil, MarshallErr(ccname)
+		return MarshallErr(cd.Name)
 	}
 
-	err = stub.PutState(ccname, cdbytes)
+	return stub.PutState(cd.Name, cdbytes)
+}
+
+//getInstalledChaincodeData builds the ChaincodeData to be recorded for the
+//chaincode from the package installed on this peer
+func (lccc *LifeCycleSysCC) getInstalledChaincodeData(cds *pb.ChaincodeDeploymentSpec, policy []byte, escc string, vscc string) (*ChaincodeData, error) {
+	ccname := cds.ChaincodeSpec.ChaincodeID.Name
+	ccversion := cds.ChaincodeSpec.ChaincodeID.Version
+
+	_, installed, err := ccprovider.GetChaincodeFromFS(ccname, ccversion)
+	if err != nil {
+		logger.Debugf("could not get installed package for %s:%s - %s", ccname, ccversion, err)
+		return nil, NotInstalledErr(ccname + ":" + ccversion)
+	}
 
-	return cd, err
+	return &ChaincodeData{Name: ccname, Version: ccversion, Id: ccprovider.GetCodeHash(installed), Escc: escc, Vscc: vscc, Policy: policy}, nil
 }
 
 //checks for existence of chaincode on the given chain
@@ -229,6 +279,54 @@ func (lccc *LifeCycleSysCC) getChaincode(stub shim.ChaincodeStubInterface, chain
 	return nil, nil, NotFoundErr(ccname)
 }
 
+//getChaincodes returns the chaincodes instantiated on the chain by scanning
+//all the ChaincodeData recorded by LCCC
+func (lccc *LifeCycleSysCC) getChaincodes(stub shim.ChaincodeStubInterface) ([]byte, error) {
+	itr, err := stub.RangeQueryState("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+
+	var ccInfoArray []*pb.ChaincodeInfo
+	for itr.HasNext() {
+		ccname, cdbytes, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		cd := &ChaincodeData{}
+		if err = proto.Unmarshal(cdbytes, cd); err != nil {
+			return nil, MarshallErr(ccname)
+		}
+
+		ccInfoArray = append(ccInfoArray, &pb.ChaincodeInfo{Name: cd.Name, Version: cd.Version, Id: cd.Id, Escc: cd.Escc, Vscc: cd.Vscc})
+	}
+
+	cqr := &pb.ChaincodeQueryResponse{Chaincodes: ccInfoArray}
+	cqrbytes, err := proto.Marshal(cqr)
+	if err != nil {
+		return nil, err
+	}
+
+	return cqrbytes, nil
+}
+
+//getInstalledChaincodes returns the chaincodes installed on this peer
+func (lccc *LifeCycleSysCC) getInstalledChaincodes() ([]byte, error) {
+	cqr, err := ccprovider.GetInstalledChaincodes()
+	if err != nil {
+		return nil, err
+	}
+
+	cqrbytes, err := proto.Marshal(cqr)
+	if err != nil {
+		return nil, err
+	}
+
+	return cqrbytes, nil
+}
+
 //getChaincodeDeploymentSpec returns a ChaincodeDeploymentSpec given args
 func (lccc *LifeCycleSysCC) getChaincodeDeploymentSpec(code []byte) (*pb.ChaincodeDeploymentSpec, error) {
 	cds := &pb.ChaincodeDeploymentSpec{}
@@ -255,6 +353,20 @@ func (lccc *LifeCycleSysCC) isValidChainName(chainname string) bool {
 	return true
 }
 
+//check validity of chaincode version
+func (lccc *LifeCycleSysCC) isValidChaincodeVersion(version string) bool {
+	if version == "" {
+		return false
+	}
+
+	//the version is part of the installed package file name
+	if strings.ContainsAny(version, specialChars) {
+		return false
+	}
+
+	return true
+}
+
 //check validity of chaincode name
 func (lccc *LifeCycleSysCC) isValidChaincodeName(chaincodename string) bool {
 	//TODO we probably need more checks
@@ -302,8 +414,7 @@ func (lccc *LifeCycleSysCC) deploy(stub shim.ChaincodeStubInterface, chainname s
 	//TXID of the calling proposal
 	txid := util.GenerateUUID()
 
-	//deploy does not need a version
-	cccid := NewCCContext(chainname, cds.ChaincodeSpec.ChaincodeID.Name, startVersion, txid, false, nil)
+	cccid := NewCCContext(chainname, cds.ChaincodeSpec.ChaincodeID.Name, cds.ChaincodeSpec.ChaincodeID.Version, txid, false, nil, nil)
 
 	_, err = theChaincodeSupport.Deploy(ctxt, cccid, cds)
 	if err != nil {
@@ -322,8 +433,84 @@ func (lccc *LifeCycleSysCC) deploy(stub shim.ChaincodeStubInterface, chainname s
 	return nil
 }
 
+//this implements "install" Invoke transaction. The package is written to
+//the local chaincode store of this peer, nothing is written to the ledger
+func (lccc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, pkgbytes []byte) error {
+	pkg := &pb.SignedChaincodeDeploymentSpec{}
+	if err := proto.Unmarshal(pkgbytes, pkg); err != nil {
+		return InvalidDeploymentSpecErr(err.Error())
+	}
+
+	cds, err := ccpackage.ExtractDeploymentSpec(pkg)
+	if err != nil {
+		return InvalidDeploymentSpecErr(err.Error())
+	}
+
+	if !lccc.isValidChaincodeName(cds.ChaincodeSpec.ChaincodeID.Name) {
+		return InvalidChaincodeNameErr(cds.ChaincodeSpec.ChaincodeID.Name)
+	}
+
+	if !lccc.isValidChaincodeVersion(cds.ChaincodeSpec.ChaincodeID.Version) {
+		return InvalidVersionErr(cds.ChaincodeSpec.ChaincodeID.Version)
+	}
+
+	//the owners are validated when the chaincode is instantiated on a chain,
+	//as they need not belong to the MSPs known to this peer
+	return ccprovider.PutChaincodePackageIntoFS(pkg)
+}
+
+//checkInstantiationPolicy checks that the package installed for the chaincode
+//may be instantiated on the chain by the creator of the transaction proposal:
+//the owner endorsements of the package must be valid on the chain and the
+//creator must satisfy the package's instantiation policy
+func (lccc *LifeCycleSysCC) checkInstantiationPolicy(stub shim.ChaincodeStubInterface, chainname string, cds *pb.ChaincodeDeploymentSpec) error {
+	ccname := cds.ChaincodeSpec.ChaincodeID.Name
+	ccversion := cds.ChaincodeSpec.ChaincodeID.Version
+
+	pkg, err := ccprovider.GetChaincodePackageFromFS(ccname, ccversion)
+	if err != nil {
+		return NotInstalledErr(ccname + ":" + ccversion)
+	}
+
+	deserializer := mspmgmt.GetMSPCommon(chainname)
+	if err = ccpackage.ValidateOwnerEndorsements(pkg, deserializer); err != nil {
+		return err
+	}
+
+	//a package without an instantiation policy can be instantiated by anyone
+	if len(pkg.InstantiationPolicy) == 0 {
+		return nil
+	}
+
+	signedProp, err := stub.GetSignedProposal()
+	if err != nil {
+		return err
+	}
+	if signedProp == nil {
+		return fmt.Errorf("no signed proposal to check the instantiation policy of %s:%s against", ccname, ccversion)
+	}
+
+	prop, err := putils.GetProposal(signedProp.ProposalBytes)
+	if err != nil {
+		return err
+	}
+
+	hdr, err := putils.GetHeader(prop.Header)
+	if err != nil {
+		return err
+	}
+
+	signedData := []*common.SignedData{{
+		Data:      signedProp.ProposalBytes,
+		Identity:  hdr.SignatureHeader.Creator,
+		Signature: signedProp.Signature,
+	}}
+
+	return ccpackage.CheckInstantiationPolicy(pkg, signedData, deserializer)
+}
+
 //this implements "deploy" Invoke transaction
-func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, code []byte) error {
+func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, code []byte, policy []byte, escc string, vscc string) error {
 	cds, err := lccc.getChaincodeDeploymentSpec(code)
 
 	if err != nil {
@@ -334,6 +521,10 @@ func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 		return InvalidChaincodeNameErr(cds.ChaincodeSpec.ChaincodeID.Name)
 	}
 
+	if !lccc.isValidChaincodeVersion(cds.ChaincodeSpec.ChaincodeID.Version) {
+		return InvalidVersionErr(cds.ChaincodeSpec.ChaincodeID.Version)
+	}
+
 	if err = lccc.acl(stub, chainname, cds); err != nil {
 		return err
 	}
@@ -351,13 +542,20 @@ func (lccc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 		 *}
 		 **/
 
-	_, err = lccc.createChaincode(stub, chainname, cds.ChaincodeSpec.ChaincodeID.Name, code)
+	if err = lccc.checkInstantiationPolicy(stub, chainname, cds); err != nil {
+		return err
+	}
+
+	cd, err = lccc.getInstalledChaincodeData(cds, policy, escc, vscc)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return lccc.createChaincode(stub, chainname, cd)
 }
 
 //this implements "upgrade" Invoke transaction
-func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, code []byte) ([]byte, error) {
+func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, code []byte, policy []byte, escc string, vscc string) ([]byte, error) {
 	cds, err := lccc.getChaincodeDeploymentSpec(code)
 	if err != nil {
 		return nil, err
@@ -372,62 +570,101 @@ func (lccc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 		return nil, InvalidChaincodeNameErr(chaincodeName)
 	}
 
+	newVersion := cds.ChaincodeSpec.ChaincodeID.Version
+	if !lccc.isValidChaincodeVersion(newVersion) {
+		return nil, InvalidVersionErr(newVersion)
+	}
+
 	// check for existence of chaincode
 	cd, _, err := lccc.getChaincode(stub, chainName, chaincodeName)
 	if cd == nil {
 		return nil, NotFoundErr(chainName)
 	}
 
-	// if (ever) we allow users to specify a "deployed" version, this will have to change so
-	// accept that. We might then require that users provide an "upgrade" version too. In
-	// that case we'd replace the increment below with a uniqueness check. For now we will
-	// assume Version is internal and is a number.
-	//
-	// NOTE - system chaincodes use the fabric's version and hence are not numbers.
-	// As they cannot be "upgraded" via LCCC, that's not an issue. But they do help illustrate
-	// the kind of issues we will have if we allow users to specify version.
-	v, err := strconv.ParseInt(cd.Version, 10, 32)
+	//versions are chosen by the user when installing, so just make sure
+	//we are actually moving to a different one
+	if cd.Version == newVersion {
+		return nil, IdenticalVersionErr(chaincodeName + ":" + newVersion)
+	}
 
-	//This should never happen as long we don't expose version as version is computed internally
-	//so panic till we find a need to relax
-	if err != nil {
-		panic(fmt.Sprintf("invalid version %s/%s [err: %s]", chaincodeName, chainName, err))
+	if err = lccc.checkInstantiationPolicy(stub, chainName, cds); err != nil {
+		return nil, err
 	}
 
-	// replace the ChaincodeDeploymentSpec using the next version
-	newVersion := fmt.Sprintf("%d", (v + 1))
-	newCD, err := lccc.upgradeChaincode(stub, chainName, chaincodeName, newVersion, code)
+	newCD, err := lccc.getInstalledChaincodeData(cds, policy, escc, vscc)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = lccc.upgradeChaincode(stub, chainName, newCD); err != nil {
+		return nil, err
+	}
+
 	return []byte(newCD.Version), nil
 }
 
+//getDeployArgs returns the optional policy, escc and vscc arguments of deploy
+//and upgrade, applying the defaults for the ones not provided
+func (lccc *LifeCycleSysCC) getDeployArgs(args [][]byte) ([]byte, string, string) {
+	var policy []byte
+	escc := defaultEscc
+	vscc := defaultVscc
+
+	if len(args) > 3 && len(args[3]) > 0 {
+		policy = args[3]
+	}
+
+	if len(args) > 4 && len(args[4]) > 0 {
+		escc = string(args[4])
+	}
+
+	if len(args) > 5 && len(args[5]) > 0 {
+		vscc = string(args[5])
+	}
+
+	return policy, escc, vscc
+}
+
 //-------------- the chaincode stub interface implementation ----------
 
 //Init does nothing
-func (lccc *LifeCycleSysCC) Init(stub shim.ChaincodeStubInterface) ([]byte, error) {
-	return nil, nil
+func (lccc *LifeCycleSysCC) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
 }
 
-// Invoke implements lifecycle functions "deploy", "start", "stop", "upgrade".
+// Invoke implements lifecycle functions "install", "deploy", "start", "stop", "upgrade".
+// Install's arguments - {[]byte("install"), <unmarshalled pb.SignedChaincodeDeploymentSpec>}
 // Deploy's arguments -  {[]byte("deploy"), []byte(<chainname>), <unmarshalled pb.ChaincodeDeploymentSpec>}
+// optionally followed by the endorsement policy, []byte(<escc>) and []byte(<vscc>)
 //
 // Invoke also implements some query-like functions
 // Get chaincode arguments -  {[]byte("getid"), []byte(<chainname>), []byte(<chaincodename>)}
-func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
+// Get instantiated chaincodes arguments - {[]byte("getchaincodes")}
+// Get installed chaincodes arguments - {[]byte("getinstalledchaincodes")}
+func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 	if len(args) < 1 {
-		return nil, InvalidArgsLenErr(len(args))
+		return shim.Error(InvalidArgsLenErr(len(args)).Error())
 	}
 
 	function := string(args[0])
 
 	switch function {
+	case INSTALL:
+		if len(args) != 2 {
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
+		}
+
+		//bytes corresponding to the signed chaincode package
+		pkgbytes := args[1]
+
+		if err := lccc.executeInstall(stub, pkgbytes); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
 	case DEPLOY:
-		if len(args) != 3 {
-			return nil, InvalidArgsLenErr(len(args))
+		if len(args) < 3 || len(args) > 6 {
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
 		//chain the chaincode shoud be associated with. It
@@ -435,30 +672,40 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) ([]byte, er
 		chainname := string(args[1])
 
 		if !lccc.isValidChainName(chainname) {
-			return nil, InvalidChainNameErr(chainname)
+			return shim.Error(InvalidChainNameErr(chainname).Error())
 		}
 
 		//bytes corresponding to deployment spec
 		code := args[2]
 
-		err := lccc.executeDeploy(stub, chainname, code)
+		policy, escc, vscc := lccc.getDeployArgs(args)
 
-		return nil, err
+		if err := lccc.executeDeploy(stub, chainname, code, policy, escc, vscc); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
 	case UPGRADE:
-		if len(args) != 3 {
-			return nil, InvalidArgsLenErr(len(args))
+		if len(args) < 3 || len(args) > 6 {
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
 		chainname := string(args[1])
 		if !lccc.isValidChainName(chainname) {
-			return nil, InvalidChainNameErr(chainname)
+			return shim.Error(InvalidChainNameErr(chainname).Error())
 		}
 
 		code := args[2]
-		return lccc.executeUpgrade(stub, chainname, code)
+
+		policy, escc, vscc := lccc.getDeployArgs(args)
+
+		verBytes, err := lccc.executeUpgrade(stub, chainname, code, policy, escc, vscc)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(verBytes)
 	case GETCCINFO, GETDEPSPEC, GETCCDATA:
 		if len(args) != 3 {
-			return nil, InvalidArgsLenErr(len(args))
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
 		chain := string(args[1])
@@ -468,16 +715,43 @@ func (lccc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) ([]byte, er
 		cd, cdbytes, _ := lccc.getChaincode(stub, chain, ccname)
 		if cd == nil || cdbytes == nil {
 			logger.Debug("ChaincodeID [%s/%s] does not exist", chain, ccname)
-			return nil, TXNotFoundErr(ccname + "/" + chain)
+			return shim.Error(TXNotFoundErr(ccname + "/" + chain).Error())
 		}
 
 		if function == GETCCINFO {
-			return []byte(cd.Name), nil
+			return shim.Success([]byte(cd.Name))
 		} else if function == GETCCDATA {
-			return cdbytes, nil
+			return shim.Success(cdbytes)
+		}
+
+		//the deployment spec is not on the ledger, get it from the package
+		//installed on this peer
+		depspec, _, err := ccprovider.GetChaincodeFromFS(cd.Name, cd.Version)
+		if err != nil {
+			return shim.Error(NotInstalledErr(cd.Name + ":" + cd.Version).Error())
+		}
+		return shim.Success(depspec)
+	case GETCHAINCODES:
+		if len(args) != 1 {
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
+		}
+
+		cqrbytes, err := lccc.getChaincodes(stub)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(cqrbytes)
+	case GETINSTALLEDCHAINCODES:
+		if len(args) != 1 {
+			return shim.Error(InvalidArgsLenErr(len(args)).Error())
+		}
+
+		cqrbytes, err := lccc.getInstalledChaincodes()
+		if err != nil {
+			return shim.Error(err.Error())
 		}
-		return cd.DepSpec, nil
+		return shim.Success(cqrbytes)
 	}
 
-	return nil, InvalidFunctionErr(function)
+	return shim.Error(InvalidFunctionErr(function).Error())
 }
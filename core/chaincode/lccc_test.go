@@ -16,31 +16,49 @@ limitations under the License.
 package chaincode
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/container"
+	"github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/testtools"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"google.golang.org/grpc"
 )
 
 func register(stub *shim.MockStub, ccname string) error {
 	args := [][]byte{[]byte("register"), []byte(ccname)}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		return err
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		return fmt.Errorf("%s", res.Message)
 	}
 	return nil
 }
 
-func constructDeploymentSpec(name string, path string, initArgs [][]byte) (*pb.ChaincodeDeploymentSpec, error) {
-	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: name, Path: path}, CtorMsg: &pb.ChaincodeInput{Args: initArgs}}
+func constructDeploymentSpec(name string, path string, version string, initArgs [][]byte, createFS bool) (*pb.ChaincodeDeploymentSpec, error) {
+	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: name, Path: path, Version: version}, CtorMsg: &pb.ChaincodeInput{Args: initArgs}}
 	codePackageBytes, err := container.GetChaincodePackageBytes(spec)
 	if err != nil {
 		return nil, err
 	}
 	chaincodeDeploymentSpec := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: codePackageBytes}
+	if createFS {
+		if err = ccprovider.PutChaincodeIntoFS(chaincodeDeploymentSpec); err != nil {
+			return nil, err
+		}
+	}
 	return chaincodeDeploymentSpec, nil
 }
 
@@ -59,6 +77,11 @@ func initialize() {
 
 	ccStartupTimeout := time.Duration(30000) * time.Millisecond
 	pb.RegisterChaincodeSupportServer(grpcServer, NewChaincodeSupport(getPeerEndpoint, false, ccStartupTimeout))
+
+	//start every test with an empty chaincode install path
+	ccpath := filepath.Join(os.TempDir(), "lccctest", "chaincodes")
+	os.RemoveAll(ccpath)
+	ccprovider.SetChaincodesPath(ccpath)
 }
 
 //TestDeploy tests the deploy function (stops short of actually running the chaincode)
@@ -68,14 +91,14 @@ func TestDeploy(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 }
@@ -89,8 +112,7 @@ func TestInvalidCodeDeploy(t *testing.T) {
 
 	baddepspec := []byte("bad deploy spec")
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), baddepspec}
-	_, err := stub.MockInvoke("1", args)
-	if _, ok := err.(InvalidDeploymentSpecErr); !ok {
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "Invalid deployment spec") {
 		t.FailNow()
 	}
 }
@@ -102,7 +124,7 @@ func TestInvalidChaincodeName(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 
 	//change name to empty
 	cds.ChaincodeSpec.ChaincodeID.Name = ""
@@ -113,8 +135,7 @@ func TestInvalidChaincodeName(t *testing.T) {
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	_, err = stub.MockInvoke("1", args)
-	if _, ok := err.(InvalidChaincodeNameErr); !ok {
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "invalid chain code name") {
 		t.FailNow()
 	}
 }
@@ -126,21 +147,20 @@ func TestRedeploy(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	//this should fail with exists error
 	args = [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	_, err = stub.MockInvoke("1", args)
-	if _, ok := err.(ExistsErr); !ok {
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "Chaincode exists") {
 		t.FailNow()
 	}
 }
@@ -152,19 +172,19 @@ func TestCheckCC(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	args = [][]byte{[]byte(GETCCINFO), []byte("test"), []byte(cds.ChaincodeSpec.ChaincodeID.Name)}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 }
@@ -177,35 +197,35 @@ func TestMultipleDeploy(t *testing.T) {
 	stub := shim.NewMockStub("lccc", scc)
 
 	//deploy 02
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	args = [][]byte{[]byte(GETCCINFO), []byte("test"), []byte(cds.ChaincodeSpec.ChaincodeID.Name)}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	//deploy 01
-	cds, err = constructDeploymentSpec("example01", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example01", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err = constructDeploymentSpec("example01", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example01", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
 	}
 
 	args = [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	args = [][]byte{[]byte(GETCCINFO), []byte("test"), []byte(cds.ChaincodeSpec.ChaincodeID.Name)}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 }
@@ -218,7 +238,7 @@ func TestRetryFailedDeploy(t *testing.T) {
 	stub := shim.NewMockStub("lccc", scc)
 
 	//deploy 02
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.FailNow()
@@ -226,7 +246,7 @@ func TestRetryFailedDeploy(t *testing.T) {
 
 	//send invalid chain name name that should fail
 	args := [][]byte{[]byte(DEPLOY), []byte(""), b}
-	if _, err = stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		//expected error but got success
 		t.FailNow()
 	}
@@ -238,13 +258,13 @@ func TestRetryFailedDeploy(t *testing.T) {
 
 	//deploy correctly now
 	args = [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
 		t.FailNow()
 	}
 
 	//get the deploymentspec
 	args = [][]byte{[]byte(GETDEPSPEC), []byte("test"), []byte(cds.ChaincodeSpec.ChaincodeID.Name)}
-	if depspec, err := stub.MockInvoke("1", args); err != nil || depspec == nil {
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK || res.Payload == nil {
 		t.FailNow()
 	}
 }
@@ -256,28 +276,29 @@ func TestUpgrade(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.Fatalf("Marshal DeploymentSpec failed")
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("Deploy chaincode error: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %v", res.Message)
 	}
 
-	newCds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	newCds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "1", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var newb []byte
 	if newb, err = proto.Marshal(newCds); err != nil || newb == nil {
 		t.Fatalf("Marshal DeploymentSpec failed")
 	}
 
 	args = [][]byte{[]byte(UPGRADE), []byte("test"), newb}
-	version, err := stub.MockInvoke("1", args)
-	if err != nil {
-		t.Fatalf("Upgrade chaincode error: %v", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("Upgrade chaincode error: %v", res.Message)
 	}
+	version := res.Payload
 
 	expectVer := "1"
 	newVer := string(version)
@@ -293,26 +314,346 @@ func TestUpgradeNonExistChaincode(t *testing.T) {
 	scc := new(LifeCycleSysCC)
 	stub := shim.NewMockStub("lccc", scc)
 
-	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var b []byte
 	if b, err = proto.Marshal(cds); err != nil || b == nil {
 		t.Fatalf("Marshal DeploymentSpec failed")
 	}
 
 	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("Deploy chaincode error: %v", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %v", res.Message)
 	}
 
-	newCds, err := constructDeploymentSpec("example03", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")})
+	newCds, err := constructDeploymentSpec("example03", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 	var newb []byte
 	if newb, err = proto.Marshal(newCds); err != nil || newb == nil {
 		t.Fatalf("Marshal DeploymentSpec failed")
 	}
 
 	args = [][]byte{[]byte(UPGRADE), []byte("test"), newb}
-	_, err = stub.MockInvoke("1", args)
-	if _, ok := err.(NotFoundErr); !ok {
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "chaincode not found") {
 		t.FailNow()
 	}
 }
+
+//constructInstallPackage wraps the deployment spec into an unsigned package
+func constructInstallPackage(cds *pb.ChaincodeDeploymentSpec) ([]byte, error) {
+	pkg, err := ccpackage.OwnerCreateSignedCCDepSpec(cds, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(pkg)
+}
+
+//TestInstall tests the install function
+func TestInstall(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, false)
+	b, err := constructInstallPackage(cds)
+	if err != nil {
+		t.Fatalf("Creating chaincode package failed: %s", err)
+	}
+
+	args := [][]byte{[]byte(INSTALL), b}
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Install chaincode error: %v", res.Message)
+	}
+
+	if _, _, err := ccprovider.GetChaincodeFromFS("example02", "0"); err != nil {
+		t.Fatalf("Installed chaincode not found: %v", err)
+	}
+
+	//installing the same name and version again should fail
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Expected error reinstalling chaincode")
+	}
+}
+
+//TestInstallWithoutVersion tests install fails without a version
+func TestInstallWithoutVersion(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, false)
+	b, err := constructInstallPackage(cds)
+	if err != nil {
+		t.Fatalf("Creating chaincode package failed: %s", err)
+	}
+
+	args := [][]byte{[]byte(INSTALL), b}
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "invalid chaincode version") {
+		t.Fatalf("Expected InvalidVersionErr, got %v", res.Message)
+	}
+}
+
+//TestDeployNotInstalled tests deploying a chaincode that was not installed
+func TestDeployNotInstalled(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, false)
+	var b []byte
+	if b, err = proto.Marshal(cds); err != nil || b == nil {
+		t.Fatalf("Marshal DeploymentSpec failed")
+	}
+
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
+	if res := stub.MockInvoke("1", args); !strings.HasPrefix(res.Message, "chaincode not installed") {
+		t.Fatalf("Expected NotInstalledErr, got %v", res.Message)
+	}
+}
+
+//TestDeployRecordsChaincodeData tests that only the chaincode data is recorded on deploy
+func TestDeployRecordsChaincodeData(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	var b []byte
+	if b, err = proto.Marshal(cds); err != nil || b == nil {
+		t.Fatalf("Marshal DeploymentSpec failed")
+	}
+
+	//send the deploy without the code package, as the CLI does
+	cds.CodePackage = nil
+	if b, err = proto.Marshal(cds); err != nil || b == nil {
+		t.Fatalf("Marshal DeploymentSpec failed")
+	}
+
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b, []byte("policy"), []byte("myescc"), []byte("")}
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %v", res.Message)
+	}
+
+	args = [][]byte{[]byte(GETCCDATA), []byte("test"), []byte("example02")}
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("Get chaincode data error: %v", res.Message)
+	}
+	cdbytes := res.Payload
+
+	cd := &ChaincodeData{}
+	if err = proto.Unmarshal(cdbytes, cd); err != nil {
+		t.Fatalf("Unmarshal chaincode data error: %v", err)
+	}
+
+	_, installed, _ := ccprovider.GetChaincodeFromFS("example02", "0")
+	if cd.Version != "0" || cd.Escc != "myescc" || cd.Vscc != defaultVscc || string(cd.Policy) != "policy" || !bytes.Equal(cd.Id, ccprovider.GetCodeHash(installed)) {
+		t.Fatalf("Unexpected chaincode data %v", cd)
+	}
+}
+
+//TestUpgradeSameVersion tests upgrading to the deployed version fails
+func TestUpgradeSameVersion(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	var b []byte
+	if b, err = proto.Marshal(cds); err != nil || b == nil {
+		t.Fatalf("Marshal DeploymentSpec failed")
+	}
+
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy chaincode error: %v", res.Message)
+	}
+
+	args = [][]byte{[]byte(UPGRADE), []byte("test"), b}
+	if res := stub.MockInvoke("1", args); !strings.HasSuffix(res.Message, "is already at that version") {
+		t.Fatalf("Expected IdenticalVersionErr, got %v", res.Message)
+	}
+}
+
+//TestGetChaincodes tests listing the instantiated and installed chaincodes
+func TestGetChaincodes(t *testing.T) {
+	initialize()
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	for _, ccname := range []string{"example02", "example02b"} {
+		cds, err := constructDeploymentSpec(ccname, "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+		var b []byte
+		if b, err = proto.Marshal(cds); err != nil || b == nil {
+			t.Fatalf("Marshal DeploymentSpec failed")
+		}
+
+		args := [][]byte{[]byte(DEPLOY), []byte("test"), b}
+		if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+			t.Fatalf("Deploy chaincode error: %v", res.Message)
+		}
+	}
+
+	for _, function := range []string{GETCHAINCODES, GETINSTALLEDCHAINCODES} {
+		args := [][]byte{[]byte(function)}
+		res := stub.MockInvoke("1", args)
+		if res.Status != shim.OK {
+			t.Fatalf("%s error: %v", function, res.Message)
+		}
+		cqrbytes := res.Payload
+
+		cqr := &pb.ChaincodeQueryResponse{}
+		if err := proto.Unmarshal(cqrbytes, cqr); err != nil {
+			t.Fatalf("Unmarshal %s response error: %v", function, err)
+		}
+
+		if len(cqr.Chaincodes) != 2 || cqr.Chaincodes[0].Name != "example02" || cqr.Chaincodes[1].Name != "example02b" {
+			t.Fatalf("Unexpected %s response %v", function, cqr)
+		}
+
+		for _, info := range cqr.Chaincodes {
+			if info.Version != "0" || len(info.Id) == 0 {
+				t.Fatalf("Unexpected chaincode info %v", info)
+			}
+		}
+	}
+
+	//extra arguments are rejected
+	args := [][]byte{[]byte(GETCHAINCODES), []byte("test")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Expected error with extra arguments")
+	}
+}
+
+//newPolicyTestIdentity returns a signing identity of a newly generated MSP.
+//If chainID is not empty the MSP is also set up as the MSP of that chain
+func newPolicyTestIdentity(t *testing.T, chainID string) msp.SigningIdentity {
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		t.Fatalf("Could not generate msp: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf, err := msp.GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("Could not load msp config: %s", err)
+	}
+
+	if chainID != "" {
+		if err = mspmgmt.GetManagerForChain(chainID).Setup([]*mspprotos.MSPConfig{conf}); err != nil {
+			t.Fatalf("Could not setup msp manager: %s", err)
+		}
+	}
+
+	m, err := msp.NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Could not create msp: %s", err)
+	}
+	if err = m.Setup(conf); err != nil {
+		t.Fatalf("Could not setup msp: %s", err)
+	}
+
+	id, err := m.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("Could not get signing identity: %s", err)
+	}
+
+	return id
+}
+
+//signedDeployProposal returns the deploy proposal of cds signed by signer
+func signedDeployProposal(t *testing.T, chainID string, cds *pb.ChaincodeDeploymentSpec, signer msp.SigningIdentity) *pb.SignedProposal {
+	creator, err := signer.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize identity: %s", err)
+	}
+
+	prop, _, err := putils.CreateDeployProposalFromCDS(chainID, cds, creator)
+	if err != nil {
+		t.Fatalf("Could not create proposal: %s", err)
+	}
+
+	signedProp, err := putils.GetSignedProposal(prop, signer)
+	if err != nil {
+		t.Fatalf("Could not sign proposal: %s", err)
+	}
+
+	return signedProp
+}
+
+//TestInstantiationPolicy tests that deploy and upgrade enforce the
+//instantiation policy of the installed package
+func TestInstantiationPolicy(t *testing.T) {
+	initialize()
+
+	chainID := "instpolicychain"
+	owner := newPolicyTestIdentity(t, chainID)
+	stranger := newPolicyTestIdentity(t, "")
+
+	creator, err := owner.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize identity: %s", err)
+	}
+	policy := cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{creator})
+
+	install := func(version string) *pb.ChaincodeDeploymentSpec {
+		cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", version, [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, false)
+		if err != nil {
+			t.Fatalf("Could not create deployment spec: %s", err)
+		}
+		pkg, err := ccpackage.OwnerCreateSignedCCDepSpec(cds, policy, owner)
+		if err != nil {
+			t.Fatalf("Could not create package: %s", err)
+		}
+		if err = ccprovider.PutChaincodePackageIntoFS(pkg); err != nil {
+			t.Fatalf("Could not install package: %s", err)
+		}
+		return cds
+	}
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lccc", scc)
+
+	cds := install("0")
+	b, err := proto.Marshal(cds)
+	if err != nil {
+		t.Fatalf("Could not marshal deployment spec: %s", err)
+	}
+	args := [][]byte{[]byte(DEPLOY), []byte(chainID), b}
+
+	//without a proposal the policy cannot be checked
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Expected deploy without a proposal to fail")
+	}
+
+	stub.SetSignedProposal(signedDeployProposal(t, chainID, cds, stranger))
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Expected deploy by an identity not satisfying the policy to fail")
+	}
+
+	stub.SetSignedProposal(signedDeployProposal(t, chainID, cds, owner))
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Deploy by the owner failed: %s", res.Message)
+	}
+
+	cds = install("1")
+	if b, err = proto.Marshal(cds); err != nil {
+		t.Fatalf("Could not marshal deployment spec: %s", err)
+	}
+	args = [][]byte{[]byte(UPGRADE), []byte(chainID), b}
+
+	stub.SetSignedProposal(signedDeployProposal(t, chainID, cds, stranger))
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("Expected upgrade by an identity not satisfying the policy to fail")
+	}
+
+	stub.SetSignedProposal(signedDeployProposal(t, chainID, cds, owner))
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("Upgrade by the owner failed: %s", res.Message)
+	}
+}
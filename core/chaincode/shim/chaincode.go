@@ -20,6 +20,8 @@ package shim
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"flag"
 	"fmt"
@@ -32,6 +34,7 @@ import (
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -49,6 +52,13 @@ type ChaincodeStub struct {
 	chaincodeEvent *pb.ChaincodeEvent
 	args           [][]byte
 	handler        *Handler
+	signedProposal *pb.SignedProposal
+
+	// fields decoded from signedProposal
+	creator     []byte
+	transient   []byte
+	binding     []byte
+	txTimestamp *timestamp.Timestamp
 }
 
 // Peer address derived from command line or env var
@@ -266,17 +276,60 @@ func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode
 // -- init stub ---
 // ChaincodeInvocation functionality
 
-func (stub *ChaincodeStub) init(handler *Handler, txid string, input *pb.ChaincodeInput) {
+func (stub *ChaincodeStub) init(handler *Handler, txid string, input *pb.ChaincodeInput, signedProposal *pb.SignedProposal) error {
 	stub.TxID = txid
 	stub.args = input.Args
 	stub.handler = handler
+	stub.signedProposal = signedProposal
+
+	// There is no proposal when the peer itself initializes the chaincode,
+	// as for system chaincodes
+	if signedProposal == nil {
+		return nil
+	}
+
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return fmt.Errorf("Failed extracting proposal from signed proposal: %s", err)
+	}
+	hdr := &common.Header{}
+	if err := proto.Unmarshal(proposal.Header, hdr); err != nil {
+		return fmt.Errorf("Failed extracting header from proposal: %s", err)
+	}
+	if hdr.ChainHeader == nil || hdr.SignatureHeader == nil {
+		return errors.New("Invalid proposal header, chain header or signature header missing")
+	}
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return fmt.Errorf("Failed extracting payload from proposal: %s", err)
+	}
+
+	stub.creator = hdr.SignatureHeader.Creator
+	stub.transient = payload.Transient
+	stub.txTimestamp = hdr.ChainHeader.Timestamp
+	stub.binding = computeBinding(hdr)
+
+	return nil
+}
+
+// computeBinding returns the SHA256 hash of the nonce, the creator and the
+// epoch (8 bytes, little endian) of the proposal header
+func computeBinding(hdr *common.Header) []byte {
+	epoch := make([]byte, 8)
+	binary.LittleEndian.PutUint64(epoch, hdr.ChainHeader.Epoch)
+
+	h := sha256.New()
+	h.Write(hdr.SignatureHeader.Nonce)
+	h.Write(hdr.SignatureHeader.Creator)
+	h.Write(epoch)
+	return h.Sum(nil)
 }
 
 func InitTestStub(funargs ...string) *ChaincodeStub {
 	stub := ChaincodeStub{}
 	allargs := util.ToChaincodeArgs(funargs...)
 	newCI := &pb.ChaincodeInput{Args: allargs}
-	stub.init(&Handler{}, "TEST-txid", newCI)
+	stub.init(&Handler{}, "TEST-txid", newCI, nil)
 	return &stub
 }
 
@@ -663,9 +716,29 @@ func (stub *ChaincodeStub) GetCallerMetadata() ([]byte, error) {
 	return nil, nil
 }
 
-// GetBinding returns the transaction binding
+// GetCreator returns the serialized identity of the client that created the
+// transaction proposal, as found in its SignatureHeader
+func (stub *ChaincodeStub) GetCreator() ([]byte, error) {
+	return stub.creator, nil
+}
+
+// GetTransient returns the transient field of the transaction proposal
+// payload. It carries data, like cryptographic material, that the
+// chaincode may use but that is never written to the ledger
+func (stub *ChaincodeStub) GetTransient() ([]byte, error) {
+	return stub.transient, nil
+}
+
+// GetBinding returns the transaction binding, a hash of the nonce, creator
+// and epoch of the proposal. It lets the chaincode tie application data to
+// this proposal and so prevent the data from being replayed in another one
 func (stub *ChaincodeStub) GetBinding() ([]byte, error) {
-	return nil, nil
+	return stub.binding, nil
+}
+
+// GetSignedProposal returns the signed transaction proposal
+func (stub *ChaincodeStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return stub.signedProposal, nil
 }
 
 // GetPayload returns transaction payload, which is a `ChaincodeSpec` defined
@@ -674,11 +747,11 @@ func (stub *ChaincodeStub) GetPayload() ([]byte, error) {
 	return nil, nil
 }
 
-// GetTxTimestamp returns transaction created timestamp, which is currently
-// taken from the peer receiving the transaction. Note that this timestamp
-// may not be the same with the other peers' time.
+// GetTxTimestamp returns the timestamp set by the client in the ChainHeader
+// of the transaction proposal. It is the same for all the endorsers of the
+// transaction.
 func (stub *ChaincodeStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
-	return nil, nil
+	return stub.txTimestamp, nil
 }
 
 func getTable(stub ChaincodeStubInterface, tableName string) (*Table, error) {
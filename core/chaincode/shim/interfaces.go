@@ -19,18 +19,21 @@ package shim
 
 import (
 	"github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 // Chaincode interface must be implemented by all chaincodes. The fabric runs
 // the transactions by calling these functions as specified.
 type Chaincode interface {
 	// Init is called during Deploy transaction after the container has been
-	// established, allowing the chaincode to initialize its internal data
-	Init(stub ChaincodeStubInterface) ([]byte, error)
+	// established, allowing the chaincode to initialize its internal data.
+	// A response with a status at or above ERRORTHRESHOLD fails the deployment
+	Init(stub ChaincodeStubInterface) pb.Response
 
 	// Invoke is called for every Invoke transactions. The chaincode may change
-	// its state variables
-	Invoke(stub ChaincodeStubInterface) ([]byte, error)
+	// its state variables. A response with a status at or above ERRORTHRESHOLD
+	// is returned to the client without being endorsed
+	Invoke(stub ChaincodeStubInterface) pb.Response
 }
 
 // ChaincodeStubInterface is used by deployable chaincode apps to access and modify their ledgers
@@ -115,16 +118,31 @@ type ChaincodeStubInterface interface {
 	// GetCallerMetadata returns caller metadata
 	GetCallerMetadata() ([]byte, error)
 
-	// GetBinding returns the transaction binding
+	// GetCreator returns the serialized identity of the client that created
+	// the transaction proposal. It is nil if the transaction has no proposal
+	GetCreator() ([]byte, error)
+
+	// GetTransient returns the transient field of the transaction proposal
+	// payload. It carries data, like cryptographic material, that the
+	// chaincode may use but that is never written to the ledger
+	GetTransient() ([]byte, error)
+
+	// GetBinding returns the transaction binding, a hash of the nonce,
+	// creator and epoch of the proposal. It lets the chaincode tie
+	// application data to this proposal
 	GetBinding() ([]byte, error)
 
+	// GetSignedProposal returns the signed transaction proposal. It is nil if
+	// the transaction has no proposal
+	GetSignedProposal() (*pb.SignedProposal, error)
+
 	// GetPayload returns transaction payload, which is a `ChaincodeSpec` defined
 	// in fabric/protos/chaincode.proto
 	GetPayload() ([]byte, error)
 
-	// GetTxTimestamp returns transaction created timestamp, which is currently
-	// taken from the peer receiving the transaction. Note that this timestamp
-	// may not be the same with the other peers' time.
+	// GetTxTimestamp returns the timestamp set by the client in the
+	// ChainHeader of the transaction proposal. It is the same for all the
+	// endorsers of the transaction.
 	GetTxTimestamp() (*timestamp.Timestamp, error)
 
 	// SetEvent saves the event to be sent when a transaction is made part of a block
@@ -29,6 +29,7 @@ import org.hyperledger.java.fsm.exceptions.NoTransitionException;
 import org.hyperledger.java.helper.Channel;
 import org.hyperledger.protos.Chaincode.*;
 import org.hyperledger.protos.Chaincode.ChaincodeMessage.Builder;
+import protos.FabricProposalResponse.Response;
 
 import java.util.HashMap;
 import java.util.List;
@@ -224,7 +225,7 @@ public class Handler {
 				// Send COMPLETED message to chaincode support and change state
 				nextStatemessage = ChaincodeMessage.newBuilder()
 						.setType(COMPLETED)
-						.setPayload(result)
+						.setPayload(successResponse(result))
 						.setTxid(message.getTxid())
 						.build();
 
@@ -326,11 +327,11 @@ public class Handler {
 						shortID(message), COMPLETED));
 
 				// Send COMPLETED message to chaincode support and change state
-				Builder builder = ChaincodeMessage.newBuilder()
+				nextStatemessage = ChaincodeMessage.newBuilder()
 						.setType(COMPLETED)
-						.setTxid(message.getTxid());
-				if (response != null) builder.setPayload(response);
-				nextStatemessage = builder.build();
+						.setPayload(successResponse(response))
+						.setTxid(message.getTxid())
+						.build();
 			} finally {
 				triggerNextState(nextStatemessage, send);
 			}
@@ -788,6 +789,14 @@ public class Handler {
 		}
 	}
 	
+	// successResponse wraps the result of Init or Invoke in the Response the
+	// peer expects in the payload of a COMPLETED message
+	private ByteString successResponse(ByteString result) {
+		Response.Builder builder = Response.newBuilder().setStatus(200);
+		if (result != null) builder.setPayload(result);
+		return builder.build().toByteString();
+	}
+
 	private String shortID(ChaincodeMessage message) {
 		return shortID(message.getTxid());
 	}
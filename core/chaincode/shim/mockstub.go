@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/op/go-logging"
 )
 
@@ -55,6 +56,38 @@ type MockStub struct {
 	// stores a transaction uuid while being Invoked / Deployed
 	// TODO if a chaincode uses recursion this may need to be a stack of TxIDs or possibly a reference counting map
 	TxID string
+
+	// proposal data returned to the chaincode, set with the Set functions
+	creator        []byte
+	transient      []byte
+	binding        []byte
+	signedProposal *pb.SignedProposal
+	txTimestamp    *timestamp.Timestamp
+}
+
+// SetCreator sets the identity returned by GetCreator
+func (stub *MockStub) SetCreator(creator []byte) {
+	stub.creator = creator
+}
+
+// SetTransient sets the data returned by GetTransient
+func (stub *MockStub) SetTransient(transient []byte) {
+	stub.transient = transient
+}
+
+// SetBinding sets the binding returned by GetBinding
+func (stub *MockStub) SetBinding(binding []byte) {
+	stub.binding = binding
+}
+
+// SetSignedProposal sets the proposal returned by GetSignedProposal
+func (stub *MockStub) SetSignedProposal(signedProposal *pb.SignedProposal) {
+	stub.signedProposal = signedProposal
+}
+
+// SetTxTimestamp sets the timestamp returned by GetTxTimestamp
+func (stub *MockStub) SetTxTimestamp(txTimestamp *timestamp.Timestamp) {
+	stub.txTimestamp = txTimestamp
 }
 
 func (stub *MockStub) GetTxID() string {
@@ -105,21 +138,21 @@ func (stub *MockStub) MockPeerChaincode(invokableChaincodeName string, otherStub
 }
 
 // Initialise this chaincode,  also starts and ends a transaction.
-func (stub *MockStub) MockInit(uuid string, args [][]byte) ([]byte, error) {
+func (stub *MockStub) MockInit(uuid string, args [][]byte) pb.Response {
 	stub.args = args
 	stub.MockTransactionStart(uuid)
-	bytes, err := stub.cc.Init(stub)
+	res := stub.cc.Init(stub)
 	stub.MockTransactionEnd(uuid)
-	return bytes, err
+	return res
 }
 
 // Invoke this chaincode, also starts and ends a transaction.
-func (stub *MockStub) MockInvoke(uuid string, args [][]byte) ([]byte, error) {
+func (stub *MockStub) MockInvoke(uuid string, args [][]byte) pb.Response {
 	stub.args = args
 	stub.MockTransactionStart(uuid)
-	bytes, err := stub.cc.Invoke(stub)
+	res := stub.cc.Invoke(stub)
 	stub.MockTransactionEnd(uuid)
-	return bytes, err
+	return res
 }
 
 // GetState retrieves the value for a given key from the ledger
@@ -255,9 +288,9 @@ func (stub *MockStub) InvokeChaincode(chaincodeName string, args [][]byte) ([]by
 	otherStub := stub.Invokables[chaincodeName]
 	mockLogger.Debug("MockStub", stub.Name, "Invoking peer chaincode", otherStub.Name, args)
 	//	function, strings := getFuncArgs(args)
-	bytes, err := otherStub.MockInvoke(stub.TxID, args)
-	mockLogger.Debug("MockStub", stub.Name, "Invoked peer chaincode", otherStub.Name, "got", bytes, err)
-	return bytes, err
+	res := otherStub.MockInvoke(stub.TxID, args)
+	mockLogger.Debug("MockStub", stub.Name, "Invoked peer chaincode", otherStub.Name, "got", res)
+	return fromResponse(res)
 }
 
 // Not implemented
@@ -270,9 +303,24 @@ func (stub *MockStub) GetCallerMetadata() ([]byte, error) {
 	return nil, nil
 }
 
-// Not implemented
+// GetCreator returns the identity set with SetCreator
+func (stub *MockStub) GetCreator() ([]byte, error) {
+	return stub.creator, nil
+}
+
+// GetTransient returns the data set with SetTransient
+func (stub *MockStub) GetTransient() ([]byte, error) {
+	return stub.transient, nil
+}
+
+// GetBinding returns the binding set with SetBinding
 func (stub *MockStub) GetBinding() ([]byte, error) {
-	return nil, nil
+	return stub.binding, nil
+}
+
+// GetSignedProposal returns the proposal set with SetSignedProposal
+func (stub *MockStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return stub.signedProposal, nil
 }
 
 // Not implemented
@@ -280,9 +328,9 @@ func (stub *MockStub) GetPayload() ([]byte, error) {
 	return nil, nil
 }
 
-// Not implemented
+// GetTxTimestamp returns the timestamp set with SetTxTimestamp
 func (stub *MockStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
-	return nil, nil
+	return stub.txTimestamp, nil
 }
 
 // Not implemented
@@ -324,25 +372,41 @@ func (iter *MockStateRangeQueryIterator) HasNext() bool {
 		return false
 	}
 
-	if iter.Current == nil {
-		mockLogger.Error("HasNext() couldn't get Current")
-		return false
-	}
-
-	if iter.Current.Next() == nil {
+	if iter.next() == nil {
 		// we've reached the end of the underlying values
 		mockLogger.Debug("HasNext() but no next")
 		return false
 	}
 
-	if iter.EndKey == iter.Current.Value {
+	mockLogger.Debug("HasNext() got next")
+	return true
+}
+
+// next returns the element following Current within the range, or nil at the
+// end of the range. Before the first call to Next, Current is nil and the
+// first element is the first key not less than StartKey
+func (iter *MockStateRangeQueryIterator) next() *list.Element {
+	var elem *list.Element
+	if iter.Current == nil {
+		for elem = iter.Stub.Keys.Front(); elem != nil; elem = elem.Next() {
+			if strings.Compare(elem.Value.(string), iter.StartKey) >= 0 {
+				break
+			}
+		}
+	} else {
+		elem = iter.Current.Next()
+	}
+
+	if elem == nil {
+		return nil
+	}
+
+	if iter.EndKey != "" && strings.Compare(elem.Value.(string), iter.EndKey) > 0 {
 		// we've reached the end of the specified range
-		mockLogger.Debug("HasNext() at end of specified range")
-		return false
+		return nil
 	}
 
-	mockLogger.Debug("HasNext() got next")
-	return true
+	return elem
 }
 
 // Next returns the next key and value in the range query iterator.
@@ -357,12 +421,7 @@ func (iter *MockStateRangeQueryIterator) Next() (string, []byte, error) {
 		return "", nil, errors.New("MockStateRangeQueryIterator.Next() called when it does not HaveNext()")
 	}
 
-	iter.Current = iter.Current.Next()
-
-	if iter.Current == nil {
-		mockLogger.Error("MockStateRangeQueryIterator.Next() went past end of range")
-		return "", nil, errors.New("MockStateRangeQueryIterator.Next() went past end of range")
-	}
+	iter.Current = iter.next()
 	key := iter.Current.Value.(string)
 	value, err := iter.Stub.GetState(key)
 	return key, value, err
@@ -398,7 +457,7 @@ func NewMockStateRangeQueryIterator(stub *MockStub, startKey string, endKey stri
 	iter.Stub = stub
 	iter.StartKey = startKey
 	iter.EndKey = endKey
-	iter.Current = stub.Keys.Front()
+	iter.Current = nil
 
 	iter.Print()
 
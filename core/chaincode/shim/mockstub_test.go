@@ -17,10 +17,13 @@ limitations under the License.
 package shim
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/util"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 )
 
@@ -131,6 +134,37 @@ func TestMockStateRangeQueryIterator(t *testing.T) {
 	}
 }
 
+func TestMockStateRangeQueryIteratorAllKeys(t *testing.T) {
+	stub := NewMockStub("rangeTest", nil)
+	stub.MockTransactionStart("init")
+	stub.PutState("b", []byte{62})
+	stub.PutState("a", []byte{61})
+	stub.PutState("c", []byte{63})
+	stub.MockTransactionEnd("init")
+
+	expectKeys := []string{"a", "b", "c"}
+
+	rqi, _ := stub.RangeQueryState("", "")
+	var keys []string
+	for rqi.HasNext() {
+		key, _, err := rqi.Next()
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		keys = append(keys, key)
+	}
+	rqi.Close()
+
+	if len(keys) != len(expectKeys) {
+		t.Fatalf("Expected keys %v, got %v", expectKeys, keys)
+	}
+	for i := range keys {
+		if keys[i] != expectKeys[i] {
+			t.Fatalf("Expected keys %v, got %v", expectKeys, keys)
+		}
+	}
+}
+
 func TestMockTable(t *testing.T) {
 	stub := NewMockStub("CreateTable", nil)
 	stub.MockTransactionStart("init")
@@ -190,3 +224,34 @@ func TestSetChaincodeLoggingLevel(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestMockProposalData(t *testing.T) {
+	stub := NewMockStub("proposalDataStub", nil)
+	if creator, _ := stub.GetCreator(); creator != nil {
+		t.Fatalf("Expected no creator by default, got %s", creator)
+	}
+
+	ts := util.CreateUtcTimestamp()
+	signedProp := &pb.SignedProposal{ProposalBytes: []byte("proposal")}
+	stub.SetCreator([]byte("creator"))
+	stub.SetTransient([]byte("transient"))
+	stub.SetBinding([]byte("binding"))
+	stub.SetSignedProposal(signedProp)
+	stub.SetTxTimestamp(ts)
+
+	if creator, _ := stub.GetCreator(); !bytes.Equal(creator, []byte("creator")) {
+		t.Fatalf("Expected creator, got %s", creator)
+	}
+	if transient, _ := stub.GetTransient(); !bytes.Equal(transient, []byte("transient")) {
+		t.Fatalf("Expected transient, got %s", transient)
+	}
+	if binding, _ := stub.GetBinding(); !bytes.Equal(binding, []byte("binding")) {
+		t.Fatalf("Expected binding, got %s", binding)
+	}
+	if sp, _ := stub.GetSignedProposal(); sp != signedProp {
+		t.Fatalf("Expected the signed proposal that was set")
+	}
+	if txts, _ := stub.GetTxTimestamp(); txts != ts {
+		t.Fatalf("Expected the timestamp that was set")
+	}
+}
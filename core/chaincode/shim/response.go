@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shim
+
+import (
+	"errors"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+const (
+	// OK constant - status code less than 400, endorser will endorse it.
+	// OK means init or invoke successfully.
+	OK = 200
+
+	// ERRORTHRESHOLD constant - status code greater than or equal to 400 will be considered an error and rejected by endorser.
+	ERRORTHRESHOLD = 400
+
+	// ERROR constant - default error value
+	ERROR = 500
+)
+
+// Success returns a response with status OK and the given payload
+func Success(payload []byte) pb.Response {
+	return pb.Response{
+		Status:  OK,
+		Payload: payload,
+	}
+}
+
+// Error returns a response with status ERROR and the given message
+func Error(msg string) pb.Response {
+	return pb.Response{
+		Status:  ERROR,
+		Message: msg,
+	}
+}
+
+// LegacyChaincode is the contract chaincodes implemented before Init and
+// Invoke returned a pb.Response. It can still be run through AdaptLegacy
+type LegacyChaincode interface {
+	Init(stub ChaincodeStubInterface) ([]byte, error)
+
+	Invoke(stub ChaincodeStubInterface) ([]byte, error)
+}
+
+// AdaptLegacy turns a LegacyChaincode into a Chaincode. A returned error
+// becomes an ERROR response with the error as message, and a returned payload
+// a Success response
+func AdaptLegacy(cc LegacyChaincode) Chaincode {
+	return &legacyAdapter{cc}
+}
+
+type legacyAdapter struct {
+	cc LegacyChaincode
+}
+
+func (a *legacyAdapter) Init(stub ChaincodeStubInterface) pb.Response {
+	return toResponse(a.cc.Init(stub))
+}
+
+func (a *legacyAdapter) Invoke(stub ChaincodeStubInterface) pb.Response {
+	return toResponse(a.cc.Invoke(stub))
+}
+
+func toResponse(payload []byte, err error) pb.Response {
+	if err != nil {
+		return Error(err.Error())
+	}
+	return Success(payload)
+}
+
+// fromResponse returns the payload of res, or its message as an error if its
+// status is at or above ERRORTHRESHOLD
+func fromResponse(res pb.Response) ([]byte, error) {
+	if res.Status >= ERRORTHRESHOLD {
+		return nil, errors.New(res.Message)
+	}
+	return res.Payload, nil
+}
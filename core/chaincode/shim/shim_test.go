@@ -17,9 +17,15 @@ limitations under the License.
 package shim
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/op/go-logging"
 )
 
@@ -142,3 +148,120 @@ func TestNilEventName(t *testing.T) {
 	}
 
 }
+
+func createSignedProposal(t *testing.T, hdr *common.Header, transient []byte) *pb.SignedProposal {
+	hdrBytes, err := proto.Marshal(hdr)
+	if err != nil {
+		t.Fatalf("Error marshalling header: %s", err)
+	}
+	payloadBytes, err := proto.Marshal(&pb.ChaincodeProposalPayload{Input: []byte("input"), Transient: transient})
+	if err != nil {
+		t.Fatalf("Error marshalling payload: %s", err)
+	}
+	propBytes, err := proto.Marshal(&pb.Proposal{Header: hdrBytes, Payload: payloadBytes})
+	if err != nil {
+		t.Fatalf("Error marshalling proposal: %s", err)
+	}
+	return &pb.SignedProposal{ProposalBytes: propBytes, Signature: []byte("signature")}
+}
+
+func TestStubProposalData(t *testing.T) {
+	ts := util.CreateUtcTimestamp()
+	hdr := &common.Header{
+		ChainHeader:     &common.ChainHeader{TxID: "txid", Timestamp: ts, Epoch: 1},
+		SignatureHeader: &common.SignatureHeader{Creator: []byte("creator"), Nonce: []byte("nonce")},
+	}
+	signedProp := createSignedProposal(t, hdr, []byte("transient"))
+
+	stub := ChaincodeStub{}
+	if err := stub.init(&Handler{}, "txid", &pb.ChaincodeInput{}, signedProp); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+
+	if creator, _ := stub.GetCreator(); !bytes.Equal(creator, []byte("creator")) {
+		t.Fatalf("Expected creator to be creator, got %s", creator)
+	}
+	if transient, _ := stub.GetTransient(); !bytes.Equal(transient, []byte("transient")) {
+		t.Fatalf("Expected transient to be transient, got %s", transient)
+	}
+	if sp, _ := stub.GetSignedProposal(); sp != signedProp {
+		t.Fatalf("Expected the signed proposal the stub was initialized with")
+	}
+	if txts, _ := stub.GetTxTimestamp(); !proto.Equal(txts, ts) {
+		t.Fatalf("Expected timestamp %v, got %v", ts, txts)
+	}
+
+	binding, _ := stub.GetBinding()
+	if len(binding) != 32 {
+		t.Fatalf("Expected a SHA256 binding, got %x", binding)
+	}
+	//the binding depends on the epoch
+	hdr.ChainHeader.Epoch = 2
+	other := ChaincodeStub{}
+	if err := other.init(&Handler{}, "txid", &pb.ChaincodeInput{}, createSignedProposal(t, hdr, nil)); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+	if otherBinding, _ := other.GetBinding(); bytes.Equal(binding, otherBinding) {
+		t.Fatalf("Expected different bindings for different epochs")
+	}
+}
+
+func TestStubWithoutProposal(t *testing.T) {
+	stub := InitTestStub("f", "a")
+	if creator, err := stub.GetCreator(); creator != nil || err != nil {
+		t.Fatalf("Expected no creator without a proposal, got %s, %v", creator, err)
+	}
+	if sp, err := stub.GetSignedProposal(); sp != nil || err != nil {
+		t.Fatalf("Expected no signed proposal, got %v, %v", sp, err)
+	}
+}
+
+func TestStubInvalidProposal(t *testing.T) {
+	stub := ChaincodeStub{}
+	err := stub.init(&Handler{}, "txid", &pb.ChaincodeInput{}, &pb.SignedProposal{ProposalBytes: []byte("garbage")})
+	if err == nil {
+		t.Fatalf("Expected an error for a malformed proposal")
+	}
+
+	//the signature header is needed for the creator
+	signedProp := createSignedProposal(t, &common.Header{ChainHeader: &common.ChainHeader{}}, nil)
+	if err = stub.init(&Handler{}, "txid", &pb.ChaincodeInput{}, signedProp); err == nil {
+		t.Fatalf("Expected an error for a proposal without signature header")
+	}
+}
+
+type legacyCC struct {
+	err error
+}
+
+func (cc *legacyCC) Init(stub ChaincodeStubInterface) ([]byte, error) {
+	return nil, cc.err
+}
+
+func (cc *legacyCC) Invoke(stub ChaincodeStubInterface) ([]byte, error) {
+	if cc.err != nil {
+		return nil, cc.err
+	}
+	return []byte("payload"), nil
+}
+
+func TestAdaptLegacy(t *testing.T) {
+	stub := NewMockStub("legacy", AdaptLegacy(&legacyCC{}))
+	res := stub.MockInvoke("1", nil)
+	if res.Status != OK || string(res.Payload) != "payload" {
+		t.Fatalf("Expected an OK response with the payload, got %v", res)
+	}
+	payload, err := fromResponse(res)
+	if err != nil || string(payload) != "payload" {
+		t.Fatalf("Expected the payload back, got %s, %v", payload, err)
+	}
+
+	stub = NewMockStub("legacy", AdaptLegacy(&legacyCC{err: errors.New("failed")}))
+	res = stub.MockInit("1", nil)
+	if res.Status != ERROR || res.Message != "failed" {
+		t.Fatalf("Expected an ERROR response with the error message, got %v", res)
+	}
+	if _, err = fromResponse(res); err == nil || err.Error() != "failed" {
+		t.Fatalf("Expected the message back as an error, got %v", err)
+	}
+}
@@ -123,7 +123,7 @@ func deploySysCC(chainID string, syscc *SystemChaincode) error {
 	txid := util.GenerateUUID()
 
 	version := util.GetSysCCVersion()
-	cccid := NewCCContext(chainID, chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Name, version, txid, true, nil)
+	cccid := NewCCContext(chainID, chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Name, version, txid, true, nil, nil)
 
 	_, _, err = Execute(ctxt, cccid, chaincodeDeploymentSpec)
 
@@ -149,7 +149,7 @@ func deDeploySysCC(chainID string, syscc *SystemChaincode) error {
 	chaincodeSupport := GetChain()
 	if chaincodeSupport != nil {
 		version := util.GetSysCCVersion()
-		cccid := NewCCContext(chainID, syscc.Name, version, "", true, nil)
+		cccid := NewCCContext(chainID, syscc.Name, version, "", true, nil, nil)
 		err = chaincodeSupport.Stop(ctx, cccid, chaincodeDeploymentSpec)
 	}
 
@@ -105,7 +105,7 @@ func deploySampleSysCC(t *testing.T, ctxt context.Context, chainID string) error
 
 	_, _, _, err := invokeWithVersion(ctxt, chainID, sysCCVers, spec)
 
-	cccid := NewCCContext(chainID, "sample_syscc", sysCCVers, "", true, nil)
+	cccid := NewCCContext(chainID, "sample_syscc", sysCCVers, "", true, nil, nil)
 	if err != nil {
 		theChaincodeSupport.Stop(ctxt, cccid, cdsforStop)
 		t.Logf("Error invoking sample_syscc: %s", err)
@@ -18,10 +18,12 @@ package chaincode
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
 
 	"github.com/golang/protobuf/proto"
@@ -53,6 +55,16 @@ func upgrade(ctx context.Context, cccid *CCContext, spec *pb.ChaincodeSpec) (*CC
 }
 
 func upgrade2(ctx context.Context, cccid *CCContext, chaincodeDeploymentSpec *pb.ChaincodeDeploymentSpec) (*CCContext, error) {
+	//install the next version of the chaincode before upgrading to it
+	v, err := strconv.Atoi(cccid.Version)
+	if err != nil {
+		return nil, fmt.Errorf("Expected a numeric version for the test but got %s", cccid.Version)
+	}
+	chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Version = strconv.Itoa(v + 1)
+	if err = installChaincode(chaincodeDeploymentSpec); err != nil {
+		return nil, fmt.Errorf("Error installing chaincode : %s\n", err)
+	}
+
 	cis, err := getUpgradeLCCCSpec(cccid.ChainID, chaincodeDeploymentSpec)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating lccc spec : %s\n", err)
@@ -79,14 +91,18 @@ func upgrade2(ctx context.Context, cccid *CCContext, chaincodeDeploymentSpec *pb
 	}()
 
 	sysCCVers := util.GetSysCCVersion()
-	lcccid := NewCCContext(cccid.ChainID, cis.ChaincodeSpec.ChaincodeID.Name, sysCCVers, uuid, true, nil)
+	lcccid := NewCCContext(cccid.ChainID, cis.ChaincodeSpec.ChaincodeID.Name, sysCCVers, uuid, true, nil, nil)
 
-	var versionBytes []byte
 	//write to lccc
-	if versionBytes, _, err = Execute(ctx, lcccid, cis); err != nil {
+	var res *pb.Response
+	if res, _, err = Execute(ctx, lcccid, cis); err != nil {
 		return nil, fmt.Errorf("Error executing LCCC for upgrade: %s", err)
+	} else if res.Status != shim.OK {
+		err = fmt.Errorf("Error executing LCCC for upgrade: %s", res.Message)
+		return nil, err
 	}
 
+	versionBytes := res.Payload
 	if versionBytes == nil {
 		return nil, fmt.Errorf("Expected version back from LCCC but got nil")
 	}
@@ -96,7 +112,7 @@ func upgrade2(ctx context.Context, cccid *CCContext, chaincodeDeploymentSpec *pb
 		return nil, fmt.Errorf("Expected new version from LCCC but got same %s(%s)", newVersion, cccid.Version)
 	}
 
-	newcccid := NewCCContext(cccid.ChainID, chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Name, newVersion, uuid, false, nil)
+	newcccid := NewCCContext(cccid.ChainID, chaincodeDeploymentSpec.ChaincodeSpec.ChaincodeID.Name, newVersion, uuid, false, nil, nil)
 
 	if _, _, err = Execute(ctx, newcccid, chaincodeDeploymentSpec); err != nil {
 		return nil, fmt.Errorf("Error deploying chaincode for upgrade: %s", err)
@@ -136,7 +152,7 @@ func TestUpgradeCC(t *testing.T) {
 
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: chaincodeID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid := NewCCContext(chainID, ccName, "0", "", false, nil)
+	cccid := NewCCContext(chainID, ccName, "0", "", false, nil, nil)
 	_, err = deploy(ctxt, cccid, spec)
 
 	if err != nil {
@@ -225,7 +241,7 @@ func TestInvalUpgradeCC(t *testing.T) {
 
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: chaincodeID, CtorMsg: &pb.ChaincodeInput{Args: args}}
 
-	cccid := NewCCContext(chainID, ccName, "0", "", false, nil)
+	cccid := NewCCContext(chainID, ccName, "0", "", false, nil, nil)
 
 	//Note ccName hasn't changed...
 	chaincodeID = &pb.ChaincodeID{Name: ccName, Path: url}
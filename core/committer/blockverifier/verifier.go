@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package blockverifier
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Verifier checks that a block was produced by the ordering service
+// of a chain before it is gossiped or committed
+type Verifier interface {
+	// Verify returns nil if the data of the block matches its header and
+	// the signatures of the block satisfy the block validation policy
+	Verify(block *common.Block) error
+}
+
+// implementation of Verifier interface, keeps the
+// block validation policy of the chain
+type blockVerifier struct {
+	chainID string
+	policy  policies.Policy
+}
+
+// New creates a verifier for the chain whose configuration is found in
+// configBlock; signatures are checked using the MSP manager of the chain.
+// The configuration must hold a block validation policy, otherwise anyone
+// could forge the blocks of the chain
+func New(configBlock *common.Block, mspManager msp.MSPManager) (Verifier, error) {
+	chainID, err := utils.GetChainIDFromBlock(configBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	configItem, err := getBlockValidationPolicy(configBlock)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the block validation policy of chain %s: %s", chainID, err)
+	}
+	if configItem == nil {
+		return nil, fmt.Errorf("Chain %s has no block validation policy", chainID)
+	}
+
+	policy := &common.Policy{}
+	if err = proto.Unmarshal(configItem.Value, policy); err != nil {
+		return nil, fmt.Errorf("Cannot unmarshal the block validation policy of chain %s: %s", chainID, err)
+	}
+	if policy.Type != int32(common.Policy_SIGNATURE) {
+		return nil, fmt.Errorf("Unsupported type of block validation policy for chain %s: %d", chainID, policy.Type)
+	}
+	compiled, err := cauthdsl.NewPolicyProvider(cauthdsl.NewMSPCryptoHelper(mspManager)).NewPolicy(policy.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid block validation policy for chain %s: %s", chainID, err)
+	}
+
+	return &blockVerifier{chainID: chainID, policy: compiled}, nil
+}
+
+func (v *blockVerifier) Verify(block *common.Block) error {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return fmt.Errorf("Block of chain %s is incomplete", v.chainID)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("Data hash of block %d of chain %s does not match its header", block.Header.Number, v.chainID)
+	}
+
+	// The genesis block is not signed by the ordering service, it is
+	// trusted because it is the block the peer joined the chain with
+	if block.Header.Number == 0 {
+		return nil
+	}
+
+	signedData, err := getSignedData(block)
+	if err != nil {
+		return fmt.Errorf("Cannot read the signatures of block %d of chain %s: %s", block.Header.Number, v.chainID, err)
+	}
+	if err = v.policy.Evaluate(signedData); err != nil {
+		return fmt.Errorf("Signatures of block %d of chain %s do not satisfy the block validation policy: %s", block.Header.Number, v.chainID, err)
+	}
+
+	return nil
+}
+
+// VerifyPreviousHash returns nil if block links to the block whose
+// header hash is previousHash
+func VerifyPreviousHash(block *common.Block, previousHash []byte) error {
+	if block == nil || block.Header == nil {
+		return fmt.Errorf("Block has no header")
+	}
+	if !bytes.Equal(block.Header.PreviousHash, previousHash) {
+		return fmt.Errorf("Previous hash of block %d does not match the hash of the last committed block", block.Header.Number)
+	}
+	return nil
+}
+
+// getSignedData extracts the signatures of the ordering service from the
+// metadata of the block, signatures are over the metadata value, the
+// signature header and the block header
+func getSignedData(block *common.Block) ([]*common.SignedData, error) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_SIGNATURES) {
+		return nil, fmt.Errorf("Block has no signature metadata")
+	}
+	md, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData := make([]*common.SignedData, len(md.Signatures))
+	for i, sig := range md.Signatures {
+		shdr := &common.SignatureHeader{}
+		if err = proto.Unmarshal(sig.SignatureHeader, shdr); err != nil {
+			return nil, fmt.Errorf("Cannot unmarshal signature header: %s", err)
+		}
+		signedData[i] = &common.SignedData{
+			Data:      util.ConcatenateBytes(md.Value, sig.SignatureHeader, block.Header.Bytes()),
+			Identity:  shdr.Creator,
+			Signature: sig.Signature,
+		}
+	}
+	return signedData, nil
+}
+
+// getBlockValidationPolicy returns the configuration item holding the block
+// validation policy in configBlock, or nil if there is none
+func getBlockValidationPolicy(configBlock *common.Block) (*common.ConfigurationItem, error) {
+	env, err := utils.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := utils.ExtractPayload(env)
+	if err != nil {
+		return nil, err
+	}
+	configEnvelope, err := utils.UnmarshalConfigurationEnvelope(payload.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range configEnvelope.Items {
+		configItem, err := utils.UnmarshalConfigurationItem(item.ConfigurationItem)
+		if err != nil {
+			return nil, err
+		}
+		if configItem.Type == common.ConfigurationItem_Policy && configItem.Key == configtx.BlockValidationPolicyID {
+			return configItem, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package blockverifier
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVerifier(t *testing.T) Verifier {
+	configBlock, err := utils.MakeConfigurationBlock("testchainid")
+	assert.NoError(t, err)
+	mgr, err := mspmgmt.GetMSPManagerFromBlock(configBlock)
+	assert.NoError(t, err)
+	verifier, err := New(configBlock, mgr)
+	assert.NoError(t, err)
+	return verifier
+}
+
+// newSignedBlock creates a block signed the way the ordering service does
+func newSignedBlock(number uint64, previousHash []byte) *common.Block {
+	block := common.NewBlock(number, previousHash)
+	block.Data.Data = [][]byte{[]byte("tx1"), []byte("tx2")}
+	block.Header.DataHash = block.Data.Hash()
+
+	signer := localmsp.NewSigner()
+	sig := &common.MetadataSignature{SignatureHeader: utils.MarshalOrPanic(signer.NewSignatureHeader())}
+	sig.Signature = signer.Sign(util.ConcatenateBytes(nil, sig.SignatureHeader, block.Header.Bytes()))
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&common.Metadata{
+		Signatures: []*common.MetadataSignature{sig},
+	})
+	return block
+}
+
+func TestVerifySignedBlock(t *testing.T) {
+	verifier := newVerifier(t)
+
+	assert.NoError(t, verifier.Verify(newSignedBlock(1, []byte("previous"))))
+}
+
+func TestVerifyGenesisBlock(t *testing.T) {
+	verifier := newVerifier(t)
+
+	block := common.NewBlock(0, nil)
+	block.Header.DataHash = block.Data.Hash()
+	assert.NoError(t, verifier.Verify(block), "The genesis block is not signed")
+}
+
+func TestVerifyTamperedBlock(t *testing.T) {
+	verifier := newVerifier(t)
+
+	block := newSignedBlock(1, []byte("previous"))
+	block.Data.Data[0] = []byte("forged tx")
+	assert.Error(t, verifier.Verify(block), "Data that do not match the data hash should be rejected")
+
+	block = newSignedBlock(1, []byte("previous"))
+	block.Data.Data[0] = []byte("forged tx")
+	block.Header.DataHash = block.Data.Hash()
+	assert.Error(t, verifier.Verify(block), "A header that does not match the signature should be rejected")
+
+	block = newSignedBlock(1, []byte("previous"))
+	block.Header.PreviousHash = []byte("forged")
+	assert.Error(t, verifier.Verify(block), "A header that does not match the signature should be rejected")
+
+	block = newSignedBlock(1, []byte("previous"))
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&common.Metadata{})
+	assert.Error(t, verifier.Verify(block), "A block without signatures should be rejected")
+
+	block = newSignedBlock(1, []byte("previous"))
+	md, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	assert.NoError(t, err)
+	md.Signatures[0].Signature[len(md.Signatures[0].Signature)-1] ^= 1
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(md)
+	assert.Error(t, verifier.Verify(block), "A corrupted signature should be rejected")
+}
+
+func TestNewWithoutBlockValidationPolicy(t *testing.T) {
+	configBlock, err := utils.MakeConfigurationBlock("testchainid")
+	assert.NoError(t, err)
+	mgr, err := mspmgmt.GetMSPManagerFromBlock(configBlock)
+	assert.NoError(t, err)
+
+	// Remove the block validation policy from the configuration
+	payload := utils.ExtractPayloadOrPanic(utils.ExtractEnvelopeOrPanic(configBlock, 0))
+	configEnvelope, err := utils.UnmarshalConfigurationEnvelope(payload.Data)
+	assert.NoError(t, err)
+	var items []*common.SignedConfigurationItem
+	for _, item := range configEnvelope.Items {
+		configItem, err := utils.UnmarshalConfigurationItem(item.ConfigurationItem)
+		assert.NoError(t, err)
+		if configItem.Key != configtx.BlockValidationPolicyID {
+			items = append(items, item)
+		}
+	}
+	assert.Len(t, items, len(configEnvelope.Items)-1)
+	configEnvelope.Items = items
+	payload.Data = utils.MarshalOrPanic(configEnvelope)
+	configBlock.Data.Data[0] = utils.MarshalOrPanic(&common.Envelope{Payload: utils.MarshalOrPanic(payload)})
+
+	_, err = New(configBlock, mgr)
+	assert.Error(t, err, "A chain without block validation policy should not be trusted")
+}
+
+func TestVerifyPreviousHash(t *testing.T) {
+	previous := newSignedBlock(1, []byte("previous"))
+
+	assert.NoError(t, VerifyPreviousHash(newSignedBlock(2, previous.Header.Hash()), previous.Header.Hash()))
+	assert.Error(t, VerifyPreviousHash(newSignedBlock(2, []byte("forged")), previous.Header.Hash()))
+}
+
+func TestMain(m *testing.M) {
+	if err := mspmgmt.LoadLocalMsp("../../../msp/sampleconfig", nil); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
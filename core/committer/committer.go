@@ -30,6 +30,9 @@ type Committer interface {
 	// Commit block to the ledger
 	CommitBlock(block *common.Block) error
 
+	// Verify that block comes from the ordering service of the chain
+	VerifyBlock(block *common.Block) error
+
 	// Get recent block sequence number
 	LedgerHeight() (uint64, error)
 
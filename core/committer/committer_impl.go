@@ -17,9 +17,14 @@ limitations under the License.
 package committer
 
 import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/committer/blockverifier"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 )
 
@@ -38,22 +43,92 @@ func init() {
 // it keeps the reference to the ledger to commit blocks and retreive
 // chain information
 type LedgerCommitter struct {
-	ledger ledger.ValidatedLedger
+	ledger      ledger.ValidatedLedger
+	newVerifier VerifierFactory
+
+	sync.RWMutex
+	verifier blockverifier.Verifier
 }
 
+// VerifierFactory creates the verifier of a chain from its configuration block
+type VerifierFactory func(configBlock *common.Block) (blockverifier.Verifier, error)
+
 // NewLedgerCommitter is a factory function to create an instance of the committer
 func NewLedgerCommitter(ledger ledger.ValidatedLedger) *LedgerCommitter {
-	return &LedgerCommitter{ledger}
+	return &LedgerCommitter{ledger: ledger}
+}
+
+// NewVerifyingLedgerCommitter creates an instance of the committer which
+// only commits blocks accepted by the verifier newVerifier creates from
+// configBlock and linked to the last committed block by their previous hash.
+// The verifier is created again from every configuration block committed
+func NewVerifyingLedgerCommitter(ledger ledger.ValidatedLedger, configBlock *common.Block, newVerifier VerifierFactory) (*LedgerCommitter, error) {
+	verifier, err := newVerifier(configBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &LedgerCommitter{ledger: ledger, newVerifier: newVerifier, verifier: verifier}, nil
 }
 
 // CommitBlock commits block to into the ledger
 func (lc *LedgerCommitter) CommitBlock(block *common.Block) error {
+	if lc.newVerifier != nil {
+		if err := lc.VerifyBlock(block); err != nil {
+			logger.Errorf("Rejecting block: %s", err)
+			return err
+		}
+		if err := lc.verifyPreviousHash(block); err != nil {
+			logger.Errorf("Rejecting block: %s", err)
+			return err
+		}
+	}
 	if err := lc.ledger.Commit(block); err != nil {
 		return err
 	}
+	if lc.newVerifier != nil && utils.IsConfigBlock(block) {
+		return lc.updateVerifier(block)
+	}
+	return nil
+}
+
+// VerifyBlock checks the block against the verifier of the committer,
+// any block is accepted if the committer has no verifier
+func (lc *LedgerCommitter) VerifyBlock(block *common.Block) error {
+	lc.RLock()
+	verifier := lc.verifier
+	lc.RUnlock()
+	if verifier == nil {
+		return nil
+	}
+	return verifier.Verify(block)
+}
+
+// updateVerifier replaces the verifier of the committer by one created
+// from the committed configuration block, so that the following blocks are
+// checked against the current block validation policy of the chain
+func (lc *LedgerCommitter) updateVerifier(configBlock *common.Block) error {
+	verifier, err := lc.newVerifier(configBlock)
+	if err != nil {
+		logger.Errorf("Cannot create the verifier from configuration block %d: %s", configBlock.Header.Number, err)
+		return fmt.Errorf("Committed configuration block %d but cannot create the verifier from it: %s", configBlock.Header.Number, err)
+	}
+	lc.Lock()
+	lc.verifier = verifier
+	lc.Unlock()
 	return nil
 }
 
+func (lc *LedgerCommitter) verifyPreviousHash(block *common.Block) error {
+	info, err := lc.ledger.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	if info.Height == 0 {
+		return nil
+	}
+	return blockverifier.VerifyPreviousHash(block, info.CurrentBlockHash)
+}
+
 // LedgerHeight returns recently committed block sequence number
 func (lc *LedgerCommitter) LedgerHeight() (uint64, error) {
 	var info *pb.BlockchainInfo
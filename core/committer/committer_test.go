@@ -17,14 +17,19 @@ limitations under the License.
 package committer
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/committer/blockverifier"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 func TestKVLedgerBlockStorage(t *testing.T) {
@@ -69,3 +74,92 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	testutil.AssertEquals(t, bcInfo, &pb.BlockchainInfo{
 		Height: 1, CurrentBlockHash: block1Hash, PreviousBlockHash: []byte{}})
 }
+
+type mockVerifier struct {
+	reject bool
+}
+
+func (v *mockVerifier) Verify(block *common.Block) error {
+	if v.reject {
+		return fmt.Errorf("Rejected block %d", block.Header.Number)
+	}
+	return nil
+}
+
+func TestVerifyingCommitter(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/committertest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	ledger, err := ledgermgmt.CreateLedger("TestLedger")
+	assert.NoError(t, err, "Error while creating ledger: %s", err)
+	defer ledger.Close()
+
+	verifier := &mockVerifier{}
+	committer, err := NewVerifyingLedgerCommitter(ledger, nil, func(*common.Block) (blockverifier.Verifier, error) {
+		return verifier, nil
+	})
+	assert.NoError(t, err)
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+
+	bg := testutil.NewBlockGenerator(t)
+	blocks := []*common.Block{bg.NextBlock([][]byte{simRes}, true), bg.NextBlock([][]byte{simRes}, true)}
+
+	assert.NoError(t, committer.CommitBlock(blocks[0]))
+
+	forged := testutil.ConstructBlock(t, [][]byte{simRes}, true)
+	forged.Header.Number = 2
+	assert.Error(t, committer.CommitBlock(forged), "A block not linked to the last committed block should be rejected")
+
+	verifier.reject = true
+	assert.Error(t, committer.VerifyBlock(blocks[1]))
+	assert.Error(t, committer.CommitBlock(blocks[1]), "A block rejected by the verifier should not be committed")
+
+	height, err := committer.LedgerHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	verifier.reject = false
+	assert.NoError(t, committer.CommitBlock(blocks[1]))
+	height, err = committer.LedgerHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+}
+
+func TestVerifyingCommitterConfigBlock(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/committertest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	ledger, err := ledgermgmt.CreateLedger("TestLedger")
+	assert.NoError(t, err, "Error while creating ledger: %s", err)
+	defer ledger.Close()
+
+	configBlock, err := utils.MakeConfigurationBlock("TestLedger")
+	assert.NoError(t, err)
+	// The validator marks the configuration transaction as invalid
+	// for the ledger, which only processes endorser transactions
+	txsFilter := ledgerUtil.NewFilterBitArray(1)
+	txsFilter.Set(0)
+	utils.InitBlockMetadata(configBlock)
+	configBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+
+	var configBlocks []*common.Block
+	committer, err := NewVerifyingLedgerCommitter(ledger, configBlock, func(cb *common.Block) (blockverifier.Verifier, error) {
+		configBlocks = append(configBlocks, cb)
+		return &mockVerifier{reject: len(configBlocks) > 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, committer.VerifyBlock(configBlock))
+
+	assert.NoError(t, committer.CommitBlock(configBlock))
+	assert.Len(t, configBlocks, 2, "The verifier should be created again from the committed configuration block")
+	assert.True(t, configBlocks[1] == configBlock)
+	assert.Error(t, committer.VerifyBlock(configBlock), "Blocks should be checked by the new verifier")
+
+	_, err = NewVerifyingLedgerCommitter(ledger, configBlock, func(*common.Block) (blockverifier.Verifier, error) {
+		return nil, fmt.Errorf("No block validation policy")
+	})
+	assert.Error(t, err)
+}
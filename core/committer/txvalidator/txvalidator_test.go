@@ -17,6 +17,7 @@ limitations under the License.
 package txvalidator
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
@@ -26,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	ptestutils "github.com/hyperledger/fabric/protos/testutils"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -130,3 +132,101 @@ func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 
 	assert.True(t, txsfltr.IsSet(0))
 }
+
+func TestNewTxValidator_DuplicateTransactionsInBlock(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	ledger, _ := ledgermgmt.CreateLedger("TestLedger")
+	defer ledger.Close()
+
+	validator := &txValidator{ledger, &mockVsccValidator{}}
+
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+
+	env, _, err := testutil.ConstructTransaction(t, simRes, true)
+	assert.NoError(t, err)
+	envBytes, err := proto.Marshal(env)
+	assert.NoError(t, err)
+
+	// Replay the transaction within the same block
+	block := common.NewBlock(1, []byte{})
+	block.Data.Data = [][]byte{envBytes, envBytes}
+	block.Header.DataHash = block.Data.Hash()
+
+	validator.Validate(block)
+
+	txsfltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+
+	assert.False(t, txsfltr.IsSet(0))
+	assert.True(t, txsfltr.IsSet(1))
+}
+
+// constructSignedTx returns a transaction with the simulation results, signed
+// by the default test signer
+func constructSignedTx(t *testing.T, simRes []byte) []byte {
+	env, _, err := ptestutils.ConstructSingedTxEnvWithDefaultSigner(util2.GetTestChainID(), "foo", simRes, nil, nil)
+	assert.NoError(t, err)
+	envBytes, err := proto.Marshal(env)
+	assert.NoError(t, err)
+	return envBytes
+}
+
+func TestNewTxValidator_DuplicateTransactionsInSnapshot(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	ledger, _ := ledgermgmt.CreateLedger("TestLedger")
+
+	// commit the transaction, followed by the block the snapshot is taken
+	// at, so that the transaction is only known by its ID in the snapshot
+	var envBytes []byte
+	var block *common.Block
+	previousHash := []byte{}
+	for i, value := range []string{"value1", "value2"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		txBytes := constructSignedTx(t, simRes)
+		if i == 0 {
+			envBytes = txBytes
+		}
+
+		block = common.NewBlock(uint64(i+1), previousHash)
+		block.Data.Data = [][]byte{txBytes}
+		block.Header.DataHash = block.Data.Hash()
+		utils.InitBlockMetadata(block)
+		assert.NoError(t, ledger.Commit(block))
+		previousHash = block.Header.Hash()
+	}
+
+	var snapshot bytes.Buffer
+	info, err := ledger.ExportSnapshot(&snapshot)
+	assert.NoError(t, err)
+	ledger.Close()
+
+	// bootstrap a fresh peer from the snapshot, which only knows the ID of
+	// the transaction committed before it
+	ledgermgmt.CleanupTestEnv()
+	ledgermgmt.InitializeTestEnv()
+	_, ledger, err = ledgermgmt.CreateLedgerFromSnapshot(bytes.NewReader(snapshot.Bytes()), info.Hash)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	validator := &txValidator{ledger, &mockVsccValidator{}}
+
+	// Replay the transaction after the snapshot
+	block = common.NewBlock(3, previousHash)
+	block.Data.Data = [][]byte{envBytes}
+	block.Header.DataHash = block.Data.Hash()
+
+	validator.Validate(block)
+
+	txsfltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+
+	assert.True(t, txsfltr.IsSet(0))
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/golang/protobuf/proto"
 	coreUtil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/blkstorage"
 	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/protos/common"
@@ -76,6 +78,9 @@ func (v *txValidator) Validate(block *common.Block) {
 	logger.Debug("START Block Validation")
 	defer logger.Debug("END Block Validation")
 	txsfltr := ledgerUtil.NewFilterBitArray(uint(len(block.Data.Data)))
+	// transactions IDs seen in this block, to reject a replay
+	// within the block itself before it reaches the ledger
+	txids := make(map[string]bool)
 	for tIdx, d := range block.Data.Data {
 		// Start by marking transaction as invalid, before
 		// doing any validation checks.
@@ -93,12 +98,18 @@ func (v *txValidator) Validate(block *common.Block) {
 				if payload, _, err := peer.ValidateTransaction(env); err != nil {
 					logger.Errorf("Invalid transaction with index %d, error %s", tIdx, err)
 				} else {
-					// Check duplicate transactions
+					// Check duplicate transactions, including the ones committed
+					// before the snapshot the ledger was bootstrapped from
 					txID := payload.Header.ChainHeader.TxID
-					if _, err := v.ledger.GetTransactionByID(txID); err == nil {
+					if _, err := v.ledger.GetTransactionByID(txID); err == nil || err == blkstorage.ErrTxInSnapshot {
 						logger.Warning("Duplicate transaction found, ", txID, ", skipping")
 						continue
 					}
+					if txids[txID] {
+						logger.Warning("Duplicate transaction found in the block, ", txID, ", skipping")
+						continue
+					}
+					txids[txID] = true
 
 					//the payload is used to get headers
 					logger.Debug("Validating transaction vscc tx validate")
@@ -174,7 +185,7 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 	if hdrExt.ChaincodeID.Name != "lccc" {
 		// Extracting vscc from lccc
 		logger.Info("Extracting chaincode data from LCCC txid = ", txid, "chainID", chainID, "chaincode name", hdrExt.ChaincodeID.Name)
-		data, err = chaincode.GetChaincodeDataFromLCCC(ctxt, txid, nil, chainID, hdrExt.ChaincodeID.Name)
+		data, err = chaincode.GetChaincodeDataFromLCCC(ctxt, txid, nil, nil, chainID, hdrExt.ChaincodeID.Name)
 		if err != nil {
 			logger.Errorf("Unable to get chaincode data from LCCC for txid %s, due to %s", txid, err)
 			return err
@@ -190,15 +201,19 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 	vscctxid := coreUtil.GenerateUUID()
 	// Get chaincode version
 	version := coreUtil.GetSysCCVersion()
-	cccid := chaincode.NewCCContext(chainID, vscc, version, vscctxid, true, nil)
+	cccid := chaincode.NewCCContext(chainID, vscc, version, vscctxid, true, nil, nil)
 
 	// invoke VSCC
 	logger.Info("Invoking VSCC txid", txid, "chaindID", chainID)
-	_, _, err = chaincode.ExecuteChaincode(ctxt, cccid, args)
+	res, _, err := chaincode.ExecuteChaincode(ctxt, cccid, args)
 	if err != nil {
-		logger.Errorf("VSCC check failed for transaction txid=%s, error %s", txid, err)
+		logger.Errorf("Invoke VSCC failed for transaction txid=%s, error %s", txid, err)
 		return err
 	}
+	if res.Status != shim.OK {
+		logger.Errorf("VSCC check failed for transaction txid=%s, error %s", txid, res.Message)
+		return fmt.Errorf("%s", res.Message)
+	}
 
 	return nil
 }
@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccpackage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+)
+
+// getSignatureData returns the bytes signed by an owner of the package: the
+// package holding only the deployment spec, the instantiation policy and the
+// owner's endorsement without signature. Protobuf length-prefixes each field,
+// so bytes cannot be moved from a field to another without breaking the
+// signature
+func getSignatureData(pkg *peer.SignedChaincodeDeploymentSpec, endorser []byte) ([]byte, error) {
+	return proto.Marshal(&peer.SignedChaincodeDeploymentSpec{
+		ChaincodeDeploymentSpec: pkg.ChaincodeDeploymentSpec,
+		InstantiationPolicy:     pkg.InstantiationPolicy,
+		OwnerEndorsements:       []*peer.Endorsement{{Endorser: endorser}},
+	})
+}
+
+// OwnerCreateSignedCCDepSpec creates a package from the deployment spec and
+// the instantiation policy. If an owner is given the package is signed by it,
+// otherwise the package is returned without endorsements
+func OwnerCreateSignedCCDepSpec(cds *peer.ChaincodeDeploymentSpec, instPolicy *common.SignaturePolicyEnvelope, owner msp.SigningIdentity) (*peer.SignedChaincodeDeploymentSpec, error) {
+	if cds == nil {
+		return nil, fmt.Errorf("Invalid nil deployment spec")
+	}
+
+	cdsBytes, err := proto.Marshal(cds)
+	if err != nil {
+		return nil, fmt.Errorf("Could not marshal deployment spec: %s", err)
+	}
+
+	var policyBytes []byte
+	if instPolicy != nil {
+		if policyBytes, err = proto.Marshal(instPolicy); err != nil {
+			return nil, fmt.Errorf("Could not marshal instantiation policy: %s", err)
+		}
+	}
+
+	pkg := &peer.SignedChaincodeDeploymentSpec{ChaincodeDeploymentSpec: cdsBytes, InstantiationPolicy: policyBytes}
+	if owner == nil {
+		return pkg, nil
+	}
+
+	return SignExistingPackage(pkg, owner)
+}
+
+// SignExistingPackage adds the endorsement of the owner to the package
+func SignExistingPackage(pkg *peer.SignedChaincodeDeploymentSpec, owner msp.SigningIdentity) (*peer.SignedChaincodeDeploymentSpec, error) {
+	if pkg == nil || owner == nil {
+		return nil, fmt.Errorf("A package and an owner are required to sign a package")
+	}
+
+	endorser, err := owner.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Could not serialize the owner identity: %s", err)
+	}
+
+	for _, e := range pkg.OwnerEndorsements {
+		if bytes.Equal(e.Endorser, endorser) {
+			return nil, fmt.Errorf("Package already signed by this owner")
+		}
+	}
+
+	data, err := getSignatureData(pkg, endorser)
+	if err != nil {
+		return nil, fmt.Errorf("Could not marshal the package: %s", err)
+	}
+
+	signature, err := owner.Sign(data)
+	if err != nil {
+		return nil, fmt.Errorf("Could not sign the package: %s", err)
+	}
+
+	pkg.OwnerEndorsements = append(pkg.OwnerEndorsements, &peer.Endorsement{Endorser: endorser, Signature: signature})
+
+	return pkg, nil
+}
+
+// ExtractDeploymentSpec returns the deployment spec carried by the package
+func ExtractDeploymentSpec(pkg *peer.SignedChaincodeDeploymentSpec) (*peer.ChaincodeDeploymentSpec, error) {
+	cds := &peer.ChaincodeDeploymentSpec{}
+	if err := proto.Unmarshal(pkg.ChaincodeDeploymentSpec, cds); err != nil {
+		return nil, fmt.Errorf("Could not unmarshal deployment spec from package: %s", err)
+	}
+
+	if cds.ChaincodeSpec == nil || cds.ChaincodeSpec.ChaincodeID == nil {
+		return nil, fmt.Errorf("Package does not carry a chaincode ID")
+	}
+
+	return cds, nil
+}
+
+// ValidateOwnerEndorsements checks that every owner endorsement on the
+// package is a valid signature of a known identity
+func ValidateOwnerEndorsements(pkg *peer.SignedChaincodeDeploymentSpec, deserializer msp.Common) error {
+	for _, e := range pkg.OwnerEndorsements {
+		id, err := deserializer.DeserializeIdentity(e.Endorser)
+		if err != nil {
+			return fmt.Errorf("Could not deserialize package owner: %s", err)
+		}
+
+		if err = id.Validate(); err != nil {
+			return fmt.Errorf("Invalid package owner: %s", err)
+		}
+
+		data, err := getSignatureData(pkg, e.Endorser)
+		if err != nil {
+			return fmt.Errorf("Could not marshal the package: %s", err)
+		}
+
+		if err = id.Verify(data, e.Signature); err != nil {
+			return fmt.Errorf("Invalid package owner signature: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// mspCryptoHelper verifies the signatures evaluated against a policy using
+// the identities known to an MSP
+type mspCryptoHelper struct {
+	deserializer msp.Common
+}
+
+func (h *mspCryptoHelper) VerifySignature(sd *common.SignedData) error {
+	id, err := h.deserializer.DeserializeIdentity(sd.Identity)
+	if err != nil {
+		return err
+	}
+
+	if err = id.Validate(); err != nil {
+		return err
+	}
+
+	return id.Verify(sd.Data, sd.Signature)
+}
+
+// CheckInstantiationPolicy checks that the signatures satisfy the
+// instantiation policy of the package. A package without an instantiation
+// policy can be instantiated by anyone
+func CheckInstantiationPolicy(pkg *peer.SignedChaincodeDeploymentSpec, signatures []*common.SignedData, deserializer msp.Common) error {
+	if len(pkg.InstantiationPolicy) == 0 {
+		return nil
+	}
+
+	policy, err := cauthdsl.NewPolicyProvider(&mspCryptoHelper{deserializer: deserializer}).NewPolicy(pkg.InstantiationPolicy)
+	if err != nil {
+		return fmt.Errorf("Invalid instantiation policy: %s", err)
+	}
+
+	if err = policy.Evaluate(signatures); err != nil {
+		return fmt.Errorf("Instantiation policy not satisfied: %s", err)
+	}
+
+	return nil
+}
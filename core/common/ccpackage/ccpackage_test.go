@@ -0,0 +1,190 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccpackage
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/util"
+	mspmgmt "github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/testtools"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+)
+
+var signer msp.SigningIdentity
+
+func createDeploymentSpec() *peer.ChaincodeDeploymentSpec {
+	spec := &peer.ChaincodeSpec{Type: 1, ChaincodeID: &peer.ChaincodeID{Name: "testcc", Version: "0"}}
+	return &peer.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: []byte("code")}
+}
+
+func signedData(t *testing.T, id msp.SigningIdentity, data []byte) []*common.SignedData {
+	creator, err := id.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize identity: %s", err)
+	}
+
+	signature, err := id.Sign(data)
+	if err != nil {
+		t.Fatalf("Could not sign: %s", err)
+	}
+
+	return []*common.SignedData{{Data: data, Identity: creator, Signature: signature}}
+}
+
+func TestCreateUnsignedPackage(t *testing.T) {
+	pkg, err := OwnerCreateSignedCCDepSpec(createDeploymentSpec(), nil, nil)
+	if err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+
+	if len(pkg.OwnerEndorsements) != 0 || len(pkg.InstantiationPolicy) != 0 {
+		t.Fatalf("Unexpected endorsements or policy in unsigned package")
+	}
+
+	cds, err := ExtractDeploymentSpec(pkg)
+	if err != nil {
+		t.Fatalf("Could not extract deployment spec: %s", err)
+	}
+
+	if cds.ChaincodeSpec.ChaincodeID.Name != "testcc" || cds.ChaincodeSpec.ChaincodeID.Version != "0" {
+		t.Fatalf("Unexpected chaincode ID %v", cds.ChaincodeSpec.ChaincodeID)
+	}
+
+	//no policy means anyone can instantiate
+	if err = CheckInstantiationPolicy(pkg, nil, mspmgmt.GetMSPCommon(util.GetTestChainID())); err != nil {
+		t.Fatalf("Unexpected error checking empty policy: %s", err)
+	}
+}
+
+func TestCreateSignedPackage(t *testing.T) {
+	pkg, err := OwnerCreateSignedCCDepSpec(createDeploymentSpec(), nil, signer)
+	if err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+
+	if len(pkg.OwnerEndorsements) != 1 {
+		t.Fatalf("Expected 1 endorsement, got %d", len(pkg.OwnerEndorsements))
+	}
+
+	if err = ValidateOwnerEndorsements(pkg, mspmgmt.GetMSPCommon(util.GetTestChainID())); err != nil {
+		t.Fatalf("Could not validate endorsements: %s", err)
+	}
+
+	//the same owner cannot sign twice
+	if _, err = SignExistingPackage(pkg, signer); err == nil {
+		t.Fatalf("Expected error signing the package twice with the same owner")
+	}
+}
+
+func TestTamperedPackage(t *testing.T) {
+	pkg, err := OwnerCreateSignedCCDepSpec(createDeploymentSpec(), nil, signer)
+	if err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+
+	pkg.ChaincodeDeploymentSpec = append(pkg.ChaincodeDeploymentSpec, 0)
+	if err = ValidateOwnerEndorsements(pkg, mspmgmt.GetMSPCommon(util.GetTestChainID())); err == nil {
+		t.Fatalf("Expected error validating a tampered package")
+	}
+}
+
+func TestMovedPackageBytes(t *testing.T) {
+	creator, err := signer.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize identity: %s", err)
+	}
+
+	policy := cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{creator})
+	pkg, err := OwnerCreateSignedCCDepSpec(createDeploymentSpec(), policy, signer)
+	if err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+
+	//moving the first byte of the policy to the end of the deployment spec
+	//keeps the concatenation of the fields but must break the signature
+	pkg.ChaincodeDeploymentSpec = append(pkg.ChaincodeDeploymentSpec, pkg.InstantiationPolicy[0])
+	pkg.InstantiationPolicy = pkg.InstantiationPolicy[1:]
+	if err = ValidateOwnerEndorsements(pkg, mspmgmt.GetMSPCommon(util.GetTestChainID())); err == nil {
+		t.Fatalf("Expected error validating a package whose bytes moved between fields")
+	}
+}
+
+func TestInstantiationPolicy(t *testing.T) {
+	creator, err := signer.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize identity: %s", err)
+	}
+
+	policy := cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{creator})
+	pkg, err := OwnerCreateSignedCCDepSpec(createDeploymentSpec(), policy, signer)
+	if err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+
+	deserializer := mspmgmt.GetMSPCommon(util.GetTestChainID())
+	if err = CheckInstantiationPolicy(pkg, signedData(t, signer, []byte("proposal")), deserializer); err != nil {
+		t.Fatalf("Expected policy to be satisfied: %s", err)
+	}
+
+	//a bad signature does not satisfy the policy
+	sd := signedData(t, signer, []byte("proposal"))
+	sd[0].Data = []byte("other proposal")
+	if err = CheckInstantiationPolicy(pkg, sd, deserializer); err == nil {
+		t.Fatalf("Expected error with invalid signature")
+	}
+
+	//a policy requiring another identity is not satisfied
+	policy = cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{[]byte("someone else")})
+	if pkg, err = OwnerCreateSignedCCDepSpec(createDeploymentSpec(), policy, nil); err != nil {
+		t.Fatalf("Could not create package: %s", err)
+	}
+	if err = CheckInstantiationPolicy(pkg, signedData(t, signer, []byte("proposal")), deserializer); err == nil {
+		t.Fatalf("Expected error with unsatisfied policy")
+	}
+}
+
+func TestMain(m *testing.M) {
+	// the identities are generated, so that the tests do not depend on the
+	// expiry of the sample MSP certificates
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		fmt.Printf("Could not generate msp, err %s", err)
+		os.Exit(-1)
+	}
+
+	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(dir, nil)
+	if err != nil {
+		fmt.Printf("Could not initialize msp, err %s", err)
+		os.Exit(-1)
+	}
+
+	signer, err = mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err != nil {
+		fmt.Printf("Could not get signer, err %s", err)
+		os.Exit(-1)
+	}
+
+	retVal := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(retVal)
+}
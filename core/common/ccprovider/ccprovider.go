@@ -0,0 +1,201 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccprovider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/op/go-logging"
+)
+
+var ccproviderLogger = logging.MustGetLogger("ccprovider")
+
+//chaincodeInstallPath is the directory where installed chaincode packages are kept
+var chaincodeInstallPath string
+
+//SetChaincodesPath sets the chaincode path for this peer, creating the
+//directory if it does not exist
+func SetChaincodesPath(path string) {
+	if s, err := os.Stat(path); err != nil {
+		if !os.IsNotExist(err) {
+			panic(fmt.Sprintf("Could not stat chaincodes install path: %s", err))
+		}
+		if err = os.MkdirAll(path, 0755); err != nil {
+			panic(fmt.Sprintf("Could not create chaincodes install path: %s", err))
+		}
+	} else if !s.IsDir() {
+		panic(fmt.Errorf("chaincode path exists but not a dir: %s", path))
+	}
+
+	chaincodeInstallPath = path
+}
+
+//getChaincodePackagePath returns the file path of the package for a chaincode
+//name and version
+func getChaincodePackagePath(ccname string, ccversion string) (string, error) {
+	if chaincodeInstallPath == "" {
+		return "", fmt.Errorf("Chaincodes install path not set")
+	}
+
+	if ccname == "" || ccversion == "" {
+		return "", fmt.Errorf("Chaincode name and version are required (name=%s, version=%s)", ccname, ccversion)
+	}
+
+	return filepath.Join(chaincodeInstallPath, ccname+"."+ccversion), nil
+}
+
+//GetChaincodePackageFromFS returns the signed package installed for the
+//chaincode name and version
+func GetChaincodePackageFromFS(ccname string, ccversion string) (*pb.SignedChaincodeDeploymentSpec, error) {
+	path, err := getChaincodePackagePath(ccname, ccversion)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgbytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &pb.SignedChaincodeDeploymentSpec{}
+	if err = proto.Unmarshal(pkgbytes, pkg); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal installed package for %s:%s - %s", ccname, ccversion, err)
+	}
+
+	return pkg, nil
+}
+
+//GetChaincodeFromFS returns the deployment spec of the package installed for
+//the chaincode name and version, both as bytes and unmarshaled
+func GetChaincodeFromFS(ccname string, ccversion string) ([]byte, *pb.ChaincodeDeploymentSpec, error) {
+	pkg, err := GetChaincodePackageFromFS(ccname, ccversion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cds, err := ccpackage.ExtractDeploymentSpec(pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pkg.ChaincodeDeploymentSpec, cds, nil
+}
+
+//PutChaincodePackageIntoFS writes the signed package to the local package
+//store. Installing the same name and version twice is an error
+func PutChaincodePackageIntoFS(pkg *pb.SignedChaincodeDeploymentSpec) error {
+	if pkg == nil {
+		return fmt.Errorf("Invalid nil package")
+	}
+
+	depSpec, err := ccpackage.ExtractDeploymentSpec(pkg)
+	if err != nil {
+		return err
+	}
+
+	ccname := depSpec.ChaincodeSpec.ChaincodeID.Name
+	ccversion := depSpec.ChaincodeSpec.ChaincodeID.Version
+
+	path, err := getChaincodePackagePath(ccname, ccversion)
+	if err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(path); err == nil {
+		return fmt.Errorf("Chaincode %s:%s already installed", ccname, ccversion)
+	}
+
+	b, err := proto.Marshal(pkg)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal package for %s:%s - %s", ccname, ccversion, err)
+	}
+
+	if err = ioutil.WriteFile(path, b, 0644); err != nil {
+		return err
+	}
+
+	ccproviderLogger.Debugf("Installed chaincode %s:%s at %s", ccname, ccversion, path)
+
+	return nil
+}
+
+//PutChaincodeIntoFS writes the deployment spec to the local package store as
+//a package without instantiation policy or owners
+func PutChaincodeIntoFS(depSpec *pb.ChaincodeDeploymentSpec) error {
+	pkg, err := ccpackage.OwnerCreateSignedCCDepSpec(depSpec, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return PutChaincodePackageIntoFS(pkg)
+}
+
+//GetCodeHash returns the hash recorded on the ledger for the code package of
+//an installed chaincode
+func GetCodeHash(depSpec *pb.ChaincodeDeploymentSpec) []byte {
+	return util.ComputeCryptoHash(depSpec.CodePackage)
+}
+
+//GetInstalledChaincodes returns information about the chaincode packages
+//installed on this peer. The name and version are read from each package
+//since file names can not be split unambiguously
+func GetInstalledChaincodes() (*pb.ChaincodeQueryResponse, error) {
+	if chaincodeInstallPath == "" {
+		return nil, fmt.Errorf("Chaincodes install path not set")
+	}
+
+	files, err := ioutil.ReadDir(chaincodeInstallPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var ccInfoArray []*pb.ChaincodeInfo
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		pkgbytes, err := ioutil.ReadFile(filepath.Join(chaincodeInstallPath, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		pkg := &pb.SignedChaincodeDeploymentSpec{}
+		if err = proto.Unmarshal(pkgbytes, pkg); err != nil {
+			ccproviderLogger.Warningf("Skipping invalid package %s: %s", file.Name(), err)
+			continue
+		}
+
+		depSpec, err := ccpackage.ExtractDeploymentSpec(pkg)
+		if err != nil {
+			ccproviderLogger.Warningf("Skipping invalid package %s: %s", file.Name(), err)
+			continue
+		}
+
+		ccID := depSpec.ChaincodeSpec.ChaincodeID
+		ccInfoArray = append(ccInfoArray, &pb.ChaincodeInfo{Name: ccID.Name, Version: ccID.Version, Path: ccID.Path, Id: GetCodeHash(depSpec)})
+	}
+
+	return &pb.ChaincodeQueryResponse{Chaincodes: ccInfoArray}, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccprovider
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func getTestDepSpec(name string, version string) *pb.ChaincodeDeploymentSpec {
+	spec := &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: name, Version: version, Path: "some/path"}}
+	return &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: []byte("code package")}
+}
+
+func TestPutAndGetChaincode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccprovidertest")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetChaincodesPath(dir)
+
+	cds := getTestDepSpec("mycc", "1.0")
+	if err = PutChaincodeIntoFS(cds); err != nil {
+		t.Fatalf("Install failed: %s", err)
+	}
+
+	if err = PutChaincodeIntoFS(cds); err == nil {
+		t.Fatalf("Installing the same chaincode twice should have failed")
+	}
+
+	_, got, err := GetChaincodeFromFS("mycc", "1.0")
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+
+	if !bytes.Equal(GetCodeHash(got), GetCodeHash(cds)) {
+		t.Fatalf("Installed package does not match")
+	}
+
+	if _, _, err = GetChaincodeFromFS("mycc", "2.0"); err == nil {
+		t.Fatalf("Expected error for a version that was not installed")
+	}
+}
+
+func TestPutChaincodeWithoutVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccprovidertest")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetChaincodesPath(dir)
+
+	if err = PutChaincodeIntoFS(getTestDepSpec("mycc", "")); err == nil {
+		t.Fatalf("Expected error for chaincode without version")
+	}
+}
+
+func TestGetInstalledChaincodes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccprovidertest")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetChaincodesPath(dir)
+
+	for _, version := range []string{"1.0", "1.1"} {
+		if err = PutChaincodeIntoFS(getTestDepSpec("my.cc", version)); err != nil {
+			t.Fatalf("Install failed: %s", err)
+		}
+	}
+
+	resp, err := GetInstalledChaincodes()
+	if err != nil {
+		t.Fatalf("Get installed chaincodes failed: %s", err)
+	}
+
+	if len(resp.Chaincodes) != 2 {
+		t.Fatalf("Expected 2 installed chaincodes, got %d", len(resp.Chaincodes))
+	}
+
+	for i, version := range []string{"1.0", "1.1"} {
+		info := resp.Chaincodes[i]
+		if info.Name != "my.cc" || info.Version != version || info.Path != "some/path" || !bytes.Equal(info.Id, GetCodeHash(getTestDepSpec("my.cc", version))) {
+			t.Fatalf("Unexpected chaincode info %v", info)
+		}
+	}
+}
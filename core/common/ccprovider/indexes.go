@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ccprovider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// StateDBIndexesDir is the directory of a chaincode that contains the CouchDB
+// index definitions to be created when the chaincode is deployed
+const StateDBIndexesDir = "META-INF/statedb/couchdb/indexes"
+
+// ExtractStateDBIndexes returns the index definitions (the *.json files of the
+// StateDBIndexesDir directory) of a gzipped tar code package, keyed by their path
+// in the package. An empty code package has no index definitions
+func ExtractStateDBIndexes(codePackage []byte) (map[string][]byte, error) {
+	indexes := make(map[string][]byte)
+	if len(codePackage) == 0 {
+		return indexes, nil
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(codePackage))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading code package: %s", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return indexes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading code package: %s", err)
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if path.Ext(name) != ".json" || !strings.HasSuffix(path.Dir(name), StateDBIndexesDir) {
+			continue
+		}
+		definition, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading %s from code package: %s", name, err)
+		}
+		indexes[name] = definition
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ccprovider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func writeTestCodePackage(t *testing.T, files map[string]string) []byte {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatalf("Error writing header: %s", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Error writing content: %s", err)
+		}
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestExtractStateDBIndexes(t *testing.T) {
+	index := `{"index":{"fields":["owner"]},"name":"indexOwner","type":"json"}`
+	codePackage := writeTestCodePackage(t, map[string]string{
+		"src/github.com/example/cc/cc.go":                                        "package main",
+		"src/github.com/example/cc/META-INF/statedb/couchdb/indexes/owner.json":  index,
+		"src/github.com/example/cc/META-INF/statedb/couchdb/indexes/README.md":   "not an index",
+		"src/github.com/example/cc/META-INF/statedb/couchdb/other/ignored.json":  "{}",
+		"src/github.com/example/cc/vendor/META-INF/statedb/couchdb/notindex.txt": "{}",
+	})
+
+	indexes, err := ExtractStateDBIndexes(codePackage)
+	if err != nil {
+		t.Fatalf("Error extracting indexes: %s", err)
+	}
+	if len(indexes) != 1 {
+		t.Fatalf("Expected 1 index, got %d: %v", len(indexes), indexes)
+	}
+	if string(indexes["src/github.com/example/cc/META-INF/statedb/couchdb/indexes/owner.json"]) != index {
+		t.Fatalf("Unexpected indexes %v", indexes)
+	}
+
+	indexes, err = ExtractStateDBIndexes(nil)
+	if err != nil || len(indexes) != 0 {
+		t.Fatalf("Expected no index for an empty package, got %v, %v", indexes, err)
+	}
+
+	if _, err = ExtractStateDBIndexes([]byte("not a package")); err == nil {
+		t.Fatalf("Expected an error for an invalid package")
+	}
+}
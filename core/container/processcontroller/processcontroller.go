@@ -0,0 +1,417 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processcontroller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultMaxRestarts = 5
+	binaryName         = "chaincode"
+)
+
+var (
+	processLogger = logging.MustGetLogger("processcontroller")
+
+	//running chaincode processes by VM name
+	instances     = make(map[string]*ccProcess)
+	instancesLock sync.Mutex
+
+	//delay before restarting a chaincode process that exited on its own
+	restartDelay = time.Second
+)
+
+//ccProcess is a chaincode running as a child process of the peer. It is
+//restarted when it exits until it is stopped or has crashed too many times
+type ccProcess struct {
+	sync.Mutex
+	name     string
+	binary   string
+	args     []string
+	env      []string
+	cmd      *exec.Cmd
+	stopped  bool
+	restarts int
+	//closed when the process is no longer supervised
+	done chan struct{}
+}
+
+//ProcessVM is a vm running chaincode as local processes. Chaincode is built
+//with the local Go toolchain into a binary cached under vm.process.cachepath
+type ProcessVM struct {
+	id string
+}
+
+//getCachePath returns the directory holding the chaincode binaries
+func getCachePath() string {
+	if path := viper.GetString("vm.process.cachepath"); path != "" {
+		return path
+	}
+	return filepath.Join(viper.GetString("peer.fileSystemPath"), "ccbin")
+}
+
+//getMaxRestarts returns how many times a crashed chaincode is restarted
+func getMaxRestarts() int {
+	if !viper.IsSet("vm.process.maxrestarts") {
+		return defaultMaxRestarts
+	}
+	return viper.GetInt("vm.process.maxrestarts")
+}
+
+//getGoCache returns the build cache of the Go toolchain, shared by the
+//chaincode builds
+func getGoCache() string {
+	if cache := os.Getenv("GOCACHE"); cache != "" {
+		return cache
+	}
+	return filepath.Join(getCachePath(), "gocache")
+}
+
+//getAllowedEnv returns the variables of the peer's environment among names.
+//Chaincode does not inherit the rest of the environment of the peer, which
+//holds secrets such as the passphrase of the keystore and the CORE_ settings
+func getAllowedEnv(names ...string) []string {
+	var env []string
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+func getBinaryPath(name string) string {
+	return filepath.Join(getCachePath(), name, binaryName)
+}
+
+//extractCodePackage writes the files of the gzipped tar code package under dir
+func extractCodePackage(reader io.Reader, dir string) error {
+	gr, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("Error reading code package: %s", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Error reading code package: %s", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.Clean("/"+hdr.Name))
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//getGoPackage returns the import path of the chaincode
+func getGoPackage(spec *pb.ChaincodeSpec) string {
+	path := spec.ChaincodeID.Path
+	path = strings.TrimPrefix(path, "http://")
+	path = strings.TrimPrefix(path, "https://")
+	return strings.TrimSuffix(path, "/")
+}
+
+//build extracts the code package in a private GOPATH and builds the
+//chaincode binary from it. The sources are removed once the binary is built
+func (vm *ProcessVM) build(ccid ccintf.CCID, reader io.Reader) error {
+	if ccid.ChaincodeSpec.Type != pb.ChaincodeSpec_GOLANG {
+		return fmt.Errorf("Only Go chaincode can run as a process, not %s", ccid.ChaincodeSpec.Type)
+	}
+
+	if reader == nil {
+		return fmt.Errorf("No code package to build the chaincode from")
+	}
+
+	name, _ := vm.GetVMName(ccid)
+	gopath := filepath.Join(getCachePath(), name, "gopath")
+	os.RemoveAll(gopath)
+	defer os.RemoveAll(gopath)
+
+	if err := extractCodePackage(reader, gopath); err != nil {
+		return err
+	}
+
+	binary := getBinaryPath(name)
+	cmd := exec.Command("go", "build", "-o", binary, getGoPackage(ccid.ChaincodeSpec))
+	cmd.Dir = gopath
+	//the peer's GOPATH is searched after the code package, and the build is
+	//done in GOPATH mode whatever the toolchain defaults are
+	cmd.Env = append(getAllowedEnv("PATH"),
+		"GOPATH="+gopath+string(os.PathListSeparator)+os.Getenv("GOPATH"),
+		"GOCACHE="+getGoCache(),
+		"GO111MODULE=off",
+		"GOFLAGS=")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		processLogger.Errorf("Error building chaincode %s: %s", name, err)
+		processLogger.Errorf("Build Output:\n********************\n%s\n********************", output)
+		return fmt.Errorf("Error building chaincode %s: %s", name, err)
+	}
+
+	processLogger.Debugf("Built chaincode %s at %s", name, binary)
+
+	return nil
+}
+
+//Deploy builds the chaincode binary from the code package
+func (vm *ProcessVM) Deploy(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, attachstdin bool, attachstdout bool, reader io.Reader) error {
+	return vm.build(ccid, reader)
+}
+
+//outputLogger logs every line the chaincode writes to one of its outputs
+type outputLogger struct {
+	name string
+	buf  []byte
+}
+
+func (l *outputLogger) Write(b []byte) (int, error) {
+	l.buf = append(l.buf, b...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		processLogger.Infof("[%s] %s", l.name, l.buf[:i])
+		l.buf = l.buf[i+1:]
+	}
+}
+
+//launch starts the chaincode process, call this under lock
+func (p *ccProcess) launch() error {
+	cmd := exec.Command(p.binary, p.args...)
+	cmd.Env = append(getAllowedEnv("PATH"), p.env...)
+	cmd.Stdout = &outputLogger{name: p.name}
+	cmd.Stderr = &outputLogger{name: p.name}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	p.cmd = cmd
+	processLogger.Debugf("Started chaincode %s (pid %d)", p.name, cmd.Process.Pid)
+
+	return nil
+}
+
+//supervise waits for the chaincode process to exit and restarts it unless it
+//was stopped or has been restarted too many times
+func (p *ccProcess) supervise() {
+	defer close(p.done)
+	for {
+		p.Lock()
+		cmd := p.cmd
+		p.Unlock()
+
+		err := cmd.Wait()
+
+		p.Lock()
+		if p.stopped {
+			p.Unlock()
+			processLogger.Debugf("Chaincode %s stopped", p.name)
+			return
+		}
+
+		if p.restarts >= getMaxRestarts() {
+			p.stopped = true
+			p.Unlock()
+			processLogger.Errorf("Chaincode %s exited (%v), giving up after %d restarts", p.name, err, p.restarts)
+			removeInstance(p)
+			return
+		}
+		p.restarts++
+		p.Unlock()
+
+		processLogger.Warningf("Chaincode %s exited (%v), restarting (%d/%d)", p.name, err, p.restarts, getMaxRestarts())
+		time.Sleep(restartDelay)
+
+		p.Lock()
+		if p.stopped {
+			p.Unlock()
+			return
+		}
+		err = p.launch()
+		p.Unlock()
+		if err != nil {
+			processLogger.Errorf("Could not restart chaincode %s: %s", p.name, err)
+			removeInstance(p)
+			return
+		}
+	}
+}
+
+//stop terminates the chaincode process and waits for the supervisor to
+//return. The process is killed if it is still running after the timeout
+func (p *ccProcess) stop(timeout uint, dontkill bool) {
+	p.Lock()
+	p.stopped = true
+	cmd := p.cmd
+	p.Unlock()
+
+	if cmd.Process != nil {
+		cmd.Process.Signal(os.Interrupt)
+	}
+
+	select {
+	case <-p.done:
+		return
+	case <-time.After(time.Duration(timeout) * time.Second):
+	}
+
+	if dontkill {
+		processLogger.Warningf("Chaincode %s did not exit after %d seconds", p.name, timeout)
+		return
+	}
+
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+	<-p.done
+}
+
+func removeInstance(p *ccProcess) {
+	instancesLock.Lock()
+	if instances[p.name] == p {
+		delete(instances, p.name)
+	}
+	instancesLock.Unlock()
+}
+
+//Start runs the chaincode binary as a supervised process, building it first
+//if it is not in the cache
+func (vm *ProcessVM) Start(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, attachstdin bool, attachstdout bool, reader io.Reader) error {
+	name, _ := vm.GetVMName(ccid)
+
+	//stop if necessary
+	vm.stopInternal(name, 0, false)
+
+	binary := getBinaryPath(name)
+	if _, err := os.Stat(binary); err != nil {
+		processLogger.Debugf("start-could not find binary for %s ...attempt to build it", name)
+		if err = vm.build(ccid, reader); err != nil {
+			return err
+		}
+	}
+
+	//the first argument is the executable inside the docker image, replace
+	//it with the cached binary
+	if len(args) > 0 {
+		args = args[1:]
+	}
+
+	p := &ccProcess{name: name, binary: binary, args: args, env: env, done: make(chan struct{})}
+	p.Lock()
+	err := p.launch()
+	p.Unlock()
+	if err != nil {
+		processLogger.Errorf("start-could not start chaincode %s: %s", name, err)
+		return err
+	}
+
+	instancesLock.Lock()
+	instances[name] = p
+	instancesLock.Unlock()
+
+	go p.supervise()
+
+	return nil
+}
+
+func (vm *ProcessVM) stopInternal(name string, timeout uint, dontkill bool) {
+	instancesLock.Lock()
+	p := instances[name]
+	delete(instances, name)
+	instancesLock.Unlock()
+
+	if p == nil {
+		processLogger.Debugf("No chaincode process running for %s", name)
+		return
+	}
+
+	p.stop(timeout, dontkill)
+}
+
+//Stop stops a running chaincode. A process does not leave anything behind
+//once it exits so dontremove has no effect
+func (vm *ProcessVM) Stop(ctxt context.Context, ccid ccintf.CCID, timeout uint, dontkill bool, dontremove bool) error {
+	name, _ := vm.GetVMName(ccid)
+	vm.stopInternal(name, timeout, dontkill)
+	return nil
+}
+
+//Destroy stops the chaincode and removes its cached binary
+func (vm *ProcessVM) Destroy(ctxt context.Context, ccid ccintf.CCID, force bool, noprune bool) error {
+	name, _ := vm.GetVMName(ccid)
+	vm.stopInternal(name, 0, false)
+
+	if err := os.RemoveAll(filepath.Join(getCachePath(), name)); err != nil {
+		processLogger.Errorf("error while destroying chaincode binary: %s", err)
+		return err
+	}
+
+	processLogger.Debugf("Destroyed chaincode binary %s", name)
+	return nil
+}
+
+//GetVMName generates the name of the chaincode binary from peer information
+//like the docker image name, so that several peers can share a host
+func (vm *ProcessVM) GetVMName(ccid ccintf.CCID) (string, error) {
+	name := ccid.GetName()
+
+	if ccid.NetworkID != "" {
+		return fmt.Sprintf("%s-%s-%s", ccid.NetworkID, ccid.PeerID, name), nil
+	} else if ccid.PeerID != "" {
+		return fmt.Sprintf("%s-%s", ccid.PeerID, name), nil
+	}
+	return name, nil
+}
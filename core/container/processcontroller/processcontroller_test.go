@@ -0,0 +1,243 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package processcontroller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+//the test program appends a line to the file named by CCPROC_OUT, followed
+//by the variable named by CCPROC_PRINTENV if any, and then either waits to be
+//stopped or exits with an error, as a crashing chaincode
+const testProgram = `package main
+
+import (
+	"os"
+	"time"
+)
+
+func main() {
+	f, err := os.OpenFile(os.Getenv("CCPROC_OUT"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		os.Exit(2)
+	}
+	f.WriteString(os.Args[1] + "\n")
+	if name := os.Getenv("CCPROC_PRINTENV"); name != "" {
+		f.WriteString(name + "=" + os.Getenv(name) + "\n")
+	}
+	f.Close()
+
+	if os.Getenv("CCPROC_CRASH") != "" {
+		os.Exit(1)
+	}
+	time.Sleep(time.Minute)
+}
+`
+
+const testPath = "example.com/ccproc"
+
+func getCodePackage(t *testing.T) []byte {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	hdr := &tar.Header{Name: "src/" + testPath + "/main.go", Mode: 0644, Size: int64(len(testProgram))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("Error writing tar header: %s", err)
+	}
+	tw.Write([]byte(testProgram))
+	tw.Close()
+	gw.Close()
+
+	return buf.Bytes()
+}
+
+func getCCID(name string) ccintf.CCID {
+	spec := &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: name, Path: testPath}}
+	return ccintf.CCID{ChaincodeSpec: spec, PeerID: "peer0", Version: "0"}
+}
+
+func setupCache(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "processcontroller")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	viper.Set("vm.process.cachepath", dir)
+	return dir
+}
+
+func readLines(file string) []string {
+	b, err := ioutil.ReadFile(file)
+	if err != nil || len(b) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func isRunning(name string) bool {
+	instancesLock.Lock()
+	defer instancesLock.Unlock()
+	return instances[name] != nil
+}
+
+//waitFor polls the condition for up to 10 seconds
+func waitFor(cond func() bool) bool {
+	for i := 0; i < 1000; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestDeployStartStopDestroy(t *testing.T) {
+	dir := setupCache(t)
+	defer os.RemoveAll(dir)
+
+	vm := &ProcessVM{}
+	ccid := getCCID("mycc")
+	name, _ := vm.GetVMName(ccid)
+	out := filepath.Join(dir, "out")
+
+	if err := vm.Deploy(context.Background(), ccid, nil, nil, false, false, bytes.NewReader(getCodePackage(t))); err != nil {
+		t.Fatalf("Deploy failed: %s", err)
+	}
+
+	if _, err := os.Stat(getBinaryPath(name)); err != nil {
+		t.Fatalf("Chaincode binary not cached: %s", err)
+	}
+
+	//the first argument is replaced with the cached binary
+	args := []string{"/opt/gopath/bin/mycc", "started"}
+	if err := vm.Start(context.Background(), ccid, args, []string{"CCPROC_OUT=" + out}, false, false, nil); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+
+	if !waitFor(func() bool { return len(readLines(out)) == 1 }) {
+		t.Fatalf("Chaincode process did not start")
+	}
+	if lines := readLines(out); lines[0] != "started" {
+		t.Fatalf("Unexpected chaincode arguments %v", lines)
+	}
+
+	if err := vm.Stop(context.Background(), ccid, 0, false, false); err != nil {
+		t.Fatalf("Stop failed: %s", err)
+	}
+	if isRunning(name) {
+		t.Fatalf("Chaincode process still registered after stop")
+	}
+
+	if err := vm.Destroy(context.Background(), ccid, false, false); err != nil {
+		t.Fatalf("Destroy failed: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+		t.Fatalf("Chaincode binary not removed: %v", err)
+	}
+}
+
+func TestPeerEnvironmentNotInherited(t *testing.T) {
+	dir := setupCache(t)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("BCCSP_KEYSTORE_PASSPHRASE", "secret")
+	defer os.Unsetenv("BCCSP_KEYSTORE_PASSPHRASE")
+
+	vm := &ProcessVM{}
+	ccid := getCCID("envcc")
+	out := filepath.Join(dir, "out")
+
+	args := []string{"/opt/gopath/bin/envcc", "started"}
+	env := []string{"CCPROC_OUT=" + out, "CCPROC_PRINTENV=BCCSP_KEYSTORE_PASSPHRASE"}
+	if err := vm.Start(context.Background(), ccid, args, env, false, false, bytes.NewReader(getCodePackage(t))); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+	defer vm.Destroy(context.Background(), ccid, false, false)
+
+	if !waitFor(func() bool { return len(readLines(out)) == 2 }) {
+		t.Fatalf("Chaincode process did not start")
+	}
+	if lines := readLines(out); lines[1] != "BCCSP_KEYSTORE_PASSPHRASE=" {
+		t.Fatalf("The passphrase of the peer reached the chaincode: %v", lines)
+	}
+}
+
+func TestRestartOnCrash(t *testing.T) {
+	dir := setupCache(t)
+	defer os.RemoveAll(dir)
+
+	viper.Set("vm.process.maxrestarts", 2)
+	defer viper.Set("vm.process.maxrestarts", defaultMaxRestarts)
+	restartDelay = 10 * time.Millisecond
+	defer func() { restartDelay = time.Second }()
+
+	vm := &ProcessVM{}
+	ccid := getCCID("crashcc")
+	name, _ := vm.GetVMName(ccid)
+	out := filepath.Join(dir, "out")
+
+	//start builds the binary from the package when it is not cached
+	args := []string{"/opt/gopath/bin/crashcc", "run"}
+	env := []string{"CCPROC_OUT=" + out, "CCPROC_CRASH=true"}
+	if err := vm.Start(context.Background(), ccid, args, env, false, false, bytes.NewReader(getCodePackage(t))); err != nil {
+		t.Fatalf("Start failed: %s", err)
+	}
+
+	if !waitFor(func() bool { return !isRunning(name) }) {
+		t.Fatalf("Supervisor did not give up on the crashing chaincode")
+	}
+
+	//one run and two restarts
+	if lines := readLines(out); len(lines) != 3 {
+		t.Fatalf("Expected 3 runs, got %d", len(lines))
+	}
+}
+
+func TestStartWithoutPackage(t *testing.T) {
+	dir := setupCache(t)
+	defer os.RemoveAll(dir)
+
+	vm := &ProcessVM{}
+	if err := vm.Start(context.Background(), getCCID("nocc"), nil, nil, false, false, nil); err == nil {
+		t.Fatalf("Expected error starting a chaincode that was never built")
+	}
+}
+
+func TestDeployNotGolang(t *testing.T) {
+	dir := setupCache(t)
+	defer os.RemoveAll(dir)
+
+	vm := &ProcessVM{}
+	ccid := getCCID("javacc")
+	ccid.ChaincodeSpec.Type = pb.ChaincodeSpec_JAVA
+	if err := vm.Deploy(context.Background(), ccid, nil, nil, false, false, bytes.NewReader(getCodePackage(t))); err == nil {
+		t.Fatalf("Expected error deploying java chaincode as a process")
+	}
+}
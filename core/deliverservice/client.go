@@ -104,7 +104,7 @@ func (d *DeliverService) startDeliver(committer committer.Committer) error {
 
 	}
 
-	d.readUntilClose()
+	d.readUntilClose(committer)
 
 	return nil
 }
@@ -191,7 +191,7 @@ func (d *DeliverService) seekLatestFromCommitter(height uint64) error {
 	})
 }
 
-func (d *DeliverService) readUntilClose() {
+func (d *DeliverService) readUntilClose(committer committer.Committer) {
 	for {
 		msg, err := d.client.Recv()
 		if err != nil {
@@ -206,6 +206,12 @@ func (d *DeliverService) readUntilClose() {
 			}
 			logger.Warning("Got error ", t)
 		case *orderer.DeliverResponse_Block:
+			// Reject blocks not produced by the ordering service before
+			// they reach the validator and other peers
+			if err := committer.VerifyBlock(t.Block); err != nil {
+				logger.Errorf("Rejecting block received from the ordering service: %s", err)
+				continue
+			}
 			seqNum := t.Block.Header.Number
 
 			// Create new transactions validator
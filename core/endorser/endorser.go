@@ -25,7 +25,12 @@ import (
 
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/blkstorage"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protos/common"
@@ -88,9 +93,9 @@ func (e *Endorser) deploy(ctxt context.Context, cccid *chaincode.CCContext, cds
 }
 
 //call specified chaincode (system or user)
-func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version string, txid string, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, cid *pb.ChaincodeID, txsim ledger.TxSimulator) ([]byte, *pb.ChaincodeEvent, error) {
+func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
 	var err error
-	var b []byte
+	var res *pb.Response
 	var ccevent *pb.ChaincodeEvent
 
 	if txsim != nil {
@@ -100,14 +105,22 @@ func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version s
 	//is this a system chaincode
 	syscc := chaincode.IsSysCC(cid.Name)
 
-	cccid := chaincode.NewCCContext(chainID, cid.Name, version, txid, syscc, prop)
+	cccid := chaincode.NewCCContext(chainID, cid.Name, version, txid, syscc, signedProp, prop)
 
-	b, ccevent, err = chaincode.ExecuteChaincode(ctxt, cccid, cis.ChaincodeSpec.CtorMsg.Args)
+	res, ccevent, err = chaincode.ExecuteChaincode(ctxt, cccid, cis.ChaincodeSpec.CtorMsg.Args)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
+	//per doc anything < 400 can be sent as TX.
+	//fabric errors will always be >= 400 (ie, unambiguous errors )
+	//"lccc" will respond with status 200 or 500 (ie, unambiguous OK or ERROR)
+	//This leaves all < 400 to be anything the application wants to return.
+	if res.Status >= shim.ERRORTHRESHOLD {
+		return res, nil, nil
+	}
+
 	//----- BEGIN -  SECTION THAT MAY NEED TO BE DONE IN LCCC ------
 	//if this a call to deploy a chaincode, We need a mechanism
 	//to pass TxSimulator into LCCC. Till that is worked out this
@@ -116,19 +129,7 @@ func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version s
 	//
 	//NOTE that if there's an error all simulation, including the chaincode
 	//table changes in lccc will be thrown away
-	if cid.Name == "lccc" && len(cis.ChaincodeSpec.CtorMsg.Args) == 3 && (string(cis.ChaincodeSpec.CtorMsg.Args[0]) == "deploy" || string(cis.ChaincodeSpec.CtorMsg.Args[0]) == "upgrade") {
-		var ccVersion string
-		switch string(cis.ChaincodeSpec.CtorMsg.Args[0]) {
-		case "deploy":
-			//NOTE - if user provides chaincode version on deploy, that'll be in the
-			//ChaincodeID and will be used
-			ccVersion = "0"
-		case "upgrade":
-			//use the new version
-			ccVersion = string(b)
-		default:
-			panic(fmt.Sprintf("invalid call to lccc... we shouldn't have got here (ie,passed ExecuteChaincode (%s))", cis.ChaincodeSpec.CtorMsg.Args[0]))
-		}
+	if cid.Name == "lccc" && len(cis.ChaincodeSpec.CtorMsg.Args) >= 3 && (string(cis.ChaincodeSpec.CtorMsg.Args[0]) == "deploy" || string(cis.ChaincodeSpec.CtorMsg.Args[0]) == "upgrade") {
 		var cds *pb.ChaincodeDeploymentSpec
 		cds, err = putils.GetChaincodeDeploymentSpec(cis.ChaincodeSpec.CtorMsg.Args[2])
 		if err != nil {
@@ -140,20 +141,31 @@ func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version s
 			return nil, nil, fmt.Errorf("attempting to deploy a system chaincode %s/%s", cds.ChaincodeSpec.ChaincodeID.Name, chainID)
 		}
 
-		cccid = chaincode.NewCCContext(chainID, cds.ChaincodeSpec.ChaincodeID.Name, ccVersion, txid, false, prop)
+		//the code comes from the package installed on this peer, the
+		//proposal only carries the name, version and init arguments
+		ccVersion := cds.ChaincodeSpec.ChaincodeID.Version
+
+		var installedCds *pb.ChaincodeDeploymentSpec
+		_, installedCds, err = ccprovider.GetChaincodeFromFS(cds.ChaincodeSpec.ChaincodeID.Name, ccVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get installed chaincode %s:%s - %s", cds.ChaincodeSpec.ChaincodeID.Name, ccVersion, err)
+		}
+		installedCds.ChaincodeSpec.CtorMsg = cds.ChaincodeSpec.CtorMsg
+
+		cccid = chaincode.NewCCContext(chainID, cds.ChaincodeSpec.ChaincodeID.Name, ccVersion, txid, false, signedProp, prop)
 
-		err = e.deploy(ctxt, cccid, cds)
+		err = e.deploy(ctxt, cccid, installedCds)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 	//----- END -------
 
-	return b, ccevent, err
+	return res, ccevent, err
 }
 
 //simulate the proposal by calling the chaincode
-func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid string, prop *pb.Proposal, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*chaincode.ChaincodeData, []byte, []byte, *pb.ChaincodeEvent, error) {
+func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*chaincode.ChaincodeData, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
 	//we do expect the payload to be a ChaincodeInvocationSpec
 	//if we are supporting other payloads in future, this be glaringly point
 	//as something that should change
@@ -176,7 +188,7 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 	//default it to a system CC
 	version := util.GetSysCCVersion()
 	if !chaincode.IsSysCC(cid.Name) {
-		cd, err = e.getCDSFromLCCC(ctx, chainID, txid, prop, cid.Name, txsim)
+		cd, err = e.getCDSFromLCCC(ctx, chainID, txid, signedProp, prop, cid.Name, txsim)
 		if err != nil {
 			return nil, nil, nil, nil, fmt.Errorf("failed to obtain cds for %s - %s", cid.Name, err)
 		}
@@ -185,47 +197,46 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 
 	//---3. execute the proposal and get simulation results
 	var simResult []byte
-	var resp []byte
+	var res *pb.Response
 	var ccevent *pb.ChaincodeEvent
-	resp, ccevent, err = e.callChaincode(ctx, chainID, version, txid, prop, cis, cid, txsim)
+	res, ccevent, err = e.callChaincode(ctx, chainID, version, txid, signedProp, prop, cis, cid, txsim)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
+	//a rejected proposal is not endorsed, its simulation results are of no use
+	if res.Status >= shim.ERRORTHRESHOLD {
+		return cd, res, nil, nil, nil
+	}
+
 	if txsim != nil {
 		if simResult, err = txsim.GetTxSimulationResults(); err != nil {
 			return nil, nil, nil, nil, err
 		}
 	}
 
-	return cd, resp, simResult, ccevent, nil
+	return cd, res, simResult, ccevent, nil
 }
 
-func (e *Endorser) getCDSFromLCCC(ctx context.Context, chainID string, txid string, prop *pb.Proposal, chaincodeID string, txsim ledger.TxSimulator) (*chaincode.ChaincodeData, error) {
+func (e *Endorser) getCDSFromLCCC(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, chaincodeID string, txsim ledger.TxSimulator) (*chaincode.ChaincodeData, error) {
 	ctxt := ctx
 	if txsim != nil {
 		ctxt = context.WithValue(ctx, chaincode.TXSimulatorKey, txsim)
 	}
 
-	return chaincode.GetChaincodeDataFromLCCC(ctxt, txid, prop, chainID, chaincodeID)
+	return chaincode.GetChaincodeDataFromLCCC(ctxt, txid, signedProp, prop, chainID, chaincodeID)
 }
 
 //endorse the proposal by calling the ESCC
-func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid string, proposal *pb.Proposal, simRes []byte, event *pb.ChaincodeEvent, visibility []byte, ccid *pb.ChaincodeID, txsim ledger.TxSimulator, cd *chaincode.ChaincodeData) (*pb.ProposalResponse, error) {
+func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, proposal *pb.Proposal, response *pb.Response, simRes []byte, event *pb.ChaincodeEvent, visibility []byte, ccid *pb.ChaincodeID, txsim ledger.TxSimulator, cd *chaincode.ChaincodeData) (*pb.ProposalResponse, error) {
 	endorserLogger.Infof("endorseProposal starts for chainID %s, ccid %s", chainID, ccid)
 
-	// 1) extract the chaincodeDeploymentSpec for the chaincode we are invoking; we need it to get the escc
+	// 1) extract the chaincode data for the chaincode we are invoking; we need it to get the escc
 	var escc string
 
 	//ie, not "lccc" or system chaincodes
-	if cd != nil {
-		_, err := putils.GetChaincodeDeploymentSpec(cd.DepSpec)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cds for %s - %s", ccid, err)
-		}
-
-		// FIXME: pick the right escc from cds - currently cds doesn't have this info
-		escc = "escc"
+	if cd != nil && cd.Escc != "" {
+		escc = cd.Escc
 	} else {
 		// FIXME: getCDSFromLCCC seems to fail for lccc - not sure this is expected?
 		escc = "escc"
@@ -243,6 +254,11 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 		}
 	}
 
+	resBytes, err := putils.GetBytesResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response bytes - %s", err)
+	}
+
 	// 3) call the ESCC we've identified
 	// arguments:
 	// args[0] - function name (not used now)
@@ -251,14 +267,19 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 	// args[3] - binary blob of simulation results
 	// args[4] - serialized events
 	// args[5] - payloadVisibility
-	args := [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, eventBytes, visibility}
+	// args[6] - serialized Response of the chaincode, signed along with the results
+	args := [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, eventBytes, visibility, resBytes}
 	version := util.GetSysCCVersion()
 	ecccis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: escc}, CtorMsg: &pb.ChaincodeInput{Args: args}}}
-	prBytes, _, err := e.callChaincode(ctx, chainID, version, txid, proposal, ecccis, &pb.ChaincodeID{Name: escc}, txsim)
+	res, _, err := e.callChaincode(ctx, chainID, version, txid, signedProp, proposal, ecccis, &pb.ChaincodeID{Name: escc}, txsim)
 	if err != nil {
 		return nil, err
 	}
 
+	if res.Status >= shim.ERRORTHRESHOLD {
+		return nil, fmt.Errorf("%s failed to endorse the proposal: %s", escc, res.Message)
+	}
+
 	// Note that we do not extract any simulation results from
 	// the call to ESCC. This is intentional becuse ESCC is meant
 	// to endorse (i.e. sign) the simulation results of a chaincode,
@@ -269,7 +290,7 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 	// endorsement process.
 
 	//3 -- respond
-	pResp, err := putils.GetProposalResponse(prBytes)
+	pResp, err := putils.GetProposalResponse(res.Payload)
 	if err != nil {
 		return nil, err
 	}
@@ -305,14 +326,27 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 	}
 
-	//TODO check for uniqueness of prop.TxID with ledger
-
 	txid := hdr.ChainHeader.TxID
 	if txid == "" {
 		err = fmt.Errorf("Invalid txID")
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 	}
 
+	//the txID is derived from the nonce and the creator, a proposal whose
+	//transaction is already in the ledger, or was committed before the
+	//snapshot the ledger was created from, is a replay
+	if chainID != "" {
+		lgr := peer.GetLedger(chainID)
+		if lgr == nil {
+			err = fmt.Errorf("chain does not exist(%s)", chainID)
+			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		}
+		if _, err = lgr.GetTransactionByID(txid); err == nil || err == blkstorage.ErrTxInSnapshot {
+			err = fmt.Errorf("Duplicate transaction found [%s]", txid)
+			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		}
+	}
+
 	// obtaining once the tx simulator for this proposal. This will be nil
 	// for chainless proposals
 	var txsim ledger.TxSimulator
@@ -330,36 +364,73 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	//       to validate the supplied action before endorsing it
 
 	//1 -- simulate
-	//TODO what do we do with response ? We need it for Invoke responses for sure
-	//Which field in PayloadResponse will carry return value ?
-	cd, result, simulationResult, ccevent, err := e.simulateProposal(ctx, chainID, txid, prop, hdrExt.ChaincodeID, txsim)
+	cd, res, simulationResult, ccevent, err := e.simulateProposal(ctx, chainID, txid, signedProp, prop, hdrExt.ChaincodeID, txsim)
 	if err != nil {
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 	}
 
+	//the chaincode rejected the proposal: return its response, with its
+	//status and message, to the client without endorsing it
+	if res.Status >= shim.ERRORTHRESHOLD {
+		endorserLogger.Debugf("chaincode %s rejected proposal %s with status %d: %s", hdrExt.ChaincodeID.Name, txid, res.Status, res.Message)
+		return &pb.ProposalResponse{Response: res}, nil
+	}
+
 	//2 -- endorse and get a marshalled ProposalResponse message
 	var pResp *pb.ProposalResponse
+	var hashedValues []byte
 
 	//TODO till we implement global ESCC, CSCC for system chaincodes
 	//chainless proposals (such as CSCC) don't have to be endorsed
 	if ischainless {
-		pResp = &pb.ProposalResponse{Response: &pb.Response{}}
+		pResp = &pb.ProposalResponse{}
 	} else {
-		pResp, err = e.endorseProposal(ctx, chainID, txid, prop, simulationResult, ccevent, hdrExt.PayloadVisibility, hdrExt.ChaincodeID, txsim, cd)
+		//only the hashes of the large values are endorsed, the values go along with the response
+		if simulationResult, hashedValues, err = hashLargeValues(simulationResult); err != nil {
+			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
+		}
+		pResp, err = e.endorseProposal(ctx, chainID, txid, signedProp, prop, res, simulationResult, ccevent, hdrExt.PayloadVisibility, hdrExt.ChaincodeID, txsim, cd)
 		if err != nil {
 			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 		}
+		pResp.HashedValues = hashedValues
 	}
 
-	//TODO what do we do with response ? We need it for Invoke responses for sure
-	// Set the proposal response payload - it
+	// Set the response of the chaincode - its payload
 	// contains the "return value" from the
-	// chaincode invocation
-	pResp.Response.Payload = result
+	// chaincode invocation. Endorsed proposals carry
+	// it in their signed payload as well
+	pResp.Response = res
 
 	return pResp, nil
 }
 
+// hashLargeValues replaces the values larger than the threshold configured for the
+// ledger by their hashes in the simulation results. It returns the new simulation
+// results along with the replaced values
+func hashLargeValues(simRes []byte) ([]byte, []byte, error) {
+	threshold := ledgerconfig.GetValueHashThreshold()
+	if threshold <= 0 || simRes == nil {
+		return simRes, nil, nil
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := txRWSet.Unmarshal(simRes); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal simulation results - %s", err)
+	}
+	hashedValues, err := txRWSet.HashLargeValues(threshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash large values - %s", err)
+	}
+	if hashedValues == nil {
+		return simRes, nil, nil
+	}
+	if simRes, err = txRWSet.Marshal(); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal simulation results - %s", err)
+	}
+	return simRes, hashedValues, nil
+}
+
 // Only exposed for testing purposes - commit the tx simulation so that
 // a deploy transaction is persisted and that chaincode can be invoked.
 // This makes the endorser test self-sufficient
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -69,7 +70,8 @@ func initPeer(chainID string) (net.Listener, error) {
 		return nil, fmt.Errorf("Error starting peer listener %s", err)
 	}
 
-	//initialize ledger
+	//initialize ledger with the state kept in memory
+	viper.Set("ledger.state.stateDatabase", "memory")
 	peer.MockInitialize()
 
 	getPeerEndpoint := func() (*pb.PeerEndpoint, error) {
@@ -108,8 +110,8 @@ func closeListenerAndSleep(l net.Listener) {
 //getProposal gets the proposal for the chaincode invocation
 //Currently supported only for Invokes (Queries still go through devops client)
 func getInvokeProposal(cis *pb.ChaincodeInvocationSpec, chainID string, creator []byte) (*pb.Proposal, error) {
-	uuid := util.GenerateUUID()
-	return pbutils.CreateChaincodeProposal(uuid, chainID, cis, creator)
+	prop, _, err := pbutils.CreateChaincodeProposal(chainID, cis, creator)
+	return prop, err
 }
 
 func getDeployProposal(cds *pb.ChaincodeDeploymentSpec, chainID string, creator []byte) (*pb.Proposal, error) {
@@ -251,7 +253,7 @@ func TestDeploy(t *testing.T) {
 	chainID := util.GetTestChainID()
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: "ex01", Path: "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example01"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}}}
 
-	cccid := chaincode.NewCCContext(chainID, "ex01", "0", "", false, nil)
+	cccid := chaincode.NewCCContext(chainID, "ex01", "0", "", false, nil, nil)
 
 	_, _, err := deploy(endorserServer, chainID, spec, nil)
 	if err != nil {
@@ -269,7 +271,7 @@ func TestDeployBadArgs(t *testing.T) {
 	//invalid arguments
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: "ex02", Path: "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b")}}}
 
-	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil)
+	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil, nil)
 
 	_, _, err := deploy(endorserServer, chainID, spec, nil)
 	if err == nil {
@@ -287,7 +289,7 @@ func TestDeployBadPayload(t *testing.T) {
 	//invalid arguments
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: "ex02", Path: "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}}}
 
-	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil)
+	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil, nil)
 
 	f := func(cds *pb.ChaincodeDeploymentSpec) {
 		cds.CodePackage = nil
@@ -309,7 +311,7 @@ func TestRedeploy(t *testing.T) {
 	//invalid arguments
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: &pb.ChaincodeID{Name: "ex02", Path: "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}}}
 
-	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil)
+	cccid := chaincode.NewCCContext(chainID, "ex02", "0", "", false, nil, nil)
 
 	_, _, err := deploy(endorserServer, chainID, spec, nil)
 	if err != nil {
@@ -344,7 +346,7 @@ func TestDeployAndInvoke(t *testing.T) {
 	argsDeploy := util.ToChaincodeArgs(f, "a", "100", "b", "200")
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: chaincodeID, CtorMsg: &pb.ChaincodeInput{Args: argsDeploy}}
 
-	cccid := chaincode.NewCCContext(chainID, "ex01", "0", "", false, nil)
+	cccid := chaincode.NewCCContext(chainID, "ex01", "0", "", false, nil, nil)
 
 	resp, prop, err := deploy(endorserServer, chainID, spec, nil)
 	chaincodeID1 := spec.ChaincodeID.Name
@@ -394,8 +396,8 @@ func TestDeployAndUpgrade(t *testing.T) {
 	argsDeploy := util.ToChaincodeArgs(f, "a", "100", "b", "200")
 	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeID: chaincodeID1, CtorMsg: &pb.ChaincodeInput{Args: argsDeploy}}
 
-	cccid1 := chaincode.NewCCContext(chainID, "upgradeex01", "0", "", false, nil)
-	cccid2 := chaincode.NewCCContext(chainID, "upgradeex01", "1", "", false, nil)
+	cccid1 := chaincode.NewCCContext(chainID, "upgradeex01", "0", "", false, nil, nil)
+	cccid2 := chaincode.NewCCContext(chainID, "upgradeex01", "1", "", false, nil, nil)
 
 	resp, prop, err := deploy(endorserServer, chainID, spec, nil)
 
@@ -435,6 +437,82 @@ func TestDeployAndUpgrade(t *testing.T) {
 	chaincode.GetChain().Stop(ctxt, cccid2, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeID: chaincodeID2}})
 }
 
+// getChaincodesProposal returns a signed proposal which lists the chaincodes
+// of the chain, it is endorsed by the system chaincodes only
+func getChaincodesProposal(chainID string) (*pb.Proposal, *pb.SignedProposal, error) {
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: "lccc"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("getchaincodes")}}}}
+	prop, err := getInvokeProposal(cis, chainID, creator)
+	if err != nil {
+		return nil, nil, err
+	}
+	signedProp, err := getSignedProposal(prop, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prop, signedProp, nil
+}
+
+// TestDuplicateProposal submits again a proposal whose transaction was committed
+func TestDuplicateProposal(t *testing.T) {
+	chainID := util.GetTestChainID()
+
+	prop, signedProp, err := getChaincodesProposal(chainID)
+	if err != nil {
+		t.Fatalf("Error creating proposal: %s", err)
+	}
+
+	resp, err := endorserServer.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		t.Fatalf("Error endorsing the proposal: %s", err)
+	}
+	if err = endorserServer.(*Endorser).commitTxSimulation(prop, chainID, signer, resp); err != nil {
+		t.Fatalf("Error committing the transaction: %s", err)
+	}
+
+	resp, err = endorserServer.ProcessProposal(context.Background(), signedProp)
+	if err == nil {
+		t.Fatalf("A proposal whose transaction is in the ledger should be rejected")
+	}
+	if resp == nil || resp.Response.Status != 500 || !strings.Contains(resp.Response.Message, "Duplicate transaction") {
+		t.Fatalf("Expected a duplicate transaction error, got %v", resp)
+	}
+}
+
+// TestProposalWithForgedTxID submits a proposal whose txID is not derived
+// from its nonce and creator
+func TestProposalWithForgedTxID(t *testing.T) {
+	chainID := util.GetTestChainID()
+
+	prop, _, err := getChaincodesProposal(chainID)
+	if err != nil {
+		t.Fatalf("Error creating proposal: %s", err)
+	}
+	hdr, err := pbutils.GetHeader(prop.Header)
+	if err != nil {
+		t.Fatalf("Error reading the proposal header: %s", err)
+	}
+	hdr.ChainHeader.TxID, err = pbutils.ComputeProposalTxID([]byte("another nonce"), hdr.SignatureHeader.Creator)
+	if err != nil {
+		t.Fatalf("Error computing the txID: %s", err)
+	}
+	if prop.Header, err = proto.Marshal(hdr); err != nil {
+		t.Fatalf("Error marshalling the proposal header: %s", err)
+	}
+	signedProp, err := getSignedProposal(prop, signer)
+	if err != nil {
+		t.Fatalf("Error signing the proposal: %s", err)
+	}
+
+	_, err = endorserServer.ProcessProposal(context.Background(), signedProp)
+	if err == nil || !strings.Contains(err.Error(), "Invalid txID") {
+		t.Fatalf("A proposal whose txID does not match its nonce and creator should be rejected, got %v", err)
+	}
+}
+
 func TestMain(m *testing.M) {
 	SetupTestConfig()
 	viper.Set("peer.fileSystemPath", filepath.Join(os.TempDir(), "hyperledger", "production"))
@@ -452,7 +530,7 @@ func TestMain(m *testing.M) {
 
 	// setup the MSP manager so that we can sign/verify
 	mspMgrConfigDir := "../../msp/sampleconfig/"
-	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 	if err != nil {
 		fmt.Printf("Could not initialize msp/signer, err %s", err)
 		os.Exit(-1)
@@ -46,6 +46,10 @@ var (
 	ErrNotFoundInIndex = errors.New("Entry not found in index")
 	// ErrAttrNotIndexed is used to indicate that an attribute is not indexed
 	ErrAttrNotIndexed = errors.New("Attribute not indexed")
+	// ErrTxInSnapshot is used to indicate that a transaction was committed
+	// before the snapshot the block store was bootstrapped from, so only its
+	// ID is known
+	ErrTxInSnapshot = errors.New("Transaction committed before the snapshot the block store was bootstrapped from")
 )
 
 // BlockStore - an interface for persisting and retrieving blocks
@@ -58,5 +62,10 @@ type BlockStore interface {
 	RetrieveBlockByHash(blockHash []byte) (*common.Block, error)
 	RetrieveBlockByNumber(blockNum uint64) (*common.Block, error) // blockNum of  math.MaxUint64 will return last block
 	RetrieveTxByID(txID string) (*pb.Transaction, error)
+	RetrieveTxIDs() ([]string, error)
+	// BootstrapFromSnapshot starts an empty block store at the height of
+	// lastBlock. configBlock, if not nil, is kept so that it can be retrieved
+	// by its number and txIDs are indexed so that duplicates can be detected
+	BootstrapFromSnapshot(lastBlock *common.Block, configBlock *common.Block, txIDs []string) error
 	Shutdown()
 }
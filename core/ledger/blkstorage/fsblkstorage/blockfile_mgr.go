@@ -40,7 +40,8 @@ const (
 )
 
 var (
-	blkMgrInfoKey = []byte("blkMgrInfo")
+	blkMgrInfoKey          = []byte("blkMgrInfo")
+	snapshotConfigBlockKey = []byte("snapshotConfigBlock")
 )
 
 type blockfileMgr struct {
@@ -317,6 +318,63 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	return nil
 }
 
+// bootstrapFromSnapshot starts an empty block store at the height of the
+// block a snapshot was taken at. The blocks before it are not stored, except
+// for the config block which is kept apart from the block files. The IDs of
+// the transactions they hold are indexed so that duplicates can be detected
+func (mgr *blockfileMgr) bootstrapFromSnapshot(lastBlock *common.Block, configBlock *common.Block, txIDs []string) error {
+	if mgr.getBlockchainInfo().Height != 0 {
+		return fmt.Errorf("Cannot bootstrap a block store which already holds blocks")
+	}
+	if configBlock != nil {
+		if configBlock.Header.Number >= lastBlock.Header.Number {
+			return fmt.Errorf("Config block [%d] must precede the last block [%d] of the snapshot",
+				configBlock.Header.Number, lastBlock.Header.Number)
+		}
+		configBlockBytes, err := proto.Marshal(configBlock)
+		if err != nil {
+			return fmt.Errorf("Error while serializing config block: %s", err)
+		}
+		if err = mgr.db.Put(snapshotConfigBlockKey, configBlockBytes, true); err != nil {
+			return fmt.Errorf("Error while saving config block to db: %s", err)
+		}
+	}
+	if err := mgr.index.indexSnapshotTxIDs(txIDs); err != nil {
+		return fmt.Errorf("Error while indexing the transactions of the snapshot: %s", err)
+	}
+	if err := mgr.addBlock(lastBlock); err != nil {
+		return err
+	}
+	// the height of a block store is the number of its last block, the same
+	// as it is set to on a restart
+	bcInfo := mgr.getBlockchainInfo()
+	mgr.bcInfo.Store(&pb.BlockchainInfo{
+		Height:            lastBlock.Header.Number,
+		CurrentBlockHash:  bcInfo.CurrentBlockHash,
+		PreviousBlockHash: bcInfo.PreviousBlockHash})
+	return nil
+}
+
+// retrieveSnapshotConfigBlock returns the config block kept by
+// bootstrapFromSnapshot if it has the given number
+func (mgr *blockfileMgr) retrieveSnapshotConfigBlock(blockNum uint64) (*common.Block, error) {
+	b, err := mgr.db.Get(snapshotConfigBlockKey)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, blkstorage.ErrNotFoundInIndex
+	}
+	block := &common.Block{}
+	if err = proto.Unmarshal(b, block); err != nil {
+		return nil, err
+	}
+	if block.Header.Number != blockNum {
+		return nil, blkstorage.ErrNotFoundInIndex
+	}
+	return block, nil
+}
+
 func (mgr *blockfileMgr) syncIndex() error {
 	var lastBlockIndexed uint64
 	var err error
@@ -362,8 +420,9 @@ func (mgr *blockfileMgr) syncIndex() error {
 		if err != nil {
 			return err
 		}
+		//The tx offsets are relative to the block bytes, which follow the length of the block
 		for _, offset := range info.txOffsets {
-			offset.loc.offset += int(blockPlacementInfo.blockBytesOffset)
+			offset.loc.offset += int(blockPlacementInfo.blockBytesOffset - blockPlacementInfo.blockStartOffset)
 		}
 		//Update the blockIndexInfo with what was actually stored in file system
 		blockIdxInfo := &blockIdxInfo{}
@@ -420,6 +479,9 @@ func (mgr *blockfileMgr) retrieveBlockByNumber(blockNum uint64) (*common.Block,
 	}
 
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
+	if err == blkstorage.ErrNotFoundInIndex {
+		return mgr.retrieveSnapshotConfigBlock(blockNum)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -456,6 +518,10 @@ func (mgr *blockfileMgr) retrieveTransactionByID(txID string) (*pb.Transaction,
 	return mgr.fetchTransaction(loc)
 }
 
+func (mgr *blockfileMgr) retrieveTxIDs() ([]string, error) {
+	return mgr.index.getTxIDs()
+}
+
 func (mgr *blockfileMgr) retrieveTransactionForBlockNumTranNum(blockNum uint64, tranNum uint64) (*pb.Transaction, error) {
 	logger.Debugf("retrieveTransactionForBlockNumTranNum() - blockNum = [%d], tranNum = [%d]", blockNum, tranNum)
 	loc, err := mgr.index.getTXLocForBlockNumTranNum(blockNum, tranNum)
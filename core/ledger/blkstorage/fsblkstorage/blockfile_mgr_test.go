@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/blkstorage"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 
 	"github.com/hyperledger/fabric/protos/common"
@@ -197,3 +198,45 @@ func TestBlockfileMgrFileRolling(t *testing.T) {
 	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileChunkSuffixNum, 2)
 	blkfileMgrWrapper.testGetBlockByHash(blocks)
 }
+
+func TestBlockfileMgrBootstrapFromSnapshot(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	var txIDs []string
+	for _, blk := range blocks[:5] {
+		for _, txEnvelopeBytes := range blk.Data.Data {
+			txID, err := extractTxID(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "")
+			txIDs = append(txIDs, txID)
+		}
+	}
+
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	err := blkfileMgrWrapper.blockfileMgr.bootstrapFromSnapshot(blocks[4], blocks[1], txIDs)
+	testutil.AssertNoError(t, err, "Error while bootstrapping blkfileMgr")
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height, uint64(5))
+	err = blkfileMgrWrapper.blockfileMgr.bootstrapFromSnapshot(blocks[4], nil, nil)
+	testutil.AssertError(t, err, "Bootstrapping a block store holding blocks should fail")
+	blkfileMgrWrapper.addBlocks(blocks[5:])
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(t, env)
+	defer blkfileMgrWrapper.close()
+	mgr := blkfileMgrWrapper.blockfileMgr
+	testutil.AssertEquals(t, mgr.getBlockchainInfo().Height, uint64(10))
+	blkfileMgrWrapper.testGetBlockByNumber(blocks[4:], 5)
+	configBlock, err := mgr.retrieveBlockByNumber(2)
+	testutil.AssertNoError(t, err, "Error while retrieving the config block of the snapshot")
+	testutil.AssertEquals(t, configBlock, blocks[1])
+	_, err = mgr.retrieveBlockByNumber(1)
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+
+	_, err = mgr.retrieveTransactionByID(txIDs[0])
+	testutil.AssertEquals(t, err, blkstorage.ErrTxInSnapshot)
+	_, err = mgr.retrieveTransactionByID(txIDs[len(txIDs)-1])
+	testutil.AssertNoError(t, err, "Error while retrieving a tx of the last block of the snapshot")
+	allTxIDs, err := mgr.retrieveTxIDs()
+	testutil.AssertNoError(t, err, "Error while retrieving tx IDs")
+	testutil.AssertEquals(t, len(allTxIDs), 100)
+}
@@ -43,6 +43,8 @@ type index interface {
 	getBlockLocByBlockNum(blockNum uint64) (*fileLocPointer, error)
 	getTxLoc(txID string) (*fileLocPointer, error)
 	getTXLocForBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error)
+	indexSnapshotTxIDs(txIDs []string) error
+	getTxIDs() ([]string, error)
 }
 
 type blockIdxInfo struct {
@@ -177,11 +179,43 @@ func (index *blockIndex) getTxLoc(txID string) (*fileLocPointer, error) {
 	if b == nil {
 		return nil, blkstorage.ErrNotFoundInIndex
 	}
+	// transactions imported from a snapshot are indexed without a location
+	if len(b) == 0 {
+		return nil, blkstorage.ErrTxInSnapshot
+	}
 	txFLP := &fileLocPointer{}
 	txFLP.unmarshal(b)
 	return txFLP, nil
 }
 
+// indexSnapshotTxIDs indexes the IDs of the transactions committed before the
+// snapshot the block store is bootstrapped from. Their blocks are not in the
+// block files, so they are indexed without a location
+func (index *blockIndex) indexSnapshotTxIDs(txIDs []string) error {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; !ok {
+		return nil
+	}
+	batch := &leveldb.Batch{}
+	for _, txID := range txIDs {
+		batch.Put(constructTxIDKey(txID), []byte{})
+	}
+	return index.db.WriteBatch(batch, true)
+}
+
+// getTxIDs returns the IDs of all the indexed transactions
+func (index *blockIndex) getTxIDs() ([]string, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; !ok {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	itr := index.db.GetIterator([]byte{txIDIdxKeyPrefix}, []byte{txIDIdxKeyPrefix + 1})
+	defer itr.Release()
+	var txIDs []string
+	for itr.Next() {
+		txIDs = append(txIDs, string(itr.Key()[1:]))
+	}
+	return txIDs, itr.Error()
+}
+
 func (index *blockIndex) getTXLocForBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error) {
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNumTranNum]; !ok {
 		return nil, blkstorage.ErrAttrNotIndexed
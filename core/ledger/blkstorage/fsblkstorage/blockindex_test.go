@@ -45,6 +45,12 @@ func (i *noopIndex) getTxLoc(txID string) (*fileLocPointer, error) {
 func (i *noopIndex) getTXLocForBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error) {
 	return nil, nil
 }
+func (i *noopIndex) indexSnapshotTxIDs(txIDs []string) error {
+	return nil
+}
+func (i *noopIndex) getTxIDs() ([]string, error) {
+	return nil, nil
+}
 
 func TestBlockIndexSync(t *testing.T) {
 	testBlockIndexSync(t, 10, 5, false)
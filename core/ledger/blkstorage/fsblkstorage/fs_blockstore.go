@@ -69,6 +69,17 @@ func (store *FsBlockStore) RetrieveTxByID(txID string) (*pb.Transaction, error)
 	return store.fileMgr.retrieveTransactionByID(txID)
 }
 
+// RetrieveTxIDs returns the IDs of all the transactions in the block store
+func (store *FsBlockStore) RetrieveTxIDs() ([]string, error) {
+	return store.fileMgr.retrieveTxIDs()
+}
+
+// BootstrapFromSnapshot starts an empty block store at the height of the
+// block a snapshot was taken at
+func (store *FsBlockStore) BootstrapFromSnapshot(lastBlock *common.Block, configBlock *common.Block, txIDs []string) error {
+	return store.fileMgr.bootstrapFromSnapshot(lastBlock, configBlock, txIDs)
+}
+
 // Shutdown shuts down the block store
 func (store *FsBlockStore) Shutdown() {
 	store.fileMgr.close()
@@ -0,0 +1,314 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/core/ledger/blkstorage"
+	"github.com/hyperledger/fabric/core/ledger/util"
+)
+
+// VerificationReport holds the outcome of verifying a block store
+type VerificationReport struct {
+	// NumBlocks is the number of complete blocks found in the block files
+	NumBlocks uint64
+	// FirstBlockNumber and LastBlockNumber are the numbers of the first and the
+	// last of these blocks. The first block is not the genesis block for a
+	// block store bootstrapped from a snapshot
+	FirstBlockNumber uint64
+	LastBlockNumber  uint64
+	// Problems describes each inconsistency found
+	Problems []string
+	// IndexRebuilt tells whether the index was rebuilt from the block files
+	IndexRebuilt bool
+	// BlockfilesRepaired tells whether the block files were truncated after
+	// the last complete block and the checkpoint rewritten accordingly
+	BlockfilesRepaired bool
+}
+
+func (r *VerificationReport) addProblem(format string, args ...interface{}) {
+	problem := fmt.Sprintf(format, args...)
+	logger.Warning(problem)
+	r.Problems = append(r.Problems, problem)
+}
+
+// Verify checks a block store which is not in use. It walks the block files
+// and verifies that each block follows the previous one and that the hashes
+// recorded in the headers match the blocks. It cross-checks the index and the
+// checkpoint against the blocks found. If repair is set, a partially written
+// block at the end of the block files is truncated, the checkpoint is
+// rewritten to match the last complete block and the index is rebuilt from
+// the block files if it is inconsistent
+func Verify(conf *Conf, indexConfig *blkstorage.IndexConfig, repair bool) (*VerificationReport, error) {
+	dbInst := initDB(conf)
+	defer dbInst.Close()
+	index := newBlockIndex(indexConfig, dbInst)
+	mgr := &blockfileMgr{rootDir: conf.blockfilesDir, conf: conf, db: dbInst, index: index}
+	report := &VerificationReport{}
+
+	cpInfo, err := mgr.loadCurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	lastFileNum := -1
+	for {
+		exists, _, err := util.FileExists(deriveBlockfilePath(conf.blockfilesDir, lastFileNum+1))
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			break
+		}
+		lastFileNum++
+	}
+	var blocks []*blockIdxInfo
+	// end is where the last complete block ends, which the checkpoint records
+	end := &checkpointInfo{}
+	if lastFileNum >= 0 {
+		if blocks, end, err = walkBlockfiles(conf.blockfilesDir, lastFileNum, report); err != nil {
+			return nil, err
+		}
+	}
+	if cpInfo != nil && cpInfo.lastBlockNumber != report.LastBlockNumber {
+		report.addProblem("Checkpoint records last block [%d] but the block files end at block [%d]",
+			cpInfo.lastBlockNumber, report.LastBlockNumber)
+	}
+
+	if repair && end != nil {
+		if err = repairBlockfiles(mgr, lastFileNum, cpInfo, end, report); err != nil {
+			return nil, err
+		}
+	}
+
+	numProblems := len(report.Problems)
+	if err = verifyIndex(index, blocks, report); err != nil {
+		return nil, err
+	}
+	if repair && len(report.Problems) > numProblems {
+		logger.Infof("Rebuilding the index of [%d] blocks", len(blocks))
+		for _, blockIdxInfo := range blocks {
+			if err = index.indexBlock(blockIdxInfo); err != nil {
+				return nil, err
+			}
+		}
+		report.IndexRebuilt = true
+	}
+	return report, nil
+}
+
+// repairBlockfiles removes what follows the last complete block from the block
+// files, which is a block partially written by a crash, and rewrites the
+// checkpoint if it does not record the last complete block
+func repairBlockfiles(mgr *blockfileMgr, lastFileNum int, cpInfo *checkpointInfo, end *checkpointInfo,
+	report *VerificationReport) error {
+	// the block files following the one of the last complete block are only
+	// expected to be empty files created before a crash
+	for fileNum := end.latestFileChunkSuffixNum + 1; fileNum <= lastFileNum; fileNum++ {
+		_, size, err := util.FileExists(deriveBlockfilePath(mgr.rootDir, fileNum))
+		if err != nil {
+			return err
+		}
+		if size > 0 {
+			report.addProblem("Block files cannot be repaired as block file [%d] follows a partially written block", fileNum)
+			return nil
+		}
+	}
+	for fileNum := lastFileNum; fileNum >= end.latestFileChunkSuffixNum; fileNum-- {
+		filePath := deriveBlockfilePath(mgr.rootDir, fileNum)
+		if fileNum > end.latestFileChunkSuffixNum {
+			logger.Infof("Removing empty block file [%d]", fileNum)
+			if err := os.Remove(filePath); err != nil {
+				return err
+			}
+			report.BlockfilesRepaired = true
+			continue
+		}
+		_, size, err := util.FileExists(filePath)
+		if err != nil {
+			return err
+		}
+		if size > int64(end.latestFileChunksize) {
+			logger.Infof("Truncating block file [%d] from [%d] to [%d] bytes", fileNum, size, end.latestFileChunksize)
+			if err = os.Truncate(filePath, int64(end.latestFileChunksize)); err != nil {
+				return err
+			}
+			report.BlockfilesRepaired = true
+		}
+	}
+	if cpInfo == nil || *cpInfo != *end {
+		logger.Infof("Rewriting checkpoint [%s] as [%s]", cpInfo, end)
+		if err := mgr.saveCurrentInfo(end, true); err != nil {
+			return err
+		}
+		report.BlockfilesRepaired = true
+	}
+	return nil
+}
+
+// walkBlockfiles reads all the blocks of the block files and verifies the
+// chain of hashes. It returns the index entries of the blocks and the
+// checkpoint matching the end of the last complete block, which is nil when
+// a block cannot be read
+func walkBlockfiles(rootDir string, lastFileNum int, report *VerificationReport) ([]*blockIdxInfo, *checkpointInfo, error) {
+	stream, err := newBlockStream(rootDir, 0, 0, lastFileNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stream.close()
+
+	var blocks []*blockIdxInfo
+	var previousHash []byte
+	for {
+		blockBytes, placementInfo, err := stream.nextBlockBytesAndPlacementInfo()
+		if err == ErrUnexpectedEndOfBlockfile {
+			report.addProblem("Partially written block at the end of block file [%d]", stream.currentFileNum)
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if blockBytes == nil {
+			break
+		}
+		block, err := deserializeBlock(blockBytes)
+		if err != nil {
+			report.addProblem("Cannot read the block at offset [%d] of block file [%d]: %s",
+				placementInfo.blockStartOffset, placementInfo.fileNum, err)
+			return blocks, nil, nil
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		blockNum := block.Header.Number
+		if report.NumBlocks == 0 {
+			report.FirstBlockNumber = blockNum
+		} else {
+			if blockNum != report.LastBlockNumber+1 {
+				report.addProblem("Block [%d] follows block [%d]", blockNum, report.LastBlockNumber)
+			}
+			if !bytes.Equal(block.Header.PreviousHash, previousHash) {
+				report.addProblem("Previous hash of block [%d] does not match the hash of block [%d]",
+					blockNum, report.LastBlockNumber)
+			}
+		}
+		if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+			report.addProblem("Data hash of block [%d] does not match its data", blockNum)
+		}
+		report.NumBlocks++
+		report.LastBlockNumber = blockNum
+		previousHash = block.Header.Hash()
+
+		// the tx offsets are relative to the block bytes, which follow the
+		// length of the block
+		for _, txOffset := range info.txOffsets {
+			txOffset.loc.offset += int(placementInfo.blockBytesOffset - placementInfo.blockStartOffset)
+		}
+		blocks = append(blocks, &blockIdxInfo{
+			blockNum:  blockNum,
+			blockHash: previousHash,
+			flp: &fileLocPointer{fileSuffixNum: placementInfo.fileNum,
+				locPointer: locPointer{offset: int(placementInfo.blockStartOffset)}},
+			txOffsets: info.txOffsets})
+	}
+	end := &checkpointInfo{latestFileChunkSuffixNum: stream.currentFileNum,
+		latestFileChunksize: int(stream.currentFileStream.currentOffset), lastBlockNumber: report.LastBlockNumber}
+	return blocks, end, nil
+}
+
+// verifyIndex checks that the index holds the expected location of each block
+// and transaction
+func verifyIndex(index *blockIndex, blocks []*blockIdxInfo, report *VerificationReport) error {
+	check := func(attr blkstorage.IndexableAttr, what string, expected *fileLocPointer,
+		get func() (*fileLocPointer, error)) error {
+		if _, ok := index.indexItemsMap[attr]; !ok {
+			return nil
+		}
+		flp, err := get()
+		if err == blkstorage.ErrNotFoundInIndex {
+			report.addProblem("%s is missing from the %s index", what, attr)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if flp.fileSuffixNum != expected.fileSuffixNum || flp.offset != expected.offset {
+			report.addProblem("%s is indexed by %s at [%s] instead of [%s]", what, attr, flp, expected)
+		}
+		return nil
+	}
+
+	// a transaction ID is indexed at its last occurrence
+	txLocs := make(map[string]*fileLocPointer)
+	var txIDs []string
+	for _, blockIdxInfo := range blocks {
+		blockNum := blockIdxInfo.blockNum
+		what := fmt.Sprintf("Block [%d]", blockNum)
+		if err := check(blkstorage.IndexableAttrBlockNum, what, blockIdxInfo.flp, func() (*fileLocPointer, error) {
+			return index.getBlockLocByBlockNum(blockNum)
+		}); err != nil {
+			return err
+		}
+		if err := check(blkstorage.IndexableAttrBlockHash, what, blockIdxInfo.flp, func() (*fileLocPointer, error) {
+			return index.getBlockLocByHash(blockIdxInfo.blockHash)
+		}); err != nil {
+			return err
+		}
+		for i, txOffset := range blockIdxInfo.txOffsets {
+			txFlp := newFileLocationPointer(blockIdxInfo.flp.fileSuffixNum, blockIdxInfo.flp.offset, txOffset.loc)
+			tranNum := uint64(i + 1)
+			what := fmt.Sprintf("Transaction [%d] of block [%d]", tranNum, blockNum)
+			if err := check(blkstorage.IndexableAttrBlockNumTranNum, what, txFlp, func() (*fileLocPointer, error) {
+				return index.getTXLocForBlockNumTranNum(blockNum, tranNum)
+			}); err != nil {
+				return err
+			}
+			if txOffset.txID == "" {
+				continue
+			}
+			if _, ok := txLocs[txOffset.txID]; !ok {
+				txIDs = append(txIDs, txOffset.txID)
+			}
+			txLocs[txOffset.txID] = txFlp
+		}
+	}
+	for _, txID := range txIDs {
+		what := fmt.Sprintf("Transaction [%s]", txID)
+		if err := check(blkstorage.IndexableAttrTxID, what, txLocs[txID], func() (*fileLocPointer, error) {
+			return index.getTxLoc(txID)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(blocks) > 0 && len(index.indexItemsMap) > 0 {
+		lastBlockIndexed, err := index.getLastBlockIndexed()
+		if err != nil {
+			return err
+		}
+		if lastBlock := blocks[len(blocks)-1].blockNum; lastBlockIndexed != lastBlock {
+			report.addProblem("Index records last block [%d] but the block files end at block [%d]",
+				lastBlockIndexed, lastBlock)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestVerify(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blkfileMgrWrapper.addBlocks(blocks)
+	blkfileMgrWrapper.close()
+
+	report, err := Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+	testutil.AssertEquals(t, report.NumBlocks, uint64(10))
+	testutil.AssertEquals(t, report.FirstBlockNumber, uint64(1))
+	testutil.AssertEquals(t, report.LastBlockNumber, uint64(10))
+}
+
+func TestVerifyRebuildsIndex(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blkfileMgrWrapper.addBlocks(blocks)
+	txID, err := extractTxID(blocks[4].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	db := blkfileMgrWrapper.blockfileMgr.db
+	db.Delete(constructBlockNumKey(3), true)
+	db.Delete(constructBlockHashKey(blocks[5].Header.Hash()), true)
+	db.Put(constructTxIDKey(txID), constructBlockNumKey(2), true)
+	blkfileMgrWrapper.close()
+
+	report, err := Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, len(report.Problems), 3)
+	testutil.AssertEquals(t, report.IndexRebuilt, false)
+
+	report, err = Verify(env.conf, env.indexConfig, true)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, len(report.Problems), 3)
+	testutil.AssertEquals(t, report.IndexRebuilt, true)
+
+	report, err = Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(t, env)
+	defer blkfileMgrWrapper.close()
+	blkfileMgrWrapper.testGetBlockByNumber(blocks, 1)
+	blkfileMgrWrapper.testGetBlockByHash(blocks)
+}
+
+func TestVerifyDetectsBrokenChain(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blocks[4].Header.PreviousHash = []byte("previousHash")
+	blocks[6].Data.Data[0] = blocks[7].Data.Data[0]
+	blkfileMgrWrapper.addBlocks(blocks)
+	blkfileMgrWrapper.close()
+
+	report, err := Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	// block 5 does not follow block 4 and block 6 does not follow block 5 as
+	// its header changed
+	testutil.AssertEquals(t, len(report.Problems), 3)
+	testutil.AssertEquals(t, report.NumBlocks, uint64(10))
+}
+
+func TestVerifyAfterIndexSync(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	origIndex := blkfileMgr.index
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blkfileMgrWrapper.addBlocks(blocks[:5])
+	blkfileMgr.index = &noopIndex{}
+	blkfileMgrWrapper.addBlocks(blocks[5:])
+	blkfileMgr.index = origIndex
+	blkfileMgrWrapper.close()
+
+	// the index is synced from the block files on restart
+	blkfileMgrWrapper = newTestBlockfileWrapper(t, env)
+	blkfileMgrWrapper.close()
+
+	report, err := Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+}
+
+func TestVerifyRepairsPartialBlock(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blkfileMgrWrapper.addBlocks(blocks[:9])
+	blkfileMgrWrapper.close()
+	blockfilePath := deriveBlockfilePath(env.conf.blockfilesDir, 0)
+	fileInfo, err := os.Stat(blockfilePath)
+	testutil.AssertNoError(t, err, "")
+	sizeOf9Blocks := fileInfo.Size()
+
+	// the last block is only partially on disk while the checkpoint records it
+	blkfileMgrWrapper = newTestBlockfileWrapper(t, env)
+	blkfileMgrWrapper.addBlocks(blocks[9:])
+	blkfileMgrWrapper.close()
+	testutil.AssertNoError(t, os.Truncate(blockfilePath, sizeOf9Blocks+5), "")
+
+	report, err := Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	// the partial block, the checkpoint and the last block of the index
+	testutil.AssertEquals(t, len(report.Problems), 3)
+	testutil.AssertEquals(t, report.LastBlockNumber, uint64(9))
+	testutil.AssertEquals(t, report.BlockfilesRepaired, false)
+
+	report, err = Verify(env.conf, env.indexConfig, true)
+	testutil.AssertNoError(t, err, "Error while repairing block store")
+	testutil.AssertEquals(t, report.BlockfilesRepaired, true)
+	testutil.AssertEquals(t, report.IndexRebuilt, true)
+	fileInfo, err = os.Stat(blockfilePath)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, fileInfo.Size(), sizeOf9Blocks)
+
+	report, err = Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+
+	// the block store resumes after the last complete block
+	blkfileMgrWrapper = newTestBlockfileWrapper(t, env)
+	defer blkfileMgrWrapper.close()
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height, uint64(9))
+	blkfileMgrWrapper.addBlocks(blocks[9:])
+	blkfileMgrWrapper.testGetBlockByNumber(blocks, 1)
+	blkfileMgrWrapper.testGetBlockByHash(blocks)
+}
+
+func TestVerifyRewritesCheckpoint(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(t, env)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blkfileMgrWrapper.addBlocks(blocks)
+	// the checkpoint of the last block was not flushed before a crash
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	cpInfo := *blkfileMgr.cpInfo
+	cpInfo.lastBlockNumber = 8
+	testutil.AssertNoError(t, blkfileMgr.saveCurrentInfo(&cpInfo, true), "")
+	blkfileMgrWrapper.close()
+
+	report, err := Verify(env.conf, env.indexConfig, true)
+	testutil.AssertNoError(t, err, "Error while repairing block store")
+	testutil.AssertEquals(t, len(report.Problems), 1)
+	testutil.AssertEquals(t, report.BlockfilesRepaired, true)
+
+	report, err = Verify(env.conf, env.indexConfig, false)
+	testutil.AssertNoError(t, err, "Error while verifying block store")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+}
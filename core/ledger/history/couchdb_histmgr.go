@@ -18,6 +18,8 @@ package history
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/hyperledger/fabric/core/ledger"
@@ -32,6 +34,32 @@ var logger = logging.MustGetLogger("history")
 
 var compositeKeySep = []byte{0x00}
 
+// Savepoint docid (key) for the history database
+const savepointDocID = "histdb_savepoint"
+
+// GetDBName returns the name of the CouchDB database holding the history of a ledger.
+// Each ledger has its own database, so that the savepoints and the histories of keys
+// of the ledgers are kept apart. CouchDB only allows lowercase letters, digits and
+// _$()+-/ in database names, so the other characters of the ledger id are escaped
+// as '$' followed by their hexadecimal code
+func GetDBName(ledgerID string) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("history_")
+	for _, c := range []byte(ledgerID) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '-' {
+			buffer.WriteByte(c)
+		} else {
+			buffer.WriteString(fmt.Sprintf("$%02x", c))
+		}
+	}
+	return buffer.String()
+}
+
+// Savepoint data for the history database
+type couchSavepointData struct {
+	BlockNum uint64 `json:"BlockNum"`
+}
+
 // CouchDBHistMgr a simple implementation of interface `histmgmt.HistMgr'.
 // TODO This implementation does not currently use a lock but may need one to ensure query's are consistent
 type CouchDBHistMgr struct {
@@ -75,7 +103,7 @@ func (histmgr *CouchDBHistMgr) Commit(block *common.Block) error {
 		logger.Debugf("===HISTORYDB=== Updating history for tranNo: %v", tranNo)
 
 		// extract actions from the envelope message
-		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		respPayload, hashedValues, err := putils.GetActionAndHashedValuesFromEnvelope(envBytes)
 		if err != nil {
 			return err
 		}
@@ -89,6 +117,13 @@ func (histmgr *CouchDBHistMgr) Commit(block *common.Block) error {
 			return err
 		}
 
+		// put back the values that were replaced by their hashes. The validator
+		// marks the transactions for which this fails as invalid
+		if err = txRWSet.ResolveHashedValues(hashedValues); err != nil {
+			logger.Debugf("===HISTORYDB=== Skipping tranNo: %v: %s", tranNo, err)
+			continue
+		}
+
 		//Transactions that have data that is not JSON such as binary data,
 		// the write value will not write to history database.
 		//These types of transactions will have the key written to the history
@@ -130,9 +165,51 @@ func (histmgr *CouchDBHistMgr) Commit(block *common.Block) error {
 		}
 
 	}
+
+	// the savepoint is recorded last so that a block whose history is partially
+	// written is committed again when the ledger is opened
+	return histmgr.recordSavepoint(blockNo)
+}
+
+// recordSavepoint records the number of the last block committed to the history database
+func (histmgr *CouchDBHistMgr) recordSavepoint(blockNum uint64) error {
+	savepointDocJSON, err := json.Marshal(&couchSavepointData{BlockNum: blockNum})
+	if err != nil {
+		return err
+	}
+	if _, err = histmgr.couchDB.SaveDoc(savepointDocID, "", savepointDocJSON, nil); err != nil {
+		logger.Errorf("===HISTORYDB=== Failed to save the savepoint to DB %s\n", err.Error())
+		return err
+	}
 	return nil
 }
 
+// Clear implements method in interface `histmgmt.HistMgr`
+// The history database is dropped and created again
+func (histmgr *CouchDBHistMgr) Clear() error {
+	if _, err := histmgr.couchDB.DropDatabase(); err != nil {
+		return err
+	}
+	_, err := histmgr.couchDB.CreateDatabaseIfNotExist()
+	return err
+}
+
+// GetBlockNumFromSavepoint implements method in interface `histmgmt.HistMgr`
+func (histmgr *CouchDBHistMgr) GetBlockNumFromSavepoint() (uint64, error) {
+	docs, err := histmgr.couchDB.BatchRetrieveDocuments([]string{savepointDocID})
+	if err != nil {
+		return 0, err
+	}
+	if docs[0] == nil {
+		return 0, nil
+	}
+	savepointDoc := &couchSavepointData{}
+	if err = json.Unmarshal(docs[0].Value, savepointDoc); err != nil {
+		return 0, err
+	}
+	return savepointDoc.BlockNum, nil
+}
+
 //getTransactionsForNsKey contructs composite start and end keys based on the namespace and key then calls the CouchDB range scanner
 func (histmgr *CouchDBHistMgr) getTransactionsForNsKey(namespace string, key string, includeValues bool) (*histScanner, error) {
 	var compositeStartKey []byte
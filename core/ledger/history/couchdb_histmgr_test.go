@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
 )
 
 /*
@@ -96,3 +97,42 @@ func TestHistoryDatabaseCommit(t *testing.T) {
 
 	}
 }
+
+func TestGetDBName(t *testing.T) {
+	testutil.AssertEquals(t, GetDBName("mychain"), "history_mychain")
+	testutil.AssertEquals(t, GetDBName("my-chain_1"), "history_my-chain_1")
+	testutil.AssertEquals(t, GetDBName("MyChain"), "history_$4dy$43hain")
+	testutil.AssertNotEquals(t, GetDBName("MyChain"), GetDBName("mychain"))
+}
+
+// Each ledger keeps its own history database, hence its own savepoint
+func TestHistoryDatabaseSavepointPerLedger(t *testing.T) {
+	//call a helper method to load the core.yaml
+	testutil.SetupCoreYAMLConfig("./../../../peer")
+
+	if ledgerconfig.IsHistoryDBEnabled() == true {
+		env1 := newTestEnvHistoryCouchDB(t, GetDBName("ledger1"))
+		env2 := newTestEnvHistoryCouchDB(t, GetDBName("Ledger2"))
+		env1.cleanup()
+		env2.cleanup()
+		defer env1.cleanup()
+		defer env2.cleanup()
+
+		histMgr1 := NewCouchDBHistMgr(env1.couchDBAddress, env1.couchDatabaseName, env1.couchUsername, env1.couchPassword)
+		histMgr2 := NewCouchDBHistMgr(env2.couchDBAddress, env2.couchDatabaseName, env2.couchUsername, env2.couchPassword)
+		testutil.AssertNotNil(t, histMgr1)
+		testutil.AssertNotNil(t, histMgr2)
+
+		for i := uint64(1); i <= 3; i++ {
+			testutil.AssertNoError(t, histMgr1.Commit(common.NewBlock(i, nil)), "")
+		}
+		testutil.AssertNoError(t, histMgr2.Commit(common.NewBlock(1, nil)), "")
+
+		blockNum, err := histMgr1.GetBlockNumFromSavepoint()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, blockNum, uint64(3))
+		blockNum, err = histMgr2.GetBlockNumFromSavepoint()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, blockNum, uint64(1))
+	}
+}
@@ -22,5 +22,11 @@ import "github.com/hyperledger/fabric/core/ledger"
 // HistMgr - an interface that a history manager should implement
 type HistMgr interface {
 	NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error)
+	// Commit writes the history of a block and records the block number as the savepoint.
+	// Committing a block again is expected to leave the history unchanged
 	Commit(block *common.Block) error
+	// GetBlockNumFromSavepoint returns the number of the last block committed to the history, 0 if none
+	GetBlockNumFromSavepoint() (uint64, error)
+	// Clear deletes the history of all the blocks along with the savepoint
+	Clear() error
 }
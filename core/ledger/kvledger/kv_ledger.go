@@ -19,6 +19,7 @@ package kvledger
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/blkstorage"
@@ -45,26 +46,21 @@ type KVLedger struct {
 	blockStore  blkstorage.BlockStore
 	txtmgmt     txmgr.TxMgr
 	historymgmt history.HistMgr
+	// versionedDB is the state DB used by txtmgmt, nil when CouchDB is used
+	versionedDB statedb.VersionedDB
+	// commitLock keeps blocks from being committed while a snapshot is exported
+	commitLock sync.Mutex
 }
 
 // NewKVLedger constructs new `KVLedger`
 func NewKVLedger(versionedDBProvider statedb.VersionedDBProvider, ledgerID string) (*KVLedger, error) {
 	logger.Debugf("Creating KVLedger ledgerID=%s: ", ledgerID)
-	attrsToIndex := []blkstorage.IndexableAttr{
-		blkstorage.IndexableAttrBlockHash,
-		blkstorage.IndexableAttrBlockNum,
-		blkstorage.IndexableAttrTxID,
-		blkstorage.IndexableAttrBlockNumTranNum,
-	}
-	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
-
-	blockStorageDir := ledgerconfig.GetBlockStoragePath(ledgerID)
-	blockStorageConf := fsblkstorage.NewConf(blockStorageDir, ledgerconfig.GetMaxBlockfileSize())
+	blockStorageConf, indexConfig := getBlockStorageConf(ledgerID)
 	blockStore := fsblkstorage.NewFsBlockStore(blockStorageConf, indexConfig)
 
 	//State and History database managers
 	var txmgmt txmgr.TxMgr
-	var historymgmt history.HistMgr
+	var versionedDB statedb.VersionedDB
 
 	if ledgerconfig.IsCouchDBEnabled() == true {
 		//By default we can talk to CouchDB with empty id and pw (""), or you can add your own id and password to talk to a secured CouchDB
@@ -80,70 +76,39 @@ func NewKVLedger(versionedDBProvider statedb.VersionedDBProvider, ledgerID strin
 			couchDBDef.Password) //enter couchDB pw here
 	} else {
 		// Fall back to using goleveldb lockbased transaction manager
-		db := versionedDBProvider.GetDBHandle(ledgerID)
-		txmgmt = lockbasedtxmgr.NewLockBasedTxMgr(db)
+		versionedDB = versionedDBProvider.GetDBHandle(ledgerID)
+		txmgmt = lockbasedtxmgr.NewLockBasedTxMgr(versionedDB)
 	}
 
-	if ledgerconfig.IsHistoryDBEnabled() == true {
-		logger.Debugf("===HISTORYDB=== NewKVLedger() Using CouchDB for transaction history database")
-
-		couchDBDef := ledgerconfig.GetCouchDBDefinition()
-
-		historymgmt = history.NewCouchDBHistMgr(
-			couchDBDef.URL,      //couchDB connection URL
-			"system_history",    //couchDB db name matches ledger name, TODO for now use system_history ledger, eventually allow passing in subledger name
-			couchDBDef.Username, //enter couchDB id here
-			couchDBDef.Password) //enter couchDB pw here
+	historymgmt, err := newHistMgr(ledgerID)
+	if err != nil {
+		blockStore.Shutdown()
+		txmgmt.Shutdown()
+		return nil, err
 	}
 
-	l := &KVLedger{ledgerID, blockStore, txmgmt, historymgmt}
+	l := &KVLedger{ledgerID: ledgerID, blockStore: blockStore, txtmgmt: txmgmt,
+		historymgmt: historymgmt, versionedDB: versionedDB}
 
-	if err := recoverStateDB(l); err != nil {
-		panic(fmt.Errorf(`Error during state DB recovery:%s`, err))
+	if err := recoverDBs(l); err != nil {
+		panic(fmt.Errorf(`Error during state DB and history DB recovery:%s`, err))
 	}
 
 	return l, nil
 }
 
-//Recover the state database by recommitting last valid blocks
-func recoverStateDB(l *KVLedger) error {
-	//If there is no block in blockstorage, nothing to recover.
-	info, _ := l.blockStore.GetBlockchainInfo()
-	if info.Height == 0 {
-		return nil
-	}
-
-	//Getting savepointValue stored in the state DB
-	var err error
-	var savepointValue uint64
-	if savepointValue, err = l.txtmgmt.GetBlockNumFromSavepoint(); err != nil {
-		return err
-	}
-
-	//Checking whether the savepointValue is in sync with block storage height
-	if savepointValue == info.Height {
-		return nil
-	} else if savepointValue > info.Height {
-		return errors.New("BlockStorage height is behind savepoint by %d blocks. Recovery the BlockStore first")
-	}
-
-	//Compute updateSet for each missing savepoint and commit to state DB
-	for blockNumber := savepointValue + 1; blockNumber <= info.Height; blockNumber++ {
-		var block *common.Block
-		if block, err = l.GetBlockByNumber(blockNumber); err != nil {
-			return err
-		}
-		logger.Debugf("Constructing updateSet for the block %d", blockNumber)
-		if err = l.txtmgmt.ValidateAndPrepare(block, false); err != nil {
-			return err
-		}
-		logger.Debugf("Committing block %d to state database", blockNumber)
-		if err = l.txtmgmt.Commit(); err != nil {
-			return err
-		}
+// getBlockStorageConf returns the configuration of the block storage of a ledger
+func getBlockStorageConf(ledgerID string) (*fsblkstorage.Conf, *blkstorage.IndexConfig) {
+	attrsToIndex := []blkstorage.IndexableAttr{
+		blkstorage.IndexableAttrBlockHash,
+		blkstorage.IndexableAttrBlockNum,
+		blkstorage.IndexableAttrTxID,
+		blkstorage.IndexableAttrBlockNumTranNum,
 	}
+	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
 
-	return nil
+	blockStorageDir := ledgerconfig.GetBlockStoragePath(ledgerID)
+	return fsblkstorage.NewConf(blockStorageDir, ledgerconfig.GetMaxBlockfileSize()), indexConfig
 }
 
 // GetTransactionByID retrieves a transaction by id
@@ -203,6 +168,8 @@ func (l *KVLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error
 // Commit commits the valid block (returned in the method RemoveInvalidTransactionsAndPrepare) and related state changes
 func (l *KVLedger) Commit(block *common.Block) error {
 	var err error
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
 
 	logger.Debugf("Validating block")
 	err = l.txtmgmt.ValidateAndPrepare(block, true)
@@ -214,26 +181,41 @@ func (l *KVLedger) Commit(block *common.Block) error {
 	if err = l.blockStore.AddBlock(block); err != nil {
 		return err
 	}
+	afterCommitPhase(block.Header.Number, blockStoreCommitted)
 
+	// The block storage acts as a write-ahead log for the state DB and the history DB.
+	// Both record the last block they committed, and the blocks that they miss because
+	// of a crash are committed again when the ledger is opened (see recoverDBs)
 	logger.Debugf("Committing block to state database")
 	if err = l.txtmgmt.Commit(); err != nil {
 		panic(fmt.Errorf(`Error during commit to txmgr:%s`, err))
 	}
+	afterCommitPhase(block.Header.Number, stateDBCommitted)
 
-	//TODO There are still some decisions to be made regarding how consistent history
-	//needs to stay with the state.  At min will want this to run async with state db writes.
-	//(History needs to wait for the block (FSBlock) to write but not the state db)
-	logger.Debugf("===HISTORYDB=== Commit() will write to history if enabled else will be by-passed if not enabled: vledgerconfig.IsHistoryDBEnabled(): %v\n", ledgerconfig.IsHistoryDBEnabled())
-	if ledgerconfig.IsHistoryDBEnabled() == true {
+	if l.historymgmt != nil {
 		logger.Debugf("Committing transactions to history database")
 		if err := l.historymgmt.Commit(block); err != nil {
 			panic(fmt.Errorf(`Error during commit to txthistory:%s`, err))
 		}
+		afterCommitPhase(block.Header.Number, historyDBCommitted)
 	}
 
 	return nil
 }
 
+// CreateStateIndexes implements method in interface `ledger.ValidatedLedger`
+func (l *KVLedger) CreateStateIndexes(chaincodeName string, indexDefinitions map[string][]byte) error {
+	if len(indexDefinitions) == 0 {
+		return nil
+	}
+	couchDBTxMgr, ok := l.txtmgmt.(*couchdbtxmgmt.CouchDBTxMgr)
+	if !ok {
+		logger.Debugf("Ignoring the indexes of chaincode [%s] as the state database does not support indexes", chaincodeName)
+		return nil
+	}
+	return couchDBTxMgr.CreateIndexes(chaincodeName, indexDefinitions)
+}
+
 // Close closes `KVLedger`
 func (l *KVLedger) Close() {
 	l.blockStore.Shutdown()
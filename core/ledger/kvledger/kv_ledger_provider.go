@@ -18,10 +18,15 @@ package kvledger
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
 
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	// the state databases register themselves with statedb when imported
+	_ "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	_ "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statememorydb"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/util/db"
 )
@@ -47,8 +52,12 @@ func NewProvider() (ledger.ValidatedLedgerProvider, error) {
 	logger.Info("Initializing ledger provider")
 	var vdbProvider statedb.VersionedDBProvider
 	if !ledgerconfig.IsCouchDBEnabled() {
-		logger.Debugf("Constructing leveldb VersionedDBProvider")
-		vdbProvider = stateleveldb.NewVersionedDBProvider()
+		stateDatabase := ledgerconfig.GetStateDatabase()
+		logger.Debugf("Constructing VersionedDBProvider for state database [%s]", stateDatabase)
+		var err error
+		if vdbProvider, err = statedb.NewVersionedDBProvider(stateDatabase); err != nil {
+			return nil, err
+		}
 	} else {
 		//TODO same for couchDB after refactoring of couchdb code
 	}
@@ -75,6 +84,57 @@ func (provider *Provider) Create(ledgerID string) (ledger.ValidatedLedger, error
 	return l, nil
 }
 
+// CreateFromSnapshot implements the corresponding method from interface ledger.ValidatedLedgerProvider.
+// The snapshot is read twice: once to verify its hash and once to import it. The ledger id is
+// only recorded once the snapshot is imported, so the data of a ledger whose id is not recorded
+// are left over by an import that failed or crashed. They are deleted before the import and
+// after it fails, so that the import can be retried
+func (provider *Provider) CreateFromSnapshot(snapshotReader io.ReadSeeker, hash []byte) (string, ledger.ValidatedLedger, error) {
+	if provider.vdbProvider == nil {
+		return "", nil, ErrSnapshotNotSupported
+	}
+	s, err := verifySnapshot(snapshotReader, hash)
+	if err != nil {
+		return "", nil, err
+	}
+	exists, err := provider.idStore.ledgerIDExists(s.LedgerID)
+	if err != nil {
+		return "", nil, err
+	}
+	if exists {
+		return "", nil, ErrLedgerIDExists
+	}
+	if err = provider.deleteLedgerData(s.LedgerID); err != nil {
+		return "", nil, fmt.Errorf("Error deleting the data left over for ledger [%s]: %s", s.LedgerID, err)
+	}
+	l, err := NewKVLedger(provider.vdbProvider, s.LedgerID)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err = snapshotReader.Seek(0, io.SeekStart); err == nil {
+		if err = l.importSnapshot(snapshotReader); err == nil {
+			err = provider.idStore.createLedgerID(s.LedgerID)
+		}
+	}
+	if err != nil {
+		l.Close()
+		if deleteErr := provider.deleteLedgerData(s.LedgerID); deleteErr != nil {
+			logger.Errorf("Error deleting the data of ledger [%s] after a failed import: %s", s.LedgerID, deleteErr)
+		}
+		return "", nil, err
+	}
+	return s.LedgerID, l, nil
+}
+
+// deleteLedgerData deletes the state and the blocks of a ledger that must not be open. The
+// history DB is kept as importing a snapshot commits its last block to the history DB again
+func (provider *Provider) deleteLedgerData(ledgerID string) error {
+	if err := provider.vdbProvider.DeleteDB(ledgerID); err != nil {
+		return err
+	}
+	return os.RemoveAll(ledgerconfig.GetBlockStoragePath(ledgerID))
+}
+
 // Open implements the corresponding method from interface ledger.ValidatedLedgerProvider
 func (provider *Provider) Open(ledgerID string) (ledger.ValidatedLedger, error) {
 	exists, err := provider.idStore.ledgerIDExists(ledgerID)
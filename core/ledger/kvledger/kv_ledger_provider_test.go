@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statememorydb"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/spf13/viper"
 )
 
 func TestLedgerProvider(t *testing.T) {
@@ -56,6 +58,38 @@ func TestLedgerProvider(t *testing.T) {
 	testutil.AssertEquals(t, err, ErrNonExistingLedgerID)
 }
 
+func TestLedgerProviderStateDatabase(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.state.stateDatabase", "goleveldb")
+
+	viper.Set("ledger.state.stateDatabase", "unknownDB")
+	_, err := NewProvider()
+	testutil.AssertError(t, err, "Expected an error for an unknown state database")
+
+	viper.Set("ledger.state.stateDatabase", statememorydb.StateDatabaseName)
+	provider, err := NewProvider()
+	testutil.AssertNoError(t, err, "")
+	defer provider.Close()
+	_, ok := provider.(*Provider).vdbProvider.(*statememorydb.VersionedDBProvider)
+	testutil.AssertEquals(t, ok, true)
+
+	l, err := provider.Create("testLedger")
+	testutil.AssertNoError(t, err, "")
+	defer l.Close()
+	s, _ := l.NewTxSimulator()
+	s.SetState("ns", "key1", []byte("value1"))
+	s.Done()
+	res, _ := s.GetTxSimulationResults()
+	testutil.AssertNoError(t, l.Commit(testutil.ConstructBlock(t, [][]byte{res}, false)), "")
+
+	q, _ := l.NewQueryExecutor()
+	defer q.Done()
+	value, err := q.GetState("ns", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value1"))
+}
+
 func TestMultipleLedgerBasicRW(t *testing.T) {
 	env := newTestEnv(t)
 	defer env.cleanup()
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvledger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/history"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// commitPhase identifies the steps of KVLedger.Commit, in the order in which they are performed
+type commitPhase int
+
+const (
+	blockStoreCommitted commitPhase = iota
+	stateDBCommitted
+	historyDBCommitted
+)
+
+// afterCommitPhase is invoked each time a step of KVLedger.Commit completes.
+// Tests replace it to simulate a crash between the steps
+var afterCommitPhase = func(blockNum uint64, phase commitPhase) {}
+
+// newHistMgr constructs the history database manager of a ledger, nil when the history database is disabled.
+// Tests replace it to use a history database that does not require CouchDB
+var newHistMgr = func(ledgerID string) (history.HistMgr, error) {
+	if !ledgerconfig.IsHistoryDBEnabled() {
+		return nil, nil
+	}
+	logger.Debugf("===HISTORYDB=== NewKVLedger() Using CouchDB for transaction history database")
+	couchDBDef := ledgerconfig.GetCouchDBDefinition()
+	historymgmt := history.NewCouchDBHistMgr(
+		couchDBDef.URL,              //couchDB connection URL
+		history.GetDBName(ledgerID), //couchDB db name derived from the ledger id
+		couchDBDef.Username,         //enter couchDB id here
+		couchDBDef.Password)         //enter couchDB pw here
+	if historymgmt == nil {
+		return nil, errors.New("Could not create the history database")
+	}
+	return historymgmt, nil
+}
+
+// recoverableDB is a database derived from the blocks that records the number of the last block it committed
+type recoverableDB interface {
+	name() string
+	getBlockNumFromSavepoint() (uint64, error)
+	// recommit commits again a block that the database missed
+	recommit(block *common.Block) error
+}
+
+type recoverableStateDB struct {
+	txmgmt txmgr.TxMgr
+}
+
+func (r *recoverableStateDB) name() string {
+	return "state DB"
+}
+
+func (r *recoverableStateDB) getBlockNumFromSavepoint() (uint64, error) {
+	return r.txmgmt.GetBlockNumFromSavepoint()
+}
+
+func (r *recoverableStateDB) recommit(block *common.Block) error {
+	// the block storage keeps the validation results, hence the block is not validated again
+	if err := r.txmgmt.ValidateAndPrepare(block, false); err != nil {
+		return err
+	}
+	return r.txmgmt.Commit()
+}
+
+type recoverableHistoryDB struct {
+	historymgmt history.HistMgr
+}
+
+func (r *recoverableHistoryDB) name() string {
+	return "history DB"
+}
+
+func (r *recoverableHistoryDB) getBlockNumFromSavepoint() (uint64, error) {
+	return r.historymgmt.GetBlockNumFromSavepoint()
+}
+
+func (r *recoverableHistoryDB) recommit(block *common.Block) error {
+	return r.historymgmt.Commit(block)
+}
+
+// recoverDBs brings the state DB and the history DB up to date with the block storage by
+// committing again the blocks that follow their savepoints. As KVLedger.Commit adds a block
+// to the block storage before any other database, the block storage is never behind them
+func recoverDBs(l *KVLedger) error {
+	//If there is no block in blockstorage, nothing to recover.
+	info, _ := l.blockStore.GetBlockchainInfo()
+	if info.Height == 0 {
+		return nil
+	}
+
+	dbs := []recoverableDB{&recoverableStateDB{l.txtmgmt}}
+	if l.historymgmt != nil {
+		dbs = append(dbs, &recoverableHistoryDB{l.historymgmt})
+	}
+
+	//Getting the savepoints of the databases and checking whether they are in sync with block storage height
+	savepoints := make([]uint64, len(dbs))
+	firstBlockToRecommit := info.Height + 1
+	for i, db := range dbs {
+		savepoint, err := db.getBlockNumFromSavepoint()
+		if err != nil {
+			return err
+		}
+		if savepoint > info.Height {
+			return fmt.Errorf("BlockStorage height is behind the %s savepoint by %d blocks. Recover the BlockStore first", db.name(), savepoint-info.Height)
+		}
+		if savepoint < info.Height {
+			logger.Infof("Recovering the %s of ledger [%s] from block %d to block %d", db.name(), l.ledgerID, savepoint+1, info.Height)
+		}
+		savepoints[i] = savepoint
+		if savepoint+1 < firstBlockToRecommit {
+			firstBlockToRecommit = savepoint + 1
+		}
+	}
+
+	//Commit each missing block to the databases that miss it
+	for blockNumber := firstBlockToRecommit; blockNumber <= info.Height; blockNumber++ {
+		block, err := l.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return err
+		}
+		for i, db := range dbs {
+			if savepoints[i] >= blockNumber {
+				continue
+			}
+			logger.Debugf("Committing block %d to the %s", blockNumber, db.name())
+			if err = db.recommit(block); err != nil {
+				return fmt.Errorf("Error committing block %d to the %s: %s", blockNumber, db.name(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kvledger
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	ledgerpackage "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/history"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+	"github.com/hyperledger/fabric/core/ledger/util/db"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/spf13/viper"
+)
+
+const (
+	crashPhaseEnvVar = "KVLEDGER_TEST_CRASH_PHASE"
+	crashExitCode    = 3
+	crashLedgerID    = "testLedger"
+)
+
+var savepointKey = []byte("savepoint")
+
+// testHistMgr is a leveldb backed history manager that stands in for the CouchDB one.
+// The db is opened for each operation so that a ledger can be reopened within the same process
+type testHistMgr struct {
+	dbPath string
+}
+
+func newTestHistMgr(ledgerID string) (history.HistMgr, error) {
+	return &testHistMgr{filepath.Join(ledgerconfig.GetRootPath(), "testHistory", ledgerID)}, nil
+}
+
+func (h *testHistMgr) openDB() *db.DB {
+	dbInst := db.CreateDB(&db.Conf{DBPath: h.dbPath})
+	dbInst.Open()
+	return dbInst
+}
+
+func (h *testHistMgr) NewHistoryQueryExecutor() (ledgerpackage.HistoryQueryExecutor, error) {
+	return nil, errors.New("Not supported by the test history manager")
+}
+
+func (h *testHistMgr) Commit(block *common.Block) error {
+	dbInst := h.openDB()
+	defer dbInst.Close()
+	blockNum := strconv.FormatUint(block.Header.Number, 10)
+	if err := dbInst.Put([]byte("block"+blockNum), block.Header.DataHash, true); err != nil {
+		return err
+	}
+	return dbInst.Put(savepointKey, []byte(blockNum), true)
+}
+
+func (h *testHistMgr) GetBlockNumFromSavepoint() (uint64, error) {
+	dbInst := h.openDB()
+	defer dbInst.Close()
+	savepoint, err := dbInst.Get(savepointKey)
+	if err != nil || savepoint == nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(savepoint), 10, 64)
+}
+
+func (h *testHistMgr) Clear() error {
+	return os.RemoveAll(h.dbPath)
+}
+
+func setupCrashTest() func() {
+	origNewHistMgr := newHistMgr
+	newHistMgr = newTestHistMgr
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/ledgertests/crashrecovery")
+	return func() { newHistMgr = origNewHistMgr }
+}
+
+func crashedBlockPath() string {
+	return filepath.Join(ledgerconfig.GetRootPath(), "crashedBlock")
+}
+
+// TestCrashRecoveryHelper commits a block and kills the process after the phase named by
+// the environment variable. It only runs as a child process of TestCrashRecovery
+func TestCrashRecoveryHelper(t *testing.T) {
+	phase := os.Getenv(crashPhaseEnvVar)
+	if phase == "" {
+		t.Skip("Only runs as a child process of TestCrashRecovery")
+	}
+	crashAfter, _ := strconv.Atoi(phase)
+	defer setupCrashTest()()
+
+	blockBytes, err := ioutil.ReadFile(crashedBlockPath())
+	testutil.AssertNoError(t, err, "Error while reading the block to commit")
+	block := &common.Block{}
+	testutil.AssertNoError(t, proto.Unmarshal(blockBytes, block), "")
+
+	afterCommitPhase = func(blockNum uint64, phase commitPhase) {
+		if blockNum == block.Header.Number && phase == commitPhase(crashAfter) {
+			os.Exit(crashExitCode)
+		}
+	}
+	provider, _ := NewProvider()
+	ledger, err := provider.Open(crashLedgerID)
+	testutil.AssertNoError(t, err, "Error while opening ledger")
+	ledger.Commit(block)
+	t.Fatalf("Process was expected to exit after commit phase %d", crashAfter)
+}
+
+func TestCrashRecovery(t *testing.T) {
+	for _, phase := range []commitPhase{blockStoreCommitted, stateDBCommitted, historyDBCommitted} {
+		t.Run(fmt.Sprintf("phase%d", phase), func(t *testing.T) { testCrashRecovery(t, phase) })
+	}
+}
+
+func testCrashRecovery(t *testing.T, phase commitPhase) {
+	defer setupCrashTest()()
+	env := &testEnv{t}
+	env.cleanup()
+	defer env.cleanup()
+
+	provider, _ := NewProvider()
+	ledger, err := provider.Create(crashLedgerID)
+	testutil.AssertNoError(t, err, "Error while creating ledger")
+	bg := testutil.NewBlockGenerator(t)
+	blocks := []*common.Block{}
+	for i := 1; i <= 3; i++ {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(fmt.Sprintf("value%d", i)))
+		simulator.SetState("ns1", fmt.Sprintf("key%d", i+1), []byte(fmt.Sprintf("value%d", i)))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		blocks = append(blocks, bg.NextBlock([][]byte{simRes}, false))
+	}
+	testutil.AssertNoError(t, ledger.Commit(blocks[0]), "")
+	ledger.Close()
+	provider.Close()
+
+	// commit the second block in a child process that dies after the given phase
+	blockBytes, _ := proto.Marshal(blocks[1])
+	testutil.AssertNoError(t, ioutil.WriteFile(crashedBlockPath(), blockBytes, 0644), "")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashRecoveryHelper$")
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", crashPhaseEnvVar, phase))
+	output, err := cmd.CombinedOutput()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.Sys().(syscall.WaitStatus).ExitStatus() != crashExitCode {
+		t.Fatalf("Child process did not crash as expected. err=%v, output:\n%s", err, output)
+	}
+
+	// reopening the ledger recovers the state and history from the block storage
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open(crashLedgerID)
+	testutil.AssertNoError(t, err, "Error while reopening ledger")
+	defer ledger.Close()
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(2))
+	assertCrashTestState(t, ledger, 2)
+	histSavepoint, _ := newTestHistMgr(crashLedgerID)
+	blockNum, err := histSavepoint.GetBlockNumFromSavepoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, blockNum, uint64(2))
+
+	// the ledger keeps committing after the recovery
+	testutil.AssertNoError(t, ledger.Commit(blocks[2]), "Error while committing after recovery")
+	assertCrashTestState(t, ledger, 3)
+	blockNum, _ = histSavepoint.GetBlockNumFromSavepoint()
+	testutil.AssertEquals(t, blockNum, uint64(3))
+}
+
+func assertCrashTestState(t *testing.T, ledger ledgerpackage.ValidatedLedger, lastBlock int) {
+	qe, _ := ledger.NewQueryExecutor()
+	defer qe.Done()
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte(fmt.Sprintf("value%d", lastBlock)))
+	for i := 1; i <= lastBlock; i++ {
+		value, _ = qe.GetState("ns1", fmt.Sprintf("key%d", i+1))
+		testutil.AssertEquals(t, value, []byte(fmt.Sprintf("value%d", i)))
+	}
+}
+
+// The ledgers keep their history in their own CouchDB databases, hence their
+// history DB savepoints and the histories of their keys do not interfere
+func TestHistoryDBsOfTwoLedgers(t *testing.T) {
+	//call a helper method to load the core.yaml
+	testutil.SetupCoreYAMLConfig("./../../../peer")
+
+	if ledgerconfig.IsHistoryDBEnabled() == true {
+		ledgerIDs := []string{"historyLedger1", "historyLedger2"}
+		dropHistoryDBs := func() {
+			couchDBDef := ledgerconfig.GetCouchDBDefinition()
+			for _, ledgerID := range ledgerIDs {
+				couchDB, err := couchdb.CreateConnectionDefinition(couchDBDef.URL, history.GetDBName(ledgerID),
+					couchDBDef.Username, couchDBDef.Password)
+				if err == nil {
+					couchDB.DropDatabase()
+				}
+			}
+		}
+		dropHistoryDBs()
+		defer dropHistoryDBs()
+
+		histMgrs := make([]history.HistMgr, len(ledgerIDs))
+		for i, ledgerID := range ledgerIDs {
+			histMgr, err := newHistMgr(ledgerID)
+			testutil.AssertNoError(t, err, "Error while creating the history DB")
+			histMgrs[i] = histMgr
+			// the first ledger commits two blocks and the second one a single block
+			bg := testutil.NewBlockGenerator(t)
+			for j := i; j < 2; j++ {
+				txRWSet := &rwset.TxReadWriteSet{NsRWs: []*rwset.NsReadWriteSet{{NameSpace: "ns1",
+					Writes: []*rwset.KVWrite{{Key: ledgerID, Value: []byte(fmt.Sprintf(`{"value":%d}`, j))}}}}}
+				simRes, err := txRWSet.Marshal()
+				testutil.AssertNoError(t, err, "")
+				testutil.AssertNoError(t, histMgr.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+			}
+		}
+
+		for i, histMgr := range histMgrs {
+			blockNum, err := histMgr.GetBlockNumFromSavepoint()
+			testutil.AssertNoError(t, err, "")
+			testutil.AssertEquals(t, blockNum, uint64(2-i))
+
+			qe, _ := histMgr.NewHistoryQueryExecutor()
+			itr, err := qe.GetTransactionsForKey("ns1", ledgerIDs[i], true, false)
+			testutil.AssertNoError(t, err, "")
+			kmod, _ := itr.Next()
+			testutil.AssertNotNil(t, kmod)
+			// the history of the key written by the other ledger is not visible
+			itr, err = qe.GetTransactionsForKey("ns1", ledgerIDs[1-i], true, false)
+			testutil.AssertNoError(t, err, "")
+			kmod, _ = itr.Next()
+			testutil.AssertNil(t, kmod)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// snapshotVersion is the version of the snapshot encoding
+const snapshotVersion = 2
+
+// snapshotChunkSize is the maximum number of state entries or transaction IDs
+// written in a chunk of a snapshot
+const snapshotChunkSize = 1000
+
+// maxSnapshotMessageSize bounds the size of a message read from a snapshot so
+// that a corrupted length does not exhaust the memory
+const maxSnapshotMessageSize = 100 * 1024 * 1024
+
+// ErrSnapshotNotSupported is thrown when a snapshot is exported from or
+// imported into a ledger whose state is kept in CouchDB
+var ErrSnapshotNotSupported = errors.New("Snapshots are only supported with the goleveldb state database")
+
+// ErrSnapshotHashMismatch is thrown when the hash of a snapshot does not match
+// the expected one
+var ErrSnapshotHashMismatch = errors.New("Snapshot does not match the expected hash")
+
+// ExportSnapshot writes a snapshot of the ledger as of its last block to w.
+// It holds the last block, the block holding the latest configuration of the
+// chain, the IDs of all the committed transactions and the state, written in
+// chunks. The returned info holds the number of the last block and the hash
+// of the snapshot, which is anchored to the header of the last block. Blocks
+// are not committed while the snapshot is taken
+func (l *KVLedger) ExportSnapshot(w io.Writer) (*snapshot.SnapshotInfo, error) {
+	if l.versionedDB == nil {
+		return nil, ErrSnapshotNotSupported
+	}
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.Height == 0 {
+		return nil, fmt.Errorf("Cannot export a snapshot of ledger [%s] as it has no blocks", l.ledgerID)
+	}
+	lastBlock, err := l.blockStore.RetrieveBlockByNumber(info.Height)
+	if err != nil {
+		return nil, err
+	}
+	configBlock, err := l.getSnapshotConfigBlock(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	sw := newSnapshotWriter(w, lastBlock)
+	if err = sw.write(&snapshot.Snapshot{Version: snapshotVersion, LedgerID: l.ledgerID, LastBlock: lastBlock,
+		ConfigBlock: configBlock}); err != nil {
+		return nil, err
+	}
+
+	txIDs, err := l.blockStore.RetrieveTxIDs()
+	if err != nil {
+		return nil, err
+	}
+	for len(txIDs) > 0 {
+		n := len(txIDs)
+		if n > snapshotChunkSize {
+			n = snapshotChunkSize
+		}
+		if err = sw.write(&snapshot.SnapshotChunk{TxIDs: txIDs[:n]}); err != nil {
+			return nil, err
+		}
+		txIDs = txIDs[n:]
+	}
+
+	itr, err := l.versionedDB.GetStateFullScanIterator()
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	numKeys := 0
+	chunk := &snapshot.SnapshotChunk{}
+	for {
+		kv, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if kv == nil {
+			break
+		}
+		chunk.State = append(chunk.State, &snapshot.StateEntry{Namespace: kv.Namespace, Key: kv.Key, Value: kv.Value,
+			BlockNum: kv.Version.BlockNum, TxNum: kv.Version.TxNum})
+		numKeys++
+		if len(chunk.State) == snapshotChunkSize {
+			if err = sw.write(chunk); err != nil {
+				return nil, err
+			}
+			chunk = &snapshot.SnapshotChunk{}
+		}
+	}
+	if len(chunk.State) > 0 {
+		if err = sw.write(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("Exported snapshot of ledger [%s] at block [%d] with [%d] keys",
+		l.ledgerID, lastBlock.Header.Number, numKeys)
+	return &snapshot.SnapshotInfo{BlockNumber: lastBlock.Header.Number, Hash: sw.hash.Sum(nil)}, nil
+}
+
+// getSnapshotConfigBlock returns the block referenced by the LAST_CONFIGURATION
+// metadata of the last block, or nil if it is the last block itself or the
+// metadata is not set
+func (l *KVLedger) getSnapshotConfigBlock(lastBlock *common.Block) (*common.Block, error) {
+	if lastBlock.Metadata == nil || len(lastBlock.Metadata.Metadata) <= int(common.BlockMetadataIndex_LAST_CONFIGURATION) {
+		return nil, nil
+	}
+	md, err := utils.GetMetadataFromBlock(lastBlock, common.BlockMetadataIndex_LAST_CONFIGURATION)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the last configuration of block [%d]: %s", lastBlock.Header.Number, err)
+	}
+	if len(md.Value) == 0 {
+		logger.Debugf("No config block referenced by block [%d]", lastBlock.Header.Number)
+		return nil, nil
+	}
+	lc := &common.LastConfiguration{}
+	if err = proto.Unmarshal(md.Value, lc); err != nil {
+		return nil, fmt.Errorf("Error reading the last configuration of block [%d]: %s", lastBlock.Header.Number, err)
+	}
+	if lc.Index == lastBlock.Header.Number {
+		return nil, nil
+	}
+	return l.blockStore.RetrieveBlockByNumber(lc.Index)
+}
+
+// importSnapshot bootstraps the empty ledger from the snapshot in r, which
+// must have been verified with verifySnapshot. The block storage starts at
+// the last block of the snapshot and the state DB is set to the state as of
+// that block. The history DB starts with the last block, so that its
+// savepoint is set and the blocks before it are not recovered when the
+// ledger is opened. The history of the keys before the snapshot is not
+// available
+func (l *KVLedger) importSnapshot(r io.Reader) error {
+	if l.versionedDB == nil {
+		return ErrSnapshotNotSupported
+	}
+	sr := newSnapshotReader(r)
+	s := &snapshot.Snapshot{}
+	if _, err := sr.read(s); err != nil {
+		return err
+	}
+	lastBlock := s.LastBlock
+	savepoint := version.NewHeight(lastBlock.Header.Number, uint64(len(lastBlock.Data.Data)))
+
+	var txIDs []string
+	stateImported := false
+	for {
+		chunk := &snapshot.SnapshotChunk{}
+		ok, err := sr.read(chunk)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		txIDs = append(txIDs, chunk.TxIDs...)
+		if len(chunk.State) == 0 {
+			continue
+		}
+		batch := statedb.NewUpdateBatch()
+		for _, entry := range chunk.State {
+			value := entry.Value
+			if value == nil {
+				value = []byte{}
+			}
+			batch.Put(entry.Namespace, entry.Key, value, version.NewHeight(entry.BlockNum, entry.TxNum))
+		}
+		if err = l.versionedDB.ApplyUpdates(batch, savepoint); err != nil {
+			return err
+		}
+		stateImported = true
+	}
+	if !stateImported {
+		// the savepoint of the state DB is still set for an empty state
+		if err := l.versionedDB.ApplyUpdates(statedb.NewUpdateBatch(), savepoint); err != nil {
+			return err
+		}
+	}
+	if err := l.blockStore.BootstrapFromSnapshot(lastBlock, s.ConfigBlock, txIDs); err != nil {
+		return err
+	}
+	if l.historymgmt != nil {
+		return l.historymgmt.Commit(lastBlock)
+	}
+	return nil
+}
+
+// verifySnapshot reads the whole snapshot in r and checks that its hash is
+// expectedHash and that the data of its blocks match their headers. It
+// returns the header of the snapshot
+func verifySnapshot(r io.Reader, expectedHash []byte) (*snapshot.Snapshot, error) {
+	sr := newSnapshotReader(r)
+	s := &snapshot.Snapshot{}
+	ok, err := sr.read(s)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("Snapshot is empty")
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("Unsupported snapshot version [%d]", s.Version)
+	}
+	if err = checkSnapshotBlock(s.LastBlock); err != nil {
+		return nil, fmt.Errorf("Invalid last block in snapshot of ledger [%s]: %s", s.LedgerID, err)
+	}
+	if s.ConfigBlock != nil {
+		if err = checkSnapshotBlock(s.ConfigBlock); err != nil {
+			return nil, fmt.Errorf("Invalid config block in snapshot of ledger [%s]: %s", s.LedgerID, err)
+		}
+	}
+	for {
+		ok, err = sr.read(&snapshot.SnapshotChunk{})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	if !bytes.Equal(sr.hash.Sum(nil), expectedHash) {
+		return nil, ErrSnapshotHashMismatch
+	}
+	return s, nil
+}
+
+// checkSnapshotBlock checks that the data of a block of a snapshot match the
+// data hash of its header
+func checkSnapshotBlock(block *common.Block) error {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return errors.New("block is missing")
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("data of block [%d] does not match its header", block.Header.Number)
+	}
+	return nil
+}
+
+// snapshotWriter writes the length-delimited messages of a snapshot and
+// hashes them. The hash starts with the hash of the header of the last block
+// of the snapshot
+type snapshotWriter struct {
+	w    io.Writer
+	hash hash.Hash
+}
+
+func newSnapshotWriter(w io.Writer, lastBlock *common.Block) *snapshotWriter {
+	h := sha256.New()
+	h.Write(lastBlock.Header.Hash())
+	return &snapshotWriter{w: w, hash: h}
+}
+
+func (sw *snapshotWriter) write(msg proto.Message) error {
+	buf := proto.NewBuffer(nil)
+	if err := buf.EncodeMessage(msg); err != nil {
+		return err
+	}
+	sw.hash.Write(buf.Bytes())
+	_, err := sw.w.Write(buf.Bytes())
+	return err
+}
+
+// snapshotReader reads the length-delimited messages of a snapshot and hashes
+// them the way snapshotWriter does. The hash is started when the header of
+// the snapshot is read
+type snapshotReader struct {
+	r    *bufio.Reader
+	hash hash.Hash
+}
+
+func newSnapshotReader(r io.Reader) *snapshotReader {
+	return &snapshotReader{r: bufio.NewReader(r)}
+}
+
+// read reads the next message of the snapshot into msg. It returns false at
+// the end of the snapshot
+func (sr *snapshotReader) read(msg proto.Message) (bool, error) {
+	size, err := binary.ReadUvarint(sr.r)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Error reading snapshot: %s", err)
+	}
+	if size > maxSnapshotMessageSize {
+		return false, fmt.Errorf("Error reading snapshot: message of [%d] bytes exceeds the maximum size", size)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(sr.r, data); err != nil {
+		return false, fmt.Errorf("Error reading snapshot: %s", err)
+	}
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return false, fmt.Errorf("Error unmarshalling snapshot: %s", err)
+	}
+	if sr.hash == nil {
+		s, ok := msg.(*snapshot.Snapshot)
+		if !ok || s.LastBlock == nil || s.LastBlock.Header == nil {
+			return false, errors.New("Snapshot has no last block")
+		}
+		sr.hash = sha256.New()
+		sr.hash.Write(s.LastBlock.Header.Hash())
+	}
+	sr.hash.Write(proto.EncodeVarint(size))
+	sr.hash.Write(data)
+	return true, nil
+}
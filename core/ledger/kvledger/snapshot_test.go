@@ -0,0 +1,320 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/hyperledger/fabric/core/ledger/blkstorage"
+	"github.com/hyperledger/fabric/core/ledger/history"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+	snapshotpb "github.com/hyperledger/fabric/protos/ledger/snapshot"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestSnapshotExportAndImport(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	var blocks []*common.Block
+	for i, value := range []string{"value1", "value2", "value3"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.SetState("ns2", "key"+value, []byte(value))
+		if i == 0 {
+			// the state spans more than a chunk of the snapshot
+			for j := 0; j <= snapshotChunkSize; j++ {
+				simulator.SetState("ns3", fmt.Sprintf("key%d", j), []byte(value))
+			}
+		}
+		if i == 1 {
+			simulator.DeleteState("ns2", "keyvalue1")
+		}
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		blocks = append(blocks, bg.NextBlock([][]byte{simRes}, false))
+	}
+	// the last block references the second one as the config block
+	blocks[2].Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIGURATION] = utils.MarshalOrPanic(
+		&common.Metadata{Value: utils.MarshalOrPanic(&common.LastConfiguration{Index: 2})})
+	for _, block := range blocks {
+		testutil.AssertNoError(t, ledger.Commit(block), "")
+	}
+	txEnv, err := utils.GetEnvelopeFromBlock(blocks[0].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	txPayload, err := utils.GetPayload(txEnv)
+	testutil.AssertNoError(t, err, "")
+	txID := txPayload.Header.ChainHeader.TxID
+
+	var buf bytes.Buffer
+	info, err := ledger.ExportSnapshot(&buf)
+	testutil.AssertNoError(t, err, "Error while exporting snapshot")
+	testutil.AssertEquals(t, info.BlockNumber, uint64(3))
+	snapshot := buf.Bytes()
+	_, _, err = provider.CreateFromSnapshot(bytes.NewReader(snapshot), info.Hash)
+	testutil.AssertEquals(t, err, ErrLedgerIDExists)
+	ledger.Close()
+	provider.Close()
+
+	// create the ledger from the snapshot on a fresh peer
+	env.cleanup()
+	provider, _ = NewProvider()
+	defer provider.Close()
+	_, _, err = provider.CreateFromSnapshot(bytes.NewReader(snapshot), []byte("wronghash"))
+	testutil.AssertEquals(t, err, ErrSnapshotHashMismatch)
+	ledgerID, ledger, err := provider.CreateFromSnapshot(bytes.NewReader(snapshot), info.Hash)
+	testutil.AssertNoError(t, err, "Error while creating ledger from snapshot")
+	testutil.AssertEquals(t, ledgerID, "testLedger")
+
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(3))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[2].Header.Hash())
+	configBlock, err := ledger.GetBlockByNumber(2)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, configBlock, blocks[1])
+	_, err = ledger.GetBlockByNumber(1)
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+	_, err = ledger.GetTransactionByID(txID)
+	testutil.AssertEquals(t, err, blkstorage.ErrTxInSnapshot)
+
+	qe, _ := ledger.NewQueryExecutor()
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value3"))
+	value, _ = qe.GetState("ns2", "keyvalue1")
+	testutil.AssertNil(t, value)
+	value, _ = qe.GetState("ns2", "keyvalue2")
+	testutil.AssertEquals(t, value, []byte("value2"))
+	value, _ = qe.GetState("ns3", fmt.Sprintf("key%d", snapshotChunkSize))
+	testutil.AssertEquals(t, value, []byte("value1"))
+	qe.Done()
+
+	// blocks following the snapshot validate against the imported versions
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.GetState("ns2", "keyvalue2")
+	simulator.SetState("ns2", "keyvalue2", []byte("value4"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+	ledger.Close()
+
+	ledger, err = provider.Open("testLedger")
+	testutil.AssertNoError(t, err, "")
+	defer ledger.Close()
+	bcInfo, _ = ledger.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(4))
+	qe, _ = ledger.NewQueryExecutor()
+	value, _ = qe.GetState("ns2", "keyvalue2")
+	testutil.AssertEquals(t, value, []byte("value4"))
+	qe.Done()
+}
+
+func TestSnapshotVerification(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+	defer ledger.Close()
+
+	bg := testutil.NewBlockGenerator(t)
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+
+	var buf bytes.Buffer
+	info, err := ledger.ExportSnapshot(&buf)
+	testutil.AssertNoError(t, err, "Error while exporting snapshot")
+	header := &snapshotpb.Snapshot{}
+	_, err = newSnapshotReader(bytes.NewReader(buf.Bytes())).read(header)
+	testutil.AssertNoError(t, err, "")
+
+	// rewriting the snapshot with the same hash calculation is detected
+	// through the header of the last block
+	header.LastBlock.Header.PreviousHash = []byte("otherhash")
+	var tampered bytes.Buffer
+	sw := newSnapshotWriter(&tampered, header.LastBlock)
+	testutil.AssertNoError(t, sw.write(header), "")
+	_, err = verifySnapshot(bytes.NewReader(tampered.Bytes()), info.Hash)
+	testutil.AssertEquals(t, err, ErrSnapshotHashMismatch)
+
+	// the data of the last block must match its header
+	header.LastBlock.Data.Data = append(header.LastBlock.Data.Data, []byte("tx"))
+	headerBytes, _ := proto.Marshal(header)
+	_, err = verifySnapshot(bytes.NewReader(append(proto.EncodeVarint(uint64(len(headerBytes))), headerBytes...)), info.Hash)
+	testutil.AssertError(t, err, "Expected an error for a block whose data do not match its header")
+
+	// a truncated snapshot does not match the hash
+	_, err = verifySnapshot(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), info.Hash)
+	testutil.AssertError(t, err, "Expected an error for a truncated snapshot")
+	_, err = verifySnapshot(bytes.NewReader(buf.Bytes()), info.Hash)
+	testutil.AssertNoError(t, err, "")
+}
+
+func TestSnapshotImportSetsHistorySavepoint(t *testing.T) {
+	origNewHistMgr := newHistMgr
+	newHistMgr = newTestHistMgr
+	defer func() { newHistMgr = origNewHistMgr }()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	for _, value := range []string{"value1", "value2"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+	}
+	var buf bytes.Buffer
+	info, err := ledger.ExportSnapshot(&buf)
+	testutil.AssertNoError(t, err, "Error while exporting snapshot")
+	ledger.Close()
+	provider.Close()
+
+	env.cleanup()
+	provider, _ = NewProvider()
+	defer provider.Close()
+	_, ledger, err = provider.CreateFromSnapshot(bytes.NewReader(buf.Bytes()), info.Hash)
+	testutil.AssertNoError(t, err, "Error while creating ledger from snapshot")
+	ledger.Close()
+	histMgr, _ := newTestHistMgr("testLedger")
+	blockNum, err := histMgr.GetBlockNumFromSavepoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, blockNum, uint64(2))
+
+	// reopening the ledger does not recover the history from the first
+	// block, which is not part of the snapshot
+	ledger, err = provider.Open("testLedger")
+	testutil.AssertNoError(t, err, "Error while reopening ledger")
+	ledger.Close()
+}
+
+// failingHistMgr fails to commit blocks to the history DB
+type failingHistMgr struct {
+	history.HistMgr
+}
+
+func (h *failingHistMgr) Commit(block *common.Block) error {
+	return errors.New("history DB is unavailable")
+}
+
+func TestSnapshotImportRetriedAfterFailure(t *testing.T) {
+	origNewHistMgr := newHistMgr
+	newHistMgr = newTestHistMgr
+	defer func() { newHistMgr = origNewHistMgr }()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	for _, value := range []string{"value1", "value2"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+	}
+	var buf bytes.Buffer
+	info, err := ledger.ExportSnapshot(&buf)
+	testutil.AssertNoError(t, err, "Error while exporting snapshot")
+	ledger.Close()
+	provider.Close()
+
+	env.cleanup()
+	provider, _ = NewProvider()
+	defer provider.Close()
+	// the import fails on the history DB, once the state and the blocks are imported
+	newHistMgr = func(ledgerID string) (history.HistMgr, error) {
+		histMgr, err := newTestHistMgr(ledgerID)
+		return &failingHistMgr{histMgr}, err
+	}
+	_, _, err = provider.CreateFromSnapshot(bytes.NewReader(buf.Bytes()), info.Hash)
+	testutil.AssertError(t, err, "Expected an error from the history DB")
+	exists, _ := provider.Exists("testLedger")
+	testutil.AssertEquals(t, exists, false)
+	_, err = os.Stat(ledgerconfig.GetBlockStoragePath("testLedger"))
+	testutil.AssertEquals(t, os.IsNotExist(err), true)
+
+	newHistMgr = newTestHistMgr
+	_, ledger, err = provider.CreateFromSnapshot(bytes.NewReader(buf.Bytes()), info.Hash)
+	testutil.AssertNoError(t, err, "Error while retrying the import of the snapshot")
+	defer ledger.Close()
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(2))
+	qe, _ := ledger.NewQueryExecutor()
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value2"))
+	qe.Done()
+}
+
+func TestSnapshotImportDeletesLeftOverData(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+	var buf bytes.Buffer
+	info, err := ledger.ExportSnapshot(&buf)
+	testutil.AssertNoError(t, err, "Error while exporting snapshot")
+	ledger.Close()
+	provider.Close()
+
+	// an import that crashed left a block and some state behind, without recording the ledger id
+	env.cleanup()
+	provider, _ = NewProvider()
+	defer provider.Close()
+	leftOver, err := NewKVLedger(provider.(*Provider).vdbProvider, "testLedger")
+	testutil.AssertNoError(t, err, "")
+	simulator, _ = leftOver.NewTxSimulator()
+	simulator.SetState("ns1", "key2", []byte("leftover"))
+	simulator.Done()
+	simRes, _ = simulator.GetTxSimulationResults()
+	testutil.AssertNoError(t, leftOver.Commit(testutil.NewBlockGenerator(t).NextBlock([][]byte{simRes}, false)), "")
+	leftOver.Close()
+
+	_, ledger, err = provider.CreateFromSnapshot(bytes.NewReader(buf.Bytes()), info.Hash)
+	testutil.AssertNoError(t, err, "Error while creating ledger from snapshot")
+	defer ledger.Close()
+	qe, _ := ledger.NewQueryExecutor()
+	defer qe.Done()
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value1"))
+	value, _ = qe.GetState("ns1", "key2")
+	testutil.AssertNil(t, value)
+}
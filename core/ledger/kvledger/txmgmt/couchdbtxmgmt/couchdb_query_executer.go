@@ -37,17 +37,7 @@ func (q *CouchDBQueryExecutor) GetState(ns string, key string) ([]byte, error) {
 
 // GetStateMultipleKeys implements method in interface `ledger.QueryExecutor`
 func (q *CouchDBQueryExecutor) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
-	var results [][]byte
-	var value []byte
-	var err error
-	for _, key := range keys {
-		value, err = q.GetState(namespace, key)
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, value)
-	}
-	return results, nil
+	return q.txmgr.getCommittedValues(namespace, keys)
 }
 
 // GetStateRangeScanIterator implements method in interface `ledger.QueryExecutor`
@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	logging "github.com/op/go-logging"
 )
 
@@ -109,6 +110,19 @@ func (s *CouchDBTxSimulator) GetState(ns string, key string) ([]byte, error) {
 	return value, nil
 }
 
+// GetStateMultipleKeys implements method in interface `ledger.QueryExecutor`
+func (s *CouchDBTxSimulator) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := s.GetState(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
 // GetStateRangeScanIterator implements method in interface `ledger.QueryExecutor`
 func (s *CouchDBTxSimulator) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ledger.ResultsIterator, error) {
 	//s.checkDone()
@@ -116,11 +130,17 @@ func (s *CouchDBTxSimulator) GetStateRangeScanIterator(namespace string, startKe
 	if err != nil {
 		return nil, err
 	}
-	return &sKVItr{scanner, s}, nil
+	writes := rwset.SelectWritesInRange(s.getOrCreateNsRWHolder(namespace).writeMap, startKey, endKey)
+	return txmgr.NewRYWRangeScanIterator(&sKVItr{scanner, s}, writes), nil
 }
 
 // ExecuteQuery implements method in interface `ledger.QueryExecutor`
 func (s *CouchDBTxSimulator) ExecuteQuery(query string) (ledger.ResultsIterator, error) {
+	for _, nsRWs := range s.rwMap {
+		if len(nsRWs.writeMap) > 0 {
+			return nil, txmgr.ErrQueryAfterWrites
+		}
+	}
 	scanner, err := s.txmgr.getQuery(query)
 	if err != nil {
 		return nil, err
@@ -20,10 +20,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
 	"github.com/hyperledger/fabric/core/ledger/util/db"
 	"github.com/op/go-logging"
@@ -150,7 +152,7 @@ func (txmgr *CouchDBTxMgr) ValidateAndPrepare(block *common.Block, doMVCCValidat
 		}
 
 		// extract actions from the envelope message
-		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		respPayload, hashedValues, err := putils.GetActionAndHashedValuesFromEnvelope(envBytes)
 		if err != nil {
 			return err
 		}
@@ -164,6 +166,14 @@ func (txmgr *CouchDBTxMgr) ValidateAndPrepare(block *common.Block, doMVCCValidat
 			return err
 		}
 
+		// put back the values that were replaced by their hashes. The transaction
+		// is invalid if they are missing or do not match the endorsed hashes
+		if err = txRWSet.ResolveHashedValues(hashedValues); err != nil {
+			logger.Warningf("Marking transaction as invalid, txIndex=%d: %s", txIndex, err)
+			txsFilter.Set(uint(txIndex))
+			continue
+		}
+
 		// trace the first 2000 characters of RWSet only, in case it is huge
 		if logger.IsEnabledFor(logging.DEBUG) {
 			txRWSetString := txRWSet.String()
@@ -256,53 +266,70 @@ func (txmgr *CouchDBTxMgr) Commit() error {
 	defer txmgr.commitRWLock.Unlock()
 	defer func() { txmgr.updateSet = nil }()
 
-	for k, v := range txmgr.updateSet.m {
-
-		if couchdb.IsJSON(string(v.value)) {
-
-			// SaveDoc using couchdb client and use JSON format
-			rev, err := txmgr.couchDB.SaveDoc(k, "", v.value, nil)
-			if err != nil {
-				logger.Errorf("===COUCHDB=== Error during Commit(): %s\n", err.Error())
-				return err
-			}
-			if rev != "" {
-				logger.Debugf("===COUCHDB=== Saved document revision number: %s\n", rev)
-			}
+	if err := txmgr.saveUpdateSet(); err != nil {
+		logger.Errorf("===COUCHDB=== Error during Commit(): %s\n", err.Error())
+		return err
+	}
 
-		} else {
+	// Record a savepoint
+	err := txmgr.recordSavepoint()
+	if err != nil {
+		logger.Errorf("===COUCHDB=== Error during recordSavepoint: %s\n", err.Error())
+		return err
+	}
 
-			//Create an attachment structure and load the bytes
-			attachment := &couchdb.Attachment{}
-			attachment.AttachmentBytes = v.value
-			attachment.ContentType = "application/octet-stream"
-			attachment.Name = "valueBytes"
+	logger.Debugf("===COUCHDB=== Exiting CouchDBTxMgr.Commit()")
+	return nil
+}
 
-			attachments := []couchdb.Attachment{}
-			attachments = append(attachments, *attachment)
+// saveUpdateSet writes the updateSet with a single _bulk_docs request. The revisions of the
+// existing documents, which CouchDB requires for updating them, are read with a single _all_docs request
+func (txmgr *CouchDBTxMgr) saveUpdateSet() error {
+	if len(txmgr.updateSet.m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(txmgr.updateSet.m))
+	for k := range txmgr.updateSet.m {
+		keys = append(keys, k)
+	}
+	revisions, err := txmgr.couchDB.BatchRetrieveIDRevision(keys)
+	if err != nil {
+		return err
+	}
 
-			// SaveDoc using couchdb client and use attachment
-			rev, err := txmgr.couchDB.SaveDoc(k, "", nil, attachments)
-			if err != nil {
-				logger.Errorf("===COUCHDB=== Error during Commit(): %s\n", err.Error())
-				return err
-			}
-			if rev != "" {
-				logger.Debugf("===COUCHDB=== Saved document revision number: %s\n", rev)
+	docs := make([]*couchdb.CouchDoc, 0, len(keys))
+	for _, k := range keys {
+		v := txmgr.updateSet.m[k]
+		doc := &couchdb.CouchDoc{ID: k, Rev: revisions[k]}
+		switch {
+		case v.value == nil:
+			if doc.Rev == "" {
+				// nothing to delete
+				continue
 			}
-
+			doc.Deleted = true
+		case couchdb.IsJSON(string(v.value)):
+			doc.JSONValue = v.value
+		default:
+			doc.Attachments = []couchdb.Attachment{{Name: "valueBytes", ContentType: "application/octet-stream",
+				AttachmentBytes: v.value}}
 		}
-
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil
 	}
 
-	// Record a savepoint
-	err := txmgr.recordSavepoint()
+	responses, err := txmgr.couchDB.BatchUpdateDocuments(docs)
 	if err != nil {
-		logger.Errorf("===COUCHDB=== Error during recordSavepoint: %s\n", err.Error())
 		return err
 	}
-
-	logger.Debugf("===COUCHDB=== Exiting CouchDBTxMgr.Commit()")
+	for _, resp := range responses {
+		if resp.Error != "" {
+			return fmt.Errorf("Error saving document [%s]: %s %s", resp.ID, resp.Error, resp.Reason)
+		}
+		logger.Debugf("===COUCHDB=== Saved document [%s] revision number: %s\n", resp.ID, resp.Rev)
+	}
 	return nil
 }
 
@@ -354,16 +381,18 @@ func (txmgr *CouchDBTxMgr) recordSavepoint() error {
 // GetBlockNumFromSavepoint Reads the savepoint from database and returns the corresponding block number.
 // If no savepoint is found, it returns 0
 func (txmgr *CouchDBTxMgr) GetBlockNumFromSavepoint() (uint64, error) {
-	var err error
-	savepointJSON, _, err := txmgr.couchDB.ReadDoc(savepointDocID)
+	docs, err := txmgr.couchDB.BatchRetrieveDocuments([]string{savepointDocID})
 	if err != nil {
-		// TODO: differentiate between 404 and some other error code
 		logger.Errorf("====COUCHDB==== Failed to read savepoint data %s\n", err.Error())
 		return 0, err
 	}
+	// no block has been committed yet
+	if docs[0] == nil {
+		return 0, nil
+	}
 
 	savepointDoc := &couchSavepointData{}
-	err = json.Unmarshal(savepointJSON, &savepointDoc)
+	err = json.Unmarshal(docs[0].Value, &savepointDoc)
 	if err != nil {
 		logger.Errorf("====COUCHDB==== Failed to read savepoint data %s\n", err.Error())
 		return 0, err
@@ -423,15 +452,55 @@ func (txmgr *CouchDBTxMgr) getRangeScanner(namespace string, startKey string, en
 	return newKVScanner(namespace, *queryResult), nil
 }
 
-//getQuery calls the CouchDB query documents method (CouchDB _find API)
-//TODO the limit and offset are currently hard coded.  The limit should eventually be a config option
-func (txmgr *CouchDBTxMgr) getQuery(query string) (*queryScanner, error) {
+// getCommittedValues reads the values of multiple keys of a namespace with a single CouchDB request
+func (txmgr *CouchDBTxMgr) getCommittedValues(ns string, keys []string) ([][]byte, error) {
+	compositeKeys := make([]string, len(keys))
+	for i, key := range keys {
+		compositeKeys[i] = string(constructCompositeKey(ns, key))
+	}
+	docs, err := txmgr.couchDB.BatchRetrieveDocuments(compositeKeys)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(keys))
+	for i, doc := range docs {
+		if doc != nil {
+			values[i] = doc.Value
+		}
+	}
+	return values, nil
+}
 
-	//TODO - limit is currently set at 1000,  eventually this will need to be changed
-	//to reflect a config option and potentially return an exception if the threshold is exceeded
-	queryResult, _ := txmgr.couchDB.QueryDocuments(query, 1000, 0)
+//getQuery calls the CouchDB query documents method (CouchDB _find API).
+//The results are fetched lazily, one page of ledgerconfig.GetQueryPageSize() documents at a time
+func (txmgr *CouchDBTxMgr) getQuery(query string) (*queryScanner, error) {
+	jsonQuery := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(query), &jsonQuery); err != nil {
+		return nil, fmt.Errorf("Query is not a valid JSON: %s", err)
+	}
+	// a limit set in the query caps the total number of results
+	limit := 0
+	if l, ok := jsonQuery["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	scanner := newQueryScanner(txmgr.couchDB, query, ledgerconfig.GetQueryPageSize(), limit)
+	if err := scanner.fetchNextPage(); err != nil {
+		return nil, err
+	}
+	return scanner, nil
+}
 
-	return newQueryScanner(*queryResult), nil
+// CreateIndexes creates the CouchDB indexes defined by a chaincode. The indexDefinitions
+// map the names of the definition files to their contents
+func (txmgr *CouchDBTxMgr) CreateIndexes(chaincodeName string, indexDefinitions map[string][]byte) error {
+	for name, definition := range indexDefinitions {
+		resp, err := txmgr.couchDB.CreateIndex(string(definition))
+		if err != nil {
+			return fmt.Errorf("Error creating index [%s] of chaincode [%s]: %s", name, chaincodeName, err)
+		}
+		logger.Infof("Index [%s] of chaincode [%s] is %s", resp.Name, chaincodeName, resp.Result)
+	}
+	return nil
 }
 
 func encodeValue(value []byte, version uint64) []byte {
@@ -509,8 +578,15 @@ func (scanner *kvScanner) close() {
 }
 
 type queryScanner struct {
-	cursor  int
-	results []couchdb.QueryResult
+	cursor   int
+	results  []couchdb.QueryResult
+	couchDB  *couchdb.CouchDBConnectionDef
+	query    string
+	pageSize int
+	limit    int
+	fetched  int
+	bookmark string
+	done     bool
 }
 
 type queryRecord struct {
@@ -520,8 +596,27 @@ type queryRecord struct {
 	record    []byte
 }
 
-func newQueryScanner(queryResults []couchdb.QueryResult) *queryScanner {
-	return &queryScanner{-1, queryResults}
+func newQueryScanner(couchDB *couchdb.CouchDBConnectionDef, query string, pageSize int, limit int) *queryScanner {
+	return &queryScanner{cursor: -1, couchDB: couchDB, query: query, pageSize: pageSize, limit: limit}
+}
+
+// fetchNextPage replaces the results with the next page of the query
+func (scanner *queryScanner) fetchNextPage() error {
+	pageSize := scanner.pageSize
+	if scanner.limit > 0 && scanner.limit-scanner.fetched < pageSize {
+		pageSize = scanner.limit - scanner.fetched
+	}
+	results, bookmark, err := scanner.couchDB.QueryDocumentsWithBookmark(scanner.query, pageSize, scanner.bookmark)
+	if err != nil {
+		return err
+	}
+	scanner.results = results
+	scanner.cursor = -1
+	scanner.fetched += len(results)
+	scanner.bookmark = bookmark
+	// a short page, a missing bookmark (CouchDB before 2.1) or the limit ends the query
+	scanner.done = len(results) < pageSize || bookmark == "" || (scanner.limit > 0 && scanner.fetched >= scanner.limit)
+	return nil
 }
 
 func (scanner *queryScanner) next() (*queryRecord, error) {
@@ -529,7 +624,13 @@ func (scanner *queryScanner) next() (*queryRecord, error) {
 	scanner.cursor++
 
 	if scanner.cursor >= len(scanner.results) {
-		return nil, nil
+		if scanner.done {
+			return nil, nil
+		}
+		if err := scanner.fetchNextPage(); err != nil {
+			return nil, err
+		}
+		return scanner.next()
 	}
 
 	selectedValue := scanner.results[scanner.cursor]
@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package couchdbtxmgmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+	"github.com/spf13/viper"
+)
+
+// fakeCouchDB serves the subset of the CouchDB API used for bulk updates and queries
+type fakeCouchDB struct {
+	docs     map[string]map[string]interface{}
+	requests []string
+}
+
+func (f *fakeCouchDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	body := make(map[string]interface{})
+	json.NewDecoder(r.Body).Decode(&body)
+	var resp interface{}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/_all_docs"):
+		var rows []interface{}
+		for _, k := range body["keys"].([]interface{}) {
+			if doc, ok := f.docs[k.(string)]; ok {
+				rows = append(rows, map[string]interface{}{"id": k, "key": k, "value": map[string]interface{}{"rev": doc["_rev"]}, "doc": doc})
+			} else {
+				rows = append(rows, map[string]interface{}{"key": k, "error": "not_found"})
+			}
+		}
+		resp = map[string]interface{}{"rows": rows}
+	case strings.HasSuffix(r.URL.Path, "/_bulk_docs"):
+		var results []interface{}
+		for _, d := range body["docs"].([]interface{}) {
+			doc := d.(map[string]interface{})
+			id := doc["_id"].(string)
+			if existing, ok := f.docs[id]; (ok && existing["_rev"] != doc["_rev"]) || strings.HasSuffix(id, "conflict") {
+				results = append(results, map[string]interface{}{"id": id, "error": "conflict", "reason": "Document update conflict."})
+				continue
+			}
+			rev := fmt.Sprintf("rev-%d", len(f.requests))
+			if doc["_deleted"] == true {
+				delete(f.docs, id)
+			} else {
+				doc["_rev"] = rev
+				f.docs[id] = doc
+			}
+			results = append(results, map[string]interface{}{"id": id, "ok": true, "rev": rev})
+		}
+		resp = results
+	case strings.HasSuffix(r.URL.Path, "/_find"):
+		// the bookmark is the number of documents already returned
+		start := 0
+		if b, ok := body["bookmark"].(string); ok {
+			fmt.Sscanf(b, "%d", &start)
+		}
+		limit := int(body["limit"].(float64))
+		var docs []interface{}
+		for i := start; i < start+limit && i < len(f.docs); i++ {
+			docs = append(docs, f.docs[fmt.Sprintf("ns\x00key%d", i)])
+		}
+		resp = map[string]interface{}{"docs": docs, "bookmark": fmt.Sprintf("%d", start+len(docs))}
+	default:
+		http.Error(w, `{"error":"not_found","reason":"missing"}`, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newTestTxMgrWithFakeCouchDB(t *testing.T) (*CouchDBTxMgr, *fakeCouchDB, func()) {
+	fake := &fakeCouchDB{docs: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	serverURL, _ := url.Parse(server.URL)
+	couchDB, err := couchdb.CreateConnectionDefinition(serverURL.Host, "testdb", "", "")
+	testutil.AssertNoError(t, err, "")
+	return &CouchDBTxMgr{couchDB: couchDB}, fake, server.Close
+}
+
+func TestSaveUpdateSetInBatch(t *testing.T) {
+	txMgr, fake, cleanup := newTestTxMgrWithFakeCouchDB(t)
+	defer cleanup()
+
+	txMgr.updateSet = newUpdateSet()
+	txMgr.updateSet.add(constructCompositeKey("ns", "key1"), &versionedValue{[]byte(`{"owner":"bob"}`), version.NewHeight(1, 1)})
+	txMgr.updateSet.add(constructCompositeKey("ns", "key2"), &versionedValue{[]byte("binary"), version.NewHeight(1, 2)})
+	txMgr.updateSet.add(constructCompositeKey("ns", "key3"), &versionedValue{nil, version.NewHeight(1, 3)})
+	testutil.AssertNoError(t, txMgr.saveUpdateSet(), "")
+	// the revisions are read and the documents saved with one request each
+	testutil.AssertEquals(t, fake.requests, []string{"POST /testdb/_all_docs", "POST /testdb/_bulk_docs"})
+	testutil.AssertEquals(t, len(fake.docs), 2)
+
+	values, err := txMgr.getCommittedValues("ns", []string{"key1", "key2", "key3"})
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNotNil(t, values[0])
+	testutil.AssertEquals(t, values[1], []byte("binary"))
+	testutil.AssertNil(t, values[2])
+
+	// updates and deletes of existing documents carry their revisions
+	txMgr.updateSet = newUpdateSet()
+	txMgr.updateSet.add(constructCompositeKey("ns", "key1"), &versionedValue{[]byte(`{"owner":"alice"}`), version.NewHeight(2, 1)})
+	txMgr.updateSet.add(constructCompositeKey("ns", "key2"), &versionedValue{nil, version.NewHeight(2, 2)})
+	testutil.AssertNoError(t, txMgr.saveUpdateSet(), "")
+	testutil.AssertEquals(t, len(fake.docs), 1)
+	testutil.AssertEquals(t, fake.docs["ns\x00key1"]["owner"], "alice")
+
+	// an update rejected by CouchDB is reported
+	txMgr.updateSet = newUpdateSet()
+	txMgr.updateSet.add(constructCompositeKey("ns", "conflict"), &versionedValue{[]byte(`{"owner":"tom"}`), version.NewHeight(3, 1)})
+	testutil.AssertError(t, txMgr.saveUpdateSet(), "Expected an error for a rejected update")
+}
+
+func TestQueryPagination(t *testing.T) {
+	txMgr, fake, cleanup := newTestTxMgrWithFakeCouchDB(t)
+	defer cleanup()
+	for i := 0; i < 7; i++ {
+		id := fmt.Sprintf("ns\x00key%d", i)
+		fake.docs[id] = map[string]interface{}{"_id": id, "owner": "bob"}
+	}
+
+	testQueryPages(t, txMgr, fake, 3, `{"selector":{"owner":"bob"}}`, 7, 3)
+	testQueryPages(t, txMgr, fake, 7, `{"selector":{"owner":"bob"}}`, 7, 2)
+	testQueryPages(t, txMgr, fake, 3, `{"selector":{"owner":"bob"},"limit":4}`, 4, 2)
+	testQueryPages(t, txMgr, fake, 10, `{"selector":{"owner":"bob"},"limit":4}`, 4, 1)
+
+	_, err := txMgr.getQuery("not json")
+	testutil.AssertError(t, err, "Expected an error for an invalid query")
+}
+
+func testQueryPages(t *testing.T, txMgr *CouchDBTxMgr, fake *fakeCouchDB, pageSize int, query string, expectedResults int, expectedRequests int) {
+	fake.requests = nil
+	viper.Set("ledger.state.couchDBConfig.queryPageSize", pageSize)
+	defer viper.Set("ledger.state.couchDBConfig.queryPageSize", 1000)
+	scanner, err := txMgr.getQuery(query)
+	testutil.AssertNoError(t, err, "")
+	var keys []string
+	for {
+		record, err := scanner.next()
+		testutil.AssertNoError(t, err, "")
+		if record == nil {
+			break
+		}
+		keys = append(keys, record.key)
+	}
+	testutil.AssertEquals(t, len(keys), expectedResults)
+	for i, key := range keys {
+		testutil.AssertEquals(t, key, fmt.Sprintf("key%d", i))
+	}
+	testutil.AssertEquals(t, len(fake.requests), expectedRequests)
+}
@@ -22,8 +22,13 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/ledger/kvrwset"
 )
 
+// protoMsgVersion is the version of the encoding of the read-write sets serialized as a
+// `kvrwset.TxReadWriteSet` protobuf message, carried in the version field of the message
+const protoMsgVersion = 1
+
 // KVRead - a tuple of key and its version at the time of transaction simulation
 type KVRead struct {
 	Key     string
@@ -36,16 +41,18 @@ func NewKVRead(key string, version *version.Height) *KVRead {
 }
 
 // KVWrite - a tuple of key and it's value that a transaction wants to set during simulation.
-// In addition, IsDelete is set to true iff the operation performed on the key is a delete operation
+// In addition, IsDelete is set to true iff the operation performed on the key is a delete operation.
+// ValueHash is set instead of Value when a large value has been replaced by its hash
 type KVWrite struct {
-	Key      string
-	IsDelete bool
-	Value    []byte
+	Key       string
+	IsDelete  bool
+	Value     []byte
+	ValueHash []byte
 }
 
 // NewKVWrite constructs a new `KVWrite`
 func NewKVWrite(key string, value []byte) *KVWrite {
-	return &KVWrite{key, value == nil, value}
+	return &KVWrite{key, value == nil, value, nil}
 }
 
 // SetValue sets the new value for the key
@@ -54,11 +61,22 @@ func (w *KVWrite) SetValue(value []byte) {
 	w.IsDelete = value == nil
 }
 
+// RangeQueryInfo captures a range query executed by a transaction: the range, whether
+// the results were read till the end of the range and the keys and versions read.
+// It is used to detect phantom reads at commit time
+type RangeQueryInfo struct {
+	StartKey     string
+	EndKey       string
+	ItrExhausted bool
+	Results      []*KVRead
+}
+
 // NsReadWriteSet - a collection of all the reads and writes that belong to a common namespace
 type NsReadWriteSet struct {
-	NameSpace string
-	Reads     []*KVRead
-	Writes    []*KVWrite
+	NameSpace        string
+	Reads            []*KVRead
+	Writes           []*KVWrite
+	RangeQueriesInfo []*RangeQueryInfo
 }
 
 // TxReadWriteSet - a collection of all the reads and writes collected as a result of a transaction simulation
@@ -66,162 +84,92 @@ type TxReadWriteSet struct {
 	NsRWs []*NsReadWriteSet
 }
 
-// Marshal serializes a `KVRead`
-func (r *KVRead) Marshal(buf *proto.Buffer) error {
-	if err := buf.EncodeStringBytes(r.Key); err != nil {
-		return err
-	}
-	versionBytes := []byte{}
-	if r.Version != nil {
-		versionBytes = r.Version.ToBytes()
-	}
-	if err := buf.EncodeRawBytes(versionBytes); err != nil {
-		return err
-	}
-	return nil
-}
-
-// Unmarshal deserializes a `KVRead`
-func (r *KVRead) Unmarshal(buf *proto.Buffer) error {
-	var err error
-	var versionBytes []byte
-	if r.Key, err = buf.DecodeStringBytes(); err != nil {
-		return err
-	}
-	if versionBytes, err = buf.DecodeRawBytes(false); err != nil {
-		return err
-	}
-	if len(versionBytes) > 0 {
-		r.Version, _ = version.NewHeightFromBytes(versionBytes)
-	}
-	return nil
+// Marshal serializes a `TxReadWriteSet` as a `kvrwset.TxReadWriteSet` protobuf message
+func (txRW *TxReadWriteSet) Marshal() ([]byte, error) {
+	return proto.Marshal(txRW.toProtoMsg())
 }
 
-// Marshal serializes a `KVWrite`
-func (w *KVWrite) Marshal(buf *proto.Buffer) error {
-	var err error
-	if err = buf.EncodeStringBytes(w.Key); err != nil {
-		return err
-	}
-	deleteMarker := 0
-	if w.IsDelete {
-		deleteMarker = 1
-	}
-	if err = buf.EncodeVarint(uint64(deleteMarker)); err != nil {
-		return err
-	}
-	if deleteMarker == 0 {
-		if err = buf.EncodeRawBytes(w.Value); err != nil {
-			return err
-		}
+// Unmarshal deserializes a `TxReadWriteSet`. Read-write sets written in the legacy encoding,
+// that predates the protobuf message, are still accepted
+func (txRW *TxReadWriteSet) Unmarshal(b []byte) error {
+	if isLegacyEncoding(b) {
+		logger.Debugf("Read-write set is not a versioned protobuf message, decoding it as legacy")
+		return txRW.unmarshalLegacy(b)
 	}
-	return nil
-}
 
-// Unmarshal deserializes a `KVWrite`
-func (w *KVWrite) Unmarshal(buf *proto.Buffer) error {
-	var err error
-	if w.Key, err = buf.DecodeStringBytes(); err != nil {
-		return err
-	}
-	var deleteMarker uint64
-	if deleteMarker, err = buf.DecodeVarint(); err != nil {
+	protoMsg := &kvrwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(b, protoMsg); err != nil {
 		return err
 	}
-	if deleteMarker == 1 {
-		w.IsDelete = true
-		return nil
-	}
-	if w.Value, err = buf.DecodeRawBytes(false); err != nil {
-		return err
+	if protoMsg.Version != protoMsgVersion {
+		return fmt.Errorf("Unknown read-write set encoding version [%d]", protoMsg.Version)
 	}
+	txRW.setFromProtoMsg(protoMsg)
 	return nil
 }
 
-// Marshal serializes a `NsReadWriteSet`
-func (nsRW *NsReadWriteSet) Marshal(buf *proto.Buffer) error {
-	var err error
-	if err = buf.EncodeStringBytes(nsRW.NameSpace); err != nil {
-		return err
-	}
-	if err = buf.EncodeVarint(uint64(len(nsRW.Reads))); err != nil {
-		return err
-	}
-	for i := 0; i < len(nsRW.Reads); i++ {
-		nsRW.Reads[i].Marshal(buf)
-	}
-	if err = buf.EncodeVarint(uint64(len(nsRW.Writes))); err != nil {
-		return err
-	}
-	for i := 0; i < len(nsRW.Writes); i++ {
-		nsRW.Writes[i].Marshal(buf)
+func (txRW *TxReadWriteSet) toProtoMsg() *kvrwset.TxReadWriteSet {
+	protoMsg := &kvrwset.TxReadWriteSet{Version: protoMsgVersion}
+	for _, nsRW := range txRW.NsRWs {
+		protoNsRW := &kvrwset.NsReadWriteSet{Namespace: nsRW.NameSpace}
+		for _, r := range nsRW.Reads {
+			protoNsRW.Reads = append(protoNsRW.Reads, r.toProtoMsg())
+		}
+		for _, w := range nsRW.Writes {
+			protoNsRW.Writes = append(protoNsRW.Writes,
+				&kvrwset.KVWrite{Key: w.Key, IsDelete: w.IsDelete, Value: w.Value, ValueHash: w.ValueHash})
+		}
+		for _, rqi := range nsRW.RangeQueriesInfo {
+			protoRQI := &kvrwset.RangeQueryInfo{StartKey: rqi.StartKey, EndKey: rqi.EndKey, ItrExhausted: rqi.ItrExhausted}
+			for _, r := range rqi.Results {
+				protoRQI.Results = append(protoRQI.Results, r.toProtoMsg())
+			}
+			protoNsRW.RangeQueriesInfo = append(protoNsRW.RangeQueriesInfo, protoRQI)
+		}
+		protoMsg.NsRWs = append(protoMsg.NsRWs, protoNsRW)
 	}
-	return nil
+	return protoMsg
 }
 
-// Unmarshal deserializes a `NsReadWriteSet`
-func (nsRW *NsReadWriteSet) Unmarshal(buf *proto.Buffer) error {
-	var err error
-	if nsRW.NameSpace, err = buf.DecodeStringBytes(); err != nil {
-		return err
-	}
-	var numReads uint64
-	if numReads, err = buf.DecodeVarint(); err != nil {
-		return err
-	}
-	for i := 0; i < int(numReads); i++ {
-		r := &KVRead{}
-		if err = r.Unmarshal(buf); err != nil {
-			return err
+func (txRW *TxReadWriteSet) setFromProtoMsg(protoMsg *kvrwset.TxReadWriteSet) {
+	for _, protoNsRW := range protoMsg.NsRWs {
+		nsRW := &NsReadWriteSet{NameSpace: protoNsRW.Namespace}
+		for _, protoRead := range protoNsRW.Reads {
+			nsRW.Reads = append(nsRW.Reads, newKVReadFromProtoMsg(protoRead))
 		}
-		nsRW.Reads = append(nsRW.Reads, r)
-	}
-
-	var numWrites uint64
-	if numWrites, err = buf.DecodeVarint(); err != nil {
-		return err
-	}
-	for i := 0; i < int(numWrites); i++ {
-		w := &KVWrite{}
-		if err = w.Unmarshal(buf); err != nil {
-			return err
+		for _, protoWrite := range protoNsRW.Writes {
+			w := &KVWrite{protoWrite.Key, protoWrite.IsDelete, protoWrite.Value, protoWrite.ValueHash}
+			// protobuf does not tell an empty value from a missing one
+			if !w.IsDelete && w.Value == nil && w.ValueHash == nil {
+				w.Value = []byte{}
+			}
+			nsRW.Writes = append(nsRW.Writes, w)
+		}
+		for _, protoRQI := range protoNsRW.RangeQueriesInfo {
+			rqi := &RangeQueryInfo{StartKey: protoRQI.StartKey, EndKey: protoRQI.EndKey, ItrExhausted: protoRQI.ItrExhausted}
+			for _, protoRead := range protoRQI.Results {
+				rqi.Results = append(rqi.Results, newKVReadFromProtoMsg(protoRead))
+			}
+			nsRW.RangeQueriesInfo = append(nsRW.RangeQueriesInfo, rqi)
 		}
-		nsRW.Writes = append(nsRW.Writes, w)
+		txRW.NsRWs = append(txRW.NsRWs, nsRW)
 	}
-	return nil
 }
 
-// Marshal serializes a `TxReadWriteSet`
-func (txRW *TxReadWriteSet) Marshal() ([]byte, error) {
-	buf := proto.NewBuffer(nil)
-	var err error
-	if err = buf.EncodeVarint(uint64(len(txRW.NsRWs))); err != nil {
-		return nil, err
-	}
-	for i := 0; i < len(txRW.NsRWs); i++ {
-		if err = txRW.NsRWs[i].Marshal(buf); err != nil {
-			return nil, err
-		}
+func (r *KVRead) toProtoMsg() *kvrwset.KVRead {
+	protoRead := &kvrwset.KVRead{Key: r.Key}
+	if r.Version != nil {
+		protoRead.Version = &kvrwset.Version{BlockNum: r.Version.BlockNum, TxNum: r.Version.TxNum}
 	}
-	return buf.Bytes(), nil
+	return protoRead
 }
 
-// Unmarshal deserializes a `TxReadWriteSet`
-func (txRW *TxReadWriteSet) Unmarshal(b []byte) error {
-	buf := proto.NewBuffer(b)
-	var err error
-	var numEntries uint64
-	if numEntries, err = buf.DecodeVarint(); err != nil {
-		return err
+func newKVReadFromProtoMsg(protoRead *kvrwset.KVRead) *KVRead {
+	r := &KVRead{Key: protoRead.Key}
+	if protoRead.Version != nil {
+		r.Version = version.NewHeight(protoRead.Version.BlockNum, protoRead.Version.TxNum)
 	}
-	for i := 0; i < int(numEntries); i++ {
-		nsRW := &NsReadWriteSet{}
-		if err = nsRW.Unmarshal(buf); err != nil {
-			return err
-		}
-		txRW.NsRWs = append(txRW.NsRWs, nsRW)
-	}
-	return nil
+	return r
 }
 
 // String prints a `KVRead`
@@ -231,9 +179,17 @@ func (r *KVRead) String() string {
 
 // String prints a `KVWrite`
 func (w *KVWrite) String() string {
+	if w.ValueHash != nil {
+		return fmt.Sprintf("%s=hash[%x]", w.Key, w.ValueHash)
+	}
 	return fmt.Sprintf("%s=[%#v]", w.Key, w.Value)
 }
 
+// String prints a `RangeQueryInfo`
+func (rqi *RangeQueryInfo) String() string {
+	return fmt.Sprintf("StartKey=%s, EndKey=%s, ItrExhausted=%t, Results=%s", rqi.StartKey, rqi.EndKey, rqi.ItrExhausted, rqi.Results)
+}
+
 // String prints a `NsReadWriteSet`
 func (nsRW *NsReadWriteSet) String() string {
 	var buffer bytes.Buffer
@@ -247,6 +203,11 @@ func (nsRW *NsReadWriteSet) String() string {
 		buffer.WriteString(w.String())
 		buffer.WriteString(",")
 	}
+	buffer.WriteString("RangeQueriesInfo~")
+	for _, rqi := range nsRW.RangeQueriesInfo {
+		buffer.WriteString(rqi.String())
+		buffer.WriteString(",")
+	}
 	return buffer.String()
 }
 
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rwset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/protos/ledger/kvrwset"
+)
+
+// HashLargeValues replaces the values of the writes that are larger than threshold bytes by
+// their hashes, so that only the hashes are endorsed. The replaced values are returned as a
+// serialized `kvrwset.HashedValues` message, to be carried along with the transaction, or nil
+// when no value has been replaced
+func (txRW *TxReadWriteSet) HashLargeValues(threshold int) ([]byte, error) {
+	hashedValues := &kvrwset.HashedValues{}
+	for _, nsRW := range txRW.NsRWs {
+		for _, w := range nsRW.Writes {
+			if w.IsDelete || len(w.Value) <= threshold {
+				continue
+			}
+			w.ValueHash = util.ComputeCryptoHash(w.Value)
+			hashedValues.Values = append(hashedValues.Values, &kvrwset.HashedValue{Hash: w.ValueHash, Value: w.Value})
+			w.Value = nil
+		}
+	}
+	if len(hashedValues.Values) == 0 {
+		return nil, nil
+	}
+	return proto.Marshal(hashedValues)
+}
+
+// ResolveHashedValues puts back the values of the writes that were replaced by their hashes.
+// The values are taken from a serialized `kvrwset.HashedValues` message and each of them is
+// checked against its hash. An error is returned if a value does not match its hash or if the
+// value of a hashed write is missing
+func (txRW *TxReadWriteSet) ResolveHashedValues(hashedValuesBytes []byte) error {
+	hashedValues := &kvrwset.HashedValues{}
+	if err := proto.Unmarshal(hashedValuesBytes, hashedValues); err != nil {
+		return fmt.Errorf("Error unmarshalling hashed values: %s", err)
+	}
+	values := make(map[string][]byte)
+	for _, hashedValue := range hashedValues.Values {
+		if !bytes.Equal(util.ComputeCryptoHash(hashedValue.Value), hashedValue.Hash) {
+			return fmt.Errorf("Hashed value does not match its hash [%x]", hashedValue.Hash)
+		}
+		values[string(hashedValue.Hash)] = hashedValue.Value
+	}
+	for _, nsRW := range txRW.NsRWs {
+		for _, w := range nsRW.Writes {
+			if w.ValueHash == nil {
+				continue
+			}
+			value, ok := values[string(w.ValueHash)]
+			if !ok {
+				return fmt.Errorf("Value with hash [%x] for key [%s:%s] is missing", w.ValueHash, nsRW.NameSpace, w.Key)
+			}
+			w.Value = value
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package rwset
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	logging "github.com/op/go-logging"
@@ -26,12 +27,13 @@ import (
 var logger = logging.MustGetLogger("rwset")
 
 type nsRWs struct {
-	readMap  map[string]*KVRead
-	writeMap map[string]*KVWrite
+	readMap          map[string]*KVRead
+	writeMap         map[string]*KVWrite
+	rangeQueriesInfo []*RangeQueryInfo
 }
 
 func newNsRWs() *nsRWs {
-	return &nsRWs{make(map[string]*KVRead), make(map[string]*KVWrite)}
+	return &nsRWs{make(map[string]*KVRead), make(map[string]*KVWrite), nil}
 }
 
 // RWSet maintains the read-write set
@@ -56,6 +58,13 @@ func (rws *RWSet) AddToWriteSet(ns string, key string, value []byte) {
 	nsRWs.writeMap[key] = NewKVWrite(key, value)
 }
 
+// AddToRangeQuerySet adds a range query info for performing phantom read validation.
+// The results are recorded in the info as the range query iterator is consumed
+func (rws *RWSet) AddToRangeQuerySet(ns string, rqi *RangeQueryInfo) {
+	nsRWs := rws.getOrCreateNsRW(ns)
+	nsRWs.rangeQueriesInfo = append(nsRWs.rangeQueriesInfo, rqi)
+}
+
 // GetFromWriteSet return the value of a key from the write-set
 func (rws *RWSet) GetFromWriteSet(ns string, key string) ([]byte, bool) {
 	nsRWs, ok := rws.rwMap[ns]
@@ -70,6 +79,44 @@ func (rws *RWSet) GetFromWriteSet(ns string, key string) ([]byte, bool) {
 	return value, ok
 }
 
+// GetWritesInRange returns the writes to the keys of a namespace that fall in the range [startKey, endKey), sorted by key
+func (rws *RWSet) GetWritesInRange(ns string, startKey string, endKey string) []*KVWrite {
+	nsRWs, ok := rws.rwMap[ns]
+	if !ok {
+		return nil
+	}
+	return SelectWritesInRange(nsRWs.writeMap, startKey, endKey)
+}
+
+// HasWrites returns true if a key has been written or deleted
+func (rws *RWSet) HasWrites() bool {
+	for _, nsRWs := range rws.rwMap {
+		if len(nsRWs.writeMap) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectWritesInRange returns the writes of a write map whose keys fall in the range [startKey, endKey),
+// sorted by key. As for range queries, an empty endKey refers to the last key
+func SelectWritesInRange(writeMap map[string]*KVWrite, startKey string, endKey string) []*KVWrite {
+	var writes []*KVWrite
+	for key, kvWrite := range writeMap {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			writes = append(writes, kvWrite)
+		}
+	}
+	sort.Sort(kvWritesByKey(writes))
+	return writes
+}
+
+type kvWritesByKey []*KVWrite
+
+func (w kvWritesByKey) Len() int           { return len(w) }
+func (w kvWritesByKey) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
+func (w kvWritesByKey) Less(i, j int) bool { return w[i].Key < w[j].Key }
+
 // GetTxReadWriteSet returns the read-write set in the form that can be serialized
 func (rws *RWSet) GetTxReadWriteSet() *TxReadWriteSet {
 	txRWSet := &TxReadWriteSet{}
@@ -90,7 +137,7 @@ func (rws *RWSet) GetTxReadWriteSet() *TxReadWriteSet {
 		for _, key := range sortedWriteKeys {
 			writes = append(writes, nsReadWriteMap.writeMap[key])
 		}
-		nsRWs := &NsReadWriteSet{NameSpace: ns, Reads: reads, Writes: writes}
+		nsRWs := &NsReadWriteSet{NameSpace: ns, Reads: reads, Writes: writes, RangeQueriesInfo: nsReadWriteMap.rangeQueriesInfo}
 		txRWSet.NsRWs = append(txRWSet.NsRWs, nsRWs)
 	}
 	return txRWSet
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rwset
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+// The functions in this file decode the hand-written binary encoding that was used for
+// the read-write sets before the `kvrwset.TxReadWriteSet` protobuf message. They are kept
+// so that the blocks already in existing ledgers can still be read. The legacy encoding
+// carries no version and no range query info
+
+// versionFieldTag is the first byte of a marshaled `kvrwset.TxReadWriteSet`: the key of
+// its version field, number 1 with the varint wire type
+const versionFieldTag = 1<<3 | proto.WireVarint
+
+// isLegacyEncoding tells whether b is a read-write set in the legacy encoding. A marshaled
+// `kvrwset.TxReadWriteSet` starts with its version field, as protobuf encodes the fields in
+// the order of their numbers, while a legacy read-write set starts with the varint count of
+// its namespaces. The encodings only share their first bytes for legacy read-write sets of 8
+// namespaces, so those are told apart by decoding the whole read-write set as legacy
+func isLegacyEncoding(b []byte) bool {
+	if len(b) < 2 || b[0] != versionFieldTag {
+		return true
+	}
+	buf := proto.NewBuffer(b)
+	if err := (&TxReadWriteSet{}).decodeLegacy(buf); err != nil {
+		return false
+	}
+	// the legacy decoding has to consume all the bytes
+	_, err := buf.DecodeVarint()
+	return err == io.ErrUnexpectedEOF
+}
+
+func (txRW *TxReadWriteSet) unmarshalLegacy(b []byte) error {
+	return txRW.decodeLegacy(proto.NewBuffer(b))
+}
+
+func (txRW *TxReadWriteSet) decodeLegacy(buf *proto.Buffer) error {
+	var err error
+	var numEntries uint64
+	if numEntries, err = buf.DecodeVarint(); err != nil {
+		return err
+	}
+	nsRWs := []*NsReadWriteSet{}
+	for i := 0; i < int(numEntries); i++ {
+		nsRW := &NsReadWriteSet{}
+		if err = nsRW.unmarshalLegacy(buf); err != nil {
+			return err
+		}
+		nsRWs = append(nsRWs, nsRW)
+	}
+	txRW.NsRWs = append(txRW.NsRWs, nsRWs...)
+	return nil
+}
+
+func (nsRW *NsReadWriteSet) unmarshalLegacy(buf *proto.Buffer) error {
+	var err error
+	if nsRW.NameSpace, err = buf.DecodeStringBytes(); err != nil {
+		return err
+	}
+	var numReads uint64
+	if numReads, err = buf.DecodeVarint(); err != nil {
+		return err
+	}
+	for i := 0; i < int(numReads); i++ {
+		r := &KVRead{}
+		if err = r.unmarshalLegacy(buf); err != nil {
+			return err
+		}
+		nsRW.Reads = append(nsRW.Reads, r)
+	}
+
+	var numWrites uint64
+	if numWrites, err = buf.DecodeVarint(); err != nil {
+		return err
+	}
+	for i := 0; i < int(numWrites); i++ {
+		w := &KVWrite{}
+		if err = w.unmarshalLegacy(buf); err != nil {
+			return err
+		}
+		nsRW.Writes = append(nsRW.Writes, w)
+	}
+	return nil
+}
+
+func (r *KVRead) unmarshalLegacy(buf *proto.Buffer) error {
+	var err error
+	var versionBytes []byte
+	if r.Key, err = buf.DecodeStringBytes(); err != nil {
+		return err
+	}
+	if versionBytes, err = buf.DecodeRawBytes(false); err != nil {
+		return err
+	}
+	if len(versionBytes) > 0 {
+		if !isLegacyHeight(versionBytes) {
+			return fmt.Errorf("Invalid version bytes for key [%s] in legacy read-write set", r.Key)
+		}
+		r.Version, _ = version.NewHeightFromBytes(versionBytes)
+	}
+	return nil
+}
+
+// isLegacyHeight tells whether b is exactly the serialized block and tx numbers of a height,
+// each one made of a byte holding the count of the bytes of the number that follow it
+func isLegacyHeight(b []byte) bool {
+	for i := 0; i < 2; i++ {
+		if len(b) == 0 || b[0] > 8 || len(b) < int(b[0])+1 {
+			return false
+		}
+		b = b[b[0]+1:]
+	}
+	return len(b) == 0
+}
+
+func (w *KVWrite) unmarshalLegacy(buf *proto.Buffer) error {
+	var err error
+	if w.Key, err = buf.DecodeStringBytes(); err != nil {
+		return err
+	}
+	var deleteMarker uint64
+	if deleteMarker, err = buf.DecodeVarint(); err != nil {
+		return err
+	}
+	if deleteMarker == 1 {
+		w.IsDelete = true
+		return nil
+	}
+	if w.Value, err = buf.DecodeRawBytes(false); err != nil {
+		return err
+	}
+	return nil
+}
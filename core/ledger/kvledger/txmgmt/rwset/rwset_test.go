@@ -19,15 +19,18 @@ package rwset
 import (
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/ledger/kvrwset"
 )
 
 func TestNilTxRWSet(t *testing.T) {
 	txRW := &TxReadWriteSet{}
 	nsRW1 := &NsReadWriteSet{"ns1",
 		[]*KVRead{&KVRead{"key1", nil}},
-		[]*KVWrite{&KVWrite{"key1", false, []byte("value1")}}}
+		[]*KVWrite{&KVWrite{"key1", false, []byte("value1"), nil}},
+		nil}
 	txRW.NsRWs = append(txRW.NsRWs, nsRW1)
 	b, err := txRW.Marshal()
 	testutil.AssertNoError(t, err, "Error while marshalling changeset")
@@ -43,15 +46,18 @@ func TestTxRWSetMarshalUnmarshal(t *testing.T) {
 	txRW := &TxReadWriteSet{}
 	nsRW1 := &NsReadWriteSet{"ns1",
 		[]*KVRead{&KVRead{"key1", version.NewHeight(1, 1)}},
-		[]*KVWrite{&KVWrite{"key2", false, []byte("value2")}}}
+		[]*KVWrite{&KVWrite{"key2", false, []byte("value2"), nil}},
+		[]*RangeQueryInfo{&RangeQueryInfo{"key1", "key5", true, []*KVRead{&KVRead{"key1", version.NewHeight(1, 1)}, &KVRead{"key3", version.NewHeight(1, 2)}}}}}
 
 	nsRW2 := &NsReadWriteSet{"ns2",
 		[]*KVRead{&KVRead{"key3", version.NewHeight(1, 2)}},
-		[]*KVWrite{&KVWrite{"key4", true, nil}}}
+		[]*KVWrite{&KVWrite{"key4", true, nil, nil}},
+		[]*RangeQueryInfo{&RangeQueryInfo{"key3", "", false, []*KVRead{&KVRead{"key3", version.NewHeight(1, 2)}}}}}
 
 	nsRW3 := &NsReadWriteSet{"ns3",
 		[]*KVRead{&KVRead{"key5", version.NewHeight(1, 3)}},
-		[]*KVWrite{&KVWrite{"key6", false, []byte("value6")}, &KVWrite{"key7", false, []byte("value7")}}}
+		[]*KVWrite{&KVWrite{"key6", false, []byte("value6"), nil}, &KVWrite{"key7", false, []byte("value7"), nil}},
+		nil}
 
 	txRW.NsRWs = append(txRW.NsRWs, nsRW1, nsRW2, nsRW3)
 
@@ -64,3 +70,163 @@ func TestTxRWSetMarshalUnmarshal(t *testing.T) {
 	t.Logf("Unmarshalled changeset = %#+v", deserializedRWSet.NsRWs[0].Writes[0].IsDelete)
 	testutil.AssertEquals(t, deserializedRWSet, txRW)
 }
+
+func TestUnmarshalLegacy(t *testing.T) {
+	txRW := &TxReadWriteSet{}
+	nsRW1 := &NsReadWriteSet{"ns1",
+		[]*KVRead{&KVRead{"key1", version.NewHeight(1, 1)}, &KVRead{"key2", nil}},
+		[]*KVWrite{&KVWrite{"key2", false, []byte("value2"), nil}, &KVWrite{"key3", true, nil, nil}},
+		nil}
+	nsRW2 := &NsReadWriteSet{"ns2",
+		[]*KVRead{&KVRead{"key4", version.NewHeight(1, 2)}},
+		nil,
+		nil}
+	txRW.NsRWs = append(txRW.NsRWs, nsRW1, nsRW2)
+
+	deserializedRWSet := &TxReadWriteSet{}
+	err := deserializedRWSet.Unmarshal(marshalLegacy(txRW))
+	testutil.AssertNoError(t, err, "Error while unmarshalling legacy changeset")
+	testutil.AssertEquals(t, deserializedRWSet, txRW)
+}
+
+func TestUnmarshalLegacyParsableAsProto(t *testing.T) {
+	// the legacy encoding of this read-write set is also a valid protobuf
+	// message with version 1, that Unmarshal must not decode as such
+	txRW := &TxReadWriteSet{}
+	for _, ns := range []struct {
+		name   string
+		writes []*KVWrite
+	}{
+		{"a", nil},
+		{"\x1b>", []*KVWrite{&KVWrite{"21", false, []byte("H&"), nil}}},
+		{"\x12@x", []*KVWrite{&KVWrite{"\r", false, []byte{}, nil}}},
+		{"\"s", nil},
+		{"1", nil},
+		{"5Q", []*KVWrite{&KVWrite{"\x0f", false, []byte{}, nil}}},
+		{"Q]\x11", nil},
+		{"%", []*KVWrite{&KVWrite{"?A", false, []byte("+/"), nil}}},
+	} {
+		txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{ns.name, nil, ns.writes, nil})
+	}
+	b := marshalLegacy(txRW)
+	protoMsg := &kvrwset.TxReadWriteSet{}
+	testutil.AssertNoError(t, proto.Unmarshal(b, protoMsg), "The legacy bytes should parse as protobuf")
+	testutil.AssertEquals(t, protoMsg.Version, uint32(protoMsgVersion))
+
+	deserializedRWSet := &TxReadWriteSet{}
+	err := deserializedRWSet.Unmarshal(b)
+	testutil.AssertNoError(t, err, "Error while unmarshalling legacy changeset")
+	testutil.AssertEquals(t, deserializedRWSet, txRW)
+}
+
+func TestUnmarshalUnknownVersion(t *testing.T) {
+	b, err := proto.Marshal(&kvrwset.TxReadWriteSet{Version: protoMsgVersion + 1})
+	testutil.AssertNoError(t, err, "Error while marshalling changeset")
+	testutil.AssertError(t, (&TxReadWriteSet{}).Unmarshal(b), "Expected error for an unknown encoding version")
+}
+
+func TestMarshalPlainProto(t *testing.T) {
+	txRW := &TxReadWriteSet{}
+	txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{"ns1",
+		[]*KVRead{&KVRead{"key1", version.NewHeight(1, 1)}},
+		[]*KVWrite{&KVWrite{"key2", false, []byte("value2"), nil}}, nil})
+	b, err := txRW.Marshal()
+	testutil.AssertNoError(t, err, "Error while marshalling changeset")
+
+	protoMsg := &kvrwset.TxReadWriteSet{}
+	testutil.AssertNoError(t, proto.Unmarshal(b, protoMsg), "The read-write set should decode as plain protobuf")
+	testutil.AssertEquals(t, protoMsg, txRW.toProtoMsg())
+}
+
+func TestMarshalEmptyValue(t *testing.T) {
+	txRW := &TxReadWriteSet{}
+	txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{"ns1", nil,
+		[]*KVWrite{&KVWrite{"key1", false, []byte{}, nil}}, nil})
+	b, err := txRW.Marshal()
+	testutil.AssertNoError(t, err, "Error while marshalling changeset")
+
+	deserializedRWSet := &TxReadWriteSet{}
+	err = deserializedRWSet.Unmarshal(b)
+	testutil.AssertNoError(t, err, "Error while unmarshalling changeset")
+	testutil.AssertEquals(t, deserializedRWSet, txRW)
+}
+
+func TestHashLargeValues(t *testing.T) {
+	largeValue := make([]byte, 100)
+	txRW := &TxReadWriteSet{}
+	txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{"ns1", nil,
+		[]*KVWrite{NewKVWrite("key1", []byte("value1")), NewKVWrite("key2", largeValue), NewKVWrite("key3", nil)}, nil})
+
+	hashedValues, err := txRW.HashLargeValues(10)
+	testutil.AssertNoError(t, err, "Error while hashing large values")
+	testutil.AssertNotNil(t, hashedValues)
+	testutil.AssertEquals(t, txRW.NsRWs[0].Writes[0].Value, []byte("value1"))
+	testutil.AssertNil(t, txRW.NsRWs[0].Writes[0].ValueHash)
+	testutil.AssertNil(t, txRW.NsRWs[0].Writes[1].Value)
+	testutil.AssertNotNil(t, txRW.NsRWs[0].Writes[1].ValueHash)
+	testutil.AssertNil(t, txRW.NsRWs[0].Writes[2].ValueHash)
+
+	b, err := txRW.Marshal()
+	testutil.AssertNoError(t, err, "Error while marshalling changeset")
+	deserializedRWSet := &TxReadWriteSet{}
+	err = deserializedRWSet.Unmarshal(b)
+	testutil.AssertNoError(t, err, "Error while unmarshalling changeset")
+	testutil.AssertEquals(t, deserializedRWSet, txRW)
+
+	// the values are missing
+	testutil.AssertError(t, deserializedRWSet.ResolveHashedValues(nil), "Expected an error for missing values")
+
+	err = deserializedRWSet.ResolveHashedValues(hashedValues)
+	testutil.AssertNoError(t, err, "Error while resolving hashed values")
+	testutil.AssertEquals(t, deserializedRWSet.NsRWs[0].Writes[1].Value, largeValue)
+
+	// nothing to hash
+	txRW = &TxReadWriteSet{}
+	txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{"ns1", nil, []*KVWrite{NewKVWrite("key1", []byte("value1"))}, nil})
+	hashedValues, err = txRW.HashLargeValues(10)
+	testutil.AssertNoError(t, err, "Error while hashing large values")
+	testutil.AssertNil(t, hashedValues)
+}
+
+func TestResolveTamperedHashedValues(t *testing.T) {
+	txRW := &TxReadWriteSet{}
+	txRW.NsRWs = append(txRW.NsRWs, &NsReadWriteSet{"ns1", nil,
+		[]*KVWrite{NewKVWrite("key1", []byte("a large value"))}, nil})
+	hashedValues, err := txRW.HashLargeValues(1)
+	testutil.AssertNoError(t, err, "Error while hashing large values")
+
+	msg := &kvrwset.HashedValues{}
+	testutil.AssertNoError(t, proto.Unmarshal(hashedValues, msg), "")
+	msg.Values[0].Value = []byte("another large value")
+	tamperedValues, _ := proto.Marshal(msg)
+	testutil.AssertError(t, txRW.ResolveHashedValues(tamperedValues), "Expected an error for a tampered value")
+}
+
+// marshalLegacy serializes a `TxReadWriteSet` in the encoding used before the protobuf message
+func marshalLegacy(txRW *TxReadWriteSet) []byte {
+	buf := proto.NewBuffer(nil)
+	buf.EncodeVarint(uint64(len(txRW.NsRWs)))
+	for _, nsRW := range txRW.NsRWs {
+		buf.EncodeStringBytes(nsRW.NameSpace)
+		buf.EncodeVarint(uint64(len(nsRW.Reads)))
+		for _, r := range nsRW.Reads {
+			buf.EncodeStringBytes(r.Key)
+			versionBytes := []byte{}
+			if r.Version != nil {
+				versionBytes = r.Version.ToBytes()
+			}
+			buf.EncodeRawBytes(versionBytes)
+		}
+		buf.EncodeVarint(uint64(len(nsRW.Writes)))
+		for _, w := range nsRW.Writes {
+			buf.EncodeStringBytes(w.Key)
+			if w.IsDelete {
+				buf.EncodeVarint(1)
+				continue
+			}
+			buf.EncodeVarint(0)
+			buf.EncodeRawBytes(w.Value)
+		}
+	}
+	return buf.Bytes()
+}
@@ -153,6 +153,71 @@ func TestIterator(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	testItr(t, itr4, []string{"key5", "key6"})
 }
 
+// TestFullScanIterator tests the iterator over all the namespaces of a db
+func TestFullScanIterator(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db1 := dbProvider.GetDBHandle("TestDB1")
+	db1.Open()
+	defer db1.Close()
+	db2 := dbProvider.GetDBHandle("TestDB2")
+	db2.Open()
+	defer db2.Close()
+	batch1 := statedb.NewUpdateBatch()
+	batch1.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch1.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 2))
+	batch1.Put("ns2", "key3", []byte("value3"), version.NewHeight(1, 3))
+	batch1.Put("ns3", "key4", []byte("value4"), version.NewHeight(1, 4))
+	db1.ApplyUpdates(batch1, version.NewHeight(1, 4))
+	batch2 := statedb.NewUpdateBatch()
+	batch2.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 1))
+	db2.ApplyUpdates(batch2, version.NewHeight(1, 1))
+
+	itr, err := db1.GetStateFullScanIterator()
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	expectedKeys := []statedb.CompositeKey{{Namespace: "ns1", Key: "key1"}, {Namespace: "ns1", Key: "key2"},
+		{Namespace: "ns2", Key: "key3"}, {Namespace: "ns3", Key: "key4"}}
+	for _, expectedKey := range expectedKeys {
+		vkv, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, vkv.CompositeKey, expectedKey)
+	}
+	last, err := itr.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, last)
+}
+
+// TestDeleteDB tests that deleting a db removes its keys and savepoint and leaves the other dbs untouched
+func TestDeleteDB(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db1 := dbProvider.GetDBHandle("TestDB1")
+	db2 := dbProvider.GetDBHandle("TestDB2")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch.Put("ns2", "key2", []byte("value2"), version.NewHeight(1, 2))
+	db1.ApplyUpdates(batch, version.NewHeight(1, 2))
+	db2.ApplyUpdates(batch, version.NewHeight(1, 2))
+	// the values are cached by the reads
+	db1.GetState("ns1", "key1")
+
+	testutil.AssertNoError(t, dbProvider.DeleteDB("TestDB1"), "")
+	db1 = dbProvider.GetDBHandle("TestDB1")
+	vv, err := db1.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, vv)
+	sp, err := db1.GetLatestSavePoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, sp)
+	itr, _ := db1.GetStateFullScanIterator()
+	last, err := itr.Next()
+	itr.Close()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, last)
+
+	vv, _ = db2.GetState("ns2", "key2")
+	testutil.AssertEquals(t, vv.Value, []byte("value2"))
+	sp, _ = db2.GetLatestSavePoint()
+	testutil.AssertEquals(t, sp, version.NewHeight(1, 2))
+}
+
 func testItr(t *testing.T, itr statedb.ResultsIterator, expectedKeys []string) {
 	defer itr.Close()
 	for _, expectedKey := range expectedKeys {
@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package statedb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// VersionedDBProviderConstructor constructs an instance of a VersionedDBProvider
+type VersionedDBProviderConstructor func() (VersionedDBProvider, error)
+
+var registry = struct {
+	sync.RWMutex
+	constructors map[string]VersionedDBProviderConstructor
+}{constructors: make(map[string]VersionedDBProviderConstructor)}
+
+// RegisterVersionedDBProvider makes a VersionedDBProvider implementation available under the given name.
+// It is expected to be called from the init function of the package implementing the state database and
+// panics if the name is empty, the constructor is nil or the name is already registered
+func RegisterVersionedDBProvider(name string, constructor VersionedDBProviderConstructor) {
+	registry.Lock()
+	defer registry.Unlock()
+	if name == "" || constructor == nil {
+		panic("A state database must be registered with a name and a constructor")
+	}
+	if _, exists := registry.constructors[name]; exists {
+		panic(fmt.Sprintf("State database [%s] is already registered", name))
+	}
+	registry.constructors[name] = constructor
+}
+
+// NewVersionedDBProvider constructs the VersionedDBProvider registered under the given name
+func NewVersionedDBProvider(name string) (VersionedDBProvider, error) {
+	registry.RLock()
+	constructor, exists := registry.constructors[name]
+	registry.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("Unknown state database [%s]. Registered state databases are %v", name, RegisteredVersionedDBProviders())
+	}
+	return constructor()
+}
+
+// RegisteredVersionedDBProviders returns the sorted names of the registered state databases
+func RegisteredVersionedDBProviders() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.constructors))
+	for name := range registry.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package statedb
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+type testDBProvider struct {
+}
+
+func (p *testDBProvider) GetDBHandle(id string) VersionedDB {
+	return nil
+}
+
+func (p *testDBProvider) DeleteDB(id string) error {
+	return nil
+}
+
+func (p *testDBProvider) Close() {
+}
+
+func TestRegistry(t *testing.T) {
+	provider := &testDBProvider{}
+	RegisterVersionedDBProvider("testRegistryDB", func() (VersionedDBProvider, error) { return provider, nil })
+	testutil.AssertContains(t, RegisteredVersionedDBProviders(), "testRegistryDB")
+
+	p, err := NewVersionedDBProvider("testRegistryDB")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertSame(t, p, provider)
+
+	_, err = NewVersionedDBProvider("unknownDB")
+	testutil.AssertError(t, err, "Expected an error for an unregistered state database")
+
+	defer testutil.AssertPanic(t, "Expected a panic for a duplicate registration")
+	RegisterVersionedDBProvider("testRegistryDB", func() (VersionedDBProvider, error) { return provider, nil })
+}
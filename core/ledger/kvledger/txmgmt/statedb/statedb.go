@@ -22,6 +22,8 @@ import "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 type VersionedDBProvider interface {
 	// GetDBHandle returns a handle to a VersionedDB
 	GetDBHandle(id string) VersionedDB
+	// DeleteDB removes the contents of a VersionedDB. The handles previously returned for it must not be used anymore
+	DeleteDB(id string) error
 	// Close closes all the VersionedDB instances and releases any resources held by VersionedDBProvider
 	Close()
 }
@@ -35,6 +37,9 @@ type VersionedDB interface {
 	// GetStateRangeScanIterator returns an iterator that contains all the key-values between given key ranges.
 	// The returned ResultsIterator contains results of type *VersionedKV
 	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ResultsIterator, error)
+	// GetStateFullScanIterator returns an iterator that contains all the key-values of all the namespaces.
+	// The returned ResultsIterator contains results of type *VersionedKV
+	GetStateFullScanIterator() (ResultsIterator, error)
 	// ExecuteQuery executes the given query and returns an iterator that contains results of type *VersionedKV.
 	ExecuteQuery(query string) (ResultsIterator, error)
 	// ApplyUpdates applies the batch to the underlying db.
@@ -42,7 +47,7 @@ type VersionedDB interface {
 	// a state db implementation is expected to ues as a save point
 	ApplyUpdates(batch *UpdateBatch, height *version.Height) error
 	// GetLatestSavePoint returns the height of the highest transaction upto which
-	// the state db is consistent
+	// the state db is consistent. A nil height is returned if no update has been applied yet
 	GetLatestSavePoint() (*version.Height, error)
 	// Open opens the db
 	Open() error
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stateleveldb
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+)
+
+const maxCacheShards = 16
+
+// stateCache is a bounded cache of the committed values of a VersionedDB, keyed by composite key.
+// A nil value records a key that is absent from the db.
+// The keys are spread over shards, each guarded by its own lock and evicting its least recently
+// used entry when it is full. Every commit increments a sequence number before updating the
+// shards, which keeps a value read from the db before a commit from being cached after that commit
+type stateCache struct {
+	shards     []*cacheShard
+	shardSize  int
+	commitSeq  uint64
+	updateLock sync.Mutex
+}
+
+// cacheShard holds the entries of a shard in a list ordered from the most to the least recently used
+type cacheShard struct {
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cacheEntry struct {
+	key string
+	vv  *statedb.VersionedValue
+}
+
+func newStateCache(size int) *stateCache {
+	numShards := maxCacheShards
+	if size < numShards {
+		numShards = size
+	}
+	cache := &stateCache{shards: make([]*cacheShard, numShards), shardSize: (size + numShards - 1) / numShards}
+	for i := range cache.shards {
+		cache.shards[i] = &cacheShard{entries: make(map[string]*list.Element), lru: list.New()}
+	}
+	return cache
+}
+
+func (cache *stateCache) shard(key string) *cacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return cache.shards[h.Sum32()%uint32(len(cache.shards))]
+}
+
+// get returns the cached value of the key and whether the key is present in the cache
+func (cache *stateCache) get(key string) (*statedb.VersionedValue, bool) {
+	shard := cache.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	elem, ok := shard.entries[key]
+	if !ok {
+		return nil, false
+	}
+	shard.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).vv, true
+}
+
+// getCommitSeq returns the sequence number to pass to add for a value that is about to be read from the db
+func (cache *stateCache) getCommitSeq() uint64 {
+	return atomic.LoadUint64(&cache.commitSeq)
+}
+
+// add caches a value read from the db, unless a commit happened since commitSeq was obtained.
+// When the shard is full, its least recently used entry is evicted
+func (cache *stateCache) add(key string, vv *statedb.VersionedValue, commitSeq uint64) {
+	shard := cache.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	// a commit that increments the sequence after this check updates the entry added below
+	if cache.getCommitSeq() != commitSeq {
+		return
+	}
+	if _, ok := shard.entries[key]; ok {
+		return
+	}
+	if shard.lru.Len() >= cache.shardSize {
+		oldest := shard.lru.Back()
+		shard.lru.Remove(oldest)
+		delete(shard.entries, oldest.Value.(*cacheEntry).key)
+	}
+	shard.entries[key] = shard.lru.PushFront(&cacheEntry{key, vv})
+}
+
+// update replaces the cached values of the keys written by a commit, which must already be
+// applied to the db. Keys that are not cached are not added
+func (cache *stateCache) update(updates map[string]*statedb.VersionedValue) {
+	cache.updateLock.Lock()
+	defer cache.updateLock.Unlock()
+	atomic.AddUint64(&cache.commitSeq, 1)
+	for key, vv := range updates {
+		shard := cache.shard(key)
+		shard.lock.Lock()
+		if elem, ok := shard.entries[key]; ok {
+			elem.Value.(*cacheEntry).vv = vv
+		}
+		shard.lock.Unlock()
+	}
+}
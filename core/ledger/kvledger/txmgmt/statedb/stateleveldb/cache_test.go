@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package stateleveldb
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/commontests"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/spf13/viper"
+)
+
+func newCachedTestVDBEnv(t *testing.T, cacheSize int) *TestVDBEnv {
+	viper.Set("ledger.state.cacheSize", cacheSize)
+	defer viper.Set("ledger.state.cacheSize", 0)
+	return NewTestVDBEnv(t)
+}
+
+func TestCachedBasicRW(t *testing.T) {
+	env := newCachedTestVDBEnv(t, 10)
+	defer env.Cleanup()
+	commontests.TestBasicRW(t, env.DBProvider)
+}
+
+func TestCachedDeletes(t *testing.T) {
+	env := newCachedTestVDBEnv(t, 10)
+	defer env.Cleanup()
+	commontests.TestDeletes(t, env.DBProvider)
+}
+
+func TestCacheUpdatedByCommits(t *testing.T) {
+	env := newCachedTestVDBEnv(t, 10)
+	defer env.Cleanup()
+	db := env.DBProvider.GetDBHandle("TestDB").(*VersionedDB)
+
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch.Put("ns", "key2", []byte("value2"), version.NewHeight(1, 2))
+	db.ApplyUpdates(batch, version.NewHeight(1, 2))
+
+	// reads populate the cache, including with the keys that are absent
+	for _, key := range []string{"key1", "key2", "key3"} {
+		db.GetState("ns", key)
+		_, ok := db.cache.get(string(constructCompositeKey("TestDB", "ns", key)))
+		testutil.AssertEquals(t, ok, true)
+	}
+
+	// the values returned can be modified without affecting the cache
+	vv, _ := db.GetState("ns", "key1")
+	vv.Value[0] = 'V'
+	vv, _ = db.GetState("ns", "key1")
+	testutil.AssertEquals(t, vv.Value, []byte("value1"))
+
+	batch = statedb.NewUpdateBatch()
+	batch.Put("ns", "key1", []byte("newValue1"), version.NewHeight(2, 1))
+	batch.Delete("ns", "key2", version.NewHeight(2, 2))
+	batch.Put("ns", "key3", []byte("value3"), version.NewHeight(2, 3))
+	batch.Put("ns", "key4", []byte("value4"), version.NewHeight(2, 4))
+	db.ApplyUpdates(batch, version.NewHeight(2, 4))
+
+	vv, _ = db.GetState("ns", "key1")
+	testutil.AssertEquals(t, vv, &statedb.VersionedValue{Value: []byte("newValue1"), Version: version.NewHeight(2, 1)})
+	vv, _ = db.GetState("ns", "key2")
+	testutil.AssertNil(t, vv)
+	vv, _ = db.GetState("ns", "key3")
+	testutil.AssertEquals(t, vv, &statedb.VersionedValue{Value: []byte("value3"), Version: version.NewHeight(2, 3)})
+	vv, _ = db.GetState("ns", "key4")
+	testutil.AssertEquals(t, vv, &statedb.VersionedValue{Value: []byte("value4"), Version: version.NewHeight(2, 4)})
+}
+
+func TestCacheSkipsValuesReadBeforeCommit(t *testing.T) {
+	cache := newStateCache(10)
+	commitSeq := cache.getCommitSeq()
+	cache.update(map[string]*statedb.VersionedValue{"key1": nil})
+	cache.add("key1", &statedb.VersionedValue{Value: []byte("staleValue"), Version: version.NewHeight(1, 1)}, commitSeq)
+	_, ok := cache.get("key1")
+	testutil.AssertEquals(t, ok, false)
+
+	commitSeq = cache.getCommitSeq()
+	cache.add("key1", nil, commitSeq)
+	vv, ok := cache.get("key1")
+	testutil.AssertEquals(t, ok, true)
+	testutil.AssertNil(t, vv)
+}
+
+func TestCacheIsBounded(t *testing.T) {
+	cache := newStateCache(20)
+	for i := 0; i < 1000; i++ {
+		cache.add(fmt.Sprintf("key%d", i), nil, cache.getCommitSeq())
+	}
+	size := 0
+	for _, shard := range cache.shards {
+		testutil.AssertEquals(t, len(shard.entries) <= cache.shardSize, true)
+		testutil.AssertEquals(t, shard.lru.Len(), len(shard.entries))
+		size += len(shard.entries)
+	}
+	testutil.AssertEquals(t, size <= 32, true)
+	_, ok := cache.get("key999")
+	testutil.AssertEquals(t, ok, true)
+
+	cache = newStateCache(3)
+	testutil.AssertEquals(t, len(cache.shards), 3)
+	testutil.AssertEquals(t, cache.shardSize, 1)
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// a single shard of 3 entries
+	cache := newStateCache(1)
+	cache.shardSize = 3
+	for _, key := range []string{"key1", "key2", "key3"} {
+		cache.add(key, nil, cache.getCommitSeq())
+	}
+	cache.get("key1")
+	cache.add("key4", nil, cache.getCommitSeq())
+	_, ok := cache.get("key2")
+	testutil.AssertEquals(t, ok, false)
+	for _, key := range []string{"key1", "key3", "key4"} {
+		_, ok = cache.get(key)
+		testutil.AssertEquals(t, ok, true)
+	}
+}
+
+func TestCacheConcurrentReadsAndCommits(t *testing.T) {
+	env := newCachedTestVDBEnv(t, 5)
+	defer env.Cleanup()
+	db := env.DBProvider.GetDBHandle("TestDB")
+	numKeys := 10
+	numBlocks := 50
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < numBlocks*numKeys; i++ {
+				db.GetState("ns", fmt.Sprintf("key%d", i%numKeys))
+			}
+		}()
+	}
+	for blockNum := uint64(1); blockNum <= uint64(numBlocks); blockNum++ {
+		batch := statedb.NewUpdateBatch()
+		for i := 0; i < numKeys; i++ {
+			batch.Put("ns", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", blockNum)), version.NewHeight(blockNum, uint64(i)))
+		}
+		db.ApplyUpdates(batch, version.NewHeight(blockNum, uint64(numKeys)))
+	}
+	wg.Wait()
+
+	// no read that raced with a commit left a stale value in the cache
+	for i := 0; i < numKeys; i++ {
+		vv, _ := db.GetState("ns", fmt.Sprintf("key%d", i))
+		testutil.AssertEquals(t, vv.Value, []byte(fmt.Sprintf("value%d", numBlocks)))
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/util/db"
 	logging "github.com/op/go-logging"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -35,12 +36,22 @@ var compositeKeySep = []byte{0x00}
 var lastKeyIndicator = byte(0x01)
 var savePointKey = []byte{0x00}
 
+// StateDatabaseName is the name under which the leveldb state database is registered
+const StateDatabaseName = "goleveldb"
+
+func init() {
+	statedb.RegisterVersionedDBProvider(StateDatabaseName, func() (statedb.VersionedDBProvider, error) {
+		return NewVersionedDBProvider(), nil
+	})
+}
+
 // VersionedDBProvider implements interface VersionedDBProvider
 type VersionedDBProvider struct {
 	db         *db.DB
 	databases  map[string]*VersionedDB
 	mux        sync.Mutex
 	openCounts uint64
+	cacheSize  int
 }
 
 // NewVersionedDBProvider instantiates VersionedDBProvider
@@ -50,7 +61,7 @@ func NewVersionedDBProvider() *VersionedDBProvider {
 	db := db.CreateDB(&db.Conf{DBPath: dbPath})
 	db.Open()
 	logger.Debugf("Opened db dbPath=%s", dbPath)
-	return &VersionedDBProvider{db, make(map[string]*VersionedDB), sync.Mutex{}, 0}
+	return &VersionedDBProvider{db, make(map[string]*VersionedDB), sync.Mutex{}, 0, ledgerconfig.GetStateCacheSize()}
 }
 
 // GetDBHandle gets the handle to a named database
@@ -59,12 +70,31 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) statedb.Versione
 	defer provider.mux.Unlock()
 	vdb := provider.databases[dbName]
 	if vdb == nil {
-		vdb = newVersionedDB(provider.db, dbName)
+		vdb = newVersionedDB(provider.db, dbName, provider.cacheSize)
 		provider.databases[dbName] = vdb
 	}
 	return vdb
 }
 
+// DeleteDB removes the keys and the savepoint of a named database from the shared db
+func (provider *VersionedDBProvider) DeleteDB(dbName string) error {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+	delete(provider.databases, dbName)
+	levelBatch := &leveldb.Batch{}
+	dbItr := provider.db.GetIterator(append([]byte(dbName), compositeKeySep...), append([]byte(dbName), lastKeyIndicator))
+	for dbItr.Next() {
+		levelBatch.Delete(append([]byte(nil), dbItr.Key()...))
+	}
+	dbItr.Release()
+	if err := dbItr.Error(); err != nil {
+		return err
+	}
+	levelBatch.Delete(constructSavepointKey(dbName))
+	logger.Debugf("Deleting [%d] keys of db [%s]", levelBatch.Len(), dbName)
+	return provider.db.WriteBatch(levelBatch, true)
+}
+
 // Close closes the underlying db
 func (provider *VersionedDBProvider) Close() {
 	provider.db.Close()
@@ -74,11 +104,16 @@ func (provider *VersionedDBProvider) Close() {
 type VersionedDB struct {
 	db     *db.DB
 	dbName string
+	cache  *stateCache
 }
 
-// newVersionedDB constructs an instance of VersionedDB
-func newVersionedDB(db *db.DB, dbName string) *VersionedDB {
-	return &VersionedDB{db, dbName}
+// newVersionedDB constructs an instance of VersionedDB that caches the values of up to cacheSize keys
+func newVersionedDB(db *db.DB, dbName string, cacheSize int) *VersionedDB {
+	var cache *stateCache
+	if cacheSize > 0 {
+		cache = newStateCache(cacheSize)
+	}
+	return &VersionedDB{db, dbName, cache}
 }
 
 // Open implements method in VersionedDB interface
@@ -96,6 +131,22 @@ func (vdb *VersionedDB) Close() {
 func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
 	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
 	compositeKey := constructCompositeKey(vdb.dbName, namespace, key)
+	if vdb.cache == nil {
+		return vdb.getCommittedState(compositeKey)
+	}
+	if vv, ok := vdb.cache.get(string(compositeKey)); ok {
+		return copyVersionedValue(vv), nil
+	}
+	commitSeq := vdb.cache.getCommitSeq()
+	vv, err := vdb.getCommittedState(compositeKey)
+	if err != nil {
+		return nil, err
+	}
+	vdb.cache.add(string(compositeKey), vv, commitSeq)
+	return copyVersionedValue(vv), nil
+}
+
+func (vdb *VersionedDB) getCommittedState(compositeKey []byte) (*statedb.VersionedValue, error) {
 	dbVal, err := vdb.db.Get(compositeKey)
 	if err != nil {
 		return nil, err
@@ -107,6 +158,16 @@ func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.Version
 	return &statedb.VersionedValue{Value: val, Version: ver}, nil
 }
 
+// copyVersionedValue copies a cached value so that the caller can modify it
+func copyVersionedValue(vv *statedb.VersionedValue) *statedb.VersionedValue {
+	if vv == nil {
+		return nil
+	}
+	value := make([]byte, len(vv.Value))
+	copy(value, vv.Value)
+	return &statedb.VersionedValue{Value: value, Version: vv.Version}
+}
+
 // GetStateMultipleKeys implements method in VersionedDB interface
 func (vdb *VersionedDB) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
 	vals := make([]*statedb.VersionedValue, len(keys))
@@ -128,7 +189,15 @@ func (vdb *VersionedDB) GetStateRangeScanIterator(namespace string, startKey str
 		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
 	}
 	dbItr := vdb.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newKVScanner(namespace, dbItr), nil
+	return newKVScanner(dbItr), nil
+}
+
+// GetStateFullScanIterator implements method in VersionedDB interface
+func (vdb *VersionedDB) GetStateFullScanIterator() (statedb.ResultsIterator, error) {
+	startKey := append([]byte(vdb.dbName), compositeKeySep...)
+	endKey := append([]byte(vdb.dbName), lastKeyIndicator)
+	dbItr := vdb.db.GetIterator(startKey, endKey)
+	return newKVScanner(dbItr), nil
 }
 
 // ExecuteQuery implements method in VersionedDB interface
@@ -152,6 +221,19 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 	if err := vdb.db.WriteBatch(levelBatch, false); err != nil {
 		return err
 	}
+	// the cache is updated after the db so that the values it holds are always committed
+	if vdb.cache != nil {
+		cacheUpdates := make(map[string]*statedb.VersionedValue)
+		for ck, vv := range batch.KVs {
+			compositeKey := string(constructCompositeKey(vdb.dbName, ck.Namespace, ck.Key))
+			if vv.Value == nil {
+				cacheUpdates[compositeKey] = nil
+			} else {
+				cacheUpdates[compositeKey] = copyVersionedValue(vv)
+			}
+		}
+		vdb.cache.update(cacheUpdates)
+	}
 	return nil
 }
 
@@ -161,6 +243,9 @@ func (vdb *VersionedDB) GetLatestSavePoint() (*version.Height, error) {
 	if err != nil {
 		return nil, err
 	}
+	if versionBytes == nil {
+		return nil, nil
+	}
 	version, _ := version.NewHeightFromBytes(versionBytes)
 	return version, nil
 }
@@ -199,22 +284,23 @@ func constructSavepointKey(dbName string) []byte {
 }
 
 type kvScanner struct {
-	namespace string
-	dbItr     iterator.Iterator
+	dbItr iterator.Iterator
 }
 
-func newKVScanner(namespace string, dbItr iterator.Iterator) *kvScanner {
-	return &kvScanner{namespace, dbItr}
+func newKVScanner(dbItr iterator.Iterator) *kvScanner {
+	return &kvScanner{dbItr}
 }
 
 func (scanner *kvScanner) Next() (*statedb.VersionedKV, error) {
 	if !scanner.dbItr.Next() {
 		return nil, nil
 	}
-	_, _, key := splitCompositeKey(scanner.dbItr.Key())
-	value, version := decodeValue(scanner.dbItr.Value())
+	_, namespace, key := splitCompositeKey(scanner.dbItr.Key())
+	// the iterator reuses its buffer so the value is copied for the caller to keep
+	dbVal := append([]byte(nil), scanner.dbItr.Value()...)
+	value, version := decodeValue(dbVal)
 	return &statedb.VersionedKV{
-		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: key},
+		CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
 		VersionedValue: statedb.VersionedValue{Value: value, Version: version}}, nil
 }
 
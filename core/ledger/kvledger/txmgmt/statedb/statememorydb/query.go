@@ -0,0 +1,248 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package statememorydb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// query is a subset of the CouchDB selector query syntax, for instance
+// {"selector":{"owner":"bob","size":{"$gt":10}},"limit":10,"skip":0}
+// Fields are matched by equality unless an operator is supplied. Nested fields
+// are referred to with dots (e.g. "owner.name") and the selectors can be combined
+// with "$and" and "$or"
+type query struct {
+	selector map[string]interface{}
+	limit    int
+	skip     int
+}
+
+func parseQuery(queryString string) (*query, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(queryString), &raw); err != nil {
+		return nil, fmt.Errorf("Error parsing query [%s]: %s", queryString, err)
+	}
+	q := &query{}
+	for field, value := range raw {
+		var err error
+		switch field {
+		case "selector":
+			err = json.Unmarshal(value, &q.selector)
+		case "limit":
+			err = json.Unmarshal(value, &q.limit)
+		case "skip":
+			err = json.Unmarshal(value, &q.skip)
+		default:
+			return nil, fmt.Errorf("Unsupported field [%s] in query [%s]", field, queryString)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing field [%s] of query [%s]: %s", field, queryString, err)
+		}
+	}
+	if q.selector == nil {
+		return nil, fmt.Errorf("Query [%s] does not contain a selector", queryString)
+	}
+	if q.limit < 0 || q.skip < 0 {
+		return nil, fmt.Errorf("Negative limit or skip in query [%s]", queryString)
+	}
+	// evaluating the selector against an empty document reports an invalid selector upfront
+	if _, err := matchSelector(map[string]interface{}{}, q.selector); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// matches returns true if the value is a JSON object that satisfies the selector
+func (q *query) matches(value []byte) (bool, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return false, nil
+	}
+	return matchSelector(doc, q.selector)
+}
+
+func matchSelector(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	matched := true
+	for field, condition := range selector {
+		var fieldMatched bool
+		var err error
+		switch field {
+		case "$and", "$or":
+			fieldMatched, err = matchCombination(doc, field, condition)
+		default:
+			value, present := lookupField(doc, field)
+			fieldMatched, err = matchCondition(value, present, condition)
+		}
+		if err != nil {
+			return false, err
+		}
+		// all the fields are evaluated so that an invalid selector is always reported
+		matched = matched && fieldMatched
+	}
+	return matched, nil
+}
+
+func matchCombination(doc map[string]interface{}, operator string, condition interface{}) (bool, error) {
+	selectors, ok := condition.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("Operator [%s] expects an array of selectors", operator)
+	}
+	matched := operator == "$and"
+	for _, s := range selectors {
+		selector, ok := s.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("Operator [%s] expects an array of selectors", operator)
+		}
+		m, err := matchSelector(doc, selector)
+		if err != nil {
+			return false, err
+		}
+		if operator == "$and" {
+			matched = matched && m
+		} else {
+			matched = matched || m
+		}
+	}
+	return matched, nil
+}
+
+// lookupField returns the value of a (possibly nested) field of the document
+func lookupField(doc map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func matchCondition(value interface{}, present bool, condition interface{}) (bool, error) {
+	operators, ok := condition.(map[string]interface{})
+	if !ok || !isOperatorMap(operators) {
+		return present && reflect.DeepEqual(value, condition), nil
+	}
+	matched := true
+	for operator, operand := range operators {
+		m, err := matchOperator(value, present, operator, operand)
+		if err != nil {
+			return false, err
+		}
+		matched = matched && m
+	}
+	return matched, nil
+}
+
+func isOperatorMap(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func matchOperator(value interface{}, present bool, operator string, operand interface{}) (bool, error) {
+	switch operator {
+	case "$exists":
+		exists, ok := operand.(bool)
+		if !ok {
+			return false, fmt.Errorf("Operator [$exists] expects a boolean")
+		}
+		return present == exists, nil
+	case "$eq":
+		return present && reflect.DeepEqual(value, operand), nil
+	case "$ne":
+		return present && !reflect.DeepEqual(value, operand), nil
+	case "$in":
+		candidates, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("Operator [$in] expects an array")
+		}
+		for _, c := range candidates {
+			if present && reflect.DeepEqual(value, c) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$gt", "$gte", "$lt", "$lte":
+		if !isComparable(operand) {
+			return false, fmt.Errorf("Operator [%s] expects a number or a string", operator)
+		}
+		if !present {
+			return false, nil
+		}
+		res, ok := compare(value, operand)
+		if !ok {
+			return false, nil
+		}
+		switch operator {
+		case "$gt":
+			return res > 0, nil
+		case "$gte":
+			return res >= 0, nil
+		case "$lt":
+			return res < 0, nil
+		default:
+			return res <= 0, nil
+		}
+	default:
+		return false, fmt.Errorf("Unsupported operator [%s]", operator)
+	}
+}
+
+func isComparable(v interface{}) bool {
+	switch v.(type) {
+	case float64, string:
+		return true
+	}
+	return false
+}
+
+// compare returns -1, 0 or +1 if both the values are numbers or both are strings
+func compare(a interface{}, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	}
+	return 0, false
+}
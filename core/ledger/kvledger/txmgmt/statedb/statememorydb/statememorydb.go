@@ -0,0 +1,312 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package statememorydb
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	logging "github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("statememorydb")
+
+// StateDatabaseName is the name under which the in-memory state database is registered
+const StateDatabaseName = "memory"
+
+func init() {
+	statedb.RegisterVersionedDBProvider(StateDatabaseName, func() (statedb.VersionedDBProvider, error) {
+		return NewVersionedDBProvider(), nil
+	})
+}
+
+// VersionedDBProvider implements interface VersionedDBProvider.
+// The contents of the databases live as long as the provider is not closed
+type VersionedDBProvider struct {
+	databases map[string]*VersionedDB
+	mux       sync.Mutex
+}
+
+// NewVersionedDBProvider instantiates VersionedDBProvider
+func NewVersionedDBProvider() *VersionedDBProvider {
+	logger.Debugf("constructing in-memory VersionedDBProvider")
+	return &VersionedDBProvider{databases: make(map[string]*VersionedDB)}
+}
+
+// GetDBHandle gets the handle to a named database
+func (provider *VersionedDBProvider) GetDBHandle(dbName string) statedb.VersionedDB {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+	vdb := provider.databases[dbName]
+	if vdb == nil {
+		vdb = newVersionedDB(dbName)
+		provider.databases[dbName] = vdb
+	}
+	return vdb
+}
+
+// DeleteDB drops a named database
+func (provider *VersionedDBProvider) DeleteDB(dbName string) error {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+	delete(provider.databases, dbName)
+	return nil
+}
+
+// Close drops all the databases
+func (provider *VersionedDBProvider) Close() {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+	provider.databases = make(map[string]*VersionedDB)
+}
+
+// VersionedDB implements VersionedDB interface.
+// The keys of each namespace are kept sorted so that the range scans return the results in
+// the same order as the leveldb implementation does
+type VersionedDB struct {
+	dbName     string
+	mux        sync.RWMutex
+	namespaces map[string]*nsState
+	savePoint  *version.Height
+}
+
+type nsState struct {
+	kvs        map[string]*statedb.VersionedValue
+	sortedKeys []string
+}
+
+// newVersionedDB constructs an instance of VersionedDB
+func newVersionedDB(dbName string) *VersionedDB {
+	return &VersionedDB{dbName: dbName, namespaces: make(map[string]*nsState)}
+}
+
+// Open implements method in VersionedDB interface
+func (vdb *VersionedDB) Open() error {
+	// do nothing because the contents are held by the provider
+	return nil
+}
+
+// Close implements method in VersionedDB interface
+func (vdb *VersionedDB) Close() {
+	// do nothing because the contents are held by the provider
+}
+
+// GetState implements method in VersionedDB interface
+func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
+	vdb.mux.RLock()
+	defer vdb.mux.RUnlock()
+	ns := vdb.namespaces[namespace]
+	if ns == nil {
+		return nil, nil
+	}
+	vv := ns.kvs[key]
+	if vv == nil {
+		return nil, nil
+	}
+	return copyVersionedValue(vv), nil
+}
+
+// GetStateMultipleKeys implements method in VersionedDB interface
+func (vdb *VersionedDB) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	vals := make([]*statedb.VersionedValue, len(keys))
+	for i, key := range keys {
+		val, err := vdb.GetState(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// GetStateRangeScanIterator implements method in VersionedDB interface.
+// The results are collected when the iterator is created and hence, the iterator
+// is not affected by the updates applied afterwards
+func (vdb *VersionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+	vdb.mux.RLock()
+	defer vdb.mux.RUnlock()
+	var results []*statedb.VersionedKV
+	if ns := vdb.namespaces[namespace]; ns != nil {
+		results = ns.collect(namespace, startKey, endKey)
+	}
+	return newKVScanner(results), nil
+}
+
+// GetStateFullScanIterator implements method in VersionedDB interface
+func (vdb *VersionedDB) GetStateFullScanIterator() (statedb.ResultsIterator, error) {
+	vdb.mux.RLock()
+	defer vdb.mux.RUnlock()
+	var results []*statedb.VersionedKV
+	for _, namespace := range vdb.sortedNamespaces() {
+		results = append(results, vdb.namespaces[namespace].collect(namespace, "", "")...)
+	}
+	return newKVScanner(results), nil
+}
+
+// ExecuteQuery implements method in VersionedDB interface.
+// The query is expected to be a JSON document in the format of a CouchDB selector query.
+// Only the values that are JSON objects are matched against the selector
+func (vdb *VersionedDB) ExecuteQuery(query string) (statedb.ResultsIterator, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	vdb.mux.RLock()
+	defer vdb.mux.RUnlock()
+	var results []*statedb.VersionedKV
+	skipped := 0
+	for _, namespace := range vdb.sortedNamespaces() {
+		ns := vdb.namespaces[namespace]
+		for _, key := range ns.sortedKeys {
+			if q.limit > 0 && len(results) == q.limit {
+				return newKVScanner(results), nil
+			}
+			vv := ns.kvs[key]
+			matched, err := q.matches(vv.Value)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+			if skipped < q.skip {
+				skipped++
+				continue
+			}
+			results = append(results, newVersionedKV(namespace, key, vv))
+		}
+	}
+	return newKVScanner(results), nil
+}
+
+// ApplyUpdates implements method in VersionedDB interface.
+// The batch and the save point are applied atomically with respect to the readers of the db
+func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
+	vdb.mux.Lock()
+	defer vdb.mux.Unlock()
+	for ck, vv := range batch.KVs {
+		logger.Debugf("processing key=%#v, versionedValue=%#v", ck, vv)
+		ns := vdb.namespaces[ck.Namespace]
+		if vv.Value == nil {
+			if ns != nil {
+				ns.delete(ck.Key)
+				if len(ns.sortedKeys) == 0 {
+					delete(vdb.namespaces, ck.Namespace)
+				}
+			}
+			continue
+		}
+		if ns == nil {
+			ns = &nsState{kvs: make(map[string]*statedb.VersionedValue)}
+			vdb.namespaces[ck.Namespace] = ns
+		}
+		ns.put(ck.Key, copyVersionedValue(vv))
+	}
+	vdb.savePoint = version.NewHeight(height.BlockNum, height.TxNum)
+	return nil
+}
+
+// GetLatestSavePoint implements method in VersionedDB interface
+func (vdb *VersionedDB) GetLatestSavePoint() (*version.Height, error) {
+	vdb.mux.RLock()
+	defer vdb.mux.RUnlock()
+	if vdb.savePoint == nil {
+		return nil, nil
+	}
+	return version.NewHeight(vdb.savePoint.BlockNum, vdb.savePoint.TxNum), nil
+}
+
+func (vdb *VersionedDB) sortedNamespaces() []string {
+	namespaces := make([]string, 0, len(vdb.namespaces))
+	for namespace := range vdb.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+func (ns *nsState) put(key string, vv *statedb.VersionedValue) {
+	if _, exists := ns.kvs[key]; !exists {
+		i := sort.SearchStrings(ns.sortedKeys, key)
+		ns.sortedKeys = append(ns.sortedKeys, "")
+		copy(ns.sortedKeys[i+1:], ns.sortedKeys[i:])
+		ns.sortedKeys[i] = key
+	}
+	ns.kvs[key] = vv
+}
+
+func (ns *nsState) delete(key string) {
+	if _, exists := ns.kvs[key]; !exists {
+		return
+	}
+	delete(ns.kvs, key)
+	i := sort.SearchStrings(ns.sortedKeys, key)
+	ns.sortedKeys = append(ns.sortedKeys[:i], ns.sortedKeys[i+1:]...)
+}
+
+// collect returns the key-values between startKey (inclusive) and endKey (exclusive).
+// An empty endKey refers to the last available key
+func (ns *nsState) collect(namespace string, startKey string, endKey string) []*statedb.VersionedKV {
+	var results []*statedb.VersionedKV
+	for i := sort.SearchStrings(ns.sortedKeys, startKey); i < len(ns.sortedKeys); i++ {
+		key := ns.sortedKeys[i]
+		if endKey != "" && key >= endKey {
+			break
+		}
+		results = append(results, newVersionedKV(namespace, key, ns.kvs[key]))
+	}
+	return results
+}
+
+func newVersionedKV(namespace string, key string, vv *statedb.VersionedValue) *statedb.VersionedKV {
+	return &statedb.VersionedKV{
+		CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
+		VersionedValue: *copyVersionedValue(vv)}
+}
+
+// copyVersionedValue copies the value so that neither the callers nor the batches share memory with the db
+func copyVersionedValue(vv *statedb.VersionedValue) *statedb.VersionedValue {
+	var ver *version.Height
+	if vv.Version != nil {
+		ver = version.NewHeight(vv.Version.BlockNum, vv.Version.TxNum)
+	}
+	return &statedb.VersionedValue{Value: append([]byte{}, vv.Value...), Version: ver}
+}
+
+type kvScanner struct {
+	results []*statedb.VersionedKV
+	next    int
+}
+
+func newKVScanner(results []*statedb.VersionedKV) *kvScanner {
+	return &kvScanner{results, 0}
+}
+
+func (scanner *kvScanner) Next() (*statedb.VersionedKV, error) {
+	if scanner.next >= len(scanner.results) {
+		return nil, nil
+	}
+	vkv := scanner.results[scanner.next]
+	scanner.next++
+	return vkv, nil
+}
+
+func (scanner *kvScanner) Close() {
+	scanner.results = nil
+}
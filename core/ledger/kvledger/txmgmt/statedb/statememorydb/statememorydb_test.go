@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package statememorydb
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/commontests"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestBasicRW(t *testing.T) {
+	commontests.TestBasicRW(t, NewVersionedDBProvider())
+}
+
+func TestMultiDBBasicRW(t *testing.T) {
+	commontests.TestMultiDBBasicRW(t, NewVersionedDBProvider())
+}
+
+func TestDeletes(t *testing.T) {
+	commontests.TestDeletes(t, NewVersionedDBProvider())
+}
+
+func TestIterator(t *testing.T) {
+	commontests.TestIterator(t, NewVersionedDBProvider())
+}
+
+func TestFullScanIterator(t *testing.T) {
+	commontests.TestFullScanIterator(t, NewVersionedDBProvider())
+}
+
+func TestDeleteDB(t *testing.T) {
+	commontests.TestDeleteDB(t, NewVersionedDBProvider())
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := statedb.NewVersionedDBProvider(StateDatabaseName)
+	testutil.AssertNoError(t, err, "")
+	defer provider.Close()
+	_, ok := provider.(*VersionedDBProvider)
+	testutil.AssertEquals(t, ok, true)
+}
+
+func TestNoSavePoint(t *testing.T) {
+	db := NewVersionedDBProvider().GetDBHandle("TestDB")
+	sp, err := db.GetLatestSavePoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, sp)
+}
+
+func TestIsolation(t *testing.T) {
+	db := NewVersionedDBProvider().GetDBHandle("TestDB")
+	value := []byte("value1")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns", "key1", value, version.NewHeight(1, 1))
+	batch.Put("ns", "key2", []byte("value2"), version.NewHeight(1, 2))
+	db.ApplyUpdates(batch, version.NewHeight(1, 2))
+
+	// modifying the batch or the returned values does not affect the db
+	value[0] = 'X'
+	vv, _ := db.GetState("ns", "key1")
+	testutil.AssertEquals(t, vv.Value, []byte("value1"))
+	vv.Value[0] = 'X'
+	vv, _ = db.GetState("ns", "key1")
+	testutil.AssertEquals(t, vv.Value, []byte("value1"))
+
+	// an iterator is not affected by the updates applied after its creation
+	itr, _ := db.GetStateRangeScanIterator("ns", "", "")
+	defer itr.Close()
+	batch = statedb.NewUpdateBatch()
+	batch.Delete("ns", "key2", version.NewHeight(2, 1))
+	batch.Put("ns", "key3", []byte("value3"), version.NewHeight(2, 2))
+	db.ApplyUpdates(batch, version.NewHeight(2, 2))
+	for _, expectedKey := range []string{"key1", "key2"} {
+		vkv, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, vkv.Key, expectedKey)
+	}
+	vkv, _ := itr.Next()
+	testutil.AssertNil(t, vkv)
+}
+
+func TestExecuteQuery(t *testing.T) {
+	db := NewVersionedDBProvider().GetDBHandle("TestDB")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "asset1", []byte(`{"owner":"bob","size":5,"color":"blue","details":{"origin":"us"}}`), version.NewHeight(1, 1))
+	batch.Put("ns1", "asset2", []byte(`{"owner":"alice","size":10,"color":"red","details":{"origin":"fr"}}`), version.NewHeight(1, 2))
+	batch.Put("ns1", "asset3", []byte(`{"owner":"bob","size":15,"color":"red"}`), version.NewHeight(1, 3))
+	batch.Put("ns1", "asset4", []byte(`not json`), version.NewHeight(1, 4))
+	batch.Put("ns2", "asset5", []byte(`{"owner":"bob","size":20}`), version.NewHeight(1, 5))
+	db.ApplyUpdates(batch, version.NewHeight(1, 5))
+
+	testQuery(t, db, `{"selector":{"owner":"bob"}}`, []string{"asset1", "asset3", "asset5"})
+	testQuery(t, db, `{"selector":{"owner":{"$eq":"bob"}},"limit":10,"skip":0}`, []string{"asset1", "asset3", "asset5"})
+	testQuery(t, db, `{"selector":{"owner":"bob","color":"red"}}`, []string{"asset3"})
+	testQuery(t, db, `{"selector":{"size":{"$gt":5,"$lte":15}}}`, []string{"asset2", "asset3"})
+	testQuery(t, db, `{"selector":{"owner":{"$ne":"bob"}}}`, []string{"asset2"})
+	testQuery(t, db, `{"selector":{"details.origin":"fr"}}`, []string{"asset2"})
+	testQuery(t, db, `{"selector":{"details":{"$exists":false}}}`, []string{"asset3", "asset5"})
+	testQuery(t, db, `{"selector":{"color":{"$in":["blue","green"]}}}`, []string{"asset1"})
+	testQuery(t, db, `{"selector":{"$or":[{"color":"blue"},{"size":20}]}}`, []string{"asset1", "asset5"})
+	testQuery(t, db, `{"selector":{"$and":[{"owner":"bob"},{"size":{"$lt":10}}]}}`, []string{"asset1"})
+	testQuery(t, db, `{"selector":{"owner":"bob"},"limit":2}`, []string{"asset1", "asset3"})
+	testQuery(t, db, `{"selector":{"owner":"bob"},"skip":1}`, []string{"asset3", "asset5"})
+	testQuery(t, db, `{"selector":{"owner":"carol"}}`, []string{})
+
+	for _, invalidQuery := range []string{
+		`not json`,
+		`{"limit":10}`,
+		`{"selector":{"owner":"bob"},"fields":["owner"]}`,
+		`{"selector":{"owner":{"$regex":"^b"}}}`,
+		`{"selector":{"$or":{"owner":"bob"}}}`,
+		`{"selector":{"size":{"$gt":true}}}`,
+		`{"selector":{"owner":"bob"},"limit":-1}`,
+	} {
+		_, err := db.ExecuteQuery(invalidQuery)
+		testutil.AssertError(t, err, invalidQuery)
+	}
+}
+
+func testQuery(t *testing.T, db statedb.VersionedDB, query string, expectedKeys []string) {
+	itr, err := db.ExecuteQuery(query)
+	testutil.AssertNoError(t, err, query)
+	defer itr.Close()
+	keys := []string{}
+	for {
+		vkv, err := itr.Next()
+		testutil.AssertNoError(t, err, query)
+		if vkv == nil {
+			break
+		}
+		keys = append(keys, vkv.Key)
+	}
+	testutil.AssertEquals(t, keys, expectedKeys)
+}
@@ -17,14 +17,18 @@ limitations under the License.
 package commontests
 
 import (
+	"os"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/couchdbtxmgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr/lockbasedtxmgr"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/spf13/viper"
 )
@@ -37,20 +41,30 @@ type testEnv interface {
 	cleanup()
 }
 
-var testEnvs = []testEnv{&levelDBLockBasedEnv{}}
+var testEnvs = []testEnv{&levelDBLockBasedEnv{}, &levelDBLockBasedEnv{cacheSize: 100}}
 
 type levelDBLockBasedEnv struct {
+	cacheSize int
 	testDBEnv *stateleveldb.TestVDBEnv
 	testDB    statedb.VersionedDB
 	txmgr     txmgr.TxMgr
 }
 
 func (env *levelDBLockBasedEnv) getName() string {
+	if env.cacheSize > 0 {
+		return "cachedLevelDB_LockBasedTxMgr"
+	}
 	return "levelDB_LockBasedTxMgr"
 }
 
 func (env *levelDBLockBasedEnv) init(t *testing.T) {
+	env.initEnv(t)
+}
+
+func (env *levelDBLockBasedEnv) initEnv(t testing.TB) {
 	viper.Set("peer.fileSystemPath", "/tmp/fabric/ledgertests")
+	viper.Set("ledger.state.cacheSize", env.cacheSize)
+	defer viper.Set("ledger.state.cacheSize", 0)
 	testDBEnv := stateleveldb.NewTestVDBEnv(t)
 	testDB := testDBEnv.DBProvider.GetDBHandle("TestDB")
 	txMgr := lockbasedtxmgr.NewLockBasedTxMgr(testDB)
@@ -72,6 +86,56 @@ func (env *levelDBLockBasedEnv) cleanup() {
 	defer env.testDBEnv.Cleanup()
 }
 
+// allTxMgrTestEnvs returns the test environments of all the transaction managers. The CouchDB
+// one is only included when CouchDB is enabled in core.yaml, as it requires a running CouchDB
+func allTxMgrTestEnvs() []testEnv {
+	testutil.SetupCoreYAMLConfig("./../../../../../../peer")
+	if ledgerconfig.IsCouchDBEnabled() {
+		return append(testEnvs, &couchDBTxMgrEnv{})
+	}
+	return testEnvs
+}
+
+const couchDBTestDatabaseName = "system_commontests"
+
+type couchDBTxMgrEnv struct {
+	dbPath string
+	txmgr  *couchdbtxmgmt.CouchDBTxMgr
+}
+
+func (env *couchDBTxMgrEnv) getName() string {
+	return "couchDB_CouchDBTxMgr"
+}
+
+func (env *couchDBTxMgrEnv) init(t *testing.T) {
+	env.dbPath = "/tmp/fabric/ledgertests/commontests/couchdbtxmgmt"
+	env.dropDatabase()
+	os.RemoveAll(env.dbPath)
+	couchDBDef := ledgerconfig.GetCouchDBDefinition()
+	env.txmgr = couchdbtxmgmt.NewCouchDBTxMgr(&couchdbtxmgmt.Conf{DBPath: env.dbPath},
+		couchDBDef.URL, couchDBTestDatabaseName, couchDBDef.Username, couchDBDef.Password)
+}
+
+func (env *couchDBTxMgrEnv) getTxMgr() txmgr.TxMgr {
+	return env.txmgr
+}
+
+func (env *couchDBTxMgrEnv) getVDB() statedb.VersionedDB {
+	return nil
+}
+
+func (env *couchDBTxMgrEnv) cleanup() {
+	env.txmgr.Shutdown()
+	env.dropDatabase()
+	os.RemoveAll(env.dbPath)
+}
+
+func (env *couchDBTxMgrEnv) dropDatabase() {
+	couchDBDef := ledgerconfig.GetCouchDBDefinition()
+	couchDB, _ := couchdb.CreateConnectionDefinition(couchDBDef.URL, couchDBTestDatabaseName, couchDBDef.Username, couchDBDef.Password)
+	couchDB.DropDatabase()
+}
+
 type txMgrTestHelper struct {
 	t     *testing.T
 	txMgr txmgr.TxMgr
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package commontests
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	logging "github.com/op/go-logging"
+)
+
+const (
+	benchmarkNumKeys      = 10000
+	benchmarkNumHotKeys   = 100
+	benchmarkReadsPerTx   = 10
+	benchmarkValueSizeMul = 20
+)
+
+// BenchmarkTxSimulation measures the throughput of endorsements that read hot keys, with and without the state cache.
+// Run with: go test -run XXX -bench TxSimulation -cpu 1,4
+func BenchmarkTxSimulation(b *testing.B) {
+	// debug logging would dominate the measurements
+	testutil.SetLogLevel(logging.WARNING, "")
+	defer testutil.SetLogLevel(logging.DEBUG, "")
+	for _, env := range []*levelDBLockBasedEnv{{}, {cacheSize: 1000}} {
+		b.Run(env.getName(), func(b *testing.B) {
+			env.initEnv(b)
+			defer env.cleanup()
+			populateBenchmarkState(b, env.getVDB())
+			benchmarkTxSimulation(b, env)
+		})
+	}
+}
+
+func populateBenchmarkState(b *testing.B, vdb statedb.VersionedDB) {
+	batch := statedb.NewUpdateBatch()
+	for i := 1; i <= benchmarkNumKeys; i++ {
+		value := make([]byte, 0, len(createTestValue(i))*benchmarkValueSizeMul)
+		for j := 0; j < benchmarkValueSizeMul; j++ {
+			value = append(value, createTestValue(i)...)
+		}
+		batch.Put("ns1", createTestKey(i), value, version.NewHeight(1, uint64(i)))
+	}
+	if err := vdb.ApplyUpdates(batch, version.NewHeight(1, benchmarkNumKeys)); err != nil {
+		b.Fatalf("Error while populating the state: %s", err)
+	}
+}
+
+func benchmarkTxSimulation(b *testing.B, env *levelDBLockBasedEnv) {
+	txMgr := env.getTxMgr()
+	var txNum uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := int(atomic.AddUint64(&txNum, 1))
+			s, err := txMgr.NewTxSimulator()
+			if err != nil {
+				b.Fatalf("Error while creating the simulator: %s", err)
+			}
+			for i := 0; i < benchmarkReadsPerTx; i++ {
+				if _, err = s.GetState("ns1", createTestKey(1+(n+i)%benchmarkNumHotKeys)); err != nil {
+					b.Fatalf("Error while reading the state: %s", err)
+				}
+			}
+			s.SetState("ns1", createTestKey(1+n%benchmarkNumKeys), createTestValue(n))
+			s.Done()
+			if _, err = s.GetTxSimulationResults(); err != nil {
+				b.Fatalf("Error while getting the simulation results: %s", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package commontests
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	for _, testEnv := range allTxMgrTestEnvs() {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		testReadYourWrites(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testReadYourWrites(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	s1, _ := txMgr.NewTxSimulator()
+	for i := 1; i <= 4; i++ {
+		s1.SetState("ns1", createTestKey(i), createTestValue(i))
+	}
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1)
+
+	// update key2, delete key3 and add key5
+	s2, _ := txMgr.NewTxSimulator()
+	defer s2.Done()
+	s2.SetState("ns1", createTestKey(2), []byte("newValue2"))
+	s2.DeleteState("ns1", createTestKey(3))
+	s2.SetState("ns1", createTestKey(5), createTestValue(5))
+
+	value, _ := s2.GetState("ns1", createTestKey(2))
+	testutil.AssertEquals(t, value, []byte("newValue2"))
+	value, _ = s2.GetState("ns1", createTestKey(3))
+	testutil.AssertNil(t, value)
+	value, _ = s2.GetState("ns1", createTestKey(5))
+	testutil.AssertEquals(t, value, createTestValue(5))
+
+	values, err := s2.GetStateMultipleKeys("ns1", []string{createTestKey(1), createTestKey(2), createTestKey(3), createTestKey(5)})
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, values, [][]byte{createTestValue(1), []byte("newValue2"), nil, createTestValue(5)})
+
+	itr, err := s2.GetStateRangeScanIterator("ns1", "", "")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, collectKVs(t, itr), []*ledger.KV{
+		{Key: createTestKey(1), Value: createTestValue(1)},
+		{Key: createTestKey(2), Value: []byte("newValue2")},
+		{Key: createTestKey(4), Value: createTestValue(4)},
+		{Key: createTestKey(5), Value: createTestValue(5)}})
+
+	itr, err = s2.GetStateRangeScanIterator("ns1", createTestKey(2), createTestKey(5))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, collectKVs(t, itr), []*ledger.KV{
+		{Key: createTestKey(2), Value: []byte("newValue2")},
+		{Key: createTestKey(4), Value: createTestValue(4)}})
+
+	_, err = s2.ExecuteQuery(`{"selector":{"owner":"bob"}}`)
+	testutil.AssertSame(t, err, txmgr.ErrQueryAfterWrites)
+
+	// only the committed keys are read, the writes are recorded as made
+	txRWSet2Bytes, _ := s2.GetTxSimulationResults()
+	txRWSet2 := &rwset.TxReadWriteSet{}
+	testutil.AssertNoError(t, txRWSet2.Unmarshal(txRWSet2Bytes), "")
+	testutil.AssertEquals(t, len(txRWSet2.NsRWs), 1)
+	readKeys := []string{}
+	for _, kvRead := range txRWSet2.NsRWs[0].Reads {
+		readKeys = append(readKeys, kvRead.Key)
+	}
+	sort.Strings(readKeys)
+	testutil.AssertEquals(t, readKeys, []string{createTestKey(1), createTestKey(2), createTestKey(3), createTestKey(4)})
+	writes := map[string]*rwset.KVWrite{}
+	for _, kvWrite := range txRWSet2.NsRWs[0].Writes {
+		writes[kvWrite.Key] = kvWrite
+	}
+	testutil.AssertEquals(t, len(writes), 3)
+	testutil.AssertEquals(t, writes[createTestKey(2)].Value, []byte("newValue2"))
+	testutil.AssertEquals(t, writes[createTestKey(3)].IsDelete, true)
+	testutil.AssertEquals(t, writes[createTestKey(5)].Value, createTestValue(5))
+}
+
+func TestReadYourWritesWithNoExistingData(t *testing.T) {
+	for _, testEnv := range allTxMgrTestEnvs() {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		testReadYourWritesWithNoExistingData(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testReadYourWritesWithNoExistingData(t *testing.T, env testEnv) {
+	s, _ := env.getTxMgr().NewTxSimulator()
+	defer s.Done()
+	s.SetState("ns1", createTestKey(2), createTestValue(2))
+	s.SetState("ns1", createTestKey(1), createTestValue(1))
+	s.SetState("ns1", createTestKey(3), createTestValue(3))
+	s.DeleteState("ns1", createTestKey(3))
+	s.SetState("ns2", createTestKey(4), createTestValue(4))
+
+	itr, err := s.GetStateRangeScanIterator("ns1", "", "")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, collectKVs(t, itr), []*ledger.KV{
+		{Key: createTestKey(1), Value: createTestValue(1)},
+		{Key: createTestKey(2), Value: createTestValue(2)}})
+
+	itr, err = s.GetStateRangeScanIterator("ns3", "", "")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, collectKVs(t, itr), []*ledger.KV{})
+}
+
+func collectKVs(t *testing.T, itr ledger.ResultsIterator) []*ledger.KV {
+	defer itr.Close()
+	kvs := []*ledger.KV{}
+	for {
+		result, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		if result == nil {
+			return kvs
+		}
+		kvs = append(kvs, result.(*ledger.KV))
+	}
+}
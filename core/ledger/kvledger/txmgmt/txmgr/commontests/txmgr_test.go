@@ -367,6 +367,63 @@ func testTxValidationWithItr(t *testing.T, env testEnv) {
 	txMgrHelper.validateAndCommitRWSet(txRWSet2)
 }
 
+func TestTxValidationWithPhantomRead(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testEnv.init(t)
+		testTxValidationWithPhantomRead(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testTxValidationWithPhantomRead(t *testing.T, env testEnv) {
+	cID := "cID"
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+
+	// simulate tx1
+	s1, _ := txMgr.NewTxSimulator()
+	s1.SetState(cID, createTestKey(1), createTestValue(1))
+	s1.SetState(cID, createTestKey(2), createTestValue(2))
+	s1.SetState(cID, createTestKey(4), createTestValue(4))
+	s1.Done()
+	// validate and commit RWset
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1)
+
+	// simulate tx2 that reads the full range key_001 to key_004
+	s2, _ := txMgr.NewTxSimulator()
+	itr, _ := s2.GetStateRangeScanIterator(cID, createTestKey(1), createTestKey(5))
+	for kv, _ := itr.Next(); kv != nil; kv, _ = itr.Next() {
+	}
+	itr.Close()
+	s2.Done()
+
+	// simulate tx3 that reads only key_001 from the range
+	s3, _ := txMgr.NewTxSimulator()
+	itr, _ = s3.GetStateRangeScanIterator(cID, createTestKey(1), createTestKey(5))
+	itr.Next()
+	itr.Close()
+	s3.Done()
+
+	// simulate tx4 before committing tx2 and tx3. Inserts a key within the range read by tx2
+	s4, _ := txMgr.NewTxSimulator()
+	s4.SetState(cID, createTestKey(3), createTestValue(3))
+	s4.Done()
+
+	// validate and commit RWset for tx4
+	txRWSet4, _ := s4.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet4)
+
+	// tx2 should be invalid because of the phantom key_003
+	txRWSet2, _ := s2.GetTxSimulationResults()
+	txMgrHelper.checkRWsetInvalid(txRWSet2)
+
+	// tx3 should still be valid as it did not read past key_001
+	txRWSet3, _ := s3.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet3)
+}
+
 func TestGetSetMultipeKeys(t *testing.T) {
 	for _, testEnv := range testEnvs {
 		t.Logf("Running test for TestEnv = %s", testEnv.getName())
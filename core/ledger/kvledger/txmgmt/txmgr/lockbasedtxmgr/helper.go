@@ -65,7 +65,12 @@ func (h *queryHelper) getStateRangeScanIterator(namespace string, startKey strin
 	if err != nil {
 		return nil, err
 	}
-	return &resultsItr{DBItr: dbItr, RWSet: h.rwset}, nil
+	itr := &resultsItr{DBItr: dbItr, RWSet: h.rwset}
+	if h.rwset != nil {
+		itr.RangeQueryInfo = &rwset.RangeQueryInfo{StartKey: startKey, EndKey: endKey}
+		h.rwset.AddToRangeQuerySet(namespace, itr.RangeQueryInfo)
+	}
+	return itr, nil
 }
 
 func (h *queryHelper) executeQuery(query string) (ledger.ResultsIterator, error) {
@@ -90,6 +95,9 @@ func (h *queryHelper) checkDone() {
 type resultsItr struct {
 	DBItr statedb.ResultsIterator
 	RWSet *rwset.RWSet
+	// RangeQueryInfo records the results of a range query for phantom read
+	// validation, it is nil for other queries
+	RangeQueryInfo *rwset.RangeQueryInfo
 }
 
 // Next implements method in interface ledger.ResultsIterator
@@ -99,11 +107,17 @@ func (itr *resultsItr) Next() (ledger.QueryResult, error) {
 		return nil, err
 	}
 	if versionedKV == nil {
+		if itr.RangeQueryInfo != nil {
+			itr.RangeQueryInfo.ItrExhausted = true
+		}
 		return nil, nil
 	}
 	if itr.RWSet != nil {
 		itr.RWSet.AddToReadSet(versionedKV.Namespace, versionedKV.Key, versionedKV.Version)
 	}
+	if itr.RangeQueryInfo != nil {
+		itr.RangeQueryInfo.Results = append(itr.RangeQueryInfo.Results, rwset.NewKVRead(versionedKV.Key, versionedKV.Version))
+	}
 	return &ledger.KV{Key: versionedKV.Key, Value: versionedKV.Value}, nil
 }
 
@@ -20,7 +20,9 @@ import (
 	"errors"
 
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 )
 
 // LockBasedTxSimulator is a transaction simulator used in `LockBasedTxMgr`
@@ -48,6 +50,36 @@ func (s *lockBasedTxSimulator) GetState(ns string, key string) ([]byte, error) {
 	return s.helper.getState(ns, key)
 }
 
+// GetStateMultipleKeys implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := s.GetState(namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// GetStateRangeScanIterator implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ledger.ResultsIterator, error) {
+	itr, err := s.helper.getStateRangeScanIterator(namespace, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	return txmgr.NewRYWRangeScanIterator(itr, s.rwset.GetWritesInRange(namespace, startKey, endKey)), nil
+}
+
+// ExecuteQuery implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) ExecuteQuery(query string) (ledger.ResultsIterator, error) {
+	if s.rwset.HasWrites() {
+		return nil, txmgr.ErrQueryAfterWrites
+	}
+	return s.helper.executeQuery(query)
+}
+
 // SetState implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) SetState(ns string, key string, value []byte) error {
 	s.helper.checkDone()
@@ -53,6 +53,9 @@ func (txmgr *LockBasedTxMgr) GetBlockNumFromSavepoint() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	if height == nil {
+		return 0, nil
+	}
 	return height.BlockNum, nil
 }
 
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package txmgr
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
+)
+
+// The transaction simulators of all the transaction managers read their own writes:
+// - GetState and GetStateMultipleKeys return the value last written by the transaction, nil for a deleted key.
+//   Only the keys that are read from the state database are added to the read set
+// - GetStateRangeScanIterator merges the writes of the transaction into the committed results. The keys deleted
+//   by the transaction are skipped and the keys written are returned with their new value, in key order.
+//   The read set and the range query info record the committed results only
+// - ExecuteQuery fails with ErrQueryAfterWrites once the transaction has written to the state, as the results
+//   of a rich query cannot reflect writes that are not committed
+
+// ErrQueryAfterWrites is returned by the ExecuteQuery of a transaction simulator that has written to the state
+var ErrQueryAfterWrites = errors.New("ExecuteQuery is not supported after the transaction has written to the state")
+
+// rywRangeScanIterator merges the writes of a transaction into the results of a range scan
+type rywRangeScanIterator struct {
+	committed     ledger.ResultsIterator
+	nextCommitted *ledger.KV
+	committedDone bool
+	writes        []*rwset.KVWrite
+}
+
+// NewRYWRangeScanIterator returns an iterator over the committed results of a range scan merged with
+// the writes that the transaction made in the range, which must be sorted by key
+func NewRYWRangeScanIterator(committed ledger.ResultsIterator, writes []*rwset.KVWrite) ledger.ResultsIterator {
+	return &rywRangeScanIterator{committed: committed, writes: writes}
+}
+
+// Next implements method in interface ledger.ResultsIterator
+func (itr *rywRangeScanIterator) Next() (ledger.QueryResult, error) {
+	for {
+		if itr.nextCommitted == nil && !itr.committedDone {
+			result, err := itr.committed.Next()
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				itr.committedDone = true
+			} else {
+				itr.nextCommitted = result.(*ledger.KV)
+			}
+		}
+		if len(itr.writes) == 0 || (itr.nextCommitted != nil && itr.nextCommitted.Key < itr.writes[0].Key) {
+			if itr.nextCommitted == nil {
+				return nil, nil
+			}
+			kv := itr.nextCommitted
+			itr.nextCommitted = nil
+			return kv, nil
+		}
+		kvWrite := itr.writes[0]
+		itr.writes = itr.writes[1:]
+		if itr.nextCommitted != nil && itr.nextCommitted.Key == kvWrite.Key {
+			itr.nextCommitted = nil
+		}
+		if !kvWrite.IsDelete {
+			return &ledger.KV{Key: kvWrite.Key, Value: kvWrite.Value}, nil
+		}
+	}
+}
+
+// Close implements method in interface ledger.ResultsIterator
+func (itr *rywRangeScanIterator) Close() {
+	itr.committed.Close()
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package statebasedval
 
 import (
+	"sort"
+
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
@@ -54,7 +56,7 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 		}
 
 		// extract actions from the envelope message
-		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		respPayload, hashedValues, err := putils.GetActionAndHashedValuesFromEnvelope(envBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -68,6 +70,14 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 			return nil, err
 		}
 
+		// put back the values that were replaced by their hashes. The transaction
+		// is invalid if they are missing or do not match the endorsed hashes
+		if err = txRWSet.ResolveHashedValues(hashedValues); err != nil {
+			logger.Warningf("Marking transaction as invalid, txIndex=%d: %s", txIndex, err)
+			txsFilter.Set(uint(txIndex))
+			continue
+		}
+
 		// trace the first 2000 characters of RWSet only, in case it is huge
 		if logger.IsEnabledFor(logging.DEBUG) {
 			txRWSetString := txRWSet.String()
@@ -130,6 +140,77 @@ func (v *Validator) validateTx(txRWSet *rwset.TxReadWriteSet, updates *statedb.U
 				return false, nil
 			}
 		}
+		for _, rqi := range nsRWSet.RangeQueriesInfo {
+			if valid, err := v.validateRangeQuery(ns, rqi, updates); !valid || err != nil {
+				return valid, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// validateRangeQuery re-runs the range query against the committed state and the
+// updates of the preceding valid transactions in the block. The tx is invalid if
+// any key was added to, removed from or updated within the part of the range it read
+func (v *Validator) validateRangeQuery(ns string, rqi *rwset.RangeQueryInfo, updates *statedb.UpdateBatch) (bool, error) {
+	itr, err := v.db.GetStateRangeScanIterator(ns, rqi.StartKey, rqi.EndKey)
+	if err != nil {
+		return false, err
+	}
+	defer itr.Close()
+
+	versions := make(map[string]*version.Height)
+	for {
+		versionedKV, err := itr.Next()
+		if err != nil {
+			return false, err
+		}
+		if versionedKV == nil {
+			break
+		}
+		versions[versionedKV.Key] = versionedKV.Version
+	}
+
+	for compositeKey, versionedValue := range updates.KVs {
+		if compositeKey.Namespace != ns || !isInRange(compositeKey.Key, rqi.StartKey, rqi.EndKey) {
+			continue
+		}
+		if versionedValue.Value == nil {
+			delete(versions, compositeKey.Key)
+		} else {
+			versions[compositeKey.Key] = versionedValue.Version
+		}
+	}
+
+	keys := make([]string, 0, len(versions))
+	for key := range versions {
+		// when the tx did not read the range till the end, only the keys up to
+		// the last one it read matter
+		if !rqi.ItrExhausted && (len(rqi.Results) == 0 || key > rqi.Results[len(rqi.Results)-1].Key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if len(keys) != len(rqi.Results) {
+		logger.Debugf("Phantom read for range [%s:%s-%s]. %d keys in range now, %d keys read",
+			ns, rqi.StartKey, rqi.EndKey, len(keys), len(rqi.Results))
+		return false, nil
+	}
+	for i, key := range keys {
+		kvRead := rqi.Results[i]
+		if key != kvRead.Key || !version.AreSame(versions[key], kvRead.Version) {
+			logger.Debugf("Phantom read for range [%s:%s-%s]. Key [%s] with version [%s] now, key [%s] with version [%s] read",
+				ns, rqi.StartKey, rqi.EndKey, key, versions[key], kvRead.Key, kvRead.Version)
+			return false, nil
+		}
 	}
 	return true, nil
 }
+
+// isInRange checks whether the key is within [startKey, endKey). An empty
+// endKey means the range has no upper bound
+func isInRange(key string, startKey string, endKey string) bool {
+	return key >= startKey && (endKey == "" || key < endKey)
+}
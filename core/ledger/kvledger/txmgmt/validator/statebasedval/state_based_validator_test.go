@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwset"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
@@ -27,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 )
 
@@ -76,6 +78,113 @@ func TestValidator(t *testing.T) {
 	checkValidation(t, validator, []*rwset.RWSet{rwset4, rwset5}, []int{1})
 }
 
+func TestPhantomValidation(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+
+	db := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	//populate db with initial data
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 2))
+	batch.Put("ns1", "key3", []byte("value3"), version.NewHeight(1, 3))
+	batch.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 5))
+	db.ApplyUpdates(batch, version.NewHeight(1, 5))
+
+	validator := NewValidator(db)
+
+	//rwset1 should be valid - the range is unchanged
+	rwset1 := rwset.NewRWSet()
+	rwset1.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key1", EndKey: "key4", ItrExhausted: true,
+		Results: []*rwset.KVRead{
+			rwset.NewKVRead("key1", version.NewHeight(1, 1)),
+			rwset.NewKVRead("key2", version.NewHeight(1, 2)),
+			rwset.NewKVRead("key3", version.NewHeight(1, 3))}})
+	checkValidation(t, validator, []*rwset.RWSet{rwset1}, []int{})
+
+	//rwset2 should not be valid - key3 was not seen by the range query
+	rwset2 := rwset.NewRWSet()
+	rwset2.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key1", EndKey: "key4", ItrExhausted: true,
+		Results: []*rwset.KVRead{
+			rwset.NewKVRead("key1", version.NewHeight(1, 1)),
+			rwset.NewKVRead("key2", version.NewHeight(1, 2))}})
+	checkValidation(t, validator, []*rwset.RWSet{rwset2}, []int{0})
+
+	//rwset3 should be valid - the range was not read past key2
+	rwset3 := rwset.NewRWSet()
+	rwset3.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key1", EndKey: "", ItrExhausted: false,
+		Results: []*rwset.KVRead{
+			rwset.NewKVRead("key1", version.NewHeight(1, 1)),
+			rwset.NewKVRead("key2", version.NewHeight(1, 2))}})
+	checkValidation(t, validator, []*rwset.RWSet{rwset3}, []int{})
+
+	//rwset4 inserts key4 and deletes key2 in the same block - rwset5 and rwset6 see phantoms, rwset7 does not
+	rwset4 := rwset.NewRWSet()
+	rwset4.AddToWriteSet("ns1", "key4", []byte("value4"))
+	rwset4.AddToWriteSet("ns1", "key2", nil)
+	rwset5 := rwset.NewRWSet()
+	rwset5.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key3", EndKey: "key5", ItrExhausted: true,
+		Results: []*rwset.KVRead{rwset.NewKVRead("key3", version.NewHeight(1, 3))}})
+	rwset6 := rwset.NewRWSet()
+	rwset6.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key1", EndKey: "key3", ItrExhausted: true,
+		Results: []*rwset.KVRead{
+			rwset.NewKVRead("key1", version.NewHeight(1, 1)),
+			rwset.NewKVRead("key2", version.NewHeight(1, 2))}})
+	rwset7 := rwset.NewRWSet()
+	rwset7.AddToRangeQuerySet("ns1", &rwset.RangeQueryInfo{StartKey: "key5", EndKey: "", ItrExhausted: true,
+		Results: []*rwset.KVRead{rwset.NewKVRead("key5", version.NewHeight(1, 5))}})
+	checkValidation(t, validator, []*rwset.RWSet{rwset4, rwset5, rwset6, rwset7}, []int{1, 2})
+}
+
+func TestValidatorWithHashedValues(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+
+	db := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	validator := NewValidator(db)
+
+	largeValue := []byte("a value larger than the threshold")
+	rwset1 := rwset.NewRWSet()
+	rwset1.AddToWriteSet("ns1", "key1", largeValue)
+	txRWSet := rwset1.GetTxReadWriteSet()
+	hashedValues, err := txRWSet.HashLargeValues(10)
+	testutil.AssertNoError(t, err, "")
+	simulationResults, err := txRWSet.Marshal()
+	testutil.AssertNoError(t, err, "")
+
+	// first tx carries the values, second tx does not and should be invalid
+	block := testutil.ConstructBlock(t, [][]byte{simulationResults, simulationResults}, false)
+	block.Data.Data[0] = setHashedValues(t, block.Data.Data[0], hashedValues)
+	updates, err := validator.ValidateAndPrepareBatch(block, true)
+	testutil.AssertNoError(t, err, "")
+	txsFltr := util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	testutil.AssertEquals(t, txsFltr.IsSet(0), false)
+	testutil.AssertEquals(t, txsFltr.IsSet(1), true)
+	testutil.AssertEquals(t, updates.KVs[statedb.CompositeKey{Namespace: "ns1", Key: "key1"}].Value, largeValue)
+}
+
+func setHashedValues(t *testing.T, envBytes []byte, hashedValues []byte) []byte {
+	env, err := putils.GetEnvelopeFromBlock(envBytes)
+	testutil.AssertNoError(t, err, "")
+	payload, err := putils.GetPayload(env)
+	testutil.AssertNoError(t, err, "")
+	tx, err := putils.GetTransaction(payload.Data)
+	testutil.AssertNoError(t, err, "")
+	ccActionPayload, err := putils.GetChaincodeActionPayload(tx.Actions[0].Payload)
+	testutil.AssertNoError(t, err, "")
+
+	ccActionPayload.Action.HashedValues = hashedValues
+	tx.Actions[0].Payload, err = proto.Marshal(ccActionPayload)
+	testutil.AssertNoError(t, err, "")
+	payload.Data, err = proto.Marshal(tx)
+	testutil.AssertNoError(t, err, "")
+	env.Payload, err = proto.Marshal(payload)
+	testutil.AssertNoError(t, err, "")
+	envBytes, err = proto.Marshal(env)
+	testutil.AssertNoError(t, err, "")
+	return envBytes
+}
+
 func checkValidation(t *testing.T, validator *Validator, rwsets []*rwset.RWSet, invalidTxIndexes []int) {
 	simulationResults := [][]byte{}
 	for _, rwset := range rwsets {
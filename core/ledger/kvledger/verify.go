@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+// VerificationReport holds the outcome of verifying a ledger
+type VerificationReport struct {
+	LedgerID string
+	// Blocks is the outcome of verifying the block storage
+	Blocks *fsblkstorage.VerificationReport
+	// StateSavepoint is the number of the last block committed to the state DB
+	StateSavepoint uint64
+	// HistorySavepoint is the number of the last block committed to the history DB
+	HistorySavepoint uint64
+	// Problems describes each inconsistency found between the state DB or
+	// the history DB and the block storage
+	Problems []string
+	// StateRebuilt tells whether the state DB was rebuilt from the blocks
+	StateRebuilt bool
+	// HistoryRebuilt tells whether the history DB was rebuilt from the blocks
+	HistoryRebuilt bool
+}
+
+// Verify checks the consistency of the block storage, the state DB and the
+// history DB of a ledger. The ledger must not be open, which the peer being
+// stopped ensures. If repair is set, the block storage is repaired as
+// fsblkstorage.Verify does and the state DB and the history DB are brought up
+// to date by replaying the blocks. A database whose savepoint exceeds the
+// block height is cleared and rebuilt from the first block, which is not
+// possible for a ledger created from a snapshot
+func (provider *Provider) Verify(ledgerID string, repair bool) (*VerificationReport, error) {
+	exists, err := provider.idStore.ledgerIDExists(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNonExistingLedgerID
+	}
+
+	report := &VerificationReport{LedgerID: ledgerID}
+	blockStorageConf, indexConfig := getBlockStorageConf(ledgerID)
+	if report.Blocks, err = fsblkstorage.Verify(blockStorageConf, indexConfig, repair); err != nil {
+		return nil, err
+	}
+	height := report.Blocks.LastBlockNumber
+
+	var vdb statedb.VersionedDB
+	resetState, stateBehind := false, false
+	if provider.vdbProvider == nil {
+		logger.Warningf("Skipping the verification of the state of ledger [%s] as it is kept in CouchDB", ledgerID)
+	} else {
+		vdb = provider.vdbProvider.GetDBHandle(ledgerID)
+		savepoint, err := vdb.GetLatestSavePoint()
+		if err != nil {
+			return nil, err
+		}
+		if savepoint != nil {
+			report.StateSavepoint = savepoint.BlockNum
+		}
+		resetState, stateBehind = report.checkSavepoint("State DB", report.StateSavepoint, height)
+	}
+
+	historymgmt, err := newHistMgr(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	resetHistory, historyBehind := false, false
+	if historymgmt != nil {
+		if report.HistorySavepoint, err = historymgmt.GetBlockNumFromSavepoint(); err != nil {
+			return nil, err
+		}
+		resetHistory, historyBehind = report.checkSavepoint("History DB", report.HistorySavepoint, height)
+	}
+
+	if !repair || !(resetState || stateBehind || resetHistory || historyBehind) {
+		return report, nil
+	}
+	if resetState || resetHistory {
+		// the blocks before the first one of a ledger created from a
+		// snapshot are not available to replay
+		if report.Blocks.FirstBlockNumber > 1 {
+			report.addProblem("Databases cannot be rebuilt as the block storage starts at block [%d]",
+				report.Blocks.FirstBlockNumber)
+			return report, nil
+		}
+		if resetState {
+			if err = clearState(vdb); err != nil {
+				return nil, err
+			}
+		}
+		if resetHistory {
+			if err = historymgmt.Clear(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// opening the ledger replays the blocks following the savepoints
+	l, err := NewKVLedger(provider.vdbProvider, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	l.Close()
+	report.StateRebuilt = resetState || stateBehind
+	report.HistoryRebuilt = resetHistory || historyBehind
+	return report, nil
+}
+
+// checkSavepoint compares the savepoint of a database with the block height.
+// It tells whether the database is ahead of the blocks, which is a problem, or
+// behind them, which the ledger catches up with when it is opened
+func (r *VerificationReport) checkSavepoint(db string, savepoint, height uint64) (ahead bool, behind bool) {
+	if savepoint > height {
+		r.addProblem("%s savepoint [%d] exceeds the block height [%d]", db, savepoint, height)
+		return true, false
+	}
+	if savepoint < height {
+		logger.Infof("%s of ledger [%s] is [%d] blocks behind, it is caught up when the ledger is opened",
+			db, r.LedgerID, height-savepoint)
+		return false, true
+	}
+	return false, false
+}
+
+// clearState deletes all the keys of the state DB and resets its savepoint
+func clearState(vdb statedb.VersionedDB) error {
+	itr, err := vdb.GetStateFullScanIterator()
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+	batch := statedb.NewUpdateBatch()
+	for {
+		kv, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if kv == nil {
+			break
+		}
+		batch.Delete(kv.Namespace, kv.Key, kv.Version)
+	}
+	return vdb.ApplyUpdates(batch, version.NewHeight(0, 0))
+}
+
+func (r *VerificationReport) addProblem(format string, args ...interface{}) {
+	problem := fmt.Sprintf(format, args...)
+	logger.Warning(problem)
+	r.Problems = append(r.Problems, problem)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+func TestVerifyRebuildsState(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	p, _ := NewProvider()
+	provider := p.(*Provider)
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	for _, value := range []string{"value1", "value2", "value3"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.SetState("ns1", "key"+value, []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		testutil.AssertNoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes}, false)), "")
+	}
+	ledger.Close()
+
+	report, err := provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, report.Blocks.Problems, []string(nil))
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+	testutil.AssertEquals(t, report.StateSavepoint, uint64(3))
+
+	// corrupt the state and move its savepoint beyond the block height
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("corrupted"), version.NewHeight(5, 1))
+	batch.Put("ns1", "key4", []byte("value4"), version.NewHeight(5, 1))
+	provider.vdbProvider.GetDBHandle("testLedger").ApplyUpdates(batch, version.NewHeight(5, 1))
+
+	report, err = provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, len(report.Problems), 1)
+	testutil.AssertEquals(t, report.StateSavepoint, uint64(5))
+	testutil.AssertEquals(t, report.StateRebuilt, false)
+
+	report, err = provider.Verify("testLedger", true)
+	testutil.AssertNoError(t, err, "Error while repairing ledger")
+	testutil.AssertEquals(t, len(report.Problems), 1)
+	testutil.AssertEquals(t, report.StateRebuilt, true)
+
+	report, err = provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+	testutil.AssertEquals(t, report.StateSavepoint, uint64(3))
+
+	ledger, _ = provider.Open("testLedger")
+	defer ledger.Close()
+	qe, _ := ledger.NewQueryExecutor()
+	defer qe.Done()
+	value, _ := qe.GetState("ns1", "key1")
+	testutil.AssertEquals(t, value, []byte("value3"))
+	value, _ = qe.GetState("ns1", "keyvalue2")
+	testutil.AssertEquals(t, value, []byte("value2"))
+	value, _ = qe.GetState("ns1", "key4")
+	testutil.AssertNil(t, value)
+}
+
+func TestVerifyNonExistingLedger(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	p, _ := NewProvider()
+	provider := p.(*Provider)
+	defer provider.Close()
+	_, err := provider.Verify("testLedger", false)
+	testutil.AssertEquals(t, err, ErrNonExistingLedgerID)
+}
+
+func TestVerifyRebuildsHistory(t *testing.T) {
+	origNewHistMgr := newHistMgr
+	newHistMgr = newTestHistMgr
+	defer func() { newHistMgr = origNewHistMgr }()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	p, _ := NewProvider()
+	provider := p.(*Provider)
+	defer provider.Close()
+	ledger, _ := provider.Create("testLedger")
+
+	bg := testutil.NewBlockGenerator(t)
+	var blocks []*common.Block
+	for _, value := range []string{"value1", "value2", "value3"} {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		block := bg.NextBlock([][]byte{simRes}, false)
+		testutil.AssertNoError(t, ledger.Commit(block), "")
+		blocks = append(blocks, block)
+	}
+	ledger.Close()
+
+	report, err := provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+	testutil.AssertEquals(t, report.HistorySavepoint, uint64(3))
+
+	// the history DB holds a block that is not in the block storage
+	histMgr, _ := newTestHistMgr("testLedger")
+	testutil.AssertNoError(t, histMgr.Commit(bg.NextBlock([][]byte{}, false)), "")
+
+	report, err = provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, len(report.Problems), 1)
+	testutil.AssertEquals(t, report.HistorySavepoint, uint64(4))
+	testutil.AssertEquals(t, report.HistoryRebuilt, false)
+
+	report, err = provider.Verify("testLedger", true)
+	testutil.AssertNoError(t, err, "Error while repairing ledger")
+	testutil.AssertEquals(t, report.HistoryRebuilt, true)
+	testutil.AssertEquals(t, report.StateRebuilt, false)
+
+	report, err = provider.Verify("testLedger", false)
+	testutil.AssertNoError(t, err, "Error while verifying ledger")
+	testutil.AssertEquals(t, report.Problems, []string(nil))
+	testutil.AssertEquals(t, report.HistorySavepoint, uint64(3))
+	testDB := histMgr.(*testHistMgr).openDB()
+	defer testDB.Close()
+	for _, block := range blocks {
+		dataHash, _ := testDB.Get([]byte(fmt.Sprintf("block%d", block.Header.Number)))
+		testutil.AssertEquals(t, dataHash, block.Header.DataHash)
+	}
+	dataHash, _ := testDB.Get([]byte("block4"))
+	testutil.AssertNil(t, dataHash)
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package ledger
 
 import (
+	"io"
+
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -49,6 +52,10 @@ type RawLedger interface {
 type ValidatedLedgerProvider interface {
 	// CreateLedger creates a new ledger with a given unique id
 	Create(ledgerID string) (ValidatedLedger, error)
+	// CreateFromSnapshot creates a new ledger from a snapshot exported by ValidatedLedger.ExportSnapshot
+	// and returns it along with its id, which is the one recorded in the snapshot. The snapshot must match
+	// the hash returned by the export
+	CreateFromSnapshot(snapshot io.ReadSeeker, hash []byte) (string, ValidatedLedger, error)
 	// OpenLedger opens an already created ledger
 	Open(ledgerID string) (ValidatedLedger, error)
 	// Exists tells whether the ledger with given id exits
@@ -81,6 +88,13 @@ type ValidatedLedger interface {
 	NewHistoryQueryExecutor() (HistoryQueryExecutor, error)
 	// Commits block into the ledger
 	Commit(block *common.Block) error
+	// ExportSnapshot writes a snapshot of the ledger as of its last block, from which a new ledger can be created.
+	// The returned info holds the number of the last block and the hash of the snapshot
+	ExportSnapshot(w io.Writer) (*snapshot.SnapshotInfo, error)
+	// CreateStateIndexes creates the indexes shipped with a chaincode in the state database.
+	// The indexDefinitions map the names of the definition files to their contents.
+	// State databases that do not support indexes ignore the definitions
+	CreateStateIndexes(chaincodeName string, indexDefinitions map[string][]byte) error
 }
 
 // QueryExecutor executes the queries
@@ -110,7 +124,9 @@ type HistoryQueryExecutor interface {
 }
 
 // TxSimulator simulates a transaction on a consistent snapshot of the 'as recent state as possible'
-// Set* methods are for supporting KV-based data model. ExecuteUpdate method is for supporting a rich datamodel and query support
+// Set* methods are for supporting KV-based data model. ExecuteUpdate method is for supporting a rich datamodel and query support.
+// A TxSimulator reads its own writes: GetState, GetStateMultipleKeys and GetStateRangeScanIterator reflect the keys set or
+// deleted earlier in the simulation, whereas ExecuteQuery returns an error once the simulation has written to the state
 type TxSimulator interface {
 	QueryExecutor
 	// SetState sets the given value for the given namespace and key. For a chaincode, the namespace corresponds to the chaincodeId
@@ -44,6 +44,16 @@ func IsCouchDBEnabled() bool {
 	return false
 }
 
+// GetStateDatabase returns the name of the state database implementation used by the ledger.
+// Besides "CouchDB", the name refers to a state database registered with the statedb package,
+// such as "goleveldb" (the default) or "memory"
+func GetStateDatabase() string {
+	if name := viper.GetString("ledger.state.stateDatabase"); name != "" {
+		return name
+	}
+	return "goleveldb"
+}
+
 // GetRootPath returns the filesystem path.
 // All ledger related contents are expected to be stored under this path
 func GetRootPath() string {
@@ -72,6 +82,11 @@ func GetLedgerProviderPath() string {
 	return filepath.Join(GetRootPath(), "ledgerProvider")
 }
 
+// GetSnapshotsPath returns the filesystem path where the snapshots of the ledgers are written
+func GetSnapshotsPath() string {
+	return filepath.Join(GetRootPath(), "snapshots")
+}
+
 // GetMaxBlockfileSize returns the maximum size of the block file
 func GetMaxBlockfileSize() int {
 	return 0
@@ -87,6 +102,15 @@ func GetCouchDBDefinition() *CouchDBDef {
 	return &CouchDBDef{couchDBAddress, username, password}
 }
 
+// GetQueryPageSize returns the number of documents that are retrieved from CouchDB in a single
+// request while iterating over the results of a query
+func GetQueryPageSize() int {
+	if pageSize := viper.GetInt("ledger.state.couchDBConfig.queryPageSize"); pageSize > 0 {
+		return pageSize
+	}
+	return 1000
+}
+
 //IsHistoryDBEnabled exposes the historyDatabase variable
 //History database can only be enabled if couchDb is enabled
 //as it the history stored in the same couchDB instance.
@@ -98,3 +122,16 @@ func IsHistoryDBEnabled() bool {
 	}
 	return false
 }
+
+// GetValueHashThreshold returns the size in bytes above which the values written by a
+// transaction are replaced by their hashes in the endorsed read-write set and carried
+// along with the transaction instead. Zero or less disables the hashing
+func GetValueHashThreshold() int {
+	return viper.GetInt("ledger.state.valueHashThreshold")
+}
+
+// GetStateCacheSize returns the maximum number of keys of a ledger whose committed values are
+// cached in memory by the goleveldb state database. Zero or less disables the cache
+func GetStateCacheSize() int {
+	return viper.GetInt("ledger.state.cacheSize")
+}
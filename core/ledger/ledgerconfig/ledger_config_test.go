@@ -92,3 +92,38 @@ func setUpCoreYAMLConfig() {
 	//call a helper method to load the core.yaml
 	testutil.SetupCoreYAMLConfig("./../../../peer")
 }
+
+func TestGetValueHashThresholdDefault(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer testutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetValueHashThreshold(), 0) //test config is 0
+	viper.Set("ledger.state.valueHashThreshold", 1024)
+	testutil.AssertEquals(t, GetValueHashThreshold(), 1024)
+}
+
+func TestGetStateDatabase(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer testutil.ResetConfigToDefaultValues()
+	viper.Set("ledger.state.stateDatabase", "")
+	testutil.AssertEquals(t, GetStateDatabase(), "goleveldb")
+	viper.Set("ledger.state.stateDatabase", "memory")
+	testutil.AssertEquals(t, GetStateDatabase(), "memory")
+}
+
+func TestGetQueryPageSize(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer viper.Set("ledger.state.couchDBConfig.queryPageSize", 1000)
+	testutil.AssertEquals(t, GetQueryPageSize(), 1000)
+	viper.Set("ledger.state.couchDBConfig.queryPageSize", 10)
+	testutil.AssertEquals(t, GetQueryPageSize(), 10)
+	viper.Set("ledger.state.couchDBConfig.queryPageSize", 0)
+	testutil.AssertEquals(t, GetQueryPageSize(), 1000)
+}
+
+func TestGetStateCacheSize(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer viper.Set("ledger.state.cacheSize", 10000)
+	testutil.AssertEquals(t, GetStateCacheSize(), 10000) //test config is 10000
+	viper.Set("ledger.state.cacheSize", 0)
+	testutil.AssertEquals(t, GetStateCacheSize(), 0)
+}
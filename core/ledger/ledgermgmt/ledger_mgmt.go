@@ -18,6 +18,7 @@ package ledgermgmt
 
 import (
 	"errors"
+	"io"
 	"sync"
 
 	"fmt"
@@ -80,6 +81,25 @@ func CreateLedger(id string) (ledger.ValidatedLedger, error) {
 	return l, nil
 }
 
+// CreateLedgerFromSnapshot creates a new ledger from a snapshot matching the
+// given hash and returns it along with the id recorded in the snapshot
+func CreateLedgerFromSnapshot(snapshot io.ReadSeeker, hash []byte) (string, ledger.ValidatedLedger, error) {
+	logger.Info("Creating leadger from snapshot")
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return "", nil, ErrLedgerMgmtNotInitialized
+	}
+	id, l, err := ledgerProvider.CreateFromSnapshot(snapshot, hash)
+	if err != nil {
+		return "", nil, err
+	}
+	l = wrapLedger(id, l)
+	openedLedgers[id] = l
+	logger.Infof("Created leadger with id = %s from snapshot", id)
+	return id, l, nil
+}
+
 // OpenLedger returns a ledger for the given id
 func OpenLedger(id string) (ledger.ValidatedLedger, error) {
 	logger.Infof("Opening leadger with id = %s", id)
@@ -92,15 +92,10 @@ func ConstructTestBlocks(t *testing.T, numBlocks int) []*common.Block {
 // ConstructTransaction constructs a transaction for testing
 func ConstructTransaction(t *testing.T, simulationResults []byte, sign bool) (*common.Envelope, string, error) {
 	ccName := "foo"
-	txID := util.GenerateUUID()
-	var txEnv *common.Envelope
-	var err error
 	if sign {
-		txEnv, err = ptestutils.ConstructSingedTxEnvWithDefaultSigner(txID, util.GetTestChainID(), ccName, simulationResults, nil, nil)
-	} else {
-		txEnv, err = ptestutils.ConstructUnsingedTxEnv(txID, util.GetTestChainID(), ccName, simulationResults, nil, nil)
+		return ptestutils.ConstructSingedTxEnvWithDefaultSigner(util.GetTestChainID(), ccName, simulationResults, nil, nil)
 	}
-	return txEnv, txID, err
+	return ptestutils.ConstructUnsingedTxEnv(util.GetTestChainID(), ccName, simulationResults, nil, nil)
 }
 
 func newBlock(env []*common.Envelope, blockNum uint64, previousHash []byte) *common.Block {
@@ -90,6 +90,7 @@ func ResetConfigToDefaultValues() {
 	//reset to defaults
 	viper.Set("ledger.state.stateDatabase", "goleveldb")
 	viper.Set("ledger.state.historyDatabase", false)
+	viper.Set("ledger.state.valueHashThreshold", 0)
 }
 
 // SetLogLevel sets up log level
@@ -85,8 +85,57 @@ type RangeQueryResponse struct {
 
 //QueryResponse is used for processing REST query responses from CouchDB
 type QueryResponse struct {
-	Warning string            `json:"warning"`
-	Docs    []json.RawMessage `json:"docs"`
+	Warning  string            `json:"warning"`
+	Docs     []json.RawMessage `json:"docs"`
+	Bookmark string            `json:"bookmark"`
+}
+
+//BatchRetrieveResponse is used for processing REST responses of multi-key _all_docs requests
+type BatchRetrieveResponse struct {
+	Rows []struct {
+		ID    string `json:"id"`
+		Key   string `json:"key"`
+		Error string `json:"error"`
+		Value struct {
+			Rev     string `json:"rev"`
+			Deleted bool   `json:"deleted"`
+		} `json:"value"`
+		Doc json.RawMessage `json:"doc"`
+	} `json:"rows"`
+}
+
+//BatchUpdateResponse contains the outcome of the update of a single document in a _bulk_docs request
+type BatchUpdateResponse struct {
+	ID     string `json:"id"`
+	Rev    string `json:"rev"`
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+//CouchDoc defines a document to be saved or deleted in a batch.  The value is either the
+//JSONValue or, for binary values, the attachments
+type CouchDoc struct {
+	ID          string
+	Rev         string
+	JSONValue   []byte
+	Attachments []Attachment
+	Deleted     bool
+}
+
+//CreateIndexResponse contains the response of CouchDB to an index creation
+type CreateIndexResponse struct {
+	Result string `json:"result"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+}
+
+//IndexResult describes an index of the database
+type IndexResult struct {
+	DesignDocument string          `json:"ddoc"`
+	Name           string          `json:"name"`
+	Type           string          `json:"type"`
+	Definition     json.RawMessage `json:"def"`
 }
 
 //Doc is used for capturing if attachments are return in the query from CouchDB
@@ -772,6 +821,292 @@ func (dbclient *CouchDBConnectionDef) QueryDocuments(query string, limit, skip i
 
 }
 
+//QueryDocumentsWithBookmark method returns a page of at most pageSize results of a query and the bookmark
+//to pass for retrieving the next page.  An empty bookmark requests the first page.  The limit and the
+//bookmark override the corresponding fields of the query
+func (dbclient *CouchDBConnectionDef) QueryDocumentsWithBookmark(query string, pageSize int, bookmark string) ([]QueryResult, string, error) {
+
+	logger.Debugf("===COUCHDB=== Entering QueryDocumentsWithBookmark()  query=%s  bookmark=%s", query, bookmark)
+
+	jsonQuery := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(query), &jsonQuery); err != nil {
+		return nil, "", fmt.Errorf("Query is not a valid JSON: %s", err)
+	}
+	jsonQuery["limit"] = pageSize
+	delete(jsonQuery, "bookmark")
+	if bookmark != "" {
+		jsonQuery["bookmark"] = bookmark
+	}
+	queryBytes, err := json.Marshal(jsonQuery)
+	if err != nil {
+		return nil, "", err
+	}
+
+	queryURL, err := url.Parse(dbclient.URL)
+	if err != nil {
+		logger.Errorf("===COUCHDB=== URL parse error: %s", err.Error())
+		return nil, "", err
+	}
+	queryURL.Path = dbclient.Database + "/_find"
+
+	resp, _, err := dbclient.handleRequest(http.MethodPost, queryURL.String(), bytes.NewReader(queryBytes), "", "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse = &QueryResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(jsonResponse); err != nil {
+		return nil, "", err
+	}
+	if jsonResponse.Warning != "" {
+		logger.Debugf("===COUCHDB=== Query warning: %s", jsonResponse.Warning)
+	}
+
+	var results []QueryResult
+	for _, row := range jsonResponse.Docs {
+		var jsonDoc = &DocID{}
+		if err = json.Unmarshal(row, &jsonDoc); err != nil {
+			return nil, "", err
+		}
+		//TODO Replace the temporary NewHeight version when available
+		results = append(results, QueryResult{jsonDoc.ID, version.NewHeight(1, 1), row})
+	}
+
+	logger.Debugf("===COUCHDB=== Exiting QueryDocumentsWithBookmark()")
+
+	return results, jsonResponse.Bookmark, nil
+}
+
+//BatchRetrieveIDRevision method returns the current revisions of the documents with the given ids
+//in a single _all_docs request.  The documents that do not exist or are deleted are not included
+func (dbclient *CouchDBConnectionDef) BatchRetrieveIDRevision(keys []string) (map[string]string, error) {
+
+	logger.Debugf("===COUCHDB=== Entering BatchRetrieveIDRevision()  keys=%d", len(keys))
+
+	jsonResponse, err := dbclient.batchRetrieve(keys, false)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make(map[string]string)
+	for _, row := range jsonResponse.Rows {
+		if row.Error != "" || row.Value.Deleted {
+			continue
+		}
+		revisions[row.ID] = row.Value.Rev
+	}
+
+	logger.Debugf("===COUCHDB=== Exiting BatchRetrieveIDRevision()")
+
+	return revisions, nil
+}
+
+//BatchRetrieveDocuments method retrieves the documents with the given ids in a single _all_docs request.
+//The results are in the order of the ids and nil for the documents that do not exist.  As for ReadDoc,
+//the value is the content of the "valueBytes" attachment if the document has one, and the JSON otherwise
+func (dbclient *CouchDBConnectionDef) BatchRetrieveDocuments(keys []string) ([]*QueryResult, error) {
+
+	logger.Debugf("===COUCHDB=== Entering BatchRetrieveDocuments()  keys=%d", len(keys))
+
+	jsonResponse, err := dbclient.batchRetrieve(keys, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(jsonResponse.Rows) != len(keys) {
+		return nil, fmt.Errorf("Expected %d rows from CouchDB but received %d", len(keys), len(jsonResponse.Rows))
+	}
+
+	results := make([]*QueryResult, len(keys))
+	for i, row := range jsonResponse.Rows {
+		if row.Error != "" || row.Value.Deleted || len(row.Doc) == 0 || string(row.Doc) == "null" {
+			continue
+		}
+
+		var jsonDoc = &struct {
+			ID          string `json:"_id"`
+			Attachments map[string]struct {
+				Data []byte `json:"data"`
+			} `json:"_attachments"`
+		}{}
+		if err = json.Unmarshal(row.Doc, jsonDoc); err != nil {
+			return nil, err
+		}
+
+		value := []byte(row.Doc)
+		if attachment, ok := jsonDoc.Attachments["valueBytes"]; ok {
+			value = attachment.Data
+		}
+		//TODO Replace the temporary NewHeight version when available
+		results[i] = &QueryResult{jsonDoc.ID, version.NewHeight(1, 1), value}
+	}
+
+	logger.Debugf("===COUCHDB=== Exiting BatchRetrieveDocuments()")
+
+	return results, nil
+}
+
+func (dbclient *CouchDBConnectionDef) batchRetrieve(keys []string, includeDocs bool) (*BatchRetrieveResponse, error) {
+
+	batchURL, err := url.Parse(dbclient.URL)
+	if err != nil {
+		logger.Errorf("===COUCHDB=== URL parse error: %s", err.Error())
+		return nil, err
+	}
+	batchURL.Path = dbclient.Database + "/_all_docs"
+
+	if includeDocs {
+		queryParms := batchURL.Query()
+		queryParms.Add("include_docs", "true")
+		queryParms.Add("attachments", "true")
+		batchURL.RawQuery = queryParms.Encode()
+	}
+
+	keysBytes, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, _, err := dbclient.handleRequest(http.MethodPost, batchURL.String(), bytes.NewReader(keysBytes), "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse = &BatchRetrieveResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(jsonResponse); err != nil {
+		return nil, err
+	}
+	return jsonResponse, nil
+}
+
+//BatchUpdateDocuments method saves or deletes multiple documents in a single _bulk_docs request.
+//The updates are not atomic, hence the response for each document has to be checked for an error
+func (dbclient *CouchDBConnectionDef) BatchUpdateDocuments(docs []*CouchDoc) ([]*BatchUpdateResponse, error) {
+
+	logger.Debugf("===COUCHDB=== Entering BatchUpdateDocuments()  docs=%d", len(docs))
+
+	jsonDocs := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		jsonDoc := make(map[string]interface{})
+		if doc.JSONValue != nil && !doc.Deleted {
+			if err := json.Unmarshal(doc.JSONValue, &jsonDoc); err != nil {
+				return nil, fmt.Errorf("JSON format is not valid for document %s: %s", doc.ID, err)
+			}
+		}
+		jsonDoc["_id"] = doc.ID
+		if doc.Rev != "" {
+			jsonDoc["_rev"] = doc.Rev
+		}
+		if doc.Deleted {
+			jsonDoc["_deleted"] = true
+		} else if len(doc.Attachments) > 0 {
+			//attachments are sent inline, the data is base64 encoded by the JSON marshaller
+			attachments := make(map[string]interface{})
+			for _, attachment := range doc.Attachments {
+				attachments[attachment.Name] = map[string]interface{}{
+					"content_type": attachment.ContentType,
+					"data":         attachment.AttachmentBytes}
+			}
+			jsonDoc["_attachments"] = attachments
+		}
+		jsonDocs[i] = jsonDoc
+	}
+
+	bulkBytes, err := json.Marshal(map[string]interface{}{"docs": jsonDocs})
+	if err != nil {
+		return nil, err
+	}
+
+	bulkURL, err := url.Parse(dbclient.URL)
+	if err != nil {
+		logger.Errorf("===COUCHDB=== URL parse error: %s", err.Error())
+		return nil, err
+	}
+	bulkURL.Path = dbclient.Database + "/_bulk_docs"
+
+	resp, _, err := dbclient.handleRequest(http.MethodPost, bulkURL.String(), bytes.NewReader(bulkBytes), "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var responses []*BatchUpdateResponse
+	if err = json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("===COUCHDB=== Exiting BatchUpdateDocuments()")
+
+	return responses, nil
+}
+
+//CreateIndex method creates an index from a CouchDB index definition, for instance
+//{"index":{"fields":["owner"]},"ddoc":"indexOwnerDoc","name":"indexOwner","type":"json"}
+//Creating an index that already exists is not an error
+func (dbclient *CouchDBConnectionDef) CreateIndex(indexDefinition string) (*CreateIndexResponse, error) {
+
+	logger.Debugf("===COUCHDB=== Entering CreateIndex()  indexDefinition=%s", indexDefinition)
+
+	if IsJSON(indexDefinition) != true {
+		return nil, fmt.Errorf("JSON format is not valid")
+	}
+
+	indexURL, err := url.Parse(dbclient.URL)
+	if err != nil {
+		logger.Errorf("===COUCHDB=== URL parse error: %s", err.Error())
+		return nil, err
+	}
+	indexURL.Path = dbclient.Database + "/_index"
+
+	resp, _, err := dbclient.handleRequest(http.MethodPost, indexURL.String(), bytes.NewReader([]byte(indexDefinition)), "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var indexResponse = &CreateIndexResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(indexResponse); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("===COUCHDB=== Index %s is %s", indexResponse.Name, indexResponse.Result)
+
+	logger.Debugf("===COUCHDB=== Exiting CreateIndex()")
+
+	return indexResponse, nil
+}
+
+//ListIndexes method returns the indexes of the database
+func (dbclient *CouchDBConnectionDef) ListIndexes() ([]*IndexResult, error) {
+
+	logger.Debugf("===COUCHDB=== Entering ListIndexes()")
+
+	indexURL, err := url.Parse(dbclient.URL)
+	if err != nil {
+		logger.Errorf("===COUCHDB=== URL parse error: %s", err.Error())
+		return nil, err
+	}
+	indexURL.Path = dbclient.Database + "/_index"
+
+	resp, _, err := dbclient.handleRequest(http.MethodGet, indexURL.String(), nil, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse = &struct {
+		Indexes []*IndexResult `json:"indexes"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(jsonResponse); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("===COUCHDB=== Exiting ListIndexes()")
+
+	return jsonResponse.Indexes, nil
+}
+
 //handleRequest method is a generic http request handler
 func (dbclient *CouchDBConnectionDef) handleRequest(method, connectURL string, data io.Reader, rev string, multipartBoundary string) (*http.Response, *DBReturn, error) {
 
@@ -475,3 +475,119 @@ func TestDBReadDocumentRange(t *testing.T) {
 	}
 
 }
+
+func TestDBBatchOperations(t *testing.T) {
+
+	if ledgerconfig.IsCouchDBEnabled() == true {
+
+		cleanup()
+		defer cleanup()
+
+		//create a new connection
+		db, err := CreateConnectionDefinition(connectURL, database, username, password)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create database connection definition"))
+
+		//create a new database
+		_, errdb := db.CreateDatabaseIfNotExist()
+		testutil.AssertNoError(t, errdb, fmt.Sprintf("Error when trying to create database"))
+
+		//save a JSON and a binary document in a batch
+		binaryDoc := &CouchDoc{ID: "binary1", Attachments: []Attachment{{Name: "valueBytes", ContentType: "application/octet-stream", AttachmentBytes: []byte("binary value")}}}
+		responses, err := db.BatchUpdateDocuments([]*CouchDoc{{ID: "json1", JSONValue: assetJSON}, binaryDoc})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to save documents in a batch"))
+		testutil.AssertEquals(t, len(responses), 2)
+		for _, resp := range responses {
+			testutil.AssertEquals(t, resp.Error, "")
+		}
+
+		//retrieve the documents and a missing one in a batch
+		docs, err := db.BatchRetrieveDocuments([]string{"json1", "missing", "binary1"})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to retrieve documents in a batch"))
+		testutil.AssertEquals(t, len(docs), 3)
+		assetResp := &Asset{}
+		json.Unmarshal(docs[0].Value, &assetResp)
+		testutil.AssertEquals(t, assetResp.Owner, "jerry")
+		testutil.AssertNil(t, docs[1])
+		testutil.AssertEquals(t, docs[2].Value, []byte("binary value"))
+
+		//updating the documents requires their revisions
+		revisions, err := db.BatchRetrieveIDRevision([]string{"json1", "missing", "binary1"})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to retrieve revisions in a batch"))
+		testutil.AssertEquals(t, len(revisions), 2)
+		responses, err = db.BatchUpdateDocuments([]*CouchDoc{{ID: "json1", JSONValue: assetJSON}})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to save documents in a batch"))
+		testutil.AssertEquals(t, responses[0].Error, "conflict")
+
+		responses, err = db.BatchUpdateDocuments([]*CouchDoc{{ID: "json1", Rev: revisions["json1"], JSONValue: []byte(`{"asset_name":"marble1","color":"blue","size":"35","owner":"tom"}`)},
+			{ID: "binary1", Rev: revisions["binary1"], Deleted: true}})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to save documents in a batch"))
+		for _, resp := range responses {
+			testutil.AssertEquals(t, resp.Error, "")
+		}
+		docs, err = db.BatchRetrieveDocuments([]string{"json1", "binary1"})
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to retrieve documents in a batch"))
+		json.Unmarshal(docs[0].Value, &assetResp)
+		testutil.AssertEquals(t, assetResp.Owner, "tom")
+		testutil.AssertNil(t, docs[1])
+	}
+}
+
+func TestDBIndexAndQueryWithBookmark(t *testing.T) {
+
+	if ledgerconfig.IsCouchDBEnabled() == true {
+
+		cleanup()
+		defer cleanup()
+
+		//create a new connection
+		db, err := CreateConnectionDefinition(connectURL, database, username, password)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create database connection definition"))
+
+		//create a new database
+		_, errdb := db.CreateDatabaseIfNotExist()
+		testutil.AssertNoError(t, errdb, fmt.Sprintf("Error when trying to create database"))
+
+		indexResp, err := db.CreateIndex(`{"index":{"fields":["owner"]},"ddoc":"indexOwnerDoc","name":"indexOwner","type":"json"}`)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create an index"))
+		testutil.AssertEquals(t, indexResp.Name, "indexOwner")
+
+		indexes, err := db.ListIndexes()
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to list the indexes"))
+		testutil.AssertEquals(t, testutil.Contains(indexNames(indexes), "indexOwner"), true)
+
+		_, err = db.CreateIndex("not json")
+		testutil.AssertError(t, err, fmt.Sprintf("Error should have been thrown for an invalid index definition"))
+
+		var docs []*CouchDoc
+		for i := 0; i < 5; i++ {
+			docs = append(docs, &CouchDoc{ID: fmt.Sprintf("bob%d", i), JSONValue: []byte(`{"asset_name":"marble3","color":"green","size":"15","owner":"bob"}`)})
+		}
+		_, err = db.BatchUpdateDocuments(docs)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to save documents in a batch"))
+
+		//page through the query results
+		query := `{"selector":{"owner":"bob"}}`
+		var ids []string
+		bookmark := ""
+		for {
+			results, nextBookmark, err := db.QueryDocumentsWithBookmark(query, 2, bookmark)
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to query with a bookmark"))
+			for _, result := range results {
+				ids = append(ids, result.ID)
+			}
+			if len(results) < 2 {
+				break
+			}
+			bookmark = nextBookmark
+		}
+		testutil.AssertEquals(t, len(ids), 5)
+	}
+}
+
+func indexNames(indexes []*IndexResult) []string {
+	var names []string
+	for _, index := range indexes {
+		names = append(names, index.Name)
+	}
+	return names
+}
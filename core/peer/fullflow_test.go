@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -37,9 +38,8 @@ func getProposal() (*peer.Proposal, error) {
 			ChaincodeID: &peer.ChaincodeID{Name: "foo"},
 			Type:        peer.ChaincodeSpec_GOLANG}}
 
-	uuid := util.GenerateUUID()
-
-	return utils.CreateProposalFromCIS(uuid, util.GetTestChainID(), cis, signerSerialized)
+	prop, _, err := utils.CreateProposalFromCIS(util.GetTestChainID(), cis, signerSerialized)
+	return prop, err
 }
 
 func TestGoodPath(t *testing.T) {
@@ -67,7 +67,7 @@ func TestGoodPath(t *testing.T) {
 	simRes := []byte("simulation_result")
 
 	// endorse it to get a proposal response
-	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes, nil, nil, signer)
+	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -192,7 +192,7 @@ func TestBadTx(t *testing.T) {
 	simRes := []byte("simulation_result")
 
 	// endorse it to get a proposal response
-	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes, nil, nil, signer)
+	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -233,6 +233,99 @@ func TestBadTx(t *testing.T) {
 	}
 }
 
+// rewriteHeader returns a copy of prop whose header was changed by f
+func rewriteHeader(t *testing.T, prop *peer.Proposal, f func(hdr *common.Header)) *peer.Proposal {
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		t.Fatalf("GetHeader failed, err %s", err)
+	}
+	f(hdr)
+	hdrBytes, err := utils.GetBytesHeader(hdr)
+	if err != nil {
+		t.Fatalf("GetBytesHeader failed, err %s", err)
+	}
+	return &peer.Proposal{Header: hdrBytes, Payload: prop.Payload}
+}
+
+func TestReplayedProp(t *testing.T) {
+	// get a toy proposal
+	prop, err := getProposal()
+	if err != nil {
+		t.Fatalf("getProposal failed, err %s", err)
+		return
+	}
+
+	// choose the txID instead of deriving it
+	badProp := rewriteHeader(t, prop, func(hdr *common.Header) { hdr.ChainHeader.TxID = util.GenerateUUID() })
+	sProp, err := utils.GetSignedProposal(badProp, signer)
+	if err != nil {
+		t.Fatalf("GetSignedProposal failed, err %s", err)
+		return
+	}
+
+	// validate it - it should fail
+	_, _, _, err = ValidateProposalMessage(sProp)
+	if err == nil {
+		t.Fatalf("ValidateProposalMessage should have failed")
+		return
+	}
+
+	// a transaction assembled from that proposal should fail too
+	presp, err := utils.CreateProposalResponse(badProp.Header, badProp.Payload, nil, []byte("simulation_result"), nil, nil, signer)
+	if err != nil {
+		t.Fatalf("CreateProposalResponse failed, err %s", err)
+		return
+	}
+	tx, err := utils.CreateSignedTx(badProp, signer, presp)
+	if err != nil {
+		t.Fatalf("CreateSignedTx failed, err %s", err)
+		return
+	}
+	_, _, err = ValidateTransaction(tx)
+	if err == nil {
+		t.Fatalf("ValidateTransaction should have failed")
+		return
+	}
+
+	// replay the proposal an hour later
+	badProp = rewriteHeader(t, prop, func(hdr *common.Header) {
+		hdr.ChainHeader.Timestamp.Seconds -= int64(time.Hour / time.Second)
+	})
+	sProp, err = utils.GetSignedProposal(badProp, signer)
+	if err != nil {
+		t.Fatalf("GetSignedProposal failed, err %s", err)
+		return
+	}
+
+	// validate it - it should fail
+	_, _, _, err = ValidateProposalMessage(sProp)
+	if err == nil {
+		t.Fatalf("ValidateProposalMessage should have failed")
+		return
+	}
+}
+
+func TestCheckProposalTimestamp(t *testing.T) {
+	now := time.Now()
+	ts := util.CreateUtcTimestamp()
+
+	if err := checkProposalTimestamp(ts, now, time.Minute); err != nil {
+		t.Fatalf("A fresh timestamp should be accepted, err %s", err)
+	}
+	if err := checkProposalTimestamp(ts, now.Add(2*time.Minute), time.Minute); err == nil {
+		t.Fatalf("An old timestamp should be rejected")
+	}
+	if err := checkProposalTimestamp(ts, now.Add(-2*time.Minute), time.Minute); err == nil {
+		t.Fatalf("A timestamp in the future should be rejected")
+	}
+	if err := checkProposalTimestamp(ts, now.Add(2*time.Minute), 0); err != nil {
+		t.Fatalf("Any timestamp should be accepted without a window, err %s", err)
+	}
+	if err := checkProposalTimestamp(nil, now, time.Minute); err == nil {
+		t.Fatalf("A missing timestamp should be rejected")
+	}
+}
+
 func Test2EndorsersAgree(t *testing.T) {
 	// get a toy proposal
 	prop, err := getProposal()
@@ -244,7 +337,7 @@ func Test2EndorsersAgree(t *testing.T) {
 	simRes1 := []byte("simulation_result")
 
 	// endorse it to get a proposal response
-	presp1, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes1, nil, nil, signer)
+	presp1, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes1, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -253,7 +346,7 @@ func Test2EndorsersAgree(t *testing.T) {
 	simRes2 := []byte("simulation_result")
 
 	// endorse it to get a proposal response
-	presp2, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes2, nil, nil, signer)
+	presp2, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes2, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -285,7 +378,7 @@ func Test2EndorsersDisagree(t *testing.T) {
 	simRes1 := []byte("simulation_result1")
 
 	// endorse it to get a proposal response
-	presp1, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes1, nil, nil, signer)
+	presp1, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes1, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -294,7 +387,7 @@ func Test2EndorsersDisagree(t *testing.T) {
 	simRes2 := []byte("simulation_result2")
 
 	// endorse it to get a proposal response
-	presp2, err := utils.CreateProposalResponse(prop.Header, prop.Payload, simRes2, nil, nil, signer)
+	presp2, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, simRes2, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("CreateProposalResponse failed, err %s", err)
 		return
@@ -315,7 +408,7 @@ func TestMain(m *testing.M) {
 	// setup crypto algorithms
 	// setup the MSP manager so that we can sign/verify
 	mspMgrConfigDir := "../../msp/sampleconfig/"
-	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 	if err != nil {
 		fmt.Printf("Could not initialize msp, err %s", err)
 		os.Exit(-1)
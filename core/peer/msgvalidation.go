@@ -18,18 +18,50 @@ package peer
 
 import (
 	"fmt"
+	"time"
 
 	"bytes"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	mspmgmt "github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
+	"github.com/spf13/viper"
 )
 
 var putilsLogger = logging.MustGetLogger("protoutils")
 
+// defaultProposalTimeWindow is used when peer.endorser.proposalTimeWindow is not configured
+const defaultProposalTimeWindow = 15 * time.Minute
+
+// getProposalTimeWindow returns how far the timestamp of a proposal may be
+// from the local clock, a non-positive window disables the check
+func getProposalTimeWindow() time.Duration {
+	if !viper.IsSet("peer.endorser.proposalTimeWindow") {
+		return defaultProposalTimeWindow
+	}
+	return viper.GetDuration("peer.endorser.proposalTimeWindow")
+}
+
+// checkProposalTimestamp returns nil if ts is within window of now
+func checkProposalTimestamp(ts *timestamp.Timestamp, now time.Time, window time.Duration) error {
+	if window <= 0 {
+		return nil
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return fmt.Errorf("Invalid timestamp in the header, err %s", err)
+	}
+	if t.Before(now.Add(-window)) || t.After(now.Add(window)) {
+		return fmt.Errorf("The timestamp %s of the proposal is more than %s away from the time of the peer %s", t.UTC(), window, now.UTC())
+	}
+	return nil
+}
+
 // validateChaincodeProposalMessage checks the validity of a Proposal message of type CHAINCODE
 func validateChaincodeProposalMessage(prop *pb.Proposal, hdr *common.Header) (*pb.ChaincodeHeaderExtension, error) {
 	putilsLogger.Infof("validateChaincodeProposalMessage starts for proposal %p, header %p", prop, hdr)
@@ -85,7 +117,19 @@ func ValidateProposalMessage(signedProp *pb.SignedProposal) (*pb.Proposal, *comm
 
 	// TODO: ensure that creator can transact with us (some ACLs?) which set of APIs is supposed to give us this info?
 
-	// TODO: perform a check against replay attacks
+	// the transaction ID must be derived from the nonce and the creator, so
+	// that it cannot be chosen to collide with another transaction
+	err = utils.CheckProposalTxID(hdr.ChainHeader.TxID, hdr.SignatureHeader.Nonce, hdr.SignatureHeader.Creator)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// a proposal can only be replayed within the time window, the endorser
+	// then rejects it if its transaction is already in the ledger
+	err = checkProposalTimestamp(hdr.ChainHeader.Timestamp, time.Now(), getProposalTimeWindow())
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	// continue the validation in a way that depends on the type specified in the header
 	switch common.HeaderType(hdr.ChainHeader.Type) {
@@ -329,7 +373,13 @@ func ValidateTransaction(e *common.Envelope) (*common.Payload, []*pb.Transaction
 
 	// TODO: ensure that creator can transact with us (some ACLs?) which set of APIs is supposed to give us this info?
 
-	// TODO: perform a check against replay attacks
+	// the transaction ID must be derived from the nonce and the creator; the
+	// validator of the committer rejects transactions whose ID is already
+	// in the ledger or in the same block
+	err = utils.CheckProposalTxID(payload.Header.ChainHeader.TxID, payload.Header.SignatureHeader.Nonce, payload.Header.SignatureHeader.Creator)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// continue the validation in a way that depends on the type specified in the header
 	switch common.HeaderType(payload.Header.ChainHeader.Type) {
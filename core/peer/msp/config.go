@@ -8,21 +8,28 @@ import (
 	"github.com/hyperledger/fabric/protos/msp/utils"
 )
 
-func LoadLocalMsp(dir string) error {
-	conf, err := msp.GetLocalMspConfig(dir)
+// LoadLocalMsp sets up the local MSP from dir, decrypting its signing key
+// with pwd if it is encrypted
+func LoadLocalMsp(dir string, pwd []byte) error {
+	conf, err := msp.GetLocalMspConfig(dir, pwd)
 	if err != nil {
 		return err
 	}
 
-	return GetLocalMSP().Setup(conf)
+	err = GetLocalMSP().Setup(conf)
+	if err != nil {
+		return err
+	}
+
+	return msp.SetSigningIdentityDir(GetLocalMSP(), dir, pwd)
 }
 
 // FIXME: this is required for now because we need a local MSP
 // and also the MSP mgr for the test chain; as soon as the code
 // to setup chains is ready, the chain should be setup using
 // the method below and this method should disappear
-func LoadFakeSetupWithLocalMspAndTestChainMsp(dir string) error {
-	conf, err := msp.GetLocalMspConfig(dir)
+func LoadFakeSetupWithLocalMspAndTestChainMsp(dir string, pwd []byte) error {
+	conf, err := msp.GetLocalMspConfig(dir, pwd)
 	if err != nil {
 		return err
 	}
@@ -32,6 +39,11 @@ func LoadFakeSetupWithLocalMspAndTestChainMsp(dir string) error {
 		return err
 	}
 
+	err = msp.SetSigningIdentityDir(GetLocalMSP(), dir, pwd)
+	if err != nil {
+		return err
+	}
+
 	fakeConfig := []*mspprotos.MSPConfig{conf}
 
 	err = GetManagerForChain(util.GetTestChainID()).Setup(fakeConfig)
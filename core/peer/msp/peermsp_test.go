@@ -9,7 +9,7 @@ import (
 )
 
 func TestLocalMSP(t *testing.T) {
-	err := LoadLocalMsp("../../../msp/sampleconfig/")
+	err := LoadLocalMsp("../../../msp/sampleconfig/", nil)
 	if err != nil {
 		t.Fatalf("LoadLocalMsp failed, err %s", err)
 	}
@@ -22,7 +22,7 @@ func TestLocalMSP(t *testing.T) {
 
 // TODO: as soon as proper per-chain MSP support is developed, this test will no longer be required
 func TestFakeSetup(t *testing.T) {
-	err := LoadFakeSetupWithLocalMspAndTestChainMsp("../../../msp/sampleconfig/")
+	err := LoadFakeSetupWithLocalMspAndTestChainMsp("../../../msp/sampleconfig/", nil)
 	if err != nil {
 		t.Fatalf("LoadLocalMsp failed, err %s", err)
 	}
@@ -43,7 +43,7 @@ func TestFakeSetup(t *testing.T) {
 }
 
 func TestGetMSPManagerFromBlock(t *testing.T) {
-	conf, err := msp.GetLocalMspConfig("../../../msp/sampleconfig/")
+	conf, err := msp.GetLocalMspConfig("../../../msp/sampleconfig/", nil)
 	if err != nil {
 		t.Fatalf("GetLocalMspConfig failed, err %s", err)
 	}
@@ -18,8 +18,12 @@ package peer
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"google.golang.org/grpc"
@@ -29,12 +33,15 @@ import (
 
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/core/committer/blockverifier"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -94,46 +101,76 @@ func Initialize() {
 }
 
 func getCurrConfigBlockFromLedger(ledger ledger.ValidatedLedger) (*common.Block, error) {
-	// Configuration blocks contain only 1 transaction, so we look for 1-tx
-	// blocks and check the transaction type
-	var envelope *common.Envelope
-	var tx *common.Payload
 	var block *common.Block
 	var err error
-	var currBlockNumber uint64 = math.MaxUint64
-	for currBlockNumber >= 0 {
-		if block, err = ledger.GetBlockByNumber(currBlockNumber); err != nil {
-			return nil, err
+	if block, err = ledger.GetBlockByNumber(math.MaxUint64); err != nil {
+		return nil, err
+	}
+
+	// The last block references the configuration block in its metadata. A
+	// ledger created from a snapshot only holds the blocks from the snapshot
+	// onwards, so this is the only way to find it there
+	if cb := getLastConfigBlock(ledger, block); cb != nil {
+		return cb, nil
+	}
+
+	// Configuration blocks contain only 1 transaction, so we look for 1-tx
+	// blocks and check the transaction type
+	for {
+		if utils.IsConfigBlock(block) {
+			return block, nil
 		}
-		if block.Data != nil && len(block.Data.Data) == 1 {
-			if envelope, err = utils.ExtractEnvelope(block, 0); err != nil {
-				peerLogger.Warning("Failed to get Envelope from Block %d.", block.Header.Number)
-				currBlockNumber = block.Header.Number - 1
-				continue
-			}
-			if tx, err = utils.ExtractPayload(envelope); err != nil {
-				peerLogger.Warning("Failed to get Payload from Block %d.", block.Header.Number)
-				currBlockNumber = block.Header.Number - 1
-				continue
-			}
-			if tx.Header.ChainHeader.Type == int32(common.HeaderType_CONFIGURATION_TRANSACTION) {
-				return block, nil
-			}
+		if block.Header.Number == 0 {
+			break
+		}
+		if block, err = ledger.GetBlockByNumber(block.Header.Number - 1); err != nil {
+			return nil, err
 		}
-		currBlockNumber = block.Header.Number - 1
 	}
 	return nil, fmt.Errorf("Failed to find configuration block.")
 }
 
+// getLastConfigBlock returns the configuration block referenced by the
+// metadata of the given block, or nil if it cannot be found
+func getLastConfigBlock(ledger ledger.ValidatedLedger, block *common.Block) *common.Block {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_LAST_CONFIGURATION) ||
+		len(block.Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIGURATION]) == 0 {
+		return nil
+	}
+	index, err := utils.GetLastConfigurationIndexFromBlock(block)
+	if err != nil {
+		peerLogger.Warningf("Failed to get the last configuration index from Block %d: %s", block.Header.Number, err)
+		return nil
+	}
+	cb, err := ledger.GetBlockByNumber(index)
+	if err != nil || !utils.IsConfigBlock(cb) {
+		return nil
+	}
+	return cb
+}
+
+// newVerifier creates the block verifier of a chain from its configuration
+// block, with the MSPs defined in that block
+func newVerifier(cb *common.Block) (blockverifier.Verifier, error) {
+	mgr, err := mspmgmt.GetMSPManagerFromBlock(cb)
+	if err != nil {
+		return nil, err
+	}
+	return blockverifier.New(cb, mgr)
+}
+
 // createChain creates a new chain object and insert it into the chains
 func createChain(cid string, ledger ledger.ValidatedLedger, cb *common.Block) error {
-	c := committer.NewLedgerCommitter(ledger)
-
 	mgr, err := mspmgmt.GetMSPManagerFromBlock(cb)
 	if err != nil {
 		return err
 	}
 
+	c, err := committer.NewVerifyingLedgerCommitter(ledger, cb, newVerifier)
+	if err != nil {
+		return err
+	}
+
 	if err := service.GetGossipService().JoinChannel(c, cb); err != nil {
 		return err
 	}
@@ -158,6 +195,92 @@ func CreateChainFromBlock(cb *common.Block) error {
 	return createChain(cid, ledger, cb)
 }
 
+// CreateChainFromSnapshot creates a new chain from the ledger snapshot in
+// the given file, which must match hash, and returns its ID. The file must be
+// in the snapshots directory of the peer, a relative path is taken from it.
+// The configuration of the chain is read from the config block in the snapshot
+func CreateChainFromSnapshot(snapshotPath string, hash []byte) (string, error) {
+	snapshotPath, err := resolveSnapshotPath(snapshotPath)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	cid, ledger, err := ledgermgmt.CreateLedgerFromSnapshot(f, hash)
+	if err != nil {
+		return "", err
+	}
+
+	cb, err := getCurrConfigBlockFromLedger(ledger)
+	if err != nil {
+		ledger.Close()
+		return "", fmt.Errorf("Failed to find the configuration block of chain %s in the snapshot: %s", cid, err)
+	}
+
+	return cid, createChain(cid, ledger, cb)
+}
+
+// resolveSnapshotPath returns the path of a snapshot file, following the
+// symbolic links, and checks that it is inside the snapshots directory so
+// that the peer does not read any of its other files on request
+func resolveSnapshotPath(snapshotPath string) (string, error) {
+	dir, err := filepath.EvalSymlinks(ledgerconfig.GetSnapshotsPath())
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(snapshotPath) {
+		snapshotPath = filepath.Join(dir, snapshotPath)
+	}
+	if snapshotPath, err = filepath.EvalSymlinks(snapshotPath); err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, snapshotPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Snapshot %s is not in the snapshots directory %s", snapshotPath, dir)
+	}
+	return snapshotPath, nil
+}
+
+// ExportSnapshot writes a snapshot of the ledger of the given chain to the
+// snapshots directory of the peer. The returned info holds the path of the
+// snapshot along with its hash, which the peers joining from the snapshot
+// are given
+func ExportSnapshot(cid string) (*snapshot.SnapshotInfo, error) {
+	ledger := GetLedger(cid)
+	if ledger == nil {
+		return nil, fmt.Errorf("Unknown chain ID, %s", cid)
+	}
+
+	dir := ledgerconfig.GetSnapshotsPath()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// the snapshot is renamed after the block it was taken at once written
+	f, err := ioutil.TempFile(dir, cid+"_")
+	if err != nil {
+		return nil, err
+	}
+	info, err := ledger.ExportSnapshot(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	info.Path = filepath.Join(dir, fmt.Sprintf("%s_%d.snapshot", cid, info.BlockNumber))
+	if err = os.Rename(f.Name(), info.Path); err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return info, nil
+}
+
 // MockCreateChain used for creating a ledger for a chain for tests
 // without havin to join
 func MockCreateChain(cid string) error {
@@ -196,6 +319,20 @@ func GetCommitter(cid string) committer.Committer {
 	return nil
 }
 
+// GetMSPManagers returns the MSP managers of the chains created on this peer,
+// indexed by chain ID
+func GetMSPManagers() map[string]msp.MSPManager {
+	chains.RLock()
+	defer chains.RUnlock()
+	mgrs := make(map[string]msp.MSPManager)
+	for cid, c := range chains.list {
+		if c.mspmgr != nil {
+			mgrs[cid] = c.mspmgr
+		}
+	}
+	return mgrs
+}
+
 // GetCurrConfigBlock returns the cached config block of the specified chain.
 // Note that this call returns nil if chain cid has not been created.
 func GetCurrConfigBlock(cid string) *common.Block {
@@ -17,11 +17,11 @@ limitations under the License.
 package escc
 
 import (
-	"errors"
-
 	"fmt"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 
@@ -31,15 +31,15 @@ import (
 var logger = logging.MustGetLogger("escc")
 
 // EndorserOneValidSignature implements the default endorsement policy, which is to
-// sign the proposal hash and the read-write set
+// sign the proposal hash, the read-write set and the response of the chaincode
 type EndorserOneValidSignature struct {
 }
 
 // Init is called once when the chaincode started the first time
-func (e *EndorserOneValidSignature) Init(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (e *EndorserOneValidSignature) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	logger.Infof("Successfully initialized ESCC")
 
-	return nil, nil
+	return shim.Success(nil)
 }
 
 // Invoke is called to endorse the specified Proposal
@@ -48,25 +48,26 @@ func (e *EndorserOneValidSignature) Init(stub shim.ChaincodeStubInterface) ([]by
 // policy specification to be coded as a transaction of the chaincode and Client
 // could select which policy to use for endorsement using parameter
 // @return a marshalled proposal response
-// Note that Peer calls this function with 4 mandatory arguments (and 2 optional ones):
+// Note that Peer calls this function with 4 mandatory arguments (and 3 optional ones):
 // args[0] - function name (not used now)
 // args[1] - serialized Header object
 // args[2] - serialized ChaincodeProposalPayload object
 // args[3] - binary blob of simulation results
 // args[4] - serialized events (optional)
 // args[5] - payloadVisibility (optional)
+// args[6] - serialized Response of the chaincode (optional)
 //
 // NOTE: this chaincode is meant to sign another chaincode's simulation
 // results. It should not manipulate state as any state change will be
 // silently discarded: the only state changes that will be persisted if
 // this endorsement is successful is what we are about to sign, which by
 // definition can't be a state change of our own.
-func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 	if len(args) < 4 {
-		return nil, fmt.Errorf("Incorrect number of arguments (expected a minimum of 4, provided %d)", len(args))
-	} else if len(args) > 6 {
-		return nil, fmt.Errorf("Incorrect number of arguments (expected a maximum of 6, provided %d)", len(args))
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments (expected a minimum of 4, provided %d)", len(args)))
+	} else if len(args) > 7 {
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments (expected a maximum of 7, provided %d)", len(args)))
 	}
 
 	logger.Infof("ESCC starts: %d args", len(args))
@@ -74,7 +75,7 @@ func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]
 	// handle the header
 	var hdr []byte
 	if args[1] == nil {
-		return nil, errors.New("serialized Header object is null")
+		return shim.Error("serialized Header object is null")
 	}
 
 	hdr = args[1]
@@ -82,7 +83,7 @@ func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]
 	// handle the proposal payload
 	var payl []byte
 	if args[2] == nil {
-		return nil, errors.New("serialized ChaincodeProposalPayload object is null")
+		return shim.Error("serialized ChaincodeProposalPayload object is null")
 	}
 
 	payl = args[2]
@@ -90,7 +91,7 @@ func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]
 	// handle simulation results
 	var results []byte
 	if args[3] == nil {
-		return nil, errors.New("simulation results are null")
+		return shim.Error("simulation results are null")
 	}
 
 	results = args[3]
@@ -107,34 +108,47 @@ func (e *EndorserOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]
 	visibility := []byte("") // TODO: when visibility is properly defined, replace with the default
 	if len(args) > 5 {
 		if args[5] == nil {
-			return nil, errors.New("serialized events are null")
+			return shim.Error("serialized events are null")
 		}
 		visibility = args[5]
 	}
 
+	// Handle the response of the chaincode (it's an optional argument); it is
+	// signed along with the simulation results so that it can't be altered
+	var response *pb.Response
+	if len(args) > 6 {
+		if args[6] == nil {
+			return shim.Error("serialized Response object is null")
+		}
+		response = &pb.Response{}
+		if err := proto.Unmarshal(args[6], response); err != nil {
+			return shim.Error(fmt.Sprintf("Could not unmarshal Response: err %s", err))
+		}
+	}
+
 	// obtain the default signing identity for this peer; it will be used to sign this proposal response
 	localMsp := mspmgmt.GetLocalMSP()
 	if localMsp == nil {
-		return nil, fmt.Errorf("Nil local MSP manager")
+		return shim.Error("Nil local MSP manager")
 	}
 
 	signingEndorser, err := localMsp.GetDefaultSigningIdentity()
 	if err != nil {
-		return nil, fmt.Errorf("Could not obtain the default signing identity, err %s", err)
+		return shim.Error(fmt.Sprintf("Could not obtain the default signing identity, err %s", err))
 	}
 
 	// obtain a proposal response
-	presp, err := utils.CreateProposalResponse(hdr, payl, results, events, visibility, signingEndorser)
+	presp, err := utils.CreateProposalResponse(hdr, payl, response, results, events, visibility, signingEndorser)
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	// marshall the proposal response so that we return its bytes
 	prBytes, err := utils.GetBytesProposalResponse(presp)
 	if err != nil {
-		return nil, fmt.Errorf("Could not marshall ProposalResponse: err %s", err)
+		return shim.Error(fmt.Sprintf("Could not marshall ProposalResponse: err %s", err))
 	}
 
 	logger.Infof("ESCC exits successfully")
-	return prBytes, nil
+	return shim.Success(prBytes)
 }
@@ -23,6 +23,7 @@ import (
 
 	"os"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/peer"
@@ -36,8 +37,8 @@ func TestInit(t *testing.T) {
 	stub := shim.NewMockStub("endorseronevalidsignature", e)
 
 	args := [][]byte{[]byte("DEFAULT"), []byte("PEER")}
-	if _, err := stub.MockInit("1", args); err != nil {
-		fmt.Println("Init failed", err)
+	if res := stub.MockInit("1", args); res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -48,47 +49,47 @@ func TestInvoke(t *testing.T) {
 
 	// Initialize ESCC supplying the identity of the signer
 	args := [][]byte{[]byte("DEFAULT"), []byte("PEER")}
-	if _, err := stub.MockInit("1", args); err != nil {
-		fmt.Println("Init failed", err)
+	if res := stub.MockInit("1", args); res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 
 	// Failed path: Not enough parameters
 	args = [][]byte{[]byte("test")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("escc invoke should have failed with invalid number of args: %v", args)
 	}
 
 	// Failed path: Not enough parameters
 	args = [][]byte{[]byte("test"), []byte("test")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("escc invoke should have failed with invalid number of args: %v", args)
 	}
 
 	// Failed path: Not enough parameters
 	args = [][]byte{[]byte("test"), []byte("test"), []byte("test")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("escc invoke should have failed with invalid number of args: %v", args)
 	}
 
 	// Failed path: header is null
 	args = [][]byte{[]byte("test"), nil, []byte("test"), []byte("test")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
-		fmt.Println("Invoke", args, "failed", err)
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.Fatalf("escc invoke should have failed with a null header.  args: %v", args)
 	}
 
 	// Failed path: payload is null
 	args = [][]byte{[]byte("test"), []byte("test"), nil, []byte("test")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
-		fmt.Println("Invoke", args, "failed", err)
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.Fatalf("escc invoke should have failed with a null payload.  args: %v", args)
 	}
 
 	// Failed path: action struct is null
 	args = [][]byte{[]byte("test"), []byte("test"), []byte("test"), nil}
-	if _, err := stub.MockInvoke("1", args); err == nil {
-		fmt.Println("Invoke", args, "failed", err)
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.Fatalf("escc invoke should have failed with a null action struct.  args: %v", args)
 	}
 
@@ -114,9 +115,7 @@ func TestInvoke(t *testing.T) {
 		return
 	}
 
-	uuid := util.GenerateUUID()
-
-	proposal, err := putils.CreateChaincodeProposal(uuid, util.GetTestChainID(), cis, sIdBytes)
+	proposal, _, err := putils.CreateChaincodeProposal(util.GetTestChainID(), cis, sIdBytes)
 	if err != nil {
 		t.Fail()
 		t.Fatalf("couldn't generate chaincode proposal: err %s", err)
@@ -127,14 +126,15 @@ func TestInvoke(t *testing.T) {
 	simRes := []byte("simulation_result")
 
 	args = [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes}
-	prBytes, err := stub.MockInvoke("1", args)
-	if err != nil {
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
 		t.Fail()
-		t.Fatalf("escc invoke failed with: %v", err)
+		t.Fatalf("escc invoke failed with: %v", res.Message)
 		return
 	}
+	prBytes := res.Payload
 
-	err = validateProposalResponse(prBytes, proposal, nil, simRes, nil)
+	err = validateProposalResponse(prBytes, proposal, nil, simRes, nil, nil)
 	if err != nil {
 		t.Fail()
 		t.Fatalf("%s", err)
@@ -145,14 +145,15 @@ func TestInvoke(t *testing.T) {
 	events := []byte("events")
 
 	args = [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, events}
-	prBytes, err = stub.MockInvoke("1", args)
-	if err != nil {
+	res = stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
 		t.Fail()
-		t.Fatalf("escc invoke failed with: %v", err)
+		t.Fatalf("escc invoke failed with: %v", res.Message)
 		return
 	}
+	prBytes = res.Payload
 
-	err = validateProposalResponse(prBytes, proposal, nil, simRes, events)
+	err = validateProposalResponse(prBytes, proposal, nil, simRes, events, nil)
 	if err != nil {
 		t.Fail()
 		t.Fatalf("%s", err)
@@ -163,22 +164,48 @@ func TestInvoke(t *testing.T) {
 	visibility := []byte("visibility")
 
 	args = [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, events, visibility}
-	prBytes, err = stub.MockInvoke("1", args)
-	if err != nil {
+	res = stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
 		t.Fail()
-		t.Fatalf("escc invoke failed with: %v", err)
+		t.Fatalf("escc invoke failed with: %v", res.Message)
 		return
 	}
+	prBytes = res.Payload
 
-	err = validateProposalResponse(prBytes, proposal, visibility, simRes, events)
+	err = validateProposalResponse(prBytes, proposal, visibility, simRes, events, nil)
 	if err != nil {
 		t.Fail()
 		t.Fatalf("%s", err)
 		return
 	}
+
+	// success test 4: invocation with mandatory args + events, visibility and the chaincode response
+	response := &pb.Response{Status: shim.OK, Payload: []byte("return_value")}
+	resBytes, err := putils.GetBytesResponse(response)
+	if err != nil {
+		t.Fatalf("couldn't marshal response: err %s", err)
+	}
+
+	args = [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, events, visibility, resBytes}
+	res = stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		t.Fatalf("escc invoke failed with: %v", res.Message)
+	}
+	prBytes = res.Payload
+
+	err = validateProposalResponse(prBytes, proposal, visibility, simRes, events, response)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// Failed path: response cannot be unmarshalled
+	args = [][]byte{[]byte(""), proposal.Header, proposal.Payload, simRes, events, visibility, []byte("barf")}
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
+		t.Fatalf("escc invoke should have failed with a malformed response.  args: %v", args)
+	}
 }
 
-func validateProposalResponse(prBytes []byte, proposal *pb.Proposal, visibility []byte, simRes []byte, events []byte) error {
+func validateProposalResponse(prBytes []byte, proposal *pb.Proposal, visibility []byte, simRes []byte, events []byte, response *pb.Response) error {
 	if visibility == nil {
 		// TODO: set visibility to the default visibility mode once modes are defined
 	}
@@ -233,6 +260,16 @@ func validateProposalResponse(prBytes []byte, proposal *pb.Proposal, visibility
 		return fmt.Errorf("events do not match")
 	}
 
+	// validate that the chaincode response is part of the signed payload
+	if response != nil {
+		if !proto.Equal(cact.Response, response) {
+			return fmt.Errorf("signed response does not match")
+		}
+		if !proto.Equal(pResp.Response, response) {
+			return fmt.Errorf("response does not match")
+		}
+	}
+
 	// get the identity of the endorser
 	endorser, err := mspmgmt.GetManagerForChain(util.GetTestChainID()).DeserializeIdentity(pResp.Endorsement.Endorser)
 	if err != nil {
@@ -275,7 +312,7 @@ func validateProposalResponse(prBytes []byte, proposal *pb.Proposal, visibility
 
 func TestMain(m *testing.M) {
 	mspMgrConfigDir := "../../../msp/sampleconfig/"
-	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 
 	os.Exit(m.Run())
 }
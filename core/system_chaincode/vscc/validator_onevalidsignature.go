@@ -17,12 +17,12 @@ limitations under the License.
 package vscc
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 )
@@ -36,9 +36,9 @@ type ValidatorOneValidSignature struct {
 }
 
 // Init is called once when the chaincode started the first time
-func (vscc *ValidatorOneValidSignature) Init(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (vscc *ValidatorOneValidSignature) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	// best practice to do nothing (or very little) in Init
-	return nil, nil
+	return shim.Success(nil)
 }
 
 // Invoke is called to validate the specified block of transactions
@@ -50,16 +50,16 @@ func (vscc *ValidatorOneValidSignature) Init(stub shim.ChaincodeStubInterface) (
 // @return serialized Block of valid and invalid transactions indentified
 // Note that Peer calls this function with 2 arguments, where args[0] is the
 // function name and args[1] is the Envelope
-func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	// args[0] - function name (not used now)
 	// args[1] - serialized Envelope
 	args := stub.GetArgs()
 	if len(args) < 2 {
-		return nil, errors.New("Incorrect number of arguments")
+		return shim.Error("Incorrect number of arguments")
 	}
 
 	if args[1] == nil {
-		return nil, errors.New("No block to validate")
+		return shim.Error("No block to validate")
 	}
 
 	logger.Infof("VSCC invoked")
@@ -68,27 +68,27 @@ func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface)
 	env, err := utils.GetEnvelopeFromBlock(args[1])
 	if err != nil {
 		logger.Errorf("VSCC error: GetEnvelope failed, err %s", err)
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	// ...and the payload...
 	payl, err := utils.GetPayload(env)
 	if err != nil {
 		logger.Errorf("VSCC error: GetPayload failed, err %s", err)
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	// validate the payload type
 	if common.HeaderType(payl.Header.ChainHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
 		logger.Errorf("Only Endorser Transactions are supported, provided type %d", payl.Header.ChainHeader.Type)
-		return nil, fmt.Errorf("Only Endorser Transactions are supported, provided type %d", payl.Header.ChainHeader.Type)
+		return shim.Error(fmt.Sprintf("Only Endorser Transactions are supported, provided type %d", payl.Header.ChainHeader.Type))
 	}
 
 	// ...and the transaction...
 	tx, err := utils.GetTransaction(payl.Data)
 	if err != nil {
 		logger.Errorf("VSCC error: GetTransaction failed, err %s", err)
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	// loop through each of the actions within
@@ -96,7 +96,7 @@ func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface)
 		cap, err := utils.GetChaincodeActionPayload(act.Payload)
 		if err != nil {
 			logger.Errorf("VSCC error: GetChaincodeActionPayload failed, err %s", err)
-			return nil, err
+			return shim.Error(err.Error())
 		}
 
 		// this is what is being signed
@@ -108,24 +108,24 @@ func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface)
 			end, err := mspmgmt.GetManagerForChain(payl.Header.ChainHeader.ChainID).DeserializeIdentity(endorsement.Endorser)
 			if err != nil {
 				logger.Errorf("VSCC error: DeserializeIdentity failed, err %s", err)
-				return nil, err
+				return shim.Error(err.Error())
 			}
 
 			// validate it
 			err = end.Validate()
 			if err != nil {
-				return nil, fmt.Errorf("Invalid endorser identity, err %s", err)
+				return shim.Error(fmt.Sprintf("Invalid endorser identity, err %s", err))
 			}
 
 			// verify the signature
 			err = end.Verify(append(prespBytes, endorsement.Endorser...), endorsement.Signature)
 			if err != nil {
-				return nil, fmt.Errorf("Invalid signature, err %s", err)
+				return shim.Error(fmt.Sprintf("Invalid signature, err %s", err))
 			}
 		}
 	}
 
 	logger.Infof("VSCC exists successfully")
 
-	return nil, nil
+	return shim.Success(nil)
 }
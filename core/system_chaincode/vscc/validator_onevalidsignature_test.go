@@ -33,14 +33,12 @@ import (
 func createTx() (*common.Envelope, error) {
 	cis := &peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{ChaincodeID: &peer.ChaincodeID{Name: "foo"}}}
 
-	uuid := util.GenerateUUID()
-
-	prop, err := utils.CreateProposalFromCIS(uuid, util.GetTestChainID(), cis, sid)
+	prop, _, err := utils.CreateProposalFromCIS(util.GetTestChainID(), cis, sid)
 	if err != nil {
 		return nil, err
 	}
 
-	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, []byte("res"), nil, nil, id)
+	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, nil, []byte("res"), nil, nil, id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +50,8 @@ func TestInit(t *testing.T) {
 	v := new(ValidatorOneValidSignature)
 	stub := shim.NewMockStub("validatoronevalidsignature", v)
 
-	if _, err := stub.MockInit("1", nil); err != nil {
-		t.Fatalf("vscc init failed with %v", err)
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		t.Fatalf("vscc init failed with %v", res.Message)
 	}
 }
 
@@ -63,14 +61,14 @@ func TestInvoke(t *testing.T) {
 
 	// Failed path: Invalid arguments
 	args := [][]byte{[]byte("dv")}
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("vscc invoke should have failed")
 		return
 	}
 
 	args = [][]byte{[]byte("dv"), []byte("tx")}
 	args[1] = nil
-	if _, err := stub.MockInvoke("1", args); err == nil {
+	if res := stub.MockInvoke("1", args); res.Status == shim.OK {
 		t.Fatalf("vscc invoke should have failed")
 		return
 	}
@@ -88,8 +86,8 @@ func TestInvoke(t *testing.T) {
 	}
 
 	args = [][]byte{[]byte("dv"), envBytes}
-	if _, err := stub.MockInvoke("1", args); err != nil {
-		t.Fatalf("vscc invoke returned err %s", err)
+	if res := stub.MockInvoke("1", args); res.Status != shim.OK {
+		t.Fatalf("vscc invoke returned res.Message %s", res.Message)
 		return
 	}
 }
@@ -102,7 +100,7 @@ func TestMain(m *testing.M) {
 
 	// setup the MSP manager so that we can sign/verify
 	mspMgrConfigDir := "../../../msp/sampleconfig/"
-	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 
 	id, err = mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
 	if err != nil {
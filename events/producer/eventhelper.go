@@ -32,7 +32,10 @@ func init() {
 	logger = logging.MustGetLogger("eventhub_producer")
 }
 
-// SendProducerBlockEvent sends block event to clients
+// SendProducerBlockEvent sends block event to clients. Every transaction is
+// sent in its envelope, in block order, so that the TxIDs can be matched
+// against the transactions filter in the block metadata. The read write sets
+// of endorser transactions are dropped, so their signatures no longer verify
 func SendProducerBlockEvent(block *common.Block) error {
 	bevent := &common.Block{}
 	bevent.Header = block.Header
@@ -77,6 +80,7 @@ func SendProducerBlockEvent(block *common.Block) error {
 					}
 					// Drop read write set from transaction before sending block event
 					caPayload.Results = nil
+					chaincodeActionPayload.Action.HashedValues = nil
 					propRespPayload.Extension, err = proto.Marshal(caPayload)
 					if err != nil {
 						logger.Errorf("Error marshalling tx proposal extension payload for block event: %s", err)
@@ -93,12 +97,24 @@ func SendProducerBlockEvent(block *common.Block) error {
 						logger.Errorf("Error marshalling tx action payload for block event: %s", err)
 						continue
 					}
-					if t, err := proto.Marshal(tx); err == nil {
-						bevent.Data.Data = append(bevent.Data.Data, t)
+					// Wrap the transaction back in its envelope so that listeners
+					// can still read the TxID from the chain header
+					if payload.Data, err = proto.Marshal(tx); err != nil {
+						logger.Infof("Cannot marshal transaction %s\n", err)
+						continue
+					}
+					if env.Payload, err = proto.Marshal(payload); err != nil {
+						logger.Infof("Cannot marshal transaction payload %s\n", err)
+						continue
+					}
+					if e, err := proto.Marshal(env); err == nil {
+						bevent.Data.Data = append(bevent.Data.Data, e)
 						logger.Infof("calling sendProducerBlockEvent\n")
 					} else {
-						logger.Infof("Cannot marshal transaction %s\n", err)
+						logger.Infof("Cannot marshal transaction envelope %s\n", err)
 					}
+				} else {
+					bevent.Data.Data = append(bevent.Data.Data, d)
 				}
 			}
 		}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out the transactions of all the goroutines sharing it so
+// that together they do not exceed a target transactions-per-second rate. A nil
+// RateLimiter does not limit anything
+type RateLimiter struct {
+	sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter returns a limiter for tps transactions per second, or nil when
+// tps is not positive
+func NewRateLimiter(tps float64) *RateLimiter {
+	if tps <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / tps)}
+}
+
+// Wait blocks until the caller may send its next transaction
+func (r *RateLimiter) Wait() {
+	if r == nil {
+		return
+	}
+
+	r.Lock()
+	now := time.Now()
+	//a limiter that fell behind does not burst to catch up
+	if r.next.Before(now) {
+		r.next = now
+	}
+	slot := r.next
+	r.next = r.next.Add(r.interval)
+	r.Unlock()
+
+	time.Sleep(slot.Sub(now))
+}
@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Fatalf("Expected no limiter for a rate of 0")
+	}
+	//a nil limiter never blocks
+	var unlimited *RateLimiter
+	unlimited.Wait()
+
+	limiter := NewRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		limiter.Wait()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("11 transactions at 100 tps took %s, expected at least 100ms", elapsed)
+	}
+}
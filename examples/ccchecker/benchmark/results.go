@@ -0,0 +1,389 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TxResult is the outcome of one invoke
+type TxResult struct {
+	//Chaincode the invoke was sent to
+	Chaincode string
+
+	//Client is the ID of the ccchecker client that sent the invoke
+	Client int
+
+	//Iteration of the invoke for the client
+	Iteration int
+
+	//TxID of the transaction
+	TxID string
+
+	//Start is when the proposal was created
+	Start time.Time
+
+	//Endorse is the time taken by the peer to endorse the proposal
+	Endorse time.Duration
+
+	//Order is the time taken by the orderer to accept the transaction
+	Order time.Duration
+
+	//Commit is the time between the orderer accepting the transaction and
+	//the block event for it
+	Commit time.Duration
+
+	//Committed is true if the transaction was seen in a block event
+	Committed bool
+
+	//Valid is true if the transaction was committed as valid
+	Valid bool
+
+	//BlockNumber of the block the transaction was committed in
+	BlockNumber uint64
+
+	//Err is the error that stopped the transaction, if any
+	Err string
+}
+
+// EndToEnd is the time from the proposal to the block event
+func (tx *TxResult) EndToEnd() time.Duration {
+	return tx.Endorse + tx.Order + tx.Commit
+}
+
+// LatencyStats summarizes a latency over a number of transactions. The values
+// are in milliseconds
+type LatencyStats struct {
+	Count int
+	Mean  float64
+	P50   float64
+	P90   float64
+	P99   float64
+	Max   float64
+}
+
+// Summary of a benchmark run
+type Summary struct {
+	//Submitted is the number of invokes attempted
+	Submitted int
+
+	//Failed is the number of invokes that could not be endorsed or ordered
+	Failed int
+
+	//Committed is the number of transactions committed as valid
+	Committed int
+
+	//Invalid is the number of transactions committed as invalid
+	Invalid int
+
+	//TimedOut is the number of ordered transactions never seen in a block
+	TimedOut int
+
+	//DurationSecs from the first proposal to the last result
+	DurationSecs float64
+
+	//TPS is the number of valid transactions committed per second
+	TPS float64
+
+	Endorse  LatencyStats
+	Order    LatencyStats
+	Commit   LatencyStats
+	EndToEnd LatencyStats
+}
+
+// Results collects the TxResults of a benchmark run
+type Results struct {
+	sync.Mutex
+	txs     []*TxResult
+	pending sync.WaitGroup
+}
+
+// NewResults returns an empty Results
+func NewResults() *Results {
+	return &Results{}
+}
+
+// Add records a result
+func (r *Results) Add(tx *TxResult) {
+	r.Lock()
+	defer r.Unlock()
+	r.txs = append(r.txs, tx)
+}
+
+// AddWhenCommitted records tx once its commit status is received on c or
+// timeout expires, whichever comes first. The tracker stops waiting for the
+// TxID on a timeout
+func (r *Results) AddWhenCommitted(tx *TxResult, tracker *CommitTracker, c <-chan *CommitStatus, ordered time.Time, timeout time.Duration) {
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		select {
+		case s := <-c:
+			tx.Committed = true
+			tx.Valid = s.Valid
+			tx.BlockNumber = s.BlockNumber
+			tx.Commit = s.Time.Sub(ordered)
+		case <-time.After(timeout):
+			tracker.Forget(tx.TxID)
+			tx.Err = "timed out waiting for the transaction to be committed"
+		}
+		r.Add(tx)
+	}()
+}
+
+// WaitForCommits blocks until all the transactions passed to AddWhenCommitted
+// are recorded
+func (r *Results) WaitForCommits() {
+	r.pending.Wait()
+}
+
+// Transactions returns the recorded results ordered by start time
+func (r *Results) Transactions() []*TxResult {
+	r.Lock()
+	txs := make([]*TxResult, len(r.txs))
+	copy(txs, r.txs)
+	r.Unlock()
+
+	sort.Sort(byStart(txs))
+	return txs
+}
+
+type byStart []*TxResult
+
+func (s byStart) Len() int           { return len(s) }
+func (s byStart) Less(i, j int) bool { return s[i].Start.Before(s[j].Start) }
+func (s byStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Summarize computes the summary of the recorded results. Commit and end to end
+// latencies only count the transactions committed as valid
+func (r *Results) Summarize() *Summary {
+	txs := r.Transactions()
+	s := &Summary{Submitted: len(txs)}
+
+	var endorse, order, commit, endToEnd []time.Duration
+	var first, last time.Time
+	for _, tx := range txs {
+		if first.IsZero() || tx.Start.Before(first) {
+			first = tx.Start
+		}
+		if end := tx.Start.Add(tx.EndToEnd()); end.After(last) {
+			last = end
+		}
+
+		if tx.Endorse > 0 {
+			endorse = append(endorse, tx.Endorse)
+		}
+		if tx.Order > 0 {
+			order = append(order, tx.Order)
+		}
+
+		switch {
+		case tx.Committed && tx.Valid:
+			s.Committed++
+			commit = append(commit, tx.Commit)
+			endToEnd = append(endToEnd, tx.EndToEnd())
+		case tx.Committed:
+			s.Invalid++
+		case tx.Order > 0:
+			s.TimedOut++
+		default:
+			s.Failed++
+		}
+	}
+
+	if d := last.Sub(first); d > 0 {
+		s.DurationSecs = d.Seconds()
+		s.TPS = float64(s.Committed) / s.DurationSecs
+	}
+
+	s.Endorse = latencyStats(endorse)
+	s.Order = latencyStats(order)
+	s.Commit = latencyStats(commit)
+	s.EndToEnd = latencyStats(endToEnd)
+
+	return s
+}
+
+func latencyStats(d []time.Duration) LatencyStats {
+	stats := LatencyStats{Count: len(d)}
+	if len(d) == 0 {
+		return stats
+	}
+
+	sorted := make([]float64, len(d))
+	var total float64
+	for i, v := range d {
+		sorted[i] = toMs(v)
+		total += sorted[i]
+	}
+	sort.Float64s(sorted)
+
+	stats.Mean = total / float64(len(sorted))
+	stats.P50 = percentile(sorted, 50)
+	stats.P90 = percentile(sorted, 90)
+	stats.P99 = percentile(sorted, 99)
+	stats.Max = sorted[len(sorted)-1]
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func toMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Print writes a human readable summary to w
+func (s *Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "Benchmark summary\n")
+	fmt.Fprintf(w, "\tSubmitted: %d, committed: %d, invalid: %d, timed out: %d, failed: %d\n",
+		s.Submitted, s.Committed, s.Invalid, s.TimedOut, s.Failed)
+	fmt.Fprintf(w, "\tDuration(s): %.3f, throughput(tps): %.2f\n", s.DurationSecs, s.TPS)
+	fmt.Fprintf(w, "\t%-10s %8s %10s %10s %10s %10s %10s\n", "latency", "count", "mean(ms)", "p50(ms)", "p90(ms)", "p99(ms)", "max(ms)")
+	for _, l := range []struct {
+		name  string
+		stats LatencyStats
+	}{{"endorse", s.Endorse}, {"order", s.Order}, {"commit", s.Commit}, {"end2end", s.EndToEnd}} {
+		fmt.Fprintf(w, "\t%-10s %8d %10.2f %10.2f %10.2f %10.2f %10.2f\n",
+			l.name, l.stats.Count, l.stats.Mean, l.stats.P50, l.stats.P90, l.stats.P99, l.stats.Max)
+	}
+}
+
+// txRecord is how a TxResult is exported, with latencies in milliseconds
+type txRecord struct {
+	Chaincode   string
+	Client      int
+	Iteration   int
+	TxID        string
+	Start       time.Time
+	EndorseMs   float64
+	OrderMs     float64
+	CommitMs    float64
+	EndToEndMs  float64
+	Committed   bool
+	Valid       bool
+	BlockNumber uint64
+	Err         string `json:",omitempty"`
+}
+
+func newTxRecord(tx *TxResult) *txRecord {
+	return &txRecord{
+		Chaincode:   tx.Chaincode,
+		Client:      tx.Client,
+		Iteration:   tx.Iteration,
+		TxID:        tx.TxID,
+		Start:       tx.Start,
+		EndorseMs:   toMs(tx.Endorse),
+		OrderMs:     toMs(tx.Order),
+		CommitMs:    toMs(tx.Commit),
+		EndToEndMs:  toMs(tx.EndToEnd()),
+		Committed:   tx.Committed,
+		Valid:       tx.Valid,
+		BlockNumber: tx.BlockNumber,
+		Err:         tx.Err,
+	}
+}
+
+var csvHeader = []string{"chaincode", "client", "iteration", "txid", "start",
+	"endorse_ms", "order_ms", "commit_ms", "end2end_ms", "committed", "valid", "block", "error"}
+
+// WriteCSV writes one line per transaction to w
+func (r *Results) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	formatMs := func(ms float64) string { return strconv.FormatFloat(ms, 'f', 3, 64) }
+	for _, tx := range r.Transactions() {
+		rec := newTxRecord(tx)
+		line := []string{
+			rec.Chaincode,
+			strconv.Itoa(rec.Client),
+			strconv.Itoa(rec.Iteration),
+			rec.TxID,
+			rec.Start.Format(time.RFC3339Nano),
+			formatMs(rec.EndorseMs),
+			formatMs(rec.OrderMs),
+			formatMs(rec.CommitMs),
+			formatMs(rec.EndToEndMs),
+			strconv.FormatBool(rec.Committed),
+			strconv.FormatBool(rec.Valid),
+			strconv.FormatUint(rec.BlockNumber, 10),
+			rec.Err,
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the summary and the transactions to w
+func (r *Results) WriteJSON(w io.Writer) error {
+	txs := r.Transactions()
+	out := struct {
+		Summary      *Summary
+		Transactions []*txRecord
+	}{Summary: r.Summarize(), Transactions: make([]*txRecord, len(txs))}
+	for i, tx := range txs {
+		out.Transactions[i] = newTxRecord(tx)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// WriteFile exports the results to path, as JSON if it ends with .json and as
+// CSV otherwise
+func (r *Results) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = r.WriteJSON(f)
+	} else {
+		err = r.WriteCSV(f)
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func addResults(r *Results) {
+	start := time.Unix(1000, 0)
+	for i := 1; i <= 100; i++ {
+		r.Add(&TxResult{
+			Chaincode: "mycc",
+			Iteration: i,
+			TxID:      string(rune('a'+i%26)) + "tx",
+			Start:     start.Add(time.Duration(i) * 10 * time.Millisecond),
+			Endorse:   time.Duration(i) * time.Millisecond,
+			Order:     time.Millisecond,
+			Commit:    10 * time.Millisecond,
+			Committed: true,
+			Valid:     i%10 != 0,
+		})
+	}
+	//endorsement failure
+	r.Add(&TxResult{Chaincode: "mycc", Start: start, Err: "endorsement failed"})
+	//never committed
+	r.Add(&TxResult{Chaincode: "mycc", Start: start, Endorse: time.Millisecond, Order: time.Millisecond, Err: "timed out"})
+}
+
+func TestSummarize(t *testing.T) {
+	r := NewResults()
+	addResults(r)
+	s := r.Summarize()
+
+	if s.Submitted != 102 || s.Committed != 90 || s.Invalid != 10 || s.TimedOut != 1 || s.Failed != 1 {
+		t.Fatalf("Unexpected counts %#v", s)
+	}
+	if s.Endorse.Count != 101 || s.Endorse.Max != 100 {
+		t.Fatalf("Unexpected endorse stats %#v", s.Endorse)
+	}
+	if s.EndToEnd.Count != 90 || s.Commit.P50 != 10 || s.Commit.P99 != 10 {
+		t.Fatalf("Unexpected commit stats %#v %#v", s.EndToEnd, s.Commit)
+	}
+	//first start at 1000s, last result at 1000s+1000ms+111ms
+	if s.DurationSecs < 1.110 || s.DurationSecs > 1.112 {
+		t.Fatalf("Unexpected duration %f", s.DurationSecs)
+	}
+
+	empty := NewResults().Summarize()
+	if empty.Submitted != 0 || empty.TPS != 0 || empty.EndToEnd.Count != 0 {
+		t.Fatalf("Unexpected summary of no results %#v", empty)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, expected := range map[float64]float64{0: 1, 10: 1, 50: 5, 90: 9, 99: 10, 100: 10} {
+		if v := percentile(sorted, p); v != expected {
+			t.Fatalf("Expected percentile %f to be %f, got %f", p, expected, v)
+		}
+	}
+}
+
+func TestAddWhenCommitted(t *testing.T) {
+	r := NewResults()
+	tracker := NewCommitTracker()
+
+	ordered := time.Now()
+	committed := &TxResult{TxID: "tx1", Start: ordered}
+	c := make(chan *CommitStatus, 1)
+	c <- &CommitStatus{BlockNumber: 3, Valid: true, Time: ordered.Add(5 * time.Millisecond)}
+	r.AddWhenCommitted(committed, tracker, c, ordered, time.Second)
+
+	lost := &TxResult{TxID: "tx2", Start: ordered}
+	r.AddWhenCommitted(lost, tracker, tracker.Expect("tx2"), ordered, 10*time.Millisecond)
+
+	r.WaitForCommits()
+
+	if !committed.Committed || !committed.Valid || committed.BlockNumber != 3 || committed.Commit != 5*time.Millisecond {
+		t.Fatalf("Unexpected committed result %#v", committed)
+	}
+	if lost.Committed || lost.Err == "" {
+		t.Fatalf("Expected a timeout for %#v", lost)
+	}
+	if len(tracker.pending) != 0 {
+		t.Fatalf("Expected the timed out transaction to be forgotten")
+	}
+	if len(r.Transactions()) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(r.Transactions()))
+	}
+}
+
+func TestExport(t *testing.T) {
+	r := NewResults()
+	addResults(r)
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %s", err)
+	}
+	lines, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Cannot read back the CSV: %s", err)
+	}
+	if len(lines) != 103 || len(lines[0]) != len(csvHeader) {
+		t.Fatalf("Unexpected CSV shape %d lines, %d columns", len(lines), len(lines[0]))
+	}
+
+	dir, err := ioutil.TempDir("", "ccchecker-benchmark")
+	if err != nil {
+		t.Fatalf("Cannot create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "results.json")
+	if err = r.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %s", err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Cannot read back %s: %s", path, err)
+	}
+	out := struct {
+		Summary      *Summary
+		Transactions []*txRecord
+	}{}
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Cannot unmarshal JSON results: %s", err)
+	}
+	if out.Summary.Committed != 90 || len(out.Transactions) != 102 {
+		t.Fatalf("Unexpected JSON results %#v", out.Summary)
+	}
+	if out.Transactions[len(out.Transactions)-1].EndToEndMs != 111 {
+		t.Fatalf("Expected the last transaction to take 111ms, got %f", out.Transactions[len(out.Transactions)-1].EndToEndMs)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/events/consumer"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("ccchecker/benchmark")
+
+// CommitStatus is what a block event tells about a transaction
+type CommitStatus struct {
+	//BlockNumber of the block the transaction was found in
+	BlockNumber uint64
+
+	//Valid is false if the peer marked the transaction invalid in the block
+	Valid bool
+
+	//Time the block event was received
+	Time time.Time
+}
+
+// CommitTracker listens to the block events of a peer and notifies whoever is
+// waiting for a TxID when a block containing it is received
+type CommitTracker struct {
+	sync.Mutex
+	pending map[string]chan *CommitStatus
+	client  *consumer.EventsClient
+}
+
+// NewCommitTracker returns a tracker that is not yet connected to a peer. Use
+// Start to connect it, or feed it events through Recv
+func NewCommitTracker() *CommitTracker {
+	return &CommitTracker{pending: make(map[string]chan *CommitStatus)}
+}
+
+// Start registers the tracker for block events with the events server at
+// eventsAddress
+func (t *CommitTracker) Start(eventsAddress string, regTimeout time.Duration) error {
+	//the client is still usable when the timeout had to be adjusted
+	client, err := consumer.NewEventsClient(eventsAddress, regTimeout, t)
+	if client == nil {
+		return err
+	} else if err != nil {
+		logger.Warningf("%s", err)
+	}
+	if err = client.Start(); err != nil {
+		client.Stop()
+		return fmt.Errorf("could not register for block events with %s: %s", eventsAddress, err)
+	}
+	t.client = client
+	return nil
+}
+
+// Stop disconnects the tracker from the events server
+func (t *CommitTracker) Stop() {
+	if t.client != nil {
+		t.client.Stop()
+		t.client = nil
+	}
+}
+
+// Expect returns the channel on which the commit status of txID will be sent.
+// It must be called before the transaction is sent for ordering
+func (t *CommitTracker) Expect(txID string) <-chan *CommitStatus {
+	t.Lock()
+	defer t.Unlock()
+	c := make(chan *CommitStatus, 1)
+	t.pending[txID] = c
+	return c
+}
+
+// Forget stops waiting for txID
+func (t *CommitTracker) Forget(txID string) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.pending, txID)
+}
+
+// GetInterestedEvents implements consumer.EventAdapter interface for registering interested events
+func (t *CommitTracker) GetInterestedEvents() ([]*pb.Interest, error) {
+	return []*pb.Interest{{EventType: pb.EventType_BLOCK}}, nil
+}
+
+// Recv implements consumer.EventAdapter interface for receiving events
+func (t *CommitTracker) Recv(msg *pb.Event) (bool, error) {
+	if b, ok := msg.Event.(*pb.Event_Block); ok {
+		t.blockReceived(b.Block, time.Now())
+	}
+	return true, nil
+}
+
+// Disconnected implements consumer.EventAdapter interface for disconnecting
+func (t *CommitTracker) Disconnected(err error) {
+	logger.Warningf("Disconnected from the events server: %s", err)
+}
+
+func (t *CommitTracker) blockReceived(block *common.Block, now time.Time) {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return
+	}
+
+	var txsFilter util.FilterBitArray
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txsFilter = util.NewFilterBitArrayFromBytes(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for i, d := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(d)
+		if err != nil {
+			logger.Debugf("Skipping transaction %d of block %d: %s", i, block.Header.Number, err)
+			continue
+		}
+		payload, err := utils.GetPayload(env)
+		if err != nil || payload.Header == nil || payload.Header.ChainHeader == nil {
+			logger.Debugf("Skipping transaction %d of block %d, no chain header", i, block.Header.Number)
+			continue
+		}
+
+		txID := payload.Header.ChainHeader.TxID
+		c, ok := t.pending[txID]
+		if !ok {
+			continue
+		}
+		delete(t.pending, txID)
+		c <- &CommitStatus{
+			BlockNumber: block.Header.Number,
+			Valid:       txsFilter == nil || !txsFilter.IsSet(uint(i)),
+			Time:        now,
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func makeBlock(t *testing.T, num uint64, txIDs []string, invalid ...uint) *common.Block {
+	block := common.NewBlock(num, nil)
+	for _, txID := range txIDs {
+		payload := &common.Payload{Header: &common.Header{ChainHeader: &common.ChainHeader{TxID: txID}}}
+		payloadBytes, err := proto.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Error marshalling payload: %s", err)
+		}
+		envBytes, err := proto.Marshal(&common.Envelope{Payload: payloadBytes})
+		if err != nil {
+			t.Fatalf("Error marshalling envelope: %s", err)
+		}
+		block.Data.Data = append(block.Data.Data, envBytes)
+	}
+
+	txsFilter := util.NewFilterBitArray(uint(len(txIDs)))
+	for _, i := range invalid {
+		txsFilter.Set(i)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter.ToBytes()
+	return block
+}
+
+func TestCommitTrackerMatchesTxIDs(t *testing.T) {
+	tracker := NewCommitTracker()
+	c1 := tracker.Expect("tx1")
+	c2 := tracker.Expect("tx2")
+	c3 := tracker.Expect("tx3")
+	tracker.Forget("tx3")
+
+	block := makeBlock(t, 5, []string{"other", "tx1", "tx2", "tx3"}, 2)
+	if _, err := tracker.Recv(&pb.Event{Event: &pb.Event_Block{Block: block}}); err != nil {
+		t.Fatalf("Recv failed: %s", err)
+	}
+
+	select {
+	case s := <-c1:
+		if s.BlockNumber != 5 || !s.Valid {
+			t.Fatalf("Expected tx1 valid in block 5, got %#v", s)
+		}
+	default:
+		t.Fatalf("tx1 was not notified")
+	}
+
+	select {
+	case s := <-c2:
+		if s.BlockNumber != 5 || s.Valid {
+			t.Fatalf("Expected tx2 invalid in block 5, got %#v", s)
+		}
+	default:
+		t.Fatalf("tx2 was not notified")
+	}
+
+	select {
+	case s := <-c3:
+		t.Fatalf("tx3 was forgotten but got notified %#v", s)
+	default:
+	}
+
+	if len(tracker.pending) != 0 {
+		t.Fatalf("Expected no pending transactions, got %d", len(tracker.pending))
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/hyperledger/fabric/examples/ccchecker/benchmark"
 	"github.com/hyperledger/fabric/examples/ccchecker/chaincodes"
 	"github.com/hyperledger/fabric/peer/common"
 )
@@ -41,6 +42,32 @@ type CCChecker struct {
 	TimeoutToAbortSecs int
 	//ChainName name of the chain
 	ChainName string
+	//Benchmark turns the run into a benchmark when set
+	Benchmark *BenchmarkParams
+
+	//-------------runtime properties ------------
+	//tracks the commit of the invokes when benchmarking
+	tracker *benchmark.CommitTracker
+	//results of the invokes when benchmarking
+	results *benchmark.Results
+}
+
+//BenchmarkParams configures a benchmark run
+type BenchmarkParams struct {
+	//TargetTPS total invokes per second to send, shared between the
+	//chaincodes by Weight. 0 or less sends as fast as possible
+	TargetTPS float64
+	//EventsAddress of the peer's events server used to confirm commits. Empty
+	//to only measure endorsement and ordering
+	EventsAddress string
+	//CommitTimeoutSecs how long to wait for an invoke to be committed
+	CommitTimeoutSecs int
+	//ResultsFile where the results are exported, as JSON if it ends with
+	//.json and CSV otherwise. Empty to not export
+	ResultsFile string
+	//InProcess starts a peer and a solo orderer in the ccchecker process and
+	//deploys the chaincodes on them before running
+	InProcess bool
 }
 
 //LoadCCCheckerParams read the ccchecker params from a file
@@ -57,10 +84,38 @@ func LoadCCCheckerParams(file string) error {
 	}
 
 	ccchecker = &CCChecker{}
+	if sp.Benchmark != nil {
+		ccchecker.Benchmark = sp.Benchmark
+		ccchecker.tracker = benchmark.NewCommitTracker()
+		ccchecker.results = benchmark.NewResults()
+		if ccchecker.Benchmark.CommitTimeoutSecs <= 0 {
+			ccchecker.Benchmark.CommitTimeoutSecs = sp.TimeoutToAbortSecs
+		}
+	}
+
+	totalWeight := 0
+	for _, scc := range sp.Chaincodes {
+		if scc.Concurrency > 0 {
+			if scc.Weight <= 0 {
+				scc.Weight = 1
+			}
+			totalWeight = totalWeight + scc.Weight
+		}
+	}
+
 	id := 0
 	for _, scc := range sp.Chaincodes {
 		//concurrency <=0 will be dropped
 		if scc.Concurrency > 0 {
+			//all the clients of an entry share its rate limiter
+			if sp.Benchmark != nil {
+				limiter := benchmark.NewRateLimiter(sp.Benchmark.TargetTPS * float64(scc.Weight) / float64(totalWeight))
+				var tracker *benchmark.CommitTracker
+				if sp.Benchmark.EventsAddress != "" || sp.Benchmark.InProcess {
+					tracker = ccchecker.tracker
+				}
+				scc.SetBenchmark(limiter, tracker, ccchecker.results, time.Duration(ccchecker.Benchmark.CommitTimeoutSecs)*time.Second)
+			}
 			for i := 0; i < scc.Concurrency; i++ {
 				tmp := &chaincodes.CCClient{}
 				*tmp = *scc
@@ -91,6 +146,23 @@ func CCCheckerInit() {
 
 //CCCheckerRun main loops that will run the tests and cleanup
 func CCCheckerRun(report bool, verbose bool) error {
+	if ccchecker.Benchmark != nil {
+		if ccchecker.Benchmark.InProcess {
+			eventsAddress, err := startInProcessNetwork(ccchecker.ChainName, ccchecker.Chaincodes)
+			if err != nil {
+				return err
+			}
+			ccchecker.Benchmark.EventsAddress = eventsAddress
+		}
+
+		if ccchecker.Benchmark.EventsAddress != "" {
+			if err := ccchecker.tracker.Start(ccchecker.Benchmark.EventsAddress, time.Duration(ccchecker.TimeoutToAbortSecs)*time.Second); err != nil {
+				return err
+			}
+			defer ccchecker.tracker.Stop()
+		}
+	}
+
 	//connect with Broadcast client
 	bc, err := common.GetBroadcastClient()
 	if err != nil {
@@ -137,6 +209,12 @@ func CCCheckerRun(report bool, verbose bool) error {
 	//wait or timeout
 	err = ccchecker.wait(&ccsWG)
 
+	//wait for the invokes to be committed before reporting the benchmark
+	//and validating
+	if err == nil && ccchecker.results != nil {
+		ccchecker.results.WaitForCommits()
+	}
+
 	//verify results
 	if err == nil && failures.failedCCClients < len(ccchecker.Chaincodes) {
 		ccsWG = sync.WaitGroup{}
@@ -161,6 +239,17 @@ func CCCheckerRun(report bool, verbose bool) error {
 		}
 	}
 
+	if ccchecker.results != nil {
+		ccchecker.results.Summarize().Print(os.Stdout)
+		if ccchecker.Benchmark.ResultsFile != "" {
+			if werr := ccchecker.results.WriteFile(ccchecker.Benchmark.ResultsFile); werr != nil {
+				fmt.Printf("Error writing results to %s: %s\n", ccchecker.Benchmark.ResultsFile, werr)
+			} else {
+				fmt.Printf("Results written to %s\n", ccchecker.Benchmark.ResultsFile)
+			}
+		}
+	}
+
 	return err
 }
 
@@ -21,10 +21,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/fabric/examples/ccchecker/benchmark"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/peer/chaincode"
 	"github.com/hyperledger/fabric/peer/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
 
 	"golang.org/x/net/context"
 )
@@ -65,6 +67,9 @@ type CCClient struct {
 	//Path to the chaincode
 	Path string
 
+	//Version of the chaincode, used when ccchecker deploys it
+	Version string
+
 	//NumFinalQueryAttempts number of times to try final query before giving up
 	NumFinalQueryAttempts int
 
@@ -89,6 +94,10 @@ type CCClient struct {
 	//Concurrency number of goroutines to spin
 	Concurrency int
 
+	//Weight share of the benchmark's target TPS given to this chaincode
+	//relative to the others. Defaults to 1
+	Weight int
+
 	//-------------runtime properties ------------
 	//Unique number assigned to this CC by CCChecker
 	ID int
@@ -124,6 +133,30 @@ type CCClient struct {
 
 	//error on a query in an iteration
 	queryErrs []error
+
+	//paces the invokes of all the clients of a chaincode entry when
+	//benchmarking, nil for no limit
+	limiter *benchmark.RateLimiter
+
+	//tracks the commit of the invokes when benchmarking, nil to not wait
+	//for commits
+	tracker *benchmark.CommitTracker
+
+	//where the outcome of the invokes is recorded when benchmarking
+	results *benchmark.Results
+
+	//how long to wait for an invoke to be committed
+	commitTimeout time.Duration
+}
+
+//SetBenchmark makes the client pace its invokes with limiter and record their
+//latencies in results. If tracker is not nil each invoke is also followed
+//until it is committed or commitTimeout expires
+func (cc *CCClient) SetBenchmark(limiter *benchmark.RateLimiter, tracker *benchmark.CommitTracker, results *benchmark.Results, commitTimeout time.Duration) {
+	cc.limiter = limiter
+	cc.tracker = tracker
+	cc.results = results
+	cc.commitTimeout = commitTimeout
 }
 
 func (cc *CCClient) getChaincodeSpec(args [][]byte) *pb.ChaincodeSpec {
@@ -157,8 +190,10 @@ func (cc *CCClient) doInvokes(ctxt context.Context, chainID string,
 			break
 		}
 
+		cc.limiter.Wait()
+
 		var pResp *pb.ProposalResponse
-		if pResp, err = chaincode.ChaincodeInvokeOrQuery(spec, chainID, true, signer, ec, bc); err != nil {
+		if pResp, err = cc.invoke(spec, chainID, signer, ec, bc); err != nil {
 			cc.invokeErr = err
 			break
 		}
@@ -182,6 +217,85 @@ func (cc *CCClient) doInvokes(ctxt context.Context, chainID string,
 	return err
 }
 
+//invoke endorses the invoke and sends it for ordering, timing each step.
+//When benchmarking, the outcome is recorded in the results once the
+//transaction is committed
+func (cc *CCClient) invoke(spec *pb.ChaincodeSpec, chainID string, signer msp.SigningIdentity, ec pb.EndorserClient, bc common.BroadcastClient) (*pb.ProposalResponse, error) {
+	tx := &benchmark.TxResult{
+		Chaincode: cc.Name,
+		Client:    cc.ID,
+		Iteration: cc.currentInvokeIter,
+		Start:     time.Now(),
+	}
+
+	//records failures, the commit of successful invokes is recorded below
+	var err error
+	defer func() {
+		if err != nil && cc.results != nil {
+			tx.Err = err.Error()
+			cc.results.Add(tx)
+		}
+	}()
+
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing identity for %s: %s", signer.GetIdentifier(), err)
+	}
+
+	prop, txID, err := putils.CreateProposalFromCIS(chainID, &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}, creator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating proposal for invoke: %s", err)
+	}
+	tx.TxID = txID
+
+	signedProp, err := putils.GetSignedProposal(prop, signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signed proposal for invoke: %s", err)
+	}
+
+	pResp, err := ec.ProcessProposal(context.Background(), signedProp)
+	tx.Endorse = time.Since(tx.Start)
+	if err != nil {
+		return nil, fmt.Errorf("Error endorsing invoke: %s", err)
+	}
+	if pResp == nil || pResp.Response == nil {
+		err = fmt.Errorf("Error endorsing invoke: no response")
+		return nil, err
+	}
+
+	env, err := putils.CreateSignedTx(prop, signer, pResp)
+	if err != nil {
+		return pResp, fmt.Errorf("Could not assemble transaction, err %s", err)
+	}
+
+	//wait for the TxID before it can possibly be committed
+	var committed <-chan *benchmark.CommitStatus
+	if cc.tracker != nil {
+		committed = cc.tracker.Expect(tx.TxID)
+	}
+
+	sendTime := time.Now()
+	err = bc.Send(env)
+	ordered := time.Now()
+	tx.Order = ordered.Sub(sendTime)
+	if err != nil {
+		if cc.tracker != nil {
+			cc.tracker.Forget(tx.TxID)
+		}
+		return pResp, fmt.Errorf("Error sending transaction invoke: %s", err)
+	}
+
+	if cc.results != nil {
+		if cc.tracker != nil {
+			cc.results.AddWhenCommitted(tx, cc.tracker, committed, ordered, cc.commitTimeout)
+		} else {
+			cc.results.Add(tx)
+		}
+	}
+
+	return pResp, nil
+}
+
 //Run test over given number of iterations
 //  i will be unique across chaincodes and can be used as a key
 //    this is useful if chaincode occurs multiple times in the array of chaincodes
@@ -30,11 +30,15 @@ import (
 //This is where all initializations take place. These closley follow CLI
 //initializations.
 
-//read CC checker configuration from -s <jsonfile>. Defaults to ccchecker.json
-func initCCCheckerParams(mainFlags *pflag.FlagSet) {
-	configFile := ""
-	mainFlags.StringVarP(&configFile, "config", "s", "ccchecker.json", "CC Checker config file ")
+//values of the command line flags, only set once the command is executed
+var (
+	configFile      string
+	pathToYaml      string
+	mspMgrConfigDir string
+)
 
+//read CC checker configuration from -s <jsonfile>. Defaults to ccchecker.json
+func initCCCheckerParams() {
 	err := LoadCCCheckerParams(configFile)
 	if err != nil {
 		fmt.Printf("error unmarshalling ccchecker: %s\n", err)
@@ -43,16 +47,14 @@ func initCCCheckerParams(mainFlags *pflag.FlagSet) {
 }
 
 //read yaml file from -y <dir_to_core.yaml>. Defaults to ../../peer
-func initYaml(mainFlags *pflag.FlagSet) {
+func initYaml() {
 	// For environment variables.
 	viper.SetEnvPrefix(cmdRoot)
 	viper.AutomaticEnv()
 	replacer := strings.NewReplacer(".", "_")
 	viper.SetEnvKeyReplacer(replacer)
 
-	pathToYaml := ""
-	mainFlags.StringVarP(&pathToYaml, "yamlfile", "y", "../../peer", "Path to core.yaml defined for peer")
-
+	viper.AddConfigPath(pathToYaml)
 	err := common.InitConfig(cmdRoot)
 	if err != nil { // Handle errors reading the config file
 		fmt.Printf("Fatal error when reading %s config file: %s\n", cmdRoot, err)
@@ -61,19 +63,23 @@ func initYaml(mainFlags *pflag.FlagSet) {
 }
 
 //initialize MSP from -m <mspconfigdir>. Defaults to ../../msp/sampleconfig
-func initMSP(mainFlags *pflag.FlagSet) {
-	mspMgrConfigDir := ""
-	mainFlags.StringVarP(&mspMgrConfigDir, "mspcfgdir", "m", "../../msp/sampleconfig/", "Path to MSP dir")
-
-	err := common.InitCrypto(mspMgrConfigDir)
+func initMSP() {
+	err := common.InitCrypto(mspMgrConfigDir, false)
 	if err != nil {
 		panic(err.Error())
 	}
 }
 
-//InitCCCheckerEnv initialize the CCChecker environment
-func InitCCCheckerEnv(mainFlags *pflag.FlagSet) {
-	initCCCheckerParams(mainFlags)
-	initYaml(mainFlags)
-	initMSP(mainFlags)
+//InitCCCheckerFlags defines the command line flags of the CCChecker
+func InitCCCheckerFlags(mainFlags *pflag.FlagSet) {
+	mainFlags.StringVarP(&configFile, "config", "s", "ccchecker.json", "CC Checker config file ")
+	mainFlags.StringVarP(&pathToYaml, "yamlfile", "y", "../../peer", "Path to core.yaml defined for peer")
+	mainFlags.StringVarP(&mspMgrConfigDir, "mspcfgdir", "m", "../../msp/sampleconfig/", "Path to MSP dir")
+}
+
+//InitCCCheckerEnv initialize the CCChecker environment from the flags
+func InitCCCheckerEnv() {
+	initCCCheckerParams()
+	initYaml()
+	initMSP()
 }
@@ -0,0 +1,358 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/container"
+	"github.com/hyperledger/fabric/core/deliverservice"
+	"github.com/hyperledger/fabric/core/endorser"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/events/producer"
+	"github.com/hyperledger/fabric/examples/ccchecker/benchmark"
+	"github.com/hyperledger/fabric/examples/ccchecker/chaincodes"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap/provisional"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/deliver"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/rawledger/ramledger"
+	"github.com/hyperledger/fabric/orderer/solo"
+	"github.com/hyperledger/fabric/peer/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+//The in-process network is a solo orderer and a peer running in the ccchecker
+//process, the chaincodes run as local processes of the peer. It closely
+//follows the orderer's main and "peer node start", with everything listening
+//on free local ports and the ledgers in a temporary directory so that runs
+//are repeatable and do not need docker
+
+// default version of the chaincodes deployed on the in-process peer
+const defaultCCVersion = "1.0"
+
+func listenLocal() (net.Listener, error) {
+	return net.Listen("tcp", "127.0.0.1:0")
+}
+
+type broadcastSupport struct {
+	multichain.Manager
+}
+
+func (bs broadcastSupport) GetChain(chainID string) (broadcast.Support, bool) {
+	return bs.Manager.GetChain(chainID)
+}
+
+type deliverSupport struct {
+	multichain.Manager
+}
+
+func (ds deliverSupport) GetChain(chainID string) (deliver.Support, bool) {
+	return ds.Manager.GetChain(chainID)
+}
+
+type ordererServer struct {
+	bh broadcast.Handler
+	dh deliver.Handler
+}
+
+func (s *ordererServer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return s.bh.Handle(srv)
+}
+
+func (s *ordererServer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	return s.dh.Handle(srv)
+}
+
+// startSoloOrderer starts a solo orderer with a RAM ledger bootstrapped with
+// the provisional genesis block for chainID. orderer.yaml is read as the
+// orderer does, so ORDERER_GENERAL_BATCHTIMEOUT and the like apply
+func startSoloOrderer(chainID string) (string, error) {
+	conf := config.Load()
+
+	lf := ramledger.New(int(conf.RAMLedger.HistorySize))
+	genesisBlock := provisional.New(conf).GenesisBlock()
+	genesisChainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse chain ID from genesis block: %s", err)
+	}
+	if genesisChainID != chainID {
+		return "", fmt.Errorf("The in-process orderer only serves %s, not %s", genesisChainID, chainID)
+	}
+	gl, err := lf.GetOrCreate(chainID)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create the genesis chain: %s", err)
+	}
+	if err = gl.Append(genesisBlock); err != nil {
+		return "", fmt.Errorf("Could not write genesis block to ledger: %s", err)
+	}
+
+	// The orderer signs its blocks with the local MSP it shares with the peer
+	manager := multichain.NewManagerImpl(lf, map[string]multichain.Consenter{"solo": solo.New()}, localmsp.NewSigner())
+
+	lis, err := listenLocal()
+	if err != nil {
+		return "", err
+	}
+	grpcServer := grpc.NewServer()
+	ab.RegisterAtomicBroadcastServer(grpcServer, &ordererServer{
+		dh: deliver.NewHandlerImpl(deliverSupport{manager}),
+		bh: broadcast.NewHandlerImpl(broadcastSupport{manager}),
+	})
+	go grpcServer.Serve(lis)
+
+	return lis.Addr().String(), nil
+}
+
+// startPeer starts a peer joined to chainID and delivering from the orderer
+// at ordererAddress. It returns the address of the peer's events server
+func startPeer(chainID string, ordererAddress string) (string, error) {
+	//system chaincodes are deployed with the version of the binary
+	if util.GetSysCCVersion() == "" {
+		return "", fmt.Errorf("The in-process peer needs ccchecker to be built with -ldflags \"-X github.com/hyperledger/fabric/common/metadata.Version=<version>\"")
+	}
+
+	fsPath, err := ioutil.TempDir("", "ccchecker")
+	if err != nil {
+		return "", err
+	}
+
+	lis, err := listenLocal()
+	if err != nil {
+		return "", err
+	}
+	ehubLis, err := listenLocal()
+	if err != nil {
+		return "", err
+	}
+
+	viper.Set("peer.fileSystemPath", fsPath)
+	viper.Set("peer.address", lis.Addr().String())
+	viper.Set("peer.listenAddress", lis.Addr().String())
+	viper.Set("peer.addressAutoDetect", false)
+	viper.Set("peer.events.address", ehubLis.Addr().String())
+	viper.Set("peer.committer.ledger.orderer", ordererAddress)
+	viper.Set("peer.tls.enabled", false)
+	viper.Set("chaincode.mode", "net")
+	viper.Set("vm.type", "process")
+
+	ledgermgmt.Initialize()
+	if err = peer.CacheConfiguration(); err != nil {
+		return "", err
+	}
+	ccprovider.SetChaincodesPath(filepath.Join(fsPath, "chaincodes"))
+
+	peerEndpoint, err := peer.GetPeerEndpoint()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get Peer Endpoint: %s", err)
+	}
+
+	ehubGrpcServer := grpc.NewServer()
+	pb.RegisterEventsServer(ehubGrpcServer, producer.NewEventsServer(
+		uint(viper.GetInt("peer.events.buffersize")),
+		viper.GetInt("peer.events.timeout")))
+
+	grpcServer := grpc.NewServer()
+
+	tOut := viper.GetInt("chaincode.startuptimeout")
+	if tOut <= 0 {
+		tOut = 5000
+	}
+	ccSrv := chaincode.NewChaincodeSupport(peer.GetPeerEndpoint, false, time.Duration(tOut)*time.Millisecond)
+	chaincode.RegisterSysCCs()
+	pb.RegisterChaincodeSupportServer(grpcServer, ccSrv)
+
+	pb.RegisterEndorserServer(grpcServer, endorser.NewEndorserServer())
+
+	service.InitGossipService(peerEndpoint.Address, grpcServer)
+
+	chaincode.DeployChainlessSysCCs()
+
+	block, err := utils.MakeConfigurationBlock(chainID)
+	if err != nil {
+		return "", fmt.Errorf("Unable to create genesis block for [%s] due to [%s]", chainID, err)
+	}
+	if err = peer.CreateChainFromBlock(block); err != nil {
+		return "", fmt.Errorf("Unable to create chain block for [%s] due to [%s]", chainID, err)
+	}
+	chaincode.DeploySysCCs(chainID)
+
+	commit := peer.GetCommitter(chainID)
+	if commit == nil {
+		return "", fmt.Errorf("Unable to get committer for [%s]", chainID)
+	}
+
+	go grpcServer.Serve(lis)
+	go ehubGrpcServer.Serve(ehubLis)
+
+	deliverclient.NewDeliverService(chainID).Start(commit)
+
+	logger.Infof("In-process peer at %s, events at %s, ledgers in %s", peerEndpoint.Address, ehubLis.Addr(), fsPath)
+
+	return ehubLis.Addr().String(), nil
+}
+
+// deployChaincodes installs and instantiates each of the chaincodes once and
+// waits for the instantiations to be committed
+func deployChaincodes(chainID string, ccs []*chaincodes.CCClient, eventsAddress string, timeout time.Duration) error {
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		return err
+	}
+	creator, err := signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s", signer.GetIdentifier(), err)
+	}
+
+	ec, err := common.GetEndorserClient()
+	if err != nil {
+		return err
+	}
+	bc, err := common.GetBroadcastClient()
+	if err != nil {
+		return err
+	}
+	defer bc.Close()
+
+	tracker := benchmark.NewCommitTracker()
+	if err = tracker.Start(eventsAddress, timeout); err != nil {
+		return err
+	}
+	defer tracker.Stop()
+
+	pending := make(map[string]<-chan *benchmark.CommitStatus)
+	for _, cc := range ccs {
+		if _, ok := pending[cc.Name]; ok {
+			continue
+		}
+
+		version := cc.Version
+		if version == "" {
+			version = defaultCCVersion
+		}
+		args := make([][]byte, len(cc.InitArgs))
+		for i, a := range cc.InitArgs {
+			args[i] = []byte(a)
+		}
+		spec := &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value[cc.Lang]),
+			ChaincodeID: &pb.ChaincodeID{Path: cc.Path, Name: cc.Name, Version: version},
+			CtorMsg:     &pb.ChaincodeInput{Args: args},
+		}
+
+		codePackage, err := container.GetChaincodePackageBytes(spec)
+		if err != nil {
+			return fmt.Errorf("Error getting chaincode package bytes for %s: %s", cc.Name, err)
+		}
+		installProp, _, err := utils.CreateInstallProposalFromCDS(chainID, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: codePackage}, creator)
+		if err != nil {
+			return fmt.Errorf("Error creating install proposal for %s: %s", cc.Name, err)
+		}
+		if _, err = endorse(ec, signer, installProp); err != nil {
+			return fmt.Errorf("Error installing %s: %s", cc.Name, err)
+		}
+
+		deployProp, txID, err := utils.CreateDeployProposalFromCDS(chainID, &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec}, creator)
+		if err != nil {
+			return fmt.Errorf("Error creating deploy proposal for %s: %s", cc.Name, err)
+		}
+		pResp, err := endorse(ec, signer, deployProp)
+		if err != nil {
+			return fmt.Errorf("Error instantiating %s: %s", cc.Name, err)
+		}
+		env, err := utils.CreateSignedTx(deployProp, signer, pResp)
+		if err != nil {
+			return fmt.Errorf("Could not assemble transaction, err %s", err)
+		}
+
+		pending[cc.Name] = tracker.Expect(txID)
+		if err = bc.Send(env); err != nil {
+			return fmt.Errorf("Error sending the instantiation of %s: %s", cc.Name, err)
+		}
+	}
+
+	for name, c := range pending {
+		select {
+		case s := <-c:
+			if !s.Valid {
+				return fmt.Errorf("The instantiation of %s was committed as invalid", name)
+			}
+			logger.Infof("Instantiated %s in block %d", name, s.BlockNumber)
+		case <-time.After(timeout):
+			return fmt.Errorf("Timed out waiting for the instantiation of %s to be committed", name)
+		}
+	}
+
+	return nil
+}
+
+// endorse signs prop and has it endorsed, failing on a non-successful response
+func endorse(ec pb.EndorserClient, signer msp.SigningIdentity, prop *pb.Proposal) (*pb.ProposalResponse, error) {
+	signedProp, err := utils.GetSignedProposal(prop, signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+
+	pResp, err := ec.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return nil, err
+	}
+	if pResp == nil || pResp.Response == nil || pResp.Response.Status != 200 {
+		return nil, fmt.Errorf("unexpected response %v", pResp)
+	}
+
+	return pResp, nil
+}
+
+// startInProcessNetwork starts the in-process orderer and peer, deploys the
+// chaincodes and returns the address of the peer's events server
+func startInProcessNetwork(chainID string, ccs []*chaincodes.CCClient) (string, error) {
+	ordererAddress, err := startSoloOrderer(chainID)
+	if err != nil {
+		return "", err
+	}
+
+	eventsAddress, err := startPeer(chainID, ordererAddress)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := time.Duration(ccchecker.TimeoutToAbortSecs) * time.Second
+	if err = deployChaincodes(chainID, ccs, eventsAddress, timeout); err != nil {
+		return "", err
+	}
+
+	return eventsAddress, nil
+}
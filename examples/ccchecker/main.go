@@ -43,8 +43,8 @@ var mainCmd = &cobra.Command{
 func main() {
 	mainFlags := mainCmd.PersistentFlags()
 
-	//initialize the env
-	InitCCCheckerEnv(mainFlags)
+	//the env is initialized from the flags when the command runs
+	InitCCCheckerFlags(mainFlags)
 
 	// On failure Cobra prints the usage message and error string, so we only
 	// need to exit with a non-0 status
@@ -54,9 +54,13 @@ func main() {
 }
 
 func run(args []string) {
+	InitCCCheckerEnv()
 	CCCheckerInit()
 	//TODO make parameters out of report and verbose
-	CCCheckerRun(true, true)
+	if err := CCCheckerRun(true, true); err != nil {
+		fmt.Printf("Test failed: %s\n", err)
+		os.Exit(1)
+	}
 	fmt.Printf("Test complete\n")
 	return
 }
@@ -227,7 +227,7 @@ func (t *AssetManagementChaincode) Invoke(stub shim.ChaincodeStubInterface) ([]b
 func main() {
 
 	//	primitives.SetSecurityLevel("SHA3", 256)
-	err := shim.Start(new(AssetManagementChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(AssetManagementChaincode)))
 	if err != nil {
 		myLogger.Debugf("Error starting AssetManagementChaincode: %s", err)
 	}
@@ -299,7 +299,7 @@ func (t *AssetManagementChaincode) Query(stub shim.ChaincodeStubInterface) ([]by
 
 func main() {
 	primitives.SetSecurityLevel("SHA3", 256)
-	err := shim.Start(new(AssetManagementChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(AssetManagementChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting AssetManagementChaincode: %s", err)
 	}
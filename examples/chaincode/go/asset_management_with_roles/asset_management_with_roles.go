@@ -271,7 +271,7 @@ func (t *AssetManagementChaincode) query(stub shim.ChaincodeStubInterface, args
 }
 
 func main() {
-	err := shim.Start(new(AssetManagementChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(AssetManagementChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting AssetManagementChaincode: %s", err)
 	}
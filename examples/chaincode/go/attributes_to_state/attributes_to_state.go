@@ -134,7 +134,7 @@ func (t *Attributes2State) query(stub shim.ChaincodeStubInterface, args []string
 }
 
 func main() {
-	err := shim.Start(new(Attributes2State))
+	err := shim.Start(shim.AdaptLegacy(new(Attributes2State)))
 	if err != nil {
 		fmt.Printf("Error running Attributes2State chaincode: %s", err)
 	}
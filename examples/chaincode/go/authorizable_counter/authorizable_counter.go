@@ -94,7 +94,7 @@ func (t *AuthorizableCounterChaincode) Invoke(stub shim.ChaincodeStubInterface)
 }
 
 func main() {
-	err := shim.Start(new(AuthorizableCounterChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(AuthorizableCounterChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting Simple chaincode: %s", err)
 	}
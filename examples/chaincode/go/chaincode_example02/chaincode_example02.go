@@ -23,55 +23,55 @@ package main
 //hard-coding.
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 // SimpleChaincode example simple Chaincode implementation
 type SimpleChaincode struct {
 }
 
-func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	_, args := stub.GetFunctionAndParameters()
 	var A, B string    // Entities
 	var Aval, Bval int // Asset holdings
 	var err error
 
 	if len(args) != 4 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 4")
+		return shim.Error("Incorrect number of arguments. Expecting 4")
 	}
 
 	// Initialize the chaincode
 	A = args[0]
 	Aval, err = strconv.Atoi(args[1])
 	if err != nil {
-		return nil, errors.New("Expecting integer value for asset holding")
+		return shim.Error("Expecting integer value for asset holding")
 	}
 	B = args[2]
 	Bval, err = strconv.Atoi(args[3])
 	if err != nil {
-		return nil, errors.New("Expecting integer value for asset holding")
+		return shim.Error("Expecting integer value for asset holding")
 	}
 	fmt.Printf("Aval = %d, Bval = %d\n", Aval, Bval)
 
 	// Write the state to the ledger
 	err = stub.PutState(A, []byte(strconv.Itoa(Aval)))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	err = stub.PutState(B, []byte(strconv.Itoa(Bval)))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
-	return nil, nil
+	return shim.Success(nil)
 }
 
-func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	function, args := stub.GetFunctionAndParameters()
 	if function == "invoke" {
 		// Make payment of X units from A to B
@@ -84,18 +84,18 @@ func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) ([]byte, erro
 		return t.query(stub, args)
 	}
 
-	return nil, errors.New("Invalid invoke function name. Expecting \"invoke\" \"delete\" \"query\"")
+	return shim.Error("Invalid invoke function name. Expecting \"invoke\" \"delete\" \"query\"")
 }
 
 // Transaction makes payment of X units from A to B
-func (t *SimpleChaincode) invoke(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+func (t *SimpleChaincode) invoke(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	var A, B string    // Entities
 	var Aval, Bval int // Asset holdings
 	var X int          // Transaction value
 	var err error
 
 	if len(args) != 3 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+		return shim.Error("Incorrect number of arguments. Expecting 3")
 	}
 
 	A = args[0]
@@ -105,26 +105,26 @@ func (t *SimpleChaincode) invoke(stub shim.ChaincodeStubInterface, args []string
 	// TODO: will be nice to have a GetAllState call to ledger
 	Avalbytes, err := stub.GetState(A)
 	if err != nil {
-		return nil, errors.New("Failed to get state")
+		return shim.Error("Failed to get state")
 	}
 	if Avalbytes == nil {
-		return nil, errors.New("Entity not found")
+		return shim.Error("Entity not found")
 	}
 	Aval, _ = strconv.Atoi(string(Avalbytes))
 
 	Bvalbytes, err := stub.GetState(B)
 	if err != nil {
-		return nil, errors.New("Failed to get state")
+		return shim.Error("Failed to get state")
 	}
 	if Bvalbytes == nil {
-		return nil, errors.New("Entity not found")
+		return shim.Error("Entity not found")
 	}
 	Bval, _ = strconv.Atoi(string(Bvalbytes))
 
 	// Perform the execution
 	X, err = strconv.Atoi(args[2])
 	if err != nil {
-		return nil, errors.New("Invalid transaction amount, expecting a integer value")
+		return shim.Error("Invalid transaction amount, expecting a integer value")
 	}
 	Aval = Aval - X
 	Bval = Bval + X
@@ -133,21 +133,21 @@ func (t *SimpleChaincode) invoke(stub shim.ChaincodeStubInterface, args []string
 	// Write the state back to the ledger
 	err = stub.PutState(A, []byte(strconv.Itoa(Aval)))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
 	err = stub.PutState(B, []byte(strconv.Itoa(Bval)))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
 
-	return nil, nil
+	return shim.Success(nil)
 }
 
 // Deletes an entity from state
-func (t *SimpleChaincode) delete(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+func (t *SimpleChaincode) delete(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	if len(args) != 1 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+		return shim.Error("Incorrect number of arguments. Expecting 1")
 	}
 
 	A := args[0]
@@ -155,19 +155,19 @@ func (t *SimpleChaincode) delete(stub shim.ChaincodeStubInterface, args []string
 	// Delete the key from the state in ledger
 	err := stub.DelState(A)
 	if err != nil {
-		return nil, errors.New("Failed to delete state")
+		return shim.Error("Failed to delete state")
 	}
 
-	return nil, nil
+	return shim.Success(nil)
 }
 
 // query callback representing the query of a chaincode
-func (t *SimpleChaincode) query(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+func (t *SimpleChaincode) query(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	var A string // Entities
 	var err error
 
 	if len(args) != 1 {
-		return nil, errors.New("Incorrect number of arguments. Expecting name of the person to query")
+		return shim.Error("Incorrect number of arguments. Expecting name of the person to query")
 	}
 
 	A = args[0]
@@ -176,17 +176,17 @@ func (t *SimpleChaincode) query(stub shim.ChaincodeStubInterface, args []string)
 	Avalbytes, err := stub.GetState(A)
 	if err != nil {
 		jsonResp := "{\"Error\":\"Failed to get state for " + A + "\"}"
-		return nil, errors.New(jsonResp)
+		return shim.Error(jsonResp)
 	}
 
 	if Avalbytes == nil {
 		jsonResp := "{\"Error\":\"Nil amount for " + A + "\"}"
-		return nil, errors.New(jsonResp)
+		return shim.Error(jsonResp)
 	}
 
 	jsonResp := "{\"Name\":\"" + A + "\",\"Amount\":\"" + string(Avalbytes) + "\"}"
 	fmt.Printf("Query Response:%s\n", jsonResp)
-	return Avalbytes, nil
+	return shim.Success(Avalbytes)
 }
 
 func main() {
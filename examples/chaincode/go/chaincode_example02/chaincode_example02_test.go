@@ -23,9 +23,9 @@ import (
 )
 
 func checkInit(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInit("1", args)
-	if err != nil {
-		fmt.Println("Init failed", err)
+	res := stub.MockInit("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -43,11 +43,12 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkQuery(t *testing.T, stub *shim.MockStub, name string, value string) {
-	bytes, err := stub.MockInvoke("1", [][]byte{[]byte("query"), []byte(name)})
-	if err != nil {
-		fmt.Println("Query", name, "failed", err)
+	res := stub.MockInvoke("1", [][]byte{[]byte("query"), []byte(name)})
+	if res.Status != shim.OK {
+		fmt.Println("Query", name, "failed", res.Message)
 		t.FailNow()
 	}
+	bytes := res.Payload
 	if bytes == nil {
 		fmt.Println("Query", name, "failed to get value")
 		t.FailNow()
@@ -59,9 +60,9 @@ func checkQuery(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkInvoke(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Invoke", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.FailNow()
 	}
 }
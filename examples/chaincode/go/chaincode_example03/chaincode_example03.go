@@ -94,7 +94,7 @@ func (t *SimpleChaincode) query(stub shim.ChaincodeStubInterface, args []string)
 }
 
 func main() {
-	err := shim.Start(new(SimpleChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(SimpleChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting chaincode: %s", err)
 	}
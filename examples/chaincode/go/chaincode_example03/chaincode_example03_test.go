@@ -23,9 +23,9 @@ import (
 )
 
 func checkInit(t *testing.T, scc *SimpleChaincode, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInit("1", args)
-	if err != nil {
-		fmt.Println("Init failed", err)
+	res := stub.MockInit("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -43,7 +43,7 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkQuery(t *testing.T, scc *SimpleChaincode, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInit("1", args)
+	stub.MockInit("1", args)
 	bytes, err := scc.Invoke(stub)
 	if err != nil {
 		// expected failure
@@ -63,16 +63,16 @@ func checkQuery(t *testing.T, scc *SimpleChaincode, stub *shim.MockStub, args []
 }
 
 func checkInvoke(t *testing.T, scc *SimpleChaincode, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Invoke", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.FailNow()
 	}
 }
 
 func TestExample03_Init(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex03", scc)
+	stub := shim.NewMockStub("ex03", shim.AdaptLegacy(scc))
 
 	// Init A=123 B=234
 	checkInit(t, scc, stub, [][]byte{[]byte("init"), []byte("A"), []byte("123")})
@@ -82,7 +82,7 @@ func TestExample03_Init(t *testing.T) {
 
 func TestExample03_Query(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex03", scc)
+	stub := shim.NewMockStub("ex03", shim.AdaptLegacy(scc))
 
 	// Init A=345 B=456
 	checkInit(t, scc, stub, [][]byte{[]byte("init"), []byte("A"), []byte("345")})
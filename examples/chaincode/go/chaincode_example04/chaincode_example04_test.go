@@ -27,9 +27,9 @@ import (
 var eventResponse = "{\"Name\":\"Event\",\"Amount\":\"1\"}"
 
 func checkInit(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInit("1", args)
-	if err != nil {
-		fmt.Println("Init failed", err)
+	res := stub.MockInit("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -47,11 +47,12 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkQuery(t *testing.T, stub *shim.MockStub, name string, value string) {
-	bytes, err := stub.MockInvoke("1", [][]byte{[]byte("query"), []byte(name)})
-	if err != nil {
-		fmt.Println("Query", name, "failed", err)
+	res := stub.MockInvoke("1", [][]byte{[]byte("query"), []byte(name)})
+	if res.Status != shim.OK {
+		fmt.Println("Query", name, "failed", res.Message)
 		t.FailNow()
 	}
+	bytes := res.Payload
 	if bytes == nil {
 		fmt.Println("Query", name, "failed to get value")
 		t.FailNow()
@@ -63,16 +64,16 @@ func checkQuery(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkInvoke(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Invoke", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.FailNow()
 	}
 }
 
 func TestExample04_Init(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex04", scc)
+	stub := shim.NewMockStub("ex04", shim.AdaptLegacy(scc))
 
 	// Init A=123 B=234
 	checkInit(t, stub, [][]byte{[]byte("init"), []byte("Event"), []byte("123")})
@@ -82,7 +83,7 @@ func TestExample04_Init(t *testing.T) {
 
 func TestExample04_Query(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex04", scc)
+	stub := shim.NewMockStub("ex04", shim.AdaptLegacy(scc))
 
 	// Init A=345 B=456
 	checkInit(t, stub, [][]byte{[]byte("init"), []byte("Event"), []byte("1")})
@@ -93,7 +94,7 @@ func TestExample04_Query(t *testing.T) {
 
 func TestExample04_Invoke(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex04", scc)
+	stub := shim.NewMockStub("ex04", shim.AdaptLegacy(scc))
 
 	chaincodeToInvoke := "ex02"
 
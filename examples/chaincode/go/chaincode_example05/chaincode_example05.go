@@ -178,7 +178,7 @@ func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) ([]byte, erro
 }
 
 func main() {
-	err := shim.Start(new(SimpleChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(SimpleChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting Simple chaincode: %s", err)
 	}
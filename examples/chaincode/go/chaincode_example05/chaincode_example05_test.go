@@ -33,9 +33,9 @@ func jsonResponse(name string, value string) string {
 }
 
 func checkInit(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInit("1", args)
-	if err != nil {
-		fmt.Println("Init failed", err)
+	res := stub.MockInit("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
 }
@@ -53,11 +53,12 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, expect string) {
 }
 
 func checkQuery(t *testing.T, stub *shim.MockStub, args [][]byte, expect string) {
-	bytes, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Query", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Query", args, "failed", res.Message)
 		t.FailNow()
 	}
+	bytes := res.Payload
 	if bytes == nil {
 		fmt.Println("Query", args, "failed to get result")
 		t.FailNow()
@@ -69,16 +70,16 @@ func checkQuery(t *testing.T, stub *shim.MockStub, args [][]byte, expect string)
 }
 
 func checkInvoke(t *testing.T, stub *shim.MockStub, args [][]byte) {
-	_, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Invoke", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.FailNow()
 	}
 }
 
 func TestExample04_Init(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex05", scc)
+	stub := shim.NewMockStub("ex05", shim.AdaptLegacy(scc))
 
 	// Init A=123 B=234
 	checkInit(t, stub, [][]byte{[]byte("init"), []byte("sumStoreName"), []byte("432")})
@@ -88,7 +89,7 @@ func TestExample04_Init(t *testing.T) {
 
 func TestExample04_Query(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex05", scc)
+	stub := shim.NewMockStub("ex05", shim.AdaptLegacy(scc))
 
 	ccEx2 := new(ex02.SimpleChaincode)
 	stubEx2 := shim.NewMockStub("ex02", ccEx2)
@@ -103,7 +104,7 @@ func TestExample04_Query(t *testing.T) {
 
 func TestExample04_Invoke(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex05", scc)
+	stub := shim.NewMockStub("ex05", shim.AdaptLegacy(scc))
 
 	ccEx2 := new(ex02.SimpleChaincode)
 	stubEx2 := shim.NewMockStub("ex02", ccEx2)
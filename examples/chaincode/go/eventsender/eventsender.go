@@ -91,7 +91,7 @@ func (t *EventSender) Invoke(stub shim.ChaincodeStubInterface) ([]byte, error) {
 }
 
 func main() {
-	err := shim.Start(new(EventSender))
+	err := shim.Start(shim.AdaptLegacy(new(EventSender)))
 	if err != nil {
 		fmt.Printf("Error starting EventSender chaincode: %s", err)
 	}
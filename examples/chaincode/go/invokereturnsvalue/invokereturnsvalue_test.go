@@ -23,11 +23,12 @@ import (
 )
 
 func checkInit(t *testing.T, stub *shim.MockStub, args [][]byte, retval []byte) {
-	result, err := stub.MockInit("1", args)
-	if err != nil {
-		fmt.Println("Init failed", err)
+	res := stub.MockInit("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Init failed", res.Message)
 		t.FailNow()
 	}
+	result := res.Payload
 	if retval != nil {
 		if result == nil {
 			fmt.Printf("Init returned nil, expected %s", string(retval))
@@ -53,11 +54,12 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkInvoke(t *testing.T, stub *shim.MockStub, args [][]byte, retval []byte) {
-	result, err := stub.MockInvoke("1", args)
-	if err != nil {
-		fmt.Println("Invoke", args, "failed", err)
+	res := stub.MockInvoke("1", args)
+	if res.Status != shim.OK {
+		fmt.Println("Invoke", args, "failed", res.Message)
 		t.FailNow()
 	}
+	result := res.Payload
 
 	if retval != nil {
 		if result == nil {
@@ -73,7 +75,7 @@ func checkInvoke(t *testing.T, stub *shim.MockStub, args [][]byte, retval []byte
 
 func Test_Init(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex02", scc)
+	stub := shim.NewMockStub("ex02", shim.AdaptLegacy(scc))
 
 	// Init A=123 B=234
 	checkInit(t, stub, [][]byte{[]byte("init"), []byte("A"), []byte("123"), []byte("B"), []byte("234")}, []byte("OK"))
@@ -84,7 +86,7 @@ func Test_Init(t *testing.T) {
 
 func Test_Invoke(t *testing.T) {
 	scc := new(SimpleChaincode)
-	stub := shim.NewMockStub("ex02", scc)
+	stub := shim.NewMockStub("ex02", shim.AdaptLegacy(scc))
 
 	// Init A=567 B=678
 	checkInit(t, stub, [][]byte{[]byte("init"), []byte("A"), []byte("567"), []byte("B"), []byte("678")}, []byte("OK"))
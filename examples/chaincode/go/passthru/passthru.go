@@ -58,7 +58,7 @@ func (p *PassthruChaincode) Invoke(stub shim.ChaincodeStubInterface) ([]byte, er
 }
 
 func main() {
-	err := shim.Start(new(PassthruChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(PassthruChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting Passthru chaincode: %s", err)
 	}
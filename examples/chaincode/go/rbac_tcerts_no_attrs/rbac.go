@@ -299,7 +299,7 @@ func (t *RBACChaincode) hasInvokerRole(stub shim.ChaincodeStubInterface, role st
 }
 
 func main() {
-	err := shim.Start(new(RBACChaincode))
+	err := shim.Start(shim.AdaptLegacy(new(RBACChaincode)))
 	if err != nil {
 		fmt.Printf("Error starting AssetManagementChaincode: %s", err)
 	}
@@ -62,11 +62,18 @@ func newConfig(selfEndpoint string, bootPeers ...string) *gossip.Config {
 
 // NewGossipComponent creates a gossip component that attaches itself to the given gRPC server
 func NewGossipComponent(endpoint string, s *grpc.Server, dialOpts []grpc.DialOption, bootPeers ...string) gossip.Gossip {
+	return NewGossipComponentWithBlockVerifier(endpoint, s, dialOpts, nil, bootPeers...)
+}
+
+// NewGossipComponentWithBlockVerifier creates a gossip component that attaches itself to the given gRPC server
+// and only accepts the blocks for which verifyBlock returns nil
+func NewGossipComponentWithBlockVerifier(endpoint string, s *grpc.Server, dialOpts []grpc.DialOption, verifyBlock func(api.SignedBlock) error, bootPeers ...string) gossip.Gossip {
 	conf := newConfig(endpoint, bootPeers...)
-	return gossip.NewGossipService(conf, s, &orgCryptoService{}, &naiveCryptoService{}, []byte(endpoint), dialOpts...)
+	return gossip.NewGossipService(conf, s, &orgCryptoService{}, &naiveCryptoService{verifyBlock: verifyBlock}, []byte(endpoint), dialOpts...)
 }
 
 type naiveCryptoService struct {
+	verifyBlock func(api.SignedBlock) error
 }
 
 // ValidateIdentity validates the given identity.
@@ -82,8 +89,11 @@ func (*naiveCryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityType) com
 
 // VerifyBlock returns nil if the block is properly signed,
 // else returns error
-func (*naiveCryptoService) VerifyBlock(signedBlock api.SignedBlock) error {
-	return nil
+func (cs *naiveCryptoService) VerifyBlock(signedBlock api.SignedBlock) error {
+	if cs.verifyBlock == nil {
+		return nil
+	}
+	return cs.verifyBlock(signedBlock)
 }
 
 // Sign signs msg with this peer's signing key and outputs
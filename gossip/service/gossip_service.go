@@ -17,6 +17,7 @@ limitations under the License.
 package service
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -73,8 +74,9 @@ type GossipService interface {
 
 type gossipServiceImpl struct {
 	gossipSvc
-	chains map[string]state.GossipStateProvider
-	lock   sync.RWMutex
+	chains     map[string]state.GossipStateProvider
+	committers map[string]committer.Committer
+	lock       sync.RWMutex
 }
 
 var logger = logging.MustGetLogger("gossipService")
@@ -90,11 +92,12 @@ func InitGossipService(endpoint string, s *grpc.Server, bootPeers ...string) {
 			dialOpts = append(dialOpts, grpc.WithInsecure())
 		}
 
-		gossip := integration.NewGossipComponent(endpoint, s, dialOpts, bootPeers...)
 		gossipServiceInstance = &gossipServiceImpl{
-			gossipSvc: gossip,
-			chains:    make(map[string]state.GossipStateProvider),
+			chains:     make(map[string]state.GossipStateProvider),
+			committers: make(map[string]committer.Committer),
 		}
+		gossipServiceInstance.gossipSvc = integration.NewGossipComponentWithBlockVerifier(endpoint, s, dialOpts,
+			gossipServiceInstance.verifyBlock, bootPeers...)
 	})
 }
 
@@ -114,6 +117,7 @@ func (g *gossipServiceImpl) JoinChannel(commiter committer.Committer, block *com
 		// Initialize new state provider for given committer
 		logger.Debug("Creating state provider for chainID", chainID)
 		g.chains[chainID] = state.NewGossipStateProvider(chainID, g, commiter)
+		g.committers[chainID] = commiter
 		g.JoinChan(&joinChanMsg{}, gossipCommon.ChainID(chainID))
 	}
 
@@ -134,6 +138,31 @@ func (g *gossipServiceImpl) AddPayload(chainID string, payload *proto.Payload) e
 	return g.chains[chainID].AddPayload(payload)
 }
 
+// verifyBlock checks a block gossiped by another peer with the
+// committer of the chain the block belongs to
+func (g *gossipServiceImpl) verifyBlock(signedBlock api.SignedBlock) error {
+	payload, ok := signedBlock.(*proto.Payload)
+	if !ok {
+		return fmt.Errorf("Unexpected type of signed block: %T", signedBlock)
+	}
+	block, err := utils.GetBlockFromBlockBytes(payload.Data)
+	if err != nil {
+		return fmt.Errorf("Cannot unmarshal block: %s", err)
+	}
+	chainID, err := utils.GetChainIDFromBlock(block)
+	if err != nil {
+		return err
+	}
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	commiter, ok := g.committers[chainID]
+	if !ok {
+		return fmt.Errorf("Received block of unknown chain %s", chainID)
+	}
+	return commiter.VerifyBlock(block)
+}
+
 // Stop stops the gossip component
 func (g *gossipServiceImpl) Stop() {
 	for _, ch := range g.chains {
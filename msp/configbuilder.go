@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"encoding/pem"
 	"path/filepath"
 
+	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/protos/msp"
 )
 
@@ -69,40 +71,138 @@ const (
 	keystore   = "keystore"
 )
 
-func GetLocalMspConfig(dir string) (*msp.MSPConfig, error) {
-	cacertDir := filepath.Join(dir, cacerts)
-	signcertDir := filepath.Join(dir, signcerts)
-	admincertDir := filepath.Join(dir, admincerts)
+// decryptPemKey returns the PEM key raw in clear, decrypting it with pwd
+// if it is encrypted
+func decryptPemKey(raw, pwd []byte) ([]byte, error) {
+	block, _ := pem.Decode(raw)
+	if !utils.IsEncryptedPEMBlock(block) {
+		return raw, nil
+	}
+
+	der, err := utils.DecryptPEMBlock(block, pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+}
+
+// RotateSigningKeyPassphrase re-encrypts the signing keys in the keystore
+// directory of the MSP laid out in dir with newPwd; keys are decrypted with
+// pwd if they are encrypted, and a nil newPwd stores them in clear. All the
+// keys are decrypted before any file is rewritten, so that a wrong pwd
+// leaves the directory untouched.
+func RotateSigningKeyPassphrase(dir string, pwd, newPwd []byte) error {
 	keystoreDir := filepath.Join(dir, keystore)
 
-	cacerts, err := getPemMaterialFromDir(cacertDir)
-	if err != nil || len(cacerts) == 0 {
-		return nil, fmt.Errorf("Could not load a valid ca certificate from directory %s, err %s", cacertDir, err)
+	// A signing key held by the BCCSP is not rotated here
+	files, err := ioutil.ReadDir(keystoreDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Could not read directory %s, err %s", keystoreDir, err)
 	}
 
+	rotated := make(map[string][]byte)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		fullName := filepath.Join(keystoreDir, f.Name())
+		raw, err := readPemFile(fullName)
+		if err != nil {
+			continue
+		}
+
+		key, err := decryptPemKey(raw, pwd)
+		if err != nil {
+			return fmt.Errorf("Could not decrypt the signing key %s, err %s", fullName, err)
+		}
+
+		if newPwd != nil {
+			block, _ := pem.Decode(key)
+			if block, err = utils.EncryptPEMBlock(block.Type, block.Bytes, newPwd); err != nil {
+				return fmt.Errorf("Could not encrypt the signing key %s, err %s", fullName, err)
+			}
+			key = pem.EncodeToMemory(block)
+		}
+
+		rotated[fullName] = key
+	}
+
+	// Write each key next to the old one and swap them, so that a file
+	// is never left half written
+	for file, key := range rotated {
+		if err = ioutil.WriteFile(file+".tmp", key, 0600); err != nil {
+			return fmt.Errorf("Could not write file %s, err %s", file, err)
+		}
+		if err = os.Rename(file+".tmp", file); err != nil {
+			return fmt.Errorf("Could not write file %s, err %s", file, err)
+		}
+	}
+
+	mspLogger.Infof("Rotated the passphrase of %d signing keys in %s", len(rotated), keystoreDir)
+
+	return nil
+}
+
+func getSigningIdentityInfoFromDir(dir string, pwd []byte) (*msp.SigningIdentityInfo, error) {
+	signcertDir := filepath.Join(dir, signcerts)
+	keystoreDir := filepath.Join(dir, keystore)
+
 	signcert, err := getPemMaterialFromDir(signcertDir)
 	if err != nil || len(signcert) == 0 {
 		return nil, fmt.Errorf("Could not load a valid signer certificate from directory %s, err %s", signcertDir, err)
 	}
 
-	admincert, err := getPemMaterialFromDir(admincertDir)
-	if err != nil || len(admincert) == 0 {
-		return nil, fmt.Errorf("Could not load a valid admin certificate from directory %s, err %s", admincertDir, err)
-	}
-
-	keys, err := getPemMaterialFromDir(keystoreDir)
-	if err != nil || len(keys) == 0 {
-		return nil, fmt.Errorf("Could not load a valid signing key from directory %s, err %s", keystoreDir, err)
+	// Without a keystore directory or a key in it, the signing key is held
+	// by the default BCCSP, an HSM for instance, and the MSP finds it there
+	// by the SKI of the signing cert
+	var keys [][]byte
+	if _, err = os.Stat(keystoreDir); err == nil {
+		keys, err = getPemMaterialFromDir(keystoreDir)
+		if err != nil {
+			return nil, fmt.Errorf("Could not load a valid signing key from directory %s, err %s", keystoreDir, err)
+		}
 	}
 
 	// FIXME: for now we're making the following assumptions
 	// 1) there is exactly one signing cert
-	// 2) there is exactly one signing key
+	// 2) there is at most one signing key
 	// 3) the cert and the key match
 
-	keyinfo := &msp.KeyInfo{KeyIdentifier: "PEER", KeyMaterial: keys[0]}
+	keyinfo := &msp.KeyInfo{KeyIdentifier: "PEER"}
+	if len(keys) == 0 {
+		mspLogger.Infof("No signing key in directory %s, expecting it in the BCCSP", keystoreDir)
+	} else if keyinfo.KeyMaterial, err = decryptPemKey(keys[0], pwd); err != nil {
+		return nil, fmt.Errorf("Could not decrypt the signing key from directory %s, err %s", keystoreDir, err)
+	}
 
-	sigid := &msp.SigningIdentityInfo{PublicSigner: signcert[0], PrivateSigner: keyinfo}
+	return &msp.SigningIdentityInfo{PublicSigner: signcert[0], PrivateSigner: keyinfo}, nil
+}
+
+// GetLocalMspConfig returns the configuration of the MSP laid out in dir;
+// the signing key in the keystore directory is decrypted with pwd if it
+// is encrypted
+func GetLocalMspConfig(dir string, pwd []byte) (*msp.MSPConfig, error) {
+	cacertDir := filepath.Join(dir, cacerts)
+	admincertDir := filepath.Join(dir, admincerts)
+
+	cacerts, err := getPemMaterialFromDir(cacertDir)
+	if err != nil || len(cacerts) == 0 {
+		return nil, fmt.Errorf("Could not load a valid ca certificate from directory %s, err %s", cacertDir, err)
+	}
+
+	sigid, err := getSigningIdentityInfoFromDir(dir, pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	admincert, err := getPemMaterialFromDir(admincertDir)
+	if err != nil || len(admincert) == 0 {
+		return nil, fmt.Errorf("Could not load a valid admin certificate from directory %s, err %s", admincertDir, err)
+	}
 
 	fmspconf := msp.FabricMSPConfig{Admins: admincert, RootCerts: cacerts, SigningIdentity: sigid, Name: "DEFAULT"}
 
@@ -112,3 +212,17 @@ func GetLocalMspConfig(dir string) (*msp.MSPConfig, error) {
 
 	return mspconf, nil
 }
+
+// SetSigningIdentityDir sets the directory the default signing identity
+// of the supplied MSP is renewed from; the directory is laid out as the
+// one read by GetLocalMspConfig, whose signing key is decrypted with pwd
+func SetSigningIdentityDir(m MSP, dir string, pwd []byte) error {
+	bmsp, ok := m.(*bccspmsp)
+	if !ok {
+		return fmt.Errorf("MSP of type %T does not support renewal", m)
+	}
+
+	bmsp.signerDir = dir
+	bmsp.signerPwd = pwd
+	return nil
+}
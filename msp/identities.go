@@ -17,6 +17,7 @@ limitations under the License.
 package msp
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/x509"
 	"fmt"
@@ -25,9 +26,12 @@ import (
 
 	"errors"
 
+	"sync"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/signer"
+	"github.com/hyperledger/fabric/bccsp/utils"
 )
 
 type identity struct {
@@ -76,6 +80,13 @@ func (id *identity) GetOrganizationUnits() string {
 func (id *identity) Verify(msg []byte, sig []byte) error {
 	mspLogger.Infof("Verifying signature")
 
+	// Reject malleable signatures whatever the BCCSP
+	if pk, ok := id.cert.PublicKey.(*ecdsa.PublicKey); ok {
+		if err := utils.CheckLowS(pk, sig); err != nil {
+			return fmt.Errorf("The signature is invalid, err %s", err)
+		}
+	}
+
 	// Compute Hash
 	digest, err := id.msp.bccsp.Hash(msg, &bccsp.SHAOpts{})
 	if err != nil {
@@ -129,11 +140,42 @@ type signingidentity struct {
 
 	// signer corresponds to the object that can produce signatures from this identity
 	signer *signer.CryptoSigner
+
+	// lock guards the certificate, the public key and the signer,
+	// which Renew replaces
+	lock sync.RWMutex
 }
 
 func newSigningIdentity(id *IdentityIdentifier, cert *x509.Certificate, pk bccsp.Key, signer *signer.CryptoSigner, msp *bccspmsp) SigningIdentity {
 	mspLogger.Infof("Creating signing identity instance for ID %s", id)
-	return &signingidentity{identity{id: id, cert: cert, pk: pk, msp: msp}, signer}
+	return &signingidentity{identity: identity{id: id, cert: cert, pk: pk, msp: msp}, signer: signer}
+}
+
+// public returns a copy of the public part of this instance, which
+// is not affected by later renewals
+func (id *signingidentity) public() *identity {
+	id.lock.RLock()
+	defer id.lock.RUnlock()
+
+	pub := id.identity
+	return &pub
+}
+
+// Validate returns nil if this instance is a valid identity or an error otherwise
+func (id *signingidentity) Validate() error {
+	return id.public().Validate()
+}
+
+// Verify checks against a signature and a message
+// to determine whether this identity produced the
+// signature; it returns nil if so or an error otherwise
+func (id *signingidentity) Verify(msg []byte, sig []byte) error {
+	return id.public().Verify(msg, sig)
+}
+
+// Serialize returns a byte array representation of this identity
+func (id *signingidentity) Serialize() ([]byte, error) {
+	return id.public().Serialize()
 }
 
 // Sign produces a signature over msg, signed by this instance
@@ -147,7 +189,11 @@ func (id *signingidentity) Sign(msg []byte) ([]byte, error) {
 	}
 
 	// Sign
-	return id.signer.Sign(rand.Reader, digest, nil)
+	id.lock.RLock()
+	signer := id.signer
+	id.lock.RUnlock()
+
+	return signer.Sign(rand.Reader, digest, nil)
 }
 
 func (id *signingidentity) SignOpts(msg []byte, opts SignatureOpts) ([]byte, error) {
@@ -161,10 +207,26 @@ func (id *signingidentity) GetAttributeProof(spec *AttributeProofSpec) (proof []
 }
 
 func (id *signingidentity) GetPublicVersion() Identity {
-	return &id.identity
+	return id.public()
 }
 
+// Renew reloads the certificate and the key of this identity from the
+// directory the MSP was loaded from, once they have been rotated there.
+// The new certificate must be valid for the MSP and match the new key,
+// otherwise the identity is left unchanged.
 func (id *signingidentity) Renew() error {
-	// TODO
+	renewed, err := id.msp.loadRenewedSigningIdentity()
+	if err != nil {
+		return err
+	}
+
+	id.lock.Lock()
+	defer id.lock.Unlock()
+
+	id.cert = renewed.cert
+	id.pk = renewed.pk
+	id.signer = renewed.signer
+	mspLogger.Infof("Renewed signing identity %s, the certificate expires on %s", id.id, id.cert.NotAfter)
+
 	return nil
 }
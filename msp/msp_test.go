@@ -1,9 +1,25 @@
 package msp
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric/msp/testtools"
 )
 
 var localMsp MSP
@@ -50,7 +66,7 @@ func TestNoopMSP(t *testing.T) {
 }
 
 func TestMSPSetupBad(t *testing.T) {
-	_, err := GetLocalMspConfig("barf")
+	_, err := GetLocalMspConfig("barf", nil)
 	if err == nil {
 		t.Fatalf("Setup should have failed on an invalid config file")
 		return
@@ -58,7 +74,7 @@ func TestMSPSetupBad(t *testing.T) {
 }
 
 func TestMSPSetupGood(t *testing.T) {
-	conf, err := GetLocalMspConfig("./sampleconfig/")
+	conf, err := GetLocalMspConfig("./sampleconfig/", nil)
 	if err != nil {
 		t.Fatalf("Setup should have succeeded, got err %s instead", err)
 		return
@@ -155,7 +171,334 @@ func TestSignAndVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyHighS(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetSigningIdentity should have succeeded")
+	}
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign should have succeeded")
+	}
+
+	pk := id.(*signingidentity).cert.PublicKey.(*ecdsa.PublicKey)
+	r, s, err := utils.UnmarshalECDSASignature(sig)
+	if err != nil {
+		t.Fatalf("Failed unmarshalling signature [%s]", err)
+	}
+	if !utils.IsLowS(pk, s) {
+		t.Fatalf("Sign should have produced a low S")
+	}
+	highSig, err := utils.MarshalECDSASignature(r, new(big.Int).Sub(pk.Curve.Params().N, s))
+	if err != nil {
+		t.Fatalf("Failed marshalling signature [%s]", err)
+	}
+
+	err = id.Verify(msg, highSig)
+	if err != nil {
+		t.Fatalf("A high S signature should be valid unless low S is enforced [%s]", err)
+	}
+
+	utils.SetLowSOnly(true)
+	defer utils.SetLowSOnly(false)
+	err = id.Verify(msg, highSig)
+	if err == nil {
+		t.Fatalf("A high S signature should be rejected when low S is enforced")
+	}
+	err = id.Verify(msg, sig)
+	if err != nil {
+		t.Fatalf("The signature should be valid [%s]", err)
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+	roles := make(map[string]int)
+	for _, c := range GetCertificates(localMsp) {
+		roles[c.Role]++
+	}
+	if roles[RootCertRole] != 1 || roles[AdminCertRole] != 1 || roles[SigningCertRole] != 1 {
+		t.Fatalf("Expected one certificate per role, got %v", roles)
+	}
+
+	if GetCertificates(&noopmsp{}) != nil {
+		t.Fatalf("The noop MSP should have no certificates")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msp")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir [%s]", err)
+	}
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	if err = testtools.GenerateMSPDir(dir, now.Add(-time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed generating MSP [%s]", err)
+	}
+
+	conf, err := GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+	thisMsp, err := NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Constructor for msp should have succeeded, got err %s", err)
+	}
+	if err = thisMsp.Setup(conf); err != nil {
+		t.Fatalf("Setup for msp should have succeeded, got err %s", err)
+	}
+	id, err := thisMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity should have succeeded, got err %s", err)
+	}
+
+	if err = id.Renew(); err == nil {
+		t.Fatalf("Renew should fail for an MSP not loaded from a directory")
+	}
+
+	if err = SetSigningIdentityDir(thisMsp, dir, nil); err != nil {
+		t.Fatalf("SetSigningIdentityDir should have succeeded, got err %s", err)
+	}
+	if err = id.Renew(); err != nil {
+		t.Fatalf("Renew should have succeeded, got err %s", err)
+	}
+	before, _ := id.Serialize()
+
+	// A key that does not match the certificate is refused
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed generating key [%s]", err)
+	}
+	raw, _ := x509.MarshalECPrivateKey(key)
+	keyFiles, _ := filepath.Glob(filepath.Join(dir, "keystore", "*"))
+	ioutil.WriteFile(keyFiles[0], pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: raw}), 0600)
+
+	if err = id.Renew(); err == nil {
+		t.Fatalf("Renew should fail when the key does not match the certificate")
+	}
+	after, _ := id.Serialize()
+	if !bytes.Equal(before, after) {
+		t.Fatalf("A failed Renew should leave the identity unchanged")
+	}
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign should have succeeded, got err %s", err)
+	}
+	if err = id.Verify(msg, sig); err != nil {
+		t.Fatalf("The signature should be valid after a failed Renew, got err %s", err)
+	}
+
+	if err = SetSigningIdentityDir(&noopmsp{}, dir, nil); err == nil {
+		t.Fatalf("SetSigningIdentityDir should fail for the noop MSP")
+	}
+}
+
+func TestEncryptedSigningKey(t *testing.T) {
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		t.Fatalf("Failed generating MSP [%s]", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Encrypt the signing key on disk
+	pwd := []byte("passphrase")
+	keyFile := filepath.Join(dir, "keystore", "key.pem")
+	raw, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		t.Fatalf("Failed reading key [%s]", err)
+	}
+	block, _ := pem.Decode(raw)
+	encrypted, err := utils.EncryptPEMBlock(block.Type, block.Bytes, pwd)
+	if err != nil {
+		t.Fatalf("Failed encrypting key [%s]", err)
+	}
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(encrypted), 0600)
+
+	if _, err = GetLocalMspConfig(dir, nil); err == nil {
+		t.Fatalf("GetLocalMspConfig should fail without the passphrase of the key")
+	}
+	if _, err = GetLocalMspConfig(dir, []byte("wrong")); err == nil {
+		t.Fatalf("GetLocalMspConfig should fail with a wrong passphrase")
+	}
+
+	conf, err := GetLocalMspConfig(dir, pwd)
+	if err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+	thisMsp, err := NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Constructor for msp should have succeeded, got err %s", err)
+	}
+	if err = thisMsp.Setup(conf); err != nil {
+		t.Fatalf("Setup for msp should have succeeded, got err %s", err)
+	}
+	id, err := thisMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity should have succeeded, got err %s", err)
+	}
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign should have succeeded, got err %s", err)
+	}
+	if err = id.Verify(msg, sig); err != nil {
+		t.Fatalf("The signature should be valid, got err %s", err)
+	}
+
+	// Renewal decrypts the key with the same passphrase
+	if err = SetSigningIdentityDir(thisMsp, dir, nil); err != nil {
+		t.Fatalf("SetSigningIdentityDir should have succeeded, got err %s", err)
+	}
+	if err = id.Renew(); err == nil {
+		t.Fatalf("Renew should fail without the passphrase of the key")
+	}
+	if err = SetSigningIdentityDir(thisMsp, dir, pwd); err != nil {
+		t.Fatalf("SetSigningIdentityDir should have succeeded, got err %s", err)
+	}
+	if err = id.Renew(); err != nil {
+		t.Fatalf("Renew should have succeeded, got err %s", err)
+	}
+}
+
+func TestRotateSigningKeyPassphrase(t *testing.T) {
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		t.Fatalf("Failed generating MSP [%s]", err)
+	}
+	defer os.RemoveAll(dir)
+
+	clear, err := GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+
+	// Encrypt the key stored in clear
+	pwd := []byte("passphrase")
+	if err = RotateSigningKeyPassphrase(dir, nil, pwd); err != nil {
+		t.Fatalf("RotateSigningKeyPassphrase should have succeeded, got err %s", err)
+	}
+	if _, err = GetLocalMspConfig(dir, nil); err == nil {
+		t.Fatalf("GetLocalMspConfig should fail without the passphrase of the key")
+	}
+	conf, err := GetLocalMspConfig(dir, pwd)
+	if err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+	if !reflect.DeepEqual(conf, clear) {
+		t.Fatalf("The encrypted key should decrypt to the original one")
+	}
+
+	// Change the passphrase, a wrong one leaves the key untouched
+	newPwd := []byte("new passphrase")
+	if err = RotateSigningKeyPassphrase(dir, []byte("wrong"), newPwd); err == nil {
+		t.Fatalf("RotateSigningKeyPassphrase should fail with a wrong passphrase")
+	}
+	if _, err = GetLocalMspConfig(dir, pwd); err != nil {
+		t.Fatalf("A failed rotation should leave the key unchanged, got err %s", err)
+	}
+	if err = RotateSigningKeyPassphrase(dir, pwd, newPwd); err != nil {
+		t.Fatalf("RotateSigningKeyPassphrase should have succeeded, got err %s", err)
+	}
+	if _, err = GetLocalMspConfig(dir, pwd); err == nil {
+		t.Fatalf("GetLocalMspConfig should fail with the old passphrase")
+	}
+	if conf, err = GetLocalMspConfig(dir, newPwd); err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+	if !reflect.DeepEqual(conf, clear) {
+		t.Fatalf("The re-encrypted key should decrypt to the original one")
+	}
+
+	// Store the key in clear again
+	if err = RotateSigningKeyPassphrase(dir, newPwd, nil); err != nil {
+		t.Fatalf("RotateSigningKeyPassphrase should have succeeded, got err %s", err)
+	}
+	if conf, err = GetLocalMspConfig(dir, nil); err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded, got err %s", err)
+	}
+	if !reflect.DeepEqual(conf, clear) {
+		t.Fatalf("The decrypted key should be the original one")
+	}
+}
+
+func TestSigningKeyFromBCCSP(t *testing.T) {
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		t.Fatalf("Failed generating MSP [%s]", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Without the key in the BCCSP the signing identity cannot be set up
+	keystoreDir := filepath.Join(dir, "keystore")
+	raw, err := ioutil.ReadFile(filepath.Join(keystoreDir, "key.pem"))
+	if err != nil {
+		t.Fatalf("Failed reading key [%s]", err)
+	}
+	if err = os.RemoveAll(keystoreDir); err != nil {
+		t.Fatalf("Failed removing keystore [%s]", err)
+	}
+	conf, err := GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("GetLocalMspConfig should have succeeded without a keystore, got err %s", err)
+	}
+	thisMsp, err := NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Constructor for msp should have succeeded, got err %s", err)
+	}
+	if err = thisMsp.Setup(conf); err == nil {
+		t.Fatalf("Setup should fail when the BCCSP does not hold the signing key")
+	}
+
+	// Store the key in the default BCCSP, the MSP finds it by SKI
+	csp, err := factory.GetDefault()
+	if err != nil {
+		t.Fatalf("Failed getting the default BCCSP [%s]", err)
+	}
+	block, _ := pem.Decode(raw)
+	if _, err = csp.KeyImport(block.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: false}); err != nil {
+		t.Fatalf("Failed importing key [%s]", err)
+	}
+	if err = thisMsp.Setup(conf); err != nil {
+		t.Fatalf("Setup for msp should have succeeded, got err %s", err)
+	}
+	id, err := thisMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("GetDefaultSigningIdentity should have succeeded, got err %s", err)
+	}
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign should have succeeded, got err %s", err)
+	}
+	if err = id.Verify(msg, sig); err != nil {
+		t.Fatalf("The signature should be valid, got err %s", err)
+	}
+
+	// There is no key to rotate in the MSP directory
+	if err = RotateSigningKeyPassphrase(dir, nil, []byte("passphrase")); err != nil {
+		t.Fatalf("RotateSigningKeyPassphrase should have succeeded, got err %s", err)
+	}
+}
+
 func TestMain(m *testing.M) {
+	// Keep the keys stored in the default BCCSP out of os.TempDir()
+	keyStorePath, err := ioutil.TempDir("", "mspks")
+	if err != nil {
+		fmt.Printf("Failed creating temp dir [%s]\n", err)
+		os.Exit(-1)
+	}
+	if err = factory.InitDefault(&factory.InitOpts{KeyStorePath: keyStorePath}); err != nil {
+		fmt.Printf("Failed initializing the default BCCSP [%s]\n", err)
+		os.Exit(-1)
+	}
+
 	retVal := m.Run()
+	os.RemoveAll(keyStorePath)
 	os.Exit(retVal)
 }
@@ -27,6 +27,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/bccsp/signer"
 	"github.com/hyperledger/fabric/bccsp/sw"
 	m "github.com/hyperledger/fabric/protos/msp"
@@ -49,6 +50,12 @@ type bccspmsp struct {
 
 	// the provider identifier for this MSP
 	name string
+
+	// the directory the signing identity is renewed from, if any
+	signerDir string
+
+	// the passphrase of the signing key in signerDir, if encrypted
+	signerPwd []byte
 }
 
 // NewBccspMsp returns an MSP instance backed up by a BCCSP
@@ -112,16 +119,34 @@ func (msp *bccspmsp) getSigningIdentityFromConf(sidInfo *m.SigningIdentityInfo)
 		return nil, err
 	}
 
-	// Get secret key
-	pemKey, _ := pem.Decode(sidInfo.PrivateSigner.KeyMaterial)
-	key, err := msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
-	if err != nil {
-		return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import EC private key, err %s", err)
+	// Get secret key: without key material in the configuration, the key
+	// is held by the default BCCSP, an HSM for instance, under the SKI of
+	// the public key of the certificate
+	csp := msp.bccsp
+	var key bccsp.Key
+	if sidInfo.PrivateSigner == nil || len(sidInfo.PrivateSigner.KeyMaterial) == 0 {
+		csp, err = factory.GetDefault()
+		if err != nil {
+			return nil, fmt.Errorf("getIdentityFromBytes error: Failed getting the default BCCSP, err %s", err)
+		}
+		key, err = csp.GetKey(idPub.(*identity).pk.SKI())
+		if err != nil {
+			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to get the private key from the BCCSP, err %s", err)
+		}
+	} else {
+		pemKey, _ := pem.Decode(sidInfo.PrivateSigner.KeyMaterial)
+		if pemKey == nil {
+			return nil, fmt.Errorf("getIdentityFromBytes error: could not decode pem bytes of the private key")
+		}
+		key, err = msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+		if err != nil {
+			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import EC private key, err %s", err)
+		}
 	}
 
 	// get the peer signer
 	peerSigner := &signer.CryptoSigner{}
-	err = peerSigner.Init(msp.bccsp, key)
+	err = peerSigner.Init(csp, key)
 	if err != nil {
 		return nil, fmt.Errorf("getIdentityFromBytes error: Failed initializing CryptoSigner, err %s", err)
 	}
@@ -186,6 +211,78 @@ func (msp *bccspmsp) Setup(conf1 *m.MSPConfig) error {
 	return nil
 }
 
+// loadRenewedSigningIdentity loads the signing identity from the directory
+// this MSP was loaded from and checks that it can replace the current one
+func (msp *bccspmsp) loadRenewedSigningIdentity() (*signingidentity, error) {
+	if msp.signerDir == "" {
+		return nil, fmt.Errorf("Renew error: MSP %s was not loaded from a directory", msp.name)
+	}
+
+	sidInfo, err := getSigningIdentityInfoFromDir(msp.signerDir, msp.signerPwd)
+	if err != nil {
+		return nil, fmt.Errorf("Renew error: %s", err)
+	}
+
+	sid, err := msp.getSigningIdentityFromConf(sidInfo)
+	if err != nil {
+		return nil, fmt.Errorf("Renew error: %s", err)
+	}
+	renewed := sid.(*signingidentity)
+
+	err = msp.Validate(&renewed.identity)
+	if err != nil {
+		return nil, fmt.Errorf("Renew error: %s", err)
+	}
+
+	// make sure that the new key matches the new certificate
+	msg := []byte("renew")
+	sig, err := renewed.Sign(msg)
+	if err == nil {
+		err = renewed.Verify(msg, sig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Renew error: the key does not match the certificate, err %s", err)
+	}
+
+	return renewed, nil
+}
+
+// Roles of the certificates returned by GetCertificates
+const (
+	RootCertRole    = "root"
+	AdminCertRole   = "admin"
+	SigningCertRole = "signer"
+)
+
+// Certificate is a certificate an MSP is configured with
+type Certificate struct {
+	Role string
+	Cert *x509.Certificate
+}
+
+// GetCertificates returns the roots of trust, the admin certificates
+// and the default signing certificate of the supplied MSP; it returns
+// nil for MSPs that are not based on x.509 certificates
+func GetCertificates(m MSP) []Certificate {
+	msp, ok := m.(*bccspmsp)
+	if !ok {
+		return nil
+	}
+
+	var certs []Certificate
+	for _, id := range msp.trustedCerts {
+		certs = append(certs, Certificate{RootCertRole, id.(*identity).cert})
+	}
+	for _, id := range msp.admins {
+		certs = append(certs, Certificate{AdminCertRole, id.(*identity).cert})
+	}
+	if msp.signer != nil {
+		certs = append(certs, Certificate{SigningCertRole, msp.signer.(*signingidentity).public().cert})
+	}
+
+	return certs
+}
+
 // GetType returns the type for this MSP
 func (msp *bccspmsp) GetType() ProviderType {
 	return FABRIC
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testtools generates MSP material for tests, so that they do not
+// depend on the expiry of the certificates in msp/sampleconfig.
+package testtools
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// GenerateMSPDir writes to dir a local MSP laid out like msp/sampleconfig:
+// a new self-signed root certificate in cacerts, and in signcerts and
+// keystore a signing certificate it issued, with its key. The signing
+// certificate is also the admin certificate. Both certificates are valid
+// from notBefore to notAfter.
+func GenerateMSPDir(dir string, notBefore, notAfter time.Time) error {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("Could not generate the CA key: %s", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "testca", Organization: []string{"Hyperledger Fabric"}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caRaw, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("Could not create the CA certificate: %s", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("Could not generate the signing key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "testpeer", Organization: []string{"Hyperledger Fabric"}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("Could not create the signing certificate: %s", err)
+	}
+	keyRaw, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("Could not marshal the signing key: %s", err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw})
+	files := map[string][]byte{
+		filepath.Join("cacerts", "cacert.pem"):       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caRaw}),
+		filepath.Join("admincerts", "admincert.pem"): cert,
+		filepath.Join("signcerts", "peer.pem"):       cert,
+		filepath.Join("keystore", "key.pem"):         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyRaw}),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("Could not create directory %s: %s", filepath.Dir(path), err)
+		}
+		if err = ioutil.WriteFile(path, content, 0600); err != nil {
+			return fmt.Errorf("Could not write %s: %s", path, err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateTempMSPDir generates in a new temporary directory a local MSP whose
+// certificates are valid for a day around the current time, and returns
+// the directory. The caller removes it.
+func GenerateTempMSPDir() (string, error) {
+	dir, err := ioutil.TempDir("", "msp")
+	if err != nil {
+		return "", fmt.Errorf("Could not create a temporary directory: %s", err)
+	}
+
+	now := time.Now()
+	if err = GenerateMSPDir(dir, now.Add(-time.Hour), now.Add(24*time.Hour)); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
@@ -30,6 +30,8 @@ func (cbs *commonBootstrapper) makeGenesisConfigEnvelope() *cb.ConfigurationEnve
 		cbs.encodeAcceptAllPolicy(),
 		cbs.encodeIngressPolicy(),
 		cbs.encodeEgressPolicy(),
+		cbs.encodeBlockValidationPolicy(),
+		cbs.encodeMSP(),
 		cbs.lockDefaultModificationPolicy(),
 	)
 }
@@ -44,6 +46,8 @@ func (kbs *kafkaBootstrapper) makeGenesisConfigEnvelope() *cb.ConfigurationEnvel
 		kbs.encodeAcceptAllPolicy(),
 		kbs.encodeIngressPolicy(),
 		kbs.encodeEgressPolicy(),
+		kbs.encodeBlockValidationPolicy(),
+		kbs.encodeMSP(),
 		kbs.lockDefaultModificationPolicy(),
 	)
 }
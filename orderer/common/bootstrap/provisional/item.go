@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/orderer/common/sharedconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
+	msputils "github.com/hyperledger/fabric/protos/msp/utils"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -106,6 +107,28 @@ func (cbs *commonBootstrapper) lockDefaultModificationPolicy() *cb.SignedConfigu
 	return &cb.SignedConfigurationItem{ConfigurationItem: utils.MarshalOrPanic(configItem), Signatures: nil}
 }
 
+func (cbs *commonBootstrapper) encodeBlockValidationPolicy() *cb.SignedConfigurationItem {
+	// Blocks must be signed by the identity the orderer signs with
+	configItemKey := configtx.BlockValidationPolicyID
+	configItemValue := utils.MarshalOrPanic(utils.MakePolicyOrPanic(cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{cbs.ordererIdentity})))
+	modPolicy := configtx.DefaultModificationPolicyID
+
+	configItemChainHeader := utils.MakeChainHeader(cb.HeaderType_CONFIGURATION_ITEM, msgVersion, cbs.chainID, epoch)
+	configItem := utils.MakeConfigurationItem(configItemChainHeader, cb.ConfigurationItem_Policy, lastModified, modPolicy, configItemKey, configItemValue)
+	return &cb.SignedConfigurationItem{ConfigurationItem: utils.MarshalOrPanic(configItem), Signatures: nil}
+}
+
+func (cbs *commonBootstrapper) encodeMSP() *cb.SignedConfigurationItem {
+	// Peers deserialize the identity of the orderer with this MSP
+	configItemKey := msputils.MSPKey
+	configItemValue := utils.MarshalOrPanic(cbs.mspConfig)
+	modPolicy := configtx.DefaultModificationPolicyID
+
+	configItemChainHeader := utils.MakeChainHeader(cb.HeaderType_CONFIGURATION_ITEM, msgVersion, cbs.chainID, epoch)
+	configItem := utils.MakeConfigurationItem(configItemChainHeader, cb.ConfigurationItem_Chain, lastModified, modPolicy, configItemKey, configItemValue)
+	return &cb.SignedConfigurationItem{ConfigurationItem: utils.MarshalOrPanic(configItem), Signatures: nil}
+}
+
 func (kbs *kafkaBootstrapper) encodeKafkaBrokers() *cb.SignedConfigurationItem {
 	configItemKey := sharedconfig.KafkaBrokersKey
 	configItemValue := utils.MarshalOrPanic(&ab.KafkaBrokers{Brokers: kbs.kafkaBrokers})
@@ -17,11 +17,15 @@ limitations under the License.
 package provisional
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap"
 	"github.com/hyperledger/fabric/orderer/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -54,10 +58,12 @@ const (
 var DefaultChainCreators = []string{AcceptAllPolicyKey}
 
 type commonBootstrapper struct {
-	chainID       string
-	consensusType string
-	batchSize     *ab.BatchSize
-	batchTimeout  string
+	chainID         string
+	consensusType   string
+	batchSize       *ab.BatchSize
+	batchTimeout    string
+	mspConfig       *mspprotos.MSPConfig
+	ordererIdentity []byte
 }
 
 type soloBootstrapper struct {
@@ -79,6 +85,11 @@ func New(conf *config.TopLevel) bootstrap.Helper {
 		},
 		batchTimeout: conf.General.BatchTimeout.String(),
 	}
+	pwd, err := sw.LoadKeyStorePassphrase(conf.General.KeyStore.Passphrase, conf.General.KeyStore.PassphraseFile)
+	if err != nil {
+		panic(fmt.Errorf("Failed reading the keystore passphrase: %s", err))
+	}
+	cbs.mspConfig, cbs.ordererIdentity = loadLocalMSP(conf.General.LocalMSPDir, pwd)
 
 	switch conf.General.OrdererType {
 	case ConsensusTypeSolo, ConsensusTypeSbft:
@@ -95,6 +106,44 @@ func New(conf *config.TopLevel) bootstrap.Helper {
 	}
 }
 
+// loadLocalMSP reads the MSP the orderer signs blocks with from dir. It
+// returns the configuration of the MSP without its signing identity, so that
+// the private key stays out of the genesis block, and the serialized identity
+// of the orderer. The signing key is decrypted with pwd if it is encrypted.
+func loadLocalMSP(dir string, pwd []byte) (*mspprotos.MSPConfig, []byte) {
+	conf, err := msp.GetLocalMspConfig(dir, pwd)
+	if err != nil {
+		panic(fmt.Errorf("Failed to load the local MSP from %s: %s", dir, err))
+	}
+	localMSP, err := msp.NewBccspMsp()
+	if err != nil {
+		panic(fmt.Errorf("Failed to create the local MSP: %s", err))
+	}
+	if err = localMSP.Setup(conf); err != nil {
+		panic(fmt.Errorf("Failed to set up the local MSP: %s", err))
+	}
+	signer, err := localMSP.GetDefaultSigningIdentity()
+	if err != nil {
+		panic(fmt.Errorf("Failed to get the signing identity of the local MSP: %s", err))
+	}
+	identity, err := signer.Serialize()
+	if err != nil {
+		panic(fmt.Errorf("Failed to serialize the signing identity of the local MSP: %s", err))
+	}
+
+	fabricConf := &mspprotos.FabricMSPConfig{}
+	if err = json.Unmarshal(conf.Config, fabricConf); err != nil {
+		panic(fmt.Errorf("Failed to read the configuration of the local MSP: %s", err))
+	}
+	fabricConf.SigningIdentity = nil
+	publicConf, err := json.Marshal(fabricConf)
+	if err != nil {
+		panic(fmt.Errorf("Failed to encode the configuration of the local MSP: %s", err))
+	}
+
+	return &mspprotos.MSPConfig{Type: conf.Type, Config: publicConf}, identity
+}
+
 // GenesisBlock returns the genesis block to be used for bootstrapping.
 func (cbs *commonBootstrapper) GenesisBlock() *cb.Block {
 	return cbs.makeGenesisBlock(cbs.makeGenesisConfigEnvelope())
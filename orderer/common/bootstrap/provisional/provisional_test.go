@@ -18,10 +18,17 @@ package provisional
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	msputils "github.com/hyperledger/fabric/protos/msp/utils"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 var confSolo, confKafka *config.TopLevel
@@ -60,3 +67,46 @@ func TestGenesisMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestGenesisBlockValidationPolicy(t *testing.T) {
+	for _, tc := range testCases {
+		genesisBlock := New(tc).GenesisBlock()
+
+		mspConfigs, err := msputils.GetMSPManagerConfigFromBlock(genesisBlock)
+		if err != nil || len(mspConfigs) != 1 {
+			t.Fatalf("Case %s: Expected the local MSP in the genesis block, got %v, err %v", tc.General.OrdererType, mspConfigs, err)
+		}
+		fabricConf := &mspprotos.FabricMSPConfig{}
+		if err = json.Unmarshal(mspConfigs[0].Config, fabricConf); err != nil || fabricConf.SigningIdentity != nil {
+			t.Fatalf("Case %s: The signing identity of the orderer should not be in the genesis block, err %v", tc.General.OrdererType, err)
+		}
+		mspManager := msp.NewMSPManager()
+		if err = mspManager.Setup(mspConfigs); err != nil {
+			t.Fatalf("Case %s: Failed to set up the MSP of the genesis block: %s", tc.General.OrdererType, err)
+		}
+
+		_, ordererIdentity := loadLocalMSP(tc.General.LocalMSPDir, nil)
+		if _, err = mspManager.DeserializeIdentity(ordererIdentity); err != nil {
+			t.Fatalf("Case %s: The identity of the orderer should be valid for the MSP of the genesis block: %s", tc.General.OrdererType, err)
+		}
+
+		payload := utils.ExtractPayloadOrPanic(utils.ExtractEnvelopeOrPanic(genesisBlock, 0))
+		configEnvelope := utils.UnmarshalConfigurationEnvelopeOrPanic(payload.Data)
+		var policy *cb.Policy
+		for _, item := range configEnvelope.Items {
+			configItem := utils.UnmarshalConfigurationItemOrPanic(item.ConfigurationItem)
+			if configItem.Type == cb.ConfigurationItem_Policy && configItem.Key == configtx.BlockValidationPolicyID {
+				policy = &cb.Policy{}
+				if err = proto.Unmarshal(configItem.Value, policy); err != nil {
+					t.Fatalf("Case %s: Failed to unmarshal the block validation policy: %s", tc.General.OrdererType, err)
+				}
+			}
+		}
+		if policy == nil {
+			t.Fatalf("Case %s: Expected a block validation policy in the genesis block", tc.General.OrdererType)
+		}
+		if !bytes.Contains(policy.Policy, ordererIdentity) {
+			t.Fatalf("Case %s: Expected the block validation policy to require the signature of the orderer", tc.General.OrdererType)
+		}
+	}
+}
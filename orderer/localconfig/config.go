@@ -51,6 +51,31 @@ type General struct {
 	GenesisFile   string
 	Profile       Profile
 	LogLevel      string
+	LocalMSPDir   string
+	CertExpiry    CertExpiry
+	EnforceLowS   bool
+	KeyStore      KeyStore
+	BCCSP         BCCSP
+}
+
+// KeyStore contains configuration for the passphrase of the signing key of the local MSP
+type KeyStore struct {
+	Passphrase     string
+	PassphraseFile string
+}
+
+// BCCSP contains configuration for the crypto provider of the orderer
+type BCCSP struct {
+	Default string
+	PKCS11  PKCS11
+}
+
+// PKCS11 contains configuration for the token of the PKCS#11-based crypto provider
+type PKCS11 struct {
+	Library string
+	Label   string
+	Slot    uint
+	Pin     string
 }
 
 // BatchSize contains configuration affecting the size of batches
@@ -64,6 +89,12 @@ type Profile struct {
 	Address string
 }
 
+// CertExpiry contains configuration for the checks of the expiry of the certificates
+type CertExpiry struct {
+	CheckInterval time.Duration
+	WarnWithin    time.Duration
+}
+
 // RAMLedger contains config for the RAM ledger
 type RAMLedger struct {
 	HistorySize uint
@@ -119,7 +150,20 @@ var defaults = TopLevel{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
 		},
-		LogLevel: "INFO",
+		LogLevel:    "INFO",
+		LocalMSPDir: "../msp/sampleconfig/",
+		CertExpiry: CertExpiry{
+			CheckInterval: 24 * time.Hour,
+			WarnWithin:    30 * 24 * time.Hour,
+		},
+		EnforceLowS: false,
+		KeyStore: KeyStore{
+			Passphrase:     "",
+			PassphraseFile: "",
+		},
+		BCCSP: BCCSP{
+			Default: "SW",
+		},
 	},
 	RAMLedger: RAMLedger{
 		HistorySize: 10000,
@@ -178,6 +222,18 @@ func (c *TopLevel) completeInitialization() {
 		case c.General.Profile.Enabled && (c.General.Profile.Address == ""):
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", defaults.General.Profile.Address)
 			c.General.Profile.Address = defaults.General.Profile.Address
+		case c.General.LocalMSPDir == "":
+			logger.Infof("General.LocalMSPDir unset, setting to %s", defaults.General.LocalMSPDir)
+			c.General.LocalMSPDir = defaults.General.LocalMSPDir
+		case c.General.CertExpiry.CheckInterval == 0:
+			logger.Infof("General.CertExpiry.CheckInterval unset, setting to %s", defaults.General.CertExpiry.CheckInterval)
+			c.General.CertExpiry.CheckInterval = defaults.General.CertExpiry.CheckInterval
+		case c.General.CertExpiry.WarnWithin == 0:
+			logger.Infof("General.CertExpiry.WarnWithin unset, setting to %s", defaults.General.CertExpiry.WarnWithin)
+			c.General.CertExpiry.WarnWithin = defaults.General.CertExpiry.WarnWithin
+		case c.General.BCCSP.Default == "":
+			logger.Infof("General.BCCSP.Default unset, setting to %s", defaults.General.BCCSP.Default)
+			c.General.BCCSP.Default = defaults.General.BCCSP.Default
 		case c.FileLedger.Prefix == "":
 			logger.Infof("FileLedger.Prefix unset, setting to %s", defaults.FileLedger.Prefix)
 			c.FileLedger.Prefix = defaults.FileLedger.Prefix
@@ -241,5 +297,13 @@ func Load() *TopLevel {
 
 	uconf.completeInitialization()
 
+	// A relative local MSP directory is relative to the configuration file
+	if !filepath.IsAbs(uconf.General.LocalMSPDir) {
+		uconf.General.LocalMSPDir = filepath.Join(filepath.Dir(config.ConfigFileUsed()), uconf.General.LocalMSPDir)
+	}
+	if file := uconf.General.KeyStore.PassphraseFile; file != "" && !filepath.IsAbs(file) {
+		uconf.General.KeyStore.PassphraseFile = filepath.Join(filepath.Dir(config.ConfigFileUsed()), file)
+	}
+
 	return &uconf
 }
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -120,3 +121,29 @@ func TestEnvInnerVar(t *testing.T) {
 		t.Fatalf("Environmental override of inner config test 2 did not work")
 	}
 }
+
+// TestKeyStorePassphrase verifies that the keystore passphrase settings can
+// be given in the environment and that a relative passphrase file is
+// relative to the configuration file
+func TestKeyStorePassphrase(t *testing.T) {
+	envVar1 := "ORDERER_GENERAL_KEYSTORE_PASSPHRASE"
+	envVal1 := "passphrase"
+	envVar2 := "ORDERER_GENERAL_KEYSTORE_PASSPHRASEFILE"
+	envVal2 := "passphrase.txt"
+	os.Setenv(envVar1, envVal1)
+	os.Setenv(envVar2, envVal2)
+	defer os.Unsetenv(envVar1)
+	defer os.Unsetenv(envVar2)
+	config := Load()
+
+	if config == nil {
+		t.Fatalf("Could not load config")
+	}
+
+	if config.General.KeyStore.Passphrase != envVal1 {
+		t.Fatalf("Environmental override of the keystore passphrase did not work")
+	}
+	if file := config.General.KeyStore.PassphraseFile; file == envVal2 || filepath.Base(file) != envVal2 {
+		t.Fatalf("The passphrase file should be relative to the configuration file, got %s", config.General.KeyStore.PassphraseFile)
+	}
+}
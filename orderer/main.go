@@ -24,8 +24,17 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
-
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	bccsputils "github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric/common/certexpiry"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/provisional"
 	"github.com/hyperledger/fabric/orderer/kafka"
@@ -59,6 +68,58 @@ func main() {
 		}()
 	}
 
+	bccsputils.SetLowSOnly(conf.General.EnforceLowS)
+
+	// The signing key of the local MSP may be held by the crypto provider
+	err := factory.InitDefault(&factory.InitOpts{
+		ProviderName: conf.General.BCCSP.Default,
+		PKCS11: factory.PKCS11Opts{
+			Library: conf.General.BCCSP.PKCS11.Library,
+			Label:   conf.General.BCCSP.PKCS11.Label,
+			Slot:    conf.General.BCCSP.PKCS11.Slot,
+			Pin:     conf.General.BCCSP.PKCS11.Pin,
+		},
+	})
+	if err != nil {
+		logger.Fatalf("Failed initializing the %s crypto provider: %s", conf.General.BCCSP.Default, err)
+	}
+
+	// Load the local MSP, whose signing identity signs the blocks
+	pwd, err := sw.LoadKeyStorePassphrase(conf.General.KeyStore.Passphrase, conf.General.KeyStore.PassphraseFile)
+	if err != nil {
+		logger.Fatalf("Failed reading the keystore passphrase: %s", err)
+	}
+	if err = mspmgmt.LoadLocalMsp(conf.General.LocalMSPDir, pwd); err != nil {
+		logger.Fatalf("Failed initializing the local MSP from %s: %s", conf.General.LocalMSPDir, err)
+	}
+	if err := certexpiry.CheckSigningCert(mspmgmt.GetLocalMSP(), time.Now()); err != nil {
+		logger.Fatalf("%s", err)
+	}
+
+	// Warn ahead of the expiry of the local MSP certificates
+	expiryChecker := certexpiry.NewChecker(conf.General.CertExpiry.CheckInterval, conf.General.CertExpiry.WarnWithin,
+		certexpiry.MSPSource("local", mspmgmt.GetLocalMSP()))
+	expiryChecker.Publish("certExpiryDays")
+	expiryChecker.Start()
+
+	// SIGHUP renews the signing identity from the local MSP directory
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+			if err == nil {
+				err = signer.Renew()
+			}
+			if err != nil {
+				logger.Errorf("Failed renewing the local signing identity: %s", err)
+				continue
+			}
+			logger.Infof("Renewed the local signing identity")
+			expiryChecker.Check()
+		}
+	}()
+
 	grpcServer := grpc.NewServer()
 
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.General.ListenAddress, conf.General.ListenPort))
@@ -127,7 +188,7 @@ func main() {
 	consenters["solo"] = solo.New()
 	consenters["kafka"] = kafka.New(conf.Kafka.Version, conf.Kafka.Retry)
 
-	manager := multichain.NewManagerImpl(lf, consenters)
+	manager := multichain.NewManagerImpl(lf, consenters, localmsp.NewSigner())
 
 	server := NewServer(
 		manager,
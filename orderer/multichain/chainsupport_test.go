@@ -17,9 +17,12 @@ limitations under the License.
 package multichain
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	mockconfigtx "github.com/hyperledger/fabric/orderer/mocks/configtx"
 	"github.com/hyperledger/fabric/orderer/rawledger"
@@ -64,7 +67,7 @@ func (mc *mockCommitter) Commit() {
 func TestCommitConfig(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledger: ml, configManager: cm, signer: &xxxCryptoHelper{}}
+	cs := &chainSupport{ledger: ml, configManager: cm, signer: &mockSigner{}}
 	txs := []*cb.Envelope{makeNormalTx("foo", 0), makeNormalTx("bar", 1)}
 	committers := []filter.Committer{&mockCommitter{}, &mockCommitter{}}
 	block := cs.CreateNextBlock(txs)
@@ -89,7 +92,7 @@ func TestCommitConfig(t *testing.T) {
 func TestWriteBlockSignatures(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledger: ml, configManager: cm, signer: &xxxCryptoHelper{}}
+	cs := &chainSupport{ledger: ml, configManager: cm, signer: &mockSigner{}}
 
 	blockMetadata := func(block *cb.Block) *cb.Metadata {
 		metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
@@ -104,10 +107,52 @@ func TestWriteBlockSignatures(t *testing.T) {
 	}
 }
 
+type mockSigner struct{}
+
+func (ms *mockSigner) NewSignatureHeader() *cb.SignatureHeader {
+	return &cb.SignatureHeader{Creator: []byte("orderer"), Nonce: []byte("nonce")}
+}
+
+func (ms *mockSigner) Sign(message []byte) []byte {
+	return append([]byte("signed:"), message...)
+}
+
+func TestWriteBlockSignedBySigner(t *testing.T) {
+	ml := &mockLedgerReadWriter{}
+	cm := &mockconfigtx.Manager{}
+	cs := &chainSupport{ledger: ml, configManager: cm, signer: &mockSigner{}}
+
+	block := cs.WriteBlock(cb.NewBlock(0, nil), nil)
+
+	for _, index := range []cb.BlockMetadataIndex{cb.BlockMetadataIndex_SIGNATURES, cb.BlockMetadataIndex_LAST_CONFIGURATION} {
+		metadata, err := utils.GetMetadataFromBlock(block, index)
+		if err != nil {
+			t.Fatalf("Error getting metadata %d: %s", index, err)
+		}
+		if len(metadata.Signatures) != 1 {
+			t.Fatalf("Expected one signature in metadata %d, got %d", index, len(metadata.Signatures))
+		}
+		sig := metadata.Signatures[0]
+
+		shdr := &cb.SignatureHeader{}
+		if err = proto.Unmarshal(sig.SignatureHeader, shdr); err != nil {
+			t.Fatalf("Error unmarshaling signature header: %s", err)
+		}
+		if string(shdr.Creator) != "orderer" {
+			t.Fatalf("Signature header of metadata %d should come from the signer", index)
+		}
+
+		expected := (&mockSigner{}).Sign(util.ConcatenateBytes(metadata.Value, sig.SignatureHeader, block.Header.Bytes()))
+		if !bytes.Equal(sig.Signature, expected) {
+			t.Fatalf("Metadata %d should be signed by the signer over its value, signature header and block header", index)
+		}
+	}
+}
+
 func TestWriteLastConfiguration(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledger: ml, configManager: cm, signer: &xxxCryptoHelper{}}
+	cs := &chainSupport{ledger: ml, configManager: cm, signer: &mockSigner{}}
 
 	lastConfig := func(block *cb.Block) uint64 {
 		index, err := utils.GetLastConfigurationIndexFromBlock(block)
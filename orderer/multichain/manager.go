@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/policies"
+	mspmgmt "github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/orderer/common/sharedconfig"
 	"github.com/hyperledger/fabric/orderer/rawledger"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -33,23 +34,7 @@ import (
 
 var logger = logging.MustGetLogger("orderer/multichain")
 
-// XXX This crypto helper is a stand in until we have a real crypto handler
-// it considers all signatures to be valid
-type xxxCryptoHelper struct{}
-
-func (xxx xxxCryptoHelper) VerifySignature(sd *cb.SignedData) error {
-	return nil
-}
-
-func (xxx xxxCryptoHelper) NewSignatureHeader() *cb.SignatureHeader {
-	return &cb.SignatureHeader{}
-}
-
-func (xxx xxxCryptoHelper) Sign(message []byte) []byte {
-	return message
-}
-
-// Signer is a temporary stub interface which will be implemented by the local MSP
+// Signer signs blocks on behalf of the orderer, it is implemented by the local MSP
 type Signer interface {
 	// NewSignatureHeader creates a SignatureHeader with the correct signing identity and a valid nonce
 	NewSignatureHeader() *cb.SignatureHeader
@@ -74,6 +59,7 @@ type multiLedger struct {
 	consenters    map[string]Consenter
 	ledgerFactory rawledger.Factory
 	sysChain      *systemChain
+	signer        Signer
 }
 
 func getConfigTx(reader rawledger.Reader) *cb.Envelope {
@@ -90,12 +76,13 @@ func getConfigTx(reader rawledger.Reader) *cb.Envelope {
 	return utils.ExtractEnvelopeOrPanic(configBlock, 0)
 }
 
-// NewManagerImpl produces an instance of a Manager
-func NewManagerImpl(ledgerFactory rawledger.Factory, consenters map[string]Consenter) Manager {
+// NewManagerImpl produces an instance of a Manager, whose chains sign their blocks with signer
+func NewManagerImpl(ledgerFactory rawledger.Factory, consenters map[string]Consenter, signer Signer) Manager {
 	ml := &multiLedger{
 		chains:        make(map[string]*chainSupport),
 		ledgerFactory: ledgerFactory,
 		consenters:    consenters,
+		signer:        signer,
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -122,7 +109,7 @@ func NewManagerImpl(ledgerFactory rawledger.Factory, consenters map[string]Conse
 				backingLedger,
 				sharedConfigManager,
 				consenters,
-				&xxxCryptoHelper{})
+				signer)
 			ml.chains[string(chainID)] = chain
 			ml.sysChain = newSystemChain(chain)
 			// We delay starting this chain, as it might try to copy and replace the chains map via newChain before the map is fully built
@@ -135,7 +122,7 @@ func NewManagerImpl(ledgerFactory rawledger.Factory, consenters map[string]Conse
 				backingLedger,
 				sharedConfigManager,
 				consenters,
-				&xxxCryptoHelper{})
+				signer)
 			ml.chains[string(chainID)] = chain
 			chain.start()
 		}
@@ -166,7 +153,7 @@ func newConfigTxManagerAndHandlers(configEnvelope *cb.ConfigurationEnvelope) (co
 		case cb.Policy_UNKNOWN:
 			// Do not register a handler
 		case cb.Policy_SIGNATURE:
-			policyProviderMap[pType] = cauthdsl.NewPolicyProvider(xxxCryptoHelper{})
+			policyProviderMap[pType] = cauthdsl.NewPolicyProvider(cauthdsl.NewMSPCryptoHelper(mspmgmt.GetLocalMSP()))
 		case cb.Policy_MSP:
 			// Add hook for MSP Handler here
 		}
@@ -237,7 +224,7 @@ func (ml *multiLedger) newChain(configtx *cb.Envelope) {
 		newChains[key] = value
 	}
 
-	cs := newChainSupport(createStandardFilters(configManager, policyManager, sharedConfig), configManager, policyManager, backingLedger, sharedConfig, ml.consenters, &xxxCryptoHelper{})
+	cs := newChainSupport(createStandardFilters(configManager, policyManager, sharedConfig), configManager, policyManager, backingLedger, sharedConfig, ml.consenters, ml.signer)
 	chainID := configManager.ChainID()
 
 	logger.Debugf("Created and starting new chain %s", chainID)
@@ -17,10 +17,14 @@ limitations under the License.
 package multichain
 
 import (
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/testtools"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/provisional"
 	"github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/rawledger"
@@ -99,6 +103,54 @@ func TestGetConfigTxFailure(t *testing.T) {
 
 }
 
+func TestMSPCryptoHelper(t *testing.T) {
+	dir, err := testtools.GenerateTempMSPDir()
+	if err != nil {
+		t.Fatalf("Failed generating MSP: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	mspConf, err := msp.GetLocalMspConfig(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed loading MSP config: %s", err)
+	}
+	m, err := msp.NewBccspMsp()
+	if err != nil {
+		t.Fatalf("Failed creating MSP: %s", err)
+	}
+	if err = m.Setup(mspConf); err != nil {
+		t.Fatalf("Failed setting up MSP: %s", err)
+	}
+
+	signer, err := m.GetDefaultSigningIdentity()
+	if err != nil {
+		t.Fatalf("Failed getting signing identity: %s", err)
+	}
+	identity, err := signer.Serialize()
+	if err != nil {
+		t.Fatalf("Failed serializing identity: %s", err)
+	}
+	data := []byte("data")
+	signature, err := signer.Sign(data)
+	if err != nil {
+		t.Fatalf("Failed signing: %s", err)
+	}
+
+	policy, err := cauthdsl.NewPolicyProvider(cauthdsl.NewMSPCryptoHelper(m)).NewPolicy(utils.MarshalOrPanic(cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{identity})))
+	if err != nil {
+		t.Fatalf("Failed creating policy: %s", err)
+	}
+
+	if err = policy.Evaluate([]*cb.SignedData{{Data: data, Identity: identity, Signature: signature}}); err != nil {
+		t.Errorf("A valid signature should satisfy the policy: %s", err)
+	}
+	if err = policy.Evaluate([]*cb.SignedData{{Data: []byte("other"), Identity: identity, Signature: signature}}); err == nil {
+		t.Errorf("A signature over other data should not satisfy the policy")
+	}
+	if err = policy.Evaluate([]*cb.SignedData{{Data: data, Identity: identity, Signature: data}}); err == nil {
+		t.Errorf("An invalid signature should not satisfy the policy")
+	}
+}
+
 // This test essentially brings the entire system up and is ultimately what main.go will replicate
 func TestManagerImpl(t *testing.T) {
 	lf, rl := NewRAMLedgerAndFactory(10)
@@ -106,7 +158,7 @@ func TestManagerImpl(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.General.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters)
+	manager := NewManagerImpl(lf, consenters, &mockSigner{})
 
 	_, ok := manager.GetChain("Fake")
 	if ok {
@@ -152,7 +204,7 @@ func TestSignatureFilter(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.General.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters)
+	manager := NewManagerImpl(lf, consenters, &mockSigner{})
 
 	cs, ok := manager.GetChain(provisional.TestChainID)
 
@@ -190,7 +242,7 @@ func TestNewChain(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.General.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters)
+	manager := NewManagerImpl(lf, consenters, &mockSigner{})
 
 	oldGenesisTx := utils.ExtractEnvelopeOrPanic(genesisBlock, 0)
 	oldGenesisTxPayload := utils.ExtractPayloadOrPanic(oldGenesisTx)
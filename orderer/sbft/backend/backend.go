@@ -40,6 +40,7 @@ import (
 	"encoding/gob"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/orderer/rawledger"
 	"github.com/hyperledger/fabric/orderer/sbft/connection"
 	"github.com/hyperledger/fabric/orderer/sbft/persist"
@@ -381,7 +382,7 @@ func Sign(privateKey crypto.PrivateKey, data []byte) []byte {
 		if err != nil {
 			panic(err)
 		}
-		encsig, err = asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		encsig, err = asn1.Marshal(struct{ R, S *big.Int }{r, utils.ToLowS(&pvk.PublicKey, s)})
 	default:
 		panic("Unsupported private key type given.")
 	}
@@ -403,6 +404,9 @@ func CheckSig(publicKey crypto.PublicKey, data []byte, sig []byte) error {
 		if len(rest) != 0 {
 			return fmt.Errorf("invalid signature (problem with asn unmarshalling for ECDSA)")
 		}
+		if err := utils.CheckLowS(p, sig); err != nil {
+			return fmt.Errorf("invalid signature (%s)", err)
+		}
 		ok := ecdsa.Verify(p, hash[:], s.R, s.S)
 		if !ok {
 			return fmt.Errorf("invalid signature (problem with verification)")
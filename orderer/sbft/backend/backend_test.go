@@ -21,10 +21,12 @@ import (
 	"crypto/elliptic"
 	crand "crypto/rand"
 	"crypto/rsa"
+	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/provisional"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/rawledger/ramledger"
@@ -68,6 +70,41 @@ func TestSignAndVerifyEcdsa(t *testing.T) {
 	}
 }
 
+func TestCheckSigEcdsaLowS(t *testing.T) {
+	data := []byte{1, 1, 1, 1, 1}
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		panic("ECDSA failed to generate private key in test.")
+	}
+	publicKey := &privateKey.PublicKey
+
+	sig := Sign(privateKey, data)
+	r, s, err := utils.UnmarshalECDSASignature(sig)
+	if err != nil {
+		t.Fatalf("Failed unmarshalling signature: %s", err)
+	}
+	if !utils.IsLowS(publicKey, s) {
+		t.Fatal("Sign should have produced a low S.")
+	}
+	highSig, err := utils.MarshalECDSASignature(r, new(big.Int).Sub(publicKey.Curve.Params().N, s))
+	if err != nil {
+		t.Fatalf("Failed marshalling signature: %s", err)
+	}
+
+	if err = CheckSig(publicKey, data, highSig); err != nil {
+		t.Errorf("A high S signature should be valid unless low S is enforced: %s", err)
+	}
+
+	utils.SetLowSOnly(true)
+	defer utils.SetLowSOnly(false)
+	if err = CheckSig(publicKey, data, highSig); err == nil {
+		t.Error("A high S signature should be rejected when low S is enforced.")
+	}
+	if err = CheckSig(publicKey, data, sig); err != nil {
+		t.Errorf("Signature check failed: %s", err)
+	}
+}
+
 func TestLedgerReadWrite(t *testing.T) {
 	localConf := localconfig.Load()
 	localConf.General.OrdererType = provisional.ConsensusTypeSbft
@@ -22,6 +22,7 @@ import (
 	_ "net/http/pprof"
 	"os"
 
+	bccsputils "github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/provisional"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/rawledger/fileledger"
@@ -133,6 +134,7 @@ func serve(c flags) {
 
 	localConf := localconfig.Load()
 	localConf.General.OrdererType = provisional.ConsensusTypeSbft
+	bccsputils.SetLowSOnly(localConf.General.EnforceLowS)
 	genesisBlock := provisional.New(localConf).GenesisBlock()
 
 	flf := fileledger.New(c.dataDir)
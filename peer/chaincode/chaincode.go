@@ -44,6 +44,8 @@ func AddFlags(cmd *cobra.Command) {
 		fmt.Sprintf("Path to %s", chainFuncName))
 	flags.StringVarP(&chaincodeName, "name", "n", common.UndefinedParamValue,
 		fmt.Sprint("Name of the chaincode returned by the deploy transaction"))
+	flags.StringVarP(&chaincodeVersion, "version", "v", common.UndefinedParamValue,
+		fmt.Sprint("Version of the chaincode specified in install/instantiate/upgrade commands"))
 	flags.StringVarP(&chaincodeUsr, "username", "u", common.UndefinedParamValue,
 		fmt.Sprint("Username for chaincode operations when security is enabled"))
 	flags.StringVarP(&customIDGenAlg, "tid", "t", common.UndefinedParamValue,
@@ -56,8 +58,12 @@ func AddFlags(cmd *cobra.Command) {
 func Cmd(cf *ChaincodeCmdFactory) *cobra.Command {
 	AddFlags(chaincodeCmd)
 
-	chaincodeCmd.AddCommand(deployCmd(cf))
+	chaincodeCmd.AddCommand(installCmd(cf))
+	chaincodeCmd.AddCommand(packageCmd(cf))
+	chaincodeCmd.AddCommand(signpackageCmd(cf))
+	chaincodeCmd.AddCommand(instantiateCmd(cf))
 	chaincodeCmd.AddCommand(invokeCmd(cf))
+	chaincodeCmd.AddCommand(listCmd(cf))
 	chaincodeCmd.AddCommand(queryCmd(cf))
 	chaincodeCmd.AddCommand(upgradeCmd(cf))
 
@@ -70,6 +76,7 @@ var (
 	chaincodeCtorJSON       string
 	chaincodePath           string
 	chaincodeName           string
+	chaincodeVersion        string
 	chaincodeUsr            string
 	chaincodeQueryRaw       bool
 	chaincodeQueryHex       bool
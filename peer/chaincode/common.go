@@ -23,9 +23,9 @@ import (
 	"os"
 	"strings"
 
-	cutil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/peer/common"
@@ -91,7 +91,7 @@ func getChaincodeSpecification(cmd *cobra.Command) (*pb.ChaincodeSpec, error) {
 	chaincodeLang = strings.ToUpper(chaincodeLang)
 	spec = &pb.ChaincodeSpec{
 		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value[chaincodeLang]),
-		ChaincodeID: &pb.ChaincodeID{Path: chaincodePath, Name: chaincodeName},
+		ChaincodeID: &pb.ChaincodeID{Path: chaincodePath, Name: chaincodeName, Version: chaincodeVersion},
 		CtorMsg:     input,
 		Attributes:  attributes,
 	}
@@ -183,11 +183,17 @@ type ChaincodeCmdFactory struct {
 	BroadcastClient common.BroadcastClient
 }
 
-// InitCmdFactory init the ChaincodeCmdFactory with default clients
-func InitCmdFactory() (*ChaincodeCmdFactory, error) {
-	endorserClient, err := common.GetEndorserClient()
-	if err != nil {
-		return nil, fmt.Errorf("Error getting endorser client %s: %s", chainFuncName, err)
+// InitCmdFactory init the ChaincodeCmdFactory with default clients. The
+// endorser and broadcast clients are only created when the command talks to
+// the peer and to the orderer respectively
+func InitCmdFactory(isEndorserRequired, isOrdererRequired bool) (*ChaincodeCmdFactory, error) {
+	var err error
+	var endorserClient pb.EndorserClient
+	if isEndorserRequired {
+		endorserClient, err = common.GetEndorserClient()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting endorser client %s: %s", chainFuncName, err)
+		}
 	}
 
 	signer, err := common.GetDefaultSigner()
@@ -195,9 +201,12 @@ func InitCmdFactory() (*ChaincodeCmdFactory, error) {
 		return nil, fmt.Errorf("Error getting default signer: %s", err)
 	}
 
-	broadcastClient, err := common.GetBroadcastClient()
-	if err != nil {
-		return nil, fmt.Errorf("Error getting broadcast client: %s", err)
+	var broadcastClient common.BroadcastClient
+	if isOrdererRequired {
+		broadcastClient, err = common.GetBroadcastClient()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting broadcast client: %s", err)
+		}
 	}
 
 	return &ChaincodeCmdFactory{
@@ -228,15 +237,13 @@ func ChaincodeInvokeOrQuery(spec *pb.ChaincodeSpec, cID string, invoke bool, sig
 		return nil, fmt.Errorf("Error serializing identity for %s: %s", signer.GetIdentifier(), err)
 	}
 
-	uuid := cutil.GenerateUUID()
-
 	funcName := "invoke"
 	if !invoke {
 		funcName = "query"
 	}
 
 	var prop *pb.Proposal
-	prop, err = putils.CreateProposalFromCIS(uuid, cID, invocation, creator)
+	prop, _, err = putils.CreateProposalFromCIS(cID, invocation, creator)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating proposal  %s: %s", funcName, err)
 	}
@@ -266,6 +273,9 @@ func ChaincodeInvokeOrQuery(spec *pb.ChaincodeSpec, cID string, invoke bool, sig
 				return proposalResp, fmt.Errorf("Error sending transaction %s: %s", funcName, err)
 			}
 		}
+	} else if proposalResp != nil && proposalResp.Response != nil && proposalResp.Response.Status >= shim.ERRORTHRESHOLD {
+		// a rejected query is not endorsed, so report it instead of its empty payload
+		return proposalResp, fmt.Errorf("Error querying %s: status %d: %s", funcName, proposalResp.Response.Status, proposalResp.Response.Message)
 	}
 
 	return proposalResp, nil
@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+var chaincodeInstallCmd *cobra.Command
+
+// installCmd returns the cobra command for Chaincode Install
+func installCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodeInstallCmd = &cobra.Command{
+		Use:       "install [chaincode package file]",
+		Short:     fmt.Sprintf("Package the specified chaincode into a deployment spec and save it on the peer's path."),
+		Long:      fmt.Sprintf(`Package the specified chaincode into a deployment spec and save it on the peer's path. A package created with the "package" command, possibly signed by several owners, can be installed instead by giving its file.`),
+		ValidArgs: []string{"1"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return chaincodeInstall(cmd, args, cf)
+		},
+	}
+
+	return chaincodeInstallCmd
+}
+
+//checkInstallParams checks the parameters needed, in addition to the ones
+//common to all chaincode commands, to build the install package
+func checkInstallParams() error {
+	if chaincodeVersion == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply value for %s version parameter.\n", chainFuncName)
+	}
+
+	if chaincodePath == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply value for %s path parameter.\n", chainFuncName)
+	}
+
+	return nil
+}
+
+//getInstallPackage returns the package read from the file given as argument
+//or, if there is none, an unsigned package built from the command flags
+func getInstallPackage(cmd *cobra.Command, args []string) (*pb.SignedChaincodeDeploymentSpec, error) {
+	if len(args) > 0 {
+		return readChaincodePackage(args[0])
+	}
+
+	if err := checkInstallParams(); err != nil {
+		return nil, err
+	}
+
+	spec, err := getChaincodeSpecification(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cds, err := getChaincodeBytes(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
+	}
+
+	return ccpackage.OwnerCreateSignedCCDepSpec(cds, nil, nil)
+}
+
+//install the package on the peer via Endorser
+func install(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
+	pkg, err := getInstallPackage(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s\n", cf.Signer.GetIdentifier(), err)
+	}
+
+	prop, _, err := utils.CreateInstallProposalFromPackage(chainID, pkg, creator)
+	if err != nil {
+		return fmt.Errorf("Error creating proposal  %s: %s\n", chainFuncName, err)
+	}
+
+	var signedProp *pb.SignedProposal
+	signedProp, err = utils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal  %s: %s\n", chainFuncName, err)
+	}
+
+	proposalResponse, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error endorsing %s: %s\n", chainFuncName, err)
+	}
+
+	if proposalResponse == nil || proposalResponse.Response == nil || proposalResponse.Response.Status != 200 {
+		return fmt.Errorf("Error installing chaincode: %v", proposalResponse)
+	}
+
+	logger.Infof("Installed remotely %v", proposalResponse)
+
+	return nil
+}
+
+// chaincodeInstall installs the chaincode package on the peer. Nothing is
+// sent to the orderer, installation is local to the peer.
+func chaincodeInstall(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return install(cmd, args, cf)
+}
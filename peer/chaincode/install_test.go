@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func initInstallTest(t *testing.T, status int32) *ChaincodeCmdFactory {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: status},
+		Endorsement: &pb.Endorsement{},
+	}
+
+	return &ChaincodeCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+}
+
+func TestInstallCmd(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	cmd := installCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-n", "example02", "-p", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "-v", "1.0", "-c", "{\"Function\":\"init\",\"Args\": [\"param\",\"1\"]}"}
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Run chaincode install cmd error:%v", err)
+	}
+}
+
+func TestInstallCmdWithoutVersion(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	cmd := installCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-n", "example02", "-p", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "-v", "", "-c", "{\"Function\":\"init\",\"Args\": [\"param\",\"1\"]}"}
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("Expected error running install without a version")
+	}
+}
+
+func TestInstallCmdEndorseFail(t *testing.T) {
+	mockCF := initInstallTest(t, 500)
+
+	cmd := installCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-n", "example02", "-p", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "-v", "1.0", "-c", "{\"Function\":\"init\",\"Args\": [\"param\",\"1\"]}"}
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("Expected error when the peer fails to install")
+	}
+}
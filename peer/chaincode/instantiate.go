@@ -21,50 +21,47 @@ import (
 
 	"golang.org/x/net/context"
 
-	"github.com/hyperledger/fabric/common/util"
 	protcommon "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/cobra"
 )
 
-var chaincodeDeployCmd *cobra.Command
+var chaincodeInstantiateCmd *cobra.Command
 
-// deployCmd returns the cobra command for Chaincode Deploy
-func deployCmd(cf *ChaincodeCmdFactory) *cobra.Command {
-	chaincodeDeployCmd = &cobra.Command{
-		Use:       "deploy",
-		Short:     fmt.Sprintf("Deploy the specified chaincode to the network."),
-		Long:      fmt.Sprintf(`Deploy the specified chaincode to the network.`),
+// instantiateCmd returns the cobra command for Chaincode Instantiate
+func instantiateCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodeInstantiateCmd = &cobra.Command{
+		Use:       "instantiate",
+		Aliases:   []string{"deploy"},
+		Short:     fmt.Sprintf("Instantiate the specified chaincode on the chain."),
+		Long:      fmt.Sprintf(`Instantiate the specified chaincode on the chain. The chaincode must have been installed on the endorsing peers with the same name and version.`),
 		ValidArgs: []string{"1"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return chaincodeDeploy(cmd, args, cf)
 		},
 	}
 
-	return chaincodeDeployCmd
+	return chaincodeInstantiateCmd
 }
 
-//deploy the command via Endorser
-func deploy(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope, error) {
+//instantiate the command via Endorser
+func instantiate(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope, error) {
 	spec, err := getChaincodeSpecification(cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	cds, err := getChaincodeBytes(spec)
-	if err != nil {
-		return nil, fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
-	}
+	//the code is taken from the package installed on the peer, only the
+	//name, version and init arguments are sent
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec}
 
 	creator, err := cf.Signer.Serialize()
 	if err != nil {
 		return nil, fmt.Errorf("Error serializing identity for %s: %s\n", cf.Signer.GetIdentifier(), err)
 	}
 
-	uuid := util.GenerateUUID()
-
-	prop, err := utils.CreateDeployProposalFromCDS(uuid, chainID, cds, creator)
+	prop, _, err := utils.CreateDeployProposalFromCDS(chainID, cds, creator)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating proposal  %s: %s\n", chainFuncName, err)
 	}
@@ -93,19 +90,19 @@ func deploy(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope,
 	return nil, nil
 }
 
-// chaincodeDeploy deploys the chaincode. On success, the chaincode name
+// chaincodeDeploy instantiates the chaincode. On success, the chaincode name
 // (hash) is printed to STDOUT for use by subsequent chaincode-related CLI
 // commands.
 func chaincodeDeploy(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
 	var err error
 	if cf == nil {
-		cf, err = InitCmdFactory()
+		cf, err = InitCmdFactory(true, true)
 		if err != nil {
 			return err
 		}
 	}
 	defer cf.BroadcastClient.Close()
-	env, err := deploy(cmd, cf)
+	env, err := instantiate(cmd, cf)
 	if err != nil {
 		return err
 	}
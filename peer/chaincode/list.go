@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+var chaincodeListCmd *cobra.Command
+
+// list command flags
+var (
+	getInstalledChaincodes    bool
+	getInstantiatedChaincodes bool
+)
+
+// listCmd returns the cobra command for Chaincode List
+func listCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodeListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Get the instantiated chaincodes on a channel or installed chaincodes on a peer.",
+		Long:  "Get the chaincodes instantiated on the chain given with -C (--instantiated), or the chaincodes installed on the peer (--installed).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return getChaincodes(cmd, cf)
+		},
+	}
+
+	flags := chaincodeListCmd.Flags()
+	flags.BoolVarP(&getInstalledChaincodes, "installed", "", false,
+		"Get the installed chaincodes on a peer")
+	flags.BoolVarP(&getInstantiatedChaincodes, "instantiated", "", false,
+		"Get the instantiated chaincodes on a channel")
+
+	return chaincodeListCmd
+}
+
+// getChaincodes asks LCCC for the instantiated or installed chaincodes and
+// prints them
+func getChaincodes(cmd *cobra.Command, cf *ChaincodeCmdFactory) error {
+	if getInstalledChaincodes == getInstantiatedChaincodes {
+		return fmt.Errorf("Must explicitly specify one of \"--installed\" or \"--instantiated\"")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+
+	var prop *pb.Proposal
+	if getInstalledChaincodes {
+		prop, _, err = utils.CreateGetInstalledChaincodesProposal(chainID, creator)
+	} else {
+		prop, _, err = utils.CreateGetChaincodesProposal(chainID, creator)
+	}
+	if err != nil {
+		return fmt.Errorf("Error creating proposal %s: %s", chainFuncName, err)
+	}
+
+	signedProp, err := utils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal %s: %s", chainFuncName, err)
+	}
+
+	proposalResponse, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error endorsing %s: %s", chainFuncName, err)
+	}
+
+	if proposalResponse == nil || proposalResponse.Response == nil {
+		return fmt.Errorf("Error endorsing %s: empty proposal response", chainFuncName)
+	}
+
+	if proposalResponse.Response.Status != 200 {
+		return fmt.Errorf("Proposal response was not successful, error code %d, msg %s", proposalResponse.Response.Status, proposalResponse.Response.Message)
+	}
+
+	cqr := &pb.ChaincodeQueryResponse{}
+	if err = proto.Unmarshal(proposalResponse.Response.Payload, cqr); err != nil {
+		return fmt.Errorf("Cannot read chaincodes list response, %s", err)
+	}
+
+	if getInstalledChaincodes {
+		fmt.Println("Get installed chaincodes on peer:")
+	} else {
+		fmt.Printf("Get instantiated chaincodes on channel %s:\n", chainID)
+	}
+	for _, cc := range cqr.Chaincodes {
+		fmt.Printf("Name: %s, Version: %s, Path: %s, Id: %x, Escc: %s, Vscc: %s\n", cc.Name, cc.Version, cc.Path, cc.Id, cc.Escc, cc.Vscc)
+	}
+
+	return nil
+}
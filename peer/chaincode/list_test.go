@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func initListTest(t *testing.T) *ChaincodeCmdFactory {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	cqr := &pb.ChaincodeQueryResponse{Chaincodes: []*pb.ChaincodeInfo{
+		{Name: "mycc1", Version: "1.0", Path: "codePath1", Id: []byte{1, 2, 3}, Escc: "escc", Vscc: "vscc"},
+		{Name: "mycc2", Version: "1.0", Path: "codePath2", Id: []byte{4, 5, 6}, Escc: "escc", Vscc: "vscc"},
+	}}
+	payload, err := proto.Marshal(cqr)
+	if err != nil {
+		t.Fatalf("Marshal ChaincodeQueryResponse error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200, Payload: payload},
+		Endorsement: &pb.Endorsement{},
+	}
+
+	return &ChaincodeCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+}
+
+func TestListCmd(t *testing.T) {
+	mockCF := initListTest(t)
+
+	for _, flag := range []string{"--installed", "--instantiated"} {
+		cmd := listCmd(mockCF)
+		AddFlags(cmd)
+		cmd.SetArgs([]string{flag})
+
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("Run chaincode list %s error:%v", flag, err)
+		}
+	}
+}
+
+func TestListCmdFlags(t *testing.T) {
+	mockCF := initListTest(t)
+
+	for _, args := range [][]string{{}, {"--installed", "--instantiated"}} {
+		cmd := listCmd(mockCF)
+		AddFlags(cmd)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("Expected error running list with %v", args)
+		}
+	}
+}
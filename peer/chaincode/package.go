@@ -0,0 +1,167 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	"github.com/hyperledger/fabric/peer/common"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+)
+
+var chaincodePackageCmd *cobra.Command
+
+// package command flags
+var (
+	signPackage             bool
+	instantiationPolicyFile string
+)
+
+// packageCmd returns the cobra command for Chaincode Package
+func packageCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodePackageCmd = &cobra.Command{
+		Use:   "package <output file>",
+		Short: fmt.Sprintf("Package the specified chaincode into a deployment spec."),
+		Long: fmt.Sprintf(`Package the specified chaincode into a deployment spec along with its instantiation policy and write it to a file.
+The package can be signed by its owners with "signpackage" and installed with "install".`),
+		ValidArgs: []string{"1"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return chaincodePackage(cmd, args, cf)
+		},
+	}
+
+	flags := chaincodePackageCmd.Flags()
+	flags.BoolVarP(&signPackage, "sign", "S", false,
+		fmt.Sprint("Sign the package with the local MSP"))
+	flags.StringVarP(&instantiationPolicyFile, "instantiate-policy", "i", common.UndefinedParamValue,
+		fmt.Sprint("File holding the marshaled SignaturePolicyEnvelope allowed to instantiate the chaincode, by default only the creator of the package"))
+
+	return chaincodePackageCmd
+}
+
+//getInstantiationPolicy reads the instantiation policy from the file given
+//with -i, or builds the default one requiring the signer of the command
+func getInstantiationPolicy(cf *ChaincodeCmdFactory) (*pcommon.SignaturePolicyEnvelope, error) {
+	if instantiationPolicyFile != common.UndefinedParamValue {
+		b, err := ioutil.ReadFile(instantiationPolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading instantiation policy %s: %s", instantiationPolicyFile, err)
+		}
+
+		policy := &pcommon.SignaturePolicyEnvelope{}
+		if err = proto.Unmarshal(b, policy); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling instantiation policy %s: %s", instantiationPolicyFile, err)
+		}
+
+		return policy, nil
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+
+	return cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{creator}), nil
+}
+
+//readChaincodePackage reads a package written by the package command
+func readChaincodePackage(file string) (*pb.SignedChaincodeDeploymentSpec, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading chaincode package %s: %s", file, err)
+	}
+
+	pkg := &pb.SignedChaincodeDeploymentSpec{}
+	if err = proto.Unmarshal(b, pkg); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling chaincode package %s: %s", file, err)
+	}
+
+	return pkg, nil
+}
+
+//writeChaincodePackage writes the package to the file
+func writeChaincodePackage(file string, pkg *pb.SignedChaincodeDeploymentSpec) error {
+	b, err := proto.Marshal(pkg)
+	if err != nil {
+		return fmt.Errorf("Error marshaling chaincode package: %s", err)
+	}
+
+	if err = ioutil.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("Error writing chaincode package %s: %s", file, err)
+	}
+
+	return nil
+}
+
+// chaincodePackage creates the chaincode package and writes it to the file
+// given as argument
+func chaincodePackage(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Output file not specified or invalid number of args (filename should be the only arg)")
+	}
+
+	if err := checkInstallParams(); err != nil {
+		return err
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(false, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	spec, err := getChaincodeSpecification(cmd)
+	if err != nil {
+		return err
+	}
+
+	cds, err := getChaincodeBytes(spec)
+	if err != nil {
+		return fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
+	}
+
+	policy, err := getInstantiationPolicy(cf)
+	if err != nil {
+		return err
+	}
+
+	var owner = cf.Signer
+	if !signPackage {
+		owner = nil
+	}
+
+	pkg, err := ccpackage.OwnerCreateSignedCCDepSpec(cds, policy, owner)
+	if err != nil {
+		return fmt.Errorf("Error creating chaincode package: %s", err)
+	}
+
+	if err = writeChaincodePackage(args[0], pkg); err != nil {
+		return err
+	}
+
+	logger.Infof("Wrote chaincode package to %s", args[0])
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newPackageTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "packagetest")
+	if err != nil {
+		t.Fatalf("Create temp dir error: %v", err)
+	}
+
+	return dir
+}
+
+func createTestPackage(t *testing.T, mockCF *ChaincodeCmdFactory, file string, sign bool) {
+	cmd := packageCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-n", "example02", "-p", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "-v", "1.0", "-c", "{\"Function\":\"init\",\"Args\": [\"param\",\"1\"]}", file}
+	if sign {
+		args = append(args, "-S")
+	}
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Run chaincode package cmd error:%v", err)
+	}
+}
+
+func TestPackageCmd(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	dir := newPackageTestDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "example02.pak")
+	createTestPackage(t, mockCF, file, true)
+
+	pkg, err := readChaincodePackage(file)
+	if err != nil {
+		t.Fatalf("Read chaincode package error:%v", err)
+	}
+
+	if len(pkg.OwnerEndorsements) != 1 {
+		t.Fatalf("Expected 1 owner endorsement, got %d", len(pkg.OwnerEndorsements))
+	}
+
+	if len(pkg.InstantiationPolicy) == 0 {
+		t.Fatalf("Expected default instantiation policy")
+	}
+}
+
+func TestPackageCmdWithoutFile(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	cmd := packageCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-n", "example02", "-p", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "-v", "1.0", "-c", "{\"Function\":\"init\",\"Args\": [\"param\",\"1\"]}"}
+	cmd.SetArgs(args)
+
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("Expected error running package without an output file")
+	}
+}
+
+func TestSignPackageCmd(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	dir := newPackageTestDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "example02.pak")
+	createTestPackage(t, mockCF, file, false)
+
+	signed := filepath.Join(dir, "example02.signed.pak")
+	cmd := signpackageCmd(mockCF)
+	cmd.SetArgs([]string{file, signed})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Run chaincode signpackage cmd error:%v", err)
+	}
+
+	pkg, err := readChaincodePackage(signed)
+	if err != nil {
+		t.Fatalf("Read chaincode package error:%v", err)
+	}
+
+	if len(pkg.OwnerEndorsements) != 1 {
+		t.Fatalf("Expected 1 owner endorsement, got %d", len(pkg.OwnerEndorsements))
+	}
+
+	//the same owner cannot sign the package twice
+	cmd = signpackageCmd(mockCF)
+	cmd.SetArgs([]string{signed, filepath.Join(dir, "twice.pak")})
+	if err = cmd.Execute(); err == nil {
+		t.Errorf("Expected error signing a package twice with the same owner")
+	}
+}
+
+func TestInstallCmdFromPackage(t *testing.T) {
+	mockCF := initInstallTest(t, 200)
+
+	dir := newPackageTestDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "example02.pak")
+	createTestPackage(t, mockCF, file, true)
+
+	cmd := installCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{file})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Run chaincode install from package error:%v", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/common/ccpackage"
+	"github.com/spf13/cobra"
+)
+
+var chaincodeSignPackageCmd *cobra.Command
+
+// signpackageCmd returns the cobra command for Chaincode Sign Package
+func signpackageCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodeSignPackageCmd = &cobra.Command{
+		Use:       "signpackage <input package> <output package>",
+		Short:     fmt.Sprintf("Sign the specified chaincode package."),
+		Long:      fmt.Sprintf(`Add the signature of the local MSP to a package created by the "package" command, so that several owners can endorse the same package.`),
+		ValidArgs: []string{"2"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return signpackage(cmd, args, cf)
+		},
+	}
+
+	return chaincodeSignPackageCmd
+}
+
+// signpackage adds the signature of the local signer to the package
+func signpackage(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
+	if len(args) != 2 {
+		return fmt.Errorf("Peer signpackage requires an input package and an output package")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(false, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	pkg, err := readChaincodePackage(args[0])
+	if err != nil {
+		return err
+	}
+
+	pkg, err = ccpackage.SignExistingPackage(pkg, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error signing chaincode package: %s", err)
+	}
+
+	if err = writeChaincodePackage(args[1], pkg); err != nil {
+		return err
+	}
+
+	logger.Infof("Wrote signed chaincode package to %s", args[1])
+
+	return nil
+}
@@ -21,7 +21,6 @@ import (
 
 	"golang.org/x/net/context"
 
-	"github.com/hyperledger/fabric/common/util"
 	protcommon "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -35,7 +34,7 @@ func upgradeCmd(cf *ChaincodeCmdFactory) *cobra.Command {
 	chaincodeUpgradeCmd = &cobra.Command{
 		Use:       "upgrade",
 		Short:     fmt.Sprintf("Upgrade chaincode."),
-		Long:      fmt.Sprintf(`Upgrade an existing chaincode with the specified one. The new chaincode will immediately replace the existing chaincode upon the transaction committed.`),
+		Long:      fmt.Sprintf(`Upgrade an existing chaincode with the specified one. The new version must have been installed on the endorsing peers and will immediately replace the existing chaincode upon the transaction committed.`),
 		ValidArgs: []string{"1"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return chaincodeUpgrade(cmd, args, cf)
@@ -52,19 +51,15 @@ func upgrade(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope,
 		return nil, err
 	}
 
-	cds, err := getChaincodeBytes(spec)
-	if err != nil {
-		return nil, fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
-	}
+	//the new version must have been installed on the peer
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec}
 
 	creator, err := cf.Signer.Serialize()
 	if err != nil {
 		return nil, fmt.Errorf("Error serializing identity for %s: %s\n", cf.Signer.GetIdentifier(), err)
 	}
 
-	uuid := util.GenerateUUID()
-
-	prop, err := utils.CreateUpgradeProposalFromCDS(uuid, chainID, cds, creator)
+	prop, _, err := utils.CreateUpgradeProposalFromCDS(chainID, cds, creator)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating proposal %s: %s\n", chainFuncName, err)
 	}
@@ -100,7 +95,7 @@ func upgrade(cmd *cobra.Command, cf *ChaincodeCmdFactory) (*protcommon.Envelope,
 func chaincodeUpgrade(cmd *cobra.Command, args []string, cf *ChaincodeCmdFactory) error {
 	var err error
 	if cf == nil {
-		cf, err = InitCmdFactory()
+		cf, err = InitCmdFactory(true, true)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+const channelFuncName = "channel"
+
+var logger = logging.MustGetLogger("channelCmd")
+
+// Channel-related variables.
+var (
+	chainID          string
+	orderingEndpoint string
+)
+
+// AddFlags adds the flags shared by all channel commands
+func AddFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	flags.StringVarP(&chainID, "chainID", "C", common.UndefinedParamValue,
+		"The chain on which this command should be executed")
+	flags.StringVarP(&orderingEndpoint, "orderer", "o", common.UndefinedParamValue,
+		"Ordering service endpoint, defaults to peer.committer.ledger.orderer")
+}
+
+// Cmd returns the cobra command for Channel
+func Cmd(cf *ChannelCmdFactory) *cobra.Command {
+	AddFlags(channelCmd)
+
+	channelCmd.AddCommand(createCmd(cf))
+	channelCmd.AddCommand(joinCmd(cf))
+	channelCmd.AddCommand(listCmd(cf))
+	channelCmd.AddCommand(fetchCmd(cf))
+	channelCmd.AddCommand(snapshotCmd(cf))
+
+	return channelCmd
+}
+
+var channelCmd = &cobra.Command{
+	Use:   channelFuncName,
+	Short: fmt.Sprintf("%s specific commands.", channelFuncName),
+	Long:  fmt.Sprintf("%s specific commands.", channelFuncName),
+}
+
+// ChannelCmdFactory holds the clients used by ChannelCmd
+type ChannelCmdFactory struct {
+	EndorserClient  pb.EndorserClient
+	Signer          msp.SigningIdentity
+	BroadcastClient common.BroadcastClient
+	DeliverClient   common.DeliverClient
+}
+
+// InitCmdFactory init the ChannelCmdFactory with the clients required by a
+// channel command. Commands which only talk to the peer don't need the
+// ordering service to be reachable and vice versa
+func InitCmdFactory(isEndorserRequired, isOrdererRequired bool) (*ChannelCmdFactory, error) {
+	var err error
+
+	cf := &ChannelCmdFactory{}
+
+	cf.Signer, err = common.GetDefaultSigner()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting default signer: %s", err)
+	}
+
+	if isEndorserRequired {
+		cf.EndorserClient, err = common.GetEndorserClient()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting endorser client %s: %s", channelFuncName, err)
+		}
+	}
+
+	if isOrdererRequired {
+		orderer := orderingEndpoint
+		if orderer == common.UndefinedParamValue {
+			if orderer, err = common.GetOrdererEndpoint(); err != nil {
+				return nil, err
+			}
+		}
+
+		cf.BroadcastClient, err = common.NewBroadcastClient(orderer)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting broadcast client: %s", err)
+		}
+
+		cf.DeliverClient, err = common.NewDeliverClient(orderer, cf.Signer)
+		if err != nil {
+			cf.BroadcastClient.Close()
+			return nil, fmt.Errorf("Error getting deliver client: %s", err)
+		}
+	}
+
+	return cf, nil
+}
+
+// close releases the orderer connections held by the factory
+func (cf *ChannelCmdFactory) close() {
+	if cf.BroadcastClient != nil {
+		cf.BroadcastClient.Close()
+	}
+	if cf.DeliverClient != nil {
+		cf.DeliverClient.Close()
+	}
+}
+
+// executeCSCCProposal sends a proposal invoking the configuration system
+// chaincode with the given arguments to the peer and returns its response
+func executeCSCCProposal(cf *ChannelCmdFactory, args [][]byte) (*pb.ProposalResponse, error) {
+	spec := &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
+		ChaincodeID: &pb.ChaincodeID{Name: "cscc"},
+		CtorMsg:     &pb.ChaincodeInput{Args: args},
+	}
+
+	// Build the ChaincodeInvocationSpec message
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+
+	prop, _, err := putils.CreateProposalFromCIS("", invocation, creator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating proposal for %s: %s", channelFuncName, err)
+	}
+
+	signedProp, err := putils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating signed proposal %s: %s", channelFuncName, err)
+	}
+
+	proposalResp, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return nil, fmt.Errorf("Error endorsing %s: %s", channelFuncName, err)
+	}
+
+	if proposalResp == nil || proposalResp.Response == nil {
+		return nil, fmt.Errorf("Error endorsing %s: empty proposal response", channelFuncName)
+	}
+
+	if proposalResp.Response.Status != 200 {
+		return nil, fmt.Errorf("Proposal response was not successful, error code %d, msg %s", proposalResp.Response.Status, proposalResp.Response.Message)
+	}
+
+	return proposalResp, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+// defaultCreationPolicy is the chain creation policy every ordering service
+// bootstrapped with the provisional bootstrapper accepts
+const defaultCreationPolicy = "AcceptAllPolicy"
+
+// create-related variables.
+var (
+	templateBlockPath string
+	creationPolicy    string
+	systemChainID     string
+	createTimeout     time.Duration
+)
+
+func createCmd(cf *ChannelCmdFactory) *cobra.Command {
+	channelCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a chain.",
+		Long: `Create a chain on the ordering service and write its genesis block to <chainID>.block.
+The configuration of the new chain is derived from the configuration block given
+with --file, or from the current configuration of the ordering system chain.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return create(cmd, args, cf)
+		},
+	}
+
+	flags := channelCreateCmd.Flags()
+	flags.StringVarP(&templateBlockPath, "file", "f", common.UndefinedParamValue,
+		"Path to a configuration block used as template for the configuration of the new chain")
+	flags.StringVarP(&creationPolicy, "policy", "p", defaultCreationPolicy,
+		"Chain creation policy the request is validated against")
+	flags.StringVarP(&systemChainID, "systemchain", "s", util.GetTestChainID(),
+		"ID of the ordering system chain the template configuration is fetched from")
+	flags.DurationVarP(&createTimeout, "timeout", "t", 5*time.Second,
+		"How long to wait for the ordering service to create the chain")
+
+	return channelCreateCmd
+}
+
+// getTemplate returns the configuration the new chain's configuration is
+// derived from
+func getTemplate(cf *ChannelCmdFactory) (*cb.ConfigurationEnvelope, error) {
+	var block *cb.Block
+	var err error
+
+	if templateBlockPath != common.UndefinedParamValue {
+		b, err := ioutil.ReadFile(templateBlockPath)
+		if err != nil {
+			return nil, err
+		}
+		if block, err = utils.GetBlockFromBlockBytes(b); err != nil {
+			return nil, fmt.Errorf("Failed to read the template block, %s", err)
+		}
+	} else if block, err = getConfigBlock(cf.DeliverClient, systemChainID); err != nil {
+		return nil, fmt.Errorf("Failed to fetch the configuration of chain %s, %s", systemChainID, err)
+	}
+
+	envelope, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := utils.ExtractPayload(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Header == nil || payload.Header.ChainHeader == nil || payload.Header.ChainHeader.Type != int32(cb.HeaderType_CONFIGURATION_TRANSACTION) {
+		return nil, fmt.Errorf("Template block %d is not a configuration block", block.Header.Number)
+	}
+
+	return utils.UnmarshalConfigurationEnvelope(payload.Data)
+}
+
+// sendCreateChainTransaction submits the signed configuration transaction of
+// the new chain to the ordering service
+func sendCreateChainTransaction(cf *ChannelCmdFactory) error {
+	template, err := getTemplate(cf)
+	if err != nil {
+		return err
+	}
+
+	configEnv := utils.ChainCreationConfiguration(creationPolicy, chainID, template)
+
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_CONFIGURATION_TRANSACTION, chainID, cf.Signer, configEnv)
+	if err != nil {
+		return err
+	}
+
+	return cf.BroadcastClient.Send(env)
+}
+
+// getGenesisBlock waits for the ordering service to create the chain and
+// returns its genesis block
+func getGenesisBlock(cf *ChannelCmdFactory) (*cb.Block, error) {
+	deadline := time.Now().Add(createTimeout)
+	for {
+		block, err := cf.DeliverClient.GetBlock(chainID, seekSpecified(0))
+		if err == nil {
+			return block, nil
+		}
+
+		statusErr, ok := err.(*common.DeliverStatusError)
+		if !ok || statusErr.Status != cb.Status_NOT_FOUND || time.Now().After(deadline) {
+			return nil, fmt.Errorf("Failed to get the genesis block of chain %s, %s", chainID, err)
+		}
+
+		logger.Debugf("Chain %s not created yet, retrying", chainID)
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func executeCreate(cf *ChannelCmdFactory) error {
+	if err := sendCreateChainTransaction(cf); err != nil {
+		return err
+	}
+
+	block, err := getGenesisBlock(cf)
+	if err != nil {
+		return err
+	}
+
+	b, err := proto.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	file := chainID + ".block"
+	if err = ioutil.WriteFile(file, b, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Genesis block of chain %s written to %s\n", chainID, file)
+
+	return nil
+}
+
+func create(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	if chainID == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply chain ID.")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(false, true)
+		if err != nil {
+			return err
+		}
+	}
+	defer cf.close()
+
+	return executeCreate(cf)
+}
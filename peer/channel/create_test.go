@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+var once sync.Once
+
+// InitMSP init MSP
+func InitMSP() {
+	once.Do(initMSP)
+}
+
+func initMSP() {
+	// TODO: determine the location of this config file
+	var alternativeCfgPath = os.Getenv("PEER_CFG_PATH")
+	var mspMgrConfigDir string
+	if alternativeCfgPath != "" {
+		mspMgrConfigDir = alternativeCfgPath + "/msp/sampleconfig/"
+	} else if _, err := os.Stat("./msp/sampleconfig/"); err == nil {
+		mspMgrConfigDir = "./msp/sampleconfig/"
+	} else {
+		mspMgrConfigDir = os.Getenv("GOPATH") + "/src/github.com/hyperledger/fabric/msp/sampleconfig/"
+	}
+
+	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
+	if err != nil {
+		panic(fmt.Errorf("Fatal error when reading MSP config file %s: err %s\n", mspMgrConfigDir, err))
+	}
+}
+
+func writeTemplateBlock(t *testing.T, dir string) string {
+	block, err := utils.MakeConfigurationBlock("templatechain")
+	if err != nil {
+		t.Fatalf("Failed to make the template block: %s", err)
+	}
+	path := filepath.Join(dir, "template.block")
+	if err = ioutil.WriteFile(path, utils.MarshalOrPanic(block), 0644); err != nil {
+		t.Fatalf("Failed to write the template block: %s", err)
+	}
+	return path
+}
+
+func TestCreateChain(t *testing.T) {
+	InitMSP()
+
+	dir, err := ioutil.TempDir("", "channelcreate")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	genesisBlock := cb.NewBlock(0, nil)
+	mockCF := &ChannelCmdFactory{
+		Signer:          signer,
+		BroadcastClient: common.GetMockBroadcastClient(nil),
+		DeliverClient:   common.GetMockDeliverClient("mychain", []*cb.Block{genesisBlock}),
+	}
+
+	cmd := createCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-C", "mychain", "-f", writeTemplateBlock(t, dir)}
+	cmd.SetArgs(args)
+
+	if err = cmd.Execute(); err != nil {
+		t.Fatalf("Run channel create cmd error: %v", err)
+	}
+	defer os.Remove("mychain.block")
+
+	b, err := ioutil.ReadFile("mychain.block")
+	if err != nil {
+		t.Fatalf("Genesis block not written: %s", err)
+	}
+	block, err := utils.GetBlockFromBlockBytes(b)
+	if err != nil {
+		t.Fatalf("Written genesis block cannot be read: %s", err)
+	}
+	if !proto.Equal(genesisBlock, block) {
+		t.Fatalf("Written genesis block does not match the one returned by the orderer")
+	}
+}
+
+func TestCreateChainSendTXFail(t *testing.T) {
+	InitMSP()
+
+	dir, err := ioutil.TempDir("", "channelcreate")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	sendErr := fmt.Errorf("send create tx failed")
+	mockCF := &ChannelCmdFactory{
+		Signer:          signer,
+		BroadcastClient: common.GetMockBroadcastClient(sendErr),
+		DeliverClient:   common.GetMockDeliverClient("mychain", nil),
+	}
+
+	cmd := createCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-C", "mychain", "-f", writeTemplateBlock(t, dir)}
+	cmd.SetArgs(args)
+
+	if err = cmd.Execute(); err == nil || err.Error() != sendErr.Error() {
+		t.Fatalf("Run channel create cmd should have failed with %s, got %v", sendErr, err)
+	}
+}
+
+func TestCreateChainTimeout(t *testing.T) {
+	InitMSP()
+
+	dir, err := ioutil.TempDir("", "channelcreate")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockCF := &ChannelCmdFactory{
+		Signer:          signer,
+		BroadcastClient: common.GetMockBroadcastClient(nil),
+		DeliverClient:   common.GetMockDeliverClient("mychain", nil),
+	}
+
+	cmd := createCmd(mockCF)
+	AddFlags(cmd)
+
+	args := []string{"-C", "mychain", "-f", writeTemplateBlock(t, dir), "-t", "100ms"}
+	cmd.SetArgs(args)
+
+	if err = cmd.Execute(); err == nil {
+		t.Fatalf("Run channel create cmd should have failed when the chain is never created")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+)
+
+// Positions a block can be fetched at besides a block number
+const (
+	fetchOldest = "oldest"
+	fetchNewest = "newest"
+	fetchConfig = "config"
+)
+
+func fetchCmd(cf *ChannelCmdFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "fetch <oldest|newest|config|(number)> [outputfile]",
+		Short: "Fetch a block from the ordering service.",
+		Long: `Fetch a block of a chain from the ordering service and write it to a file.
+The block is either the oldest (genesis) or newest block, the block holding the
+latest configuration of the chain, or the block with the given number.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fetch(cmd, args, cf)
+		},
+	}
+}
+
+// seekSpecified returns the seek position of the block with the given number
+func seekSpecified(number uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}}
+}
+
+// getNewestBlock returns the latest block of the chain
+func getNewestBlock(dc common.DeliverClient, cid string) (*cb.Block, error) {
+	return dc.GetBlock(cid, &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}})
+}
+
+// getConfigBlock returns the block holding the latest configuration of the
+// chain, as referenced by the metadata of its newest block
+func getConfigBlock(dc common.DeliverClient, cid string) (*cb.Block, error) {
+	block, err := getNewestBlock(dc, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	lc, err := utils.GetLastConfigurationIndexFromBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Header.Number == lc {
+		return block, nil
+	}
+
+	return dc.GetBlock(cid, seekSpecified(lc))
+}
+
+// getBlockAt returns the block found at the position named on the command
+// line
+func getBlockAt(dc common.DeliverClient, cid string, position string) (*cb.Block, error) {
+	switch position {
+	case fetchOldest:
+		return dc.GetBlock(cid, &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+	case fetchNewest:
+		return getNewestBlock(dc, cid)
+	case fetchConfig:
+		return getConfigBlock(dc, cid)
+	}
+
+	number, err := strconv.ParseUint(position, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Block position must be %s, %s, %s or a block number, got %s", fetchOldest, fetchNewest, fetchConfig, position)
+	}
+
+	return dc.GetBlock(cid, seekSpecified(number))
+}
+
+func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("Fetch requires the position of the block and optionally an output file")
+	}
+
+	if chainID == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply chain ID.")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(false, true)
+		if err != nil {
+			return err
+		}
+	}
+	defer cf.close()
+
+	block, err := getBlockAt(cf.DeliverClient, chainID, args[0])
+	if err != nil {
+		return err
+	}
+
+	file := chainID + "_" + args[0] + ".block"
+	if len(args) == 2 {
+		file = args[1]
+	}
+
+	b, err := proto.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(file, b, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Block %d written to %s\n", block.Header.Number, file)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func makeBlock(number uint64, lastConfig uint64) *cb.Block {
+	block := cb.NewBlock(number, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIGURATION] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: utils.MarshalOrPanic(&cb.LastConfiguration{Index: lastConfig}),
+	})
+	return block
+}
+
+func TestGetBlockAt(t *testing.T) {
+	blocks := []*cb.Block{makeBlock(0, 0), makeBlock(1, 1), makeBlock(2, 1)}
+	dc := common.GetMockDeliverClient("mychain", blocks)
+
+	for position, expected := range map[string]uint64{
+		fetchOldest: 0,
+		fetchNewest: 2,
+		fetchConfig: 1,
+		"2":         2,
+	} {
+		block, err := getBlockAt(dc, "mychain", position)
+		if err != nil {
+			t.Fatalf("Fetching %s failed: %s", position, err)
+		}
+		if block.Header.Number != expected {
+			t.Fatalf("Fetching %s returned block %d, expected %d", position, block.Header.Number, expected)
+		}
+	}
+
+	if _, err := getBlockAt(dc, "mychain", "latest"); err == nil {
+		t.Fatalf("Fetching an unknown position should have failed")
+	}
+
+	if _, err := getBlockAt(dc, "otherchain", fetchNewest); err == nil {
+		t.Fatalf("Fetching from an unknown chain should have failed")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+// join-related variables.
+var (
+	genesisBlockPath string
+	snapshotPath     string
+	snapshotHash     string
+)
+
+func joinCmd(cf *ChannelCmdFactory) *cobra.Command {
+	channelJoinCmd := &cobra.Command{
+		Use:   "join",
+		Short: "Joins the peer to a chain.",
+		Long: `Joins the peer to a chain, either from its genesis block or from a
+snapshot of the ledger of a peer which has already joined it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return join(cmd, args, cf)
+		},
+	}
+
+	flags := channelJoinCmd.Flags()
+	flags.StringVarP(&genesisBlockPath, "blockpath", "b", common.UndefinedParamValue,
+		"Path to file containing genesis block")
+	flags.StringVarP(&snapshotPath, "snapshot", "s", common.UndefinedParamValue,
+		"Path to file containing a ledger snapshot, in place of the genesis block. The file must be in the snapshots directory of the peer, a relative path is taken from it")
+	flags.StringVarP(&snapshotHash, "snapshothash", "", common.UndefinedParamValue,
+		"Hash of the ledger snapshot, as printed by 'peer channel snapshot'")
+
+	return channelJoinCmd
+}
+
+func executeJoin(cf *ChannelCmdFactory) error {
+	if snapshotPath != common.UndefinedParamValue {
+		if genesisBlockPath != common.UndefinedParamValue {
+			return fmt.Errorf("Must supply either a genesis block file or a snapshot file, not both.")
+		}
+		return executeJoinFromSnapshot(cf)
+	}
+
+	if genesisBlockPath == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply genesis block file.")
+	}
+
+	gb, err := ioutil.ReadFile(genesisBlockPath)
+	if err != nil {
+		return err
+	}
+
+	proposalResp, err := executeCSCCProposal(cf, [][]byte{[]byte("JoinChain"), gb})
+	if err != nil {
+		return fmt.Errorf("Error on join: %s", err)
+	}
+
+	fmt.Printf("Join Result: %s\n", string(proposalResp.Response.Payload))
+
+	return nil
+}
+
+// executeJoinFromSnapshot asks the peer to join the chain from the snapshot
+// in snapshotPath. The snapshot is read by the peer from its own file system
+// so that it is not limited by the size of a gRPC message
+func executeJoinFromSnapshot(cf *ChannelCmdFactory) error {
+	if snapshotHash == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply the hash of the snapshot.")
+	}
+	hash, err := hex.DecodeString(snapshotHash)
+	if err != nil {
+		return fmt.Errorf("Invalid snapshot hash: %s", err)
+	}
+
+	proposalResp, err := executeCSCCProposal(cf, [][]byte{[]byte("JoinChainFromSnapshot"), []byte(snapshotPath), hash})
+	if err != nil {
+		return fmt.Errorf("Error on join: %s", err)
+	}
+
+	fmt.Printf("Join Result: %s\n", string(proposalResp.Response.Payload))
+
+	return nil
+}
+
+func join(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return executeJoin(cf)
+}
+
+// JoinFromBlock joins the peer to the chain of the genesis block in the file
+// blockPath. It backs the deprecated "peer node join" command.
+func JoinFromBlock(cf *ChannelCmdFactory, blockPath string) error {
+	genesisBlockPath = blockPath
+	snapshotPath = common.UndefinedParamValue
+
+	return join(nil, nil, cf)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channel
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestJoinFromBlock(t *testing.T) {
+	InitMSP()
+
+	dir, err := ioutil.TempDir("", "join")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{Response: &pb.Response{Status: 200, Payload: []byte("OK")}}
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+
+	if err = JoinFromBlock(mockCF, writeTemplateBlock(t, dir)); err != nil {
+		t.Fatalf("Join from block error: %s", err)
+	}
+
+	if err = JoinFromBlock(mockCF, common.UndefinedParamValue); err == nil {
+		t.Fatalf("Join should have failed without a genesis block file")
+	}
+}
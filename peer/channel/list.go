@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+)
+
+func listCmd(cf *ChannelCmdFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the chains this peer has joined.",
+		Long:  `List the chains this peer has joined.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list(cmd, args, cf)
+		},
+	}
+}
+
+// getChannels asks the configuration system chaincode of the peer for the
+// chains it has joined
+func getChannels(cf *ChannelCmdFactory) ([]*pb.ChannelInfo, error) {
+	proposalResp, err := executeCSCCProposal(cf, [][]byte{[]byte("GetChannels")})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting channels: %s", err)
+	}
+
+	var channelQueryResponse pb.ChannelQueryResponse
+	if err = proto.Unmarshal(proposalResp.Response.Payload, &channelQueryResponse); err != nil {
+		return nil, fmt.Errorf("Cannot read channels list response, %s", err)
+	}
+
+	return channelQueryResponse.Channels, nil
+}
+
+func list(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	channels, err := getChannels(cf)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Channels peers has joined to:")
+	for _, channel := range channels {
+		fmt.Println(channel.ChannelID)
+	}
+
+	return nil
+}
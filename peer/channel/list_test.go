@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestGetChannels(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	queryResponse := &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelID: "chain1"}, {ChannelID: "chain2"}}}
+	mockResponse := &pb.ProposalResponse{
+		Response: &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(queryResponse)},
+	}
+
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+
+	channels, err := getChannels(mockCF)
+	if err != nil {
+		t.Fatalf("Get channels error: %v", err)
+	}
+
+	if len(channels) != 2 || channels[0].ChannelID != "chain1" || channels[1].ChannelID != "chain2" {
+		t.Fatalf("Unexpected channels returned: %v", channels)
+	}
+}
+
+func TestGetChannelsEndorseFail(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "list error"}}
+
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+
+	if _, err = getChannels(mockCF); err == nil {
+		t.Fatalf("Get channels should have failed on an unsuccessful response")
+	}
+}
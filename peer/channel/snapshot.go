@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
+	"github.com/spf13/cobra"
+)
+
+func snapshotCmd(cf *ChannelCmdFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export a snapshot of the ledger of a chain.",
+		Long: `Export a snapshot of the ledger of a chain on the peer. The peer writes the
+snapshot to its file system and the command prints its path and hash. Another
+peer can join the chain from a copy of the snapshot with
+'peer channel join --snapshot <file> --snapshothash <hash>' without replaying
+the chain from its genesis block.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportSnapshotCmd(cmd, args, cf)
+		},
+	}
+}
+
+// exportSnapshot asks the configuration system chaincode of the peer to
+// write a snapshot of the ledger of the chain
+func exportSnapshot(cf *ChannelCmdFactory, cid string) (*snapshot.SnapshotInfo, error) {
+	proposalResp, err := executeCSCCProposal(cf, [][]byte{[]byte("ExportSnapshot"), []byte(cid)})
+	if err != nil {
+		return nil, fmt.Errorf("Error exporting snapshot: %s", err)
+	}
+
+	info := &snapshot.SnapshotInfo{}
+	if err = proto.Unmarshal(proposalResp.Response.Payload, info); err != nil {
+		return nil, fmt.Errorf("Error reading snapshot info: %s", err)
+	}
+	return info, nil
+}
+
+func exportSnapshotCmd(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	if len(args) > 0 {
+		return fmt.Errorf("Snapshot takes no arguments")
+	}
+
+	if chainID == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply chain ID.")
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(true, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	info, err := exportSnapshot(cf, chainID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot of %s at block %d written by the peer to %s\n", chainID, info.BlockNumber, info.Path)
+	fmt.Printf("Snapshot hash: %s\n", hex.EncodeToString(info.Hash))
+
+	return nil
+}
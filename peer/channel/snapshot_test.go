@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package channel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/hyperledger/fabric/protos/ledger/snapshot"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestExportSnapshot(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockResponse := &pb.ProposalResponse{
+		Response: &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(&snapshot.SnapshotInfo{
+			Path: "/var/hyperledger/production/ledgersData/snapshots/chain1_3.snapshot", BlockNumber: 3, Hash: []byte("hash")})},
+	}
+
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(mockResponse, nil),
+		Signer:         signer,
+	}
+
+	info, err := exportSnapshot(mockCF, "chain1")
+	if err != nil {
+		t.Fatalf("Export snapshot error: %v", err)
+	}
+
+	if info.BlockNumber != 3 || !bytes.Equal(info.Hash, []byte("hash")) {
+		t.Fatalf("Unexpected snapshot info returned: %v", info)
+	}
+
+	mockResponse = &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: "snapshot error"}}
+	mockCF.EndorserClient = common.GetMockEndorserClient(mockResponse, nil)
+
+	if _, err = exportSnapshot(mockCF, "chain1"); err == nil {
+		t.Fatalf("Export snapshot should have failed on an unsuccessful response")
+	}
+}
+
+func TestJoinWithBlockAndSnapshot(t *testing.T) {
+	genesisBlockPath, snapshotPath = "genesis.block", "chain1.snapshot"
+	defer func() {
+		genesisBlockPath, snapshotPath = common.UndefinedParamValue, common.UndefinedParamValue
+	}()
+
+	if err := executeJoin(&ChannelCmdFactory{}); err == nil {
+		t.Fatalf("Join should have failed when given both a genesis block and a snapshot")
+	}
+}
+
+func TestJoinWithSnapshotHash(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(&pb.ProposalResponse{
+			Response: &pb.Response{Status: 200, Payload: []byte("200")}}, nil),
+		Signer: signer,
+	}
+
+	snapshotPath = "chain1_3.snapshot"
+	defer func() {
+		snapshotPath, snapshotHash = common.UndefinedParamValue, common.UndefinedParamValue
+	}()
+
+	if err = executeJoin(mockCF); err == nil {
+		t.Fatalf("Join should have failed without the hash of the snapshot")
+	}
+
+	snapshotHash = "nothex"
+	if err = executeJoin(mockCF); err == nil {
+		t.Fatalf("Join should have failed with an invalid hash")
+	}
+
+	snapshotHash = "0a0b"
+	if err = executeJoin(mockCF); err != nil {
+		t.Fatalf("Join from snapshot error: %v", err)
+	}
+}
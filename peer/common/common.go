@@ -21,6 +21,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/errors"
 	"github.com/hyperledger/fabric/core/peer"
@@ -59,15 +62,56 @@ func InitConfig(cmdRoot string) error {
 	return nil
 }
 
-//InitCrypto initializes crypto for this peer
-func InitCrypto(mspMgrConfigDir string) error {
+// GetLocalMSPConfigDir returns the directory the local MSP is loaded from
+func GetLocalMSPConfigDir() string {
+	// TODO: determine the location of this config file
+	if alternativeCfgPath := os.Getenv("PEER_CFG_PATH"); alternativeCfgPath != "" {
+		return alternativeCfgPath + "/msp/sampleconfig/"
+	} else if _, err := os.Stat("./msp/sampleconfig/"); err == nil {
+		return "./msp/sampleconfig/"
+	}
+	return os.Getenv("GOPATH") + "/src/github.com/hyperledger/fabric/msp/sampleconfig/"
+}
+
+// InitCrypto initializes crypto for this peer. The default BCCSP is the
+// provider selected in security.bccsp; it keeps its software keys in the
+// keystore at security.keyStore.path only when useKeyStore is set, that is
+// for the commands running or maintaining the node; client commands use a
+// temporary keystore as they may not access the one of the node
+func InitCrypto(mspMgrConfigDir string, useKeyStore bool) error {
 	// FIXME: when this peer joins a chain, it should get the
 	// config for that chain with the list of MSPs that the
 	// chain uses; however this is not yet implemented.
 	// Additionally, we might always want to have an MSP for
 	// the local test chain so that we can run tests with the
 	// peer CLI. This is why we create this fake setup here for now
-	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	utils.SetLowSOnly(viper.GetBool("security.enforceLowS"))
+
+	// The keystore passphrase also decrypts the signing key of the local MSP
+	pwd, err := sw.LoadKeyStorePassphrase(viper.GetString("security.keyStore.passphrase"),
+		viper.GetString("security.keyStore.passphraseFile"))
+	if err != nil {
+		return fmt.Errorf("Fatal error when reading the keystore passphrase: err %s\n", err)
+	}
+
+	bccspOpts := &factory.InitOpts{
+		ProviderName: viper.GetString("security.bccsp.default"),
+		PKCS11: factory.PKCS11Opts{
+			Library: viper.GetString("security.bccsp.pkcs11.library"),
+			Label:   viper.GetString("security.bccsp.pkcs11.label"),
+			Slot:    uint(viper.GetInt("security.bccsp.pkcs11.slot")),
+			Pin:     viper.GetString("security.bccsp.pkcs11.pin"),
+		},
+	}
+	if useKeyStore {
+		bccspOpts.KeyStorePath = viper.GetString("security.keyStore.path")
+		bccspOpts.KeyStorePassphrase = pwd
+	}
+	if err = factory.InitDefault(bccspOpts); err != nil {
+		return fmt.Errorf("Fatal error when initializing the BCCSP: err %s\n", err)
+	}
+
+	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, pwd)
 	if err != nil {
 		return fmt.Errorf("Fatal error when setting up MSP from directory %s: err %s\n", mspMgrConfigDir, err)
 	}
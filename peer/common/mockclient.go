@@ -17,7 +17,10 @@ limitations under the License.
 package common
 
 import (
+	"fmt"
+
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	context "golang.org/x/net/context"
 	grpc "google.golang.org/grpc"
@@ -56,3 +59,38 @@ func (m *mockBroadcastClient) Send(env *cb.Envelope) error {
 func (m *mockBroadcastClient) Close() error {
 	return nil
 }
+
+// GetMockDeliverClient return a deliver client which serves the given blocks
+// of a single chain, the block number being the index in blocks
+func GetMockDeliverClient(chainID string, blocks []*cb.Block) DeliverClient {
+	return &mockDeliverClient{chainID: chainID, blocks: blocks}
+}
+
+type mockDeliverClient struct {
+	chainID string
+	blocks  []*cb.Block
+}
+
+func (m *mockDeliverClient) GetBlock(chainID string, position *ab.SeekPosition) (*cb.Block, error) {
+	if chainID != m.chainID || len(m.blocks) == 0 {
+		return nil, &DeliverStatusError{Status: cb.Status_NOT_FOUND}
+	}
+
+	switch t := position.Type.(type) {
+	case *ab.SeekPosition_Oldest:
+		return m.blocks[0], nil
+	case *ab.SeekPosition_Newest:
+		return m.blocks[len(m.blocks)-1], nil
+	case *ab.SeekPosition_Specified:
+		if t.Specified.Number >= uint64(len(m.blocks)) {
+			return nil, &DeliverStatusError{Status: cb.Status_NOT_FOUND}
+		}
+		return m.blocks[t.Specified.Number], nil
+	default:
+		return nil, fmt.Errorf("Unknown seek position type %T", t)
+	}
+}
+
+func (m *mockDeliverClient) Close() error {
+	return nil
+}
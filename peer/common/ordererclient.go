@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hyperledger/fabric/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -38,17 +40,22 @@ type broadcastClient struct {
 	client ab.AtomicBroadcast_BroadcastClient
 }
 
-// GetBroadcastClient creates a simple instance of the BroadcastClient interface
-func GetBroadcastClient() (BroadcastClient, error) {
+// GetOrdererEndpoint returns the address of the orderer this peer is
+// configured to talk to
+func GetOrdererEndpoint() (string, error) {
 	var orderer string
 	if viper.GetBool("peer.committer.enabled") {
 		orderer = viper.GetString("peer.committer.ledger.orderer")
 	}
 
 	if orderer == "" {
-		return nil, fmt.Errorf("Can't get orderer address")
+		return "", fmt.Errorf("Can't get orderer address")
 	}
 
+	return orderer, nil
+}
+
+func newOrdererConn(orderer string) (*grpc.ClientConn, error) {
 	var opts []grpc.DialOption
 	opts = append(opts, grpc.WithInsecure())
 	opts = append(opts, grpc.WithTimeout(3*time.Second))
@@ -58,6 +65,26 @@ func GetBroadcastClient() (BroadcastClient, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error connecting to %s due to %s", orderer, err)
 	}
+
+	return conn, nil
+}
+
+// GetBroadcastClient creates a simple instance of the BroadcastClient interface
+func GetBroadcastClient() (BroadcastClient, error) {
+	orderer, err := GetOrdererEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBroadcastClient(orderer)
+}
+
+// NewBroadcastClient creates a BroadcastClient connected to the given orderer
+func NewBroadcastClient(orderer string) (BroadcastClient, error) {
+	conn, err := newOrdererConn(orderer)
+	if err != nil {
+		return nil, err
+	}
 	client, err := ab.NewAtomicBroadcastClient(conn).Broadcast(context.TODO())
 	if err != nil {
 		conn.Close()
@@ -92,3 +119,81 @@ func (s *broadcastClient) Send(env *cb.Envelope) error {
 func (s *broadcastClient) Close() error {
 	return s.conn.Close()
 }
+
+// DeliverClient retrieves blocks from the ordering service
+type DeliverClient interface {
+	// GetBlock retrieves the block found at the given seek position on the
+	// chain. A missing chain is reported with the NOT_FOUND status
+	GetBlock(chainID string, position *ab.SeekPosition) (*cb.Block, error)
+	Close() error
+}
+
+// DeliverStatusError is returned by a DeliverClient when the orderer replies
+// with a status instead of a block
+type DeliverStatusError struct {
+	Status cb.Status
+}
+
+func (e *DeliverStatusError) Error() string {
+	return fmt.Sprintf("Got unexpected status: %v", e.Status)
+}
+
+type deliverClient struct {
+	conn   *grpc.ClientConn
+	client ab.AtomicBroadcastClient
+	signer msp.SigningIdentity
+}
+
+// NewDeliverClient creates a DeliverClient connected to the given orderer
+// which signs its seek requests with signer
+func NewDeliverClient(orderer string, signer msp.SigningIdentity) (DeliverClient, error) {
+	conn, err := newOrdererConn(orderer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deliverClient{conn: conn, client: ab.NewAtomicBroadcastClient(conn), signer: signer}, nil
+}
+
+// GetBlock opens a new deliver stream for every request so that a seek on a
+// chain which does not exist yet does not affect later requests
+func (d *deliverClient) GetBlock(chainID string, position *ab.SeekPosition) (*cb.Block, error) {
+	seekInfo := &ab.SeekInfo{
+		Start:    position,
+		Stop:     position,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+	}
+
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, chainID, d.signer, seekInfo)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating seek request: %s", err)
+	}
+
+	stream, err := d.client.Deliver(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("Error opening deliver stream: %s", err)
+	}
+	defer stream.CloseSend()
+
+	if err = stream.Send(env); err != nil {
+		return nil, fmt.Errorf("Could not send :%s)", err)
+	}
+
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("Error receiving: %s", err)
+	}
+
+	switch t := msg.Type.(type) {
+	case *ab.DeliverResponse_Block:
+		return t.Block, nil
+	case *ab.DeliverResponse_Status:
+		return nil, &DeliverStatusError{Status: t.Status}
+	default:
+		return nil, fmt.Errorf("Response type not recognized: %v", msg)
+	}
+}
+
+func (d *deliverClient) Close() error {
+	return d.conn.Close()
+}
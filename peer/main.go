@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/peer/chaincode"
+	"github.com/hyperledger/fabric/peer/channel"
 	"github.com/hyperledger/fabric/peer/clilogging"
 	"github.com/hyperledger/fabric/peer/common"
 	"github.com/hyperledger/fabric/peer/node"
@@ -90,6 +91,7 @@ func main() {
 	mainCmd.AddCommand(version.Cmd())
 	mainCmd.AddCommand(node.Cmd())
 	mainCmd.AddCommand(chaincode.Cmd(nil))
+	mainCmd.AddCommand(channel.Cmd(nil))
 	mainCmd.AddCommand(clilogging.Cmd())
 
 	runtime.GOMAXPROCS(viper.GetInt("peer.gomaxprocs"))
@@ -97,19 +99,10 @@ func main() {
 	// initialize logging format from core.yaml
 	flogging.SetLoggingFormat(viper.GetString("logging.format"), logOutput)
 
-	// Init the MSP
-	// TODO: determine the location of this config file
-	var mspMgrConfigDir string
-	var alternativeCfgPath = os.Getenv("PEER_CFG_PATH")
-	if alternativeCfgPath != "" {
-		mspMgrConfigDir = alternativeCfgPath + "/msp/sampleconfig/"
-	} else if _, err := os.Stat("./msp/sampleconfig/"); err == nil {
-		mspMgrConfigDir = "./msp/sampleconfig/"
-	} else {
-		mspMgrConfigDir = os.Getenv("GOPATH") + "/src/github.com/hyperledger/fabric/msp/sampleconfig/"
-	}
-
-	err = common.InitCrypto(mspMgrConfigDir)
+	// Init the MSP, only the node commands open the keystore of the node
+	cmd, _, err := mainCmd.Find(os.Args[1:])
+	useKeyStore := err == nil && node.UsesKeyStore(cmd)
+	err = common.InitCrypto(common.GetLocalMSPConfigDir(), useKeyStore)
 	if err != nil { // Handle errors reading the config file
 		panic(err.Error())
 	}
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/certexpiry"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/peer/msp"
+	"github.com/spf13/viper"
+)
+
+// newCertExpiryChecker returns a checker watching the local MSP, the TLS
+// certificate and the MSPs of the chains the peer has joined
+func newCertExpiryChecker() *certexpiry.Checker {
+	sources := []certexpiry.Source{certexpiry.MSPSource("local", mspmgmt.GetLocalMSP())}
+
+	if comm.TLSEnabled() {
+		sources = append(sources, certexpiry.FileSource("TLS", viper.GetString("peer.tls.cert.file")))
+	}
+
+	sources = append(sources, func() ([]certexpiry.Cert, error) {
+		var certs []certexpiry.Cert
+		for cid, mgr := range peer.GetMSPManagers() {
+			msps, err := mgr.GetMSPs()
+			if err != nil {
+				return nil, fmt.Errorf("Could not get the MSPs of chain %s: %s", cid, err)
+			}
+			for _, m := range msps {
+				c, err := certexpiry.MSPSource("chain "+cid, m)()
+				if err != nil {
+					return nil, err
+				}
+				certs = append(certs, c...)
+			}
+		}
+		return certs, nil
+	})
+
+	return certexpiry.NewChecker(viper.GetDuration("security.certExpiry.checkInterval"),
+		viper.GetDuration("security.certExpiry.warnWithin"), sources...)
+}
+
+// renewSigningIdentity reloads the local signing identity after its
+// certificate and key have been replaced in the local MSP directory
+func renewSigningIdentity() {
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	if err == nil {
+		err = signer.Renew()
+	}
+	if err != nil {
+		logger.Errorf("Failed renewing the local signing identity: %s", err)
+		return
+	}
+	logger.Info("Renewed the local signing identity")
+}
+
+// checkSigningIdentity returns an error if the certificate of the local
+// signing identity is expired or not valid yet
+func checkSigningIdentity() error {
+	return certexpiry.CheckSigningCert(mspmgmt.GetLocalMSP(), time.Now())
+}
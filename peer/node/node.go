@@ -36,10 +36,18 @@ func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(statusCmd())
 	nodeCmd.AddCommand(stopCmd())
 	nodeCmd.AddCommand(joinCmd())
+	nodeCmd.AddCommand(verifyCmd())
+	nodeCmd.AddCommand(rotateKeysCmd())
 
 	return nodeCmd
 }
 
+// UsesKeyStore returns whether cmd runs the node or maintains its keystore,
+// the other commands are clients of the node and do not open its keystore
+func UsesKeyStore(cmd *cobra.Command) bool {
+	return cmd == nodeStartCmd || cmd == nodeRotateKeysCmd
+}
+
 var nodeCmd = &cobra.Command{
 	Use:   nodeFuncName,
 	Short: fmt.Sprintf("%s specific commands.", nodeFuncName),
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var newPassphraseFile string
+
+func rotateKeysCmd() *cobra.Command {
+	nodeRotateKeysCmd.Flags().StringVar(&newPassphraseFile, "new-passphrase-file", "",
+		"File holding the new passphrase of the keystore")
+
+	return nodeRotateKeysCmd
+}
+
+var nodeRotateKeysCmd = &cobra.Command{
+	Use:   "rotatekeys",
+	Short: "Re-encrypts the keystore of the node with a new passphrase.",
+	Long: `Re-encrypts the keys of the software keystore at security.keyStore.path
+and the signing key in the keystore directory of the local MSP with the
+passphrase read from --new-passphrase-file. The current passphrase is taken
+from security.keyStore.passphrase, the ` + sw.KeyStorePassphraseEnv + `
+environment variable or security.keyStore.passphraseFile. Keys stored in clear
+get encrypted. The node must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rotateKeys()
+	},
+}
+
+func rotateKeys() error {
+	if newPassphraseFile == "" {
+		return errors.New("Missing --new-passphrase-file")
+	}
+
+	pwd, err := sw.LoadKeyStorePassphrase(viper.GetString("security.keyStore.passphrase"),
+		viper.GetString("security.keyStore.passphraseFile"))
+	if err != nil {
+		return err
+	}
+
+	newPwd, err := sw.ReadPassphraseFile(newPassphraseFile)
+	if err != nil {
+		return err
+	}
+
+	if path := viper.GetString("security.keyStore.path"); path != "" {
+		ks := &sw.FileBasedKeyStore{}
+		if err = ks.Init(pwd, path, false); err != nil {
+			return fmt.Errorf("Error opening keystore %s: %s", path, err)
+		}
+
+		if err = ks.Rotate(newPwd); err != nil {
+			return fmt.Errorf("Error rotating keystore %s: %s", path, err)
+		}
+
+		fmt.Printf("Keystore %s re-encrypted\n", path)
+	}
+
+	// The local MSP was loaded with pwd before the command ran, so its
+	// signing key is known to decrypt and cannot fail half way
+	mspDir := common.GetLocalMSPConfigDir()
+	if err = msp.RotateSigningKeyPassphrase(mspDir, pwd, newPwd); err != nil {
+		return fmt.Errorf("Error rotating the signing key of the MSP in %s: %s", mspDir, err)
+	}
+
+	fmt.Printf("Signing key of the MSP in %s re-encrypted\n", mspDir)
+	return nil
+}
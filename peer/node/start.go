@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/endorser"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
@@ -95,6 +96,14 @@ func serve(args []string) error {
 		return err
 	}
 
+	// Refuse to run with an identity nobody would accept
+	if err := checkSigningIdentity(); err != nil {
+		return err
+	}
+
+	//chaincode packages installed on this peer are kept under the file system path
+	ccprovider.SetChaincodesPath(filepath.Join(viper.GetString("peer.fileSystemPath"), "chaincodes"))
+
 	peerEndpoint, err := peer.GetPeerEndpoint()
 	if err != nil {
 		err = fmt.Errorf("Failed to get Peer Endpoint: %s", err)
@@ -184,6 +193,12 @@ func serve(args []string) error {
 		defer deliverclient.StopDeliveryService(deliver)
 	}
 
+	// Warn ahead of the expiry of the certificates in use
+	expiryChecker := newCertExpiryChecker()
+	expiryChecker.Publish("certExpiryDays")
+	expiryChecker.Start()
+	defer expiryChecker.Stop()
+
 	logger.Infof("Starting peer with ID=%s, network ID=%s, address=%s",
 		peerEndpoint.ID, viper.GetString("peer.networkId"), peerEndpoint.Address)
 
@@ -192,12 +207,20 @@ func serve(args []string) error {
 	serve := make(chan error)
 
 	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
-		sig := <-sigs
-		fmt.Println()
-		fmt.Println(sig)
-		serve <- nil
+		for sig := range sigs {
+			// SIGHUP renews the signing identity from the local MSP directory
+			if sig == syscall.SIGHUP {
+				renewSigningIdentity()
+				expiryChecker.Check()
+				continue
+			}
+			fmt.Println()
+			fmt.Println(sig)
+			serve <- nil
+			return
+		}
 	}()
 
 	go func() {
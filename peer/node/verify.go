@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package node
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/spf13/cobra"
+)
+
+var verifyRepair bool
+
+func verifyCmd() *cobra.Command {
+	nodeVerifyCmd.Flags().BoolVarP(&verifyRepair, "repair", "r", false,
+		"Repair the block files and rebuild the block index, the state DB and the history DB when they are inconsistent")
+
+	return nodeVerifyCmd
+}
+
+var nodeVerifyCmd = &cobra.Command{
+	Use:   "verify [ledgerID...]",
+	Short: "Verifies the ledgers of the stopped node.",
+	Long: `Verifies the consistency of the block files, block index, state DB and
+history DB of the given ledgers of the node, or of all its ledgers, and
+optionally repairs them. The node must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return verify(args)
+	},
+}
+
+func verify(ledgerIDs []string) error {
+	p, err := kvledger.NewProvider()
+	if err != nil {
+		return err
+	}
+	provider := p.(*kvledger.Provider)
+	defer provider.Close()
+
+	if len(ledgerIDs) == 0 {
+		if ledgerIDs, err = provider.List(); err != nil {
+			return err
+		}
+	}
+
+	numProblems := 0
+	for _, ledgerID := range ledgerIDs {
+		report, err := provider.Verify(ledgerID, verifyRepair)
+		if err != nil {
+			return fmt.Errorf("Error verifying ledger %s: %s", ledgerID, err)
+		}
+		printVerificationReport(report)
+		numProblems += len(report.Blocks.Problems) + len(report.Problems)
+	}
+
+	if numProblems > 0 && !verifyRepair {
+		return fmt.Errorf("Found %d problems, run with --repair to repair the ledgers", numProblems)
+	}
+
+	return nil
+}
+
+func printVerificationReport(report *kvledger.VerificationReport) {
+	blocks := report.Blocks
+	if blocks.NumBlocks == 0 {
+		fmt.Printf("Ledger %s: no blocks, state savepoint %d, history savepoint %d\n", report.LedgerID,
+			report.StateSavepoint, report.HistorySavepoint)
+	} else {
+		fmt.Printf("Ledger %s: blocks %d to %d, state savepoint %d, history savepoint %d\n", report.LedgerID,
+			blocks.FirstBlockNumber, blocks.LastBlockNumber, report.StateSavepoint, report.HistorySavepoint)
+	}
+	for _, problem := range append(blocks.Problems, report.Problems...) {
+		fmt.Printf("  %s\n", problem)
+	}
+	if blocks.BlockfilesRepaired {
+		fmt.Println("  Block files truncated after the last complete block and checkpoint rewritten")
+	}
+	if blocks.IndexRebuilt {
+		fmt.Println("  Block index rebuilt")
+	}
+	if report.StateRebuilt {
+		fmt.Println("  State DB rebuilt")
+	}
+	if report.HistoryRebuilt {
+		fmt.Println("  History DB rebuilt")
+	}
+}
@@ -0,0 +1,237 @@
+// Code generated by protoc-gen-go.
+// source: ledger/kvrwset/kv_rwset.proto
+// DO NOT EDIT!
+
+/*
+Package kvrwset is a generated protocol buffer package.
+
+It is generated from these files:
+	ledger/kvrwset/kv_rwset.proto
+
+It has these top-level messages:
+	TxReadWriteSet
+	NsReadWriteSet
+	Version
+	KVRead
+	KVWrite
+	RangeQueryInfo
+	HashedValues
+	HashedValue
+*/
+package kvrwset
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// TxReadWriteSet encapsulates the reads, writes and range queries performed
+// by a transaction simulation on a key-value state, grouped by namespace. It
+// is carried as is in the results field of the ChaincodeAction. The version
+// field must be set, it is the first field of the encoded message, which
+// tells the message apart from the read-write sets written before it existed
+type TxReadWriteSet struct {
+	// version of the encoding, 1 for this message
+	Version uint32            `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	NsRWs   []*NsReadWriteSet `protobuf:"bytes,2,rep,name=nsRWs" json:"nsRWs,omitempty"`
+}
+
+func (m *TxReadWriteSet) Reset()                    { *m = TxReadWriteSet{} }
+func (m *TxReadWriteSet) String() string            { return proto.CompactTextString(m) }
+func (*TxReadWriteSet) ProtoMessage()               {}
+func (*TxReadWriteSet) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *TxReadWriteSet) GetNsRWs() []*NsReadWriteSet {
+	if m != nil {
+		return m.NsRWs
+	}
+	return nil
+}
+
+// NsReadWriteSet holds the reads, writes and range queries of a transaction
+// on a single namespace (chaincode)
+type NsReadWriteSet struct {
+	Namespace        string            `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Reads            []*KVRead         `protobuf:"bytes,2,rep,name=reads" json:"reads,omitempty"`
+	Writes           []*KVWrite        `protobuf:"bytes,3,rep,name=writes" json:"writes,omitempty"`
+	RangeQueriesInfo []*RangeQueryInfo `protobuf:"bytes,4,rep,name=rangeQueriesInfo" json:"rangeQueriesInfo,omitempty"`
+}
+
+func (m *NsReadWriteSet) Reset()                    { *m = NsReadWriteSet{} }
+func (m *NsReadWriteSet) String() string            { return proto.CompactTextString(m) }
+func (*NsReadWriteSet) ProtoMessage()               {}
+func (*NsReadWriteSet) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *NsReadWriteSet) GetReads() []*KVRead {
+	if m != nil {
+		return m.Reads
+	}
+	return nil
+}
+
+func (m *NsReadWriteSet) GetWrites() []*KVWrite {
+	if m != nil {
+		return m.Writes
+	}
+	return nil
+}
+
+func (m *NsReadWriteSet) GetRangeQueriesInfo() []*RangeQueryInfo {
+	if m != nil {
+		return m.RangeQueriesInfo
+	}
+	return nil
+}
+
+// Version is the height (block number and transaction number within the
+// block) of the transaction that last committed a key
+type Version struct {
+	BlockNum uint64 `protobuf:"varint,1,opt,name=blockNum" json:"blockNum,omitempty"`
+	TxNum    uint64 `protobuf:"varint,2,opt,name=txNum" json:"txNum,omitempty"`
+}
+
+func (m *Version) Reset()                    { *m = Version{} }
+func (m *Version) String() string            { return proto.CompactTextString(m) }
+func (*Version) ProtoMessage()               {}
+func (*Version) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// KVRead is a key read during simulation along with the version read. A nil
+// version means that the key did not exist
+type KVRead struct {
+	Key     string   `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Version *Version `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *KVRead) Reset()                    { *m = KVRead{} }
+func (m *KVRead) String() string            { return proto.CompactTextString(m) }
+func (*KVRead) ProtoMessage()               {}
+func (*KVRead) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *KVRead) GetVersion() *Version {
+	if m != nil {
+		return m.Version
+	}
+	return nil
+}
+
+// KVWrite is a key written or deleted during simulation. When the value is
+// larger than the threshold configured on the endorser, only its hash is
+// set here and the value travels in HashedValues along with the transaction
+type KVWrite struct {
+	Key       string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	IsDelete  bool   `protobuf:"varint,2,opt,name=isDelete" json:"isDelete,omitempty"`
+	Value     []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	ValueHash []byte `protobuf:"bytes,4,opt,name=valueHash,proto3" json:"valueHash,omitempty"`
+}
+
+func (m *KVWrite) Reset()                    { *m = KVWrite{} }
+func (m *KVWrite) String() string            { return proto.CompactTextString(m) }
+func (*KVWrite) ProtoMessage()               {}
+func (*KVWrite) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+// RangeQueryInfo is a range query performed during simulation with the
+// results read. It is used to detect phantom reads at commit time
+type RangeQueryInfo struct {
+	StartKey     string    `protobuf:"bytes,1,opt,name=startKey" json:"startKey,omitempty"`
+	EndKey       string    `protobuf:"bytes,2,opt,name=endKey" json:"endKey,omitempty"`
+	ItrExhausted bool      `protobuf:"varint,3,opt,name=itrExhausted" json:"itrExhausted,omitempty"`
+	Results      []*KVRead `protobuf:"bytes,4,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *RangeQueryInfo) Reset()                    { *m = RangeQueryInfo{} }
+func (m *RangeQueryInfo) String() string            { return proto.CompactTextString(m) }
+func (*RangeQueryInfo) ProtoMessage()               {}
+func (*RangeQueryInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *RangeQueryInfo) GetResults() []*KVRead {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// HashedValues carries the values that were replaced by their hashes in the
+// writes of an endorsed TxReadWriteSet
+type HashedValues struct {
+	Values []*HashedValue `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *HashedValues) Reset()                    { *m = HashedValues{} }
+func (m *HashedValues) String() string            { return proto.CompactTextString(m) }
+func (*HashedValues) ProtoMessage()               {}
+func (*HashedValues) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *HashedValues) GetValues() []*HashedValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// HashedValue is a value along with its hash
+type HashedValue struct {
+	Hash  []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *HashedValue) Reset()                    { *m = HashedValue{} }
+func (m *HashedValue) String() string            { return proto.CompactTextString(m) }
+func (*HashedValue) ProtoMessage()               {}
+func (*HashedValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func init() {
+	proto.RegisterType((*TxReadWriteSet)(nil), "kvrwset.TxReadWriteSet")
+	proto.RegisterType((*NsReadWriteSet)(nil), "kvrwset.NsReadWriteSet")
+	proto.RegisterType((*Version)(nil), "kvrwset.Version")
+	proto.RegisterType((*KVRead)(nil), "kvrwset.KVRead")
+	proto.RegisterType((*KVWrite)(nil), "kvrwset.KVWrite")
+	proto.RegisterType((*RangeQueryInfo)(nil), "kvrwset.RangeQueryInfo")
+	proto.RegisterType((*HashedValues)(nil), "kvrwset.HashedValues")
+	proto.RegisterType((*HashedValue)(nil), "kvrwset.HashedValue")
+}
+
+func init() { proto.RegisterFile("ledger/kvrwset/kv_rwset.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 470 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xdf, 0x8b, 0xd3, 0x40,
+	0x10, 0x26, 0x6d, 0x9a, 0xf4, 0xe6, 0x6a, 0x2d, 0xcb, 0xa1, 0xcb, 0xa1, 0x50, 0x16, 0x84, 0x2a,
+	0xda, 0x80, 0x87, 0xf8, 0xa0, 0x4f, 0xfe, 0x42, 0x39, 0x38, 0x70, 0x95, 0x1e, 0xfa, 0x22, 0xdb,
+	0x66, 0xae, 0x09, 0x49, 0x93, 0xb2, 0xbb, 0xe9, 0xb5, 0x7f, 0x87, 0xff, 0x92, 0x7f, 0x98, 0xec,
+	0x66, 0x9b, 0xa6, 0x7a, 0x6f, 0xf3, 0xcd, 0x37, 0xf3, 0xcd, 0x37, 0xb3, 0x2c, 0x3c, 0xce, 0x31,
+	0x5e, 0xa2, 0x8c, 0xb2, 0x8d, 0xbc, 0x55, 0xa8, 0xa3, 0x6c, 0xf3, 0xcb, 0x06, 0xd3, 0xb5, 0x2c,
+	0x75, 0x49, 0x42, 0x97, 0x67, 0x3f, 0x60, 0xf8, 0x7d, 0xcb, 0x51, 0xc4, 0xd7, 0x32, 0xd5, 0xf8,
+	0x0d, 0x35, 0xa1, 0x10, 0x6e, 0x50, 0xaa, 0xb4, 0x2c, 0xa8, 0x37, 0xf6, 0x26, 0xf7, 0xf8, 0x1e,
+	0x92, 0x17, 0xd0, 0x2b, 0x14, 0xbf, 0x56, 0xb4, 0x33, 0xee, 0x4e, 0x4e, 0x5f, 0x3e, 0x9c, 0x3a,
+	0x91, 0xe9, 0x95, 0x6a, 0x2b, 0xf0, 0xba, 0x8a, 0xfd, 0xf1, 0x60, 0x78, 0xcc, 0x90, 0x47, 0x70,
+	0x52, 0x88, 0x15, 0xaa, 0xb5, 0x58, 0xa0, 0x55, 0x3f, 0xe1, 0x87, 0x04, 0x79, 0x02, 0x3d, 0x89,
+	0x22, 0xde, 0xeb, 0xdf, 0x6f, 0xf4, 0x2f, 0x67, 0x46, 0x85, 0xd7, 0x2c, 0x99, 0x40, 0x70, 0x6b,
+	0x04, 0x15, 0xed, 0xda, 0xba, 0x51, 0xab, 0xce, 0x4e, 0xe2, 0x8e, 0x27, 0xef, 0x61, 0x24, 0x45,
+	0xb1, 0xc4, 0xaf, 0x15, 0xca, 0x14, 0xd5, 0x97, 0xe2, 0xa6, 0xa4, 0xfe, 0x3f, 0xde, 0xf9, 0xbe,
+	0x60, 0x67, 0x68, 0xfe, 0x5f, 0x03, 0x7b, 0x03, 0xe1, 0xcc, 0x1d, 0xe0, 0x1c, 0xfa, 0xf3, 0xbc,
+	0x5c, 0x64, 0x57, 0xd5, 0xca, 0xba, 0xf7, 0x79, 0x83, 0xc9, 0x19, 0xf4, 0xf4, 0xd6, 0x10, 0x1d,
+	0x4b, 0xd4, 0x80, 0x7d, 0x82, 0xa0, 0x36, 0x4f, 0x46, 0xd0, 0xcd, 0x70, 0xe7, 0x96, 0x36, 0x21,
+	0x79, 0x76, 0x38, 0xb4, 0xe9, 0x69, 0x2f, 0xe2, 0x06, 0x36, 0xa7, 0x67, 0x19, 0x84, 0x6e, 0xb9,
+	0x3b, 0x84, 0xce, 0xa1, 0x9f, 0xaa, 0x0f, 0x98, 0xa3, 0x46, 0xab, 0xd4, 0xe7, 0x0d, 0x36, 0xb6,
+	0x36, 0x22, 0xaf, 0x90, 0x76, 0xc7, 0xde, 0x64, 0xc0, 0x6b, 0x60, 0xde, 0xc1, 0x06, 0x9f, 0x85,
+	0x4a, 0xa8, 0x6f, 0x99, 0x43, 0x82, 0xfd, 0xf6, 0x60, 0x78, 0x7c, 0x16, 0x33, 0x42, 0x69, 0x21,
+	0xf5, 0x65, 0x33, 0xb9, 0xc1, 0xe4, 0x01, 0x04, 0x58, 0xc4, 0x86, 0xe9, 0x58, 0xc6, 0x21, 0xc2,
+	0x60, 0x90, 0x6a, 0xf9, 0x71, 0x9b, 0x88, 0x4a, 0x69, 0x8c, 0xad, 0x83, 0x3e, 0x3f, 0xca, 0x91,
+	0xa7, 0x10, 0x4a, 0x54, 0x55, 0xae, 0x15, 0xf5, 0xef, 0x7e, 0xf4, 0x3d, 0xcf, 0xde, 0xc2, 0xc0,
+	0xb8, 0xc3, 0x78, 0x66, 0x8c, 0x2a, 0xf2, 0x1c, 0x02, 0x6b, 0x59, 0x51, 0xcf, 0x76, 0x9e, 0x35,
+	0x9d, 0xad, 0x32, 0xee, 0x6a, 0xd8, 0x6b, 0x38, 0x6d, 0xa5, 0x09, 0x01, 0x3f, 0x31, 0xbb, 0x7b,
+	0x76, 0x77, 0x1b, 0x1f, 0x4e, 0xd5, 0x69, 0x9d, 0xea, 0xdd, 0xab, 0x9f, 0x17, 0xcb, 0x54, 0x27,
+	0xd5, 0x7c, 0xba, 0x28, 0x57, 0x51, 0xb2, 0x5b, 0xa3, 0x74, 0x5f, 0xeb, 0x46, 0xcc, 0x65, 0xba,
+	0x88, 0xec, 0x87, 0x52, 0xd1, 0xf1, 0x7f, 0x9b, 0x07, 0x36, 0x7d, 0xf1, 0x77, 0x00, 0x8e, 0x21,
+	0x6f, 0xed, 0x88, 0x03, 0x00, 0x00,
+}
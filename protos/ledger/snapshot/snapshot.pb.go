@@ -0,0 +1,152 @@
+// Code generated by protoc-gen-go.
+// source: ledger/snapshot/snapshot.proto
+// DO NOT EDIT!
+
+/*
+Package snapshot is a generated protocol buffer package.
+
+It is generated from these files:
+	ledger/snapshot/snapshot.proto
+
+It has these top-level messages:
+	Snapshot
+	StateEntry
+	SnapshotChunk
+	SnapshotInfo
+*/
+package snapshot
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// Snapshot holds the state of a ledger as of a block. A new ledger can be
+// created from it whose block storage starts at that block, without having
+// to replay the chain from the genesis block. A snapshot is written as a
+// sequence of length-delimited messages: a Snapshot header followed by the
+// SnapshotChunks holding the IDs of the committed transactions and the state
+type Snapshot struct {
+	// version of the encoding
+	Version  uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	LedgerID string `protobuf:"bytes,2,opt,name=ledgerID" json:"ledgerID,omitempty"`
+	// lastBlock is the block the snapshot was taken at
+	LastBlock *common.Block `protobuf:"bytes,3,opt,name=lastBlock" json:"lastBlock,omitempty"`
+	// configBlock is the block holding the latest configuration of the chain
+	// as of lastBlock. It is not set when lastBlock is the config block
+	ConfigBlock *common.Block `protobuf:"bytes,4,opt,name=configBlock" json:"configBlock,omitempty"`
+}
+
+func (m *Snapshot) Reset()                    { *m = Snapshot{} }
+func (m *Snapshot) String() string            { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()               {}
+func (*Snapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Snapshot) GetLastBlock() *common.Block {
+	if m != nil {
+		return m.LastBlock
+	}
+	return nil
+}
+
+func (m *Snapshot) GetConfigBlock() *common.Block {
+	if m != nil {
+		return m.ConfigBlock
+	}
+	return nil
+}
+
+// StateEntry is a key of the state DB along with its value and version
+type StateEntry struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Key       string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Value     []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	BlockNum  uint64 `protobuf:"varint,4,opt,name=blockNum" json:"blockNum,omitempty"`
+	TxNum     uint64 `protobuf:"varint,5,opt,name=txNum" json:"txNum,omitempty"`
+}
+
+func (m *StateEntry) Reset()                    { *m = StateEntry{} }
+func (m *StateEntry) String() string            { return proto.CompactTextString(m) }
+func (*StateEntry) ProtoMessage()               {}
+func (*StateEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+// SnapshotChunk holds part of the state and of the IDs of all the
+// transactions committed up to the last block of a snapshot. The IDs are
+// needed to detect duplicate transactions
+type SnapshotChunk struct {
+	State []*StateEntry `protobuf:"bytes,1,rep,name=state" json:"state,omitempty"`
+	TxIDs []string      `protobuf:"bytes,2,rep,name=txIDs" json:"txIDs,omitempty"`
+}
+
+func (m *SnapshotChunk) Reset()                    { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string            { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()               {}
+func (*SnapshotChunk) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *SnapshotChunk) GetState() []*StateEntry {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+// SnapshotInfo describes a snapshot written by a peer
+type SnapshotInfo struct {
+	// path of the snapshot on the file system of the peer
+	Path        string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	BlockNumber uint64 `protobuf:"varint,2,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	// hash is computed over the hash of the header of the last block
+	// followed by the messages of the snapshot
+	Hash []byte `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *SnapshotInfo) Reset()                    { *m = SnapshotInfo{} }
+func (m *SnapshotInfo) String() string            { return proto.CompactTextString(m) }
+func (*SnapshotInfo) ProtoMessage()               {}
+func (*SnapshotInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func init() {
+	proto.RegisterType((*Snapshot)(nil), "snapshot.Snapshot")
+	proto.RegisterType((*StateEntry)(nil), "snapshot.StateEntry")
+	proto.RegisterType((*SnapshotChunk)(nil), "snapshot.SnapshotChunk")
+	proto.RegisterType((*SnapshotInfo)(nil), "snapshot.SnapshotInfo")
+}
+
+func init() { proto.RegisterFile("ledger/snapshot/snapshot.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 352 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x52, 0x41, 0x4f, 0x83, 0x30,
+	0x14, 0x0e, 0x83, 0xe9, 0x78, 0x6c, 0x89, 0xa9, 0x3b, 0x90, 0xc5, 0x18, 0xc2, 0x89, 0x68, 0x32,
+	0x92, 0x69, 0xfc, 0x01, 0x73, 0x1e, 0x76, 0x31, 0xb1, 0xbb, 0x18, 0x6f, 0x05, 0xbb, 0x41, 0x06,
+	0x2d, 0xa1, 0x65, 0x71, 0x77, 0x7f, 0x87, 0xbf, 0xd5, 0xb4, 0x05, 0xb6, 0x18, 0x4f, 0xbc, 0xef,
+	0x7d, 0x5f, 0x5f, 0xbf, 0xef, 0x51, 0xb8, 0x2d, 0xe8, 0xe7, 0x8e, 0xd6, 0xb1, 0x60, 0xa4, 0x12,
+	0x19, 0x97, 0x7d, 0x31, 0xaf, 0x6a, 0x2e, 0x39, 0x1a, 0x75, 0x78, 0x76, 0x9d, 0xf2, 0xb2, 0xe4,
+	0x2c, 0x36, 0x1f, 0x43, 0x87, 0x3f, 0x16, 0x8c, 0x36, 0xad, 0x02, 0xf9, 0x70, 0x79, 0xa0, 0xb5,
+	0xc8, 0x39, 0xf3, 0xad, 0xc0, 0x8a, 0x26, 0xb8, 0x83, 0x68, 0x06, 0x23, 0x73, 0xcf, 0x7a, 0xe5,
+	0x0f, 0x02, 0x2b, 0x72, 0x71, 0x8f, 0xd1, 0x3d, 0xb8, 0x05, 0x11, 0x72, 0x59, 0xf0, 0x74, 0xef,
+	0xdb, 0x81, 0x15, 0x79, 0x8b, 0xc9, 0xbc, 0xbd, 0x44, 0x37, 0xf1, 0x89, 0x47, 0x31, 0x78, 0x29,
+	0x67, 0xdb, 0x7c, 0x67, 0xe4, 0xce, 0x7f, 0xf2, 0x73, 0x45, 0xf8, 0x6d, 0x01, 0x6c, 0x24, 0x91,
+	0xf4, 0x85, 0xc9, 0xfa, 0x88, 0x6e, 0xc0, 0x65, 0xa4, 0xa4, 0xa2, 0x22, 0x29, 0xd5, 0x26, 0x5d,
+	0x7c, 0x6a, 0xa0, 0x2b, 0xb0, 0xf7, 0xf4, 0xd8, 0x3a, 0x54, 0x25, 0x9a, 0xc2, 0xf0, 0x40, 0x8a,
+	0x86, 0x6a, 0x63, 0x63, 0x6c, 0x80, 0x8a, 0x93, 0xa8, 0xe9, 0xaf, 0x4d, 0xa9, 0x2d, 0x38, 0xb8,
+	0xc7, 0xea, 0x84, 0xfc, 0x52, 0xc4, 0x50, 0x13, 0x06, 0x84, 0x6f, 0x30, 0xe9, 0xd6, 0xf4, 0x9c,
+	0x35, 0x6c, 0x8f, 0xee, 0x60, 0x28, 0x94, 0x2d, 0xdf, 0x0a, 0xec, 0xc8, 0x5b, 0x4c, 0xe7, 0xfd,
+	0xde, 0x4f, 0x6e, 0xb1, 0x91, 0x98, 0x91, 0xeb, 0x95, 0xf0, 0x07, 0x81, 0x1d, 0xb9, 0xd8, 0x80,
+	0xf0, 0x1d, 0xc6, 0xdd, 0xc8, 0x35, 0xdb, 0x72, 0x84, 0xc0, 0xa9, 0x88, 0xcc, 0xda, 0x54, 0xba,
+	0x46, 0x01, 0x78, 0x9d, 0xb1, 0x84, 0xd6, 0x3a, 0x98, 0x83, 0xcf, 0x5b, 0xea, 0x54, 0x46, 0x44,
+	0xd6, 0xe6, 0xd3, 0xf5, 0xf2, 0xe9, 0xe3, 0x71, 0x97, 0xcb, 0xac, 0x49, 0xd4, 0x5e, 0xe3, 0xec,
+	0x58, 0xd1, 0xba, 0x7d, 0x25, 0x5b, 0x92, 0xd4, 0x79, 0x1a, 0xeb, 0x7f, 0x2f, 0xe2, 0x3f, 0x4f,
+	0x27, 0xb9, 0xd0, 0xfd, 0x87, 0xdf, 0x01, 0x00, 0x23, 0xaa, 0x14, 0x70, 0x54, 0x02, 0x00, 0x00,
+}
@@ -18,7 +18,9 @@ It is generated from these files:
 	peer/fabric_proposal_response.proto
 	peer/fabric_service.proto
 	peer/fabric_transaction.proto
+	peer/query.proto
 	peer/server_admin.proto
+	peer/signed_cc_dep_spec.proto
 
 It has these top-level messages:
 	ChaincodeID
@@ -64,9 +66,14 @@ It has these top-level messages:
 	InvalidTransaction
 	Transaction
 	TransactionAction
+	ChannelQueryResponse
+	ChannelInfo
+	ChaincodeQueryResponse
+	ChaincodeInfo
 	ServerStatus
 	LogLevelRequest
 	LogLevelResponse
+	SignedChaincodeDeploymentSpec
 */
 package peer
 
@@ -245,6 +252,8 @@ type ChaincodeID struct {
 	// all other requests will use the name (really a hashcode) generated by
 	// the deploy transaction
 	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// user friendly version name for the chaincode
+	Version string `protobuf:"bytes,3,opt,name=version" json:"version,omitempty"`
 }
 
 func (m *ChaincodeID) Reset()                    { *m = ChaincodeID{} }
@@ -358,6 +367,9 @@ type ChaincodeMessage struct {
 	// This event is then stored (currently)
 	// with Block.NonHashData.TransactionResult
 	ChaincodeEvent *ChaincodeEvent `protobuf:"bytes,6,opt,name=chaincodeEvent" json:"chaincodeEvent,omitempty"`
+	// signed proposal of the transaction, sent to the chaincode with Init
+	// and Invoke so that it can see who created it
+	Proposal *SignedProposal `protobuf:"bytes,7,opt,name=proposal" json:"proposal,omitempty"`
 }
 
 func (m *ChaincodeMessage) Reset()                    { *m = ChaincodeMessage{} }
@@ -379,6 +391,13 @@ func (m *ChaincodeMessage) GetChaincodeEvent() *ChaincodeEvent {
 	return nil
 }
 
+func (m *ChaincodeMessage) GetProposal() *SignedProposal {
+	if m != nil {
+		return m.Proposal
+	}
+	return nil
+}
+
 type PutStateInfo struct {
 	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -571,71 +590,74 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/chaincode.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1056 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x56, 0x5d, 0x6f, 0xe2, 0x46,
-	0x17, 0x8e, 0x81, 0x04, 0x38, 0x7c, 0x64, 0x76, 0x96, 0x4d, 0x78, 0x79, 0xdb, 0x2e, 0xb2, 0xda,
-	0x8a, 0xf6, 0x02, 0xb6, 0x74, 0xb7, 0xaa, 0x54, 0x69, 0x55, 0xaf, 0x3d, 0x4b, 0xdd, 0x10, 0xc3,
-	0x0e, 0x4e, 0xb4, 0xe9, 0x4d, 0xe4, 0x98, 0x81, 0x58, 0x01, 0xdb, 0xb2, 0x07, 0x14, 0xee, 0x7a,
-	0xdd, 0xab, 0x4a, 0xfd, 0x09, 0xfd, 0x17, 0xfd, 0x75, 0xd5, 0xf8, 0x83, 0x40, 0x20, 0xd2, 0x4a,
-	0xbd, 0x62, 0x9e, 0x39, 0xcf, 0x73, 0x7c, 0xe6, 0x7c, 0x0c, 0x03, 0x35, 0x9f, 0xb1, 0xa0, 0x63,
-	0xdf, 0x5a, 0x8e, 0x6b, 0x7b, 0x63, 0xd6, 0xf6, 0x03, 0x8f, 0x7b, 0xf8, 0x28, 0xfa, 0x09, 0x1b,
-	0xff, 0xdb, 0xb6, 0xb2, 0x25, 0x73, 0x79, 0x4c, 0x69, 0xbc, 0x9c, 0x7a, 0xde, 0x74, 0xc6, 0x3a,
-	0x11, 0xba, 0x59, 0x4c, 0x3a, 0xdc, 0x99, 0xb3, 0x90, 0x5b, 0x73, 0x3f, 0x26, 0xc8, 0x6f, 0xa0,
-	0xa4, 0xa6, 0x42, 0x5d, 0xc3, 0x18, 0x72, 0xbe, 0xc5, 0x6f, 0xeb, 0x52, 0x53, 0x6a, 0x15, 0x69,
-	0xb4, 0x16, 0x7b, 0xae, 0x35, 0x67, 0xf5, 0x4c, 0xbc, 0x27, 0xd6, 0xf2, 0x97, 0x50, 0x7d, 0x90,
-	0xb9, 0xfe, 0x82, 0x0b, 0x96, 0x15, 0x4c, 0xc3, 0xba, 0xd4, 0xcc, 0xb6, 0xca, 0x34, 0x5a, 0xcb,
-	0x7f, 0x66, 0xa1, 0xb2, 0xa6, 0x8d, 0x7c, 0x66, 0xe3, 0x36, 0xe4, 0xf8, 0xca, 0x67, 0x91, 0xff,
-	0x6a, 0xb7, 0x11, 0x07, 0x11, 0xb6, 0xb7, 0x48, 0x6d, 0x73, 0xe5, 0x33, 0x1a, 0xf1, 0xf0, 0x1b,
-	0x28, 0xd9, 0x0f, 0xe1, 0x45, 0x21, 0x94, 0xba, 0xcf, 0x77, 0x64, 0xba, 0x46, 0x37, 0x79, 0xf8,
-	0x15, 0xe4, 0x6d, 0xee, 0x05, 0xe7, 0xe1, 0xb4, 0x9e, 0x8d, 0x24, 0x27, 0xbb, 0x12, 0x11, 0x35,
-	0x4d, 0x69, 0xb8, 0x0e, 0x79, 0x91, 0x1a, 0x6f, 0xc1, 0xeb, 0xb9, 0xa6, 0xd4, 0x3a, 0xa4, 0x29,
-	0xc4, 0x43, 0xa8, 0xd9, 0x9e, 0x3b, 0x71, 0xc6, 0xcc, 0xe5, 0x8e, 0x35, 0x73, 0xf8, 0xaa, 0xcf,
-	0x96, 0x6c, 0x56, 0x3f, 0x8c, 0x8e, 0xf0, 0xd9, 0xda, 0xf1, 0x1e, 0x0e, 0xdd, 0xab, 0xc4, 0x0d,
-	0x28, 0xcc, 0x19, 0xb7, 0xc6, 0x16, 0xb7, 0xea, 0x47, 0x4d, 0xa9, 0x55, 0xa6, 0x6b, 0x8c, 0xbf,
-	0x00, 0xb0, 0x38, 0x0f, 0x9c, 0x9b, 0x05, 0x67, 0x61, 0x3d, 0xdf, 0xcc, 0xb6, 0x8a, 0x74, 0x63,
-	0x47, 0x7e, 0x0b, 0x39, 0x91, 0x1e, 0x5c, 0x81, 0xe2, 0x85, 0xa1, 0x91, 0xf7, 0xba, 0x41, 0x34,
-	0x74, 0x80, 0x01, 0x8e, 0x7a, 0x83, 0xbe, 0x62, 0xf4, 0x90, 0x84, 0x0b, 0x90, 0x33, 0x06, 0x1a,
-	0x41, 0x19, 0x9c, 0x87, 0xac, 0xaa, 0x50, 0x94, 0x15, 0x5b, 0xbf, 0x2a, 0x97, 0x0a, 0xca, 0xc9,
-	0xff, 0x64, 0xe0, 0x74, 0x9d, 0x03, 0x8d, 0xf9, 0x33, 0x6f, 0x35, 0x67, 0x2e, 0x8f, 0x8a, 0xf3,
-	0x13, 0x54, 0xec, 0xcd, 0x42, 0x44, 0x55, 0x2a, 0x75, 0x5f, 0xec, 0xad, 0x12, 0xdd, 0xe6, 0xe2,
-	0x9f, 0xa1, 0xc2, 0x26, 0x13, 0x66, 0x73, 0x67, 0xc9, 0x34, 0x8b, 0xb3, 0xa4, 0x56, 0x8d, 0x76,
-	0xdc, 0x81, 0xed, 0xb4, 0x03, 0xdb, 0x66, 0xda, 0x81, 0x74, 0x5b, 0x80, 0x9b, 0x50, 0x12, 0xde,
-	0x86, 0x96, 0x7d, 0x67, 0x4d, 0x59, 0x54, 0xb8, 0x32, 0xdd, 0xdc, 0xc2, 0x06, 0xe4, 0xd9, 0x3d,
-	0xb3, 0x89, 0xbb, 0x8c, 0x8a, 0x54, 0xed, 0xbe, 0xde, 0x09, 0x6d, 0xfb, 0x48, 0x6d, 0x72, 0xcf,
-	0xec, 0x05, 0x77, 0x3c, 0x97, 0xb8, 0x4b, 0x27, 0xf0, 0x5c, 0x61, 0xa0, 0xa9, 0x13, 0xb9, 0x0d,
-	0xb5, 0x7d, 0x04, 0x91, 0x4d, 0x6d, 0xa0, 0x9e, 0x11, 0x1a, 0x67, 0x76, 0x74, 0x35, 0x32, 0xc9,
-	0x39, 0x92, 0xe4, 0xdf, 0xa5, 0x8d, 0xe4, 0xe9, 0xee, 0xd2, 0xb3, 0x2d, 0x21, 0xfd, 0xef, 0xc9,
-	0x6b, 0xc1, 0xb1, 0x33, 0xee, 0x31, 0x97, 0x05, 0x91, 0x43, 0x65, 0x36, 0x4d, 0xa6, 0xed, 0xf1,
-	0xb6, 0xfc, 0x57, 0x0e, 0xd0, 0xda, 0xd5, 0x39, 0x0b, 0x43, 0x91, 0x97, 0xef, 0xb6, 0xa6, 0xea,
-	0xf3, 0x9d, 0x4f, 0x26, 0xbc, 0xcd, 0xc1, 0xfa, 0x11, 0x8a, 0xeb, 0xab, 0xe0, 0x13, 0x4a, 0xf5,
-	0x40, 0x16, 0x93, 0xe2, 0x5b, 0xab, 0x99, 0x67, 0x8d, 0x93, 0x12, 0xa5, 0x50, 0x5c, 0x01, 0xfc,
-	0xde, 0x19, 0x47, 0xb5, 0x29, 0xd2, 0x68, 0x8d, 0xdf, 0x42, 0x75, 0x7d, 0x54, 0x22, 0x2e, 0xa6,
-	0xa8, 0xe3, 0xf7, 0x0d, 0x64, 0x64, 0xa5, 0x8f, 0xd8, 0xf2, 0xdf, 0x99, 0xfd, 0x0d, 0x5f, 0x86,
-	0x02, 0x25, 0x3d, 0x7d, 0x64, 0x12, 0x8a, 0x24, 0x5c, 0x05, 0x48, 0x11, 0xd1, 0x50, 0x46, 0xf4,
-	0xbb, 0x6e, 0xe8, 0x26, 0xca, 0xe2, 0x22, 0x1c, 0x52, 0xa2, 0x68, 0x57, 0x28, 0x87, 0x8f, 0xa1,
-	0x64, 0x52, 0xc5, 0x18, 0x29, 0xaa, 0xa9, 0x0f, 0x0c, 0x74, 0x28, 0x5c, 0xaa, 0x83, 0xf3, 0x61,
-	0x9f, 0x98, 0x44, 0x43, 0x47, 0x82, 0x4a, 0x28, 0x1d, 0x50, 0x94, 0x17, 0x96, 0x1e, 0x31, 0xaf,
-	0x47, 0xa6, 0x62, 0x12, 0x54, 0x10, 0x70, 0x78, 0x91, 0xc2, 0xa2, 0x80, 0x1a, 0xe9, 0x27, 0x10,
-	0x70, 0x0d, 0x90, 0x6e, 0x5c, 0x0e, 0xce, 0xc8, 0xb5, 0xfa, 0x8b, 0xa2, 0x1b, 0xaa, 0x98, 0xbd,
-	0x52, 0x1c, 0xe0, 0x68, 0x38, 0x30, 0x46, 0x04, 0x55, 0xf0, 0x0b, 0x78, 0x46, 0x15, 0xa3, 0x47,
-	0xae, 0x3f, 0x5c, 0x10, 0x7a, 0x95, 0x48, 0xab, 0xb8, 0x01, 0x27, 0x3b, 0xdb, 0xd7, 0x06, 0xf9,
-	0x68, 0xa2, 0x63, 0xfc, 0x7f, 0x38, 0xdd, 0xb5, 0xa9, 0xfd, 0xc1, 0x88, 0x20, 0x24, 0x42, 0x38,
-	0x23, 0x64, 0xa8, 0xf4, 0xf5, 0x4b, 0x82, 0x9e, 0xc9, 0x3f, 0x40, 0x79, 0xb8, 0xe0, 0x23, 0x6e,
-	0x71, 0xa6, 0xbb, 0x13, 0x0f, 0x23, 0xc8, 0xde, 0xb1, 0x55, 0x72, 0x8b, 0x8b, 0x25, 0xae, 0xc1,
-	0xe1, 0xd2, 0x9a, 0x2d, 0xe2, 0xb1, 0x2c, 0xd3, 0x18, 0xc8, 0x04, 0x8e, 0xa9, 0xe5, 0x4e, 0xd9,
-	0x87, 0x05, 0x0b, 0x56, 0x91, 0x5c, 0x5c, 0x4e, 0x21, 0xb7, 0x02, 0x7e, 0xb6, 0xd6, 0xaf, 0x31,
-	0x3e, 0x81, 0x23, 0xe6, 0x8e, 0x85, 0x25, 0xee, 0xce, 0x04, 0xc9, 0x5f, 0xc1, 0xf3, 0x47, 0x6e,
-	0x0c, 0x76, 0xcf, 0x71, 0x15, 0x32, 0xba, 0x96, 0x38, 0xc9, 0xe8, 0x9a, 0xfc, 0x35, 0xd4, 0x1e,
-	0xd1, 0xd4, 0x99, 0x17, 0xb2, 0x1d, 0x9e, 0x02, 0xa7, 0x8f, 0x78, 0x67, 0x6c, 0x75, 0x29, 0x02,
-	0xfe, 0xe4, 0x83, 0xfd, 0x21, 0xed, 0xf8, 0xa0, 0x2c, 0xf4, 0x3d, 0x37, 0x64, 0x98, 0x40, 0xe5,
-	0x8e, 0xad, 0x42, 0xc5, 0x1d, 0x47, 0x3e, 0xe3, 0xbf, 0xac, 0x52, 0xf7, 0x65, 0xda, 0x91, 0x4f,
-	0x7c, 0x9b, 0x6e, 0xab, 0xc4, 0x1c, 0xdc, 0x5a, 0xe1, 0xb9, 0x17, 0xc4, 0x9f, 0x2e, 0xd0, 0x14,
-	0x26, 0xe7, 0xc9, 0xa6, 0xe7, 0xf9, 0xf6, 0x35, 0xd4, 0xf6, 0xfd, 0x3b, 0x88, 0xab, 0x65, 0x78,
-	0xf1, 0xae, 0xaf, 0xab, 0xe8, 0x00, 0x23, 0x28, 0xab, 0x03, 0xe3, 0xbd, 0xae, 0x11, 0xc3, 0xd4,
-	0x95, 0x3e, 0x92, 0xba, 0x1f, 0x37, 0x06, 0x7d, 0xb4, 0xf0, 0x7d, 0x2f, 0xe0, 0x58, 0x83, 0x02,
-	0x65, 0x53, 0x27, 0xe4, 0x2c, 0xc0, 0xf5, 0xa7, 0xc6, 0xbc, 0xf1, 0xa4, 0x45, 0x3e, 0x68, 0x49,
-	0xaf, 0xa4, 0x77, 0x2a, 0x9c, 0x78, 0xc1, 0xb4, 0x7d, 0xbb, 0xf2, 0x59, 0x30, 0x63, 0xe3, 0x29,
-	0x0b, 0x12, 0xc1, 0x6f, 0xdf, 0x4c, 0x1d, 0x7e, 0xbb, 0xb8, 0x69, 0xdb, 0xde, 0xbc, 0xb3, 0x61,
-	0xee, 0x4c, 0xac, 0x9b, 0xc0, 0xb1, 0xe3, 0x57, 0x44, 0xd8, 0x11, 0xcf, 0x8d, 0x9b, 0xf8, 0xf1,
-	0xf1, 0xfd, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x5c, 0x4e, 0xfd, 0x73, 0x9b, 0x08, 0x00, 0x00,
+	// 1098 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0x0e, 0x25, 0xd9, 0x92, 0x46, 0xb2, 0xbc, 0xd9, 0x28, 0x0e, 0xab, 0xfe, 0xc4, 0x20, 0xda,
+	0x42, 0xed, 0x41, 0x4e, 0xd5, 0xb4, 0x28, 0x50, 0x20, 0x28, 0x43, 0x6e, 0x5c, 0xd6, 0x32, 0xa5,
+	0x2c, 0x69, 0x23, 0xe9, 0xc5, 0xa0, 0xa9, 0x15, 0x4d, 0x44, 0x22, 0x09, 0x72, 0x25, 0x58, 0xb7,
+	0x9e, 0x7b, 0xea, 0x3b, 0xf4, 0x2d, 0xfa, 0x02, 0x7d, 0xad, 0x62, 0xf9, 0x23, 0x4b, 0x96, 0x0c,
+	0x04, 0xe8, 0x89, 0xfb, 0xcd, 0x7c, 0x33, 0x3b, 0x3b, 0xb3, 0x33, 0x5c, 0x68, 0x47, 0x8c, 0xc5,
+	0x27, 0xee, 0x8d, 0xe3, 0x07, 0x6e, 0x38, 0x66, 0xbd, 0x28, 0x0e, 0x79, 0x88, 0xf7, 0xd3, 0x4f,
+	0xd2, 0xf9, 0x64, 0x53, 0xcb, 0x16, 0x2c, 0xe0, 0x19, 0xa5, 0xd3, 0x49, 0x55, 0x13, 0xe7, 0x3a,
+	0xf6, 0xdd, 0xab, 0x28, 0x0e, 0xa3, 0x30, 0x71, 0xa6, 0xb9, 0xee, 0xb9, 0x17, 0x86, 0xde, 0x94,
+	0x9d, 0xa4, 0xe8, 0x7a, 0x3e, 0x39, 0xe1, 0xfe, 0x8c, 0x25, 0xdc, 0x99, 0x45, 0x19, 0x41, 0x19,
+	0x42, 0x43, 0x2b, 0x9c, 0x1a, 0x3a, 0xc6, 0x50, 0x89, 0x1c, 0x7e, 0x23, 0x4b, 0xc7, 0x52, 0xb7,
+	0x4e, 0xd3, 0xb5, 0x90, 0x05, 0xce, 0x8c, 0xc9, 0xa5, 0x4c, 0x26, 0xd6, 0x58, 0x86, 0xea, 0x82,
+	0xc5, 0x89, 0x1f, 0x06, 0x72, 0x39, 0x15, 0x17, 0x50, 0xf9, 0x12, 0x5a, 0x77, 0x0e, 0x83, 0x68,
+	0xce, 0x85, 0xbd, 0x13, 0x7b, 0x89, 0x2c, 0x1d, 0x97, 0xbb, 0x4d, 0x9a, 0xae, 0x95, 0xbf, 0xca,
+	0x70, 0xb0, 0xa2, 0x59, 0x11, 0x73, 0x71, 0x0f, 0x2a, 0x7c, 0x19, 0xb1, 0x74, 0xe7, 0x56, 0xbf,
+	0x93, 0x85, 0x97, 0xf4, 0x36, 0x48, 0x3d, 0x7b, 0x19, 0x31, 0x9a, 0xf2, 0xf0, 0x0f, 0xd0, 0x70,
+	0xef, 0x02, 0x4f, 0x83, 0x6b, 0xf4, 0x9f, 0x6c, 0x99, 0x19, 0x3a, 0x5d, 0xe7, 0xe1, 0x17, 0x50,
+	0x75, 0x79, 0x18, 0x9f, 0x27, 0x5e, 0x1a, 0x78, 0xa3, 0x7f, 0xb4, 0x6d, 0x22, 0xa2, 0xa6, 0x05,
+	0x4d, 0x1c, 0x55, 0x24, 0x2d, 0x9c, 0x73, 0xb9, 0x72, 0x2c, 0x75, 0xf7, 0x68, 0x01, 0xf1, 0x08,
+	0xda, 0x6e, 0x18, 0x4c, 0xfc, 0x31, 0x0b, 0xb8, 0xef, 0x4c, 0x7d, 0xbe, 0x1c, 0xb0, 0x05, 0x9b,
+	0xca, 0x7b, 0xe9, 0x11, 0x3e, 0x5b, 0x39, 0xde, 0xc1, 0xa1, 0x3b, 0x2d, 0x71, 0x07, 0x6a, 0x33,
+	0xc6, 0x9d, 0xb1, 0xc3, 0x1d, 0x79, 0xff, 0x58, 0xea, 0x36, 0xe9, 0x0a, 0xe3, 0x2f, 0x00, 0x1c,
+	0xce, 0x63, 0xff, 0x7a, 0xce, 0x59, 0x22, 0x57, 0x8f, 0xcb, 0xdd, 0x3a, 0x5d, 0x93, 0x28, 0xaf,
+	0xa0, 0x22, 0xd2, 0x83, 0x0f, 0xa0, 0x7e, 0x61, 0xea, 0xe4, 0x8d, 0x61, 0x12, 0x1d, 0x3d, 0xc2,
+	0x00, 0xfb, 0xa7, 0xc3, 0x81, 0x6a, 0x9e, 0x22, 0x09, 0xd7, 0xa0, 0x62, 0x0e, 0x75, 0x82, 0x4a,
+	0xb8, 0x0a, 0x65, 0x4d, 0xa5, 0xa8, 0x2c, 0x44, 0xbf, 0xa9, 0x97, 0x2a, 0xaa, 0x28, 0xff, 0x94,
+	0xe0, 0xd9, 0x2a, 0x07, 0x3a, 0x8b, 0xa6, 0xe1, 0x72, 0xc6, 0x02, 0x9e, 0x16, 0xe7, 0x67, 0x38,
+	0x70, 0xd7, 0x0b, 0x91, 0x56, 0xa9, 0xd1, 0x7f, 0xba, 0xb3, 0x4a, 0x74, 0x93, 0x8b, 0x7f, 0x81,
+	0x03, 0x36, 0x99, 0x30, 0x97, 0xfb, 0x0b, 0xa6, 0x3b, 0x9c, 0xe5, 0xb5, 0xea, 0xf4, 0xb2, 0xbb,
+	0xd9, 0x2b, 0xee, 0x66, 0xcf, 0x2e, 0xee, 0x26, 0xdd, 0x34, 0xc0, 0xc7, 0xd0, 0x10, 0xde, 0x46,
+	0x8e, 0xfb, 0xc1, 0xf1, 0x58, 0x5a, 0xb8, 0x26, 0x5d, 0x17, 0x61, 0x13, 0xaa, 0xec, 0x96, 0xb9,
+	0x24, 0x58, 0xa4, 0x45, 0x6a, 0xf5, 0x5f, 0x6e, 0x85, 0xb6, 0x79, 0xa4, 0x1e, 0xb9, 0x65, 0xee,
+	0x9c, 0xfb, 0x61, 0x40, 0x82, 0x85, 0x1f, 0x87, 0x81, 0x50, 0xd0, 0xc2, 0x89, 0xd2, 0x83, 0xf6,
+	0x2e, 0x82, 0xc8, 0xa6, 0x3e, 0xd4, 0xce, 0x08, 0xcd, 0x32, 0x6b, 0xbd, 0xb7, 0x6c, 0x72, 0x8e,
+	0x24, 0xe5, 0x0f, 0x69, 0x2d, 0x79, 0x46, 0xb0, 0x08, 0x5d, 0x47, 0x98, 0xfe, 0xff, 0xe4, 0x75,
+	0xe1, 0xd0, 0x1f, 0x9f, 0xb2, 0x80, 0xc5, 0xa9, 0x43, 0x75, 0xea, 0xe5, 0x7d, 0x78, 0x5f, 0xac,
+	0xfc, 0x5b, 0x01, 0xb4, 0x72, 0x75, 0xce, 0x92, 0x44, 0xe4, 0xe5, 0xbb, 0x8d, 0xae, 0xfa, 0x7c,
+	0x6b, 0xcb, 0x9c, 0xb7, 0xde, 0x58, 0x3f, 0x41, 0x7d, 0x35, 0x24, 0x3e, 0xa2, 0x54, 0x77, 0x64,
+	0xd1, 0x29, 0x91, 0xb3, 0x9c, 0x86, 0xce, 0x38, 0x2f, 0x51, 0x01, 0xc5, 0x08, 0xe0, 0xb7, 0xfe,
+	0x38, 0xad, 0x4d, 0x9d, 0xa6, 0x6b, 0xfc, 0x0a, 0x5a, 0xab, 0xa3, 0x12, 0x31, 0xce, 0xe4, 0xfd,
+	0x07, 0x1a, 0x32, 0xd5, 0xd2, 0x7b, 0x6c, 0xdc, 0x87, 0x5a, 0x31, 0xec, 0xe4, 0xea, 0xa6, 0xa5,
+	0xe5, 0x7b, 0x01, 0x1b, 0x8f, 0x72, 0x2d, 0x5d, 0xf1, 0x94, 0xbf, 0x4b, 0xbb, 0x9b, 0xa4, 0x09,
+	0x35, 0x4a, 0x4e, 0x0d, 0xcb, 0x26, 0x14, 0x49, 0xb8, 0x05, 0x50, 0x20, 0xa2, 0xa3, 0x92, 0xe8,
+	0x11, 0xc3, 0x34, 0x6c, 0x54, 0xc6, 0x75, 0xd8, 0xa3, 0x44, 0xd5, 0xdf, 0xa3, 0x0a, 0x3e, 0x84,
+	0x86, 0x4d, 0x55, 0xd3, 0x52, 0x35, 0xdb, 0x18, 0x9a, 0x68, 0x4f, 0xb8, 0xd4, 0x86, 0xe7, 0xa3,
+	0x01, 0xb1, 0x89, 0x8e, 0xf6, 0x05, 0x95, 0x50, 0x3a, 0xa4, 0xa8, 0x2a, 0x34, 0xa7, 0xc4, 0xbe,
+	0xb2, 0x6c, 0xd5, 0x26, 0xa8, 0x26, 0xe0, 0xe8, 0xa2, 0x80, 0x75, 0x01, 0x75, 0x32, 0xc8, 0x21,
+	0xe0, 0x36, 0x20, 0xc3, 0xbc, 0x1c, 0x9e, 0x91, 0x2b, 0xed, 0x57, 0xd5, 0x30, 0x35, 0xd1, 0xaf,
+	0x8d, 0x2c, 0x40, 0x6b, 0x34, 0x34, 0x2d, 0x82, 0x0e, 0xf0, 0x53, 0x78, 0x4c, 0x55, 0xf3, 0x94,
+	0x5c, 0xbd, 0xbd, 0x20, 0xf4, 0x7d, 0x6e, 0xda, 0xc2, 0x1d, 0x38, 0xda, 0x12, 0x5f, 0x99, 0xe4,
+	0x9d, 0x8d, 0x0e, 0xf1, 0xa7, 0xf0, 0x6c, 0x5b, 0xa7, 0x0d, 0x86, 0x16, 0x41, 0x48, 0x84, 0x70,
+	0x46, 0xc8, 0x48, 0x1d, 0x18, 0x97, 0x04, 0x3d, 0x56, 0x7e, 0x84, 0xe6, 0x68, 0xce, 0x2d, 0xee,
+	0x70, 0x66, 0x04, 0x93, 0x10, 0x23, 0x28, 0x7f, 0x60, 0xcb, 0xfc, 0x9f, 0x20, 0x96, 0xb8, 0x0d,
+	0x7b, 0x0b, 0x67, 0x3a, 0xcf, 0x5a, 0xb9, 0x49, 0x33, 0xa0, 0x10, 0x38, 0xa4, 0x4e, 0xe0, 0xb1,
+	0xb7, 0x73, 0x16, 0x2f, 0x53, 0x73, 0x31, 0xd0, 0x12, 0xee, 0xc4, 0xfc, 0x6c, 0x65, 0xbf, 0xc2,
+	0xf8, 0x08, 0xf6, 0x59, 0x30, 0x16, 0x9a, 0xec, 0x46, 0xe7, 0x48, 0xf9, 0x0a, 0x9e, 0xdc, 0x73,
+	0x63, 0xb2, 0x5b, 0x8e, 0x5b, 0x50, 0x32, 0xf4, 0xdc, 0x49, 0xc9, 0xd0, 0x95, 0xaf, 0xa1, 0x7d,
+	0x8f, 0xa6, 0x4d, 0xc3, 0x84, 0x6d, 0xf1, 0x54, 0x78, 0x76, 0x8f, 0x77, 0xc6, 0x96, 0x97, 0x22,
+	0xe0, 0x8f, 0x3e, 0xd8, 0x9f, 0xd2, 0x96, 0x0f, 0xca, 0x92, 0x28, 0x0c, 0x12, 0x86, 0x09, 0x1c,
+	0x7c, 0x60, 0xcb, 0x44, 0x0d, 0xc6, 0xa9, 0xcf, 0xec, 0x37, 0xd7, 0xe8, 0x3f, 0x2f, 0xee, 0xe2,
+	0x03, 0x7b, 0xd3, 0x4d, 0x2b, 0xd1, 0x3b, 0x37, 0x4e, 0x72, 0x1e, 0xc6, 0xd9, 0xd6, 0x35, 0x5a,
+	0xc0, 0xfc, 0x3c, 0xe5, 0xe2, 0x3c, 0xdf, 0xbe, 0x84, 0xf6, 0xae, 0x3f, 0x8a, 0x18, 0x47, 0xa3,
+	0x8b, 0xd7, 0x03, 0x43, 0x43, 0x8f, 0x30, 0x82, 0xa6, 0x36, 0x34, 0xdf, 0x18, 0x3a, 0x31, 0x6d,
+	0x43, 0x1d, 0x20, 0xa9, 0xff, 0x6e, 0x6d, 0x38, 0x58, 0xf3, 0x28, 0x0a, 0x63, 0x8e, 0x75, 0xa8,
+	0x51, 0xe6, 0xf9, 0x09, 0x67, 0x31, 0x96, 0x1f, 0x1a, 0x0d, 0x9d, 0x07, 0x35, 0xca, 0xa3, 0xae,
+	0xf4, 0x42, 0x7a, 0xad, 0xc1, 0x51, 0x18, 0x7b, 0xbd, 0x9b, 0x65, 0xc4, 0xe2, 0x29, 0x1b, 0x7b,
+	0x2c, 0xce, 0x0d, 0x7e, 0xff, 0xc6, 0xf3, 0xf9, 0xcd, 0xfc, 0xba, 0xe7, 0x86, 0xb3, 0x93, 0x35,
+	0x75, 0xfe, 0x54, 0xc9, 0xde, 0x24, 0xc9, 0x89, 0x78, 0xbd, 0x5c, 0x67, 0xcf, 0x9c, 0xef, 0xff,
+	0x1b, 0x00, 0xcc, 0x9c, 0xe5, 0xdd, 0x05, 0x09, 0x00, 0x00,
 }
@@ -73,6 +73,9 @@ type ChaincodeAction struct {
 	// This field contains the events generated by the chaincode executing this
 	// invocation.
 	Events []byte `protobuf:"bytes,2,opt,name=events,proto3" json:"events,omitempty"`
+	// This field contains the result of executing this invocation. It is
+	// endorsed along with the results and the events.
+	Response *Response `protobuf:"bytes,3,opt,name=response" json:"response,omitempty"`
 }
 
 func (m *ChaincodeAction) Reset()                    { *m = ChaincodeAction{} }
@@ -80,6 +83,13 @@ func (m *ChaincodeAction) String() string            { return proto.CompactTextS
 func (*ChaincodeAction) ProtoMessage()               {}
 func (*ChaincodeAction) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
 
+func (m *ChaincodeAction) GetResponse() *Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ChaincodeHeaderExtension)(nil), "protos.ChaincodeHeaderExtension")
 	proto.RegisterType((*ChaincodeProposalPayload)(nil), "protos.ChaincodeProposalPayload")
@@ -89,22 +99,24 @@ func init() {
 func init() { proto.RegisterFile("peer/chaincode_proposal.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 264 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x64, 0x90, 0xd1, 0x4b, 0xc3, 0x30,
-	0x10, 0xc6, 0xa9, 0xe0, 0xc4, 0x6c, 0x20, 0xc6, 0x21, 0x45, 0x14, 0x46, 0x9f, 0x26, 0x4a, 0x03,
-	0x8a, 0x7f, 0x80, 0x56, 0xc1, 0xbd, 0xc8, 0x28, 0xe2, 0x83, 0x2f, 0x92, 0xa6, 0xe7, 0x1a, 0x88,
-	0x49, 0x48, 0xae, 0x62, 0x9f, 0xfc, 0xd7, 0x65, 0x4b, 0xb6, 0x55, 0xf6, 0x74, 0x7c, 0xf7, 0x1d,
-	0xbf, 0xef, 0xee, 0xc8, 0x85, 0x05, 0x70, 0x4c, 0x34, 0x5c, 0x6a, 0x61, 0x6a, 0xf8, 0xb0, 0xce,
-	0x58, 0xe3, 0xb9, 0xca, 0xad, 0x33, 0x68, 0xe8, 0x60, 0x55, 0xfc, 0xd9, 0xf8, 0xff, 0x58, 0x70,
-	0xb3, 0x5f, 0x92, 0x16, 0xeb, 0xd6, 0x33, 0xf0, 0x1a, 0xdc, 0xd3, 0x0f, 0x82, 0xf6, 0xd2, 0x68,
-	0x7a, 0x4d, 0x8e, 0x2d, 0xef, 0x94, 0xe1, 0xf5, 0x9b, 0xf4, 0xb2, 0x92, 0x4a, 0x62, 0x97, 0x26,
-	0x93, 0x64, 0x3a, 0x2a, 0x77, 0x0d, 0x7a, 0x47, 0x86, 0x1b, 0xf8, 0xec, 0x31, 0xdd, 0x9b, 0x24,
-	0xd3, 0xe1, 0xcd, 0x49, 0x88, 0xf1, 0x79, 0xb1, 0xb5, 0xca, 0xfe, 0x5c, 0xf6, 0xd2, 0x5b, 0x60,
-	0x1e, 0x37, 0x9f, 0x07, 0x38, 0x1d, 0x93, 0xfd, 0x99, 0xb6, 0x2d, 0xc6, 0xd0, 0x20, 0xe8, 0x39,
-	0x39, 0x7c, 0x75, 0x5c, 0x7b, 0x09, 0x1a, 0x57, 0x31, 0xa3, 0x72, 0xdb, 0xc8, 0x0a, 0x72, 0xb4,
-	0xe1, 0xdd, 0x0b, 0x5c, 0xde, 0x91, 0x92, 0x03, 0x07, 0xbe, 0x55, 0xe8, 0x23, 0x68, 0x2d, 0xe9,
-	0x29, 0x19, 0xc0, 0x37, 0x68, 0xf4, 0x91, 0x13, 0xd5, 0xc3, 0xd5, 0xfb, 0xe5, 0x42, 0x62, 0xd3,
-	0x56, 0xb9, 0x30, 0x5f, 0xac, 0xe9, 0x2c, 0x38, 0x05, 0xf5, 0x02, 0x1c, 0xfb, 0xe4, 0x95, 0x93,
-	0x82, 0x85, 0xab, 0xd8, 0xf2, 0xa5, 0x55, 0x78, 0xf0, 0xed, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0x97, 0xb9, 0x42, 0xce, 0x88, 0x01, 0x00, 0x00,
+	// 292 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x51, 0xdf, 0x4b, 0xfb, 0x30,
+	0x10, 0xa7, 0xdf, 0x2f, 0x4e, 0xcd, 0x06, 0x6a, 0x1c, 0x52, 0x86, 0xc2, 0xa8, 0x2f, 0x13, 0x47,
+	0x0b, 0x8a, 0x7f, 0x80, 0x4e, 0xc1, 0xbd, 0xc8, 0x28, 0xe2, 0x83, 0x2f, 0x23, 0x6d, 0xcf, 0x35,
+	0x50, 0x93, 0x98, 0x4b, 0xc5, 0x3e, 0xf9, 0xaf, 0xcb, 0x9a, 0x34, 0x9b, 0xf8, 0x14, 0xee, 0xee,
+	0x73, 0x9f, 0x1f, 0x39, 0x72, 0xa6, 0x00, 0x74, 0x92, 0x97, 0x8c, 0x8b, 0x5c, 0x16, 0xb0, 0x54,
+	0x5a, 0x2a, 0x89, 0xac, 0x8a, 0x95, 0x96, 0x46, 0xd2, 0x5e, 0xfb, 0xe0, 0x68, 0xf8, 0x1b, 0x66,
+	0xa7, 0xa3, 0xf3, 0xb6, 0xfb, 0xc6, 0x32, 0xcd, 0x73, 0xbf, 0xb9, 0xd4, 0x80, 0x4a, 0x0a, 0x74,
+	0xa0, 0xe8, 0x9b, 0x84, 0xb3, 0x6e, 0xef, 0x11, 0x58, 0x01, 0xfa, 0xe1, 0xcb, 0x80, 0x40, 0x2e,
+	0x05, 0x9d, 0x92, 0x23, 0xc5, 0x9a, 0x4a, 0xb2, 0xe2, 0x85, 0x23, 0xcf, 0x78, 0xc5, 0x4d, 0x13,
+	0x06, 0xe3, 0x60, 0x32, 0x48, 0xff, 0x0e, 0xe8, 0x0d, 0xe9, 0x7b, 0x07, 0xf3, 0xfb, 0xf0, 0xdf,
+	0x38, 0x98, 0xf4, 0xaf, 0x8e, 0xad, 0x0c, 0xc6, 0xb3, 0xcd, 0x28, 0xdd, 0xc6, 0x45, 0x4f, 0x5b,
+	0x06, 0x16, 0xce, 0xe4, 0xc2, 0x92, 0xd3, 0x21, 0xd9, 0x99, 0x0b, 0x55, 0x1b, 0x27, 0x6a, 0x0b,
+	0x7a, 0x4a, 0xf6, 0x9f, 0x35, 0x13, 0xc8, 0x41, 0x98, 0x56, 0x66, 0x90, 0x6e, 0x1a, 0xd1, 0x07,
+	0x39, 0xf0, 0x7c, 0xb7, 0xb9, 0x59, 0xe7, 0x08, 0xc9, 0xae, 0x06, 0xac, 0x2b, 0x83, 0x8e, 0xa8,
+	0x2b, 0xe9, 0x09, 0xe9, 0xc1, 0x27, 0x08, 0x83, 0x8e, 0xc7, 0x55, 0x74, 0x4a, 0xf6, 0xba, 0x7f,
+	0x0a, 0xff, 0xb7, 0x41, 0x0e, 0xbb, 0x20, 0xa9, 0xeb, 0xa7, 0x1e, 0x71, 0x77, 0xf9, 0x7a, 0xb1,
+	0xe2, 0xa6, 0xac, 0xb3, 0x38, 0x97, 0xef, 0x49, 0xd9, 0x28, 0xd0, 0x15, 0x14, 0x2b, 0xff, 0xf9,
+	0x89, 0x5d, 0x4d, 0xd6, 0xf7, 0xc8, 0xec, 0xcd, 0xae, 0x7f, 0x06, 0x00, 0x39, 0xf0, 0x61, 0xa6,
+	0xdb, 0x01, 0x00, 0x00,
 }
@@ -53,6 +53,10 @@ type ChaincodeEndorsedAction struct {
 	// The endorsement of the proposal, basically the endorser's signature over
 	// proposalResponsePayload
 	Endorsements []*Endorsement `protobuf:"bytes,2,rep,name=endorsements" json:"endorsements,omitempty"`
+	// The values of the writes that were replaced by their hashes in the
+	// results of the ChaincodeAction, as a serialized kvrwset.HashedValues
+	// message. It is copied from the ProposalResponse
+	HashedValues []byte `protobuf:"bytes,3,opt,name=hashedValues,proto3" json:"hashedValues,omitempty"`
 }
 
 func (m *ChaincodeEndorsedAction) Reset()                    { *m = ChaincodeEndorsedAction{} }
@@ -75,21 +79,22 @@ func init() {
 func init() { proto.RegisterFile("peer/chaincode_transaction.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 248 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x90, 0x31, 0x4f, 0xc3, 0x30,
-	0x14, 0x84, 0xe5, 0x22, 0x75, 0x70, 0x3b, 0x19, 0x89, 0x46, 0x2c, 0x44, 0x65, 0x09, 0x42, 0x4a,
-	0xa4, 0x32, 0x14, 0xb1, 0x01, 0xea, 0x5e, 0x65, 0x64, 0x89, 0x1c, 0xfb, 0xd1, 0x44, 0x4a, 0xfd,
-	0x2c, 0x3f, 0x33, 0xf4, 0x3f, 0xc0, 0x7f, 0x46, 0xc4, 0x4e, 0x50, 0x86, 0x4c, 0x1e, 0xee, 0xf3,
-	0xdd, 0xbd, 0xe3, 0xa9, 0x05, 0x70, 0x85, 0x6a, 0x64, 0x6b, 0x14, 0x6a, 0xa8, 0xbc, 0x93, 0x86,
-	0xa4, 0xf2, 0x2d, 0x9a, 0xdc, 0x3a, 0xf4, 0x28, 0x96, 0xfd, 0x43, 0xb7, 0xf7, 0x3d, 0xf9, 0x29,
-	0x6b, 0xd7, 0xaa, 0xca, 0x3a, 0xb4, 0x48, 0xb2, 0xab, 0x1c, 0x90, 0x45, 0x43, 0x10, 0xe0, 0xed,
-	0x0f, 0xe3, 0x37, 0xef, 0x83, 0xd9, 0x6b, 0x6f, 0x73, 0x94, 0x97, 0x0e, 0xa5, 0x16, 0x2f, 0x3c,
-	0x19, 0x63, 0x8e, 0xf1, 0x7b, 0xd4, 0x12, 0x96, 0xb2, 0x6c, 0x5d, 0xce, 0xea, 0x62, 0xcf, 0x97,
-	0xa1, 0x53, 0xb2, 0x48, 0x59, 0xb6, 0xda, 0xdd, 0x85, 0x38, 0xca, 0xc7, 0xac, 0x83, 0xd1, 0xe8,
-	0x08, 0x74, 0xc8, 0x2c, 0x23, 0xbe, 0xfd, 0x66, 0x7c, 0x33, 0xc3, 0x88, 0x67, 0xbe, 0x19, 0xce,
-	0x28, 0xe3, 0x15, 0xd3, 0x3e, 0x73, 0xb2, 0xd8, 0xf3, 0x35, 0x04, 0xaf, 0x33, 0x18, 0x4f, 0xc9,
-	0x22, 0xbd, 0xca, 0x56, 0xbb, 0xeb, 0xa1, 0xd4, 0xe1, 0x5f, 0x2b, 0x27, 0xe0, 0xdb, 0xe3, 0xc7,
-	0xc3, 0xa9, 0xf5, 0xcd, 0x57, 0x9d, 0x2b, 0x3c, 0x17, 0xcd, 0xc5, 0x82, 0xeb, 0x40, 0x9f, 0xc6,
-	0x5d, 0x8b, 0xe0, 0x50, 0xfc, 0x4d, 0x5d, 0x87, 0xe1, 0x9f, 0x7e, 0x03, 0x00, 0x00, 0xff, 0xff,
-	0xcd, 0xa1, 0x56, 0xa7, 0xa3, 0x01, 0x00, 0x00,
+	// 265 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x31, 0x6b, 0xc3, 0x30,
+	0x10, 0x85, 0x71, 0x02, 0x19, 0x14, 0x4f, 0x2a, 0x34, 0xa6, 0x4b, 0x8d, 0xbb, 0xb8, 0x14, 0x6c,
+	0x48, 0x87, 0x94, 0x6e, 0x6d, 0xc9, 0x1e, 0x3c, 0x74, 0xe8, 0x62, 0x64, 0xeb, 0x1a, 0x19, 0x1c,
+	0x9d, 0xd0, 0x29, 0x43, 0x7e, 0x44, 0xff, 0x4b, 0x7f, 0x62, 0xa9, 0x65, 0x3b, 0x78, 0xf0, 0x24,
+	0xd0, 0x7b, 0x7a, 0xdf, 0x3b, 0x1d, 0x8b, 0x0d, 0x80, 0xcd, 0x6b, 0x25, 0x1a, 0x5d, 0xa3, 0x84,
+	0xd2, 0x59, 0xa1, 0x49, 0xd4, 0xae, 0x41, 0x9d, 0x19, 0x8b, 0x0e, 0xf9, 0xaa, 0x3b, 0xe8, 0xee,
+	0xa1, 0x73, 0x7e, 0x8b, 0xca, 0x36, 0x75, 0x69, 0x2c, 0x1a, 0x24, 0xd1, 0x96, 0x16, 0xc8, 0xa0,
+	0x26, 0xf0, 0xe6, 0xe4, 0x27, 0x60, 0xb7, 0x1f, 0x43, 0xd8, 0x5b, 0x17, 0x73, 0x10, 0x97, 0x16,
+	0x85, 0xe4, 0xaf, 0x2c, 0x1a, 0x31, 0x87, 0xfe, 0x79, 0xaf, 0x45, 0x41, 0x1c, 0xa4, 0x61, 0x31,
+	0xab, 0xf3, 0x1d, 0x5b, 0xf9, 0x4e, 0xd1, 0x22, 0x0e, 0xd2, 0xf5, 0xf6, 0xde, 0xe3, 0x28, 0x1b,
+	0x59, 0x7b, 0x2d, 0xd1, 0x12, 0x48, 0xcf, 0x2c, 0x7a, 0x7b, 0xf2, 0x1b, 0xb0, 0xcd, 0x8c, 0x87,
+	0xbf, 0xb0, 0xcd, 0x30, 0x46, 0xd1, 0x4f, 0x31, 0xed, 0x33, 0x27, 0xf3, 0x1d, 0x0b, 0xc1, 0x67,
+	0x9d, 0x40, 0x3b, 0x8a, 0x16, 0xf1, 0x32, 0x5d, 0x6f, 0x6f, 0x86, 0x52, 0xfb, 0xab, 0x56, 0x4c,
+	0x8c, 0x3c, 0x61, 0xa1, 0x12, 0xa4, 0x40, 0x7e, 0x8a, 0xf6, 0x0c, 0x14, 0x2d, 0x3b, 0xce, 0xe4,
+	0xee, 0xfd, 0xe9, 0xeb, 0xf1, 0xd8, 0x38, 0x75, 0xae, 0xb2, 0x1a, 0x4f, 0xb9, 0xba, 0x18, 0xb0,
+	0x2d, 0xc8, 0xe3, 0xf8, 0xf7, 0xb9, 0xa7, 0xe4, 0xff, 0xeb, 0xa8, 0xfc, 0x72, 0x9e, 0xff, 0x06,
+	0x00, 0xed, 0x89, 0x1e, 0x4d, 0xc7, 0x01, 0x00, 0x00,
 }
@@ -36,6 +36,11 @@ type ProposalResponse struct {
 	// The endorsement of the proposal, basically
 	// the endorser's signature over the payload
 	Endorsement *Endorsement `protobuf:"bytes,6,opt,name=endorsement" json:"endorsement,omitempty"`
+	// The values of the writes that were replaced by their hashes in the
+	// simulation results, as a serialized kvrwset.HashedValues message. They
+	// are not covered by the endorsement; the committer checks them against
+	// the endorsed hashes
+	HashedValues []byte `protobuf:"bytes,7,opt,name=hashedValues,proto3" json:"hashedValues,omitempty"`
 }
 
 func (m *ProposalResponse) Reset()                    { *m = ProposalResponse{} }
@@ -147,27 +152,28 @@ func init() {
 func init() { proto.RegisterFile("peer/fabric_proposal_response.proto", fileDescriptor9) }
 
 var fileDescriptor9 = []byte{
-	// 345 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0x52, 0x4d, 0x6b, 0xe3, 0x30,
-	0x10, 0xc5, 0xd9, 0x4d, 0x36, 0x99, 0xe4, 0x10, 0xb4, 0xb0, 0x6b, 0x42, 0xa1, 0xc1, 0xbd, 0xa4,
-	0xb4, 0xd8, 0xd0, 0x52, 0xe8, 0xb9, 0x50, 0xda, 0x63, 0x10, 0xa5, 0x87, 0xf6, 0x10, 0xe4, 0x64,
-	0xe2, 0x18, 0x6c, 0x4b, 0x68, 0xe4, 0xd2, 0xfc, 0xe0, 0xfe, 0x8f, 0x62, 0x59, 0x72, 0x92, 0x9e,
-	0xcc, 0x1b, 0x3f, 0xbd, 0x0f, 0x69, 0xe0, 0x42, 0x21, 0xea, 0x64, 0x2b, 0x52, 0x9d, 0xaf, 0x57,
-	0x4a, 0x4b, 0x25, 0x49, 0x14, 0x2b, 0x8d, 0xa4, 0x64, 0x45, 0x18, 0x2b, 0x2d, 0x8d, 0x64, 0x03,
-	0xfb, 0xa1, 0xd9, 0x79, 0x26, 0x65, 0x56, 0x60, 0x62, 0x61, 0x5a, 0x6f, 0x13, 0x93, 0x97, 0x48,
-	0x46, 0x94, 0xaa, 0x25, 0x46, 0x5f, 0x01, 0x4c, 0x97, 0x4e, 0x84, 0x3b, 0x0d, 0x16, 0xc2, 0x9f,
-	0x0f, 0xd4, 0x94, 0xcb, 0x2a, 0x0c, 0xe6, 0xc1, 0xa2, 0xcf, 0x3d, 0x64, 0xf7, 0x30, 0xea, 0x14,
-	0xc2, 0xde, 0x3c, 0x58, 0x8c, 0x6f, 0x66, 0x71, 0xeb, 0x11, 0x7b, 0x8f, 0xf8, 0xc5, 0x33, 0xf8,
-	0x81, 0xcc, 0xae, 0x61, 0xe8, 0x33, 0x86, 0xbf, 0xed, 0xc1, 0x69, 0x7b, 0x82, 0x62, 0xef, 0xcb,
-	0x3b, 0x46, 0x93, 0x40, 0x89, 0x7d, 0x21, 0xc5, 0x26, 0xec, 0xcf, 0x83, 0xc5, 0x84, 0x7b, 0xc8,
-	0xee, 0x60, 0x8c, 0xd5, 0x46, 0x6a, 0xc2, 0x12, 0x2b, 0x13, 0x0e, 0xac, 0xd4, 0x5f, 0x2f, 0xf5,
-	0x78, 0xf8, 0xc5, 0x8f, 0x79, 0xd1, 0x2b, 0x0c, 0xbb, 0x7a, 0xff, 0x60, 0x40, 0x46, 0x98, 0x9a,
-	0x5c, 0x3b, 0x87, 0x1a, 0xd3, 0x12, 0x89, 0x44, 0x86, 0xb6, 0xda, 0x88, 0x7b, 0x78, 0x1c, 0xe7,
-	0xd7, 0x49, 0x9c, 0xe8, 0x1d, 0xfe, 0xff, 0xbc, 0xbe, 0xa5, 0x4b, 0x1a, 0xc1, 0xc4, 0x3f, 0xcf,
-	0xb3, 0xa0, 0x9d, 0x35, 0x9b, 0xf0, 0x93, 0x19, 0x3b, 0x83, 0x11, 0x7e, 0x1a, 0xac, 0xec, 0x5d,
-	0xf7, 0x2c, 0xe1, 0x30, 0x88, 0x9e, 0x60, 0x7c, 0x54, 0x88, 0xcd, 0x60, 0xe8, 0x2a, 0x69, 0x27,
-	0xd6, 0xe1, 0x46, 0x88, 0xf2, 0xac, 0x12, 0xa6, 0xd6, 0xe8, 0x85, 0xba, 0xc1, 0xc3, 0xd5, 0xdb,
-	0x65, 0x96, 0x9b, 0x5d, 0x9d, 0xc6, 0x6b, 0x59, 0x26, 0xbb, 0xbd, 0x42, 0x5d, 0xe0, 0x26, 0xeb,
-	0xf6, 0xa8, 0xdd, 0x0f, 0x4a, 0x9a, 0xd5, 0x4a, 0xdb, 0xdd, 0xb9, 0xfd, 0x0e, 0x00, 0x00, 0xff,
-	0xff, 0x43, 0xe1, 0xd2, 0xab, 0x69, 0x02, 0x00, 0x00,
+	// 359 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x92, 0x51, 0x4b, 0xfb, 0x30,
+	0x14, 0xc5, 0xe9, 0xfe, 0xff, 0x6d, 0x5d, 0xd6, 0x87, 0x11, 0x41, 0xcb, 0x10, 0x1c, 0xf5, 0x65,
+	0xa2, 0xb4, 0xa0, 0x08, 0x3e, 0x0b, 0xa2, 0x8f, 0x23, 0xc8, 0x1e, 0xf4, 0x61, 0xa4, 0xeb, 0x5d,
+	0x5b, 0x68, 0x9b, 0x90, 0x9b, 0x8a, 0xfb, 0x08, 0x7e, 0x6b, 0x59, 0xda, 0x74, 0x9d, 0x4f, 0xe5,
+	0xdc, 0x9e, 0xfc, 0xee, 0xb9, 0xc9, 0x25, 0xd7, 0x12, 0x40, 0x45, 0x3b, 0x1e, 0xab, 0x7c, 0xbb,
+	0x91, 0x4a, 0x48, 0x81, 0xbc, 0xd8, 0x28, 0x40, 0x29, 0x2a, 0x84, 0x50, 0x2a, 0xa1, 0x05, 0x1d,
+	0x99, 0x0f, 0xce, 0xaf, 0x52, 0x21, 0xd2, 0x02, 0x22, 0x23, 0xe3, 0x7a, 0x17, 0xe9, 0xbc, 0x04,
+	0xd4, 0xbc, 0x94, 0x8d, 0x31, 0xf8, 0x19, 0x90, 0xd9, 0xaa, 0x85, 0xb0, 0x96, 0x41, 0x7d, 0x32,
+	0xfe, 0x02, 0x85, 0xb9, 0xa8, 0x7c, 0x67, 0xe1, 0x2c, 0x87, 0xcc, 0x4a, 0xfa, 0x44, 0x26, 0x1d,
+	0xc1, 0x1f, 0x2c, 0x9c, 0xe5, 0xf4, 0x7e, 0x1e, 0x36, 0x3d, 0x42, 0xdb, 0x23, 0x7c, 0xb7, 0x0e,
+	0x76, 0x34, 0xd3, 0x3b, 0xe2, 0xda, 0x8c, 0xfe, 0x7f, 0x73, 0x70, 0xd6, 0x9c, 0xc0, 0xd0, 0xf6,
+	0x65, 0xae, 0xea, 0x25, 0x90, 0x7c, 0x5f, 0x08, 0x9e, 0xf8, 0xc3, 0x85, 0xb3, 0xf4, 0x98, 0x95,
+	0xf4, 0x91, 0x4c, 0xa1, 0x4a, 0x84, 0x42, 0x28, 0xa1, 0xd2, 0xfe, 0xc8, 0xa0, 0xce, 0x2c, 0xea,
+	0xe5, 0xf8, 0x8b, 0xf5, 0x7d, 0x34, 0x20, 0x5e, 0xc6, 0x31, 0x83, 0x64, 0xcd, 0x8b, 0x1a, 0xd0,
+	0x1f, 0x1b, 0xea, 0x49, 0x2d, 0x58, 0x13, 0xb7, 0xbb, 0x82, 0x73, 0x32, 0x42, 0xcd, 0x75, 0x8d,
+	0xed, 0x0d, 0xb4, 0xea, 0x10, 0xac, 0x04, 0x44, 0x9e, 0x82, 0x19, 0x7f, 0xc2, 0xac, 0xec, 0x47,
+	0xfe, 0x77, 0x12, 0x39, 0xf8, 0x24, 0x17, 0x7f, 0xaf, 0x78, 0xd5, 0x4e, 0x13, 0x10, 0xcf, 0x3e,
+	0xe1, 0x1b, 0xc7, 0xcc, 0x34, 0xf3, 0xd8, 0x49, 0x8d, 0x5e, 0x92, 0x09, 0x7c, 0x6b, 0xa8, 0xcc,
+	0x7b, 0x0c, 0x8c, 0xe1, 0x58, 0x08, 0x5e, 0xc9, 0xb4, 0x37, 0x34, 0x9d, 0x13, 0xb7, 0x1d, 0x5b,
+	0xb5, 0xb0, 0x4e, 0x1f, 0x40, 0x98, 0xa7, 0x15, 0xd7, 0xb5, 0x02, 0x0b, 0xea, 0x0a, 0xcf, 0xb7,
+	0x1f, 0x37, 0x69, 0xae, 0xb3, 0x3a, 0x0e, 0xb7, 0xa2, 0x8c, 0xb2, 0xbd, 0x04, 0x55, 0x40, 0x92,
+	0x76, 0xbb, 0xd6, 0xec, 0x10, 0x46, 0x87, 0xf5, 0x8b, 0x9b, 0xfd, 0x7a, 0xf8, 0x1d, 0x00, 0xad,
+	0xa1, 0x59, 0x96, 0x8d, 0x02, 0x00, 0x00,
 }
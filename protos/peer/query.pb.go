@@ -0,0 +1,108 @@
+// Code generated by protoc-gen-go.
+// source: peer/query.proto
+// DO NOT EDIT!
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// ChannelQueryResponse returns information about the channels a peer has
+// joined
+type ChannelQueryResponse struct {
+	Channels []*ChannelInfo `protobuf:"bytes,1,rep,name=channels" json:"channels,omitempty"`
+}
+
+func (m *ChannelQueryResponse) Reset()                    { *m = ChannelQueryResponse{} }
+func (m *ChannelQueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChannelQueryResponse) ProtoMessage()               {}
+func (*ChannelQueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{0} }
+
+func (m *ChannelQueryResponse) GetChannels() []*ChannelInfo {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+// ChannelInfo contains general information about a channel
+type ChannelInfo struct {
+	ChannelID string `protobuf:"bytes,1,opt,name=channelID" json:"channelID,omitempty"`
+}
+
+func (m *ChannelInfo) Reset()                    { *m = ChannelInfo{} }
+func (m *ChannelInfo) String() string            { return proto.CompactTextString(m) }
+func (*ChannelInfo) ProtoMessage()               {}
+func (*ChannelInfo) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{1} }
+
+// ChaincodeQueryResponse returns information about the chaincodes
+// instantiated on a channel or installed on a peer
+type ChaincodeQueryResponse struct {
+	Chaincodes []*ChaincodeInfo `protobuf:"bytes,1,rep,name=chaincodes" json:"chaincodes,omitempty"`
+}
+
+func (m *ChaincodeQueryResponse) Reset()                    { *m = ChaincodeQueryResponse{} }
+func (m *ChaincodeQueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeQueryResponse) ProtoMessage()               {}
+func (*ChaincodeQueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{2} }
+
+func (m *ChaincodeQueryResponse) GetChaincodes() []*ChaincodeInfo {
+	if m != nil {
+		return m.Chaincodes
+	}
+	return nil
+}
+
+// ChaincodeInfo contains general information about an instantiated or
+// installed chaincode. The path is only known for installed chaincodes,
+// escc and vscc only for instantiated ones
+type ChaincodeInfo struct {
+	Name    string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	Path    string `protobuf:"bytes,3,opt,name=path" json:"path,omitempty"`
+	// hash of the chaincode code package
+	Id   []byte `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`
+	Escc string `protobuf:"bytes,5,opt,name=escc" json:"escc,omitempty"`
+	Vscc string `protobuf:"bytes,6,opt,name=vscc" json:"vscc,omitempty"`
+}
+
+func (m *ChaincodeInfo) Reset()                    { *m = ChaincodeInfo{} }
+func (m *ChaincodeInfo) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeInfo) ProtoMessage()               {}
+func (*ChaincodeInfo) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{3} }
+
+func init() {
+	proto.RegisterType((*ChannelQueryResponse)(nil), "protos.ChannelQueryResponse")
+	proto.RegisterType((*ChannelInfo)(nil), "protos.ChannelInfo")
+	proto.RegisterType((*ChaincodeQueryResponse)(nil), "protos.ChaincodeQueryResponse")
+	proto.RegisterType((*ChaincodeInfo)(nil), "protos.ChaincodeInfo")
+}
+
+func init() { proto.RegisterFile("peer/query.proto", fileDescriptor12) }
+
+var fileDescriptor12 = []byte{
+	// 267 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x91, 0x4f, 0x4b, 0xc3, 0x30,
+	0x18, 0xc6, 0x69, 0x37, 0xa7, 0x7b, 0xa7, 0x22, 0xf1, 0x0f, 0x39, 0x78, 0x28, 0x3d, 0x55, 0x06,
+	0x0d, 0x28, 0x7e, 0x01, 0x27, 0xc8, 0x4e, 0x62, 0x8f, 0xde, 0xda, 0xf4, 0xdd, 0x12, 0xd8, 0x92,
+	0x98, 0x74, 0x83, 0x7d, 0x04, 0xbf, 0xb5, 0xa4, 0x69, 0x5d, 0x77, 0xea, 0xd3, 0xe7, 0xf7, 0x2b,
+	0x0f, 0xe5, 0x85, 0x1b, 0x83, 0x68, 0xd9, 0xcf, 0x0e, 0xed, 0x21, 0x37, 0x56, 0x37, 0x9a, 0x4c,
+	0xda, 0x87, 0x4b, 0x3f, 0xe0, 0x6e, 0x21, 0x4a, 0xa5, 0x70, 0xf3, 0xe5, 0x69, 0x81, 0xce, 0x68,
+	0xe5, 0x90, 0x30, 0xb8, 0xe0, 0xa1, 0x77, 0x34, 0x4a, 0x46, 0xd9, 0xec, 0xf9, 0x36, 0x7c, 0xe9,
+	0xf2, 0xce, 0x5f, 0xaa, 0x95, 0x2e, 0xfe, 0xa5, 0x74, 0x0e, 0xb3, 0x01, 0x20, 0x8f, 0x30, 0xed,
+	0xd0, 0xf2, 0x9d, 0x46, 0x49, 0x94, 0x4d, 0x8b, 0x63, 0x91, 0x7e, 0xc2, 0xc3, 0x42, 0x94, 0x52,
+	0x71, 0x5d, 0xe3, 0xe9, 0xee, 0x2b, 0x00, 0xef, 0x49, 0xbf, 0x7c, 0x3f, 0x58, 0x0e, 0xa4, 0xdd,
+	0x1e, 0x88, 0xe9, 0x6f, 0x04, 0x57, 0x27, 0x94, 0x10, 0x18, 0xab, 0x72, 0x8b, 0xdd, 0x76, 0x9b,
+	0x09, 0x85, 0xf3, 0x3d, 0x5a, 0x27, 0xb5, 0xa2, 0x71, 0x5b, 0xf7, 0xaf, 0xde, 0x36, 0x65, 0x23,
+	0xe8, 0x28, 0xd8, 0x3e, 0x93, 0x6b, 0x88, 0x65, 0x4d, 0xc7, 0x49, 0x94, 0x5d, 0x16, 0xb1, 0xac,
+	0xbd, 0x83, 0x8e, 0x73, 0x7a, 0x16, 0x1c, 0x9f, 0x7d, 0xb7, 0xf7, 0xdd, 0x24, 0x74, 0x3e, 0xbf,
+	0xcd, 0xbf, 0x9f, 0xd6, 0xb2, 0x11, 0xbb, 0x2a, 0xe7, 0x7a, 0xcb, 0xc4, 0xc1, 0xa0, 0xdd, 0x60,
+	0xbd, 0x46, 0xcb, 0x56, 0x65, 0x65, 0x25, 0x67, 0xe1, 0x6f, 0x98, 0xbf, 0x49, 0x15, 0xee, 0xf0,
+	0xf2, 0x37, 0x00, 0x8f, 0x97, 0x01, 0xd6, 0xa2, 0x01, 0x00, 0x00,
+}
@@ -50,7 +50,7 @@ var ServerStatus_StatusCode_value = map[string]int32{
 func (x ServerStatus_StatusCode) String() string {
 	return proto.EnumName(ServerStatus_StatusCode_name, int32(x))
 }
-func (ServerStatus_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor12, []int{0, 0} }
+func (ServerStatus_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor13, []int{0, 0} }
 
 type ServerStatus struct {
 	Status ServerStatus_StatusCode `protobuf:"varint,1,opt,name=status,enum=protos.ServerStatus_StatusCode" json:"status,omitempty"`
@@ -59,7 +59,7 @@ type ServerStatus struct {
 func (m *ServerStatus) Reset()                    { *m = ServerStatus{} }
 func (m *ServerStatus) String() string            { return proto.CompactTextString(m) }
 func (*ServerStatus) ProtoMessage()               {}
-func (*ServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{0} }
+func (*ServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{0} }
 
 type LogLevelRequest struct {
 	LogModule string `protobuf:"bytes,1,opt,name=logModule" json:"logModule,omitempty"`
@@ -69,7 +69,7 @@ type LogLevelRequest struct {
 func (m *LogLevelRequest) Reset()                    { *m = LogLevelRequest{} }
 func (m *LogLevelRequest) String() string            { return proto.CompactTextString(m) }
 func (*LogLevelRequest) ProtoMessage()               {}
-func (*LogLevelRequest) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{1} }
+func (*LogLevelRequest) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{1} }
 
 type LogLevelResponse struct {
 	LogModule string `protobuf:"bytes,1,opt,name=logModule" json:"logModule,omitempty"`
@@ -79,7 +79,7 @@ type LogLevelResponse struct {
 func (m *LogLevelResponse) Reset()                    { *m = LogLevelResponse{} }
 func (m *LogLevelResponse) String() string            { return proto.CompactTextString(m) }
 func (*LogLevelResponse) ProtoMessage()               {}
-func (*LogLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{2} }
+func (*LogLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{2} }
 
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
@@ -291,14 +291,14 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
-	Metadata: fileDescriptor12,
+	Metadata: fileDescriptor13,
 }
 
-func init() { proto.RegisterFile("peer/server_admin.proto", fileDescriptor12) }
+func init() { proto.RegisterFile("peer/server_admin.proto", fileDescriptor13) }
 
-var fileDescriptor12 = []byte{
-	// 384 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x92, 0xcf, 0x4e, 0xe2, 0x50,
+var fileDescriptor13 = []byte{
+	// 381 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x92, 0xcf, 0x4e, 0xe2, 0x50,
 	0x14, 0xc6, 0x29, 0x33, 0x30, 0xd3, 0xc3, 0xfc, 0xe9, 0xdc, 0x4c, 0x06, 0xd2, 0x99, 0x64, 0x26,
 	0x5d, 0x8d, 0x31, 0x69, 0x13, 0x5c, 0xb8, 0x50, 0x17, 0x68, 0x2b, 0x1a, 0xb0, 0x90, 0x16, 0x62,
 	0x74, 0x63, 0x5a, 0x7a, 0x28, 0x24, 0x85, 0x5b, 0xef, 0xbd, 0x25, 0xe1, 0x75, 0x7c, 0x27, 0xdf,
@@ -320,6 +320,6 @@ var fileDescriptor12 = []byte{
 	0x0f, 0x73, 0xf6, 0x34, 0xdd, 0xd2, 0x7d, 0x06, 0x3f, 0xda, 0x28, 0xe4, 0x63, 0xd7, 0x68, 0x48,
 	0x7d, 0x2d, 0x7e, 0x46, 0x5e, 0x6f, 0xbc, 0x5c, 0x48, 0x8a, 0x32, 0xc9, 0x7f, 0x97, 0xa4, 0xe3,
 	0xdd, 0xeb, 0x9d, 0x78, 0x2a, 0x26, 0x59, 0x68, 0x8e, 0xe8, 0xcc, 0x9a, 0x2c, 0x53, 0x64, 0x09,
-	0x46, 0x31, 0x32, 0x6b, 0x1c, 0x84, 0x6c, 0x3a, 0x92, 0xd7, 0xcc, 0xad, 0xfc, 0xf8, 0x43, 0x79,
-	0xe9, 0x7b, 0x0f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xa2, 0xc9, 0xe8, 0x56, 0x0b, 0x03, 0x00, 0x00,
+	0x46, 0x31, 0x32, 0x6b, 0x1c, 0x84, 0x6c, 0x3a, 0x92, 0xd7, 0xcc, 0xad, 0x14, 0x91, 0x85, 0xf2,
+	0xd2, 0xf7, 0x1e, 0x06, 0x00, 0xa2, 0xc9, 0xe8, 0x56, 0x0b, 0x03, 0x00, 0x00,
 }
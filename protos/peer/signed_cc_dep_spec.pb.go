@@ -0,0 +1,68 @@
+// Code generated by protoc-gen-go.
+// source: peer/signed_cc_dep_spec.proto
+// DO NOT EDIT!
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// SignedChaincodeDeploymentSpec is the chaincode package written by
+// "peer chaincode package". It carries the deployment spec along with the
+// policy for instantiating it and the endorsements of its owners
+type SignedChaincodeDeploymentSpec struct {
+	// The bytes of the ChaincodeDeploymentSpec
+	ChaincodeDeploymentSpec []byte `protobuf:"bytes,1,opt,name=chaincode_deployment_spec,json=chaincodeDeploymentSpec,proto3" json:"chaincode_deployment_spec,omitempty"`
+	// The instantiation policy, a marshaled SignaturePolicyEnvelope. It is
+	// checked by LCCC against the creator of the instantiate proposal
+	InstantiationPolicy []byte `protobuf:"bytes,2,opt,name=instantiation_policy,json=instantiationPolicy,proto3" json:"instantiation_policy,omitempty"`
+	// The endorsements of the owners of the package. Each is the owner's
+	// signature over a marshaled SignedChaincodeDeploymentSpec holding the
+	// chaincode_deployment_spec, the instantiation_policy and a single
+	// owner endorsement with only its endorser set
+	OwnerEndorsements []*Endorsement `protobuf:"bytes,3,rep,name=owner_endorsements,json=ownerEndorsements" json:"owner_endorsements,omitempty"`
+}
+
+func (m *SignedChaincodeDeploymentSpec) Reset()                    { *m = SignedChaincodeDeploymentSpec{} }
+func (m *SignedChaincodeDeploymentSpec) String() string            { return proto.CompactTextString(m) }
+func (*SignedChaincodeDeploymentSpec) ProtoMessage()               {}
+func (*SignedChaincodeDeploymentSpec) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{0} }
+
+func (m *SignedChaincodeDeploymentSpec) GetOwnerEndorsements() []*Endorsement {
+	if m != nil {
+		return m.OwnerEndorsements
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SignedChaincodeDeploymentSpec)(nil), "protos.SignedChaincodeDeploymentSpec")
+}
+
+func init() { proto.RegisterFile("peer/signed_cc_dep_spec.proto", fileDescriptor14) }
+
+var fileDescriptor14 = []byte{
+	// 242 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0x31, 0x4b, 0xfc, 0x40,
+	0x10, 0xc5, 0xc9, 0xff, 0xe0, 0x5f, 0xac, 0x36, 0xe6, 0x04, 0xa3, 0x70, 0x70, 0x68, 0x73, 0x22,
+	0x24, 0xa8, 0x9d, 0xe5, 0xa9, 0xbd, 0xdc, 0x75, 0x36, 0xcb, 0x66, 0x76, 0x4c, 0x16, 0x72, 0x3b,
+	0xc3, 0xcc, 0x8a, 0xe4, 0x6b, 0xfa, 0x89, 0xc4, 0x0d, 0x9e, 0x5a, 0x58, 0x0d, 0xcc, 0xef, 0xbd,
+	0x37, 0xc3, 0x33, 0x0b, 0x46, 0x94, 0x46, 0x43, 0x17, 0xd1, 0x5b, 0x00, 0xeb, 0x91, 0xad, 0x32,
+	0x42, 0xcd, 0x42, 0x89, 0xca, 0xff, 0x79, 0xe8, 0xd9, 0x45, 0x96, 0xbd, 0xb8, 0x56, 0x02, 0x58,
+	0x16, 0x62, 0x52, 0x37, 0x58, 0x41, 0x65, 0x8a, 0x8a, 0x93, 0xf8, 0xfc, 0xbd, 0x30, 0x8b, 0x6d,
+	0x4e, 0xba, 0xef, 0x5d, 0x88, 0x40, 0x1e, 0x1f, 0x90, 0x07, 0x1a, 0x77, 0x18, 0xd3, 0x96, 0x11,
+	0xca, 0x3b, 0x73, 0x0a, 0x5f, 0xc8, 0xfa, 0x3d, 0xcb, 0x17, 0xab, 0x62, 0x59, 0xac, 0x0e, 0x37,
+	0x27, 0xf0, 0x87, 0xf7, 0xda, 0x1c, 0x87, 0xa8, 0xc9, 0xc5, 0x14, 0x5c, 0x0a, 0x14, 0x2d, 0xd3,
+	0x10, 0x60, 0xac, 0xfe, 0x65, 0xdb, 0xfc, 0x17, 0x7b, 0xca, 0xa8, 0x5c, 0x9b, 0x92, 0xde, 0x22,
+	0x8a, 0xc5, 0xe8, 0x49, 0x14, 0x3f, 0xb3, 0xb4, 0x9a, 0x2d, 0x67, 0xab, 0x83, 0x9b, 0xf9, 0xf4,
+	0xb4, 0xd6, 0x8f, 0xdf, 0x6c, 0x73, 0x94, 0xe5, 0x3f, 0x36, 0xba, 0xbe, 0x7a, 0xbe, 0xec, 0x42,
+	0xea, 0x5f, 0xdb, 0x1a, 0x68, 0xd7, 0xf4, 0x23, 0xa3, 0x0c, 0xe8, 0xbb, 0x7d, 0x1b, 0xcd, 0x14,
+	0xd3, 0x30, 0xa2, 0xb4, 0x53, 0x5d, 0xb7, 0x1f, 0x03, 0x00, 0x9f, 0xe1, 0x49, 0xa4, 0x56, 0x01,
+	0x00, 0x00,
+}
@@ -20,10 +20,9 @@ import (
 	"fmt"
 	"os"
 
-	"path/filepath"
-
 	"github.com/hyperledger/fabric/core/peer/msp"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/testtools"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
@@ -34,15 +33,16 @@ var (
 )
 
 func init() {
-	var err error
-	// setup the MSP manager so that we can sign/verify
-	mspMgrConfigDir, err := getMSPMgrConfigDir()
+	// setup the MSP manager so that we can sign/verify, with a generated
+	// identity rather than the sample one whose certificate has expired
+	mspMgrConfigDir, err := testtools.GenerateTempMSPDir()
 	if err != nil {
-		fmt.Printf("Could not get location of msp manager config file")
+		fmt.Printf("Could not generate msp config, err %s", err)
 		os.Exit(-1)
 		return
 	}
-	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir)
+	defer os.RemoveAll(mspMgrConfigDir)
+	err = mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigDir, nil)
 	if err != nil {
 		fmt.Printf("Could not load msp config, err %s", err)
 		os.Exit(-1)
@@ -56,66 +56,45 @@ func init() {
 	}
 }
 
-func getMSPMgrConfigDir() (string, error) {
-	var pwd string
-	var err error
-	if pwd, err = os.Getwd(); err != nil {
-		return "", err
-	}
-	path := pwd
-	dir := ""
-	for {
-		path, dir = filepath.Split(path)
-		path = filepath.Clean(path)
-		fmt.Printf("path=%s, dir=%s\n", path, dir)
-		if dir == "fabric" {
-			break
-		}
-	}
-	filePath := filepath.Join(path, "fabric/msp/sampleconfig/")
-	fmt.Printf("filePath=%s\n", filePath)
-	return filePath, nil
-}
-
 // ConstructSingedTxEnvWithDefaultSigner constructs a transaction envelop for tests with a default signer.
 // This method helps other modules to construct a transaction with supplied parameters
-func ConstructSingedTxEnvWithDefaultSigner(txid string, chainID, ccName string, simulationResults []byte, events []byte, visibility []byte) (*common.Envelope, error) {
-	return ConstructSingedTxEnv(txid, chainID, ccName, simulationResults, events, visibility, signer)
+func ConstructSingedTxEnvWithDefaultSigner(chainID, ccName string, simulationResults []byte, events []byte, visibility []byte) (*common.Envelope, string, error) {
+	return ConstructSingedTxEnv(chainID, ccName, simulationResults, events, visibility, signer)
 }
 
-// ConstructSingedTxEnv constructs a transaction envelop for tests
-func ConstructSingedTxEnv(txid string, chainID string, ccName string, simulationResults []byte, events []byte, visibility []byte, signer msp.SigningIdentity) (*common.Envelope, error) {
+// ConstructSingedTxEnv constructs a transaction envelop for tests and returns it along with the ID of the transaction
+func ConstructSingedTxEnv(chainID string, ccName string, simulationResults []byte, events []byte, visibility []byte, signer msp.SigningIdentity) (*common.Envelope, string, error) {
 	ss, err := signer.Serialize()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	prop, err := putils.CreateChaincodeProposal(txid, chainID, &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeID: &pb.ChaincodeID{Name: ccName}}}, ss)
+	prop, txid, err := putils.CreateChaincodeProposal(chainID, &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeID: &pb.ChaincodeID{Name: ccName}}}, ss)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	presp, err := putils.CreateProposalResponse(prop.Header, prop.Payload, simulationResults, nil, nil, signer)
+	presp, err := putils.CreateProposalResponse(prop.Header, prop.Payload, nil, simulationResults, nil, nil, signer)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	env, err := putils.CreateSignedTx(prop, signer, presp)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return env, nil
+	return env, txid, nil
 }
 
 var mspLcl msp.MSP
 var sigId msp.SigningIdentity
 
 // ConstructUnsingedTxEnv creates a Transaction envelope from given inputs
-func ConstructUnsingedTxEnv(txid string, chainID string, ccName string, simulationResults []byte, events []byte, visibility []byte) (*common.Envelope, error) {
+func ConstructUnsingedTxEnv(chainID string, ccName string, simulationResults []byte, events []byte, visibility []byte) (*common.Envelope, string, error) {
 	if mspLcl == nil {
 		mspLcl = msp.NewNoopMsp()
 		sigId, _ = mspLcl.GetDefaultSigningIdentity()
 	}
 
-	return ConstructSingedTxEnv(txid, chainID, ccName, simulationResults, events, visibility, sigId)
+	return ConstructSingedTxEnv(chainID, ccName, simulationResults, events, visibility, sigId)
 }
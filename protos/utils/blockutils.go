@@ -72,6 +72,22 @@ func GetLastConfigurationIndexFromBlock(block *cb.Block) (uint64, error) {
 	return lc.Index, nil
 }
 
+// IsConfigBlock tells whether the block holds a single configuration transaction
+func IsConfigBlock(block *cb.Block) bool {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return false
+	}
+	envelope, err := ExtractEnvelope(block, 0)
+	if err != nil {
+		return false
+	}
+	payload, err := ExtractPayload(envelope)
+	if err != nil || payload.Header == nil || payload.Header.ChainHeader == nil {
+		return false
+	}
+	return payload.Header.ChainHeader.Type == int32(cb.HeaderType_CONFIGURATION_TRANSACTION)
+}
+
 // GetBlockFromBlockBytes marshals the bytes into Block
 func GetBlockFromBlockBytes(blockBytes []byte) (*cb.Block, error) {
 	block := &cb.Block{}
@@ -109,6 +125,7 @@ func MakeConfigurationBlock(testChainID string) (*cb.Block, error) {
 		encodeConsensusType(testChainID),
 		encodeBatchSize(testChainID),
 		lockDefaultModificationPolicy(testChainID),
+		encodeBlockValidationPolicy(testChainID),
 		encodeMSP(testChainID),
 	)
 	payloadChainHeader := MakeChainHeader(cb.HeaderType_CONFIGURATION_TRANSACTION,
@@ -191,9 +208,45 @@ func getTESTMSPConfigPath() string {
 	return cfgPath
 }
 
+// encodeBlockValidationPolicy requires blocks to be signed by the signing
+// identity of the MSP test configuration, which is the one the orderer
+// uses by default
+func encodeBlockValidationPolicy(testChainID string) *cb.SignedConfigurationItem {
+	conf, err := msp.GetLocalMspConfig(getTESTMSPConfigPath(), nil)
+	if err != nil {
+		panic(fmt.Sprintf("GetLocalMspConfig failed, err %s", err))
+	}
+	signer, err := msp.NewBccspMsp()
+	if err != nil {
+		panic(fmt.Sprintf("NewBccspMsp failed, err %s", err))
+	}
+	if err = signer.Setup(conf); err != nil {
+		panic(fmt.Sprintf("Setup of the test MSP failed, err %s", err))
+	}
+	id, err := signer.GetDefaultSigningIdentity()
+	if err != nil {
+		panic(fmt.Sprintf("GetDefaultSigningIdentity failed, err %s", err))
+	}
+	idBytes, err := id.Serialize()
+	if err != nil {
+		panic(fmt.Sprintf("Serialize failed, err %s", err))
+	}
+
+	ciChainHeader := MakeChainHeader(cb.HeaderType_CONFIGURATION_ITEM,
+		messageVersion, testChainID, epoch)
+	configItem := MakeConfigurationItem(ciChainHeader,
+		cb.ConfigurationItem_Policy, lastModified, configtx.DefaultModificationPolicyID,
+		configtx.BlockValidationPolicyID,
+		MarshalOrPanic(MakePolicyOrPanic(cauthdsl.Envelope(cauthdsl.SignedBy(0), [][]byte{idBytes}))))
+
+	return &cb.SignedConfigurationItem{
+		ConfigurationItem: MarshalOrPanic(configItem),
+		Signatures:        nil}
+}
+
 func encodeMSP(testChainID string) *cb.SignedConfigurationItem {
 	cfgPath := getTESTMSPConfigPath()
-	conf, err := msp.GetLocalMspConfig(cfgPath)
+	conf, err := msp.GetLocalMspConfig(cfgPath, nil)
 	if err != nil {
 		panic(fmt.Sprintf("GetLocalMspConfig failed, err %s", err))
 	}
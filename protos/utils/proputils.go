@@ -17,9 +17,12 @@ limitations under the License.
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
@@ -184,48 +187,84 @@ func GetSignatureHeader(bytes []byte) (*common.SignatureHeader, error) {
 	return sh, nil
 }
 
-// CreateChaincodeProposal creates a proposal from given input
-func CreateChaincodeProposal(txid string, chainID string, cis *peer.ChaincodeInvocationSpec, creator []byte) (*peer.Proposal, error) {
+// CreateChaincodeProposal creates a proposal from given input; the ID of the
+// transaction is derived from the nonce and the creator of the proposal and
+// returned along with it
+func CreateChaincodeProposal(chainID string, cis *peer.ChaincodeInvocationSpec, creator []byte) (*peer.Proposal, string, error) {
 	ccHdrExt := &peer.ChaincodeHeaderExtension{ChaincodeID: cis.ChaincodeSpec.ChaincodeID}
 	ccHdrExtBytes, err := proto.Marshal(ccHdrExt)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	cisBytes, err := proto.Marshal(cis)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	ccPropPayload := &peer.ChaincodeProposalPayload{Input: cisBytes}
 	ccPropPayloadBytes, err := proto.Marshal(ccPropPayload)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// generate a random nonce
 	nonce, err := primitives.GetRandomNonce()
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	// the transaction ID is bound to the nonce and the creator
+	txid, err := ComputeProposalTxID(nonce, creator)
+	if err != nil {
+		return nil, "", err
 	}
 
 	hdr := &common.Header{ChainHeader: &common.ChainHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION),
 		TxID:      txid,
+		Timestamp: util.CreateUtcTimestamp(),
 		ChainID:   chainID,
 		Extension: ccHdrExtBytes},
 		SignatureHeader: &common.SignatureHeader{Nonce: nonce, Creator: creator}}
 
 	hdrBytes, err := proto.Marshal(hdr)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	return &peer.Proposal{Header: hdrBytes, Payload: ccPropPayloadBytes}, txid, nil
+}
+
+// ComputeProposalTxID computes the ID of the transaction of a proposal as the
+// hex encoded SHA256 hash of the nonce and the serialized creator
+func ComputeProposalTxID(nonce, creator []byte) (string, error) {
+	if len(nonce) == 0 {
+		return "", fmt.Errorf("Nonce is empty")
 	}
+	if len(creator) == 0 {
+		return "", fmt.Errorf("Creator is empty")
+	}
+
+	digest := sha256.Sum256(util.ConcatenateBytes(nonce, creator))
+	return hex.EncodeToString(digest[:]), nil
+}
 
-	return &peer.Proposal{Header: hdrBytes, Payload: ccPropPayloadBytes}, nil
+// CheckProposalTxID returns nil if txid is the ID of the transaction
+// derived from the nonce and the creator
+func CheckProposalTxID(txid string, nonce, creator []byte) error {
+	expected, err := ComputeProposalTxID(nonce, creator)
+	if err != nil {
+		return err
+	}
+	if txid != expected {
+		return fmt.Errorf("Invalid txID: got [%s], expected [%s]", txid, expected)
+	}
+	return nil
 }
 
 // GetBytesProposalResponsePayload gets proposal response payload
-func GetBytesProposalResponsePayload(hash []byte, result []byte, event []byte) ([]byte, error) {
-	cAct := &peer.ChaincodeAction{Events: event, Results: result}
+func GetBytesProposalResponsePayload(hash []byte, response *peer.Response, result []byte, event []byte) ([]byte, error) {
+	cAct := &peer.ChaincodeAction{Events: event, Results: result, Response: response}
 	cActBytes, err := proto.Marshal(cAct)
 	if err != nil {
 		return nil, err
@@ -260,6 +299,16 @@ func GetBytesChaincodeEvent(event *peer.ChaincodeEvent) ([]byte, error) {
 	return eventBytes, nil
 }
 
+// GetBytesResponse gets the bytes of Response
+func GetBytesResponse(res *peer.Response) ([]byte, error) {
+	resBytes, err := proto.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return resBytes, nil
+}
+
 // GetBytesChaincodeActionPayload get the bytes of ChaincodeActionPayload from the message
 func GetBytesChaincodeActionPayload(cap *peer.ChaincodeActionPayload) ([]byte, error) {
 	capBytes, err := proto.Marshal(cap)
@@ -340,62 +389,106 @@ func GetBytesEnvelope(env *common.Envelope) ([]byte, error) {
 	return bytes, nil
 }
 
-// GetActionFromEnvelope extracts a ChaincodeAction message from a serialized Envelope
-func GetActionFromEnvelope(envBytes []byte) (*peer.ChaincodeAction, error) {
+// GetActionAndHashedValuesFromEnvelope extracts a ChaincodeAction message from a serialized
+// Envelope along with the values that were replaced by their hashes in its results
+func GetActionAndHashedValuesFromEnvelope(envBytes []byte) (*peer.ChaincodeAction, []byte, error) {
 	env, err := GetEnvelopeFromBlock(envBytes)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	payl, err := GetPayload(env)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tx, err := GetTransaction(payl.Data)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	_, respPayload, err := GetPayloads(tx.Actions[0])
-	return respPayload, err
+	ccActionPayload, respPayload, err := GetPayloads(tx.Actions[0])
+	if err != nil || ccActionPayload == nil {
+		return respPayload, nil, err
+	}
+	return respPayload, ccActionPayload.Action.HashedValues, nil
 }
 
 // CreateProposalFromCIS returns a proposal given a serialized identity and a ChaincodeInvocationSpec
-func CreateProposalFromCIS(txid string, chainID string, cis *peer.ChaincodeInvocationSpec, creator []byte) (*peer.Proposal, error) {
-	return CreateChaincodeProposal(txid, chainID, cis, creator)
+func CreateProposalFromCIS(chainID string, cis *peer.ChaincodeInvocationSpec, creator []byte) (*peer.Proposal, string, error) {
+	return CreateChaincodeProposal(chainID, cis, creator)
+}
+
+// CreateInstallProposalFromCDS returns an install proposal given a serialized identity and a ChaincodeDeploymentSpec.
+// The deployment spec is installed as a package without instantiation policy or owners
+func CreateInstallProposalFromCDS(chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, string, error) {
+	cdsBytes, err := proto.Marshal(cds)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return CreateInstallProposalFromPackage(chainID, &peer.SignedChaincodeDeploymentSpec{ChaincodeDeploymentSpec: cdsBytes}, creator)
+}
+
+// CreateInstallProposalFromPackage returns an install proposal given a serialized identity and a SignedChaincodeDeploymentSpec
+func CreateInstallProposalFromPackage(chainID string, pkg *peer.SignedChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, string, error) {
+	return createProposalFromCDS(chainID, pkg, creator, "install")
 }
 
 // CreateDeployProposalFromCDS returns a deploy proposal given a serialized identity and a ChaincodeDeploymentSpec
-func CreateDeployProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, error) {
-	return createProposalFromCDS(txid, chainID, cds, creator, true)
+func CreateDeployProposalFromCDS(chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, string, error) {
+	return createProposalFromCDS(chainID, cds, creator, "deploy")
 }
 
 // CreateUpgradeProposalFromCDS returns a upgrade proposal given a serialized identity and a ChaincodeDeploymentSpec
-func CreateUpgradeProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, error) {
-	return createProposalFromCDS(txid, chainID, cds, creator, false)
+func CreateUpgradeProposalFromCDS(chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte) (*peer.Proposal, string, error) {
+	return createProposalFromCDS(chainID, cds, creator, "upgrade")
+}
+
+// CreateGetChaincodesProposal returns a proposal to list the chaincodes instantiated on the chain
+func CreateGetChaincodesProposal(chainID string, creator []byte) (*peer.Proposal, string, error) {
+	return createLCCCQueryProposal(chainID, "getchaincodes", creator)
+}
+
+// CreateGetInstalledChaincodesProposal returns a proposal to list the chaincodes installed on the peer
+func CreateGetInstalledChaincodesProposal(chainID string, creator []byte) (*peer.Proposal, string, error) {
+	return createLCCCQueryProposal(chainID, "getinstalledchaincodes", creator)
+}
+
+// createLCCCQueryProposal returns a proposal for a lccc function without arguments
+func createLCCCQueryProposal(chainID string, function string, creator []byte) (*peer.Proposal, string, error) {
+	lcccSpec := &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			Type:        peer.ChaincodeSpec_GOLANG,
+			ChaincodeID: &peer.ChaincodeID{Name: "lccc"},
+			CtorMsg:     &peer.ChaincodeInput{Args: [][]byte{[]byte(function)}}}}
+
+	return CreateProposalFromCIS(chainID, lcccSpec, creator)
 }
 
-// createProposalFromCDS returns a deploy or upgrade proposal given a serialized identity and a ChaincodeDeploymentSpec
-func createProposalFromCDS(txid string, chainID string, cds *peer.ChaincodeDeploymentSpec, creator []byte, deploy bool) (*peer.Proposal, error) {
-	b, err := proto.Marshal(cds)
+// createProposalFromCDS returns an install, deploy or upgrade proposal given a serialized identity and a
+// ChaincodeDeploymentSpec (or the SignedChaincodeDeploymentSpec package for install)
+func createProposalFromCDS(chainID string, msg proto.Message, creator []byte, propType string) (*peer.Proposal, string, error) {
+	b, err := proto.Marshal(msg)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	var propType string
-	if deploy {
-		propType = "deploy"
+	//install is not tied to the chain, it only carries the package
+	var args [][]byte
+	if propType == "install" {
+		args = [][]byte{[]byte(propType), b}
 	} else {
-		propType = "upgrade"
+		args = [][]byte{[]byte(propType), []byte(chainID), b}
 	}
+
 	//wrap the deployment in an invocation spec to lccc...
 	lcccSpec := &peer.ChaincodeInvocationSpec{
 		ChaincodeSpec: &peer.ChaincodeSpec{
 			Type:        peer.ChaincodeSpec_GOLANG,
 			ChaincodeID: &peer.ChaincodeID{Name: "lccc"},
-			CtorMsg:     &peer.ChaincodeInput{Args: [][]byte{[]byte(propType), []byte(chainID), b}}}}
+			CtorMsg:     &peer.ChaincodeInput{Args: args}}}
 
 	//...and get the proposal for it
-	return CreateProposalFromCIS(txid, chainID, lcccSpec, creator)
+	return CreateProposalFromCIS(chainID, lcccSpec, creator)
 }
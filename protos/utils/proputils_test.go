@@ -42,9 +42,8 @@ func createCIS() *pb.ChaincodeInvocationSpec {
 }
 
 func TestProposal(t *testing.T) {
-	uuid := util.GenerateUUID()
 	// create a proposal from a ChaincodeInvocationSpec
-	prop, err := CreateChaincodeProposal(uuid, util.GetTestChainID(), createCIS(), []byte("creator"))
+	prop, txid, err := CreateChaincodeProposal(util.GetTestChainID(), createCIS(), []byte("creator"))
 	if err != nil {
 		t.Fatalf("Could not create chaincode proposal, err %s\n", err)
 		return
@@ -92,6 +91,14 @@ func TestProposal(t *testing.T) {
 		return
 	}
 
+	// the transaction ID is derived from the nonce and the creator
+	if hdr.ChainHeader.TxID != txid {
+		t.Fatalf("The returned txID %s does not match the header %s\n", txid, hdr.ChainHeader.TxID)
+	}
+	if err = CheckProposalTxID(txid, hdr.SignatureHeader.Nonce, hdr.SignatureHeader.Creator); err != nil {
+		t.Fatalf("The txID should be derived from the nonce and the creator, err %s\n", err)
+	}
+
 	// get back the header extension
 	hdrExt, err := GetChaincodeHeaderExtension(hdr)
 	if err != nil {
@@ -139,7 +146,7 @@ func TestProposalResponse(t *testing.T) {
 	}
 
 	// get the bytes of the ProposalResponsePayload
-	prpBytes, err := GetBytesProposalResponsePayload(pHashBytes, results, eventBytes)
+	prpBytes, err := GetBytesProposalResponsePayload(pHashBytes, nil, results, eventBytes)
 	if err != nil {
 		t.Fatalf("Failure while marshalling the ProposalResponsePayload")
 		return
@@ -197,8 +204,7 @@ func TestProposalResponse(t *testing.T) {
 
 func TestEnvelope(t *testing.T) {
 	// create a proposal from a ChaincodeInvocationSpec
-	uuid := util.GenerateUUID()
-	prop, err := CreateChaincodeProposal(uuid, util.GetTestChainID(), createCIS(), signerSerialized)
+	prop, _, err := CreateChaincodeProposal(util.GetTestChainID(), createCIS(), signerSerialized)
 	if err != nil {
 		t.Fatalf("Could not create chaincode proposal, err %s\n", err)
 		return
@@ -206,7 +212,7 @@ func TestEnvelope(t *testing.T) {
 
 	res := []byte("res")
 
-	presp, err := CreateProposalResponse(prop.Header, prop.Payload, res, nil, nil, signer)
+	presp, err := CreateProposalResponse(prop.Header, prop.Payload, nil, res, nil, nil, signer)
 	if err != nil {
 		t.Fatalf("Could not create proposal response, err %s\n", err)
 		return
@@ -230,7 +236,7 @@ func TestEnvelope(t *testing.T) {
 		return
 	}
 
-	act2, err := GetActionFromEnvelope(envBytes)
+	act2, _, err := GetActionAndHashedValuesFromEnvelope(envBytes)
 	if err != nil {
 		t.Fatalf("Could not extract actions from envelop, err %s\n", err)
 		return
@@ -289,13 +295,51 @@ func TestEnvelope(t *testing.T) {
 	}
 }
 
+func TestEnvelopeWithHashedValues(t *testing.T) {
+	prop, _, err := CreateChaincodeProposal(util.GetTestChainID(), createCIS(), signerSerialized)
+	if err != nil {
+		t.Fatalf("Could not create chaincode proposal, err %s\n", err)
+		return
+	}
+
+	res := []byte("res")
+	hashedValues := []byte("hashed values")
+
+	presp, err := CreateProposalResponse(prop.Header, prop.Payload, nil, res, nil, nil, signer)
+	if err != nil {
+		t.Fatalf("Could not create proposal response, err %s\n", err)
+		return
+	}
+	presp.HashedValues = hashedValues
+
+	tx, err := CreateSignedTx(prop, signer, presp)
+	if err != nil {
+		t.Fatalf("Could not create signed tx, err %s\n", err)
+		return
+	}
+
+	envBytes, err := GetBytesEnvelope(tx)
+	if err != nil {
+		t.Fatalf("Could not marshal envelope, err %s\n", err)
+		return
+	}
+
+	act, hashedValuesBack, err := GetActionAndHashedValuesFromEnvelope(envBytes)
+	if err != nil {
+		t.Fatalf("Could not extract actions from envelop, err %s\n", err)
+		return
+	}
+	assert.Equal(t, res, act.Results)
+	assert.Equal(t, hashedValues, hashedValuesBack)
+}
+
 var signer msp.SigningIdentity
 var signerSerialized []byte
 
 func TestMain(m *testing.M) {
 	// setup the MSP manager so that we can sign/verify
 	mspMgrConfigFile := "../../msp/sampleconfig/"
-	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigFile)
+	err := mspmgmt.LoadFakeSetupWithLocalMspAndTestChainMsp(mspMgrConfigFile, nil)
 	if err != nil {
 		os.Exit(-1)
 		fmt.Printf("Could not initialize msp")
@@ -317,3 +361,32 @@ func TestMain(m *testing.M) {
 
 	os.Exit(m.Run())
 }
+
+func TestProposalTxID(t *testing.T) {
+	nonce := []byte("nonce")
+	creator := []byte("creator")
+
+	txid, err := ComputeProposalTxID(nonce, creator)
+	if err != nil {
+		t.Fatalf("Could not compute the txID, err %s", err)
+	}
+	if other, _ := ComputeProposalTxID(nonce, creator); other != txid {
+		t.Fatalf("The txID should be deterministic")
+	}
+
+	if err = CheckProposalTxID(txid, nonce, creator); err != nil {
+		t.Fatalf("The txID should match, err %s", err)
+	}
+	if err = CheckProposalTxID(txid, []byte("other nonce"), creator); err == nil {
+		t.Fatalf("The txID should not match another nonce")
+	}
+	if err = CheckProposalTxID(txid, nonce, []byte("other creator")); err == nil {
+		t.Fatalf("The txID should not match another creator")
+	}
+	if err = CheckProposalTxID(util.GenerateUUID(), nonce, creator); err == nil {
+		t.Fatalf("A txID chosen by the client should not match")
+	}
+	if _, err = ComputeProposalTxID(nil, creator); err == nil {
+		t.Fatalf("The txID should not be computed without a nonce")
+	}
+}
@@ -72,6 +72,42 @@ func GetEnvelopeFromBlock(data []byte) (*common.Envelope, error) {
 	return env, nil
 }
 
+// CreateSignedEnvelope creates a signed envelope of the desired type, with
+// marshaled dataMsg and signs it
+func CreateSignedEnvelope(txType common.HeaderType, chainID string, signer msp.SigningIdentity, dataMsg proto.Message) (*common.Envelope, error) {
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Error serializing signing identity: %s", err)
+	}
+
+	nonce, err := CreateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := proto.Marshal(dataMsg)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling envelope data: %s", err)
+	}
+
+	payload := &common.Payload{
+		Header: MakePayloadHeader(MakeChainHeader(txType, 0, chainID, 0), MakeSignatureHeader(creator, nonce)),
+		Data:   data,
+	}
+
+	paylBytes, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling payload: %s", err)
+	}
+
+	sig, err := signer.Sign(paylBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Error signing payload: %s", err)
+	}
+
+	return &common.Envelope{Payload: paylBytes, Signature: sig}, nil
+}
+
 // assemble an Envelope message from proposal, endorsements and a signer.
 // This function should be called by a client when it has collected enough endorsements
 // for a proposal to create a transaction and submit it to peers for ordering
@@ -131,8 +167,9 @@ func CreateSignedTx(proposal *peer.Proposal, signer msp.SigningIdentity, resps .
 		endorsements[n] = r.Endorsement
 	}
 
-	// create ChaincodeEndorsedAction
-	cea := &peer.ChaincodeEndorsedAction{ProposalResponsePayload: resps[0].Payload, Endorsements: endorsements}
+	// create ChaincodeEndorsedAction; the values that were replaced by their
+	// hashes in the endorsed results are the same for all the responses
+	cea := &peer.ChaincodeEndorsedAction{ProposalResponsePayload: resps[0].Payload, Endorsements: endorsements, HashedValues: resps[0].HashedValues}
 
 	// obtain the bytes of the proposal payload that will go to the transaction
 	propPayloadBytes, err := GetBytesProposalPayloadForTx(pPayl, hdrExt.PayloadVisibility)
@@ -182,7 +219,9 @@ func CreateSignedTx(proposal *peer.Proposal, signer msp.SigningIdentity, resps .
 	return &common.Envelope{Payload: paylBytes, Signature: sig}, nil
 }
 
-func CreateProposalResponse(hdr []byte, payl []byte, results []byte, events []byte, visibility []byte, signingEndorser msp.SigningIdentity) (*peer.ProposalResponse, error) {
+// CreateProposalResponse creates and signs a proposal response. The response
+// of the chaincode, when given, is signed along with the results and events
+func CreateProposalResponse(hdr []byte, payl []byte, response *peer.Response, results []byte, events []byte, visibility []byte, signingEndorser msp.SigningIdentity) (*peer.ProposalResponse, error) {
 	// obtain the proposal hash given proposal header, payload and the requested visibility
 	pHashBytes, err := GetProposalHash1(hdr, payl, visibility)
 	if err != nil {
@@ -190,7 +229,7 @@ func CreateProposalResponse(hdr []byte, payl []byte, results []byte, events []by
 	}
 
 	// get the bytes of the proposal response payload - we need to sign them
-	prpBytes, err := GetBytesProposalResponsePayload(pHashBytes, results, events)
+	prpBytes, err := GetBytesProposalResponsePayload(pHashBytes, response, results, events)
 	if err != nil {
 		return nil, errors.New("Failure while unmarshalling the ProposalResponsePayload")
 	}
@@ -213,6 +252,9 @@ func CreateProposalResponse(hdr []byte, payl []byte, results []byte, events []by
 		Endorsement: &peer.Endorsement{Signature: signature, Endorser: endorser},
 		Payload:     prpBytes,
 		Response:    &peer.Response{Status: 200, Message: "OK"}}
+	if response != nil {
+		resp.Response = response
+	}
 
 	return resp, nil
 }
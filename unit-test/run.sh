@@ -24,6 +24,9 @@ fi
 
 echo "DONE!"
 
+# The test environment provides SoftHSM for the PKCS#11 crypto provider
+GO_TAGS=${GO_TAGS:-pkcs11}
+
 echo "Running tests..."
-gocov test -ldflags "$GO_LDFLAGS" $PKGS -p 1 -timeout=20m | gocov-xml > report.xml
+gocov test -tags "$GO_TAGS" -ldflags "$GO_LDFLAGS" $PKGS -p 1 -timeout=20m | gocov-xml > report.xml
 